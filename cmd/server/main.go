@@ -23,17 +23,20 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"runtime"
 	"syscall"
 	"time"
+	_ "time/tzdata" // TIMEZONE must resolve in images without system zoneinfo
 
 	"loglynx/internal/api"
 	"loglynx/internal/api/handlers"
 	"loglynx/internal/banner"
 	"loglynx/internal/config"
 	"loglynx/internal/database"
+	"loglynx/internal/database/models"
 	"loglynx/internal/database/repositories"
 	"loglynx/internal/discovery"
 	"loglynx/internal/enrichment"
@@ -118,6 +121,8 @@ func main() {
 
 	// Initialize database connection with configured settings
 	db, err := database.NewConnection(&database.Config{
+		Driver:       cfg.Database.Driver,
+		DSN:          cfg.Database.DSN,
 		Path:         cfg.Database.Path,
 		MaxOpenConns: cfg.Database.MaxOpenConns,
 		MaxIdleConns: cfg.Database.MaxIdleConns,
@@ -137,12 +142,34 @@ func main() {
 	logger.Debug("Initializing repositories...")
 	sourceRepo := repositories.NewLogSourceRepository(db)
 	httpRepo := repositories.NewHTTPRequestRepository(db, logger)
+	httpRepo.SetFullTextSearch(cfg.Database.FullTextSearch)
 	statsRepo := repositories.NewStatsRepository(db, logger)
+	statsRepo.SetBenignStatusCodes(cfg.Stats.BenignStatusCodes)
+	statsRepo.SetMaxTopLimit(cfg.Stats.MaxTopLimit)
+	statsRepo.SetAuthAbuseConfig(strings.Split(cfg.Stats.AuthAbusePaths, ","), cfg.Stats.AuthAbuseMinRequests, cfg.Stats.AuthAbuseMinFailureRatio)
+	statsRepo.SetReferrerBlocklist(strings.Split(cfg.Stats.ReferrerBlocklist, ","))
+	statsRepo.SetDisplayTimezone(cfg.Stats.DisplayTimezone)
 	ipTagRepo := repositories.NewIPTagRepository(db)
 
 	// Initialize GeoIP enricher (optional - will work without GeoIP databases)
 	var geoIP *enrichment.GeoIPEnricher
+	var geoIPUpdater *enrichment.GeoIPUpdater
 	if cfg.GeoIP.Enabled {
+		// Fetch missing or stale databases before opening them (only with a MaxMind license key)
+		if cfg.GeoIP.LicenseKey != "" {
+			geoIPUpdater = enrichment.NewGeoIPUpdater(
+				cfg.GeoIP.LicenseKey,
+				enrichment.GeoLite2Editions(cfg.GeoIP.CityDBPath, cfg.GeoIP.CountryDBPath, cfg.GeoIP.ASNDBPath),
+				time.Duration(cfg.GeoIP.MaxAgeDays)*24*time.Hour,
+				logger,
+			)
+			updateCtx, cancelUpdate := context.WithTimeout(context.Background(), 10*time.Minute)
+			if _, err := geoIPUpdater.Update(updateCtx); err != nil {
+				logger.Warn("Some GeoIP databases could not be downloaded, using existing files", logger.Args("error", err))
+			}
+			cancelUpdate()
+		}
+
 		logger.Debug("Initializing GeoIP enricher...")
 		geoIP, err = enrichment.NewGeoIPEnricher(
 			cfg.GeoIP.CityDBPath,
@@ -154,7 +181,16 @@ func main() {
 		)
 		if err != nil {
 			logger.Warn("GeoIP enricher initialization failed, continuing without GeoIP", logger.Args("error", err))
-		} else if geoIP.IsEnabled() {
+		} else {
+			geoIP.SetProviderOrder(strings.Split(cfg.GeoIP.ProviderOrder, ","))
+			geoIP.SetCacheTTL(cfg.GeoIP.CacheTTL)
+			geoIP.SetNegativeCacheTTL(cfg.GeoIP.NegativeCacheTTL)
+			if geoIPUpdater != nil {
+				// Databases updated later are swapped in without a restart
+				geoIPUpdater.Start(geoIP)
+			}
+		}
+		if geoIP != nil && geoIP.IsEnabled() {
 			logger.Info("GeoIP enrichment enabled successfully")
 			// Load cache from database in background (non-blocking)
 			go func() {
@@ -173,16 +209,38 @@ func main() {
 	// Initialize parser registry
 	logger.Debug("Initializing parser registry...")
 	parserRegistry := parsers.NewRegistry(logger)
+	if cfg.LogSources.GenericFieldMap != "" {
+		if err := parserRegistry.RegisterGeneric(cfg.LogSources.GenericFieldMap); err != nil {
+			logger.Warn("Generic parser disabled: invalid field map", logger.Args("error", err))
+		}
+	}
+
+	discoveryEngine := discovery.NewEngine(sourceRepo, logger)
+
+	// Reconcile sources declared in LOG_SOURCES_FILE before discovery runs
+	if cfg.LogSources.SourcesFile != "" {
+		if err := applySourcesFile(cfg.LogSources.SourcesFile, discoveryEngine, parserRegistry, logger); err != nil {
+			logger.Warn("Sources file not applied, keeping stored sources", logger.Args("error", err))
+		}
+	}
 
 	// Run initial discovery SYNCHRONOUSLY to ensure log sources are found before starting ingestion
 	logger.Info("Discovering log sources...")
-	discoveryEngine := discovery.NewEngine(sourceRepo, logger)
 	if err := discoveryEngine.Run(logger); err != nil {
 		logger.Warn("Initial discovery failed", logger.Args("error", err))
 	} else {
 		logger.Info("Log source discovery completed")
 	}
 
+	// Apply per-source retention (DB_SOURCE_RETENTION_DAYS) to the discovered sources
+	for name, days := range cfg.Database.SourceRetention {
+		if err := sourceRepo.UpdateRetention(name, days); err != nil {
+			logger.Warn("Failed to set source retention", logger.Args("source", name, "error", err))
+			continue
+		}
+		logger.Debug("Source retention set", logger.Args("source", name, "retention_days", days))
+	}
+
 	// Run periodic discovery in background for late-arriving files
 	go func() {
 		ticker := time.NewTicker(5 * time.Minute) // Check every 5 minutes
@@ -205,8 +263,66 @@ func main() {
 	// Initialize real-time metrics collector with configured interval
 	logger.Info("Initializing real-time metrics collector...")
 	metricsCollector := realtime.NewMetricsCollector(db, logger)
+	metricsCollector.SetMaxBuffered(cfg.Performance.RealtimeMaxBuffered)
+	if err := metricsCollector.SetWindows(cfg.Performance.RealtimeBufferDuration, cfg.Performance.RealtimeRateWindow); err != nil {
+		logger.WithCaller().Fatal("Invalid real-time windows", logger.Args("error", err))
+	}
+	if cfg.Performance.RealtimePerSourceEnabled {
+		metricsCollector.EnablePerSourceBuffers(cfg.Performance.RealtimeMaxSourceBuffered)
+	}
 	metricsCollector.Start(cfg.Performance.RealtimeMetricsInterval)
 
+	// Threshold alerts on realtime metrics (optional)
+	var alertEngine *realtime.AlertEngine
+	if cfg.Alerts.Enabled {
+		var rules []realtime.AlertRule
+		if cfg.Alerts.ServerErrorRate > 0 {
+			rules = append(rules, realtime.AlertRule{
+				Name:      "high_5xx_rate",
+				Metric:    realtime.AlertMetricServerErrorRate,
+				Threshold: cfg.Alerts.ServerErrorRate,
+				For:       cfg.Alerts.ServerErrorDuration,
+			})
+		}
+		if cfg.Alerts.ErrorRate > 0 {
+			rules = append(rules, realtime.AlertRule{
+				Name:      "high_error_rate",
+				Metric:    realtime.AlertMetricErrorRate,
+				Threshold: cfg.Alerts.ErrorRate,
+				For:       cfg.Alerts.ErrorRateDuration,
+			})
+		}
+		if cfg.Alerts.ResponseTimeMs > 0 {
+			rules = append(rules, realtime.AlertRule{
+				Name:      "slow_responses",
+				Metric:    realtime.AlertMetricAvgResponseTime,
+				Threshold: cfg.Alerts.ResponseTimeMs,
+				For:       cfg.Alerts.ResponseTimeDuration,
+			})
+		}
+		if cfg.Alerts.TrafficStopDuration > 0 {
+			rules = append(rules, realtime.AlertRule{
+				Name:            "traffic_stopped",
+				Metric:          realtime.AlertMetricRequestRate,
+				Threshold:       0,
+				Below:           true,
+				For:             cfg.Alerts.TrafficStopDuration,
+				RequireActivity: true,
+			})
+		}
+
+		var notifiers []realtime.Notifier
+		if cfg.Alerts.WebhookURL != "" {
+			notifiers = append(notifiers, realtime.NewWebhookNotifier(cfg.Alerts.WebhookURL, cfg.Alerts.WebhookTimeout))
+		}
+
+		alertEngine = realtime.NewAlertEngine(logger, rules, cfg.Alerts.Cooldown, notifiers...)
+		alertEngine.SetBufferDuration(metricsCollector.BufferDuration())
+		metricsCollector.Subscribe(alertEngine.Observe)
+		logger.Info("Alert engine enabled",
+			logger.Args("rules", alertEngine.RuleCount(), "webhook", cfg.Alerts.WebhookURL != ""))
+	}
+
 	// Initialize ingestion coordinator with initial import limiting and performance config
 	// NOTE: Coordinator is initialized before cleanup service because cleanup needs to pause ingestion during VACUUM
 	logger.Debug("Initializing ingestion coordinator...")
@@ -225,6 +341,142 @@ func main() {
 
 	// Set processor pauser on httpRepo to enable coordinated pausing during index creation
 	httpRepo.SetProcessorPauser(coordinator)
+	coordinator.SetParseErrorLogInterval(cfg.Performance.ParseErrorLogInterval)
+	coordinator.SetBatchTimeout(cfg.Performance.BatchTimeout)
+	coordinator.SetMaxLineLength(cfg.Performance.MaxLineLength)
+	coordinator.SetPartialLineFlushDelay(cfg.Performance.PartialLineFlushDelay)
+	logger.Info("Ingestion tuning",
+		logger.Args(
+			"workers", cfg.Performance.WorkerPoolSize,
+			"batch_size", cfg.Performance.BatchSize,
+			"batch_timeout", cfg.Performance.BatchTimeout,
+		))
+
+	// Persisted per-source parse counters (parsed/failed/skipped/duplicate lines)
+	var parseStats *ingestion.ParseStatsRecorder
+	if cfg.ParseStats.Enabled {
+		parseStats = ingestion.NewParseStatsRecorder(
+			repositories.NewParseStatsRepository(db),
+			logger,
+			cfg.ParseStats.Bucket,
+			time.Duration(cfg.ParseStats.RetentionDays)*24*time.Hour,
+		)
+		parseStats.Start(cfg.ParseStats.FlushInterval)
+		coordinator.SetParseStatsRecorder(parseStats)
+	}
+
+	// Rejected lines go to the dead-letter log so silent ingestion gaps can be inspected
+	var deadLetter *ingestion.DeadLetterWriter
+	if cfg.DeadLetter.Path != "" {
+		writer, err := ingestion.NewDeadLetterWriter(
+			cfg.DeadLetter.Path,
+			int64(cfg.DeadLetter.MaxSizeMB)*1024*1024,
+			cfg.DeadLetter.RateLimit,
+			logger,
+		)
+		if err != nil {
+			logger.Warn("Dead-letter log disabled", logger.Args("path", cfg.DeadLetter.Path, "error", err))
+		} else {
+			deadLetter = writer
+			coordinator.SetDeadLetterWriter(deadLetter)
+			logger.Info("Dead-letter log enabled", logger.Args("path", cfg.DeadLetter.Path))
+		}
+	}
+
+	// Internal ranges are flagged per request so stats can hide them cheaply
+	excludeInternal := false
+	if cfg.Stats.InternalNetworks != "" {
+		networks, err := enrichment.NewInternalNetworks(strings.Split(cfg.Stats.InternalNetworks, ","))
+		if err != nil {
+			logger.Warn("Internal network flagging disabled: invalid INTERNAL_NETWORKS", logger.Args("error", err))
+		} else if networks.Len() > 0 {
+			coordinator.SetInternalNetworks(networks)
+			excludeInternal = true
+			logger.Info("Internal network flagging enabled", logger.Args("ranges", networks.Len()))
+		}
+	}
+
+	// Fields telling requests apart when deduplicating, for formats without sub-second timestamps or timings
+	if cfg.LogSources.DedupHashFields != "" {
+		fields, err := models.DefaultHashFields.With(cfg.LogSources.DedupHashFields)
+		if err != nil {
+			logger.Warn("Using the default request hash fields: invalid DEDUP_HASH_FIELDS", logger.Args("error", err))
+		} else {
+			coordinator.SetHashFields(fields)
+			logger.Info("Request hash fields configured", logger.Args("fields", cfg.LogSources.DedupHashFields))
+		}
+	}
+
+	// Sources with an http(s) URL path are polled over HTTP; headers usually carry credentials
+	remoteHeaders, err := ingestion.ParseRemoteHeaders(cfg.LogSources.RemoteHeaders)
+	if err != nil {
+		logger.Warn("Remote sources sent without headers: invalid REMOTE_SOURCE_HEADERS", logger.Args("error", err))
+		remoteHeaders = nil
+	}
+	coordinator.SetRemoteConfig(ingestion.RemoteConfig{
+		Headers:      remoteHeaders,
+		PollInterval: cfg.LogSources.RemotePollInterval,
+		Timeout:      cfg.LogSources.RemoteTimeout,
+	})
+
+	// Multi-hop setups log the last proxy's address; walk X-Forwarded-For past trusted hops
+	if cfg.LogSources.TrustedProxies != "" {
+		proxies, err := enrichment.NewTrustedProxies(strings.Split(cfg.LogSources.TrustedProxies, ","))
+		if err != nil {
+			logger.Warn("X-Forwarded-For resolution disabled: invalid TRUSTED_PROXIES", logger.Args("error", err))
+		} else if proxies.Len() > 0 {
+			coordinator.SetTrustedProxies(proxies)
+			logger.Info("X-Forwarded-For resolution enabled", logger.Args("trusted_proxies", proxies.Len()))
+		}
+	}
+
+	// Scanner-heavy networks can be dropped before they reach the database
+	if len(cfg.GeoIP.ExcludeASNs) > 0 {
+		if geoIP == nil || !geoIP.IsEnabled() {
+			logger.Warn("EXCLUDE_ASNS is set but GeoIP is disabled, no requests will be excluded")
+		} else {
+			coordinator.SetExcludedASNs(cfg.GeoIP.ExcludeASNs)
+			logger.Info("ASN exclusion enabled", logger.Args("asns", len(cfg.GeoIP.ExcludeASNs)))
+		}
+	}
+
+	// User-defined classification rules (optional)
+	if cfg.Classification.Rules != "" {
+		classifier, err := enrichment.NewClassifier(cfg.Classification.Rules, logger)
+		if err != nil {
+			logger.Warn("Request classification disabled: invalid rules", logger.Args("error", err))
+		} else {
+			coordinator.SetClassifier(classifier)
+			logger.Info("Request classification enabled", logger.Args("rules", classifier.RuleCount()))
+
+			// Reload rules on SIGHUP; invalid rules keep the current set
+			reloadChan := make(chan os.Signal, 1)
+			signal.Notify(reloadChan, syscall.SIGHUP)
+			go func() {
+				for range reloadChan {
+					if err := classifier.Reload(); err != nil {
+						logger.Warn("Failed to reload classification rules, keeping current rules", logger.Args("error", err))
+						continue
+					}
+					logger.Info("Classification rules reloaded", logger.Args("rules", classifier.RuleCount()))
+				}
+			}()
+		}
+	}
+
+	// Reverse DNS enrichment (optional - PTR lookups can be slow)
+	if cfg.ReverseDNS.Enabled {
+		coordinator.SetReverseDNS(enrichment.NewReverseDNSEnricher(
+			logger,
+			cfg.ReverseDNS.Timeout,
+			cfg.ReverseDNS.Workers,
+			cfg.ReverseDNS.CacheTTL,
+			cfg.ReverseDNS.NegativeCacheTTL,
+			cfg.ReverseDNS.CacheSize,
+		))
+		logger.Info("Reverse DNS enrichment enabled",
+			logger.Args("timeout", cfg.ReverseDNS.Timeout, "workers", cfg.ReverseDNS.Workers))
+	}
 
 	// Initialize database cleanup service with coordinator reference for maintenance windows
 	logger.Debug("Initializing database cleanup service...")
@@ -237,6 +489,15 @@ func main() {
 		cfg.Database.VacuumEnabled,
 		coordinator, // Pass coordinator to enable pause/resume during VACUUM
 	)
+	cleanupService.SetRetentionMode(cfg.Database.RetentionMode)
+	cleanupService.SetSchedule(cfg.Database.CleanupSchedule, cfg.Database.CleanupTimezone)
+	if cfg.Database.ReputationCleanup {
+		var reputationCache database.ReputationCache
+		if geoIP != nil {
+			reputationCache = geoIP
+		}
+		cleanupService.SetReputationCleanup(true, reputationCache)
+	}
 	cleanupService.Start()
 
 	// Start ingestion engine
@@ -264,7 +525,11 @@ func main() {
 	// Initialize web server with configured settings
 	logger.Info("Initializing web server...")
 	dashboardHandler := handlers.NewDashboardHandler(statsRepo, httpRepo, logger)
-	realtimeHandler := handlers.NewRealtimeHandler(metricsCollector, logger)
+	realtimeHandler := handlers.NewRealtimeHandler(metricsCollector, logger, cfg.Server.RealtimeBinary)
+	realtimeHandler.SetAlertEngine(alertEngine)
+	realtimeHandler.SetMaxConnections(cfg.Server.RealtimeMaxConns)
+	dashboardHandler.SetExcludeInternal(excludeInternal)
+	realtimeHandler.SetExcludeInternal(excludeInternal)
 	systemHandler := handlers.NewSystemHandler(
 		statsRepo,
 		httpRepo,
@@ -273,7 +538,59 @@ func main() {
 		cfg.Database.Path,
 		cfg.Database.RetentionDays,
 	)
+	systemHandler.SetParseStats(parseStats)
+	systemHandler.SetDeadLetter(deadLetter)
+	systemHandler.SetProcessors(coordinator)
+	systemHandler.SetSources(coordinator)
+	systemHandler.SetConfigSnapshot(cfg.Redacted())
+	systemHandler.SetDatabasePool(db, cfg.Database.PoolSaturationThreshold)
+	if cfg.Performance.MemoryBreakdown {
+		systemHandler.SetMemoryBreakdown(geoIP, metricsCollector)
+	}
 	ipTagHandler := handlers.NewIPTagHandler(ipTagRepo, logger)
+	var metricsHandler *handlers.MetricsHandler
+	if cfg.Server.MetricsEnabled {
+		metricsHandler = handlers.NewMetricsHandler(
+			coordinator,
+			metricsCollector,
+			geoIP,
+			db,
+			cfg.Database.PoolSaturationThreshold,
+			logger,
+		)
+	}
+	var discoveryHandler *handlers.DiscoveryHandler
+	if cfg.Server.DiscoverEndpoint {
+		if cfg.Server.AdminToken == "" {
+			logger.Warn("Discover endpoint requires ADMIN_TOKEN, leaving it disabled")
+		} else {
+			discoveryHandler = handlers.NewDiscoveryHandler(discoveryEngine, coordinator, logger)
+		}
+	}
+	var replayer *realtime.Replayer
+	var replayHandler *handlers.ReplayHandler
+	if cfg.Server.ReplayEndpoint {
+		if cfg.Server.AdminToken == "" {
+			logger.Warn("Replay endpoint requires ADMIN_TOKEN, leaving it disabled")
+		} else {
+			replayer = realtime.NewReplayer(metricsCollector, httpRepo, logger)
+			replayHandler = handlers.NewReplayHandler(replayer, logger)
+		}
+	}
+	var ingestHandler *handlers.IngestHandler
+	if cfg.Server.IngestEndpoint {
+		if cfg.Server.AdminToken == "" {
+			logger.Warn("Ingest endpoint requires ADMIN_TOKEN, leaving it disabled")
+		} else {
+			ingestHandler = handlers.NewIngestHandler(coordinator, cfg.Server.IngestMaxBodyBytes, logger)
+		}
+	}
+	healthHandler := handlers.NewHealthHandler(db, coordinator, logger)
+	if cfg.Server.PprofEnabled && cfg.Server.PprofContention {
+		// Sample blocking of 10µs or more and one in 100 mutex contentions for the block/mutex profiles
+		runtime.SetBlockProfileRate(int(10 * time.Microsecond))
+		runtime.SetMutexProfileFraction(100)
+	}
 	webServer := api.NewServer(&api.Config{
 		Host:                cfg.Server.Host,
 		Port:                cfg.Server.Port,
@@ -283,7 +600,14 @@ func main() {
 		TimeZone:            cfg.Server.TimeZone,
 		WidgetEnabled:       cfg.Server.WidgetEnabled,
 		HasExistingData:     httpRepo.HasExistingData(),
-	}, dashboardHandler, realtimeHandler, systemHandler, ipTagHandler, logger)
+		BasePath:            cfg.Server.BasePath,
+		AdminToken:          cfg.Server.AdminToken,
+		MetricsRequireToken: cfg.Server.MetricsRequireToken,
+		PprofEnabled:        cfg.Server.PprofEnabled,
+		PprofRequireToken:   cfg.Server.PprofRequireToken,
+		ClientIPHeaders:     cfg.Server.ClientIPHeaders,
+		TrustedProxies:      cfg.Server.TrustedProxies,
+	}, dashboardHandler, realtimeHandler, systemHandler, ipTagHandler, metricsHandler, discoveryHandler, replayHandler, ingestHandler, healthHandler, logger)
 
 	// Start web server in goroutine
 	go func() {
@@ -294,7 +618,7 @@ func main() {
 
 	logger.Info("LogLynx is running",
 		logger.Args(
-			"url", pterm.Sprintf("http://localhost:%d", cfg.Server.Port),
+			"url", pterm.Sprintf("http://localhost:%d%s/", cfg.Server.Port, api.NormalizeBasePath(cfg.Server.BasePath)),
 			"processors", coordinator.GetProcessorCount(),
 		))
 
@@ -327,10 +651,23 @@ func main() {
 	logger.Debug("Stopping ingestion coordinator...")
 	coordinator.Stop()
 
+	// Persist the remaining parse counters
+	if parseStats != nil {
+		parseStats.Stop()
+	}
+	if deadLetter != nil {
+		deadLetter.Close()
+	}
+
 	// Stop cleanup service
 	logger.Debug("Stopping cleanup service...")
 	cleanupService.Stop()
 
+	// Stop feeding replayed requests into the real-time buffer
+	if replayer != nil {
+		replayer.Stop()
+	}
+
 	// Signal real-time streams to close immediately (prevents shutdown delays)
 	logger.Debug("Closing active real-time streams...")
 	realtimeHandler.Shutdown()
@@ -348,9 +685,34 @@ func main() {
 	}
 
 	// Close GeoIP
+	if geoIPUpdater != nil {
+		geoIPUpdater.Stop()
+	}
 	if geoIP != nil {
 		geoIP.Close()
 	}
 
 	logger.Info("LogLynx stopped gracefully")
 }
+
+// applySourcesFile loads LOG_SOURCES_FILE and reconciles the managed sources.
+// Nothing is changed when the file is invalid or names an unknown parser.
+func applySourcesFile(value string, engine *discovery.Engine, registry *parsers.Registry, logger *pterm.Logger) error {
+	defs, err := discovery.LoadSourceDefinitions(value)
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		if _, err := registry.Get(def.Parser); err != nil {
+			return fmt.Errorf("source %q: %w", def.Name, err)
+		}
+	}
+
+	result, err := engine.ApplySourceDefinitions(defs, logger)
+	if err != nil {
+		return err
+	}
+	logger.Info("Sources file applied",
+		logger.Args("created", result.Created, "updated", result.Updated, "removed", result.Removed, "unchanged", result.Unchanged))
+	return nil
+}
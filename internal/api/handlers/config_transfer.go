@@ -0,0 +1,114 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"loglynx/internal/database"
+	"loglynx/internal/ingestion"
+	"loglynx/internal/version"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigExport is the instance state returned by ExportConfig and restored by ImportConfig
+type ConfigExport struct {
+	Version    string                  `json:"version"`
+	ExportedAt time.Time               `json:"exported_at"`
+	Retention  RetentionSettings       `json:"retention"`
+	Sources    []ingestion.SourceState `json:"sources"`
+	Config     any                     `json:"config,omitempty"` // Effective configuration with secrets redacted
+}
+
+// RetentionSettings is the global retention and the resolved retention of each source
+type RetentionSettings struct {
+	RetentionDays int                        `json:"retention_days"` // 0 = unlimited
+	RetentionMode string                     `json:"retention_mode,omitempty"`
+	Sources       []database.SourceRetention `json:"sources,omitempty"`
+}
+
+// SetConfigSnapshot attaches the effective configuration included in ExportConfig.
+// It is serialized as is, so secrets must already be redacted.
+func (h *SystemHandler) SetConfigSnapshot(config any) {
+	h.config = config
+}
+
+// ExportConfig returns the registered log sources with their read positions, the
+// retention settings and the effective configuration, to back up the instance or move
+// it to another host without ingesting the logs again
+func (h *SystemHandler) ExportConfig(c *gin.Context) {
+	if h.sources == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log source management is unavailable"})
+		return
+	}
+
+	sources, err := h.sources.ExportSources()
+	if err != nil {
+		h.logger.WithCaller().Error("Failed to export log sources", h.logger.Args("error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export log sources"})
+		return
+	}
+
+	export := ConfigExport{
+		Version:    version.Version,
+		ExportedAt: time.Now().UTC(),
+		Retention:  RetentionSettings{RetentionDays: h.retentionDays},
+		Sources:    sources,
+		Config:     h.config,
+	}
+	if h.cleanupService != nil {
+		cleanupStats := h.cleanupService.GetStats()
+		export.Retention.RetentionMode = cleanupStats.RetentionMode
+		export.Retention.Sources = cleanupStats.SourceRetention
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=loglynx-config.json")
+	c.JSON(http.StatusOK, export)
+}
+
+// ImportConfig restores the log sources of a ConfigExport with their retention and read
+// positions. The configuration and global retention come from the environment and are
+// not applied: they are exported for reference only.
+func (h *SystemHandler) ImportConfig(c *gin.Context) {
+	if h.sources == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log source management is unavailable"})
+		return
+	}
+
+	var export ConfigExport
+	if err := c.ShouldBindJSON(&export); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration export: " + err.Error()})
+		return
+	}
+
+	result, err := h.sources.ImportSources(export.Sources)
+	if err != nil {
+		h.logger.WithCaller().Error("Failed to import log sources", h.logger.Args("error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import log sources"})
+		return
+	}
+	h.logger.Info("Imported configuration",
+		h.logger.Args("created", len(result.Created), "updated", len(result.Updated), "skipped", len(result.Skipped)))
+	c.JSON(http.StatusOK, result)
+}
@@ -25,13 +25,18 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"loglynx/internal/database/models"
 	"loglynx/internal/database/repositories"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pterm/pterm"
+	"gorm.io/gorm"
 )
 
 // DashboardHandler handles dashboard data requests
@@ -39,6 +44,8 @@ type DashboardHandler struct {
 	statsRepo   repositories.StatsRepository
 	requestRepo repositories.HTTPRequestRepository
 	logger      *pterm.Logger
+
+	excludeInternal bool // Hide INTERNAL_NETWORKS traffic unless include_internal=true
 }
 
 // NewDashboardHandler creates a new dashboard handler
@@ -50,6 +57,16 @@ func NewDashboardHandler(statsRepo repositories.StatsRepository, requestRepo rep
 	}
 }
 
+// SetExcludeInternal hides requests from internal networks by default
+func (h *DashboardHandler) SetExcludeInternal(exclude bool) {
+	h.excludeInternal = exclude
+}
+
+// hideInternal reports whether internal traffic should be excluded for this request
+func (h *DashboardHandler) hideInternal(c *gin.Context) bool {
+	return h.excludeInternal && c.Query("include_internal") != "true"
+}
+
 // ServiceFilter is a local struct for handlers, converted to repositories.ServiceFilter
 type ServiceFilter struct {
 	Name string `json:"name"`
@@ -75,9 +92,18 @@ type updateComparisonSnapshotRequest struct {
 const comparisonOwnerCookie = "loglynx_compare_owner"
 
 // getServiceFilter extracts service filter from request
+// The legacy host parameter matches the host column unless service_type says otherwise
 func (h *DashboardHandler) getServiceFilter(c *gin.Context) (string, string) {
 	service := c.Query("service")
 	serviceType := c.Query("service_type")
+	if service == "" {
+		if host := c.Query("host"); host != "" {
+			service = host
+			if serviceType == "" {
+				serviceType = "host"
+			}
+		}
+	}
 	return service, serviceType
 }
 
@@ -146,9 +172,13 @@ func (h *DashboardHandler) getExcludeOwnIP(c *gin.Context) (enabled bool, client
 
 	excludeOwn := c.Query("exclude_own_ip") == "true"
 
-	allIPs := manualIPs
+	// Stored client IPs are normalized, so "::ffff:1.2.3.4" must exclude "1.2.3.4"
+	allIPs := make([]string, 0, len(manualIPs)+1)
+	for _, ip := range manualIPs {
+		allIPs = append(allIPs, models.NormalizeIP(ip))
+	}
 	if excludeOwn {
-		allIPs = append(allIPs, c.ClientIP())
+		allIPs = append(allIPs, models.NormalizeIP(c.ClientIP()))
 	}
 
 	if len(allIPs) == 0 {
@@ -176,13 +206,15 @@ func (h *DashboardHandler) getExcludeOwnIP(c *gin.Context) (enabled bool, client
 // buildExcludeIPFilter builds ExcludeIPFilter from request
 func (h *DashboardHandler) buildExcludeIPFilter(c *gin.Context) *repositories.ExcludeIPFilter {
 	excludeIPEnabled, clientIPs, excludeServices := h.getExcludeOwnIP(c)
-	if !excludeIPEnabled {
+	excludeInternal := h.hideInternal(c)
+	if !excludeIPEnabled && !excludeInternal {
 		return nil
 	}
 
 	return &repositories.ExcludeIPFilter{
 		ClientIPs:       clientIPs,
 		ExcludeServices: h.convertToRepoFilters(excludeServices),
+		ExcludeInternal: excludeInternal,
 	}
 }
 
@@ -206,7 +238,7 @@ func generateComparisonOwnerID() string {
 
 // getHours extracts hours parameter from request, defaulting to 168 (7 days)
 func (h *DashboardHandler) getHours(c *gin.Context) int {
-	hours := 168
+	hours := repositories.DefaultLookbackHours
 	if hoursParam := c.Query("hours"); hoursParam != "" {
 		if val, err := strconv.Atoi(hoursParam); err == nil && val >= 0 {
 			hours = val
@@ -219,9 +251,75 @@ func (h *DashboardHandler) getHours(c *gin.Context) int {
 	return hours
 }
 
+// getOffsetHours extracts the offsetHours parameter, which shifts the window back from now
+func (h *DashboardHandler) getOffsetHours(c *gin.Context) int {
+	offset := 0
+	if offsetParam := c.Query("offsetHours"); offsetParam != "" {
+		if val, err := strconv.Atoi(offsetParam); err == nil && val >= 0 {
+			offset = val
+		}
+	}
+	if offset > 8760 {
+		offset = 8760
+	}
+	return offset
+}
+
+// stats returns the stats repository, with windows ending at now minus offsetHours when set
+func (h *DashboardHandler) stats(c *gin.Context) repositories.StatsRepository {
+	if offset := h.getOffsetHours(c); offset > 0 {
+		return h.statsRepo.WithTimeOffset(time.Duration(offset) * time.Hour)
+	}
+	return h.statsRepo
+}
+
+// topStats is stats narrowed to the statusClass or statusMin/statusMax parameters, for the top-N lists.
+// It writes a 400 response and returns false when those parameters are invalid.
+func (h *DashboardHandler) topStats(c *gin.Context) (repositories.StatsRepository, bool) {
+	statusMin, statusMax, err := getStatusRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	stats := h.stats(c)
+	if statusMin > 0 || statusMax > 0 {
+		stats = stats.WithStatusRange(statusMin, statusMax)
+	}
+	return stats, true
+}
+
+// getStatusRange reads statusClass (2xx..5xx) or the inclusive statusMin/statusMax bounds.
+// 0 means the bound is not set.
+func getStatusRange(c *gin.Context) (int, int, error) {
+	if class := strings.ToLower(c.Query("statusClass")); class != "" {
+		if len(class) != 3 || !strings.HasSuffix(class, "xx") || class[0] < '1' || class[0] > '5' {
+			return 0, 0, fmt.Errorf("invalid statusClass: expected 1xx, 2xx, 3xx, 4xx or 5xx")
+		}
+		base := int(class[0]-'0') * 100
+		return base, base + 99, nil
+	}
+
+	bounds := [2]int{}
+	for i, name := range []string{"statusMin", "statusMax"} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		val, err := strconv.Atoi(raw)
+		if err != nil || val < 100 || val > 599 {
+			return 0, 0, fmt.Errorf("invalid %s: expected a status code between 100 and 599", name)
+		}
+		bounds[i] = val
+	}
+	if bounds[0] > 0 && bounds[1] > 0 && bounds[0] > bounds[1] {
+		return 0, 0, fmt.Errorf("statusMin must not be greater than statusMax")
+	}
+	return bounds[0], bounds[1], nil
+}
+
 // GetSummary returns overall statistics
 func (h *DashboardHandler) GetSummary(c *gin.Context) {
-	summary, err := h.statsRepo.GetSummary(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	summary, err := h.stats(c).GetSummary(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get summary"})
 		return
@@ -231,7 +329,7 @@ func (h *DashboardHandler) GetSummary(c *gin.Context) {
 
 // GetTimeline returns timeline statistics
 func (h *DashboardHandler) GetTimeline(c *gin.Context) {
-	timeline, err := h.statsRepo.GetTimelineStats(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	timeline, err := h.stats(c).GetTimelineStats(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timeline"})
 		return
@@ -239,9 +337,19 @@ func (h *DashboardHandler) GetTimeline(c *gin.Context) {
 	c.JSON(http.StatusOK, timeline)
 }
 
+// GetBandwidthTimeline returns inbound and outbound bytes over time
+func (h *DashboardHandler) GetBandwidthTimeline(c *gin.Context) {
+	timeline, err := h.stats(c).GetBandwidthTimeline(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bandwidth timeline"})
+		return
+	}
+	c.JSON(http.StatusOK, timeline)
+}
+
 // GetStatusCodeTimeline returns status code distribution over time
 func (h *DashboardHandler) GetStatusCodeTimeline(c *gin.Context) {
-	timeline, err := h.statsRepo.GetStatusCodeTimeline(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	timeline, err := h.stats(c).GetStatusCodeTimeline(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get status code timeline"})
 		return
@@ -249,6 +357,35 @@ func (h *DashboardHandler) GetStatusCodeTimeline(c *gin.Context) {
 	c.JSON(http.StatusOK, timeline)
 }
 
+// GetUniqueVisitorTimeline returns distinct visitors per bucket with new/returning splits and a running total
+// Optional host parameter narrows it to one site
+func (h *DashboardHandler) GetUniqueVisitorTimeline(c *gin.Context) {
+	timeline, err := h.stats(c).GetUniqueVisitorTimeline(h.getHours(c), c.Query("host"), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get unique visitor timeline"})
+		return
+	}
+	c.JSON(http.StatusOK, timeline)
+}
+
+// GetConcurrencyTimeline returns estimated concurrent requests over time.
+// The optional bucket parameter sets the bucket size in seconds.
+func (h *DashboardHandler) GetConcurrencyTimeline(c *gin.Context) {
+	var bucket time.Duration
+	if bucketParam := c.Query("bucket"); bucketParam != "" {
+		if val, err := strconv.Atoi(bucketParam); err == nil && val >= 60 {
+			bucket = time.Duration(val) * time.Second
+		}
+	}
+
+	timeline, err := h.stats(c).GetConcurrencyTimeline(h.getHours(c), bucket, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get concurrency timeline"})
+		return
+	}
+	c.JSON(http.StatusOK, timeline)
+}
+
 // GetTrafficHeatmap returns traffic heatmap data
 func (h *DashboardHandler) GetTrafficHeatmap(c *gin.Context) {
 	// Heatmap defaults to 30 days
@@ -267,7 +404,41 @@ func (h *DashboardHandler) GetTrafficHeatmap(c *gin.Context) {
 	c.JSON(http.StatusOK, heatmap)
 }
 
-// GetTopPaths returns most accessed paths
+// GetPeakTraffic returns the busiest time buckets for capacity planning
+// Query params: granularity (minute, hour, day; default hour), days (default 30, 0 = all time), limit (default 10)
+func (h *DashboardHandler) GetPeakTraffic(c *gin.Context) {
+	granularity := c.DefaultQuery("granularity", "hour")
+	if !repositories.IsValidPeakGranularity(granularity) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid granularity, expected minute, hour or day"})
+		return
+	}
+
+	days := 30
+	if daysParam := c.Query("days"); daysParam != "" {
+		if val, err := strconv.Atoi(daysParam); err == nil && val >= 0 {
+			days = val
+		}
+	}
+	if days > 365 {
+		days = 365
+	}
+
+	limit := 10
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+
+	peaks, err := h.stats(c).GetPeakTraffic(granularity, days, limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get peak traffic"})
+		return
+	}
+	c.JSON(http.StatusOK, peaks)
+}
+
+// GetTopPaths returns the top paths by hits, bandwidth or average response time (sort)
 func (h *DashboardHandler) GetTopPaths(c *gin.Context) {
 	limit := 10
 	if limitParam := c.Query("limit"); limitParam != "" {
@@ -276,7 +447,11 @@ func (h *DashboardHandler) GetTopPaths(c *gin.Context) {
 		}
 	}
 
-	paths, err := h.statsRepo.GetTopPaths(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	paths, err := stats.GetTopPaths(h.getHours(c), limit, c.DefaultQuery("sort", repositories.PathOrderHits), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top paths"})
 		return
@@ -284,6 +459,64 @@ func (h *DashboardHandler) GetTopPaths(c *gin.Context) {
 	c.JSON(http.StatusOK, paths)
 }
 
+// GetPathFlows returns the most common path transitions per client
+func (h *DashboardHandler) GetPathFlows(c *gin.Context) {
+	window := 60
+	if windowParam := c.Query("window"); windowParam != "" {
+		if val, err := strconv.Atoi(windowParam); err == nil && val > 0 {
+			window = val
+		}
+	}
+
+	limit := 10
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+
+	flows, err := h.statsRepo.GetPathSequences(window, limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get path flows"})
+		return
+	}
+	c.JSON(http.StatusOK, flows)
+}
+
+// GetAuthAbuse returns IPs showing credential-stuffing patterns on login paths
+func (h *DashboardHandler) GetAuthAbuse(c *gin.Context) {
+	window := 60
+	if windowParam := c.Query("window"); windowParam != "" {
+		if val, err := strconv.Atoi(windowParam); err == nil && val > 0 {
+			window = val
+		}
+	}
+
+	abusers, err := h.statsRepo.GetAuthAbuse(window)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get auth abuse stats"})
+		return
+	}
+	c.JSON(http.StatusOK, abusers)
+}
+
+// GetSuspiciousIPs returns client IPs scored by request rate, 4xx ratio and path variety
+func (h *DashboardHandler) GetSuspiciousIPs(c *gin.Context) {
+	limit := 20
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+
+	ips, err := h.stats(c).GetSuspiciousIPs(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get suspicious IPs"})
+		return
+	}
+	c.JSON(http.StatusOK, ips)
+}
+
 // GetTopCountries returns top countries
 func (h *DashboardHandler) GetTopCountries(c *gin.Context) {
 	limit := 10
@@ -293,7 +526,11 @@ func (h *DashboardHandler) GetTopCountries(c *gin.Context) {
 		}
 	}
 
-	countries, err := h.statsRepo.GetTopCountries(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	countries, err := stats.GetTopCountries(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top countries"})
 		return
@@ -301,6 +538,37 @@ func (h *DashboardHandler) GetTopCountries(c *gin.Context) {
 	c.JSON(http.StatusOK, countries)
 }
 
+// Geo heatmap grid bounds in degrees
+const (
+	minGeoGridSize = 0.1
+	maxGeoGridSize = 45.0
+)
+
+// GetGeoHeatmap returns geolocated request counts aggregated on a lat/lon grid
+// Query params: grid (cell size in degrees, default 1, 0.1-45)
+func (h *DashboardHandler) GetGeoHeatmap(c *gin.Context) {
+	gridSize := repositories.DefaultGeoGridSize
+	if gridParam := c.Query("grid"); gridParam != "" {
+		val, err := strconv.ParseFloat(gridParam, 64)
+		if err != nil || val < minGeoGridSize || val > maxGeoGridSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grid, expected a cell size between 0.1 and 45 degrees"})
+			return
+		}
+		gridSize = val
+	}
+
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	cells, err := stats.GetGeoHeatmap(h.getHours(c), gridSize, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get geo heatmap"})
+		return
+	}
+	c.JSON(http.StatusOK, cells)
+}
+
 // GetTopIPs returns most active IP addresses
 func (h *DashboardHandler) GetTopIPs(c *gin.Context) {
 	limit := 10
@@ -335,7 +603,11 @@ func (h *DashboardHandler) GetTopIPs(c *gin.Context) {
 		ipFilter = nil
 	}
 
-	ips, err := h.statsRepo.GetTopIPAddresses(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c), tagFilter, ipFilter)
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	ips, err := stats.GetTopIPAddresses(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c), tagFilter, ipFilter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top IPs"})
 		return
@@ -345,7 +617,7 @@ func (h *DashboardHandler) GetTopIPs(c *gin.Context) {
 
 // GetStatusCodeDistribution returns status code distribution
 func (h *DashboardHandler) GetStatusCodeDistribution(c *gin.Context) {
-	stats, err := h.statsRepo.GetStatusCodeDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, err := h.stats(c).GetStatusCodeDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get status code distribution"})
 		return
@@ -355,7 +627,7 @@ func (h *DashboardHandler) GetStatusCodeDistribution(c *gin.Context) {
 
 // GetMethodDistribution returns HTTP method distribution
 func (h *DashboardHandler) GetMethodDistribution(c *gin.Context) {
-	stats, err := h.statsRepo.GetMethodDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, err := h.stats(c).GetMethodDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get method distribution"})
 		return
@@ -363,9 +635,46 @@ func (h *DashboardHandler) GetMethodDistribution(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetMethodStatusMatrix returns request counts per HTTP method and status class
+func (h *DashboardHandler) GetMethodStatusMatrix(c *gin.Context) {
+	stats, err := h.stats(c).GetMethodStatusMatrix(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get method/status matrix"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetResponseSizeHistogram returns request counts per response size range
+// Query params: bounds (optional, ascending comma-separated byte limits between buckets)
+func (h *DashboardHandler) GetResponseSizeHistogram(c *gin.Context) {
+	var bounds []int64
+	if boundsParam := c.Query("bounds"); boundsParam != "" {
+		for _, part := range strings.Split(boundsParam, ",") {
+			bound, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil || bound <= 0 || (len(bounds) > 0 && bound <= bounds[len(bounds)-1]) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bounds, expected ascending positive byte sizes"})
+				return
+			}
+			bounds = append(bounds, bound)
+		}
+		if len(bounds) > repositories.MaxResponseSizeBounds {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d bounds are allowed", repositories.MaxResponseSizeBounds)})
+			return
+		}
+	}
+
+	histogram, err := h.stats(c).GetResponseSizeHistogram(h.getHours(c), bounds, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get response size histogram"})
+		return
+	}
+	c.JSON(http.StatusOK, histogram)
+}
+
 // GetProtocolDistribution returns HTTP protocol distribution
 func (h *DashboardHandler) GetProtocolDistribution(c *gin.Context) {
-	stats, err := h.statsRepo.GetProtocolDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, err := h.stats(c).GetProtocolDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get protocol distribution"})
 		return
@@ -375,7 +684,7 @@ func (h *DashboardHandler) GetProtocolDistribution(c *gin.Context) {
 
 // GetTLSVersionDistribution returns TLS version distribution
 func (h *DashboardHandler) GetTLSVersionDistribution(c *gin.Context) {
-	stats, err := h.statsRepo.GetTLSVersionDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, err := h.stats(c).GetTLSVersionDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get TLS version distribution"})
 		return
@@ -383,6 +692,27 @@ func (h *DashboardHandler) GetTLSVersionDistribution(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetTLSSecurityStats returns how much traffic uses secure, weak or no TLS
+// Optional host parameter narrows it to one site
+func (h *DashboardHandler) GetTLSSecurityStats(c *gin.Context) {
+	stats, err := h.stats(c).GetTLSSecurityStats(h.getHours(c), c.Query("host"), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get TLS security stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetContentTypeDistribution returns requests and bandwidth per response content type
+func (h *DashboardHandler) GetContentTypeDistribution(c *gin.Context) {
+	stats, err := h.stats(c).GetContentTypeDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get content type distribution"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
 // GetTopUserAgents returns most common user agents
 func (h *DashboardHandler) GetTopUserAgents(c *gin.Context) {
 	limit := 10
@@ -392,7 +722,11 @@ func (h *DashboardHandler) GetTopUserAgents(c *gin.Context) {
 		}
 	}
 
-	agents, err := h.statsRepo.GetTopUserAgents(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	agents, err := stats.GetTopUserAgents(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top user agents"})
 		return
@@ -400,6 +734,70 @@ func (h *DashboardHandler) GetTopUserAgents(c *gin.Context) {
 	c.JSON(http.StatusOK, agents)
 }
 
+// GetTopOriginServers returns the origin software behind the most requests
+func (h *DashboardHandler) GetTopOriginServers(c *gin.Context) {
+	limit := 10
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	servers, err := stats.GetTopOriginServers(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top origin servers"})
+		return
+	}
+	c.JSON(http.StatusOK, servers)
+}
+
+// GetSlowRequests returns the slowest requests above a response time threshold.
+// The threshold query parameter is in milliseconds and defaults to 1000 (the idx_slow cutoff).
+func (h *DashboardHandler) GetSlowRequests(c *gin.Context) {
+	limit := 50
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+
+	threshold := float64(repositories.SlowRequestIndexThresholdMs)
+	if thresholdParam := c.Query("threshold"); thresholdParam != "" {
+		if val, err := strconv.ParseFloat(thresholdParam, 64); err == nil && val > 0 {
+			threshold = val
+		}
+	}
+
+	requests, err := h.stats(c).GetSlowRequests(h.getHours(c), threshold, limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get slow requests"})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetErrorAnalysis returns the paths with the most failed requests, with their status mix
+// and most recent failure
+func (h *DashboardHandler) GetErrorAnalysis(c *gin.Context) {
+	limit := 20
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+
+	paths, err := h.stats(c).GetErrorAnalysis(h.getHours(c), c.Query("host"), limit, h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get error analysis"})
+		return
+	}
+	c.JSON(http.StatusOK, paths)
+}
+
 // GetTopBrowsers returns most common browsers
 func (h *DashboardHandler) GetTopBrowsers(c *gin.Context) {
 	limit := 10
@@ -409,7 +807,11 @@ func (h *DashboardHandler) GetTopBrowsers(c *gin.Context) {
 		}
 	}
 
-	browsers, err := h.statsRepo.GetTopBrowsers(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	browsers, err := stats.GetTopBrowsers(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top browsers"})
 		return
@@ -426,7 +828,11 @@ func (h *DashboardHandler) GetTopOperatingSystems(c *gin.Context) {
 		}
 	}
 
-	osList, err := h.statsRepo.GetTopOperatingSystems(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	osList, err := stats.GetTopOperatingSystems(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top operating systems"})
 		return
@@ -436,7 +842,7 @@ func (h *DashboardHandler) GetTopOperatingSystems(c *gin.Context) {
 
 // GetDeviceTypeDistribution returns distribution of device types
 func (h *DashboardHandler) GetDeviceTypeDistribution(c *gin.Context) {
-	stats, err := h.statsRepo.GetDeviceTypeDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, err := h.stats(c).GetDeviceTypeDistribution(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get device type distribution"})
 		return
@@ -444,6 +850,54 @@ func (h *DashboardHandler) GetDeviceTypeDistribution(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetClientProfiles returns the most common browser, OS and device type combinations
+func (h *DashboardHandler) GetClientProfiles(c *gin.Context) {
+	limit := 10
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+
+	profiles, err := h.stats(c).GetClientProfiles(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get client profiles"})
+		return
+	}
+	c.JSON(http.StatusOK, profiles)
+}
+
+// GetTrafficByLabel returns traffic grouped by user-defined classification label
+func (h *DashboardHandler) GetTrafficByLabel(c *gin.Context) {
+	labels, err := h.stats(c).GetTrafficByLabel(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get traffic by label"})
+		return
+	}
+	c.JSON(http.StatusOK, labels)
+}
+
+// GetBotTrafficStats returns the bot vs human split of traffic
+// Query params: breakdown (true adds the busiest bots by name), limit (bots listed, default 10)
+func (h *DashboardHandler) GetBotTrafficStats(c *gin.Context) {
+	botLimit := 0
+	if c.Query("breakdown") == "true" {
+		botLimit = 10
+		if limitParam := c.Query("limit"); limitParam != "" {
+			if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+				botLimit = val
+			}
+		}
+	}
+
+	stats, err := h.stats(c).GetBotTrafficStats(h.getHours(c), botLimit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bot traffic stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
 // GetTopASNs returns top ASNs
 func (h *DashboardHandler) GetTopASNs(c *gin.Context) {
 	limit := 10
@@ -453,7 +907,11 @@ func (h *DashboardHandler) GetTopASNs(c *gin.Context) {
 		}
 	}
 
-	asns, err := h.statsRepo.GetTopASNs(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	asns, err := stats.GetTopASNs(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top ASNs"})
 		return
@@ -470,7 +928,11 @@ func (h *DashboardHandler) GetTopBackends(c *gin.Context) {
 		}
 	}
 
-	backends, err := h.statsRepo.GetTopBackends(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	backends, err := stats.GetTopBackends(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top backends"})
 		return
@@ -487,7 +949,11 @@ func (h *DashboardHandler) GetTopReferrers(c *gin.Context) {
 		}
 	}
 
-	referrers, err := h.statsRepo.GetTopReferrers(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	referrers, err := stats.GetTopReferrers(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top referrers"})
 		return
@@ -504,7 +970,11 @@ func (h *DashboardHandler) GetTopReferrerDomains(c *gin.Context) {
 		}
 	}
 
-	domains, err := h.statsRepo.GetTopReferrerDomains(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, ok := h.topStats(c)
+	if !ok {
+		return
+	}
+	domains, err := stats.GetTopReferrerDomains(h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get top referrer domains"})
 		return
@@ -512,9 +982,68 @@ func (h *DashboardHandler) GetTopReferrerDomains(c *gin.Context) {
 	c.JSON(http.StatusOK, domains)
 }
 
+// GetReferrerCategories returns requests split into search, social, direct, internal and other referrers
+// Query params: host (optional, the site whose self-referrals count as internal)
+func (h *DashboardHandler) GetReferrerCategories(c *gin.Context) {
+	categories, err := h.stats(c).GetReferrerCategories(h.getHours(c), c.Query("host"), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get referrer categories"})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// GetProxyOverheadStats returns, per backend, the response time added by the proxy on top of the upstream time
+// Optional host parameter narrows it to one site
+func (h *DashboardHandler) GetProxyOverheadStats(c *gin.Context) {
+	stats, err := h.stats(c).GetProxyOverheadStats(h.getHours(c), c.Query("host"), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get proxy overhead stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetVisitorReturnStats returns how many visitors of the window are new and how many returned
+func (h *DashboardHandler) GetVisitorReturnStats(c *gin.Context) {
+	stats, err := h.stats(c).GetVisitorReturnStats(h.getHours(c), c.Query("host"), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get visitor return stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetRetriedRequests returns the upstream retry rate, the final status by retry count and the most retried paths
+func (h *DashboardHandler) GetRetriedRequests(c *gin.Context) {
+	limit := 20
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+
+	stats, err := h.stats(c).GetRetriedRequests(h.getHours(c), c.Query("host"), limit, h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get retry stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetBackendBandwidthProjection returns the daily bandwidth of each backend and its 30-day projection
+func (h *DashboardHandler) GetBackendBandwidthProjection(c *gin.Context) {
+	projection, err := h.stats(c).GetBackendBandwidthProjection(h.getHours(c), c.Query("host"), h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get backend bandwidth projection"})
+		return
+	}
+	c.JSON(http.StatusOK, projection)
+}
+
 // GetResponseTimeStats returns response time statistics
 func (h *DashboardHandler) GetResponseTimeStats(c *gin.Context) {
-	stats, err := h.statsRepo.GetResponseTimeStats(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
+	stats, err := h.stats(c).GetResponseTimeStats(h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)), h.buildExcludeIPFilter(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get response time stats"})
 		return
@@ -552,6 +1081,64 @@ func (h *DashboardHandler) GetComparison(c *gin.Context) {
 	c.JSON(http.StatusOK, comparison)
 }
 
+// defaultComparisonWindow is the current window of GetSummaryComparison when start is omitted
+const defaultComparisonWindow = 7 * 24 * time.Hour
+
+// GetSummaryComparison compares the summary of a window with an earlier one, e.g. this
+// week against last week. Without previousStart/previousEnd the previous window is the
+// one of the same length right before the current window.
+func (h *DashboardHandler) GetSummaryComparison(c *gin.Context) {
+	end, err := parseTimeQuery(c, "end", time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	start, err := parseTimeQuery(c, "start", end.Add(-defaultComparisonWindow))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !end.After(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start must be before end"})
+		return
+	}
+
+	previousEnd, err := parseTimeQuery(c, "previousEnd", start)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	previousStart, err := parseTimeQuery(c, "previousStart", previousEnd.Add(-end.Sub(start)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !previousEnd.After(previousStart) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "previousStart must be before previousEnd"})
+		return
+	}
+
+	comparison, err := h.statsRepo.GetSummaryComparison(c.Query("host"), start, end, previousStart, previousEnd, h.buildExcludeIPFilter(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get summary comparison"})
+		return
+	}
+	c.JSON(http.StatusOK, comparison)
+}
+
+// parseTimeQuery reads an RFC3339 query parameter, returning fallback when it is absent
+func parseTimeQuery(c *gin.Context, name string, fallback time.Time) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: expected RFC3339 timestamp", name)
+	}
+	return parsed, nil
+}
+
 // CreateComparisonSnapshot stores a precomputed comparison response for sharing.
 func (h *DashboardHandler) CreateComparisonSnapshot(c *gin.Context) {
 	var req createComparisonSnapshotRequest
@@ -682,7 +1269,7 @@ func (h *DashboardHandler) GetRecentRequests(c *gin.Context) {
 	// Handle IP exclusion for request list
 	_, clientIPs, excludeServices := h.getExcludeOwnIP(c)
 
-	requests, err := h.requestRepo.FindAll(limit, offset, service, serviceType, clientIPs, h.convertToRepoFilters(excludeServices))
+	requests, err := h.requestRepo.FindAll(limit, offset, service, serviceType, clientIPs, h.convertToRepoFilters(excludeServices), h.hideInternal(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get requests"})
 		return
@@ -691,15 +1278,170 @@ func (h *DashboardHandler) GetRecentRequests(c *gin.Context) {
 	c.JSON(http.StatusOK, requests)
 }
 
+// ListRequests returns a page of requests filtered by service, client IP, status,
+// method and time window, together with the total number of matches
+func (h *DashboardHandler) ListRequests(c *gin.Context) {
+	limit := 50
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	offset := 0
+	if offsetParam := c.Query("offset"); offsetParam != "" {
+		if val, err := strconv.Atoi(offsetParam); err == nil && val >= 0 {
+			offset = val
+		}
+	}
+
+	filter := repositories.RequestListFilter{
+		ClientIP:        strings.TrimSpace(c.Query("client_ip")),
+		Method:          strings.ToUpper(strings.TrimSpace(c.Query("method"))),
+		ExcludeInternal: h.hideInternal(c),
+		Limit:           limit,
+		Offset:          offset,
+	}
+	filter.ServiceName, filter.ServiceType = h.getServiceFilter(c)
+
+	var err error
+	if filter.StatusMin, filter.StatusMax, err = parseStatusFilter(c.Query("status")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if startParam := c.Query("start"); startParam != "" {
+		if filter.Start, err = time.Parse(time.RFC3339, startParam); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start: expected RFC3339 timestamp"})
+			return
+		}
+	}
+	if endParam := c.Query("end"); endParam != "" {
+		if filter.End, err = time.Parse(time.RFC3339, endParam); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end: expected RFC3339 timestamp"})
+			return
+		}
+	}
+	if !filter.Start.IsZero() && !filter.End.IsZero() && filter.Start.After(filter.End) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start must be before end"})
+		return
+	}
+
+	requests, total, err := h.requestRepo.ListRequests(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   requests,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// SearchRequests returns requests whose path or user agent contains q, newest first
+func (h *DashboardHandler) SearchRequests(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		return
+	}
+
+	limit := 50
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			limit = val
+		}
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	requests, err := h.requestRepo.Search(query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search requests"})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// GetRequestsByRequestID returns the stored rows for an X-Request-ID, for correlating with application logs
+func (h *DashboardHandler) GetRequestsByRequestID(c *gin.Context) {
+	h.respondCorrelatedRequests(c, h.requestRepo.FindByRequestID)
+}
+
+// GetRequestsByTraceID returns the stored rows for a distributed trace ID
+func (h *DashboardHandler) GetRequestsByTraceID(c *gin.Context) {
+	h.respondCorrelatedRequests(c, h.requestRepo.FindByTraceID)
+}
+
+// respondCorrelatedRequests answers 404 when no stored request carries the :id path parameter
+func (h *DashboardHandler) respondCorrelatedRequests(c *gin.Context, find func(string) ([]*models.HTTPRequest, error)) {
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID is required"})
+		return
+	}
+
+	requests, err := find(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find requests"})
+		return
+	}
+	if len(requests) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No requests found for this ID"})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// requestDetail is a stored request with its ProxyMetadata decoded instead of left as a JSON string
+type requestDetail struct {
+	*models.HTTPRequest
+	ProxyMetadata any
+}
+
+// GetRequest returns everything stored for one request, for expanding a row of the requests table
+func (h *DashboardHandler) GetRequest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+
+	request, err := h.requestRepo.FindByID(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get request"})
+		return
+	}
+
+	detail := requestDetail{HTTPRequest: request}
+	if request.ProxyMetadata != "" {
+		// Keep the raw string if a parser ever stored something that is not JSON
+		if json.Unmarshal([]byte(request.ProxyMetadata), &detail.ProxyMetadata) != nil {
+			detail.ProxyMetadata = request.ProxyMetadata
+		}
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
 // GetIPDetailedStats returns comprehensive statistics for a specific IP address
 func (h *DashboardHandler) GetIPDetailedStats(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
 	}
 
-	stats, err := h.statsRepo.GetIPDetailedStats(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
+	stats, err := h.stats(c).GetIPDetailedStats(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP stats"})
 		return
@@ -709,13 +1451,13 @@ func (h *DashboardHandler) GetIPDetailedStats(c *gin.Context) {
 
 // GetIPTimeline returns timeline statistics for a specific IP
 func (h *DashboardHandler) GetIPTimeline(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
 	}
 
-	timeline, err := h.statsRepo.GetIPTimelineStats(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
+	timeline, err := h.stats(c).GetIPTimelineStats(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP timeline"})
 		return
@@ -725,7 +1467,7 @@ func (h *DashboardHandler) GetIPTimeline(c *gin.Context) {
 
 // GetIPHeatmap returns traffic heatmap data for a specific IP
 func (h *DashboardHandler) GetIPHeatmap(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
@@ -749,7 +1491,7 @@ func (h *DashboardHandler) GetIPHeatmap(c *gin.Context) {
 
 // GetIPTopPaths returns most accessed paths for a specific IP
 func (h *DashboardHandler) GetIPTopPaths(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
@@ -762,7 +1504,7 @@ func (h *DashboardHandler) GetIPTopPaths(c *gin.Context) {
 		}
 	}
 
-	paths, err := h.statsRepo.GetIPTopPaths(ip, h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)))
+	paths, err := h.stats(c).GetIPTopPaths(ip, h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP top paths"})
 		return
@@ -772,7 +1514,7 @@ func (h *DashboardHandler) GetIPTopPaths(c *gin.Context) {
 
 // GetIPTopBackends returns backend statistics for a specific IP
 func (h *DashboardHandler) GetIPTopBackends(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
@@ -785,7 +1527,7 @@ func (h *DashboardHandler) GetIPTopBackends(c *gin.Context) {
 		}
 	}
 
-	backends, err := h.statsRepo.GetIPTopBackends(ip, h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)))
+	backends, err := h.stats(c).GetIPTopBackends(ip, h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP top backends"})
 		return
@@ -795,13 +1537,13 @@ func (h *DashboardHandler) GetIPTopBackends(c *gin.Context) {
 
 // GetIPStatusCodeDistribution returns status code distribution for a specific IP
 func (h *DashboardHandler) GetIPStatusCodeDistribution(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
 	}
 
-	stats, err := h.statsRepo.GetIPStatusCodeDistribution(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
+	stats, err := h.stats(c).GetIPStatusCodeDistribution(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP status code distribution"})
 		return
@@ -811,7 +1553,7 @@ func (h *DashboardHandler) GetIPStatusCodeDistribution(c *gin.Context) {
 
 // GetIPTopBrowsers returns top browsers for a specific IP
 func (h *DashboardHandler) GetIPTopBrowsers(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
@@ -824,7 +1566,7 @@ func (h *DashboardHandler) GetIPTopBrowsers(c *gin.Context) {
 		}
 	}
 
-	browsers, err := h.statsRepo.GetIPTopBrowsers(ip, h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)))
+	browsers, err := h.stats(c).GetIPTopBrowsers(ip, h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP top browsers"})
 		return
@@ -834,7 +1576,7 @@ func (h *DashboardHandler) GetIPTopBrowsers(c *gin.Context) {
 
 // GetIPTopOperatingSystems returns top operating systems for a specific IP
 func (h *DashboardHandler) GetIPTopOperatingSystems(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
@@ -847,7 +1589,7 @@ func (h *DashboardHandler) GetIPTopOperatingSystems(c *gin.Context) {
 		}
 	}
 
-	osList, err := h.statsRepo.GetIPTopOperatingSystems(ip, h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)))
+	osList, err := h.stats(c).GetIPTopOperatingSystems(ip, h.getHours(c), limit, h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP top operating systems"})
 		return
@@ -857,13 +1599,13 @@ func (h *DashboardHandler) GetIPTopOperatingSystems(c *gin.Context) {
 
 // GetIPDeviceTypeDistribution returns device type distribution for a specific IP
 func (h *DashboardHandler) GetIPDeviceTypeDistribution(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
 	}
 
-	stats, err := h.statsRepo.GetIPDeviceTypeDistribution(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
+	stats, err := h.stats(c).GetIPDeviceTypeDistribution(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP device type distribution"})
 		return
@@ -873,13 +1615,13 @@ func (h *DashboardHandler) GetIPDeviceTypeDistribution(c *gin.Context) {
 
 // GetIPResponseTimeStats returns response time statistics for a specific IP
 func (h *DashboardHandler) GetIPResponseTimeStats(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
 	}
 
-	stats, err := h.statsRepo.GetIPResponseTimeStats(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
+	stats, err := h.stats(c).GetIPResponseTimeStats(ip, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP response time stats"})
 		return
@@ -889,7 +1631,7 @@ func (h *DashboardHandler) GetIPResponseTimeStats(c *gin.Context) {
 
 // GetIPRecentRequests returns recent HTTP requests for a specific IP
 func (h *DashboardHandler) GetIPRecentRequests(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address is required"})
 		return
@@ -902,7 +1644,7 @@ func (h *DashboardHandler) GetIPRecentRequests(c *gin.Context) {
 		}
 	}
 
-	requests, err := h.statsRepo.GetIPRecentRequests(ip, limit, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
+	requests, err := h.stats(c).GetIPRecentRequests(ip, limit, h.getHours(c), h.convertToRepoFilters(h.getServiceFilters(c)))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get IP recent requests"})
 		return
@@ -912,7 +1654,7 @@ func (h *DashboardHandler) GetIPRecentRequests(c *gin.Context) {
 
 // SearchIPs searches for IP addresses
 func (h *DashboardHandler) SearchIPs(c *gin.Context) {
-	query := c.Query("q")
+	query := models.NormalizeIP(c.Query("q"))
 	if query == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
 		return
@@ -925,7 +1667,7 @@ func (h *DashboardHandler) SearchIPs(c *gin.Context) {
 		}
 	}
 
-	results, err := h.statsRepo.SearchIPs(query, limit)
+	results, err := h.stats(c).SearchIPs(query, h.getHours(c), limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search IPs"})
 		return
@@ -943,9 +1685,9 @@ func (h *DashboardHandler) GetLogProcessingStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
-// GetDomains returns all unique domains
+// GetDomains returns all unique domains, from the host column unless type=backend_name
 func (h *DashboardHandler) GetDomains(c *gin.Context) {
-	domains, err := h.statsRepo.GetDomains()
+	domains, err := h.statsRepo.GetDomains(c.DefaultQuery("type", "host"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get domains"})
 		return
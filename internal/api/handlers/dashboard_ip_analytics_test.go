@@ -30,18 +30,33 @@ func (m *MockStatsRepository) GetTimelineStats(hours int, filters []repositories
 	return args.Get(0).([]*repositories.TimelineData), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetBandwidthTimeline(hours int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.BandwidthTimelineData, error) {
+	args := m.Called(hours, filters, excludeIP)
+	return args.Get(0).([]*repositories.BandwidthTimelineData), args.Error(1)
+}
+
 func (m *MockStatsRepository) GetStatusCodeTimeline(hours int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.StatusCodeTimelineData, error) {
 	args := m.Called(hours, filters, excludeIP)
 	return args.Get(0).([]*repositories.StatusCodeTimelineData), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetConcurrencyTimeline(hours int, bucket time.Duration, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.ConcurrencyData, error) {
+	args := m.Called(hours, bucket, filters, excludeIP)
+	return args.Get(0).([]*repositories.ConcurrencyData), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetPeakTraffic(granularity string, days int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.PeakTrafficData, error) {
+	args := m.Called(granularity, days, limit, filters, excludeIP)
+	return args.Get(0).([]*repositories.PeakTrafficData), args.Error(1)
+}
+
 func (m *MockStatsRepository) GetTrafficHeatmap(days int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.TrafficHeatmapData, error) {
 	args := m.Called(days, filters, excludeIP)
 	return args.Get(0).([]*repositories.TrafficHeatmapData), args.Error(1)
 }
 
-func (m *MockStatsRepository) GetTopPaths(hours int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.PathStats, error) {
-	args := m.Called(hours, limit, filters, excludeIP)
+func (m *MockStatsRepository) GetTopPaths(hours int, limit int, orderBy string, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.PathStats, error) {
+	args := m.Called(hours, limit, orderBy, filters, excludeIP)
 	return args.Get(0).([]*repositories.PathStats), args.Error(1)
 }
 
@@ -50,6 +65,11 @@ func (m *MockStatsRepository) GetTopCountries(hours int, limit int, filters []re
 	return args.Get(0).([]*repositories.CountryStats), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetGeoHeatmap(hours int, gridSize float64, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.GeoHeatmapCell, error) {
+	args := m.Called(hours, gridSize, filters, excludeIP)
+	return args.Get(0).([]*repositories.GeoHeatmapCell), args.Error(1)
+}
+
 func (m *MockStatsRepository) GetTopIPAddresses(hours int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter, tagFilter string, ipFilter *repositories.IPStatsFilter) ([]*repositories.IPStats, error) {
 	args := m.Called(hours, limit, filters, excludeIP, tagFilter, ipFilter)
 	return args.Get(0).([]*repositories.IPStats), args.Error(1)
@@ -65,6 +85,16 @@ func (m *MockStatsRepository) GetMethodDistribution(hours int, filters []reposit
 	return args.Get(0).([]*repositories.MethodStats), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetResponseSizeHistogram(hours int, bounds []int64, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.ResponseSizeBucket, error) {
+	args := m.Called(hours, bounds, filters, excludeIP)
+	return args.Get(0).([]*repositories.ResponseSizeBucket), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetMethodStatusMatrix(hours int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.MethodStatusStats, error) {
+	args := m.Called(hours, filters, excludeIP)
+	return args.Get(0).([]*repositories.MethodStatusStats), args.Error(1)
+}
+
 func (m *MockStatsRepository) GetProtocolDistribution(hours int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.ProtocolStats, error) {
 	args := m.Called(hours, filters, excludeIP)
 	return args.Get(0).([]*repositories.ProtocolStats), args.Error(1)
@@ -75,11 +105,46 @@ func (m *MockStatsRepository) GetTLSVersionDistribution(hours int, filters []rep
 	return args.Get(0).([]*repositories.TLSVersionStats), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetContentTypeDistribution(hours int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.ContentTypeStats, error) {
+	args := m.Called(hours, filters, excludeIP)
+	return args.Get(0).([]*repositories.ContentTypeStats), args.Error(1)
+}
+
 func (m *MockStatsRepository) GetTopUserAgents(hours int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.UserAgentStats, error) {
 	args := m.Called(hours, limit, filters, excludeIP)
 	return args.Get(0).([]*repositories.UserAgentStats), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetTopOriginServers(hours int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.OriginServerStats, error) {
+	args := m.Called(hours, limit, filters, excludeIP)
+	return args.Get(0).([]*repositories.OriginServerStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetErrorAnalysis(hours int, host string, limit int, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.ErrorPathStats, error) {
+	args := m.Called(hours, host, limit, excludeIP)
+	return args.Get(0).([]*repositories.ErrorPathStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetVisitorReturnStats(hours int, host string, excludeIP *repositories.ExcludeIPFilter) (*repositories.VisitorReturnStats, error) {
+	args := m.Called(hours, host, excludeIP)
+	return args.Get(0).(*repositories.VisitorReturnStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetRetriedRequests(hours int, host string, limit int, excludeIP *repositories.ExcludeIPFilter) (*repositories.RetryStats, error) {
+	args := m.Called(hours, host, limit, excludeIP)
+	return args.Get(0).(*repositories.RetryStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetBackendBandwidthProjection(hours int, host string, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.BackendBandwidthProjection, error) {
+	args := m.Called(hours, host, excludeIP)
+	return args.Get(0).([]*repositories.BackendBandwidthProjection), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetSlowRequests(hours int, thresholdMs float64, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.SlowRequest, error) {
+	args := m.Called(hours, thresholdMs, limit, filters, excludeIP)
+	return args.Get(0).([]*repositories.SlowRequest), args.Error(1)
+}
+
 func (m *MockStatsRepository) GetTopBrowsers(hours int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.BrowserStats, error) {
 	args := m.Called(hours, limit, filters, excludeIP)
 	return args.Get(0).([]*repositories.BrowserStats), args.Error(1)
@@ -95,6 +160,21 @@ func (m *MockStatsRepository) GetDeviceTypeDistribution(hours int, filters []rep
 	return args.Get(0).([]*repositories.DeviceTypeStats), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetClientProfiles(hours int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.ClientProfileStats, error) {
+	args := m.Called(hours, limit, filters, excludeIP)
+	return args.Get(0).([]*repositories.ClientProfileStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetTrafficByLabel(hours int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.LabelStats, error) {
+	args := m.Called(hours, filters, excludeIP)
+	return args.Get(0).([]*repositories.LabelStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetBotTrafficStats(hours int, botLimit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) (*repositories.BotTrafficStats, error) {
+	args := m.Called(hours, botLimit, filters, excludeIP)
+	return args.Get(0).(*repositories.BotTrafficStats), args.Error(1)
+}
+
 func (m *MockStatsRepository) GetTopASNs(hours int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.ASNStats, error) {
 	args := m.Called(hours, limit, filters, excludeIP)
 	return args.Get(0).([]*repositories.ASNStats), args.Error(1)
@@ -115,6 +195,26 @@ func (m *MockStatsRepository) GetTopReferrerDomains(hours int, limit int, filter
 	return args.Get(0).([]*repositories.ReferrerDomainStats), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetUniqueVisitorTimeline(hours int, host string, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.UniqueVisitorData, error) {
+	args := m.Called(hours, host, excludeIP)
+	return args.Get(0).([]*repositories.UniqueVisitorData), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetTLSSecurityStats(hours int, host string, excludeIP *repositories.ExcludeIPFilter) (*repositories.TLSSecurityStats, error) {
+	args := m.Called(hours, host, excludeIP)
+	return args.Get(0).(*repositories.TLSSecurityStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetProxyOverheadStats(hours int, host string, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.ProxyOverheadStats, error) {
+	args := m.Called(hours, host, excludeIP)
+	return args.Get(0).([]*repositories.ProxyOverheadStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetReferrerCategories(hours int, host string, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.ReferrerCategoryStats, error) {
+	args := m.Called(hours, host, excludeIP)
+	return args.Get(0).([]*repositories.ReferrerCategoryStats), args.Error(1)
+}
+
 func (m *MockStatsRepository) GetResponseTimeStats(hours int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) (*repositories.ResponseTimeStats, error) {
 	args := m.Called(hours, filters, excludeIP)
 	return args.Get(0).(*repositories.ResponseTimeStats), args.Error(1)
@@ -125,6 +225,11 @@ func (m *MockStatsRepository) GetComparison(periods []repositories.ComparisonPer
 	return args.Get(0).(*repositories.ComparisonResult), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetSummaryComparison(host string, currentStart, currentEnd, previousStart, previousEnd time.Time, excludeIP *repositories.ExcludeIPFilter) (*repositories.SummaryComparison, error) {
+	args := m.Called(host, currentStart, currentEnd, previousStart, previousEnd, excludeIP)
+	return args.Get(0).(*repositories.SummaryComparison), args.Error(1)
+}
+
 func (m *MockStatsRepository) CreateComparisonSnapshot(ownerID string, title string, payload string, expiresAt *time.Time) (*models.ComparisonSnapshot, error) {
 	args := m.Called(ownerID, title, payload, expiresAt)
 	return args.Get(0).(*models.ComparisonSnapshot), args.Error(1)
@@ -155,8 +260,8 @@ func (m *MockStatsRepository) GetLogProcessingStats() ([]*repositories.LogProces
 	return args.Get(0).([]*repositories.LogProcessingStats), args.Error(1)
 }
 
-func (m *MockStatsRepository) GetDomains() ([]*repositories.DomainStats, error) {
-	args := m.Called()
+func (m *MockStatsRepository) GetDomains(filterType string) ([]*repositories.DomainStats, error) {
+	args := m.Called(filterType)
 	return args.Get(0).([]*repositories.DomainStats), args.Error(1)
 }
 
@@ -222,8 +327,8 @@ func (m *MockStatsRepository) GetIPRecentRequests(ip string, limit int, hours in
 	return args.Get(0).([]*models.HTTPRequest), args.Error(1)
 }
 
-func (m *MockStatsRepository) SearchIPs(query string, limit int) ([]*repositories.IPSearchResult, error) {
-	args := m.Called(query, limit)
+func (m *MockStatsRepository) SearchIPs(query string, hours int, limit int) ([]*repositories.IPSearchResult, error) {
+	args := m.Called(query, hours, limit)
 	return args.Get(0).([]*repositories.IPSearchResult), args.Error(1)
 }
 
@@ -242,6 +347,56 @@ func (m *MockStatsRepository) GetRecordsTimeline(days int) ([]*repositories.Time
 	return args.Get(0).([]*repositories.TimelineData), args.Error(1)
 }
 
+func (m *MockStatsRepository) GetQueryPlans(hours int) (*repositories.QueryPlanReport, error) {
+	args := m.Called(hours)
+	return args.Get(0).(*repositories.QueryPlanReport), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetPathSequences(windowMinutes int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.PathTransition, error) {
+	args := m.Called(windowMinutes, limit, filters, excludeIP)
+	return args.Get(0).([]*repositories.PathTransition), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetAuthAbuse(windowMinutes int) ([]*repositories.AuthAbuseIP, error) {
+	args := m.Called(windowMinutes)
+	return args.Get(0).([]*repositories.AuthAbuseIP), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetSuspiciousIPs(hours int, limit int, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) ([]*repositories.SuspiciousIP, error) {
+	args := m.Called(hours, limit, filters, excludeIP)
+	return args.Get(0).([]*repositories.SuspiciousIP), args.Error(1)
+}
+
+func (m *MockStatsRepository) SetAuthAbuseConfig(paths []string, minRequests int, minFailureRatio float64) {
+	m.Called(paths, minRequests, minFailureRatio)
+}
+
+func (m *MockStatsRepository) SetReferrerBlocklist(entries []string) {
+	m.Called(entries)
+}
+
+func (m *MockStatsRepository) SetBenignStatusCodes(codes []int) {
+	m.Called(codes)
+}
+
+func (m *MockStatsRepository) SetMaxTopLimit(limit int) {
+	m.Called(limit)
+}
+
+func (m *MockStatsRepository) SetDisplayTimezone(loc *time.Location) {
+	m.Called(loc)
+}
+
+func (m *MockStatsRepository) WithTimeOffset(offset time.Duration) repositories.StatsRepository {
+	args := m.Called(offset)
+	return args.Get(0).(repositories.StatsRepository)
+}
+
+func (m *MockStatsRepository) WithStatusRange(minStatus, maxStatus int) repositories.StatsRepository {
+	args := m.Called(minStatus, maxStatus)
+	return args.Get(0).(repositories.StatsRepository)
+}
+
 func TestIPAnalyticsHoursAndScope(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -325,3 +480,44 @@ func TestIPAnalyticsHoursAndScope(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestExcludedIPsAreNormalized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := pterm.DefaultLogger
+	handler := NewDashboardHandler(new(MockStatsRepository), nil, &logger)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/stats/summary?exclude_own_ip=true&excluded_ips[]=::ffff:1.2.3.4&excluded_ips[]=2001:DB8::1", nil)
+	c.Request.RemoteAddr = "[::ffff:10.0.0.1]:51000"
+
+	filter := handler.buildExcludeIPFilter(c)
+	if assert.NotNil(t, filter) {
+		assert.Equal(t, []string{"1.2.3.4", "2001:db8::1", "10.0.0.1"}, filter.ClientIPs)
+	}
+}
+
+func TestIPRoutesNormalizeQueriedIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(MockStatsRepository)
+	logger := pterm.DefaultLogger
+	handler := NewDashboardHandler(mockRepo, nil, &logger)
+
+	// Stored client IPs are canonical, so the IPv4-mapped spelling must query the IPv4 form
+	mockRepo.On("GetIPDetailedStats", "1.2.3.4", repositories.DefaultLookbackHours, []repositories.ServiceFilter(nil)).Return(&repositories.IPDetailedStats{}, nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = []gin.Param{{Key: "ip", Value: "::ffff:1.2.3.4"}}
+	c.Request, _ = http.NewRequest("GET", "/api/v1/ip/::ffff:1.2.3.4/stats", nil)
+	handler.GetIPDetailedStats(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.On("SearchIPs", "2001:db8::1", repositories.DefaultLookbackHours, 10).Return([]*repositories.IPSearchResult{}, nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/ip/search?q=2001:0DB8:0000::0001", nil)
+	handler.SearchIPs(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"loglynx/internal/database/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTopListsNarrowToStatusRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := pterm.DefaultLogger
+
+	tests := []struct {
+		query    string
+		min, max int
+	}{
+		{"statusClass=4xx", 400, 499},
+		{"statusClass=5XX", 500, 599},
+		{"statusMin=404&statusMax=404", 404, 404},
+		{"statusMin=500", 500, 0},
+		{"statusClass=2xx&statusMin=500", 200, 299},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			mockRepo := new(MockStatsRepository)
+			narrowed := new(MockStatsRepository)
+			handler := NewDashboardHandler(mockRepo, nil, &logger)
+
+			mockRepo.On("WithStatusRange", tt.min, tt.max).Return(narrowed)
+			narrowed.On("GetTopPaths", 24, 10, "hits", mock.Anything, mock.Anything).Return([]*repositories.PathStats{}, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/api/v1/stats/top/paths?hours=24&"+tt.query, nil)
+			handler.GetTopPaths(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockRepo.AssertExpectations(t)
+			narrowed.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTopListsRejectInvalidStatusRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := pterm.DefaultLogger
+	mockRepo := new(MockStatsRepository)
+	handler := NewDashboardHandler(mockRepo, nil, &logger)
+
+	for _, query := range []string{"statusClass=6xx", "statusClass=404", "statusMin=abc", "statusMax=700", "statusMin=500&statusMax=400"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/api/v1/stats/top/countries?"+query, nil)
+		handler.GetTopCountries(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockRepo.AssertNotCalled(t, "GetTopCountries", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSummaryComparisonWindows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := pterm.DefaultLogger
+	end := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name                           string
+		query                          string
+		start, previousStart, previous time.Time
+	}{
+		{"previous defaults to the window before", "start=2026-03-01T00:00:00Z&end=2026-03-08T00:00:00Z",
+			end.Add(-week), end.Add(-2 * week), end.Add(-week)},
+		{"explicit previous window", "start=2026-03-07T00:00:00Z&end=2026-03-08T00:00:00Z&previousStart=2026-02-28T00:00:00Z&previousEnd=2026-03-01T00:00:00Z",
+			end.Add(-24 * time.Hour), end.Add(-8 * 24 * time.Hour), end.Add(-week)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockStatsRepository)
+			handler := NewDashboardHandler(mockRepo, nil, &logger)
+			mockRepo.On("GetSummaryComparison", "shop.example.com", tt.start, end, tt.previousStart, tt.previous, mock.Anything).
+				Return(&repositories.SummaryComparison{}, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/api/v1/stats/compare?host=shop.example.com&"+tt.query, nil)
+			handler.GetSummaryComparison(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSummaryComparisonRejectsInvalidWindows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := pterm.DefaultLogger
+	mockRepo := new(MockStatsRepository)
+	handler := NewDashboardHandler(mockRepo, nil, &logger)
+
+	for _, query := range []string{
+		"start=last-week",
+		"start=2026-03-08T00:00:00Z&end=2026-03-01T00:00:00Z",
+		"start=2026-03-01T00:00:00Z&end=2026-03-08T00:00:00Z&previousStart=2026-03-01T00:00:00Z&previousEnd=2026-02-22T00:00:00Z",
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/api/v1/stats/compare?"+query, nil)
+		handler.GetSummaryComparison(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockRepo.AssertNotCalled(t, "GetSummaryComparison")
+}
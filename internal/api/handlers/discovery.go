@@ -0,0 +1,76 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"net/http"
+
+	"loglynx/internal/database/models"
+	"loglynx/internal/discovery"
+	"loglynx/internal/ingestion"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+)
+
+// DiscoveryHandler re-runs log source discovery on demand
+type DiscoveryHandler struct {
+	engine      *discovery.Engine
+	coordinator *ingestion.Coordinator
+	logger      *pterm.Logger
+}
+
+// NewDiscoveryHandler creates a new discovery handler
+func NewDiscoveryHandler(engine *discovery.Engine, coordinator *ingestion.Coordinator, logger *pterm.Logger) *DiscoveryHandler {
+	return &DiscoveryHandler{
+		engine:      engine,
+		coordinator: coordinator,
+		logger:      logger,
+	}
+}
+
+// TriggerDiscovery runs the detectors, registers sources not known yet and starts their processors
+func (h *DiscoveryHandler) TriggerDiscovery(c *gin.Context) {
+	sources, err := h.engine.Discover(h.logger)
+	if err != nil {
+		h.logger.WithCaller().Error("On-demand discovery failed", h.logger.Args("error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run discovery"})
+		return
+	}
+
+	// A failed start is not fatal: the source is registered and the sync loop retries it
+	failed := make(map[string]string)
+	for _, source := range sources {
+		if err := h.coordinator.AddProcessor(source); err != nil {
+			failed[source.Name] = err.Error()
+		}
+	}
+
+	if sources == nil {
+		sources = []*models.LogSource{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"discovered": sources,
+		"count":      len(sources),
+		"failed":     failed,
+	})
+}
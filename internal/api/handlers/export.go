@@ -0,0 +1,244 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"loglynx/internal/database/models"
+	"loglynx/internal/database/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushEvery is how many rows are written between flushes to the client
+const exportFlushEvery = 500
+
+// activeExports counts exports currently streaming, reported in the system stats
+var activeExports atomic.Int64
+
+// exportColumn is an exported HTTPRequest field
+type exportColumn struct {
+	name  string
+	index int
+}
+
+// exportColumns lists the HTTPRequest fields written by ExportRequests, in declaration order.
+// Relations (LogSource) are skipped; time.Time is the only struct type kept.
+var exportColumns = func() []exportColumn {
+	t := reflect.TypeOf(models.HTTPRequest{})
+	columns := make([]exportColumn, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			continue
+		}
+		columns = append(columns, exportColumn{name: field.Name, index: i})
+	}
+	return columns
+}()
+
+// ExportRequests streams raw HTTP requests as CSV or NDJSON
+func (h *DashboardHandler) ExportRequests(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "ndjson" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or ndjson"})
+		return
+	}
+
+	filter, err := h.getExportFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	activeExports.Add(1)
+	defer activeExports.Add(-1)
+
+	filename := fmt.Sprintf("loglynx-requests-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-cache")
+
+	// Count written rows and push them to the client every exportFlushEvery rows
+	var csvWriter *csv.Writer
+	rows := 0
+	rowWritten := func() {
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if csvWriter != nil {
+				csvWriter.Flush()
+			}
+			c.Writer.Flush()
+		}
+	}
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		csvWriter = csv.NewWriter(c.Writer)
+		header := make([]string, len(exportColumns))
+		for i, col := range exportColumns {
+			header[i] = col.name
+		}
+		_ = csvWriter.Write(header)
+
+		err = h.requestRepo.StreamByTimeRange(c.Request.Context(), filter, func(req *models.HTTPRequest) error {
+			if err := csvWriter.Write(exportCSVRecord(req)); err != nil {
+				return err
+			}
+			rowWritten()
+			return nil
+		})
+		csvWriter.Flush()
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+
+		err = h.requestRepo.StreamByTimeRange(c.Request.Context(), filter, func(req *models.HTTPRequest) error {
+			line, err := exportJSONLine(req)
+			if err != nil {
+				return err
+			}
+			if _, err := c.Writer.Write(line); err != nil {
+				return err
+			}
+			rowWritten()
+			return nil
+		})
+	}
+	c.Writer.Flush()
+
+	if err != nil {
+		// Headers are already sent, so the error can only be logged
+		h.logger.WithCaller().Error("Request export aborted",
+			h.logger.Args("format", format, "rows_written", rows, "error", err))
+		return
+	}
+	h.logger.Debug("Request export completed", h.logger.Args("format", format, "rows", rows))
+}
+
+// getExportFilter builds the export filter from start, end, service, status and limit parameters.
+// The window defaults to the last 24 hours.
+func (h *DashboardHandler) getExportFilter(c *gin.Context) (repositories.RequestExportFilter, error) {
+	filter := repositories.RequestExportFilter{End: time.Now()}
+
+	if endParam := c.Query("end"); endParam != "" {
+		end, err := time.Parse(time.RFC3339, endParam)
+		if err != nil {
+			return filter, fmt.Errorf("invalid end: expected RFC3339 timestamp")
+		}
+		filter.End = end
+	}
+	filter.Start = filter.End.Add(-24 * time.Hour)
+	if startParam := c.Query("start"); startParam != "" {
+		start, err := time.Parse(time.RFC3339, startParam)
+		if err != nil {
+			return filter, fmt.Errorf("invalid start: expected RFC3339 timestamp")
+		}
+		filter.Start = start
+	}
+	if filter.Start.After(filter.End) {
+		return filter, fmt.Errorf("start must be before end")
+	}
+
+	filter.ServiceName, filter.ServiceType = h.getServiceFilter(c)
+
+	statusMin, statusMax, err := parseStatusFilter(c.Query("status"))
+	if err != nil {
+		return filter, err
+	}
+	filter.StatusMin, filter.StatusMax = statusMin, statusMax
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
+			filter.Limit = val
+		}
+	}
+
+	return filter, nil
+}
+
+// parseStatusFilter turns an exact code (404) or a class (4xx) into an inclusive
+// status_code range. An empty value yields 0, 0 (no filter).
+func parseStatusFilter(statusParam string) (int, int, error) {
+	statusParam = strings.ToLower(statusParam)
+	if statusParam == "" {
+		return 0, 0, nil
+	}
+	if len(statusParam) == 3 && strings.HasSuffix(statusParam, "xx") && statusParam[0] >= '1' && statusParam[0] <= '5' {
+		class := int(statusParam[0]-'0') * 100
+		return class, class + 99, nil
+	}
+	if code, err := strconv.Atoi(statusParam); err == nil && code >= 100 && code < 600 {
+		return code, code, nil
+	}
+	return 0, 0, fmt.Errorf("invalid status: expected a code like 404 or a class like 4xx")
+}
+
+// exportCSVRecord formats a request as a CSV record matching exportColumns
+func exportCSVRecord(req *models.HTTPRequest) []string {
+	v := reflect.ValueOf(req).Elem()
+	record := make([]string, len(exportColumns))
+	for i, col := range exportColumns {
+		switch val := v.Field(col.index).Interface().(type) {
+		case time.Time:
+			record[i] = val.Format(time.RFC3339Nano)
+		case string:
+			record[i] = val
+		case float64:
+			record[i] = strconv.FormatFloat(val, 'f', -1, 64)
+		default:
+			record[i] = fmt.Sprint(val)
+		}
+	}
+	return record
+}
+
+// exportJSONLine encodes a request as one NDJSON line with keys in exportColumns order
+func exportJSONLine(req *models.HTTPRequest) ([]byte, error) {
+	v := reflect.ValueOf(req).Elem()
+	buf := make([]byte, 0, 1024)
+	buf = append(buf, '{')
+	for i, col := range exportColumns {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		value, err := json.Marshal(v.Field(col.index).Interface())
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, strconv.Quote(col.name)...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	buf = append(buf, '}', '\n')
+	return buf, nil
+}
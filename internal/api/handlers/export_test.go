@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+	"loglynx/internal/database/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newExportTestHandler returns a dashboard handler over an in-memory database holding
+// four requests, one hour apart, ending at the returned time
+func newExportTestHandler(t *testing.T) (*DashboardHandler, time.Time) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.HTTPRequest{}))
+
+	end := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	requests := []models.HTTPRequest{
+		{RequestHash: "export-1", ClientIP: "1.1.1.1", Host: "shop.example.com", Method: "GET", Path: "/", StatusCode: 200, Timestamp: end.Add(-3 * time.Hour)},
+		{RequestHash: "export-2", ClientIP: "1.1.1.1", Host: "shop.example.com", Method: "GET", Path: "/missing", StatusCode: 404, Timestamp: end.Add(-2 * time.Hour)},
+		{RequestHash: "export-3", ClientIP: "2.2.2.2", Host: "blog.example.com", Method: "POST", Path: "/comment", StatusCode: 403, Timestamp: end.Add(-time.Hour)},
+		{RequestHash: "export-4", ClientIP: "2.2.2.2", Host: "blog.example.com", Method: "GET", Path: "/", StatusCode: 200, Timestamp: end},
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	return NewDashboardHandler(nil, repositories.NewHTTPRequestRepository(db, logger), logger), end
+}
+
+func runExport(handler *DashboardHandler, ctx context.Context, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/export/requests?"+query, nil).WithContext(ctx)
+	handler.ExportRequests(c)
+	return w
+}
+
+func TestExportRequestsCSV(t *testing.T) {
+	handler, end := newExportTestHandler(t)
+	window := "start=" + end.Add(-4*time.Hour).Format(time.RFC3339) + "&end=" + end.Format(time.RFC3339)
+
+	w := runExport(handler, context.Background(), window+"&format=csv&status=4xx")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="loglynx-requests-\d{8}-\d{6}\.csv"$`, w.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3, "header plus the two 4xx requests")
+	header := records[0]
+	assert.Equal(t, len(exportColumns), len(header))
+	pathCol := indexOf(header, "Path")
+	require.GreaterOrEqual(t, pathCol, 0)
+	assert.Equal(t, "/missing", records[1][pathCol], "rows are ordered oldest first")
+	assert.Equal(t, "/comment", records[2][pathCol])
+
+	// An empty range still writes the header
+	empty := "start=" + end.Add(time.Hour).Format(time.RFC3339) + "&end=" + end.Add(2*time.Hour).Format(time.RFC3339)
+	w = runExport(handler, context.Background(), empty+"&format=csv")
+	require.Equal(t, http.StatusOK, w.Code)
+	records, err = csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestExportRequestsNDJSON(t *testing.T) {
+	handler, end := newExportTestHandler(t)
+	window := "start=" + end.Add(-4*time.Hour).Format(time.RFC3339) + "&end=" + end.Format(time.RFC3339)
+
+	w := runExport(handler, context.Background(), window+"&format=ndjson&host=blog.example.com")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Regexp(t, `\.ndjson"$`, w.Header().Get("Content-Disposition"))
+
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	require.Len(t, lines, 2, "only the blog requests match the host filter")
+	var row map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &row))
+	assert.Equal(t, "blog.example.com", row["Host"])
+	assert.Equal(t, "/comment", row["Path"])
+
+	w = runExport(handler, context.Background(), window+"&format=ndjson&limit=1")
+	assert.Equal(t, 1, strings.Count(w.Body.String(), "\n"))
+
+	// An empty range yields an empty body
+	empty := "start=" + end.Add(time.Hour).Format(time.RFC3339) + "&end=" + end.Add(2*time.Hour).Format(time.RFC3339)
+	w = runExport(handler, context.Background(), empty+"&format=ndjson")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestExportRequestsStopsWhenClientDisconnects(t *testing.T) {
+	handler, end := newExportTestHandler(t)
+	window := "start=" + end.Add(-4*time.Hour).Format(time.RFC3339) + "&end=" + end.Format(time.RFC3339)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := runExport(handler, ctx, window+"&format=ndjson")
+	assert.Empty(t, w.Body.String(), "no rows are scanned for a cancelled request")
+}
+
+func TestExportRequestsRejectsInvalidParameters(t *testing.T) {
+	handler, _ := newExportTestHandler(t)
+
+	for _, query := range []string{"format=xml", "status=6xx", "start=yesterday", "start=2026-03-02T00:00:00Z&end=2026-03-01T00:00:00Z"} {
+		w := runExport(handler, context.Background(), query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func indexOf(values []string, want string) int {
+	for i, v := range values {
+		if v == want {
+			return i
+		}
+	}
+	return -1
+}
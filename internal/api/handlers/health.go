@@ -0,0 +1,95 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"loglynx/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds the database round trip of a readiness probe
+const readinessTimeout = 2 * time.Second
+
+// IngestionStatus is the part of the ingestion coordinator readiness depends on
+type IngestionStatus interface {
+	IsRunning() bool
+	GetProcessorCount() int
+}
+
+// HealthHandler serves the liveness and readiness probes used by container
+// orchestrators. Both avoid the stats queries behind /api/v1/system/stats.
+type HealthHandler struct {
+	db        *gorm.DB
+	ingestion IngestionStatus
+	logger    *pterm.Logger
+}
+
+// NewHealthHandler creates a new probe handler
+func NewHealthHandler(db *gorm.DB, ingestion IngestionStatus, logger *pterm.Logger) *HealthHandler {
+	return &HealthHandler{
+		db:        db,
+		ingestion: ingestion,
+		logger:    logger,
+	}
+}
+
+// Liveness answers 200 as long as the HTTP server is serving
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness answers 200 once migrations have run, the database answers SELECT 1
+// within readinessTimeout and at least one log source is being processed;
+// otherwise 503 with the first failing check
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if reason := h.notReadyReason(c.Request.Context()); reason != "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "reason": reason})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+// notReadyReason returns why the instance is not ready, or "" when it is
+func (h *HealthHandler) notReadyReason(ctx context.Context) string {
+	if !database.MigrationsApplied() {
+		return "migrations pending"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	if err := h.db.WithContext(ctx).Exec("SELECT 1").Error; err != nil {
+		h.logger.Debug("Readiness probe database check failed", h.logger.Args("error", err))
+		return "database unavailable"
+	}
+
+	if !h.ingestion.IsRunning() || h.ingestion.GetProcessorCount() == 0 {
+		return "no log source is being processed"
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"loglynx/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeIngestion struct {
+	running    bool
+	processors int
+}
+
+func (f *fakeIngestion) IsRunning() bool        { return f.running }
+func (f *fakeIngestion) GetProcessorCount() int { return f.processors }
+
+func TestReadiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	ingestion := &fakeIngestion{}
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	handler := NewHealthHandler(db, ingestion, logger)
+
+	probe := func(fn gin.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		fn(c)
+		return w
+	}
+
+	w := probe(handler.Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "no log source is being processed")
+
+	ingestion.running, ingestion.processors = true, 1
+	w = probe(handler.Readiness)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ready"}`, w.Body.String())
+
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+	w = probe(handler.Readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "database unavailable")
+
+	w = probe(handler.Liveness)
+	assert.Equal(t, http.StatusOK, w.Code, "liveness does not depend on the database")
+}
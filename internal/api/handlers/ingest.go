@@ -0,0 +1,84 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"loglynx/internal/ingestion"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+)
+
+// DefaultIngestSource is the log source name given to imported requests when none is set
+const DefaultIngestSource = "import"
+
+// LogImporter parses, enriches and stores log lines read from a request body
+type LogImporter interface {
+	Import(parserType string, sourceName string, r io.Reader) (*ingestion.ImportResult, error)
+}
+
+// IngestHandler imports logs pushed over HTTP, for dumps from hosts without a file mount
+type IngestHandler struct {
+	importer     LogImporter
+	maxBodyBytes int64
+	logger       *pterm.Logger
+}
+
+// NewIngestHandler creates a new ingest handler accepting bodies of up to maxBodyBytes
+func NewIngestHandler(importer LogImporter, maxBodyBytes int64, logger *pterm.Logger) *IngestHandler {
+	return &IngestHandler{
+		importer:     importer,
+		maxBodyBytes: maxBodyBytes,
+		logger:       logger,
+	}
+}
+
+// Ingest imports the log lines of the request body (NDJSON or raw lines, one request per line)
+// Query params: parser (required, e.g. traefik, caddy, apache), source (default "import")
+func (h *IngestHandler) Ingest(c *gin.Context) {
+	parserType := c.Query("parser")
+	if parserType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parser is required"})
+		return
+	}
+	source := c.DefaultQuery("source", DefaultIngestSource)
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBodyBytes)
+	result, err := h.importer.Import(parserType, source, body)
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, ingestion.ErrUnknownParser):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body exceeds the ingest size limit", "result": result})
+	case err != nil:
+		h.logger.WithCaller().Error("Failed to import logs", h.logger.Args("source", source, "parser", parserType, "error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import logs", "result": result})
+	default:
+		c.JSON(http.StatusOK, result)
+	}
+}
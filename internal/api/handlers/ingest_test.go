@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"loglynx/internal/ingestion"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeImporter counts the lines of the body, failing on unknown parsers like the coordinator
+type fakeImporter struct {
+	source string
+}
+
+func (f *fakeImporter) Import(parserType string, sourceName string, r io.Reader) (*ingestion.ImportResult, error) {
+	if parserType != "traefik" {
+		return nil, ingestion.ErrUnknownParser
+	}
+	f.source = sourceName
+	body, err := io.ReadAll(r)
+	result := &ingestion.ImportResult{Lines: int64(strings.Count(string(body), "\n"))}
+	result.Inserted = result.Lines
+	return result, err
+}
+
+func runIngest(handler *IngestHandler, query string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/ingest?"+query, strings.NewReader(body))
+	handler.Ingest(c)
+	return w
+}
+
+func TestIngest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	importer := &fakeImporter{}
+	handler := NewIngestHandler(importer, 64, logger)
+
+	w := runIngest(handler, "parser=traefik", "{}\n{}\n")
+	require.Equal(t, http.StatusOK, w.Code)
+	var result ingestion.ImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, int64(2), result.Inserted)
+	assert.Equal(t, DefaultIngestSource, importer.source)
+
+	runIngest(handler, "parser=traefik&source=old-host", "{}\n")
+	assert.Equal(t, "old-host", importer.source)
+
+	assert.Equal(t, http.StatusBadRequest, runIngest(handler, "", "{}\n").Code)
+	assert.Equal(t, http.StatusBadRequest, runIngest(handler, "parser=unknown", "{}\n").Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, runIngest(handler, "parser=traefik", strings.Repeat("{}\n", 40)).Code)
+}
+
+func TestIngestReportsPartialImportOnFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	handler := NewIngestHandler(failingImporter{}, 1024, logger)
+
+	w := runIngest(handler, "parser=traefik", "{}\n")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), `"inserted":5`)
+}
+
+type failingImporter struct{}
+
+func (failingImporter) Import(string, string, io.Reader) (*ingestion.ImportResult, error) {
+	return &ingestion.ImportResult{Lines: 10, Inserted: 5}, errors.New("database is locked")
+}
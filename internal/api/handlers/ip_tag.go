@@ -56,6 +56,7 @@ func (h *IPTagHandler) CreateOrUpdateTag(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	request.IPAddress = models.NormalizeIP(request.IPAddress)
 
 	tag, err := h.ipTagRepo.FindByIP(request.IPAddress)
 	if err != nil {
@@ -84,7 +85,7 @@ func (h *IPTagHandler) CreateOrUpdateTag(c *gin.Context) {
 
 // DeleteTag deletes an IP tag
 func (h *IPTagHandler) DeleteTag(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address required"})
 		return
@@ -102,7 +103,7 @@ func (h *IPTagHandler) DeleteTag(c *gin.Context) {
 
 // GetTag gets an IP tag by IP
 func (h *IPTagHandler) GetTag(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := models.NormalizeIP(c.Param("ip"))
 	if ip == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "IP address required"})
 		return
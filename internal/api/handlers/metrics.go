@@ -0,0 +1,201 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"net/http"
+
+	"loglynx/internal/database"
+	"loglynx/internal/enrichment"
+	"loglynx/internal/ingestion"
+	"loglynx/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/pterm/pterm"
+	"gorm.io/gorm"
+)
+
+// ProcessorMetricsSource reports the counters of every running source processor
+type ProcessorMetricsSource interface {
+	GetProcessorMetrics() []ingestion.ProcessorMetrics
+}
+
+// MetricsHandler exports ingestion and processing metrics for Prometheus
+type MetricsHandler struct {
+	handler http.Handler
+}
+
+// NewMetricsHandler creates a new Prometheus metrics handler backed by its own registry
+func NewMetricsHandler(
+	processors ProcessorMetricsSource,
+	collector *realtime.MetricsCollector,
+	geoIP *enrichment.GeoIPEnricher,
+	db *gorm.DB,
+	poolThreshold float64,
+	logger *pterm.Logger,
+) *MetricsHandler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		&loglynxCollector{
+			processors:    processors,
+			collector:     collector,
+			geoIP:         geoIP,
+			db:            db,
+			poolThreshold: poolThreshold,
+			logger:        logger,
+		},
+	)
+
+	return &MetricsHandler{
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}
+}
+
+// GetPrometheusMetrics serves all metrics in the Prometheus exposition format
+func (h *MetricsHandler) GetPrometheusMetrics(c *gin.Context) {
+	h.handler.ServeHTTP(c.Writer, c.Request)
+}
+
+// Metric descriptors, labelled with the log source where they are per source
+var (
+	requestsProcessedDesc = prometheus.NewDesc("loglynx_requests_processed_total",
+		"Requests parsed and stored, per log source.", []string{"source"}, nil)
+	parseErrorsDesc = prometheus.NewDesc("loglynx_parse_errors_total",
+		"Log lines that failed parsing, per log source.", []string{"source"}, nil)
+	requestsFilteredDesc = prometheus.NewDesc("loglynx_requests_filtered_total",
+		"Requests dropped by the ASN exclude list, per log source.", []string{"source"}, nil)
+	insertErrorsDesc = prometheus.NewDesc("loglynx_insert_errors_total",
+		"Requests lost to failed batch inserts, per log source.", []string{"source"}, nil)
+	batchInsertDurationDesc = prometheus.NewDesc("loglynx_batch_insert_duration_seconds",
+		"Time spent in successful batch inserts, per log source.", []string{"source"}, nil)
+
+	geoIPCacheHitsDesc = prometheus.NewDesc("loglynx_geoip_cache_hits_total",
+		"GeoIP lookups answered from the in-memory cache.", nil, nil)
+	geoIPCacheMissesDesc = prometheus.NewDesc("loglynx_geoip_cache_misses_total",
+		"GeoIP lookups that missed the in-memory cache.", nil, nil)
+	geoIPCacheHitRatioDesc = prometheus.NewDesc("loglynx_geoip_cache_hit_ratio",
+		"Share of GeoIP lookups answered from cache since startup.", nil, nil)
+	geoIPCacheEntriesDesc = prometheus.NewDesc("loglynx_geoip_cache_entries",
+		"Entries in the GeoIP in-memory cache.", nil, nil)
+	geoIPCacheEvictionsDesc = prometheus.NewDesc("loglynx_geoip_cache_evictions_total",
+		"Least recently used entries dropped to keep the GeoIP cache within GEOIP_CACHE_SIZE.", nil, nil)
+	geoIPLookupsDesc = prometheus.NewDesc("loglynx_geoip_lookups_total",
+		"GeoIP database lookups by outcome (success, not_found, failed).", []string{"result"}, nil)
+
+	sseConnectionsDesc = prometheus.NewDesc("loglynx_sse_active_connections",
+		"Open real-time metrics stream connections.", nil, nil)
+
+	poolOpenDesc = prometheus.NewDesc("loglynx_db_pool_open_connections",
+		"Open database connections.", nil, nil)
+	poolInUseDesc = prometheus.NewDesc("loglynx_db_pool_in_use_connections",
+		"Database connections currently in use.", nil, nil)
+	poolMaxOpenDesc = prometheus.NewDesc("loglynx_db_pool_max_open_connections",
+		"Configured maximum of open database connections.", nil, nil)
+	poolUtilizationDesc = prometheus.NewDesc("loglynx_db_pool_utilization_ratio",
+		"Share of the maximum connections in use.", nil, nil)
+	poolWaitDesc = prometheus.NewDesc("loglynx_db_pool_wait_total",
+		"Connections that had to wait for a free pool slot.", nil, nil)
+	poolWaitSecondsDesc = prometheus.NewDesc("loglynx_db_pool_wait_seconds_total",
+		"Total time spent waiting for a free pool slot.", nil, nil)
+)
+
+// loglynxCollector reads the current counters on every scrape
+type loglynxCollector struct {
+	processors    ProcessorMetricsSource
+	collector     *realtime.MetricsCollector
+	geoIP         *enrichment.GeoIPEnricher
+	db            *gorm.DB
+	poolThreshold float64
+	logger        *pterm.Logger
+}
+
+// Describe implements prometheus.Collector
+func (l *loglynxCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		requestsProcessedDesc, parseErrorsDesc, requestsFilteredDesc, insertErrorsDesc, batchInsertDurationDesc,
+		geoIPCacheHitsDesc, geoIPCacheMissesDesc, geoIPCacheHitRatioDesc, geoIPCacheEntriesDesc, geoIPCacheEvictionsDesc, geoIPLookupsDesc,
+		sseConnectionsDesc,
+		poolOpenDesc, poolInUseDesc, poolMaxOpenDesc, poolUtilizationDesc, poolWaitDesc, poolWaitSecondsDesc,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (l *loglynxCollector) Collect(ch chan<- prometheus.Metric) {
+	// Ingestion, per source
+	if l.processors != nil {
+		for _, p := range l.processors.GetProcessorMetrics() {
+			ch <- prometheus.MustNewConstMetric(requestsProcessedDesc, prometheus.CounterValue, float64(p.Processed), p.Source)
+			ch <- prometheus.MustNewConstMetric(parseErrorsDesc, prometheus.CounterValue, float64(p.ParseErrors), p.Source)
+			ch <- prometheus.MustNewConstMetric(requestsFilteredDesc, prometheus.CounterValue, float64(p.Filtered), p.Source)
+			ch <- prometheus.MustNewConstMetric(insertErrorsDesc, prometheus.CounterValue, float64(p.InsertErrors), p.Source)
+			ch <- prometheus.MustNewConstSummary(batchInsertDurationDesc, uint64(p.BatchInserts), p.BatchInsertSeconds, nil, p.Source)
+		}
+	}
+
+	// GeoIP cache
+	if l.geoIP != nil && l.geoIP.IsEnabled() {
+		hits, misses := l.geoIP.GetCacheStats()
+		hitRatio := 0.0
+		if hits+misses > 0 {
+			hitRatio = float64(hits) / float64(hits+misses)
+		}
+		ch <- prometheus.MustNewConstMetric(geoIPCacheHitsDesc, prometheus.CounterValue, float64(hits))
+		ch <- prometheus.MustNewConstMetric(geoIPCacheMissesDesc, prometheus.CounterValue, float64(misses))
+		ch <- prometheus.MustNewConstMetric(geoIPCacheHitRatioDesc, prometheus.GaugeValue, hitRatio)
+		ch <- prometheus.MustNewConstMetric(geoIPCacheEntriesDesc, prometheus.GaugeValue, float64(l.geoIP.GetCacheSize()))
+		ch <- prometheus.MustNewConstMetric(geoIPCacheEvictionsDesc, prometheus.CounterValue, float64(l.geoIP.GetCacheEvictions()))
+
+		lookups := l.geoIP.GetLookupStats()
+		ch <- prometheus.MustNewConstMetric(geoIPLookupsDesc, prometheus.CounterValue, float64(lookups.Success), "success")
+		ch <- prometheus.MustNewConstMetric(geoIPLookupsDesc, prometheus.CounterValue, float64(lookups.NotFound), "not_found")
+		ch <- prometheus.MustNewConstMetric(geoIPLookupsDesc, prometheus.CounterValue, float64(lookups.Failed), "failed")
+	}
+
+	// Real-time streams
+	if l.collector != nil {
+		ch <- prometheus.MustNewConstMetric(sseConnectionsDesc, prometheus.GaugeValue, float64(l.collector.GetActiveConnections()))
+	}
+
+	// Database connection pool
+	if l.db == nil {
+		return
+	}
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		l.logger.WithCaller().Warn("Failed to read database pool stats", l.logger.Args("error", err))
+		return
+	}
+	pool := database.ReadPoolStats(sqlDB, l.poolThreshold)
+	ch <- prometheus.MustNewConstMetric(poolOpenDesc, prometheus.GaugeValue, float64(pool.OpenConns))
+	ch <- prometheus.MustNewConstMetric(poolInUseDesc, prometheus.GaugeValue, float64(pool.InUse))
+	ch <- prometheus.MustNewConstMetric(poolMaxOpenDesc, prometheus.GaugeValue, float64(pool.MaxOpenConns))
+	ch <- prometheus.MustNewConstMetric(poolUtilizationDesc, prometheus.GaugeValue, pool.Utilization)
+	ch <- prometheus.MustNewConstMetric(poolWaitDesc, prometheus.CounterValue, float64(pool.WaitCount))
+	ch <- prometheus.MustNewConstMetric(poolWaitSecondsDesc, prometheus.CounterValue, pool.WaitDuration.Seconds())
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"loglynx/internal/ingestion"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type fakeProcessorMetrics []ingestion.ProcessorMetrics
+
+func (f fakeProcessorMetrics) GetProcessorMetrics() []ingestion.ProcessorMetrics { return f }
+
+func TestGetPrometheusMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	processors := fakeProcessorMetrics{
+		{Source: "traefik", Processed: 120, ParseErrors: 3, Filtered: 2, InsertErrors: 1, BatchInserts: 4, BatchInsertSeconds: 0.5},
+		{Source: `odd "name"\path`, Processed: 7},
+	}
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	handler := NewMetricsHandler(processors, nil, nil, db, 0.8, logger)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	handler.GetPrometheusMetrics(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+
+	// The body must parse as the text exposition format, label escaping included
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(strings.NewReader(w.Body.String()))
+	require.NoError(t, err)
+
+	processed := families["loglynx_requests_processed_total"]
+	require.NotNil(t, processed)
+	values := map[string]float64{}
+	for _, m := range processed.GetMetric() {
+		values[m.GetLabel()[0].GetValue()] = m.GetCounter().GetValue()
+	}
+	assert.Equal(t, map[string]float64{"traefik": 120, `odd "name"\path`: 7}, values)
+
+	duration := families["loglynx_batch_insert_duration_seconds"]
+	require.NotNil(t, duration)
+	for _, m := range duration.GetMetric() {
+		if m.GetLabel()[0].GetValue() == "traefik" {
+			assert.Equal(t, uint64(4), m.GetSummary().GetSampleCount())
+			assert.Equal(t, 0.5, m.GetSummary().GetSampleSum())
+		}
+	}
+
+	assert.Contains(t, families, "loglynx_parse_errors_total")
+	assert.Contains(t, families, "loglynx_db_pool_open_connections")
+	assert.Contains(t, families, "go_goroutines")
+	assert.NotContains(t, families, "loglynx_geoip_cache_hits_total", "GeoIP metrics are omitted without an enricher")
+	assert.NotContains(t, families, "loglynx_sse_active_connections", "stream metrics are omitted without a collector")
+}
@@ -0,0 +1,79 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"loglynx/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartOptimize runs ANALYZE and index reconciliation on demand, plus VACUUM when
+// vacuum=true (ingestion pauses while it runs). Answers 202 with a job to poll.
+func (h *SystemHandler) StartOptimize(c *gin.Context) {
+	if h.cleanupService == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Database maintenance is not available"})
+		return
+	}
+
+	vacuum := false
+	if vacuumParam := c.Query("vacuum"); vacuumParam != "" {
+		val, err := strconv.ParseBool(vacuumParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vacuum, expected true or false"})
+			return
+		}
+		vacuum = val
+	}
+
+	job, err := h.cleanupService.StartOptimize(vacuum)
+	if errors.Is(err, database.ErrMaintenanceRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.WithCaller().Error("Failed to start database optimize", h.logger.Args("error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start database optimize"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetOptimizeJob returns the progress of a recent optimize job
+func (h *SystemHandler) GetOptimizeJob(c *gin.Context) {
+	if h.cleanupService == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Database maintenance is not available"})
+		return
+	}
+
+	job, ok := h.cleanupService.OptimizeStatus(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Optimize job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
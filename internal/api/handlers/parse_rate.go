@@ -0,0 +1,102 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"loglynx/internal/ingestion"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetParseStats attaches the recorder backing GetSourceParseRate
+func (h *SystemHandler) SetParseStats(recorder *ingestion.ParseStatsRecorder) {
+	h.parseStats = recorder
+}
+
+// GetSourceParseRate returns the parsed/failed/skipped/duplicate line counts of a
+// log source over time. A drop in success rate usually means the log format changed.
+// Query params: hours (default 24, max 8760), interval (e.g. 15m, 1h; default = recorded bucket)
+func (h *SystemHandler) GetSourceParseRate(c *gin.Context) {
+	if h.parseStats == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Parse stats are disabled (PARSE_STATS_ENABLED=false)"})
+		return
+	}
+
+	source := c.Param("name")
+
+	hours := 24
+	if hoursParam := c.Query("hours"); hoursParam != "" {
+		if val, err := strconv.Atoi(hoursParam); err == nil && val > 0 {
+			hours = val
+		}
+	}
+	if hours > 8760 {
+		hours = 8760
+	}
+
+	interval := h.parseStats.Bucket()
+	if intervalParam := c.Query("interval"); intervalParam != "" {
+		val, err := time.ParseDuration(intervalParam)
+		if err != nil || val <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval, expected a duration such as 15m or 1h"})
+			return
+		}
+		interval = val
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	timeline, err := h.parseStats.Timeline(source, since, interval)
+	if err != nil {
+		h.logger.WithCaller().Error("Failed to get parse rate timeline",
+			h.logger.Args("source", source, "error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get parse rate timeline"})
+		return
+	}
+
+	var parsed, failed, skipped, duplicates int64
+	for _, point := range timeline {
+		parsed += point.Parsed
+		failed += point.Failed
+		skipped += point.Skipped
+		duplicates += point.Duplicates
+	}
+	var successRate *float64
+	if lines := parsed + failed + skipped; lines > 0 {
+		rate := float64(parsed) / float64(lines) * 100
+		successRate = &rate
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"source":       source,
+		"hours":        hours,
+		"timeline":     timeline,
+		"parsed":       parsed,
+		"failed":       failed,
+		"skipped":      skipped,
+		"duplicates":   duplicates,
+		"success_rate": successRate,
+	})
+}
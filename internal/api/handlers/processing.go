@@ -0,0 +1,103 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"loglynx/internal/database/repositories"
+	"loglynx/internal/ingestion"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProcessingProgress is the processing progress of a log source with its current rate
+// Rates are averaged since the source processor started; EtaSeconds is nil while the
+// source is caught up or nothing has been read yet
+type ProcessingProgress struct {
+	*repositories.LogProcessingStats
+	RecordsProcessed int64    `json:"records_processed"`
+	BytesPerSecond   float64  `json:"bytes_per_second"`
+	RecordsPerSecond float64  `json:"records_per_second"`
+	RemainingBytes   int64    `json:"remaining_bytes"`
+	EtaSeconds       *float64 `json:"eta_seconds"`
+}
+
+// SetProcessors attaches the source processors whose rates are reported by GetProcessingProgress
+func (h *SystemHandler) SetProcessors(processors ProcessorMetricsSource) {
+	h.processors = processors
+}
+
+// GetProcessingProgress returns the log processing progress of every source with
+// its processing rate and estimated time remaining
+func (h *SystemHandler) GetProcessingProgress(c *gin.Context) {
+	stats, err := h.statsRepo.GetLogProcessingStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get log processing stats"})
+		return
+	}
+
+	metrics := make(map[string]ingestion.ProcessorMetrics)
+	if h.processors != nil {
+		for _, m := range h.processors.GetProcessorMetrics() {
+			metrics[m.Source] = m
+		}
+	}
+
+	now := time.Now()
+	progress := make([]*ProcessingProgress, 0, len(stats))
+	for _, s := range stats {
+		m, ok := metrics[s.LogSourceName]
+		if !ok {
+			m = ingestion.ProcessorMetrics{StartedAt: now}
+		}
+		progress = append(progress, newProcessingProgress(s, m, now))
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// newProcessingProgress combines a source's stored position with its processor counters
+func newProcessingProgress(stats *repositories.LogProcessingStats, m ingestion.ProcessorMetrics, now time.Time) *ProcessingProgress {
+	p := &ProcessingProgress{
+		LogProcessingStats: stats,
+		RecordsProcessed:   m.Processed,
+	}
+
+	if remaining := stats.FileSize - stats.BytesProcessed; remaining > 0 {
+		p.RemainingBytes = remaining
+	}
+
+	elapsed := now.Sub(m.StartedAt).Seconds()
+	if elapsed <= 0 {
+		return p
+	}
+	p.BytesPerSecond = float64(m.BytesRead) / elapsed
+	p.RecordsPerSecond = float64(m.Processed) / elapsed
+
+	if p.RemainingBytes > 0 && p.BytesPerSecond > 0 {
+		eta := float64(p.RemainingBytes) / p.BytesPerSecond
+		p.EtaSeconds = &eta
+	}
+	return p
+}
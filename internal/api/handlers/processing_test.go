@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/repositories"
+	"loglynx/internal/ingestion"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProcessingProgress(t *testing.T) {
+	now := time.Now()
+	stats := &repositories.LogProcessingStats{LogSourceName: "traefik", FileSize: 10000, BytesProcessed: 4000}
+	metrics := ingestion.ProcessorMetrics{Source: "traefik", Processed: 200, BytesRead: 4000, StartedAt: now.Add(-10 * time.Second)}
+
+	p := newProcessingProgress(stats, metrics, now)
+	assert.Equal(t, int64(200), p.RecordsProcessed)
+	assert.InDelta(t, 400.0, p.BytesPerSecond, 0.001)
+	assert.InDelta(t, 20.0, p.RecordsPerSecond, 0.001)
+	assert.Equal(t, int64(6000), p.RemainingBytes)
+	require.NotNil(t, p.EtaSeconds)
+	assert.InDelta(t, 15.0, *p.EtaSeconds, 0.001)
+
+	// Caught up: no ETA even though there is a rate
+	caughtUp := &repositories.LogProcessingStats{LogSourceName: "traefik", FileSize: 4000, BytesProcessed: 4000}
+	p = newProcessingProgress(caughtUp, metrics, now)
+	assert.Zero(t, p.RemainingBytes)
+	assert.Nil(t, p.EtaSeconds)
+
+	// Nothing read since start: no rate, no ETA
+	p = newProcessingProgress(stats, ingestion.ProcessorMetrics{StartedAt: now}, now)
+	assert.Zero(t, p.BytesPerSecond)
+	assert.Nil(t, p.EtaSeconds)
+}
@@ -22,33 +22,112 @@
 package handlers
 
 import (
+	"fmt"
+	"loglynx/internal/database/models"
 	"loglynx/internal/database/repositories"
 	"loglynx/internal/realtime"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pterm/pterm"
 )
 
+const (
+	streamDefaultInterval = 1 * time.Second // Push cadence when ?interval= is not given
+	streamMinInterval     = 500 * time.Millisecond
+	streamMaxInterval     = 10 * time.Second
+	sseHeartbeatInterval  = 15 * time.Second // Keep-alive for proxies that close idle streams
+)
+
 // RealtimeHandler handles real-time metrics requests
 type RealtimeHandler struct {
-	collector *realtime.MetricsCollector
-	logger    *pterm.Logger
+	collector     *realtime.MetricsCollector
+	logger        *pterm.Logger
+	binaryEnabled bool // Allow compact binary frames when requested via Accept header
+
+	alerts *realtime.AlertEngine // Nil when alerting is disabled
+
+	excludeInternal bool // Hide INTERNAL_NETWORKS traffic unless include_internal=true
+
+	maxConnections int // Max concurrent SSE, binary and WebSocket streams (0 = unlimited)
+
+	closing chan struct{} // Closed on Shutdown; hijacked WebSocket streams are not ended by the HTTP server
 }
 
 // NewRealtimeHandler creates a new real-time handler
-func NewRealtimeHandler(collector *realtime.MetricsCollector, logger *pterm.Logger) *RealtimeHandler {
+func NewRealtimeHandler(collector *realtime.MetricsCollector, logger *pterm.Logger, binaryEnabled bool) *RealtimeHandler {
 	return &RealtimeHandler{
-		collector: collector,
-		logger:    logger,
+		collector:     collector,
+		logger:        logger,
+		binaryEnabled: binaryEnabled,
+		closing:       make(chan struct{}),
 	}
 }
 
+// SetAlertEngine attaches the alert engine served by GetActiveAlerts
+func (h *RealtimeHandler) SetAlertEngine(engine *realtime.AlertEngine) {
+	h.alerts = engine
+}
+
+// SetExcludeInternal hides requests from internal networks by default
+func (h *RealtimeHandler) SetExcludeInternal(exclude bool) {
+	h.excludeInternal = exclude
+}
+
+// SetMaxConnections limits the number of concurrent real-time streams
+func (h *RealtimeHandler) SetMaxConnections(max int) {
+	h.maxConnections = max
+}
+
+// acquireStream reserves a stream slot or answers 503 when the limit is reached
+// Callers must release the slot with AdjustActiveConnections(-1)
+func (h *RealtimeHandler) acquireStream(c *gin.Context) bool {
+	if h.collector.TryAcquireConnection(h.maxConnections) {
+		return true
+	}
+	h.logger.Warn("Rejected real-time stream, connection limit reached",
+		h.logger.Args("client_ip", c.ClientIP(), "max_connections", h.maxConnections))
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many real-time connections"})
+	return false
+}
+
+// GetActiveAlerts returns the currently firing alerts
+func (h *RealtimeHandler) GetActiveAlerts(c *gin.Context) {
+	if h.alerts == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"enabled": false,
+			"alerts":  []realtime.Alert{},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"alerts":  h.alerts.GetActiveAlerts(),
+	})
+}
+
+// wantsBinary reports whether the client asked for binary frames and they are enabled
+func (h *RealtimeHandler) wantsBinary(c *gin.Context) bool {
+	return h.binaryEnabled && strings.Contains(c.GetHeader("Accept"), realtime.BinaryContentType)
+}
+
 // getServiceFilter extracts service filter parameters from request
 // Supported: service (auto), service_type (backend_name, backend_url, host)
 func (h *RealtimeHandler) getServiceFilter(c *gin.Context) (string, string) {
 	service := c.Query("service")
 	serviceType := c.Query("service_type")
+	if service == "" {
+		if host := c.Query("host"); host != "" {
+			service = host
+			if serviceType == "" {
+				serviceType = "host"
+			}
+		}
+	}
 	return service, serviceType
 }
 
@@ -102,15 +181,9 @@ func (h *RealtimeHandler) getExcludeOwnIP(c *gin.Context) *realtime.ExcludeIPFil
 	manualIPs := c.QueryArray("excluded_ips[]")
 
 	// Check if current IP should be excluded
-	excludeOwn := c.Query("exclude_own_ip") == "true"
-
-	allIPs := manualIPs
-	if excludeOwn {
-		allIPs = append(allIPs, c.ClientIP())
-	}
-
-	if len(allIPs) == 0 {
-		return nil
+	ownIP := ""
+	if c.Query("exclude_own_ip") == "true" {
+		ownIP = c.ClientIP()
 	}
 
 	// Get exclude services
@@ -128,39 +201,106 @@ func (h *RealtimeHandler) getExcludeOwnIP(c *gin.Context) *realtime.ExcludeIPFil
 		}
 	}
 
+	return h.newExcludeIPFilter(manualIPs, ownIP, c.Query("include_internal") == "true", excludeServices)
+}
+
+// newExcludeIPFilter combines manual and own-IP exclusions with the internal network default
+// Returns nil when nothing is excluded
+func (h *RealtimeHandler) newExcludeIPFilter(manualIPs []string, ownIP string, includeInternal bool, excludeServices []realtime.ServiceFilter) *realtime.ExcludeIPFilter {
+	// Stored client IPs are normalized, so "::ffff:1.2.3.4" must exclude "1.2.3.4"
+	allIPs := make([]string, 0, len(manualIPs)+1)
+	for _, ip := range manualIPs {
+		allIPs = append(allIPs, models.NormalizeIP(ip))
+	}
+	if ownIP != "" {
+		allIPs = append(allIPs, models.NormalizeIP(ownIP))
+	}
+
+	excludeInternal := h.excludeInternal && !includeInternal
+
+	if len(allIPs) == 0 {
+		if excludeInternal {
+			return &realtime.ExcludeIPFilter{ExcludeInternal: true}
+		}
+		return nil
+	}
+
 	return &realtime.ExcludeIPFilter{
 		ClientIPs:       allIPs,
 		ExcludeServices: excludeServices,
+		ExcludeInternal: excludeInternal,
+	}
+}
+
+// parseStreamInterval reads the push interval from ?interval=
+// Accepts a Go duration ("2s", "750ms") or plain milliseconds, clamped to 500ms-10s
+func parseStreamInterval(raw string) (time.Duration, error) {
+	if raw == "" {
+		return streamDefaultInterval, nil
+	}
+
+	interval, err := time.ParseDuration(raw)
+	if err != nil {
+		ms, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			return 0, fmt.Errorf("invalid interval %q: use a duration like 2s or milliseconds", raw)
+		}
+		interval = time.Duration(ms) * time.Millisecond
+	}
+
+	if interval < streamMinInterval {
+		return streamMinInterval, nil
+	}
+	if interval > streamMaxInterval {
+		return streamMaxInterval, nil
 	}
+	return interval, nil
 }
 
 // StreamMetrics streams real-time metrics via Server-Sent Events
+// Snapshots are sent as named "metrics" events, with a "heartbeat" event every 15 seconds
+// Clients sending "Accept: application/x-loglynx-metrics" receive length-prefixed binary frames instead
 func (h *RealtimeHandler) StreamMetrics(c *gin.Context) {
+	interval, err := parseStreamInterval(c.Query("interval"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.acquireStream(c) {
+		return
+	}
+	defer h.collector.AdjustActiveConnections(-1)
+
+	if h.wantsBinary(c) {
+		h.streamBinaryMetrics(c, interval)
+		return
+	}
+
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
 	c.Header("Transfer-Encoding", "chunked")
 
 	// Get filters
+	sourceName := c.Query("source")
 	serviceName, _ := h.getServiceFilter(c)
 	serviceFilters := h.getServiceFilters(c)
 	excludeIPFilter := h.getExcludeOwnIP(c)
 
-	// Add a channel to keep track of active connections
-	h.collector.AdjustActiveConnections(1)
-	defer h.collector.AdjustActiveConnections(-1)
-
 	// Track connection state
 	notify := c.Request.Context().Done()
 
-	// Create ticker for periodic updates (every 1 second)
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
 
-	// Wave 2: Detailed logging for connection status
-	currentConnections := 0 // This would ideally come from collector but simpler for logging
 	h.logger.Debug("New SSE connection established",
-		h.logger.Args("client_ip", c.ClientIP(), "host_filter", serviceName, "exclude_own_ip", excludeIPFilter != nil, "active_connections", currentConnections))
+		h.logger.Args("client_ip", c.ClientIP(), "host_filter", serviceName, "exclude_own_ip", excludeIPFilter != nil, "interval", interval))
+
+	// Send the first snapshot right away instead of waiting a full tick
+	h.sendSSEMetrics(c, sourceName, serviceName, serviceFilters, excludeIPFilter)
 
 	for {
 		select {
@@ -168,47 +308,111 @@ func (h *RealtimeHandler) StreamMetrics(c *gin.Context) {
 			h.logger.Debug("SSE connection closed by client", h.logger.Args("client_ip", c.ClientIP()))
 			return
 		case <-ticker.C:
-			var metrics *realtime.RealtimeMetrics
-
-			// Optimized: if no filter, use global cached JSON
-			if serviceName == "" && len(serviceFilters) == 0 && excludeIPFilter == nil {
-				jsonBytes := h.collector.GetCachedJSON()
-				if jsonBytes != nil {
-					c.SSEvent("message", string(jsonBytes))
-					c.Writer.Flush()
-					continue
-				}
-				// Fallback to GetMetrics if cache empty
-				metrics = h.collector.GetMetrics()
-			} else if len(serviceFilters) > 0 || excludeIPFilter != nil {
-				// Use new filter-aware method
-				metrics = h.collector.GetMetricsWithFilters(serviceName, serviceFilters, excludeIPFilter)
-			} else {
-				// Use host-specific method (legacy)
-				metrics = h.collector.GetMetricsWithHost(serviceName)
-			}
+			h.sendSSEMetrics(c, sourceName, serviceName, serviceFilters, excludeIPFilter)
+		case <-heartbeat.C:
+			c.SSEvent("heartbeat", gin.H{"timestamp": time.Now().Unix(), "interval_ms": interval.Milliseconds()})
+			c.Writer.Flush()
+		}
+	}
+}
+
+// sendSSEMetrics writes one "metrics" event matching the requested filters
+// Streams reuse pre-encoded JSON: the collector's for unfiltered and per-source streams,
+// and the shared per-tick snapshot for filtered ones
+func (h *RealtimeHandler) sendSSEMetrics(c *gin.Context, sourceName string, serviceName string, serviceFilters []realtime.ServiceFilter, excludeIPFilter *realtime.ExcludeIPFilter) {
+	jsonBytes := h.cachedMetricsJSON(sourceName, serviceName, serviceFilters, excludeIPFilter)
+
+	if jsonBytes != nil {
+		c.SSEvent("metrics", string(jsonBytes))
+	} else {
+		metrics := h.selectMetrics(sourceName, serviceName, serviceFilters, excludeIPFilter)
+		if metrics == nil {
+			return
+		}
+		c.SSEvent("metrics", metrics)
+	}
+	c.Writer.Flush()
+}
+
+// streamBinaryMetrics streams real-time metrics as compact binary frames
+// Each frame is prefixed with its length as a big-endian uint32
+func (h *RealtimeHandler) streamBinaryMetrics(c *gin.Context, interval time.Duration) {
+	c.Header("Content-Type", realtime.BinaryContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	sourceName := c.Query("source")
+	serviceName, _ := h.getServiceFilter(c)
+	serviceFilters := h.getServiceFilters(c)
+	excludeIPFilter := h.getExcludeOwnIP(c)
+
+	notify := c.Request.Context().Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-			if metrics != nil {
-				c.SSEvent("message", metrics)
-				c.Writer.Flush()
+	h.logger.Debug("New binary stream connection established",
+		h.logger.Args("client_ip", c.ClientIP(), "host_filter", serviceName, "exclude_own_ip", excludeIPFilter != nil, "interval", interval))
+
+	for {
+		select {
+		case <-notify:
+			h.logger.Debug("Binary stream connection closed by client", h.logger.Args("client_ip", c.ClientIP()))
+			return
+		case <-ticker.C:
+			metrics := h.selectMetrics(sourceName, serviceName, serviceFilters, excludeIPFilter)
+			if metrics == nil {
+				continue
 			}
+			if err := realtime.WriteBinaryFrame(c.Writer, realtime.EncodeBinary(metrics)); err != nil {
+				h.logger.Debug("Binary stream write failed", h.logger.Args("client_ip", c.ClientIP(), "error", err))
+				return
+			}
+			c.Writer.Flush()
 		}
 	}
 }
 
+// selectMetrics returns the metrics snapshot matching the requested filters
+// A log source selection takes precedence over service and IP filters.
+// Filtered snapshots are shared with every stream using the same filters during a tick
+func (h *RealtimeHandler) selectMetrics(sourceName string, serviceName string, serviceFilters []realtime.ServiceFilter, excludeIPFilter *realtime.ExcludeIPFilter) *realtime.RealtimeMetrics {
+	if sourceName != "" {
+		return h.collector.GetSourceMetrics(sourceName)
+	} else if len(serviceFilters) > 0 || excludeIPFilter != nil {
+		return h.collector.GetSharedMetricsWithFilters(serviceName, serviceFilters, excludeIPFilter)
+	} else if serviceName != "" {
+		return h.collector.GetSharedMetricsWithFilters(serviceName, nil, nil)
+	}
+	return h.collector.GetMetrics()
+}
+
+// cachedMetricsJSON returns pre-encoded metrics matching the filters, or nil when a source
+// has no cached snapshot and must be computed with selectMetrics
+func (h *RealtimeHandler) cachedMetricsJSON(sourceName string, serviceName string, serviceFilters []realtime.ServiceFilter, excludeIPFilter *realtime.ExcludeIPFilter) []byte {
+	if sourceName != "" {
+		return h.collector.GetSourceCachedJSON(sourceName)
+	} else if len(serviceFilters) > 0 || excludeIPFilter != nil {
+		return h.collector.GetSharedFilteredJSON(serviceName, serviceFilters, excludeIPFilter)
+	} else if serviceName != "" {
+		return h.collector.GetSharedFilteredJSON(serviceName, nil, nil)
+	}
+	return h.collector.GetCachedJSON()
+}
+
 // GetCurrentMetrics returns a single snapshot of real-time metrics
 func (h *RealtimeHandler) GetCurrentMetrics(c *gin.Context) {
+	sourceName := c.Query("source")
 	serviceName, _ := h.getServiceFilter(c)
 	serviceFilters := h.getServiceFilters(c)
 	excludeIPFilter := h.getExcludeOwnIP(c)
 
-	var metrics *realtime.RealtimeMetrics
-	if len(serviceFilters) > 0 || excludeIPFilter != nil {
-		metrics = h.collector.GetMetricsWithFilters(serviceName, serviceFilters, excludeIPFilter)
-	} else if serviceName != "" {
-		metrics = h.collector.GetMetricsWithHost(serviceName)
-	} else {
-		metrics = h.collector.GetMetrics()
+	metrics := h.selectMetrics(sourceName, serviceName, serviceFilters, excludeIPFilter)
+
+	if h.wantsBinary(c) {
+		c.Data(http.StatusOK, realtime.BinaryContentType, realtime.EncodeBinary(metrics))
+		return
 	}
 
 	c.JSON(200, metrics)
@@ -231,7 +435,8 @@ func (h *RealtimeHandler) GetPerServiceMetrics(c *gin.Context) {
 	var repoExcludeIP *repositories.ExcludeIPFilter
 	if excludeIPFilter != nil {
 		repoExcludeIP = &repositories.ExcludeIPFilter{
-			ClientIPs: excludeIPFilter.ClientIPs,
+			ClientIPs:       excludeIPFilter.ClientIPs,
+			ExcludeInternal: excludeIPFilter.ExcludeInternal,
 		}
 		if len(excludeIPFilter.ExcludeServices) > 0 {
 			repoExcludeIP.ExcludeServices = make([]repositories.ServiceFilter, len(excludeIPFilter.ExcludeServices))
@@ -245,7 +450,8 @@ func (h *RealtimeHandler) GetPerServiceMetrics(c *gin.Context) {
 	c.JSON(200, metrics)
 }
 
-// Shutdown stops the real-time collector
+// Shutdown ends open WebSocket streams and stops the real-time collector
 func (h *RealtimeHandler) Shutdown() {
+	close(h.closing)
 	h.collector.Stop()
 }
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"loglynx/internal/database"
+	"loglynx/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestParseStreamInterval(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want time.Duration
+	}{
+		{"", time.Second},
+		{"2s", 2 * time.Second},
+		{"750ms", 750 * time.Millisecond},
+		{"3000", 3 * time.Second},
+		{"100ms", 500 * time.Millisecond},
+		{"1m", 10 * time.Second},
+		{"-5", 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		got, err := parseStreamInterval(tt.raw)
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+
+	_, err := parseStreamInterval("soon")
+	assert.Error(t, err)
+}
+
+func TestStreamMetricsSendsInitialSnapshot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, database.RunMigrations(db))
+
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	handler := NewRealtimeHandler(realtime.NewMetricsCollector(db, logger), logger, false)
+
+	// The interval is far longer than the connection, so only the initial snapshot can arrive
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/realtime/stream?interval=10s", nil).WithContext(ctx)
+	handler.StreamMetrics(c)
+
+	body := w.Body.String()
+	assert.Equal(t, 1, strings.Count(body, "event:metrics\n"), body)
+	assert.NotContains(t, body, "event:message")
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/realtime/stream?interval=soon", nil)
+	handler.StreamMetrics(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRealtimeExcludedIPsAreNormalized(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	handler := NewRealtimeHandler(nil, logger, false)
+
+	filter := handler.newExcludeIPFilter([]string{"::ffff:1.2.3.4"}, "2001:DB8::1", false, nil)
+	require.NotNil(t, filter)
+	assert.Equal(t, []string{"1.2.3.4", "2001:db8::1"}, filter.ClientIPs)
+}
@@ -0,0 +1,210 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"loglynx/internal/realtime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	wsPushInterval = streamDefaultInterval // Same cadence as the default SSE stream
+	wsWriteTimeout = 10 * time.Second      // Drop clients that stop reading
+)
+
+// wsFilterMessage replaces the filters of an open WebSocket stream
+// Fields mirror the query parameters accepted by StreamMetrics
+type wsFilterMessage struct {
+	Source          string            `json:"source"`
+	Service         string            `json:"service"`
+	ServiceType     string            `json:"service_type"`
+	Services        []wsServiceFilter `json:"services"`
+	ExcludeOwnIP    bool              `json:"exclude_own_ip"`
+	ExcludedIPs     []string          `json:"excluded_ips"`
+	IncludeInternal bool              `json:"include_internal"`
+	ExcludeServices []wsServiceFilter `json:"exclude_services"`
+}
+
+// wsServiceFilter is a service filter as sent by WebSocket clients
+type wsServiceFilter struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// wsStreamFilters holds the filters applied to each push
+type wsStreamFilters struct {
+	source    string
+	service   string
+	services  []realtime.ServiceFilter
+	excludeIP *realtime.ExcludeIPFilter
+}
+
+// StreamMetricsWebSocket pushes real-time metrics over a WebSocket every second
+// Initial filters come from the StreamMetrics query parameters; clients change them
+// by sending a JSON wsFilterMessage instead of reconnecting
+func (h *RealtimeHandler) StreamMetricsWebSocket(c *gin.Context) {
+	if !h.acquireStream(c) {
+		return
+	}
+	defer h.collector.AdjustActiveConnections(-1)
+
+	serviceName, _ := h.getServiceFilter(c)
+	filters := wsStreamFilters{
+		source:    c.Query("source"),
+		service:   serviceName,
+		services:  h.getServiceFilters(c),
+		excludeIP: h.getExcludeOwnIP(c),
+	}
+	clientIP := c.ClientIP()
+
+	server := websocket.Server{
+		// The API allows any origin (see corsMiddleware), so the handshake does too
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			h.serveWebSocket(ws, clientIP, filters)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveWebSocket runs the push loop while a reader goroutine applies filter messages
+func (h *RealtimeHandler) serveWebSocket(ws *websocket.Conn, clientIP string, filters wsStreamFilters) {
+	defer ws.Close()
+
+	h.logger.Debug("New WebSocket connection established",
+		h.logger.Args("client_ip", clientIP, "host_filter", filters.service, "exclude_own_ip", filters.excludeIP != nil))
+
+	updates := make(chan wsStreamFilters)
+	closed := make(chan struct{}) // Reader stopped: client went away
+	done := make(chan struct{})   // Push loop stopped
+	defer close(done)
+
+	go func() {
+		defer close(closed)
+		for {
+			var data []byte
+			if err := websocket.Message.Receive(ws, &data); err != nil {
+				if !errors.Is(err, io.EOF) {
+					h.logger.Debug("WebSocket read failed", h.logger.Args("client_ip", clientIP, "error", err))
+				}
+				return
+			}
+
+			var msg wsFilterMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				h.logger.Debug("Ignoring malformed WebSocket filter message", h.logger.Args("client_ip", clientIP, "error", err))
+				continue
+			}
+
+			select {
+			case updates <- h.filtersFromMessage(msg, clientIP):
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			h.logger.Debug("WebSocket connection closed by client", h.logger.Args("client_ip", clientIP))
+			return
+		case <-h.closing:
+			return
+		case filters = <-updates:
+			// Answer a filter change right away rather than on the next tick
+			if err := h.pushMetrics(ws, filters); err != nil {
+				h.logger.Debug("WebSocket write failed", h.logger.Args("client_ip", clientIP, "error", err))
+				return
+			}
+		case <-ticker.C:
+			if err := h.pushMetrics(ws, filters); err != nil {
+				h.logger.Debug("WebSocket write failed", h.logger.Args("client_ip", clientIP, "error", err))
+				return
+			}
+		}
+	}
+}
+
+// pushMetrics sends one RealtimeMetrics payload, reusing cached or shared JSON when available
+func (h *RealtimeHandler) pushMetrics(ws *websocket.Conn, filters wsStreamFilters) error {
+	if err := ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+
+	if cached := h.cachedMetricsJSON(filters.source, filters.service, filters.services, filters.excludeIP); cached != nil {
+		return websocket.Message.Send(ws, string(cached))
+	}
+
+	metrics := h.selectMetrics(filters.source, filters.service, filters.services, filters.excludeIP)
+	if metrics == nil {
+		return nil
+	}
+	return websocket.JSON.Send(ws, metrics)
+}
+
+// filtersFromMessage converts a client filter message, applying the same defaults as the query parameters
+func (h *RealtimeHandler) filtersFromMessage(msg wsFilterMessage, clientIP string) wsStreamFilters {
+	services := make([]realtime.ServiceFilter, 0, len(msg.Services))
+	for _, s := range msg.Services {
+		if s.Name == "" {
+			continue
+		}
+		serviceType := s.Type
+		if serviceType == "" {
+			serviceType = "auto"
+		}
+		services = append(services, realtime.ServiceFilter{Name: s.Name, Type: serviceType})
+	}
+	if len(services) == 0 && msg.Service != "" {
+		services = append(services, realtime.ServiceFilter{Name: msg.Service, Type: msg.ServiceType})
+	}
+
+	var excludeServices []realtime.ServiceFilter
+	for _, s := range msg.ExcludeServices {
+		if s.Name != "" && s.Type != "" {
+			excludeServices = append(excludeServices, realtime.ServiceFilter{Name: s.Name, Type: s.Type})
+		}
+	}
+
+	ownIP := ""
+	if msg.ExcludeOwnIP {
+		ownIP = clientIP
+	}
+
+	return wsStreamFilters{
+		source:    msg.Source,
+		service:   msg.Service,
+		services:  services,
+		excludeIP: h.newExcludeIPFilter(msg.ExcludedIPs, ownIP, msg.IncludeInternal, excludeServices),
+	}
+}
@@ -0,0 +1,93 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"loglynx/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+)
+
+// ReplayHandler replays stored requests into the real-time stream for demos and debugging
+type ReplayHandler struct {
+	replayer *realtime.Replayer
+	logger   *pterm.Logger
+}
+
+// NewReplayHandler creates a new replay handler
+func NewReplayHandler(replayer *realtime.Replayer, logger *pterm.Logger) *ReplayHandler {
+	return &ReplayHandler{
+		replayer: replayer,
+		logger:   logger,
+	}
+}
+
+// StartReplay replays the requests stored in a time window into the real-time metrics.
+// Body: {"start": RFC3339, "end": RFC3339, "speed": multiplier (default 1)}
+func (h *ReplayHandler) StartReplay(c *gin.Context) {
+	var request struct {
+		Start time.Time `json:"start" binding:"required"`
+		End   time.Time `json:"end" binding:"required"`
+		Speed float64   `json:"speed"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Speed == 0 {
+		request.Speed = 1
+	}
+
+	status, err := h.replayer.Start(request.Start, request.End, request.Speed)
+	switch {
+	case errors.Is(err, realtime.ErrReplayRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "status": status})
+	case errors.Is(err, realtime.ErrReplayEmpty):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, realtime.ErrInvalidReplay):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		h.logger.WithCaller().Error("Failed to start replay", h.logger.Args("error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start replay"})
+	default:
+		c.JSON(http.StatusAccepted, status)
+	}
+}
+
+// GetReplayStatus returns the state of the current or last replay
+func (h *ReplayHandler) GetReplayStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.replayer.Status())
+}
+
+// StopReplay ends the running replay
+func (h *ReplayHandler) StopReplay(c *gin.Context) {
+	if !h.replayer.Stop() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No replay is running"})
+		return
+	}
+	c.JSON(http.StatusOK, h.replayer.Status())
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"loglynx/internal/database/models"
+	"loglynx/internal/database/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newRequestDetailTestHandler returns a dashboard handler over an in-memory database holding
+// one request with proxy metadata (ID 1) and one without (ID 2)
+func newRequestDetailTestHandler(t *testing.T) *DashboardHandler {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.HTTPRequest{}))
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "detail-1", ClientIP: "203.0.113.7", Method: "GET", Path: "/missing", StatusCode: 404, ProxyMetadata: `{"forwarded_for":["203.0.113.7","10.0.0.1"]}`},
+		{RequestHash: "detail-2", ClientIP: "203.0.113.8", Method: "GET", Path: "/", StatusCode: 200},
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	return NewDashboardHandler(nil, repositories.NewHTTPRequestRepository(db, logger), logger)
+}
+
+func runGetRequest(handler *DashboardHandler, id string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/requests/"+id, nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	handler.GetRequest(c)
+	return w
+}
+
+func TestGetRequestDecodesProxyMetadata(t *testing.T) {
+	handler := newRequestDetailTestHandler(t)
+
+	w := runGetRequest(handler, "1")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "/missing", body["Path"])
+	assert.Equal(t, float64(404), body["StatusCode"])
+	assert.Equal(t, map[string]any{"forwarded_for": []any{"203.0.113.7", "10.0.0.1"}}, body["ProxyMetadata"])
+
+	w = runGetRequest(handler, "2")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Nil(t, body["ProxyMetadata"])
+}
+
+func TestGetRequestErrors(t *testing.T) {
+	handler := newRequestDetailTestHandler(t)
+
+	assert.Equal(t, http.StatusNotFound, runGetRequest(handler, "99").Code)
+	assert.Equal(t, http.StatusBadRequest, runGetRequest(handler, "abc").Code)
+	assert.Equal(t, http.StatusBadRequest, runGetRequest(handler, "0").Code)
+}
@@ -0,0 +1,188 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"loglynx/internal/database/models"
+	"loglynx/internal/ingestion"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SourceManager registers and unregisters log sources while ingestion is running
+type SourceManager interface {
+	ProcessorMetricsSource
+	ListSources() ([]*models.LogSource, error)
+	AddSource(source *models.LogSource, sample string) error
+	RemoveSource(name string) error
+	ExportSources() ([]ingestion.SourceState, error)
+	ImportSources(states []ingestion.SourceState) (*ingestion.SourceImportResult, error)
+}
+
+// SourceStatus is a registered log source with the counters of its running processors
+type SourceStatus struct {
+	Name          string     `json:"name"`
+	Path          string     `json:"path"`
+	ParserType    string     `json:"parser_type"`
+	Managed       bool       `json:"managed"`
+	RetentionDays int        `json:"retention_days"`
+	SampleRate    int        `json:"sample_rate"` // Keeps 1 in N requests (0 = all)
+	LastReadAt    *time.Time `json:"last_read_at"`
+	Running       bool       `json:"running"`
+	Processed     int64      `json:"processed"`
+	ParseErrors   int64      `json:"parse_errors"`
+	InsertErrors  int64      `json:"insert_errors"`
+	Filtered      int64      `json:"filtered"`
+	Sampled       int64      `json:"sampled"`
+	BytesRead     int64      `json:"bytes_read"`
+	StartedAt     *time.Time `json:"started_at"`
+}
+
+// createSourceRequest is the body of CreateSource
+type createSourceRequest struct {
+	Name       string `json:"name" binding:"required"`
+	Path       string `json:"path" binding:"required"`
+	ParserType string `json:"parser_type" binding:"required"`
+	Sample     string `json:"sample"` // Line checked with the parser; defaults to the file's first line
+}
+
+// SetSources attaches the coordinator used to list, add and remove log sources
+func (h *SystemHandler) SetSources(sources SourceManager) {
+	h.sources = sources
+}
+
+// ListSources returns every registered log source with its processing counters
+func (h *SystemHandler) ListSources(c *gin.Context) {
+	if h.sources == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log source management is unavailable"})
+		return
+	}
+
+	sources, err := h.sources.ListSources()
+	if err != nil {
+		h.logger.WithCaller().Error("Failed to list log sources", h.logger.Args("error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list log sources"})
+		return
+	}
+
+	metrics := make(map[string]ingestion.ProcessorMetrics)
+	for _, m := range h.sources.GetProcessorMetrics() {
+		metrics[m.Source] = m
+	}
+
+	statuses := make([]SourceStatus, 0, len(sources))
+	for _, source := range sources {
+		status := SourceStatus{
+			Name:          source.Name,
+			Path:          source.Path,
+			ParserType:    source.ParserType,
+			Managed:       source.Managed,
+			RetentionDays: source.RetentionDays,
+			SampleRate:    source.SampleRate,
+			LastReadAt:    source.LastReadAt,
+		}
+		if m, ok := metrics[source.Name]; ok {
+			startedAt := m.StartedAt
+			status.Running = true
+			status.Processed = m.Processed
+			status.ParseErrors = m.ParseErrors
+			status.InsertErrors = m.InsertErrors
+			status.Filtered = m.Filtered
+			status.Sampled = m.Sampled
+			status.BytesRead = m.BytesRead
+			status.StartedAt = &startedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
+
+// CreateSource registers a log source and starts following it without a restart.
+// The parser must accept the sample line, or the file's first line when none is given.
+func (h *SystemHandler) CreateSource(c *gin.Context) {
+	if h.sources == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log source management is unavailable"})
+		return
+	}
+
+	var req createSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source: name, path and parser_type are required"})
+		return
+	}
+	source := &models.LogSource{
+		Name:       strings.TrimSpace(req.Name),
+		Path:       strings.TrimSpace(req.Path),
+		ParserType: strings.TrimSpace(req.ParserType),
+	}
+	if source.Name == "" || source.Path == "" || source.ParserType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source: name, path and parser_type are required"})
+		return
+	}
+
+	err := h.sources.AddSource(source, strings.TrimSpace(req.Sample))
+	switch {
+	case errors.Is(err, ingestion.ErrUnknownParser), errors.Is(err, ingestion.ErrSampleNotParsable),
+		errors.Is(err, ingestion.ErrSourceCredentials):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ingestion.ErrSourceExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		h.logger.WithCaller().Error("Failed to add log source", h.logger.Args("source", source.Name, "error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add log source"})
+	default:
+		c.JSON(http.StatusCreated, SourceStatus{
+			Name:       source.Name,
+			Path:       source.Path,
+			ParserType: source.ParserType,
+		})
+	}
+}
+
+// DeleteSource stops a log source's processors and unregisters it; its stored requests are kept.
+// Sources declared in LOG_SOURCES_FILE cannot be removed here.
+func (h *SystemHandler) DeleteSource(c *gin.Context) {
+	if h.sources == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log source management is unavailable"})
+		return
+	}
+
+	name := c.Param("name")
+	err := h.sources.RemoveSource(name)
+	switch {
+	case errors.Is(err, ingestion.ErrSourceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ingestion.ErrSourceManaged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		h.logger.WithCaller().Error("Failed to remove log source", h.logger.Args("source", name, "error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove log source"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Log source removed", "name": name})
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+	"loglynx/internal/ingestion"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSourceManager keeps sources in memory, failing like the coordinator
+type fakeSourceManager struct {
+	sources []*models.LogSource
+	metrics []ingestion.ProcessorMetrics
+}
+
+func (f *fakeSourceManager) GetProcessorMetrics() []ingestion.ProcessorMetrics {
+	return f.metrics
+}
+
+func (f *fakeSourceManager) ListSources() ([]*models.LogSource, error) {
+	return f.sources, nil
+}
+
+func (f *fakeSourceManager) AddSource(source *models.LogSource, sample string) error {
+	if source.ParserType != "traefik" {
+		return fmt.Errorf("%w: %s", ingestion.ErrUnknownParser, source.ParserType)
+	}
+	if sample == "garbage" {
+		return ingestion.ErrSampleNotParsable
+	}
+	for _, current := range f.sources {
+		if current.Name == source.Name {
+			return ingestion.ErrSourceExists
+		}
+	}
+	f.sources = append(f.sources, source)
+	return nil
+}
+
+func (f *fakeSourceManager) RemoveSource(name string) error {
+	for i, current := range f.sources {
+		if current.Name != name {
+			continue
+		}
+		if current.Managed {
+			return ingestion.ErrSourceManaged
+		}
+		f.sources = append(f.sources[:i], f.sources[i+1:]...)
+		return nil
+	}
+	return ingestion.ErrSourceNotFound
+}
+
+func (f *fakeSourceManager) ExportSources() ([]ingestion.SourceState, error) {
+	states := make([]ingestion.SourceState, 0, len(f.sources))
+	for _, source := range f.sources {
+		states = append(states, ingestion.SourceState{Name: source.Name, Path: source.Path, ParserType: source.ParserType, LastPosition: source.LastPosition})
+	}
+	return states, nil
+}
+
+func (f *fakeSourceManager) ImportSources(states []ingestion.SourceState) (*ingestion.SourceImportResult, error) {
+	result := &ingestion.SourceImportResult{}
+	for _, state := range states {
+		f.sources = append(f.sources, &models.LogSource{Name: state.Name, Path: state.Path, ParserType: state.ParserType, LastPosition: state.LastPosition})
+		result.Created = append(result.Created, state.Name)
+	}
+	return result, nil
+}
+
+func newSourcesTestHandler() (*SystemHandler, *fakeSourceManager) {
+	gin.SetMode(gin.TestMode)
+	manager := &fakeSourceManager{}
+	handler := &SystemHandler{logger: pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)}
+	handler.SetSources(manager)
+	return handler, manager
+}
+
+func runSourceRequest(handler gin.HandlerFunc, method string, body string, name string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/api/v1/sources", strings.NewReader(body))
+	if name != "" {
+		c.Params = gin.Params{{Key: "name", Value: name}}
+	}
+	handler(c)
+	return w
+}
+
+func TestCreateAndListSources(t *testing.T) {
+	handler, manager := newSourcesTestHandler()
+
+	w := runSourceRequest(handler.CreateSource, http.MethodPost, `{"name":"web","path":"/logs/web.log","parser_type":"traefik"}`, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	for body, code := range map[string]int{
+		`{"name":"web","path":"/logs/other.log","parser_type":"traefik"}`:                  http.StatusConflict,
+		`{"name":"api","path":"/logs/api.log","parser_type":"nginx"}`:                      http.StatusBadRequest,
+		`{"name":"api","path":"/logs/api.log","parser_type":"traefik","sample":"garbage"}`: http.StatusBadRequest,
+		`{"name":" ","path":"/logs/api.log","parser_type":"traefik"}`:                      http.StatusBadRequest,
+		`{"path":"/logs/api.log","parser_type":"traefik"}`:                                 http.StatusBadRequest,
+	} {
+		assert.Equal(t, code, runSourceRequest(handler.CreateSource, http.MethodPost, body, "").Code, body)
+	}
+
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager.sources = append(manager.sources, &models.LogSource{Name: "idle", Path: "/logs/idle.log", ParserType: "caddy"})
+	manager.metrics = []ingestion.ProcessorMetrics{{Source: "web", Processed: 42, ParseErrors: 3, StartedAt: started}}
+
+	w = runSourceRequest(handler.ListSources, http.MethodGet, "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var statuses []SourceStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "web", statuses[0].Name)
+	assert.True(t, statuses[0].Running)
+	assert.Equal(t, int64(42), statuses[0].Processed)
+	assert.Equal(t, int64(3), statuses[0].ParseErrors)
+	require.NotNil(t, statuses[0].StartedAt)
+	assert.True(t, started.Equal(*statuses[0].StartedAt))
+	assert.False(t, statuses[1].Running, "a source without processors is reported as stopped")
+	assert.Nil(t, statuses[1].StartedAt)
+}
+
+func TestDeleteSource(t *testing.T) {
+	handler, manager := newSourcesTestHandler()
+	manager.sources = []*models.LogSource{
+		{Name: "web", Path: "/logs/web.log", ParserType: "traefik"},
+		{Name: "declared", Path: "/logs/declared.log", ParserType: "caddy", Managed: true},
+	}
+
+	assert.Equal(t, http.StatusOK, runSourceRequest(handler.DeleteSource, http.MethodDelete, "", "web").Code)
+	assert.Equal(t, http.StatusNotFound, runSourceRequest(handler.DeleteSource, http.MethodDelete, "", "web").Code)
+	assert.Equal(t, http.StatusConflict, runSourceRequest(handler.DeleteSource, http.MethodDelete, "", "declared").Code)
+	assert.Len(t, manager.sources, 1)
+}
+
+func TestSourcesUnavailableWithoutManager(t *testing.T) {
+	handler := &SystemHandler{}
+	assert.Equal(t, http.StatusNotFound, runSourceRequest(handler.ListSources, http.MethodGet, "", "").Code)
+}
+
+func TestExportAndImportConfig(t *testing.T) {
+	handler, manager := newSourcesTestHandler()
+	handler.retentionDays = 30
+	handler.SetConfigSnapshot(map[string]string{"log_level": "info"})
+	manager.sources = []*models.LogSource{{Name: "web", Path: "/logs/web.log", ParserType: "traefik", LastPosition: 4096}}
+
+	w := runSourceRequest(handler.ExportConfig, http.MethodGet, "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var export ConfigExport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+	assert.Equal(t, 30, export.Retention.RetentionDays)
+	assert.Equal(t, map[string]any{"log_level": "info"}, export.Config)
+	require.Len(t, export.Sources, 1)
+	assert.Equal(t, int64(4096), export.Sources[0].LastPosition)
+
+	// Restored on an empty instance
+	target, targetManager := newSourcesTestHandler()
+	w = runSourceRequest(target.ImportConfig, http.MethodPost, w.Body.String(), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var result ingestion.SourceImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, []string{"web"}, result.Created)
+	require.Len(t, targetManager.sources, 1)
+	assert.Equal(t, int64(4096), targetManager.sources[0].LastPosition)
+
+	assert.Equal(t, http.StatusBadRequest, runSourceRequest(target.ImportConfig, http.MethodPost, "not json", "").Code)
+}
@@ -32,10 +32,14 @@ import (
 
 	"loglynx/internal/database"
 	"loglynx/internal/database/repositories"
+	"loglynx/internal/enrichment"
+	"loglynx/internal/ingestion"
+	"loglynx/internal/realtime"
 	"loglynx/internal/version"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pterm/pterm"
+	"gorm.io/gorm"
 )
 
 // SystemHandler handles system statistics requests
@@ -47,6 +51,20 @@ type SystemHandler struct {
 	startTime      time.Time
 	dbPath         string
 	retentionDays  int
+
+	parseStats *ingestion.ParseStatsRecorder // Nil when parse stats are disabled
+	deadLetter *ingestion.DeadLetterWriter   // Nil when the dead-letter log is disabled
+	processors ProcessorMetricsSource        // Nil when ingestion rates are unavailable
+	sources    SourceManager                 // Nil when sources cannot be managed at runtime
+	config     any                           // Effective configuration, redacted, included in ExportConfig
+
+	db            *gorm.DB // Nil when the connection pool and schema status are not reported
+	poolThreshold float64
+
+	// Subsystems attributed in the memory breakdown (reported only when memoryBreakdown is set)
+	memoryBreakdown bool
+	geoIP           *enrichment.GeoIPEnricher
+	collector       *realtime.MetricsCollector
 }
 
 // SystemStats holds comprehensive system statistics
@@ -64,6 +82,9 @@ type SystemStats struct {
 	MemorySysMB   float64 `json:"memory_sys_mb"`
 	GCPauseMs     float64 `json:"gc_pause_ms"`
 
+	// Per-subsystem share of the memory above (omitted when SYSTEM_STATS_MEMORY_BREAKDOWN=false)
+	MemoryBreakdown *MemoryBreakdown `json:"memory_breakdown,omitempty"`
+
 	// Database Info
 	TotalRecords     int64   `json:"total_records"`
 	RecordsToCleanup int64   `json:"records_to_cleanup"`
@@ -76,12 +97,47 @@ type SystemStats struct {
 	NextCleanupCountdown string `json:"next_cleanup_countdown"`
 	LastCleanupTime      string `json:"last_cleanup_time"`
 
+	// Effective retention per log source (overrides and inherited)
+	SourceRetention []database.SourceRetention `json:"source_retention,omitempty"`
+
+	// Lines rejected by the parsers (omitted when DEAD_LETTER_PATH is unset)
+	DeadLetter *ingestion.DeadLetterStats `json:"dead_letter,omitempty"`
+
+	// Database connection pool usage
+	DatabasePool *DatabasePoolStats `json:"database_pool,omitempty"`
+
 	// Additional Stats
 	OldestRecordAge   string  `json:"oldest_record_age"`
 	NewestRecordAge   string  `json:"newest_record_age"`
 	RequestsPerSecond float64 `json:"requests_per_second"`
 }
 
+// MemoryBreakdown attributes memory to the subsystems that can grow large.
+// Byte counts are estimates from entry sizes, not allocator measurements.
+type MemoryBreakdown struct {
+	GeoIPCacheEntries      int   `json:"geoip_cache_entries"`
+	GeoIPCacheBytes        int64 `json:"geoip_cache_bytes"`
+	RealtimeBufferRequests int   `json:"realtime_buffer_requests"`
+	RealtimeBufferBytes    int64 `json:"realtime_buffer_bytes"`
+	ActiveRealtimeStreams  int   `json:"active_realtime_streams"` // SSE, binary and WebSocket streams
+	ActiveExports          int64 `json:"active_exports"`
+}
+
+// DatabasePoolStats is the state of the database connection pool. Wait counters are
+// cumulative since startup; a growing wait count means queries queue for a connection.
+type DatabasePoolStats struct {
+	MaxOpenConnections int     `json:"max_open_connections"`
+	OpenConnections    int     `json:"open_connections"`
+	InUse              int     `json:"in_use"`
+	Idle               int     `json:"idle"`
+	WaitCount          int64   `json:"wait_count"`
+	WaitDurationMs     float64 `json:"wait_duration_ms"`
+	AvgWaitMs          float64 `json:"avg_wait_ms"`
+	Utilization        float64 `json:"utilization"` // InUse / MaxOpenConnections
+	HighUtilization    bool    `json:"high_utilization"`
+	Saturated          bool    `json:"saturated"`
+}
+
 // NewSystemHandler creates a new system handler
 func NewSystemHandler(
 	statsRepo repositories.StatsRepository,
@@ -102,6 +158,26 @@ func NewSystemHandler(
 	}
 }
 
+// SetDeadLetter attaches the dead-letter log whose counters are reported in the system stats
+func (h *SystemHandler) SetDeadLetter(writer *ingestion.DeadLetterWriter) {
+	h.deadLetter = writer
+}
+
+// SetDatabasePool reports the connection pool of db in the system stats, flagging
+// high utilization at the given threshold (DB_POOL_SATURATION_THRESHOLD)
+func (h *SystemHandler) SetDatabasePool(db *gorm.DB, threshold float64) {
+	h.db = db
+	h.poolThreshold = threshold
+}
+
+// SetMemoryBreakdown enables the per-subsystem memory breakdown. Either subsystem
+// may be nil (e.g. GeoIP disabled); its fields are then reported as zero.
+func (h *SystemHandler) SetMemoryBreakdown(geoIP *enrichment.GeoIPEnricher, collector *realtime.MetricsCollector) {
+	h.memoryBreakdown = true
+	h.geoIP = geoIP
+	h.collector = collector
+}
+
 // HandleSystemStatsPage renders the system stats page
 func (h *SystemHandler) HandleSystemStatsPage(c *gin.Context) {
 	c.HTML(http.StatusOK, "system.html", gin.H{
@@ -145,6 +221,44 @@ func (h *SystemHandler) GetRecordsTimeline(c *gin.Context) {
 	c.JSON(http.StatusOK, timeline)
 }
 
+// GetQueryPlans explains the canonical dashboard queries and compares present and expected indexes
+func (h *SystemHandler) GetQueryPlans(c *gin.Context) {
+	// Get hours parameter (default 24, 0 = all time)
+	hours := 24
+	if hoursParam := c.Query("hours"); hoursParam != "" {
+		if hr, err := strconv.Atoi(hoursParam); err == nil && hr >= 0 {
+			hours = hr
+		}
+	}
+
+	report, err := h.statsRepo.GetQueryPlans(hours)
+	if err != nil {
+		h.logger.WithCaller().Error("Failed to get query plans", h.logger.Args("error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get query plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetSchemaStatus reports the schema version, the recorded migrations and whether the
+// expected indexes are in place, with the outcome of the last index reconciliation
+func (h *SystemHandler) GetSchemaStatus(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schema status is unavailable"})
+		return
+	}
+
+	report, err := database.GetSchemaReport(h.db)
+	if err != nil {
+		h.logger.WithCaller().Error("Failed to get schema status", h.logger.Args("error", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get schema status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // collectSystemStats gathers all system statistics
 func (h *SystemHandler) collectSystemStats() (*SystemStats, error) {
 	stats := &SystemStats{
@@ -169,6 +283,9 @@ func (h *SystemHandler) collectSystemStats() (*SystemStats, error) {
 	stats.MemoryTotalMB = float64(m.TotalAlloc) / 1024 / 1024
 	stats.MemorySysMB = float64(m.Sys) / 1024 / 1024
 	stats.GCPauseMs = float64(m.PauseNs[(m.NumGC+255)%256]) / 1000000
+	if h.memoryBreakdown {
+		stats.MemoryBreakdown = h.collectMemoryBreakdown()
+	}
 
 	// Database record count
 	totalRecords, err := h.httpRepo.Count()
@@ -178,8 +295,19 @@ func (h *SystemHandler) collectSystemStats() (*SystemStats, error) {
 	stats.TotalRecords = totalRecords
 
 	// Calculate records to cleanup (if retention is enabled)
-	if h.retentionDays > 0 {
-		cutoffDate := time.Now().AddDate(0, 0, -h.retentionDays)
+	retentionEnabled := h.retentionDays > 0
+	if h.cleanupService != nil {
+		retentionEnabled = h.cleanupService.IsEnabled()
+		if retentionEnabled {
+			// Honors per-source retention
+			recordsToCleanup, err := h.cleanupService.CountExpiredRecords()
+			if err != nil {
+				h.logger.WithCaller().Warn("Failed to count records to cleanup", h.logger.Args("error", err))
+			}
+			stats.RecordsToCleanup = recordsToCleanup
+		}
+	} else if retentionEnabled {
+		cutoffDate := time.Now().UTC().AddDate(0, 0, -h.retentionDays)
 		recordsToCleanup, err := h.statsRepo.CountRecordsOlderThan(cutoffDate)
 		if err != nil {
 			h.logger.WithCaller().Warn("Failed to count records to cleanup", h.logger.Args("error", err))
@@ -193,8 +321,9 @@ func (h *SystemHandler) collectSystemStats() (*SystemStats, error) {
 	}
 
 	// Cleanup schedule info
-	if h.cleanupService != nil && h.retentionDays > 0 {
+	if h.cleanupService != nil && retentionEnabled {
 		cleanupStats := h.cleanupService.GetStats()
+		stats.SourceRetention = cleanupStats.SourceRetention
 		stats.NextCleanupTime = cleanupStats.NextScheduledRun.Format(time.RFC3339)
 
 		timeUntilCleanup := time.Until(cleanupStats.NextScheduledRun)
@@ -211,6 +340,19 @@ func (h *SystemHandler) collectSystemStats() (*SystemStats, error) {
 		stats.LastCleanupTime = "N/A"
 	}
 
+	if h.deadLetter != nil {
+		deadLetterStats := h.deadLetter.Stats()
+		stats.DeadLetter = &deadLetterStats
+	}
+
+	if h.db != nil {
+		if sqlDB, err := h.db.DB(); err != nil {
+			h.logger.WithCaller().Warn("Failed to read database pool stats", h.logger.Args("error", err))
+		} else {
+			stats.DatabasePool = newDatabasePoolStats(database.ReadPoolStats(sqlDB, h.poolThreshold))
+		}
+	}
+
 	// Oldest and newest record ages
 	oldestTime, newestTime, err := h.statsRepo.GetRecordTimeRange()
 	if err == nil {
@@ -235,6 +377,36 @@ func (h *SystemHandler) collectSystemStats() (*SystemStats, error) {
 	return stats, nil
 }
 
+// newDatabasePoolStats converts the pool monitor's stats to their JSON form
+func newDatabasePoolStats(pool *database.PoolStats) *DatabasePoolStats {
+	return &DatabasePoolStats{
+		MaxOpenConnections: pool.MaxOpenConns,
+		OpenConnections:    pool.OpenConns,
+		InUse:              pool.InUse,
+		Idle:               pool.Idle,
+		WaitCount:          pool.WaitCount,
+		WaitDurationMs:     float64(pool.WaitDuration.Microseconds()) / 1000,
+		AvgWaitMs:          float64(pool.AvgWaitTime.Microseconds()) / 1000,
+		Utilization:        pool.Utilization,
+		HighUtilization:    pool.IsHighUtilization,
+		Saturated:          pool.IsSaturated,
+	}
+}
+
+// collectMemoryBreakdown gathers the memory estimates of the attributed subsystems
+func (h *SystemHandler) collectMemoryBreakdown() *MemoryBreakdown {
+	breakdown := &MemoryBreakdown{ActiveExports: activeExports.Load()}
+	if h.geoIP != nil {
+		breakdown.GeoIPCacheEntries = h.geoIP.GetCacheSize()
+		breakdown.GeoIPCacheBytes = h.geoIP.GetCacheMemoryEstimate()
+	}
+	if h.collector != nil {
+		breakdown.RealtimeBufferRequests, breakdown.RealtimeBufferBytes = h.collector.BufferStats()
+		breakdown.ActiveRealtimeStreams = h.collector.GetActiveConnections()
+	}
+	return breakdown
+}
+
 // formatDuration formats a duration into a human-readable string
 func formatDuration(d time.Duration) string {
 	if d < 0 {
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"loglynx/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNewDatabasePoolStats(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(2)
+
+	conn, err := sqlDB.Conn(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	stats := newDatabasePoolStats(database.ReadPoolStats(sqlDB, 0.5))
+	assert.Equal(t, 2, stats.MaxOpenConnections)
+	assert.Equal(t, 1, stats.InUse)
+	assert.Equal(t, 0.5, stats.Utilization)
+	assert.True(t, stats.HighUtilization)
+	assert.False(t, stats.Saturated)
+
+	second, err := sqlDB.Conn(context.Background())
+	require.NoError(t, err)
+	defer second.Close()
+	assert.True(t, newDatabasePoolStats(database.ReadPoolStats(sqlDB, 0.5)).Saturated)
+}
+
+func TestNewDatabasePoolStatsUnlimitedPool(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	stats := newDatabasePoolStats(database.ReadPoolStats(sqlDB, 0.85))
+	assert.Zero(t, stats.MaxOpenConnections)
+	assert.False(t, stats.Saturated)
+}
+
+func TestGetSchemaStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &SystemHandler{logger: pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/system/schema", nil)
+	handler.GetSchemaStatus(c)
+	assert.Equal(t, http.StatusNotFound, w.Code, "unavailable without a database")
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "schema.db")), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, database.RunMigrations(db))
+	handler.SetDatabasePool(db, 0.85)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/system/schema", nil)
+	handler.GetSchemaStatus(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report database.SchemaReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, database.SchemaVersion, report.AppliedVersion)
+	assert.True(t, report.UpToDate)
+	require.NotNil(t, report.Indexes)
+}
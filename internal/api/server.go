@@ -23,8 +23,11 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"net/http/pprof"
+	"strings"
 	"sync"
 	"time"
 
@@ -94,10 +97,20 @@ type Config struct {
 	TimeZone            string // Dashboard timezone
 	WidgetEnabled       bool   // If false, widget page and API endpoints are disabled
 	HasExistingData     bool   // If true, database has existing data - skip initial load checks
+	BasePath            string // URL prefix the app is mounted under (e.g. "/loglynx"), empty for root
+	AdminToken          string // Bearer token required by /api/v1/admin routes, empty for no auth
+	MetricsRequireToken bool   // If true, /metrics also requires AdminToken
+	PprofEnabled        bool   // If true, the net/http/pprof handlers are served at /debug/pprof
+	PprofRequireToken   bool   // If true, /debug/pprof also requires AdminToken
+
+	// Headers the viewer's IP is read from (empty for gin's default) and the proxy
+	// IPs/CIDRs allowed to set them (empty for any peer)
+	ClientIPHeaders []string
+	TrustedProxies  []string
 }
 
 // NewServer creates a new HTTP server
-func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtimeHandler *handlers.RealtimeHandler, systemHandler *handlers.SystemHandler, ipTagHandler *handlers.IPTagHandler, logger *pterm.Logger) *Server {
+func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtimeHandler *handlers.RealtimeHandler, systemHandler *handlers.SystemHandler, ipTagHandler *handlers.IPTagHandler, metricsHandler *handlers.MetricsHandler, discoveryHandler *handlers.DiscoveryHandler, replayHandler *handlers.ReplayHandler, ingestHandler *handlers.IngestHandler, healthHandler *handlers.HealthHandler, logger *pterm.Logger) *Server {
 	// Set Gin mode
 	if cfg.Production {
 		gin.SetMode(gin.ReleaseMode)
@@ -107,6 +120,17 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 
 	router := gin.New()
 
+	// Read the viewer's IP (exclude_own_ip, logs) from the headers set by the proxy in front
+	if len(cfg.ClientIPHeaders) > 0 {
+		router.RemoteIPHeaders = cfg.ClientIPHeaders
+	}
+	if len(cfg.TrustedProxies) > 0 {
+		if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+			logger.Warn("Ignoring client IP headers: invalid DASHBOARD_TRUSTED_PROXIES", logger.Args("error", err))
+			_ = router.SetTrustedProxies(nil)
+		}
+	}
+
 	// Middleware
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
@@ -114,8 +138,13 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 
 	initialLoadState := NewInitialLoadState(cfg.SplashScreenEnabled)
 
+	// Every route lives under the configured base path so LogLynx can sit
+	// behind a reverse proxy at a subpath
+	basePath := NormalizeBasePath(cfg.BasePath)
+	base := router.Group(basePath)
+
 	// Health check
-	router.GET("/health", func(c *gin.Context) {
+	base.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"status":    "healthy",
 			"timestamp": time.Now(),
@@ -123,6 +152,29 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 		})
 	})
 
+	// Liveness/readiness probes for container orchestrators
+	base.GET("/healthz", healthHandler.Liveness)
+	base.GET("/readyz", healthHandler.Readiness)
+
+	// Prometheus metrics (nil when disabled)
+	if metricsHandler != nil {
+		metricsToken := ""
+		if cfg.MetricsRequireToken {
+			metricsToken = cfg.AdminToken
+		}
+		base.GET("/metrics", adminAuthMiddleware(metricsToken), metricsHandler.GetPrometheusMetrics)
+	}
+
+	// Go runtime profiles for go tool pprof (off unless PPROF_ENABLED)
+	if cfg.PprofEnabled {
+		pprofToken := ""
+		if cfg.PprofRequireToken {
+			pprofToken = cfg.AdminToken
+		}
+		registerPprof(base.Group("/debug/pprof", adminAuthMiddleware(pprofToken)))
+		logger.Warn("pprof profiling endpoints enabled", logger.Args("path", basePath+"/debug/pprof/", "token_required", pprofToken != ""))
+	}
+
 	// Helper function to render pages with common config
 	splashScreenEnabled := cfg.SplashScreenEnabled
 	timezone := cfg.TimeZone
@@ -137,6 +189,7 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 			"SplashScreenEnabled": splashScreenEnabled,
 			"TimeZone":            timezone,
 			"HasExistingData":     hasExistingData,
+			"BasePath":            basePath,
 		})
 	}
 
@@ -146,30 +199,30 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 		router.LoadHTMLGlob("web/templates/**/*.html")
 
 		// Static files
-		router.Static("/static", "./web/static")
+		base.Static("/static", "./web/static")
 
 		// Dashboard pages (HTML)
-		router.GET("/", func(c *gin.Context) {
+		base.GET("/", func(c *gin.Context) {
 			renderPage(c, "overview", "Executive Overview", "fas fa-home")
 		})
 
-		router.GET("/realtime", func(c *gin.Context) {
+		base.GET("/realtime", func(c *gin.Context) {
 			renderPage(c, "realtime", "Real-time Monitor", "fas fa-broadcast-tower")
 		})
 
-		router.GET("/traffic", func(c *gin.Context) {
+		base.GET("/traffic", func(c *gin.Context) {
 			renderPage(c, "traffic", "Traffic Analysis", "fas fa-globe")
 		})
 
-		router.GET("/performance", func(c *gin.Context) {
+		base.GET("/performance", func(c *gin.Context) {
 			renderPage(c, "performance", "Performance Monitoring", "fas fa-tachometer-alt")
 		})
 
-		router.GET("/compare", initialLoadPageBlockingMiddleware(initialLoadState, logger), func(c *gin.Context) {
+		base.GET("/compare", initialLoadPageBlockingMiddleware(initialLoadState, logger), func(c *gin.Context) {
 			renderPage(c, "compare", "Period Comparison", "fas fa-code-compare")
 		})
 
-		router.GET("/compare/:token", initialLoadPageBlockingMiddleware(initialLoadState, logger), func(c *gin.Context) {
+		base.GET("/compare/:token", initialLoadPageBlockingMiddleware(initialLoadState, logger), func(c *gin.Context) {
 			c.HTML(http.StatusOK, "compare.html", gin.H{
 				"Title":               "Period Comparison",
 				"PageName":            "compare",
@@ -179,48 +232,50 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 				"SplashScreenEnabled": splashScreenEnabled,
 				"TimeZone":            timezone,
 				"HasExistingData":     hasExistingData,
+				"BasePath":            basePath,
 				"SnapshotToken":       c.Param("token"),
 			})
 		})
 
-		router.GET("/security", func(c *gin.Context) {
+		base.GET("/security", func(c *gin.Context) {
 			renderPage(c, "security", "Security & Network", "fas fa-shield-alt")
 		})
 
-		router.GET("/users", func(c *gin.Context) {
+		base.GET("/users", func(c *gin.Context) {
 			renderPage(c, "users", "User Analytics", "fas fa-users")
 		})
 
-		router.GET("/content", func(c *gin.Context) {
+		base.GET("/content", func(c *gin.Context) {
 			renderPage(c, "content", "Content Analytics", "fas fa-file-alt")
 		})
 
-		router.GET("/backends", func(c *gin.Context) {
+		base.GET("/backends", func(c *gin.Context) {
 			renderPage(c, "backends", "Backend Health", "fas fa-server")
 		})
 
-		router.GET("/geographic", func(c *gin.Context) {
+		base.GET("/geographic", func(c *gin.Context) {
 			renderPage(c, "geographic", "Geographic Analytics", "fas fa-map-marked-alt")
 		})
 
-		router.GET("/system", func(c *gin.Context) {
+		base.GET("/system", func(c *gin.Context) {
 			renderPage(c, "system", "System Statistics", "fas fa-server")
 		})
 
 		// Widget page route (only if enabled)
 		if cfg.WidgetEnabled {
-			router.GET("/widget", func(c *gin.Context) {
+			base.GET("/widget", func(c *gin.Context) {
 				theme := c.DefaultQuery("theme", "dark")
 				mode := c.DefaultQuery("time", "realtime")
 				c.HTML(http.StatusOK, "widget.html", gin.H{
-					"Theme": theme,
-					"Mode":  mode,
+					"Theme":    theme,
+					"Mode":     mode,
+					"BasePath": basePath,
 				})
 			})
 		}
 
 		// IP Analytics page
-		router.GET("/ip/:ip", func(c *gin.Context) {
+		base.GET("/ip/:ip", func(c *gin.Context) {
 			ip := c.Param("ip")
 			c.HTML(http.StatusOK, "ip-detail.html", gin.H{
 				"Title":               "IP Analytics - " + ip,
@@ -232,27 +287,28 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 				"SplashScreenEnabled": splashScreenEnabled,
 				"TimeZone":            timezone,
 				"HasExistingData":     hasExistingData,
+				"BasePath":            basePath,
 			})
 		})
 
-		logger.Info("Dashboard UI routes enabled")
+		logger.Info("Dashboard UI routes enabled", logger.Args("base_path", basePath+"/"))
 	} else {
 		logger.Info("Dashboard UI disabled - API-only mode")
 		// Serve a simple message at root when dashboard is disabled
-		router.GET("/", func(c *gin.Context) {
+		base.GET("/", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{
 				"message": "LogLynx API Server - Dashboard UI is disabled",
-				"api":     "/api/v1",
-				"health":  "/health",
+				"api":     basePath + "/api/v1",
+				"health":  basePath + "/health",
 				"version": version.Version,
 			})
 		})
 	}
 
 	// API routes
-	api := router.Group("/api/v1")
+	api := base.Group("/api/v1")
 	// Apply initial load blocking middleware to API group
-	api.Use(initialLoadBlockingMiddleware(initialLoadState, logger))
+	api.Use(initialLoadBlockingMiddleware(initialLoadState, basePath, logger))
 	{
 		api.GET("/version", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"version": version.Version})
@@ -264,7 +320,13 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 		// Timeline data
 		api.GET("/stats/timeline", dashboardHandler.GetTimeline)
 		api.GET("/stats/timeline/status-codes", dashboardHandler.GetStatusCodeTimeline)
+		api.GET("/stats/timeline/unique-visitors", dashboardHandler.GetUniqueVisitorTimeline)
+		api.GET("/stats/visitor-retention", dashboardHandler.GetVisitorReturnStats)
+		api.GET("/stats/bandwidth-timeline", dashboardHandler.GetBandwidthTimeline)
+		api.GET("/stats/concurrency", dashboardHandler.GetConcurrencyTimeline)
 		api.GET("/stats/heatmap/traffic", dashboardHandler.GetTrafficHeatmap)
+		api.GET("/stats/heatmap/geo", dashboardHandler.GetGeoHeatmap)
+		api.GET("/stats/peaks", dashboardHandler.GetPeakTraffic)
 
 		// Top stats
 		api.GET("/stats/top/paths", dashboardHandler.GetTopPaths)
@@ -277,17 +339,39 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 		api.GET("/stats/top/backends", dashboardHandler.GetTopBackends)
 		api.GET("/stats/top/referrers", dashboardHandler.GetTopReferrers)
 		api.GET("/stats/top/referrer-domains", dashboardHandler.GetTopReferrerDomains)
+		api.GET("/stats/referrer-categories", dashboardHandler.GetReferrerCategories)
+		api.GET("/stats/origin-servers", dashboardHandler.GetTopOriginServers)
+		api.GET("/stats/slow-requests", dashboardHandler.GetSlowRequests)
+		api.GET("/stats/errors", dashboardHandler.GetErrorAnalysis)
+
+		// Path flows
+		api.GET("/stats/path-flows", dashboardHandler.GetPathFlows)
+
+		// Security
+		api.GET("/security/auth-abuse", dashboardHandler.GetAuthAbuse)
+		api.GET("/stats/suspicious-ips", dashboardHandler.GetSuspiciousIPs)
 
 		// Distribution stats
 		api.GET("/stats/distribution/status-codes", dashboardHandler.GetStatusCodeDistribution)
 		api.GET("/stats/distribution/methods", dashboardHandler.GetMethodDistribution)
+		api.GET("/stats/method-status", dashboardHandler.GetMethodStatusMatrix)
+		api.GET("/stats/response-sizes", dashboardHandler.GetResponseSizeHistogram)
 		api.GET("/stats/distribution/protocols", dashboardHandler.GetProtocolDistribution)
 		api.GET("/stats/distribution/tls-versions", dashboardHandler.GetTLSVersionDistribution)
+		api.GET("/stats/tls-security", dashboardHandler.GetTLSSecurityStats)
+		api.GET("/stats/content-types", dashboardHandler.GetContentTypeDistribution)
 		api.GET("/stats/distribution/device-types", dashboardHandler.GetDeviceTypeDistribution)
+		api.GET("/stats/client-profiles", dashboardHandler.GetClientProfiles)
+		api.GET("/stats/distribution/labels", dashboardHandler.GetTrafficByLabel)
+		api.GET("/stats/bots", dashboardHandler.GetBotTrafficStats)
 
 		// Performance stats
 		api.GET("/stats/performance/response-time", dashboardHandler.GetResponseTimeStats)
+		api.GET("/stats/proxy-overhead", dashboardHandler.GetProxyOverheadStats)
+		api.GET("/stats/backend-projection", dashboardHandler.GetBackendBandwidthProjection)
+		api.GET("/stats/retries", dashboardHandler.GetRetriedRequests)
 		api.POST("/stats/compare", dashboardHandler.GetComparison)
+		api.GET("/stats/compare", dashboardHandler.GetSummaryComparison)
 		api.GET("/stats/log-processing", dashboardHandler.GetLogProcessingStats)
 
 		// Comparison snapshots
@@ -298,12 +382,20 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 		api.DELETE("/compare/snapshots/:token", dashboardHandler.DeleteComparisonSnapshot)
 
 		// Recent requests
+		api.GET("/requests", dashboardHandler.ListRequests)
 		api.GET("/requests/recent", dashboardHandler.GetRecentRequests)
+		api.GET("/requests/export", dashboardHandler.ExportRequests)
+		api.GET("/requests/search", dashboardHandler.SearchRequests)
+		api.GET("/requests/by-request-id/:id", dashboardHandler.GetRequestsByRequestID)
+		api.GET("/requests/by-trace-id/:id", dashboardHandler.GetRequestsByTraceID)
+		api.GET("/requests/:id", dashboardHandler.GetRequest)
 
 		// Real-time metrics
 		api.GET("/realtime/metrics", realtimeHandler.GetCurrentMetrics)
 		api.GET("/realtime/stream", realtimeHandler.StreamMetrics)
+		api.GET("/realtime/ws", realtimeHandler.StreamMetricsWebSocket)
 		api.GET("/realtime/services", realtimeHandler.GetPerServiceMetrics)
+		api.GET("/alerts", realtimeHandler.GetActiveAlerts)
 
 		// Domains list (deprecated)
 		api.GET("/domains", dashboardHandler.GetDomains)
@@ -334,6 +426,45 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 		// System Statistics
 		api.GET("/system/stats", systemHandler.GetSystemStats)
 		api.GET("/system/timeline", systemHandler.GetRecordsTimeline)
+		api.GET("/system/processing", systemHandler.GetProcessingProgress)
+		api.GET("/system/schema", systemHandler.GetSchemaStatus)
+
+		// On-demand ANALYZE/index rebuild/VACUUM; requires ADMIN_TOKEN
+		api.POST("/system/optimize", requireAdminToken(cfg.AdminToken), systemHandler.StartOptimize)
+		api.GET("/system/optimize/:id", requireAdminToken(cfg.AdminToken), systemHandler.GetOptimizeJob)
+		api.GET("/system/query-plans", requireAdminToken(cfg.AdminToken), systemHandler.GetQueryPlans)
+
+		// Log sources added and removed at runtime; changes require ADMIN_TOKEN
+		api.GET("/sources", systemHandler.ListSources)
+		api.POST("/sources", requireAdminToken(cfg.AdminToken), systemHandler.CreateSource)
+		api.DELETE("/sources/:name", requireAdminToken(cfg.AdminToken), systemHandler.DeleteSource)
+
+		// Backup and restore of the log sources with their read positions; requires ADMIN_TOKEN
+		api.GET("/config/export", requireAdminToken(cfg.AdminToken), systemHandler.ExportConfig)
+		api.POST("/config/import", requireAdminToken(cfg.AdminToken), systemHandler.ImportConfig)
+
+		// Per-source parse success/failure timeline
+		api.GET("/sources/:name/parse-rate", systemHandler.GetSourceParseRate)
+
+		// Admin actions, protected by ADMIN_TOKEN when set
+		admin := api.Group("/admin", adminAuthMiddleware(cfg.AdminToken))
+
+		// On-demand log source discovery - only if enabled
+		if discoveryHandler != nil {
+			admin.POST("/discover", discoveryHandler.TriggerDiscovery)
+		}
+
+		// Replay of stored requests into the real-time stream - only if enabled
+		if replayHandler != nil {
+			admin.POST("/replay", replayHandler.StartReplay)
+			admin.GET("/replay", replayHandler.GetReplayStatus)
+			admin.DELETE("/replay", replayHandler.StopReplay)
+		}
+
+		// Bulk import of log lines pushed over HTTP - only if enabled
+		if ingestHandler != nil {
+			admin.POST("/ingest", ingestHandler.Ingest)
+		}
 
 		// Widget API (compact data for iframe embedding) - only if enabled
 		if cfg.WidgetEnabled {
@@ -360,6 +491,20 @@ func NewServer(cfg *Config, dashboardHandler *handlers.DashboardHandler, realtim
 	}
 }
 
+// registerPprof mounts the net/http/pprof handlers. Named profiles get explicit routes
+// because pprof.Index only resolves them under the root /debug/pprof/ path, not below a base path.
+func registerPprof(debug *gin.RouterGroup) {
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		debug.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
+
 // Run starts the HTTP server
 func (s *Server) Run() error {
 	s.logger.Info("Starting web server", s.logger.Args("address", s.server.Addr))
@@ -383,10 +528,20 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
+// NormalizeBasePath turns a configured prefix into the form used for route
+// registration: a leading slash, no trailing slash, and "" for the root
+func NormalizeBasePath(p string) string {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
 // initialLoadBlockingMiddleware blocks API calls during initial load (first startup)
 // This prevents excessive database load during index creation
-// Whitelisted endpoints: /version and /stats/log-processing (used by startup loader)
-func initialLoadBlockingMiddleware(ils *InitialLoadState, logger *pterm.Logger) gin.HandlerFunc {
+// Whitelisted endpoints: /version, /stats/log-processing and /system/processing (used by startup loader)
+func initialLoadBlockingMiddleware(ils *InitialLoadState, basePath string, logger *pterm.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip blocking if initial load is complete
 		if ils.IsInitialLoadComplete() {
@@ -395,8 +550,9 @@ func initialLoadBlockingMiddleware(ils *InitialLoadState, logger *pterm.Logger)
 		}
 
 		// Whitelist endpoints that are needed during startup
-		if c.Request.URL.Path == "/api/v1/version" ||
-			c.Request.URL.Path == "/api/v1/stats/log-processing" {
+		if c.Request.URL.Path == basePath+"/api/v1/version" ||
+			c.Request.URL.Path == basePath+"/api/v1/stats/log-processing" ||
+			c.Request.URL.Path == basePath+"/api/v1/system/processing" {
 			c.Next()
 			return
 		}
@@ -442,6 +598,34 @@ func initialLoadPageBlockingMiddleware(ils *InitialLoadState, logger *pterm.Logg
 	}
 }
 
+// adminAuthMiddleware requires "Authorization: Bearer <token>" when a token is configured
+func adminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing admin token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireAdminToken is adminAuthMiddleware for routes that must never be open:
+// without a configured token they answer 403 instead of skipping the check
+func requireAdminToken(token string) gin.HandlerFunc {
+	if token == "" {
+		return func(c *gin.Context) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This endpoint requires ADMIN_TOKEN to be configured"})
+		}
+	}
+	return adminAuthMiddleware(token)
+}
+
 // corsMiddleware adds CORS headers
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"loglynx/internal/api/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(basePath string) *Server {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	return NewServer(&Config{
+		Production: true,
+		BasePath:   basePath,
+	}, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+}
+
+func registeredPaths(s *Server) map[string]bool {
+	paths := make(map[string]bool)
+	for _, route := range s.router.Routes() {
+		paths[route.Method+" "+route.Path] = true
+	}
+	return paths
+}
+
+func TestNormalizeBasePath(t *testing.T) {
+	cases := map[string]string{
+		"":           "",
+		"/":          "",
+		"  ":         "",
+		"loglynx":    "/loglynx",
+		"/loglynx":   "/loglynx",
+		"/loglynx/":  "/loglynx",
+		"/apps/logs": "/apps/logs",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, NormalizeBasePath(in), "input %q", in)
+	}
+}
+
+func TestRoutesRegisterUnderBasePath(t *testing.T) {
+	paths := registeredPaths(newTestServer("/loglynx/"))
+
+	assert.True(t, paths["GET /loglynx/health"])
+	assert.True(t, paths["GET /loglynx/"])
+	assert.True(t, paths["GET /loglynx/api/v1/version"])
+	assert.True(t, paths["GET /loglynx/api/v1/stats/summary"])
+	assert.True(t, paths["GET /loglynx/api/v1/realtime/stream"])
+	assert.True(t, paths["GET /loglynx/api/v1/realtime/ws"])
+
+	for path := range paths {
+		assert.Regexp(t, `^[A-Z]+ /loglynx/`, path)
+	}
+}
+
+func TestRoutesRegisterAtRootWithoutBasePath(t *testing.T) {
+	paths := registeredPaths(newTestServer(""))
+
+	assert.True(t, paths["GET /health"])
+	assert.True(t, paths["GET /healthz"])
+	assert.True(t, paths["GET /readyz"])
+	assert.True(t, paths["GET /api/v1/version"])
+	assert.True(t, paths["GET /api/v1/realtime/stream"])
+}
+
+func TestBasePathRequests(t *testing.T) {
+	s := newTestServer("/loglynx")
+	s.MarkInitialLoadComplete()
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/loglynx/api/v1/version", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestInitialLoadWhitelistHonorsBasePath(t *testing.T) {
+	s := newTestServer("/loglynx")
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/loglynx/api/v1/version", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/open", adminAuthMiddleware(""), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/locked", adminAuthMiddleware("s3cret"), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	cases := []struct {
+		path   string
+		header string
+		want   int
+	}{
+		{"/open", "", http.StatusNoContent},
+		{"/locked", "", http.StatusUnauthorized},
+		{"/locked", "Bearer wrong", http.StatusUnauthorized},
+		{"/locked", "s3cret", http.StatusUnauthorized},
+		{"/locked", "Bearer s3cret", http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.want, w.Code, "%s with %q", tc.path, tc.header)
+	}
+}
+
+func TestRequireAdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/unconfigured", requireAdminToken(""), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/locked", requireAdminToken("s3cret"), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	cases := []struct {
+		path   string
+		header string
+		want   int
+	}{
+		{"/unconfigured", "", http.StatusForbidden},
+		{"/unconfigured", "Bearer ", http.StatusForbidden},
+		{"/locked", "", http.StatusUnauthorized},
+		{"/locked", "Bearer s3cret", http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.want, w.Code, "%s with %q", tc.path, tc.header)
+	}
+}
+
+func TestSensitiveRoutesRequireAdminToken(t *testing.T) {
+	s := newTestServer("")
+	s.MarkInitialLoadComplete()
+
+	for _, route := range [][2]string{
+		{http.MethodPost, "/api/v1/sources"},
+		{http.MethodDelete, "/api/v1/sources/legacy-app"},
+		{http.MethodGet, "/api/v1/config/export"},
+		{http.MethodPost, "/api/v1/config/import"},
+		{http.MethodPost, "/api/v1/system/optimize"},
+		{http.MethodGet, "/api/v1/system/optimize/1"},
+		{http.MethodGet, "/api/v1/system/query-plans"},
+	} {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(route[0], route[1], nil))
+		assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", route[0], route[1])
+	}
+}
+
+func TestMetricsRouteHonorsAdminToken(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	metrics := handlers.NewMetricsHandler(nil, nil, nil, nil, 0, logger)
+
+	for _, requireToken := range []bool{true, false} {
+		s := NewServer(&Config{
+			Production:          true,
+			AdminToken:          "s3cret",
+			MetricsRequireToken: requireToken,
+		}, nil, nil, nil, nil, metrics, nil, nil, nil, nil, logger)
+		s.MarkInitialLoadComplete()
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if requireToken {
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		} else {
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		w = httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "go_goroutines")
+	}
+}
+
+func TestPprofRoutesAreOptIn(t *testing.T) {
+	paths := registeredPaths(newTestServer(""))
+	assert.False(t, paths["GET /debug/pprof/"], "pprof is off by default")
+
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	s := NewServer(&Config{
+		Production:        true,
+		BasePath:          "/loglynx",
+		AdminToken:        "s3cret",
+		PprofEnabled:      true,
+		PprofRequireToken: true,
+	}, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/loglynx/debug/pprof/goroutine?debug=1", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Named profiles resolve below the base path too
+	req := httptest.NewRequest(http.MethodGet, "/loglynx/debug/pprof/goroutine?debug=1", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "goroutine profile:")
+
+	req = httptest.NewRequest(http.MethodGet, "/loglynx/debug/pprof/", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "mutex")
+}
+
+func TestClientIPHonorsTrustedProxies(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	clientIP := func(cfg *Config, peer string, headers map[string]string) string {
+		cfg.Production = true
+		s := NewServer(cfg, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+		s.MarkInitialLoadComplete()
+		s.router.GET("/whoami", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		req.RemoteAddr = peer + ":51234"
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	forwarded := map[string]string{"X-Forwarded-For": "203.0.113.7, 10.0.0.1"}
+
+	// Without a proxy list the header is honored from any peer
+	assert.Equal(t, "203.0.113.7", clientIP(&Config{}, "10.0.0.2", forwarded))
+
+	trusted := []string{"10.0.0.0/8"}
+	assert.Equal(t, "203.0.113.7", clientIP(&Config{TrustedProxies: trusted}, "10.0.0.2", forwarded))
+	assert.Equal(t, "198.51.100.9", clientIP(&Config{TrustedProxies: trusted}, "198.51.100.9", forwarded), "untrusted peers cannot set the client IP")
+
+	cloudflare := &Config{ClientIPHeaders: []string{"CF-Connecting-IP"}, TrustedProxies: trusted}
+	assert.Equal(t, "203.0.113.8", clientIP(cloudflare, "10.0.0.2", map[string]string{"CF-Connecting-IP": "203.0.113.8", "X-Forwarded-For": "203.0.113.7"}))
+
+	// An invalid list trusts no proxy
+	assert.Equal(t, "10.0.0.2", clientIP(&Config{TrustedProxies: []string{"not-an-ip"}}, "10.0.0.2", forwarded))
+}
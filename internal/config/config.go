@@ -22,8 +22,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -37,6 +40,21 @@ type Config struct {
 	// GeoIP Configuration
 	GeoIP GeoIPConfig
 
+	// Reverse DNS Configuration
+	ReverseDNS ReverseDNSConfig
+
+	// Alerting Configuration
+	Alerts AlertsConfig
+
+	// Parse Statistics Configuration
+	ParseStats ParseStatsConfig
+
+	// Dead-Letter Log Configuration
+	DeadLetter DeadLetterConfig
+
+	// Request Classification Configuration
+	Classification ClassificationConfig
+
 	// Log configuration
 	LogLevel string
 
@@ -49,20 +67,31 @@ type Config struct {
 	// Performance Configuration
 	Performance PerformanceConfig
 
+	// Statistics Configuration
+	Stats StatsConfig
+
 	// Anonymous usage telemetry
 	Telemetry TelemetryConfig
 }
 
 // DatabaseConfig contains database-related settings
 type DatabaseConfig struct {
-	Path            string
-	MaxOpenConns    int
-	MaxIdleConns    int
-	ConnMaxLife     time.Duration
-	RetentionDays   int           // Number of days to retain data (0 = unlimited)
-	CleanupInterval time.Duration // How often to check for cleanup (default: 1 hour)
-	CleanupTime     string        // Time of day to run cleanup (24-hour format, e.g., "02:00")
-	VacuumEnabled   bool          // Run VACUUM after cleanup to reclaim space
+	Driver            string // "sqlite" (default) or "postgres"
+	DSN               string // PostgreSQL connection string, used when Driver is "postgres"
+	Path              string
+	MaxOpenConns      int
+	MaxIdleConns      int
+	ConnMaxLife       time.Duration
+	RetentionDays     int            // Number of days to retain data (0 = unlimited)
+	SourceRetention   map[string]int // Per-source retention in days, overriding RetentionDays
+	CleanupInterval   time.Duration  // How often to check for cleanup (default: 1 hour)
+	CleanupTime       string         // Time of day to run cleanup (24-hour format, e.g., "02:00")
+	CleanupSchedule   string         // "daily" runs at CleanupTime, "interval" runs every CleanupInterval
+	CleanupTimezone   *time.Location // Timezone CleanupTime is read in (default: server local time)
+	VacuumEnabled     bool           // Run VACUUM after cleanup to reclaim space
+	ReputationCleanup bool           // Also purge ip_reputation rows of IPs with no stored requests
+	RetentionMode     string         // "delete" drops expired rows, "rollup" aggregates them into hourly_rollups first
+	FullTextSearch    bool           // Maintain an FTS5 index for request search (needs the sqlite_fts5 build tag)
 
 	// Connection Pool Monitoring
 	PoolMonitoringEnabled   bool          // Enable connection pool monitoring
@@ -73,10 +102,73 @@ type DatabaseConfig struct {
 
 // GeoIPConfig contains GeoIP database paths
 type GeoIPConfig struct {
-	CityDBPath    string
-	CountryDBPath string
-	ASNDBPath     string
+	CityDBPath    string // Comma-separated list allowed
+	CountryDBPath string // Comma-separated list allowed
+	ASNDBPath     string // Comma-separated list allowed
 	Enabled       bool
+
+	// Priority of location providers; later ones fill fields earlier ones lack
+	ProviderOrder string
+
+	// How long found IPs are served from the memory cache before being looked up again
+	CacheTTL time.Duration
+
+	// How long IPs missing from every database are cached before being looked up again
+	NegativeCacheTTL time.Duration
+
+	// MaxMind license key; when set, missing or stale GeoLite2 databases are downloaded
+	// to the first City/Country/ASN path at startup and refreshed while running
+	LicenseKey string
+	MaxAgeDays int // Re-download databases older than this many days
+
+	// Requests from these autonomous systems are dropped at ingestion (needs the ASN database)
+	ExcludeASNs []int
+}
+
+// ClassificationConfig contains the user-defined request labeling rules
+type ClassificationConfig struct {
+	Rules string // Inline YAML/JSON or path to a rule file (empty = disabled); reloaded on SIGHUP
+}
+
+// ReverseDNSConfig contains settings for PTR lookups of client IPs
+type ReverseDNSConfig struct {
+	Enabled          bool
+	Timeout          time.Duration // Per-lookup timeout, including the wait for a free worker
+	Workers          int           // Maximum concurrent lookups
+	CacheTTL         time.Duration // How long resolved hostnames are cached
+	NegativeCacheTTL time.Duration // How long failed lookups are cached before being retried
+	CacheSize        int           // Maximum cached IPs
+}
+
+// AlertsConfig contains thresholds for alerts raised from realtime metrics
+// A threshold or duration of 0 disables the corresponding rule
+type AlertsConfig struct {
+	Enabled              bool
+	WebhookURL           string
+	WebhookTimeout       time.Duration
+	Cooldown             time.Duration // Minimum time between two firings of the same rule
+	ServerErrorRate      float64       // 5xx/sec
+	ServerErrorDuration  time.Duration
+	ErrorRate            float64 // 4xx+5xx/sec
+	ErrorRateDuration    time.Duration
+	ResponseTimeMs       float64
+	ResponseTimeDuration time.Duration
+	TrafficStopDuration  time.Duration // Fire when traffic stays at zero this long after being active
+}
+
+// ParseStatsConfig contains settings for the persisted per-source parse counters
+type ParseStatsConfig struct {
+	Enabled       bool
+	Bucket        time.Duration // Granularity of the stored counters
+	FlushInterval time.Duration // How often counters are written to the database
+	RetentionDays int           // 0 keeps all buckets
+}
+
+// DeadLetterConfig contains settings for the log of lines the parsers rejected
+type DeadLetterConfig struct {
+	Path      string // Empty disables the dead-letter log
+	MaxSizeMB int    // Size at which the file is rotated to <path>.1 (0 = no cap)
+	RateLimit int    // Max lines written per second (0 = unlimited)
 }
 
 // LogSourcesConfig contains log source paths
@@ -84,9 +176,28 @@ type LogSourcesConfig struct {
 	TraefikLogPath      string
 	TraefikLogFormat    string // auto, json, clf
 	CaddyLogPath        string
+	GenericLogPath      string // JSON log parsed with the generic field-mapping parser
+	GenericFieldMap     string // Inline YAML/JSON field map or path to a mapping file
+	HAProxyLogPath      string // HAProxy HTTP log (option httplog), no auto-discovery
+	ApacheLogPath       string // Apache combined log; /var/log/apache2/access.log is auto-discovered
+	SourcesFile         string // Inline YAML/JSON source declarations or path to a sources file
 	AutoDiscover        bool
 	InitialImportDays   int  // Only import last N days on first run (0 = import all)
 	InitialImportEnable bool // Enable initial import limiting
+
+	// Proxy IPs and CIDR blocks whose X-Forwarded-For header is trusted when
+	// resolving the client IP at ingestion (empty = keep the IP the proxy logged)
+	TrustedProxies string
+
+	// Optional request hash fields on top of the defaults, e.g. "request_id,-start_utc"
+	// (empty = duration, start_utc and requests_total); sources can refine it
+	DedupHashFields string
+
+	// Sources whose path is an http(s) URL are polled with range requests.
+	// Headers are "Name: value" pairs separated by semicolons, e.g. an Authorization header.
+	RemoteHeaders      string
+	RemotePollInterval time.Duration
+	RemoteTimeout      time.Duration
 }
 
 // ServerConfig contains web server settings
@@ -98,14 +209,66 @@ type ServerConfig struct {
 	SplashScreenEnabled bool   // If false, splash screen is disabled on startup
 	TimeZone            string // Dashboard timezone (e.g., "UTC")
 	WidgetEnabled       bool   // If false, widget page and API endpoints are disabled
+	RealtimeBinary      bool   // If true, realtime endpoints may answer with compact binary frames
+	RealtimeMaxConns    int    // Max concurrent real-time streams (SSE, binary and WebSocket); 0 = unlimited
+	MetricsEnabled      bool   // If true, Prometheus metrics are exposed at /metrics
+	MetricsRequireToken bool   // If true, /metrics requires AdminToken like the admin routes
+	PprofEnabled        bool   // If true, the net/http/pprof handlers are served at /debug/pprof
+	PprofRequireToken   bool   // If true, /debug/pprof requires AdminToken like the admin routes
+	PprofContention     bool   // If true, block and mutex contention are sampled for /debug/pprof
+	DiscoverEndpoint    bool   // If true, POST /api/v1/admin/discover re-runs log source discovery (needs AdminToken)
+	ReplayEndpoint      bool   // If true, /api/v1/admin/replay replays stored requests into the real-time stream (needs AdminToken)
+	IngestEndpoint      bool   // If true, POST /api/v1/admin/ingest imports log lines from the request body (needs AdminToken)
+	IngestMaxBodyBytes  int64  // Largest body accepted by the ingest endpoint
+	AdminToken          string // Bearer token required by /api/v1/admin routes (empty = no auth)
+	BasePath            string // URL prefix all routes are served under (e.g. "/loglynx")
+
+	// Headers the viewer's IP is read from (exclude_own_ip, access logs) and the proxy
+	// IPs/CIDRs allowed to set them (empty = any peer)
+	ClientIPHeaders []string
+	TrustedProxies  []string
 }
 
 // PerformanceConfig contains performance tuning settings
 type PerformanceConfig struct {
-	RealtimeMetricsInterval time.Duration
-	GeoIPCacheSize          int
-	BatchSize               int
-	WorkerPoolSize          int
+	RealtimeMetricsInterval   time.Duration
+	GeoIPCacheSize            int
+	BatchSize                 int           // Lines per ingestion batch (INGEST_BATCH_SIZE, legacy BATCH_SIZE)
+	WorkerPoolSize            int           // Parse/enrich workers per source (INGEST_WORKERS, legacy WORKER_POOL_SIZE)
+	BatchTimeout              time.Duration // Flush a partial batch after this long (INGEST_BATCH_TIMEOUT)
+	MaxLineLength             int           // Longest log line read in bytes, longer ones are skipped (INGEST_MAX_LINE_BYTES)
+	PartialLineFlushDelay     time.Duration // Read a last line without newline once unchanged this long, 0 = never (INGEST_PARTIAL_LINE_FLUSH_DELAY)
+	RealtimePerSourceEnabled  bool          // Keep per-source real-time buffers and cached metrics
+	RealtimeMaxSourceBuffered int           // Max requests held across all per-source buffers
+	RealtimeMaxBuffered       int           // Hard cap on the shared real-time buffer (oldest evicted first)
+	RealtimeBufferDuration    time.Duration // How long requests are kept in the real-time buffer
+	RealtimeRateWindow        time.Duration // Window real-time rates are averaged over (at most the buffer duration)
+	ParseErrorLogInterval     time.Duration // Coalesce parse-failure warnings into one summary per interval (0 = log each)
+	MemoryBreakdown           bool          // Report per-subsystem memory estimates in the system stats
+}
+
+// StatsConfig contains settings that affect how statistics are computed
+type StatsConfig struct {
+	BenignStatusCodes []int // 4xx codes that do not count against the availability rate (e.g., 401, 403, 404, 429)
+	MaxTopLimit       int   // Upper bound on rows returned by top-N lists
+
+	// IPs and CIDR blocks (IPv4/IPv6) flagged as internal at ingestion and hidden
+	// from stats unless include_internal=true; changes only affect new requests
+	InternalNetworks string
+
+	// Credential-stuffing report: comma-separated path prefixes treated as login
+	// endpoints, and the thresholds an IP must reach to be reported
+	AuthAbusePaths           string
+	AuthAbuseMinRequests     int
+	AuthAbuseMinFailureRatio float64 // Share of 401/403 responses (0-1)
+
+	// Comma-separated referrer-spam domains (subdomains included) or /regex/ patterns
+	// hidden from the referrer reports
+	ReferrerBlocklist string
+
+	// Dashboard timezone (TIMEZONE) whose days and hours the timeline buckets follow.
+	// Requests are always stored in UTC.
+	DisplayTimezone *time.Location
 }
 
 // TelemetryConfig contains anonymous usage telemetry settings.
@@ -122,14 +285,21 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		Database: DatabaseConfig{
-			Path:            getEnv("DB_PATH", "loglynx.db"),
-			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 10),
-			ConnMaxLife:     getEnvAsDuration("DB_CONN_MAX_LIFE", time.Hour),
-			RetentionDays:   getEnvAsInt("DB_RETENTION_DAYS", 60),
-			CleanupInterval: getEnvAsDuration("DB_CLEANUP_INTERVAL", 1*time.Hour),
-			CleanupTime:     getEnv("DB_CLEANUP_TIME", "02:00"),
-			VacuumEnabled:   getEnvAsBool("DB_VACUUM_ENABLED", true),
+			Driver:            getEnv("DB_DRIVER", "sqlite"),
+			DSN:               getEnv("DB_DSN", ""),
+			Path:              getEnv("DB_PATH", "loglynx.db"),
+			MaxOpenConns:      getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:      getEnvAsInt("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLife:       getEnvAsDuration("DB_CONN_MAX_LIFE", time.Hour),
+			RetentionDays:     getEnvAsInt("DB_RETENTION_DAYS", 60),
+			SourceRetention:   getEnvAsIntMap("DB_SOURCE_RETENTION_DAYS"),
+			CleanupInterval:   getEnvAsDuration("DB_CLEANUP_INTERVAL", 1*time.Hour),
+			CleanupTime:       getEnv("DB_CLEANUP_TIME", "02:00"),
+			CleanupSchedule:   getEnv("DB_CLEANUP_SCHEDULE", "daily"),
+			VacuumEnabled:     getEnvAsBool("DB_VACUUM_ENABLED", true),
+			ReputationCleanup: getEnvAsBool("DB_REPUTATION_CLEANUP_ENABLED", false),
+			RetentionMode:     getEnv("DB_RETENTION_MODE", "delete"),
+			FullTextSearch:    getEnvAsBool("DB_FULL_TEXT_SEARCH", false),
 
 			// Connection Pool Monitoring
 			PoolMonitoringEnabled:   getEnvAsBool("DB_POOL_MONITORING", true),
@@ -142,14 +312,68 @@ func Load() (*Config, error) {
 			CountryDBPath: getEnv("GEOIP_COUNTRY_DB", "geoip/GeoLite2-Country.mmdb"),
 			ASNDBPath:     getEnv("GEOIP_ASN_DB", "geoip/GeoLite2-ASN.mmdb"),
 			Enabled:       getEnvAsBool("GEOIP_ENABLED", true),
+
+			ProviderOrder:    getEnv("GEOIP_PROVIDER_ORDER", "city,country"),
+			CacheTTL:         getEnvAsDuration("GEOIP_CACHE_TTL", 24*time.Hour),
+			NegativeCacheTTL: getEnvAsDuration("GEOIP_NEGATIVE_CACHE_TTL", time.Hour),
+
+			LicenseKey: getEnv("MAXMIND_LICENSE_KEY", ""),
+			MaxAgeDays: getEnvAsInt("GEOIP_MAX_AGE_DAYS", 7),
+
+			ExcludeASNs: getEnvAsIntSlice("EXCLUDE_ASNS", nil),
+		},
+		Classification: ClassificationConfig{
+			Rules: getEnv("CLASSIFICATION_RULES", ""),
+		},
+		ReverseDNS: ReverseDNSConfig{
+			Enabled:          getEnvAsBool("REVERSE_DNS_ENABLED", false),
+			Timeout:          getEnvAsDuration("REVERSE_DNS_TIMEOUT", 500*time.Millisecond),
+			Workers:          getEnvAsInt("REVERSE_DNS_WORKERS", 8),
+			CacheTTL:         getEnvAsDuration("REVERSE_DNS_CACHE_TTL", 24*time.Hour),
+			NegativeCacheTTL: getEnvAsDuration("REVERSE_DNS_NEGATIVE_CACHE_TTL", time.Hour),
+			CacheSize:        getEnvAsInt("REVERSE_DNS_CACHE_SIZE", 50000),
+		},
+		Alerts: AlertsConfig{
+			Enabled:              getEnvAsBool("ALERTS_ENABLED", false),
+			WebhookURL:           getEnv("ALERT_WEBHOOK_URL", ""),
+			WebhookTimeout:       getEnvAsDuration("ALERT_WEBHOOK_TIMEOUT", 10*time.Second),
+			Cooldown:             getEnvAsDuration("ALERT_COOLDOWN", 10*time.Minute),
+			ServerErrorRate:      getEnvAsFloat("ALERT_5XX_RATE", 1),
+			ServerErrorDuration:  getEnvAsDuration("ALERT_5XX_DURATION", 30*time.Second),
+			ErrorRate:            getEnvAsFloat("ALERT_ERROR_RATE", 0),
+			ErrorRateDuration:    getEnvAsDuration("ALERT_ERROR_RATE_DURATION", 30*time.Second),
+			ResponseTimeMs:       getEnvAsFloat("ALERT_RESPONSE_TIME_MS", 0),
+			ResponseTimeDuration: getEnvAsDuration("ALERT_RESPONSE_TIME_DURATION", time.Minute),
+			TrafficStopDuration:  getEnvAsDuration("ALERT_TRAFFIC_STOP_DURATION", 5*time.Minute),
+		},
+		ParseStats: ParseStatsConfig{
+			Enabled:       getEnvAsBool("PARSE_STATS_ENABLED", true),
+			Bucket:        getEnvAsDuration("PARSE_STATS_BUCKET", 5*time.Minute),
+			FlushInterval: getEnvAsDuration("PARSE_STATS_FLUSH_INTERVAL", time.Minute),
+			RetentionDays: getEnvAsInt("PARSE_STATS_RETENTION_DAYS", 30),
+		},
+		DeadLetter: DeadLetterConfig{
+			Path:      getEnv("DEAD_LETTER_PATH", ""),
+			MaxSizeMB: getEnvAsInt("DEAD_LETTER_MAX_SIZE_MB", 10),
+			RateLimit: getEnvAsInt("DEAD_LETTER_RATE_LIMIT", 100),
 		},
 		LogSources: LogSourcesConfig{
 			TraefikLogPath:      getEnv("TRAEFIK_LOG_PATH", "traefik/logs/access.log"),
 			TraefikLogFormat:    getEnv("TRAEFIK_LOG_FORMAT", "auto"),
 			CaddyLogPath:        getEnv("CADDY_LOG_PATH", "caddy/logs/access.log"),
+			GenericLogPath:      getEnv("GENERIC_LOG_PATH", ""),
+			GenericFieldMap:     getEnv("GENERIC_LOG_FIELD_MAP", ""),
+			HAProxyLogPath:      getEnv("HAPROXY_LOG_PATH", ""),
+			ApacheLogPath:       getEnv("APACHE_LOG_PATH", ""),
+			SourcesFile:         getEnv("LOG_SOURCES_FILE", ""),
 			AutoDiscover:        getEnvAsBool("LOG_AUTO_DISCOVER", true),
 			InitialImportDays:   getEnvAsInt("INITIAL_IMPORT_DAYS", 60),
 			InitialImportEnable: getEnvAsBool("INITIAL_IMPORT_ENABLE", true),
+			TrustedProxies:      getEnv("TRUSTED_PROXIES", ""),
+			DedupHashFields:     getEnv("DEDUP_HASH_FIELDS", ""),
+			RemoteHeaders:       getEnv("REMOTE_SOURCE_HEADERS", ""),
+			RemotePollInterval:  getEnvAsDuration("REMOTE_SOURCE_POLL_INTERVAL", 10*time.Second),
+			RemoteTimeout:       getEnvAsDuration("REMOTE_SOURCE_TIMEOUT", 30*time.Second),
 		},
 		Server: ServerConfig{
 			Host:                getEnv("SERVER_HOST", "0.0.0.0"),
@@ -159,12 +383,48 @@ func Load() (*Config, error) {
 			SplashScreenEnabled: getEnvAsBool("SPLASH_SCREEN_ENABLED", true),
 			TimeZone:            getEnv("TIMEZONE", "UTC"),
 			WidgetEnabled:       getEnvAsBool("WIDGET_ENABLED", false),
+			RealtimeBinary:      getEnvAsBool("REALTIME_BINARY_ENABLED", false),
+			RealtimeMaxConns:    getEnvAsInt("REALTIME_MAX_CONNECTIONS", 100),
+			MetricsEnabled:      getEnvAsBool("PROMETHEUS_METRICS_ENABLED", false),
+			MetricsRequireToken: getEnvAsBool("PROMETHEUS_METRICS_REQUIRE_TOKEN", true),
+			PprofEnabled:        getEnvAsBool("PPROF_ENABLED", false),
+			PprofRequireToken:   getEnvAsBool("PPROF_REQUIRE_TOKEN", true),
+			PprofContention:     getEnvAsBool("PPROF_CONTENTION_PROFILES", false),
+			DiscoverEndpoint:    getEnvAsBool("DISCOVER_ENDPOINT_ENABLED", true),
+			ReplayEndpoint:      getEnvAsBool("REPLAY_ENDPOINT_ENABLED", false),
+			IngestEndpoint:      getEnvAsBool("INGEST_ENDPOINT_ENABLED", false),
+			IngestMaxBodyBytes:  int64(getEnvAsInt("INGEST_ENDPOINT_MAX_BYTES", 100*1024*1024)),
+			AdminToken:          getEnv("ADMIN_TOKEN", ""),
+			BasePath:            getEnv("BASE_PATH", ""),
+			ClientIPHeaders:     getEnvAsStringSlice("DASHBOARD_CLIENT_IP_HEADERS", []string{"X-Forwarded-For", "X-Real-IP"}),
+			TrustedProxies:      getEnvAsStringSlice("DASHBOARD_TRUSTED_PROXIES", nil),
 		},
 		Performance: PerformanceConfig{
-			RealtimeMetricsInterval: getEnvAsDuration("METRICS_INTERVAL", 1*time.Second),
-			GeoIPCacheSize:          getEnvAsInt("GEOIP_CACHE_SIZE", 10000),
-			BatchSize:               getEnvAsInt("BATCH_SIZE", 1000),
-			WorkerPoolSize:          getEnvAsInt("WORKER_POOL_SIZE", 4),
+			RealtimeMetricsInterval:   getEnvAsDuration("METRICS_INTERVAL", 1*time.Second),
+			GeoIPCacheSize:            getEnvAsInt("GEOIP_CACHE_SIZE", 10000),
+			BatchSize:                 getEnvAsInt("INGEST_BATCH_SIZE", getEnvAsInt("BATCH_SIZE", 0)),
+			WorkerPoolSize:            getEnvAsInt("INGEST_WORKERS", getEnvAsInt("WORKER_POOL_SIZE", 0)),
+			BatchTimeout:              getEnvAsDuration("INGEST_BATCH_TIMEOUT", 500*time.Millisecond),
+			MaxLineLength:             getEnvAsInt("INGEST_MAX_LINE_BYTES", 1024*1024),
+			PartialLineFlushDelay:     getEnvAsDuration("INGEST_PARTIAL_LINE_FLUSH_DELAY", 0),
+			RealtimePerSourceEnabled:  getEnvAsBool("REALTIME_PER_SOURCE_ENABLED", false),
+			RealtimeMaxSourceBuffered: getEnvAsInt("REALTIME_MAX_SOURCE_BUFFERED", 50000),
+			RealtimeMaxBuffered:       getEnvAsInt("REALTIME_MAX_BUFFERED", 100000),
+			RealtimeBufferDuration:    getEnvAsDuration("REALTIME_BUFFER_DURATION", 60*time.Second),
+			RealtimeRateWindow:        getEnvAsDuration("REALTIME_RATE_WINDOW", 5*time.Second),
+			ParseErrorLogInterval:     getEnvAsDuration("PARSE_ERROR_LOG_INTERVAL", 10*time.Second),
+			MemoryBreakdown:           getEnvAsBool("SYSTEM_STATS_MEMORY_BREAKDOWN", true),
+		},
+		Stats: StatsConfig{
+			BenignStatusCodes: getEnvAsIntSlice("AVAILABILITY_BENIGN_STATUS_CODES", nil),
+			MaxTopLimit:       getEnvAsInt("STATS_MAX_TOP_LIMIT", 1000),
+			InternalNetworks:  getEnv("INTERNAL_NETWORKS", ""),
+
+			AuthAbusePaths:           getEnv("AUTH_ABUSE_PATHS", "/login,/wp-login.php,/api/auth"),
+			AuthAbuseMinRequests:     getEnvAsInt("AUTH_ABUSE_MIN_REQUESTS", 10),
+			AuthAbuseMinFailureRatio: getEnvAsFloat("AUTH_ABUSE_MIN_FAILURE_RATIO", 0.5),
+
+			ReferrerBlocklist: getEnv("REFERRER_BLOCKLIST", ""),
 		},
 		Telemetry: TelemetryConfig{
 			Enabled:  getEnvAsBool("LOGLYNX_USAGE_TELEMETRY", true),
@@ -174,9 +434,135 @@ func Load() (*Config, error) {
 		LogLevel: getEnv("LOG_LEVEL", "info"),
 	}
 
+	if err := cfg.Performance.resolveIngestion(runtime.NumCPU()); err != nil {
+		return nil, err
+	}
+	if err := cfg.Performance.validateRealtimeWindows(); err != nil {
+		return nil, err
+	}
+
+	displayTimezone, err := loadDisplayTimezone(cfg.Server.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Stats.DisplayTimezone = displayTimezone
+
+	cleanupTimezone, err := loadCleanupTimezone(os.Getenv("DB_CLEANUP_TIMEZONE"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Database.CleanupTimezone = cleanupTimezone
+
 	return cfg, nil
 }
 
+// redactedValue replaces secrets in Redacted
+const redactedValue = "[redacted]"
+
+// Redacted returns a copy of the configuration with its secrets (database DSN, MaxMind
+// license key, alert webhook URL, admin token and remote source headers) masked, safe
+// to show or export
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	for _, secret := range []*string{
+		&redacted.Database.DSN,
+		&redacted.GeoIP.LicenseKey,
+		&redacted.Alerts.WebhookURL,
+		&redacted.Server.AdminToken,
+		&redacted.LogSources.RemoteHeaders,
+	} {
+		if *secret != "" {
+			*secret = redactedValue
+		}
+	}
+	return &redacted
+}
+
+// Ingestion tuning bounds
+const (
+	maxIngestWorkers      = 64
+	maxIngestBatchSize    = 50000
+	minIngestBatchTimeout = 10 * time.Millisecond
+	maxIngestBatchTimeout = time.Minute
+	minIngestLineLength   = 1024
+	maxIngestLineLength   = 64 * 1024 * 1024
+)
+
+// resolveIngestion fills auto (0) ingestion settings from the CPU count and rejects
+// out-of-range values. One worker per CPU keeps small containers from oversubscribing
+// while letting large hosts chew through a backlog; batches grow with the workers so
+// each insert round still feeds all of them.
+func (p *PerformanceConfig) resolveIngestion(numCPU int) error {
+	if p.WorkerPoolSize == 0 {
+		p.WorkerPoolSize = min(max(numCPU, 2), 16)
+	}
+	if p.BatchSize == 0 {
+		p.BatchSize = min(max(numCPU*250, 1000), 5000)
+	}
+
+	if p.WorkerPoolSize < 1 || p.WorkerPoolSize > maxIngestWorkers {
+		return fmt.Errorf("INGEST_WORKERS must be between 1 and %d (0 = auto), got %d", maxIngestWorkers, p.WorkerPoolSize)
+	}
+	if p.BatchSize < 1 || p.BatchSize > maxIngestBatchSize {
+		return fmt.Errorf("INGEST_BATCH_SIZE must be between 1 and %d (0 = auto), got %d", maxIngestBatchSize, p.BatchSize)
+	}
+	if p.BatchTimeout < minIngestBatchTimeout || p.BatchTimeout > maxIngestBatchTimeout {
+		return fmt.Errorf("INGEST_BATCH_TIMEOUT must be between %s and %s, got %s", minIngestBatchTimeout, maxIngestBatchTimeout, p.BatchTimeout)
+	}
+	if p.MaxLineLength < minIngestLineLength || p.MaxLineLength > maxIngestLineLength {
+		return fmt.Errorf("INGEST_MAX_LINE_BYTES must be between %d and %d, got %d", minIngestLineLength, maxIngestLineLength, p.MaxLineLength)
+	}
+	if p.PartialLineFlushDelay < 0 {
+		return fmt.Errorf("INGEST_PARTIAL_LINE_FLUSH_DELAY must not be negative, got %s", p.PartialLineFlushDelay)
+	}
+	return nil
+}
+
+// Real-time window bounds
+const (
+	minRealtimeBufferDuration = 10 * time.Second
+	maxRealtimeBufferDuration = time.Hour
+	minRealtimeRateWindow     = time.Second
+)
+
+// validateRealtimeWindows rejects real-time buffer and rate windows out of range.
+// The buffer holds every request received within it, so its memory grows with the
+// traffic times the duration (REALTIME_MAX_BUFFERED still caps it).
+func (p *PerformanceConfig) validateRealtimeWindows() error {
+	if p.RealtimeBufferDuration < minRealtimeBufferDuration || p.RealtimeBufferDuration > maxRealtimeBufferDuration {
+		return fmt.Errorf("REALTIME_BUFFER_DURATION must be between %s and %s, got %s", minRealtimeBufferDuration, maxRealtimeBufferDuration, p.RealtimeBufferDuration)
+	}
+	if p.RealtimeRateWindow < minRealtimeRateWindow || p.RealtimeRateWindow > p.RealtimeBufferDuration {
+		return fmt.Errorf("REALTIME_RATE_WINDOW must be between %s and REALTIME_BUFFER_DURATION (%s), got %s", minRealtimeRateWindow, p.RealtimeBufferDuration, p.RealtimeRateWindow)
+	}
+	return nil
+}
+
+// loadDisplayTimezone resolves an IANA timezone name such as Europe/Rome.
+// "Local" is rejected: it depends on the container and PostgreSQL cannot resolve it.
+func loadDisplayTimezone(name string) (*time.Location, error) {
+	if name == "Local" {
+		return nil, fmt.Errorf("TIMEZONE must be an IANA timezone name such as Europe/Rome, got Local")
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("TIMEZONE %q is not a known timezone: %w", name, err)
+	}
+	return loc, nil
+}
+
+// loadCleanupTimezone resolves the DB_CLEANUP_TIMEZONE name; empty keeps the server's local time
+func loadCleanupTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("DB_CLEANUP_TIMEZONE %q is not a known timezone: %w", name, err)
+	}
+	return loc, nil
+}
+
 // Helper functions to read environment variables with defaults
 
 func getEnv(key, defaultValue string) string {
@@ -229,3 +615,47 @@ func getEnvAsFloat(key string, defaultValue float64) float64 {
 	}
 	return defaultValue
 }
+
+// getEnvAsIntMap parses "name:value,name:value" pairs, skipping malformed entries
+func getEnvAsIntMap(key string) map[string]int {
+	values := map[string]int{}
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		name, valueStr, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		if value, err := strconv.Atoi(strings.TrimSpace(valueStr)); err == nil && strings.TrimSpace(name) != "" {
+			values[strings.TrimSpace(name)] = value
+		}
+	}
+	return values
+}
+
+// getEnvAsStringSlice splits a comma-separated list, dropping empty entries
+func getEnvAsStringSlice(key string, defaultValue []string) []string {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	values := []string{}
+	for _, part := range strings.Split(valueStr, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func getEnvAsIntSlice(key string, defaultValue []int) []int {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	values := []int{}
+	for _, part := range strings.Split(valueStr, ",") {
+		if value, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validPerformanceConfig() PerformanceConfig {
+	return PerformanceConfig{
+		BatchTimeout:  500 * time.Millisecond,
+		MaxLineLength: 1024 * 1024,
+	}
+}
+
+func TestResolveIngestionAutoDefaults(t *testing.T) {
+	tests := []struct {
+		numCPU    int
+		workers   int
+		batchSize int
+	}{
+		{numCPU: 1, workers: 2, batchSize: 1000},
+		{numCPU: 4, workers: 4, batchSize: 1000},
+		{numCPU: 8, workers: 8, batchSize: 2000},
+		{numCPU: 64, workers: 16, batchSize: 5000},
+	}
+
+	for _, tt := range tests {
+		p := validPerformanceConfig()
+		require.NoError(t, p.resolveIngestion(tt.numCPU))
+		assert.Equal(t, tt.workers, p.WorkerPoolSize, "workers for %d CPUs", tt.numCPU)
+		assert.Equal(t, tt.batchSize, p.BatchSize, "batch size for %d CPUs", tt.numCPU)
+	}
+
+	// Explicit values are kept
+	p := validPerformanceConfig()
+	p.WorkerPoolSize = 3
+	p.BatchSize = 250
+	require.NoError(t, p.resolveIngestion(32))
+	assert.Equal(t, 3, p.WorkerPoolSize)
+	assert.Equal(t, 250, p.BatchSize)
+}
+
+func TestResolveIngestionRejectsOutOfRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *PerformanceConfig)
+		env    string
+	}{
+		{"negative workers", func(p *PerformanceConfig) { p.WorkerPoolSize = -1 }, "INGEST_WORKERS"},
+		{"too many workers", func(p *PerformanceConfig) { p.WorkerPoolSize = maxIngestWorkers + 1 }, "INGEST_WORKERS"},
+		{"negative batch size", func(p *PerformanceConfig) { p.BatchSize = -5 }, "INGEST_BATCH_SIZE"},
+		{"batch too large", func(p *PerformanceConfig) { p.BatchSize = maxIngestBatchSize + 1 }, "INGEST_BATCH_SIZE"},
+		{"timeout too short", func(p *PerformanceConfig) { p.BatchTimeout = time.Millisecond }, "INGEST_BATCH_TIMEOUT"},
+		{"timeout too long", func(p *PerformanceConfig) { p.BatchTimeout = 2 * time.Minute }, "INGEST_BATCH_TIMEOUT"},
+		{"line too short", func(p *PerformanceConfig) { p.MaxLineLength = 100 }, "INGEST_MAX_LINE_BYTES"},
+		{"negative flush delay", func(p *PerformanceConfig) { p.PartialLineFlushDelay = -time.Second }, "INGEST_PARTIAL_LINE_FLUSH_DELAY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validPerformanceConfig()
+			tt.modify(&p)
+			err := p.resolveIngestion(4)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.env)
+		})
+	}
+
+	// Bounds are inclusive
+	p := validPerformanceConfig()
+	p.WorkerPoolSize = maxIngestWorkers
+	p.BatchSize = maxIngestBatchSize
+	p.BatchTimeout = maxIngestBatchTimeout
+	assert.NoError(t, p.resolveIngestion(4))
+}
+
+func TestLoadIngestionSettings(t *testing.T) {
+	t.Setenv("INGEST_WORKERS", "6")
+	t.Setenv("INGEST_BATCH_SIZE", "1500")
+	t.Setenv("INGEST_BATCH_TIMEOUT", "2s")
+	t.Setenv("WORKER_POOL_SIZE", "2")
+	t.Setenv("BATCH_SIZE", "100")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 6, cfg.Performance.WorkerPoolSize, "INGEST_WORKERS wins over WORKER_POOL_SIZE")
+	assert.Equal(t, 1500, cfg.Performance.BatchSize, "INGEST_BATCH_SIZE wins over BATCH_SIZE")
+	assert.Equal(t, 2*time.Second, cfg.Performance.BatchTimeout)
+
+	// Legacy variables still apply when the new ones are unset
+	t.Setenv("INGEST_WORKERS", "")
+	t.Setenv("INGEST_BATCH_SIZE", "")
+	t.Setenv("INGEST_BATCH_TIMEOUT", "")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Performance.WorkerPoolSize)
+	assert.Equal(t, 100, cfg.Performance.BatchSize)
+	assert.Equal(t, 500*time.Millisecond, cfg.Performance.BatchTimeout)
+
+	t.Setenv("INGEST_WORKERS", "500")
+	_, err = Load()
+	assert.ErrorContains(t, err, "INGEST_WORKERS")
+}
+
+func TestLoadDisplayTimezone(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, cfg.Stats.DisplayTimezone, "defaults to UTC")
+
+	t.Setenv("TIMEZONE", "Europe/Rome")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Rome", cfg.Stats.DisplayTimezone.String())
+
+	for _, name := range []string{"Local", "Mars/Olympus"} {
+		t.Setenv("TIMEZONE", name)
+		_, err = Load()
+		assert.ErrorContains(t, err, "TIMEZONE", name)
+	}
+}
+
+func TestLoadRealtimeWindows(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 60*time.Second, cfg.Performance.RealtimeBufferDuration)
+	assert.Equal(t, 5*time.Second, cfg.Performance.RealtimeRateWindow)
+
+	t.Setenv("REALTIME_BUFFER_DURATION", "5m")
+	t.Setenv("REALTIME_RATE_WINDOW", "30s")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.Performance.RealtimeBufferDuration)
+	assert.Equal(t, 30*time.Second, cfg.Performance.RealtimeRateWindow)
+
+	t.Setenv("REALTIME_RATE_WINDOW", "10m")
+	_, err = Load()
+	assert.ErrorContains(t, err, "REALTIME_RATE_WINDOW", "the rate window cannot exceed the buffer")
+
+	t.Setenv("REALTIME_RATE_WINDOW", "5s")
+	t.Setenv("REALTIME_BUFFER_DURATION", "2h")
+	_, err = Load()
+	assert.ErrorContains(t, err, "REALTIME_BUFFER_DURATION")
+}
+
+func TestLoadCleanupSchedule(t *testing.T) {
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "daily", cfg.Database.CleanupSchedule)
+	assert.Equal(t, time.Local, cfg.Database.CleanupTimezone, "defaults to the server's local time")
+
+	t.Setenv("DB_CLEANUP_SCHEDULE", "interval")
+	t.Setenv("DB_CLEANUP_TIMEZONE", "America/New_York")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "interval", cfg.Database.CleanupSchedule)
+	assert.Equal(t, "America/New_York", cfg.Database.CleanupTimezone.String())
+
+	t.Setenv("DB_CLEANUP_TIMEZONE", "Mars/Olympus")
+	_, err = Load()
+	assert.ErrorContains(t, err, "DB_CLEANUP_TIMEZONE")
+}
+
+func TestRedactedMasksSecrets(t *testing.T) {
+	cfg := &Config{
+		Database:   DatabaseConfig{DSN: "postgres://loglynx:secret@db/loglynx", Path: "loglynx.db"},
+		Server:     ServerConfig{AdminToken: "token", Port: 8080},
+		LogSources: LogSourcesConfig{RemoteHeaders: "Authorization: Bearer abc"},
+	}
+
+	redacted := cfg.Redacted()
+	assert.Equal(t, redactedValue, redacted.Database.DSN)
+	assert.Equal(t, redactedValue, redacted.Server.AdminToken)
+	assert.Equal(t, redactedValue, redacted.LogSources.RemoteHeaders)
+	assert.Empty(t, redacted.GeoIP.LicenseKey, "unset secrets stay empty")
+	assert.Equal(t, "loglynx.db", redacted.Database.Path)
+	assert.Equal(t, 8080, redacted.Server.Port)
+	assert.Equal(t, "token", cfg.Server.AdminToken, "the original is left untouched")
+}
@@ -25,6 +25,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/pterm/pterm"
@@ -38,6 +39,11 @@ type CoordinatorController interface {
 	GetProcessorCount() int
 }
 
+// ReputationCache reports whether an IP is held in the in-memory GeoIP cache
+type ReputationCache interface {
+	IsCached(ip string) bool
+}
+
 // CleanupService manages database cleanup and retention
 type CleanupService struct {
 	db              *gorm.DB
@@ -45,6 +51,9 @@ type CleanupService struct {
 	retentionDays   int
 	cleanupInterval time.Duration
 	cleanupTime     string
+	schedule        string         // CleanupScheduleDaily or CleanupScheduleInterval
+	location        *time.Location // Timezone cleanupTime is read in
+	startedAt       time.Time
 	vacuumEnabled   bool
 	coordinator     CoordinatorController
 	stopChan        chan struct{}
@@ -53,16 +62,46 @@ type CleanupService struct {
 	lastRunTime     time.Time
 	recordsDeleted  int64
 	cleanupDuration time.Duration
+
+	// Optional purge of ip_reputation rows no request refers to anymore
+	reputationCleanup bool
+	reputationCache   ReputationCache // Cached IPs are never purged (nil = no guard)
+	reputationDeleted int64
+
+	rollupEnabled bool // Aggregate expired requests into hourly_rollups before deleting them
+
+	// Serializes VACUUM windows and on-demand optimize jobs
+	maintenanceMu sync.Mutex
+	jobsMu        sync.Mutex
+	optimizeJobs  []*OptimizeJob // Most recent last, capped at maxOptimizeJobs
+}
+
+// SourceRetention is the effective retention of one log source
+type SourceRetention struct {
+	Source        string `json:"source"`
+	RetentionDays int    `json:"retention_days"` // 0 = kept indefinitely
+	Inherited     bool   `json:"inherited"`      // Falls back to DB_RETENTION_DAYS
 }
 
+// Cleanup schedules (DB_CLEANUP_SCHEDULE)
+const (
+	CleanupScheduleDaily    = "daily"    // Once a day at DB_CLEANUP_TIME
+	CleanupScheduleInterval = "interval" // Every DB_CLEANUP_INTERVAL
+)
+
 // CleanupStats holds statistics about cleanup operations
 type CleanupStats struct {
 	LastRunTime      time.Time
 	RecordsDeleted   int64
+	ReputationPurged int64 // ip_reputation rows removed by the last run
 	SpaceFreed       int64
 	VacuumDuration   time.Duration
 	CleanupDuration  time.Duration
 	NextScheduledRun time.Time
+	// Effective policy: the global default and each source's resolved retention
+	DefaultRetentionDays int
+	SourceRetention      []SourceRetention
+	RetentionMode        string // "delete" or "rollup"
 }
 
 // NewCleanupService creates a new cleanup service
@@ -73,6 +112,8 @@ func NewCleanupService(db *gorm.DB, logger *pterm.Logger, retentionDays int, cle
 		retentionDays:   retentionDays,
 		cleanupInterval: cleanupInterval,
 		cleanupTime:     cleanupTime,
+		schedule:        CleanupScheduleDaily,
+		location:        time.Local,
 		vacuumEnabled:   vacuumEnabled,
 		coordinator:     coordinator,
 		stopChan:        make(chan struct{}),
@@ -80,18 +121,55 @@ func NewCleanupService(db *gorm.DB, logger *pterm.Logger, retentionDays int, cle
 	}
 }
 
+// SetReputationCleanup enables purging orphaned ip_reputation rows in the scheduled job
+func (s *CleanupService) SetReputationCleanup(enabled bool, cache ReputationCache) {
+	s.reputationCleanup = enabled
+	s.reputationCache = cache
+}
+
+// SetSchedule selects when cleanup runs: daily at the cleanup time, read in loc
+// (nil = server local time), or every cleanup interval
+func (s *CleanupService) SetSchedule(schedule string, loc *time.Location) {
+	if loc == nil {
+		loc = time.Local
+	}
+	s.location = loc
+
+	switch schedule {
+	case CleanupScheduleDaily, "":
+		s.schedule = CleanupScheduleDaily
+	case CleanupScheduleInterval:
+		if s.cleanupInterval <= 0 {
+			s.logger.Warn("Interval cleanup needs a positive DB_CLEANUP_INTERVAL, running daily instead",
+				s.logger.Args("interval", s.cleanupInterval))
+			s.schedule = CleanupScheduleDaily
+			return
+		}
+		s.schedule = CleanupScheduleInterval
+	default:
+		s.logger.Warn("Unknown cleanup schedule, running daily",
+			s.logger.Args("schedule", schedule, "supported", []string{CleanupScheduleDaily, CleanupScheduleInterval}))
+		s.schedule = CleanupScheduleDaily
+	}
+}
+
 // Start begins the cleanup service
 func (s *CleanupService) Start() {
-	if s.retentionDays <= 0 {
-		s.logger.Info("Data retention disabled (DB_RETENTION_DAYS=0), cleanup service not started")
+	if !s.retentionEnabled() {
+		s.logger.Info("Data retention disabled (DB_RETENTION_DAYS=0, no per-source retention), cleanup service not started")
 		return
 	}
 
 	s.running = true
+	s.startedAt = time.Now()
 	s.logger.Info("Starting database cleanup service",
 		s.logger.Args(
 			"retention_days", s.retentionDays,
+			"mode", s.retentionMode(),
+			"schedule", s.schedule,
 			"cleanup_time", s.cleanupTime,
+			"timezone", s.location.String(),
+			"interval", s.cleanupInterval,
 			"vacuum_enabled", s.vacuumEnabled,
 		))
 
@@ -109,7 +187,7 @@ func (s *CleanupService) Stop() {
 	s.running = false
 }
 
-// scheduledCleanupLoop runs cleanup at scheduled time daily
+// scheduledCleanupLoop runs cleanup at the scheduled time daily, or at every interval
 func (s *CleanupService) scheduledCleanupLoop() {
 	// Run initial cleanup check after 1 minute
 	time.Sleep(1 * time.Minute)
@@ -121,14 +199,9 @@ func (s *CleanupService) scheduledCleanupLoop() {
 		default:
 			// Check if it's time to run cleanup
 			now := time.Now()
-			targetTime := s.parseCleanupTime(now)
-
-			// If target time has passed today, schedule for tomorrow
-			if now.After(targetTime) {
-				targetTime = targetTime.Add(24 * time.Hour)
-			}
+			targetTime := s.nextRun(now)
 
-			waitDuration := time.Until(targetTime)
+			waitDuration := targetTime.Sub(now)
 			s.logger.Debug("Next cleanup scheduled",
 				s.logger.Args("next_run", targetTime.Format(time.DateTime), "wait_duration", waitDuration.Round(time.Minute)))
 
@@ -146,7 +219,27 @@ func (s *CleanupService) scheduledCleanupLoop() {
 	}
 }
 
-// parseCleanupTime parses the cleanup time string (HH:MM) and returns today's time
+// nextRun returns when the next scheduled cleanup is due after now
+func (s *CleanupService) nextRun(now time.Time) time.Time {
+	if s.schedule == CleanupScheduleInterval {
+		if s.startedAt.IsZero() || now.Before(s.startedAt) {
+			return now.Add(s.cleanupInterval)
+		}
+		// Runs are due every interval counted from Start
+		return s.startedAt.Add((now.Sub(s.startedAt)/s.cleanupInterval + 1) * s.cleanupInterval)
+	}
+
+	targetTime := s.parseCleanupTime(now.In(s.location))
+
+	// If target time has passed today, schedule for tomorrow (same wall clock time across DST changes)
+	if now.After(targetTime) {
+		targetTime = targetTime.AddDate(0, 0, 1)
+	}
+	return targetTime
+}
+
+// parseCleanupTime parses the cleanup time string (HH:MM) and returns its time on baseTime's day,
+// in baseTime's location
 func (s *CleanupService) parseCleanupTime(baseTime time.Time) time.Time {
 	// Parse HH:MM format
 	cleanupTime, err := time.Parse("15:04", s.cleanupTime)
@@ -167,48 +260,148 @@ func (s *CleanupService) parseCleanupTime(baseTime time.Time) time.Time {
 // runCleanup performs the cleanup operation
 func (s *CleanupService) runCleanup() {
 	s.logger.Info("Starting scheduled database cleanup",
-		s.logger.Args("retention_days", s.retentionDays))
+		s.logger.Args("retention_days", s.retentionDays, "mode", s.retentionMode()))
 
 	startTime := time.Now()
 
-	// Calculate cutoff date
-	cutoffDate := time.Now().AddDate(0, 0, -s.retentionDays)
-
-	// Delete old records in batches to avoid long locks
-	totalDeleted, err := s.deleteOldRecords(cutoffDate)
+	// Delete old records in batches to avoid long locks, rolling them up first if configured
+	var totalDeleted int64
+	var err error
+	if s.rollupEnabled {
+		totalDeleted, err = s.rollupOldRecords(startTime)
+	} else {
+		totalDeleted, err = s.deleteOldRecords(startTime)
+	}
 	if err != nil {
 		s.logger.WithCaller().Error("Failed to delete old records",
-			s.logger.Args("error", err, "cutoff_date", cutoffDate.Format("2006-01-02")))
+			s.logger.Args("error", err))
 		return
 	}
 
+	// Reputation rows are purged after requests so freshly orphaned IPs are included
+	reputationDeleted := int64(0)
+	if s.reputationCleanup {
+		reputationDeleted, err = s.deleteOrphanedReputation(startTime)
+		if err != nil {
+			s.logger.Warn("Failed to purge orphaned ip_reputation rows",
+				s.logger.Args("error", err, "purged", reputationDeleted))
+		}
+	}
+
 	cleanupDuration := time.Since(startTime)
 
 	// Update stats
 	s.lastRunTime = startTime
 	s.recordsDeleted = totalDeleted
+	s.reputationDeleted = reputationDeleted
 	s.cleanupDuration = cleanupDuration
 
 	s.logger.Info("Cleanup completed",
 		s.logger.Args(
 			"records_deleted", totalDeleted,
+			"reputation_purged", reputationDeleted,
 			"duration", cleanupDuration.Round(time.Second),
-			"cutoff_date", cutoffDate.Format("2006-01-02"),
 		))
 
 	// Run VACUUM if enabled and significant space was freed
-	if s.vacuumEnabled && totalDeleted > 0 {
+	if s.vacuumEnabled && totalDeleted+reputationDeleted > 0 {
 		s.runVacuum()
 	}
 }
 
-// deleteOldRecords deletes records older than cutoff date in batches
-func (s *CleanupService) deleteOldRecords(cutoffDate time.Time) (int64, error) {
+// retentionPolicy resolves the retention of every known log source
+func (s *CleanupService) retentionPolicy() ([]SourceRetention, error) {
+	var sources []struct {
+		Name          string
+		RetentionDays int
+	}
+	if err := s.db.Table("log_sources").Select("name, retention_days").Order("name").Scan(&sources).Error; err != nil {
+		return nil, err
+	}
+
+	policy := make([]SourceRetention, 0, len(sources))
+	for _, source := range sources {
+		retention := SourceRetention{Source: source.Name, RetentionDays: source.RetentionDays}
+		if retention.RetentionDays <= 0 {
+			retention.RetentionDays = s.retentionDays
+			retention.Inherited = true
+		}
+		if retention.RetentionDays < 0 {
+			retention.RetentionDays = 0
+		}
+		policy = append(policy, retention)
+	}
+	return policy, nil
+}
+
+// retentionEnabled reports whether any records can expire, globally or for a single source
+func (s *CleanupService) retentionEnabled() bool {
+	if s.retentionDays > 0 {
+		return true
+	}
+	policy, err := s.retentionPolicy()
+	if err != nil {
+		s.logger.Warn("Failed to load per-source retention", s.logger.Args("error", err))
+		return false
+	}
+	for _, retention := range policy {
+		if retention.RetentionDays > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// expiryConditions returns one WHERE clause per retention cutoff, relative to now.
+// Requests from sources missing in log_sources follow the global retention.
+func (s *CleanupService) expiryConditions(now time.Time) ([]string, [][]interface{}, error) {
+	policy, err := s.retentionPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+	now = now.UTC() // Timestamps are stored in UTC and compared as text
+
+	var conditions []string
+	var args [][]interface{}
+	for _, retention := range policy {
+		if retention.RetentionDays <= 0 {
+			continue
+		}
+		conditions = append(conditions, "source_name = ? AND timestamp < ?")
+		args = append(args, []interface{}{retention.Source, now.AddDate(0, 0, -retention.RetentionDays)})
+	}
+	if s.retentionDays > 0 {
+		conditions = append(conditions, "timestamp < ? AND source_name NOT IN (SELECT name FROM log_sources)")
+		args = append(args, []interface{}{now.AddDate(0, 0, -s.retentionDays)})
+	}
+	return conditions, args, nil
+}
+
+// deleteOldRecords deletes expired records in batches, one pass per source cutoff
+func (s *CleanupService) deleteOldRecords(now time.Time) (int64, error) {
+	conditions, args, err := s.expiryConditions(now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load retention policy: %w", err)
+	}
+
+	totalDeleted := int64(0)
+	for i, condition := range conditions {
+		deleted, err := s.deleteInBatches(condition, args[i]...)
+		totalDeleted += deleted
+		if err != nil {
+			return totalDeleted, err
+		}
+	}
+	return totalDeleted, nil
+}
+
+// deleteInBatches deletes records matching condition in batches
+func (s *CleanupService) deleteInBatches(condition string, conditionArgs ...interface{}) (int64, error) {
 	const batchSize = 1000
 	totalDeleted := int64(0)
 
 	s.logger.Debug("Deleting records in batches",
-		s.logger.Args("batch_size", batchSize, "cutoff_date", cutoffDate.Format("2006-01-02")))
+		s.logger.Args("batch_size", batchSize, "condition", condition, "args", conditionArgs))
 
 	for {
 		// Delete in batches using subquery to avoid full table scan
@@ -216,10 +409,10 @@ func (s *CleanupService) deleteOldRecords(cutoffDate time.Time) (int64, error) {
 			DELETE FROM http_requests
 			WHERE id IN (
 				SELECT id FROM http_requests
-				WHERE timestamp < ?
+				WHERE `+condition+`
 				LIMIT ?
 			)
-		`, cutoffDate, batchSize)
+		`, append(conditionArgs, batchSize)...)
 
 		if result.Error != nil {
 			return totalDeleted, result.Error
@@ -242,9 +435,98 @@ func (s *CleanupService) deleteOldRecords(cutoffDate time.Time) (int64, error) {
 	return totalDeleted, nil
 }
 
-// runVacuum runs VACUUM to reclaim space
-// pauses ingestion to prevent "database locked" errors
+// longestRetentionDays returns the largest retention in effect, globally or for any source
+func (s *CleanupService) longestRetentionDays() (int, error) {
+	policy, err := s.retentionPolicy()
+	if err != nil {
+		return 0, err
+	}
+	longest := s.retentionDays
+	for _, retention := range policy {
+		if retention.RetentionDays > longest {
+			longest = retention.RetentionDays
+		}
+	}
+	return longest, nil
+}
+
+// deleteOrphanedReputation removes ip_reputation rows last seen before the longest retention
+// that no stored request refers to. It runs after expired requests are deleted, so any
+// remaining request for the IP is recent. IPs in the GeoIP memory cache are skipped.
+func (s *CleanupService) deleteOrphanedReputation(now time.Time) (int64, error) {
+	const batchSize = 1000
+
+	days, err := s.longestRetentionDays()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load retention policy: %w", err)
+	}
+	if days <= 0 {
+		return 0, nil
+	}
+	cutoff := now.UTC().AddDate(0, 0, -days)
+
+	totalDeleted := int64(0)
+	lastID := uint(0)
+	for {
+		var candidates []struct {
+			ID        uint
+			IPAddress string
+		}
+		if err := s.db.Raw(`
+			SELECT id, ip_address FROM ip_reputation
+			WHERE id > ? AND last_seen < ?
+			AND NOT EXISTS (SELECT 1 FROM http_requests WHERE http_requests.client_ip = ip_reputation.ip_address)
+			ORDER BY id
+			LIMIT ?
+		`, lastID, cutoff, batchSize).Scan(&candidates).Error; err != nil {
+			return totalDeleted, err
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		lastID = candidates[len(candidates)-1].ID
+
+		ids := make([]uint, 0, len(candidates))
+		for _, candidate := range candidates {
+			if s.reputationCache != nil && s.reputationCache.IsCached(candidate.IPAddress) {
+				continue
+			}
+			ids = append(ids, candidate.ID)
+		}
+
+		if len(ids) > 0 {
+			result := s.db.Exec("DELETE FROM ip_reputation WHERE id IN ?", ids)
+			if result.Error != nil {
+				return totalDeleted, result.Error
+			}
+			totalDeleted += result.RowsAffected
+		}
+
+		s.logger.Trace("Purged ip_reputation batch",
+			s.logger.Args("candidates", len(candidates), "purged", len(ids), "total_purged", totalDeleted))
+
+		if len(candidates) < batchSize {
+			break
+		}
+
+		// Small pause between batches to avoid hogging the database
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return totalDeleted, nil
+}
+
+// runVacuum runs VACUUM after a scheduled cleanup, waiting for any running optimize job
 func (s *CleanupService) runVacuum() {
+	s.maintenanceMu.Lock()
+	defer s.maintenanceMu.Unlock()
+	_ = s.vacuum()
+}
+
+// vacuum runs VACUUM to reclaim space
+// pauses ingestion to prevent "database locked" errors
+// Callers must hold maintenanceMu.
+func (s *CleanupService) vacuum() error {
 	s.logger.Info("Starting VACUUM maintenance window")
 
 	startTime := time.Now()
@@ -284,7 +566,7 @@ func (s *CleanupService) runVacuum() {
 					s.logger.Args("error", err))
 			}
 		}
-		return
+		return err
 	}
 
 	vacuumDuration := time.Since(vacuumStart)
@@ -296,7 +578,7 @@ func (s *CleanupService) runVacuum() {
 			s.logger.WithCaller().Error("Failed to restart coordinator",
 				s.logger.Args("error", err))
 			// Critical error - coordinator should always restart
-			return
+			return err
 		}
 
 		// Verify processors restarted successfully
@@ -312,31 +594,55 @@ func (s *CleanupService) runVacuum() {
 			"vacuum_duration", vacuumDuration.Round(time.Second),
 			"total_duration", totalDuration.Round(time.Second),
 		))
+	return nil
 }
 
 // GetStats returns cleanup statistics
 func (s *CleanupService) GetStats() *CleanupStats {
-	// Calculate next scheduled run
-	now := time.Now()
-	targetTime := s.parseCleanupTime(now)
-
-	// If target time has passed today, schedule for tomorrow
-	if now.After(targetTime) {
-		targetTime = targetTime.Add(24 * time.Hour)
+	policy, err := s.retentionPolicy()
+	if err != nil {
+		s.logger.Warn("Failed to load per-source retention", s.logger.Args("error", err))
 	}
 
 	return &CleanupStats{
-		LastRunTime:      s.lastRunTime,
-		RecordsDeleted:   s.recordsDeleted,
-		CleanupDuration:  s.cleanupDuration,
-		NextScheduledRun: targetTime,
+		LastRunTime:          s.lastRunTime,
+		RecordsDeleted:       s.recordsDeleted,
+		ReputationPurged:     s.reputationDeleted,
+		CleanupDuration:      s.cleanupDuration,
+		NextScheduledRun:     s.nextRun(time.Now()),
+		DefaultRetentionDays: s.retentionDays,
+		SourceRetention:      policy,
+		RetentionMode:        s.retentionMode(),
 	}
 }
 
+// IsEnabled reports whether cleanup runs, i.e. some records have a retention limit
+func (s *CleanupService) IsEnabled() bool {
+	return s.retentionEnabled()
+}
+
+// CountExpiredRecords counts the records the next cleanup would delete
+func (s *CleanupService) CountExpiredRecords() (int64, error) {
+	conditions, args, err := s.expiryConditions(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	total := int64(0)
+	for i, condition := range conditions {
+		var count int64
+		if err := s.db.Table("http_requests").Where(condition, args[i]...).Count(&count).Error; err != nil {
+			return total, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
 // ManualCleanup triggers cleanup immediately (useful for testing/admin)
 func (s *CleanupService) ManualCleanup() error {
-	if s.retentionDays <= 0 {
-		return fmt.Errorf("retention disabled (DB_RETENTION_DAYS=0)")
+	if !s.retentionEnabled() {
+		return fmt.Errorf("retention disabled (DB_RETENTION_DAYS=0, no per-source retention)")
 	}
 
 	s.logger.Info("Manual cleanup triggered")
@@ -351,4 +657,4 @@ func min(a, b time.Duration) time.Duration {
 	}
 	return b
 }
-
+
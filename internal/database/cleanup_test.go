@@ -0,0 +1,232 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupCleanupTest(t *testing.T, retentionDays int) (*gorm.DB, *CleanupService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.LogSource{}, &models.HTTPRequest{}, &models.IPReputation{}, &models.HourlyRollup{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	logger := pterm.DefaultLogger
+	return db, NewCleanupService(db, &logger, retentionDays, time.Hour, "02:00", false, nil)
+}
+
+func TestCleanupHonorsPerSourceRetention(t *testing.T) {
+	db, service := setupCleanupTest(t, 30)
+	now := time.Now()
+
+	sources := []models.LogSource{
+		{Name: "auth-proxy", Path: "/logs/auth.log", ParserType: "traefik", RetentionDays: 90},
+		{Name: "static-assets", Path: "/logs/static.log", ParserType: "traefik", RetentionDays: 7},
+		{Name: "main", Path: "/logs/main.log", ParserType: "traefik"},
+	}
+	assert.NoError(t, db.Create(&sources).Error)
+
+	age := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	requests := []models.HTTPRequest{
+		{RequestHash: "auth-60", SourceName: "auth-proxy", Timestamp: age(60)},      // kept: 90 days
+		{RequestHash: "auth-100", SourceName: "auth-proxy", Timestamp: age(100)},    // expired
+		{RequestHash: "static-3", SourceName: "static-assets", Timestamp: age(3)},   // kept: 7 days
+		{RequestHash: "static-10", SourceName: "static-assets", Timestamp: age(10)}, // expired
+		{RequestHash: "main-20", SourceName: "main", Timestamp: age(20)},            // kept: global 30 days
+		{RequestHash: "main-40", SourceName: "main", Timestamp: age(40)},            // expired
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	expired, err := service.CountExpiredRecords()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), expired)
+
+	deleted, err := service.deleteOldRecords(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	var remaining []string
+	assert.NoError(t, db.Model(&models.HTTPRequest{}).Order("request_hash").Pluck("request_hash", &remaining).Error)
+	assert.Equal(t, []string{"auth-60", "main-20", "static-3"}, remaining)
+
+	stats := service.GetStats()
+	assert.Equal(t, 30, stats.DefaultRetentionDays)
+	assert.Equal(t, []SourceRetention{
+		{Source: "auth-proxy", RetentionDays: 90},
+		{Source: "main", RetentionDays: 30, Inherited: true},
+		{Source: "static-assets", RetentionDays: 7},
+	}, stats.SourceRetention)
+}
+
+func TestCleanupPerSourceRetentionWithoutGlobalRetention(t *testing.T) {
+	db, service := setupCleanupTest(t, 0)
+	assert.False(t, service.IsEnabled())
+
+	assert.NoError(t, db.Create(&models.LogSource{Name: "noisy", Path: "/logs/noisy.log", ParserType: "traefik", RetentionDays: 1}).Error)
+	assert.True(t, service.IsEnabled())
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "noisy-old", SourceName: "noisy", Timestamp: time.Now().AddDate(0, 0, -2)},
+		{RequestHash: "other-old", SourceName: "other", Timestamp: time.Now().AddDate(-1, 0, 0)}, // No retention: kept forever
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	deleted, err := service.deleteOldRecords(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+type fakeReputationCache map[string]bool
+
+func (c fakeReputationCache) IsCached(ip string) bool { return c[ip] }
+
+func TestCleanupPurgesOrphanedReputation(t *testing.T) {
+	db, service := setupCleanupTest(t, 30)
+	service.SetReputationCleanup(true, fakeReputationCache{"198.51.100.4": true})
+	now := time.Now()
+
+	old := now.AddDate(0, 0, -45)
+	reputations := []models.IPReputation{
+		{IPAddress: "198.51.100.1", FirstSeen: old, LastSeen: old},                    // orphaned: purged
+		{IPAddress: "198.51.100.2", FirstSeen: old, LastSeen: old},                    // still has a request
+		{IPAddress: "198.51.100.3", FirstSeen: now, LastSeen: now.AddDate(0, 0, -5)},  // seen recently
+		{IPAddress: "198.51.100.4", FirstSeen: old, LastSeen: old},                    // hot in the GeoIP cache
+		{IPAddress: "2001:db8::5", FirstSeen: old, LastSeen: old},                     // orphaned: purged
+		{IPAddress: "198.51.100.6", FirstSeen: old, LastSeen: now.AddDate(0, 0, -29)}, // within retention
+	}
+	assert.NoError(t, db.Create(&reputations).Error)
+	assert.NoError(t, db.Create(&models.HTTPRequest{RequestHash: "recent", ClientIP: "198.51.100.2", Timestamp: now.AddDate(0, 0, -1)}).Error)
+
+	purged, err := service.deleteOrphanedReputation(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	var remaining []string
+	assert.NoError(t, db.Model(&models.IPReputation{}).Order("ip_address").Pluck("ip_address", &remaining).Error)
+	assert.Equal(t, []string{"198.51.100.2", "198.51.100.3", "198.51.100.4", "198.51.100.6"}, remaining)
+}
+
+func TestCleanupReputationUsesLongestRetention(t *testing.T) {
+	db, service := setupCleanupTest(t, 0)
+	assert.NoError(t, db.Create(&models.LogSource{Name: "audit", Path: "/logs/audit.log", ParserType: "traefik", RetentionDays: 90}).Error)
+
+	now := time.Now()
+	reputations := []models.IPReputation{
+		{IPAddress: "203.0.113.1", FirstSeen: now, LastSeen: now.AddDate(0, 0, -60)},  // kept: audit keeps 90 days
+		{IPAddress: "203.0.113.2", FirstSeen: now, LastSeen: now.AddDate(0, 0, -120)}, // purged
+	}
+	assert.NoError(t, db.Create(&reputations).Error)
+
+	purged, err := service.deleteOrphanedReputation(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
+
+func TestCleanupRollsUpExpiredRecords(t *testing.T) {
+	db, service := setupCleanupTest(t, 30)
+	service.SetRetentionMode(RetentionModeRollup)
+	now := time.Now()
+	hour := now.AddDate(0, 0, -40).Truncate(time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "a-1", ClientIP: "1.1.1.1", SourceName: "main", Host: "a.example.com", StatusCode: 200, ResponseSize: 100, ResponseTimeMs: 10, Timestamp: hour.Add(5 * time.Minute)},
+		{RequestHash: "a-2", ClientIP: "2.2.2.2", SourceName: "main", Host: "a.example.com", StatusCode: 200, ResponseSize: 50, Timestamp: hour.Add(10 * time.Minute)},
+		{RequestHash: "a-3", ClientIP: "1.1.1.1", SourceName: "main", Host: "a.example.com", StatusCode: 404, Timestamp: hour.Add(20 * time.Minute)},
+		{RequestHash: "b-1", ClientIP: "1.1.1.1", SourceName: "main", Host: "b.example.com", StatusCode: 200, ResponseSize: 10, Timestamp: hour.Add(61 * time.Minute)},
+		{RequestHash: "recent", ClientIP: "1.1.1.1", SourceName: "main", Host: "a.example.com", StatusCode: 200, Timestamp: now.AddDate(0, 0, -1)},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	deleted, err := service.rollupOldRecords(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+
+	var remaining []string
+	assert.NoError(t, db.Model(&models.HTTPRequest{}).Pluck("request_hash", &remaining).Error)
+	assert.Equal(t, []string{"recent"}, remaining)
+
+	var rollups []models.HourlyRollup
+	assert.NoError(t, db.Order("timestamp, host, status_code").Find(&rollups).Error)
+	assert.Len(t, rollups, 3)
+	assert.Equal(t, "a.example.com", rollups[0].Host)
+	assert.Equal(t, 200, rollups[0].StatusCode)
+	assert.Equal(t, int64(2), rollups[0].Requests)
+	assert.Equal(t, int64(150), rollups[0].Bandwidth)
+	assert.Equal(t, int64(1), rollups[0].TimedRequests)
+	assert.Equal(t, 10.0, rollups[0].ResponseTimeSum)
+	assert.Equal(t, int64(2), rollups[0].UniqueVisitors)
+	assert.Equal(t, 404, rollups[1].StatusCode)
+	assert.Equal(t, "b.example.com", rollups[2].Host)
+	assert.True(t, rollups[2].Timestamp.Equal(hour.Add(time.Hour)))
+
+	// A late request for an hour already rolled up is added to its counters
+	assert.NoError(t, db.Create(&models.HTTPRequest{RequestHash: "a-late", ClientIP: "3.3.3.3", SourceName: "main", Host: "a.example.com", StatusCode: 200, ResponseSize: 5, Timestamp: hour.Add(30 * time.Minute)}).Error)
+	deleted, err = service.rollupOldRecords(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	var count int64
+	assert.NoError(t, db.Model(&models.HourlyRollup{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+	var merged models.HourlyRollup
+	assert.NoError(t, db.Where("host = ? AND status_code = ?", "a.example.com", 200).First(&merged).Error)
+	assert.Equal(t, int64(3), merged.Requests)
+	assert.Equal(t, int64(155), merged.Bandwidth)
+
+	assert.Equal(t, RetentionModeRollup, service.GetStats().RetentionMode)
+}
+
+func TestNextRunDailyHonorsTimezone(t *testing.T) {
+	_, service := setupCleanupTest(t, 30)
+	rome, err := time.LoadLocation("Europe/Rome")
+	assert.NoError(t, err)
+	service.SetSchedule(CleanupScheduleDaily, rome)
+
+	// 01:30 UTC is 02:30 in Rome: today's 02:00 has passed
+	now := time.Date(2026, 1, 15, 1, 30, 0, 0, time.UTC)
+	assert.True(t, service.nextRun(now).Equal(time.Date(2026, 1, 16, 2, 0, 0, 0, rome)))
+
+	// 00:30 UTC is 01:30 in Rome: the run is still due today
+	now = time.Date(2026, 1, 15, 0, 30, 0, 0, time.UTC)
+	assert.True(t, service.nextRun(now).Equal(time.Date(2026, 1, 15, 2, 0, 0, 0, rome)))
+
+	// Across the spring DST change the run keeps its wall clock time
+	now = time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC)
+	next := service.nextRun(now)
+	assert.Equal(t, 3, next.Hour(), "02:00 does not exist on 2026-03-29 in Rome")
+	assert.Equal(t, 29, next.Day())
+}
+
+func TestNextRunInterval(t *testing.T) {
+	_, service := setupCleanupTest(t, 30)
+	service.cleanupInterval = 4 * time.Hour
+	service.SetSchedule(CleanupScheduleInterval, nil)
+	assert.Equal(t, CleanupScheduleInterval, service.schedule)
+
+	started := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	service.startedAt = started
+	assert.Equal(t, started.Add(4*time.Hour), service.nextRun(started.Add(time.Minute)))
+	assert.Equal(t, started.Add(8*time.Hour), service.nextRun(started.Add(4*time.Hour)), "a due run moves to the next slot")
+	assert.Equal(t, started.Add(12*time.Hour), service.nextRun(started.Add(9*time.Hour)))
+
+	// GetStats reports the slot of the active schedule
+	service.startedAt = time.Now().Add(-5 * time.Hour)
+	assert.Equal(t, service.startedAt.Add(8*time.Hour), service.GetStats().NextScheduledRun)
+
+	// Interval mode needs an interval; unknown schedules fall back to daily
+	service.cleanupInterval = 0
+	service.SetSchedule(CleanupScheduleInterval, nil)
+	assert.Equal(t, CleanupScheduleDaily, service.schedule)
+	service.SetSchedule("hourly", nil)
+	assert.Equal(t, CleanupScheduleDaily, service.schedule)
+}
@@ -24,6 +24,7 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
 	"loglynx/internal/database/repositories"
 	"loglynx/internal/discovery"
 	"os"
@@ -32,13 +33,22 @@ import (
 	"time"
 
 	"github.com/pterm/pterm"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// Supported database drivers (DB_DRIVER), matching the GORM dialector names
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
 type Config struct {
-	Path         string
+	Driver       string // DriverSQLite (default) or DriverPostgres
+	DSN          string // PostgreSQL connection string
+	Path         string // SQLite database file
 	MaxOpenConns int
 	MaxIdleConns int
 	ConnMaxLife  time.Duration
@@ -117,7 +127,7 @@ func (l *SlowQueryLogger) Trace(ctx context.Context, begin time.Time, fc func()
 		// Ignore UNIQUE constraint errors - these are expected during deduplication
 		// The application handles them gracefully in the repository layer
 		errStr := err.Error()
-		if strings.Contains(errStr, "UNIQUE constraint failed") || strings.Contains(errStr, "request_hash") {
+		if strings.Contains(errStr, "UNIQUE constraint failed") || strings.Contains(errStr, "duplicate key value") || strings.Contains(errStr, "request_hash") {
 			// This is a duplicate - silently skip logging (summary is logged in repository)
 			return
 		}
@@ -131,28 +141,51 @@ func (l *SlowQueryLogger) Trace(ctx context.Context, begin time.Time, fc func()
 	}
 }
 
+// openDialector returns the GORM dialector for the configured driver
+func openDialector(cfg *Config, logger *pterm.Logger) (gorm.Dialector, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case DriverPostgres, "postgresql":
+		if cfg.DSN == "" {
+			return nil, errors.New("DB_DSN is required when DB_DRIVER=postgres")
+		}
+		logger.Debug("Initialization of the PostgreSQL database connection.")
+		return postgres.Open(cfg.DSN), nil
+
+	case DriverSQLite, "":
+		// Optimized DSN with:
+		// - WAL mode for concurrent reads/writes
+		// - NORMAL synchronous for balance between safety and speed
+		// - cache_size=-64000 (negative means KB, 64MB) for better query performance
+		// - busy_timeout=5000ms (5 seconds) to prevent SQLITE_BUSY errors
+		// Note: mattn/go-sqlite3 uses different parameter names than glebarez
+		dsn := cfg.Path + "?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=-64000&_busy_timeout=5000"
+		_, err := os.Stat(cfg.Path)
+
+		if errors.Is(err, os.ErrPermission) {
+			logger.WithCaller().Fatal("Permission denied to access database file.", logger.Args("error", err))
+			// Fatal() terminates the program, so no code after this will execute
+		}
+
+		logger.Debug("Permission to access database file granted.", logger.Args("path", cfg.Path))
+		logger.Debug("Initialization of the database with optimized settings (WAL mode, page_size=4096).")
+		return sqlite.Open(dsn), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q, expected %s or %s", cfg.Driver, DriverSQLite, DriverPostgres)
+	}
+}
+
 func NewConnection(cfg *Config, logger *pterm.Logger) (*gorm.DB, error) {
-	// Optimized DSN with:
-	// - WAL mode for concurrent reads/writes
-	// - NORMAL synchronous for balance between safety and speed
-	// - cache_size=-64000 (negative means KB, 64MB) for better query performance
-	// - busy_timeout=5000ms (5 seconds) to prevent SQLITE_BUSY errors
-	// Note: mattn/go-sqlite3 uses different parameter names than glebarez
-	dsn := cfg.Path + "?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=-64000&_busy_timeout=5000"
-	_, err := os.Stat(cfg.Path)
-
-	if errors.Is(err, os.ErrPermission) {
-		logger.WithCaller().Fatal("Permission denied to access database file.", logger.Args("error", err))
+	dialector, err := openDialector(cfg, logger)
+	if err != nil {
+		logger.WithCaller().Fatal("Invalid database configuration.", logger.Args("error", err))
 		// Fatal() terminates the program, so no code after this will execute
 	}
 
-	logger.Debug("Permission to access database file granted.", logger.Args("path", cfg.Path))
-	logger.Debug("Initialization of the database with optimized settings (WAL mode, page_size=4096).")
-
 	// Create slow query logger (log queries taking >100ms)
 	slowQueryLogger := NewSlowQueryLogger(logger, 100*time.Millisecond)
 
-	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialector, &gorm.Config{
 		PrepareStmt: true,
 		Logger:      slowQueryLogger,
 	})
@@ -1,7 +1,10 @@
 package indexes
 
 import (
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pterm/pterm"
 	"gorm.io/gorm"
@@ -9,8 +12,14 @@ import (
 
 // Definition represents an index name and its creation SQL.
 type Definition struct {
-	Name string
-	SQL  string
+	Name       string
+	SQL        string
+	SQLiteOnly bool // Relies on SQLite storing timestamps as text; skipped on PostgreSQL
+}
+
+// isPostgres reports whether db is a PostgreSQL connection
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
 }
 
 // expectedDefinitions is the single source of truth for performance indexes.
@@ -24,9 +33,9 @@ var expectedDefinitions = []Definition{
 	{Name: "idx_time_backend", SQL: `CREATE INDEX IF NOT EXISTS idx_time_backend ON http_requests(timestamp DESC, backend_name, status_code)`},
 	{Name: "idx_time_backend_url", SQL: `CREATE INDEX IF NOT EXISTS idx_time_backend_url ON http_requests(timestamp DESC, backend_url)`},
 	{Name: "idx_time_client_ip", SQL: `CREATE INDEX IF NOT EXISTS idx_time_client_ip ON http_requests(timestamp DESC, client_ip)`},
-	{Name: "idx_summary_cover", SQL: `CREATE INDEX IF NOT EXISTS idx_summary_cover ON http_requests(status_code, response_size, response_time_ms, client_ip, path)`},
-	{Name: "idx_summary_time_cover", SQL: `CREATE INDEX IF NOT EXISTS idx_summary_time_cover ON http_requests(timestamp, status_code, response_size, response_time_ms, client_ip, path, geo_country)`},
-	{Name: "idx_month_timeline", SQL: `CREATE INDEX IF NOT EXISTS idx_month_timeline ON http_requests(substr(timestamp, 1, 7), client_ip, response_size, response_time_ms, status_code)`},
+	{Name: "idx_summary_weight_cover", SQL: `CREATE INDEX IF NOT EXISTS idx_summary_weight_cover ON http_requests(status_code, response_size, response_time_ms, client_ip, path, sample_weight)`},
+	{Name: "idx_summary_time_weight_cover", SQL: `CREATE INDEX IF NOT EXISTS idx_summary_time_weight_cover ON http_requests(timestamp, status_code, response_size, response_time_ms, client_ip, path, geo_country, sample_weight)`},
+	{Name: "idx_month_timeline_weight", SQL: `CREATE INDEX IF NOT EXISTS idx_month_timeline_weight ON http_requests(substr(timestamp, 1, 7), client_ip, response_size, response_time_ms, status_code, sample_weight)`, SQLiteOnly: true},
 
 	// ===== AGGREGATION INDEXES (for GROUP BY queries) =====
 	{Name: "idx_path_agg", SQL: `CREATE INDEX IF NOT EXISTS idx_path_agg ON http_requests(path, timestamp, client_ip, response_time_ms, response_size)`},
@@ -55,11 +64,21 @@ var expectedDefinitions = []Definition{
 	{Name: "idx_method", SQL: `CREATE INDEX IF NOT EXISTS idx_method ON http_requests(method, timestamp)`},
 	{Name: "idx_asn_agg", SQL: `CREATE INDEX IF NOT EXISTS idx_asn_agg ON http_requests(asn, timestamp, asn_org, geo_country, response_size) WHERE asn > 0`},
 	{Name: "idx_device_type", SQL: `CREATE INDEX IF NOT EXISTS idx_device_type ON http_requests(device_type, timestamp) WHERE device_type != ''`},
+	{Name: "idx_browser_agg", SQL: `CREATE INDEX IF NOT EXISTS idx_browser_agg ON http_requests(browser, timestamp) WHERE browser != ''`},
+	{Name: "idx_os_agg", SQL: `CREATE INDEX IF NOT EXISTS idx_os_agg ON http_requests(os, timestamp) WHERE os != ''`},
+	{Name: "idx_user_agent_agg", SQL: `CREATE INDEX IF NOT EXISTS idx_user_agent_agg ON http_requests(user_agent, timestamp) WHERE user_agent != ''`},
+	{Name: "idx_content_type_agg", SQL: `CREATE INDEX IF NOT EXISTS idx_content_type_agg ON http_requests(response_content_type, timestamp, response_size) WHERE response_content_type != ''`},
+	{Name: "idx_origin_server_agg", SQL: `CREATE INDEX IF NOT EXISTS idx_origin_server_agg ON http_requests(origin_server, timestamp, host, response_size) WHERE origin_server != ''`},
+	{Name: "idx_request_id_lookup", SQL: `CREATE INDEX IF NOT EXISTS idx_request_id_lookup ON http_requests(request_id, timestamp) WHERE request_id != ''`},
+	{Name: "idx_trace_id_lookup", SQL: `CREATE INDEX IF NOT EXISTS idx_trace_id_lookup ON http_requests(trace_id, timestamp) WHERE trace_id != ''`},
+	{Name: "idx_source_time", SQL: `CREATE INDEX IF NOT EXISTS idx_source_time ON http_requests(source_name, timestamp)`},
+	{Name: "idx_label", SQL: `CREATE INDEX IF NOT EXISTS idx_label ON http_requests(label, timestamp, client_ip, response_size) WHERE label != ''`},
 	{Name: "idx_protocol", SQL: `CREATE INDEX IF NOT EXISTS idx_protocol ON http_requests(protocol, timestamp) WHERE protocol != ''`},
 	{Name: "idx_tls_version", SQL: `CREATE INDEX IF NOT EXISTS idx_tls_version ON http_requests(tls_version, timestamp) WHERE tls_version != ''`},
 
 	// ===== PARTIAL INDEXES (for specific filtered queries) =====
 	{Name: "idx_errors", SQL: `CREATE INDEX IF NOT EXISTS idx_errors ON http_requests(timestamp DESC, status_code, path, client_ip) WHERE status_code >= 400`},
+	{Name: "idx_retried", SQL: `CREATE INDEX IF NOT EXISTS idx_retried ON http_requests(timestamp DESC, retry_attempts, path, backend_name, backend_url, host, status_code) WHERE retry_attempts > 0`},
 	{Name: "idx_slow", SQL: `CREATE INDEX IF NOT EXISTS idx_slow ON http_requests(timestamp DESC, response_time_ms, path, host) WHERE response_time_ms > 1000`},
 	{Name: "idx_response_time", SQL: `CREATE INDEX IF NOT EXISTS idx_response_time ON http_requests(timestamp DESC, response_time_ms) WHERE response_time_ms > 0`},
 	{Name: "idx_response_value", SQL: `CREATE INDEX IF NOT EXISTS idx_response_value ON http_requests(response_time_ms) WHERE response_time_ms > 0`},
@@ -69,6 +88,8 @@ var expectedDefinitions = []Definition{
 }
 
 // legacyIndexes represent deprecated/older index names that should be dropped when reconciling.
+// A name must never appear here and in expectedDefinitions: it would be dropped and
+// recreated on every reconciliation that finds anything missing.
 var legacyIndexes = []string{
 	"idx_source_name",
 	"idx_timestamp",
@@ -76,11 +97,9 @@ var legacyIndexes = []string{
 	"idx_client_ip",
 	"idx_host",
 	"idx_status",
-	"idx_response_time",
 	"idx_retry_attempts",
 	"idx_browser",
 	"idx_os",
-	"idx_device_type",
 	"idx_router_name",
 	"idx_request_id",
 	"idx_trace_id",
@@ -98,10 +117,52 @@ var legacyIndexes = []string{
 	"idx_dashboard_covering",
 	"idx_error_analysis",
 	"idx_timestamp_cleanup",
+	// Replaced by the *_weight covering indexes, which include sample_weight
+	"idx_summary_cover",
+	"idx_summary_time_cover",
+	"idx_month_timeline",
+}
+
+// reconcileMu serializes index reconciliation. Startup optimization, deferred creation after
+// the first load and background reconciliation for existing databases can all call Ensure;
+// running them concurrently could interleave a legacy DROP with another caller's CREATE.
+var reconcileMu sync.Mutex
+
+// Reconciliation is the outcome of one Ensure call
+type Reconciliation struct {
+	At      time.Time `json:"at"`
+	Created int       `json:"created"`
+	Dropped int       `json:"dropped"`
+	Error   string    `json:"error,omitempty"`
 }
 
-// Ensure reconciles expected indexes against SQLite, dropping obsolete ones and creating missing ones.
+// lastReconciliation is the outcome of the last Ensure in this process, guarded by reconcileMu
+var lastReconciliation *Reconciliation
+
+// LastReconciliation returns the outcome of the last Ensure in this process, or nil
+// when indexes have not been reconciled yet (e.g. deferred until the first load ends)
+func LastReconciliation() *Reconciliation {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	if lastReconciliation == nil {
+		return nil
+	}
+	last := *lastReconciliation
+	return &last
+}
+
+// Ensure reconciles expected indexes against the database, dropping obsolete ones and creating missing ones.
+// Concurrent calls are serialized, so a later caller sees the indexes created by an earlier one.
 func Ensure(db *gorm.DB, logger *pterm.Logger) (created int, dropped int, err error) {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	defer func() {
+		lastReconciliation = &Reconciliation{At: time.Now().UTC(), Created: created, Dropped: dropped}
+		if err != nil {
+			lastReconciliation.Error = err.Error()
+		}
+	}()
+
 	existingIndexes, err := fetchExistingIndexes(db)
 	if err != nil {
 		return 0, 0, err
@@ -112,8 +173,10 @@ func Ensure(db *gorm.DB, logger *pterm.Logger) (created int, dropped int, err er
 		existingSet[name] = struct{}{}
 	}
 
+	definitions := definitionsFor(db)
+
 	var missing []Definition
-	for _, def := range expectedDefinitions {
+	for _, def := range definitions {
 		if _, ok := existingSet[def.Name]; !ok {
 			missing = append(missing, def)
 		}
@@ -133,7 +196,7 @@ func Ensure(db *gorm.DB, logger *pterm.Logger) (created int, dropped int, err er
 		}
 	}
 
-	for _, def := range expectedDefinitions {
+	for _, def := range definitions {
 		if err := db.Exec(def.SQL).Error; err != nil {
 			logger.Warn("Failed to create index", logger.Args("index", def.Name, "error", err))
 			return created, dropped, err
@@ -146,9 +209,74 @@ func Ensure(db *gorm.DB, logger *pterm.Logger) (created int, dropped int, err er
 	return created, dropped, nil
 }
 
+// definitionsFor returns the expected indexes that apply to db's dialect
+func definitionsFor(db *gorm.DB) []Definition {
+	if !isPostgres(db) {
+		return expectedDefinitions
+	}
+	definitions := make([]Definition, 0, len(expectedDefinitions))
+	for _, def := range expectedDefinitions {
+		if !def.SQLiteOnly {
+			definitions = append(definitions, def)
+		}
+	}
+	return definitions
+}
+
+// Report compares the indexes on http_requests with the expected ones
+type Report struct {
+	Expected []string `json:"expected"`
+	Present  []string `json:"present"`
+	Missing  []string `json:"missing"` // Expected but not created (yet)
+	Other    []string `json:"other"`   // Present but not managed by LogLynx, e.g. created by hand
+	Legacy   []string `json:"legacy"`  // Deprecated LogLynx indexes still present, dropped when a reconciliation finds one missing
+}
+
+// Status reports which expected indexes exist, without changing anything
+func Status(db *gorm.DB) (*Report, error) {
+	existing, err := fetchExistingIndexes(db)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(existing)
+
+	report := &Report{Present: existing, Missing: []string{}, Other: []string{}, Legacy: []string{}}
+	expected := make(map[string]struct{})
+	present := make(map[string]struct{}, len(existing))
+	for _, name := range existing {
+		present[name] = struct{}{}
+	}
+	for _, def := range definitionsFor(db) {
+		expected[def.Name] = struct{}{}
+		report.Expected = append(report.Expected, def.Name)
+		if _, ok := present[def.Name]; !ok {
+			report.Missing = append(report.Missing, def.Name)
+		}
+	}
+	legacy := make(map[string]struct{}, len(legacyIndexes))
+	for _, name := range legacyIndexes {
+		legacy[name] = struct{}{}
+	}
+	for _, name := range existing {
+		if _, ok := expected[name]; ok {
+			continue
+		}
+		if _, ok := legacy[name]; ok {
+			report.Legacy = append(report.Legacy, name)
+		} else {
+			report.Other = append(report.Other, name)
+		}
+	}
+	return report, nil
+}
+
 func fetchExistingIndexes(db *gorm.DB) ([]string, error) {
 	var names []string
-	rows, err := db.Raw(`SELECT name FROM sqlite_master WHERE type='index' AND tbl_name='http_requests' AND name NOT LIKE 'sqlite_%'`).Rows()
+	query := `SELECT name FROM sqlite_master WHERE type='index' AND tbl_name='http_requests' AND name NOT LIKE 'sqlite_%'`
+	if isPostgres(db) {
+		query = `SELECT indexname FROM pg_indexes WHERE tablename = 'http_requests' AND schemaname = current_schema()`
+	}
+	rows, err := db.Raw(query).Rows()
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,261 @@
+package indexes
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	// A file-backed database is required so every pooled connection sees the same schema
+	dsn := filepath.Join(t.TempDir(), "indexes.db") + "?_busy_timeout=5000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.HTTPRequest{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+func TestEnsureConcurrentReconcilers(t *testing.T) {
+	db := setupTestDB(t)
+	logger := pterm.DefaultLogger
+
+	// Seed a couple of legacy indexes so reconciliation has something to drop
+	assert.NoError(t, db.Exec(`CREATE INDEX idx_host ON http_requests(host)`).Error)
+	assert.NoError(t, db.Exec(`CREATE INDEX idx_ip_time ON http_requests(client_ip, timestamp)`).Error)
+
+	before, err := fetchExistingIndexes(db)
+	assert.NoError(t, err)
+	missing := 0
+	for _, def := range expectedDefinitions {
+		if !contains(before, def.Name) {
+			missing++
+		}
+	}
+
+	const reconcilers = 16
+	var (
+		wg           sync.WaitGroup
+		mu           sync.Mutex
+		totalCreated int
+		errs         []error
+	)
+
+	start := make(chan struct{})
+	for i := 0; i < reconcilers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			created, _, err := Ensure(db, &logger)
+
+			mu.Lock()
+			defer mu.Unlock()
+			totalCreated += created
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Empty(t, errs)
+
+	// Serialized reconciliation means only the first caller creates anything
+	assert.Equal(t, missing, totalCreated)
+
+	existing, err := fetchExistingIndexes(db)
+	assert.NoError(t, err)
+	for _, def := range expectedDefinitions {
+		assert.Contains(t, existing, def.Name)
+	}
+	assert.NotContains(t, existing, "idx_host")
+	assert.NotContains(t, existing, "idx_ip_time")
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEnsureSearch(t *testing.T) {
+	db := setupTestDB(t)
+	logger := pterm.DefaultLogger
+
+	assert.NoError(t, db.Create(&models.HTTPRequest{RequestHash: "search-1", Path: "/wp-login.php", UserAgent: "curl/8.0", Host: "example.com"}).Error)
+
+	available, err := EnsureSearch(db, &logger, true)
+	assert.NoError(t, err)
+	if !available {
+		t.Skip("SQLite built without FTS5; search falls back to LIKE")
+	}
+
+	// Rows stored before the table existed are backfilled, new rows are indexed by trigger
+	assert.NoError(t, db.Create(&models.HTTPRequest{RequestHash: "search-2", Path: "/api/users", UserAgent: "Mozilla/5.0", Host: "example.com"}).Error)
+
+	var ids []uint
+	assert.NoError(t, db.Raw(`SELECT rowid FROM `+SearchTable+` WHERE `+SearchTable+` MATCH ?`, `"login"`).Scan(&ids).Error)
+	assert.Equal(t, 1, len(ids))
+	assert.NoError(t, db.Raw(`SELECT rowid FROM `+SearchTable+` WHERE `+SearchTable+` MATCH ?`, `"mozilla"`).Scan(&ids).Error)
+	assert.Equal(t, 1, len(ids))
+
+	// Deletes are propagated
+	assert.NoError(t, db.Exec(`DELETE FROM http_requests WHERE request_hash = ?`, "search-1").Error)
+	assert.NoError(t, db.Raw(`SELECT rowid FROM `+SearchTable+` WHERE `+SearchTable+` MATCH ?`, `"login"`).Scan(&ids).Error)
+	assert.Equal(t, 0, len(ids))
+
+	// Calling again is a no-op; disabling drops the table and triggers
+	available, err = EnsureSearch(db, &logger, true)
+	assert.NoError(t, err)
+	assert.True(t, available)
+
+	available, err = EnsureSearch(db, &logger, false)
+	assert.NoError(t, err)
+	assert.False(t, available)
+
+	var count int64
+	assert.NoError(t, db.Raw(`SELECT COUNT(*) FROM sqlite_master WHERE name LIKE 'http_requests_fts%'`).Scan(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+// queryPatterns lists the column prefixes StatsRepository and HTTPRequestRepository
+// filter, group or sort on. Each must be the leading columns of an expected index.
+var queryPatterns = []struct {
+	query   string
+	columns []string
+}{
+	{"time window of every stats query and cleanup", []string{"timestamp"}},
+	{"deduplication on insert", []string{"request_hash"}},
+	{"status code distribution", []string{"status_code"}},
+	{"method distribution", []string{"method"}},
+	{"protocol distribution", []string{"protocol"}},
+	{"TLS version distribution", []string{"tls_version"}},
+	{"device types and bot traffic", []string{"device_type"}},
+	{"top browsers", []string{"browser"}},
+	{"top operating systems", []string{"os"}},
+	{"top user agents", []string{"user_agent"}},
+	{"top origin servers", []string{"origin_server"}},
+	{"content type distribution", []string{"response_content_type"}},
+	{"top paths", []string{"path"}},
+	{"top countries", []string{"geo_country"}},
+	{"top referrers", []string{"referer"}},
+	{"top ASNs", []string{"asn"}},
+	{"traffic by label", []string{"label"}},
+	{"top backends by name", []string{"backend_name"}},
+	{"top backends by URL", []string{"backend_url"}},
+	{"top backends by host", []string{"host"}},
+	{"IP analytics", []string{"client_ip", "timestamp"}},
+	{"host filter over time", []string{"timestamp", "host"}},
+	{"backend filter over time", []string{"timestamp", "backend_name"}},
+	{"client IP filter over time", []string{"timestamp", "client_ip"}},
+	{"response time percentiles", []string{"timestamp", "response_time_ms"}},
+	{"per-source lookups and retention", []string{"source_name", "timestamp"}},
+	{"request ID lookup", []string{"request_id"}},
+	{"trace ID lookup", []string{"trace_id"}},
+}
+
+// indexColumns returns the column expressions of a CREATE INDEX statement,
+// without sort order, e.g. [timestamp status_code] for "(timestamp DESC, status_code)"
+func indexColumns(sql string) []string {
+	start := strings.Index(sql, "(")
+	if start < 0 {
+		return nil
+	}
+
+	var columns []string
+	depth := 0
+	current := strings.Builder{}
+	for _, r := range sql[start+1:] {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth == 0:
+			return append(columns, normalizeColumn(current.String()))
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			columns = append(columns, normalizeColumn(current.String()))
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return columns
+}
+
+func normalizeColumn(column string) string {
+	column = strings.TrimSpace(column)
+	column = strings.TrimSuffix(column, " DESC")
+	column = strings.TrimSuffix(column, " ASC")
+	return strings.TrimSpace(column)
+}
+
+func TestIndexColumns(t *testing.T) {
+	assert.Equal(t, []string{"timestamp", "status_code"},
+		indexColumns(`CREATE INDEX IF NOT EXISTS x ON http_requests(timestamp DESC, status_code)`))
+	assert.Equal(t, []string{"substr(timestamp, 1, 7)", "client_ip"},
+		indexColumns(`CREATE INDEX IF NOT EXISTS x ON http_requests(substr(timestamp, 1, 7), client_ip) WHERE client_ip != ''`))
+}
+
+func TestQueryPatternsHaveIndexes(t *testing.T) {
+	for _, pattern := range queryPatterns {
+		covered := false
+		for _, def := range expectedDefinitions {
+			columns := indexColumns(def.SQL)
+			if len(columns) < len(pattern.columns) {
+				continue
+			}
+			matches := true
+			for i, column := range pattern.columns {
+				if columns[i] != column {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				covered = true
+				break
+			}
+		}
+		assert.True(t, covered, "no index leads with %v (%s)", pattern.columns, pattern.query)
+	}
+}
+
+func TestExpectedDefinitionsAreConsistent(t *testing.T) {
+	seen := make(map[string]struct{}, len(expectedDefinitions))
+	for _, def := range expectedDefinitions {
+		_, duplicate := seen[def.Name]
+		assert.False(t, duplicate, "index %s defined twice", def.Name)
+		seen[def.Name] = struct{}{}
+
+		assert.Contains(t, def.SQL, " "+def.Name+" ON http_requests(", "SQL of %s creates a different index", def.Name)
+	}
+}
+
+func TestLegacyIndexesDoNotOverlapExpected(t *testing.T) {
+	expected := make(map[string]struct{}, len(expectedDefinitions))
+	for _, def := range expectedDefinitions {
+		expected[def.Name] = struct{}{}
+	}
+	for _, name := range legacyIndexes {
+		_, overlap := expected[name]
+		assert.False(t, overlap, "%s is both legacy and expected: it would be dropped and recreated on reconcile", name)
+	}
+}
@@ -0,0 +1,128 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package indexes
+
+import (
+	"strings"
+
+	"github.com/pterm/pterm"
+	"gorm.io/gorm"
+)
+
+// SearchTable is the FTS5 table indexing http_requests.path and user_agent.
+// It is an external-content table: rows are kept in sync by triggers, and the
+// trigram tokenizer lets MATCH answer substring queries like LIKE '%...%'.
+//
+// Tradeoff: every insert, delete and path/user_agent update pays for a second
+// write into the FTS index (roughly doubling insert cost and adding 30-60% to
+// the database size), in exchange for searches that do not scan the table.
+const SearchTable = "http_requests_fts"
+
+var searchTableSQL = `CREATE VIRTUAL TABLE ` + SearchTable + ` USING fts5(
+	path, user_agent, content='http_requests', content_rowid='id', tokenize='trigram'
+)`
+
+var searchTriggers = []Definition{
+	{Name: "http_requests_fts_ai", SQL: `CREATE TRIGGER IF NOT EXISTS http_requests_fts_ai AFTER INSERT ON http_requests BEGIN
+		INSERT INTO ` + SearchTable + `(rowid, path, user_agent) VALUES (new.id, new.path, new.user_agent);
+	END`},
+	{Name: "http_requests_fts_ad", SQL: `CREATE TRIGGER IF NOT EXISTS http_requests_fts_ad AFTER DELETE ON http_requests BEGIN
+		INSERT INTO ` + SearchTable + `(` + SearchTable + `, rowid, path, user_agent) VALUES ('delete', old.id, old.path, old.user_agent);
+	END`},
+	{Name: "http_requests_fts_au", SQL: `CREATE TRIGGER IF NOT EXISTS http_requests_fts_au AFTER UPDATE OF path, user_agent ON http_requests BEGIN
+		INSERT INTO ` + SearchTable + `(` + SearchTable + `, rowid, path, user_agent) VALUES ('delete', old.id, old.path, old.user_agent);
+		INSERT INTO ` + SearchTable + `(rowid, path, user_agent) VALUES (new.id, new.path, new.user_agent);
+	END`},
+}
+
+// EnsureSearch creates the full-text search table and its triggers when enabled,
+// backfilling it from existing rows, and removes them when disabled.
+// It reports whether the table can be queried; a SQLite build without FTS5
+// (or the trigram tokenizer) is not an error, callers fall back to LIKE.
+func EnsureSearch(db *gorm.DB, logger *pterm.Logger, enabled bool) (bool, error) {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+
+	if isPostgres(db) {
+		// FTS5 is SQLite-only; PostgreSQL searches keep using LIKE
+		if enabled {
+			logger.Warn("Full-text search index is only available with SQLite, request search will use LIKE")
+		}
+		return false, nil
+	}
+
+	var count int64
+	if err := db.Raw(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`, SearchTable).Scan(&count).Error; err != nil {
+		return false, err
+	}
+	exists := count > 0
+
+	if !enabled {
+		if exists {
+			// Stop paying the write overhead once search is switched off
+			for _, trigger := range searchTriggers {
+				if err := db.Exec("DROP TRIGGER IF EXISTS " + trigger.Name).Error; err != nil {
+					return false, err
+				}
+			}
+			if err := db.Exec("DROP TABLE IF EXISTS " + SearchTable).Error; err != nil {
+				return false, err
+			}
+			logger.Info("Removed full-text search index")
+		}
+		return false, nil
+	}
+
+	if !exists {
+		if err := db.Exec(searchTableSQL).Error; err != nil {
+			if isMissingFTS5(err) {
+				logger.Warn("SQLite build lacks FTS5 trigram support, request search will use LIKE",
+					logger.Args("error", err))
+				return false, nil
+			}
+			return false, err
+		}
+	}
+
+	for _, trigger := range searchTriggers {
+		if err := db.Exec(trigger.SQL).Error; err != nil {
+			logger.Warn("Failed to create search trigger", logger.Args("trigger", trigger.Name, "error", err))
+			return false, err
+		}
+	}
+
+	if !exists {
+		// Index the rows stored before the table existed
+		if err := db.Exec(`INSERT INTO ` + SearchTable + `(` + SearchTable + `) VALUES ('rebuild')`).Error; err != nil {
+			return false, err
+		}
+		logger.Info("Built full-text search index")
+	}
+
+	return true, nil
+}
+
+// isMissingFTS5 reports errors caused by SQLite being compiled without FTS5 or trigram
+func isMissingFTS5(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "no such module") || strings.Contains(msg, "no such tokenizer")
+}
@@ -0,0 +1,224 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"loglynx/internal/database/indexes"
+	"loglynx/internal/database/models"
+)
+
+// ErrMaintenanceRunning is returned when an optimize job or VACUUM window is already in progress
+var ErrMaintenanceRunning = errors.New("database maintenance already running")
+
+// maxOptimizeJobs bounds the finished jobs kept for polling
+const maxOptimizeJobs = 20
+
+// Optimize job states
+const (
+	OptimizeRunning = "running"
+	OptimizeDone    = "done"
+	OptimizeFailed  = "failed"
+)
+
+// OptimizeJob reports the progress of an on-demand optimize run
+type OptimizeJob struct {
+	ID             string     `json:"id"`
+	Status         string     `json:"status"`         // running, done or failed
+	Step           string     `json:"step,omitempty"` // analyze, indexes or vacuum while running
+	Vacuum         bool       `json:"vacuum"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	RowsAnalyzed   int64      `json:"rows_analyzed"` // http_requests rows the planner statistics cover
+	IndexesCreated int        `json:"indexes_created"`
+	IndexesDropped int        `json:"indexes_dropped"`
+	SizeBefore     int64      `json:"size_before_bytes"`
+	SizeAfter      int64      `json:"size_after_bytes"`
+	SpaceFreed     int64      `json:"space_freed_bytes"`
+	Error          string     `json:"error,omitempty"`
+}
+
+// StartOptimize runs ANALYZE, reconciles indexes and optionally VACUUMs in the background.
+// VACUUM pauses ingestion like the scheduled maintenance window. The returned snapshot
+// carries the id to poll with OptimizeStatus.
+func (s *CleanupService) StartOptimize(vacuum bool) (OptimizeJob, error) {
+	if !s.maintenanceMu.TryLock() {
+		return OptimizeJob{}, ErrMaintenanceRunning
+	}
+
+	job := &OptimizeJob{
+		ID:        newJobID(),
+		Status:    OptimizeRunning,
+		Vacuum:    vacuum,
+		StartedAt: time.Now(),
+	}
+
+	s.jobsMu.Lock()
+	s.optimizeJobs = append(s.optimizeJobs, job)
+	if len(s.optimizeJobs) > maxOptimizeJobs {
+		s.optimizeJobs = s.optimizeJobs[len(s.optimizeJobs)-maxOptimizeJobs:]
+	}
+	snapshot := *job
+	s.jobsMu.Unlock()
+
+	s.logger.Info("Manual database optimize started", s.logger.Args("job", job.ID, "vacuum", vacuum))
+
+	go func() {
+		defer s.maintenanceMu.Unlock()
+		s.runOptimize(job)
+	}()
+
+	return snapshot, nil
+}
+
+// OptimizeStatus returns a snapshot of a recent optimize job
+func (s *CleanupService) OptimizeStatus(id string) (OptimizeJob, bool) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	for _, job := range s.optimizeJobs {
+		if job.ID == id {
+			return *job, true
+		}
+	}
+	return OptimizeJob{}, false
+}
+
+// runOptimize performs the job steps; the caller holds maintenanceMu
+func (s *CleanupService) runOptimize(job *OptimizeJob) {
+	sizeBefore := s.databaseSize()
+	s.updateJob(job, func(j *OptimizeJob) {
+		j.SizeBefore = sizeBefore
+		j.Step = "analyze"
+	})
+
+	if err := s.db.Exec("ANALYZE").Error; err != nil {
+		s.finishJob(job, err)
+		return
+	}
+	var rows int64
+	if err := s.db.Model(&models.HTTPRequest{}).Count(&rows).Error; err != nil {
+		s.logger.Warn("Failed to count analyzed rows", s.logger.Args("error", err))
+	}
+	s.updateJob(job, func(j *OptimizeJob) {
+		j.RowsAnalyzed = rows
+		j.Step = "indexes"
+	})
+
+	created, dropped, err := indexes.Ensure(s.db, s.logger)
+	s.updateJob(job, func(j *OptimizeJob) {
+		j.IndexesCreated = created
+		j.IndexesDropped = dropped
+	})
+	if err != nil {
+		s.finishJob(job, err)
+		return
+	}
+
+	if job.Vacuum {
+		s.updateJob(job, func(j *OptimizeJob) { j.Step = "vacuum" })
+		if err := s.vacuum(); err != nil {
+			s.finishJob(job, err)
+			return
+		}
+	}
+
+	s.finishJob(job, nil)
+}
+
+// finishJob records the final sizes and outcome of a job
+func (s *CleanupService) finishJob(job *OptimizeJob, err error) {
+	sizeAfter := s.databaseSize()
+	finished := time.Now()
+
+	s.updateJob(job, func(j *OptimizeJob) {
+		j.Step = ""
+		j.FinishedAt = &finished
+		j.SizeAfter = sizeAfter
+		if j.SizeBefore > sizeAfter && sizeAfter > 0 {
+			j.SpaceFreed = j.SizeBefore - sizeAfter
+		}
+		if err != nil {
+			j.Status = OptimizeFailed
+			j.Error = err.Error()
+		} else {
+			j.Status = OptimizeDone
+		}
+	})
+
+	if err != nil {
+		s.logger.WithCaller().Error("Manual database optimize failed",
+			s.logger.Args("job", job.ID, "error", err))
+		return
+	}
+	snapshot, _ := s.OptimizeStatus(job.ID)
+	s.logger.Info("Manual database optimize completed",
+		s.logger.Args(
+			"job", job.ID,
+			"indexes_created", snapshot.IndexesCreated,
+			"space_freed_bytes", snapshot.SpaceFreed,
+			"duration", finished.Sub(snapshot.StartedAt).Round(time.Millisecond),
+		))
+}
+
+// updateJob applies a change to a job under the jobs lock so pollers see consistent snapshots
+func (s *CleanupService) updateJob(job *OptimizeJob, update func(*OptimizeJob)) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	update(job)
+}
+
+// databaseSize returns the size of the database in bytes, or 0 if it cannot be determined
+func (s *CleanupService) databaseSize() int64 {
+	var size int64
+	if s.db.Dialector.Name() == DriverPostgres {
+		if err := s.db.Raw("SELECT pg_database_size(current_database())").Scan(&size).Error; err != nil {
+			s.logger.Debug("Failed to read database size", s.logger.Args("error", err))
+			return 0
+		}
+		return size
+	}
+
+	var pageCount, pageSize int64
+	if err := s.db.Raw("PRAGMA page_count").Scan(&pageCount).Error; err != nil {
+		s.logger.Debug("Failed to read database size", s.logger.Args("error", err))
+		return 0
+	}
+	if err := s.db.Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
+		s.logger.Debug("Failed to read database size", s.logger.Args("error", err))
+		return 0
+	}
+	return pageCount * pageSize
+}
+
+// newJobID returns a random identifier for polling a job
+func newJobID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestStartOptimize(t *testing.T) {
+	// A file database, so ANALYZE and VACUUM see the same data on every pooled connection
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "optimize.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.LogSource{}, &models.HTTPRequest{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	assert.NoError(t, db.Create(&[]models.HTTPRequest{
+		{RequestHash: "opt-1", SourceName: "main", Timestamp: time.Now()},
+		{RequestHash: "opt-2", SourceName: "main", Timestamp: time.Now()},
+	}).Error)
+
+	logger := pterm.DefaultLogger
+	service := NewCleanupService(db, &logger, 0, time.Hour, "02:00", false, nil)
+
+	job, err := service.StartOptimize(true)
+	assert.NoError(t, err)
+	assert.Equal(t, OptimizeRunning, job.Status)
+
+	var status OptimizeJob
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		var ok bool
+		status, ok = service.OptimizeStatus(job.ID)
+		assert.True(t, ok)
+		if status.Status != OptimizeRunning {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, OptimizeDone, status.Status, status.Error)
+	assert.Equal(t, int64(2), status.RowsAnalyzed)
+	assert.Greater(t, status.IndexesCreated, 0)
+	assert.Greater(t, status.SizeAfter, int64(0))
+	assert.NotNil(t, status.FinishedAt)
+
+	_, ok := service.OptimizeStatus("missing")
+	assert.False(t, ok)
+}
+
+func TestStartOptimizeWhileMaintenanceRuns(t *testing.T) {
+	_, service := setupCleanupTest(t, 30)
+
+	service.maintenanceMu.Lock()
+	defer service.maintenanceMu.Unlock()
+
+	_, err := service.StartOptimize(false)
+	assert.ErrorIs(t, err, ErrMaintenanceRunning)
+}
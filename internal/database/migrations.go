@@ -22,17 +22,111 @@
 package database
 
 import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"loglynx/internal/database/indexes"
 	"loglynx/internal/database/models"
+	"loglynx/internal/version"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// SchemaVersion is the schema version of this build. Bump it, with a schemaVersions
+// entry, whenever a release changes the models or the expected indexes.
+const SchemaVersion = 1
+
+// schemaVersions describes every schema version up to SchemaVersion, in order
+var schemaVersions = []struct {
+	Version     int
+	Description string
+}{
+	{1, "Tables created by AutoMigrate, http_requests indexes reconciled by name"},
+}
+
+// migrationsApplied is set once RunMigrations has succeeded in this process
+var migrationsApplied atomic.Bool
+
+// MigrationsApplied reports whether the schema migrations have completed
+func MigrationsApplied() bool {
+	return migrationsApplied.Load()
+}
+
 func RunMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&models.LogSource{},
+		&models.LogSourceFile{},
 		&models.HTTPRequest{},
 		&models.IPReputation{},
 		&models.IPTag{},
 		&models.ComparisonSnapshot{},
+		&models.ParseStat{},
+		&models.HourlyRollup{},
+		&models.SchemaMigration{},
 	)
+	if err != nil {
+		return err
+	}
+	if err := recordSchemaVersions(db); err != nil {
+		return err
+	}
+	migrationsApplied.Store(true)
+	return nil
+}
+
+// recordSchemaVersions stores the schema versions this database has not reached before
+func recordSchemaVersions(db *gorm.DB) error {
+	now := time.Now().UTC()
+	for _, v := range schemaVersions {
+		migration := &models.SchemaMigration{
+			Version:     v.Version,
+			Description: v.Description,
+			AppVersion:  version.Version,
+			AppliedAt:   now,
+		}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(migration).Error; err != nil {
+			return fmt.Errorf("failed to record schema version %d: %w", v.Version, err)
+		}
+	}
+	return nil
+}
+
+// SchemaReport is the schema version of the database and the state of its indexes
+type SchemaReport struct {
+	Version           int                      `json:"version"`         // Schema version of this build
+	AppliedVersion    int                      `json:"applied_version"` // Highest version recorded; above Version after a downgrade
+	UpToDate          bool                     `json:"up_to_date"`
+	MigrationsApplied bool                     `json:"migrations_applied"` // RunMigrations succeeded in this process
+	Migrations        []models.SchemaMigration `json:"migrations"`
+	Indexes           *indexes.Report          `json:"indexes"`
+	// Outcome of the last index reconciliation in this process (nil until one ran)
+	LastReconciliation *indexes.Reconciliation `json:"last_reconciliation"`
+}
+
+// GetSchemaReport reports the applied schema versions and compares the indexes of
+// http_requests with the expected ones, without changing anything
+func GetSchemaReport(db *gorm.DB) (*SchemaReport, error) {
+	report := &SchemaReport{
+		Version:            SchemaVersion,
+		MigrationsApplied:  MigrationsApplied(),
+		Migrations:         []models.SchemaMigration{},
+		LastReconciliation: indexes.LastReconciliation(),
+	}
+
+	if err := db.Order("version ASC").Find(&report.Migrations).Error; err != nil {
+		return nil, fmt.Errorf("failed to load schema migrations: %w", err)
+	}
+	for _, migration := range report.Migrations {
+		report.AppliedVersion = max(report.AppliedVersion, migration.Version)
+	}
+	report.UpToDate = report.AppliedVersion == SchemaVersion
+
+	status, err := indexes.Status(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	report.Indexes = status
+	return report, nil
 }
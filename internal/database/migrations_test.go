@@ -0,0 +1,57 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"loglynx/internal/database/indexes"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSchemaReport(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "schema.db")), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, RunMigrations(db))
+	require.NoError(t, RunMigrations(db), "versions already recorded are kept")
+
+	report, err := GetSchemaReport(db)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, report.Version)
+	assert.Equal(t, SchemaVersion, report.AppliedVersion)
+	assert.True(t, report.UpToDate)
+	assert.True(t, report.MigrationsApplied)
+	require.Len(t, report.Migrations, len(schemaVersions))
+	assert.Equal(t, 1, report.Migrations[0].Version)
+	assert.NotEmpty(t, report.Indexes.Missing, "indexes are created after migrations")
+
+	// A deprecated index left behind by an older release is reported until reconciled
+	require.NoError(t, db.Exec(`CREATE INDEX idx_host ON http_requests(host)`).Error)
+	report, err = GetSchemaReport(db)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"idx_host"}, report.Indexes.Legacy)
+
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	created, dropped, err := indexes.Ensure(db, logger)
+	require.NoError(t, err)
+	report, err = GetSchemaReport(db)
+	require.NoError(t, err)
+	assert.Empty(t, report.Indexes.Missing)
+	assert.Empty(t, report.Indexes.Legacy)
+	require.NotNil(t, report.LastReconciliation)
+	assert.Equal(t, created, report.LastReconciliation.Created)
+	assert.Equal(t, dropped, report.LastReconciliation.Dropped)
+	assert.Empty(t, report.LastReconciliation.Error)
+
+	// A database migrated by a newer release is not up to date for this build
+	require.NoError(t, db.Exec(`INSERT INTO schema_migrations (version, description, app_version, applied_at) VALUES (?, 'future', 'v99', CURRENT_TIMESTAMP)`, SchemaVersion+1).Error)
+	report, err = GetSchemaReport(db)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion+1, report.AppliedVersion)
+	assert.False(t, report.UpToDate)
+}
@@ -0,0 +1,52 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package models
+
+import (
+	"time"
+)
+
+// HourlyRollup holds additive counters of raw requests that were aggregated and deleted by
+// the cleanup service (DB_RETENTION_MODE=rollup). Columns share the names used by
+// http_requests so the stats time window and service filters apply to both tables.
+type HourlyRollup struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	Timestamp   time.Time `gorm:"not null;uniqueIndex:idx_rollup_key,priority:1"` // Start of the hour
+	SourceName  string    `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_rollup_key,priority:2"`
+	Host        string    `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_rollup_key,priority:3"`
+	BackendName string    `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_rollup_key,priority:4"`
+	BackendURL  string    `gorm:"type:varchar(512);not null;default:'';uniqueIndex:idx_rollup_key,priority:5"`
+	StatusCode  int       `gorm:"not null;default:0;uniqueIndex:idx_rollup_key,priority:6"`
+	IsInternal  bool      `gorm:"not null;default:false;uniqueIndex:idx_rollup_key,priority:7"`
+
+	Requests        int64   `gorm:"not null;default:0"`
+	Bandwidth       int64   `gorm:"not null;default:0"` // Sum of response_size
+	BytesIn         int64   `gorm:"not null;default:0"` // Sum of request_length
+	ResponseTimeSum float64 `gorm:"not null;default:0"` // Sum of response_time_ms
+	TimedRequests   int64   `gorm:"not null;default:0"` // Requests with a response time > 0
+	UniqueVisitors  int64   `gorm:"not null;default:0"` // Distinct client IPs of the group; overcounts when summed
+}
+
+func (HourlyRollup) TableName() string {
+	return "hourly_rollups"
+}
@@ -0,0 +1,56 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package models
+
+import "strings"
+
+// MethodOther is stored in place of HTTP methods outside KnownMethods, so malformed
+// lines cannot fill the method column with garbage verbs
+const MethodOther = "OTHER"
+
+// KnownMethods are the HTTP methods stored as-is: RFC 9110, PATCH and the WebDAV/CalDAV
+// methods used by file and calendar servers commonly run behind a proxy
+var KnownMethods = []string{
+	"GET", "HEAD", "POST", "PUT", "DELETE", "CONNECT", "OPTIONS", "TRACE", "PATCH",
+	"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK", "REPORT", "SEARCH", "MKCALENDAR",
+}
+
+var knownMethods = func() map[string]struct{} {
+	set := make(map[string]struct{}, len(KnownMethods))
+	for _, method := range KnownMethods {
+		set[method] = struct{}{}
+	}
+	return set
+}()
+
+// NormalizeMethod uppercases an HTTP method and maps unknown ones to MethodOther.
+// An empty method (not logged, e.g. TCP entries) stays empty.
+func NormalizeMethod(method string) string {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		return ""
+	}
+	if _, ok := knownMethods[method]; ok {
+		return method
+	}
+	return MethodOther
+}
@@ -42,6 +42,12 @@ type HTTPRequest struct {
 	ClientPort int    `gorm:"check:client_port >= 0 AND client_port <= 65535"`
 	ClientUser string `gorm:"type:varchar(255)"` // HTTP authentication user (NPM: remote_user)
 
+	// Set at ingestion when the client IP is inside INTERNAL_NETWORKS
+	IsInternal bool `gorm:"not null;default:false"`
+
+	// Requests this row stands for: N when its source keeps 1 in N requests, 1 otherwise
+	SampleWeight int `gorm:"not null;default:1"`
+
 	// Request info
 	Method        string `gorm:"type:varchar(10);not null"` // GET, POST, PUT, DELETE, etc.
 	Protocol      string `gorm:"type:varchar(10)"`          // HTTP/1.1, HTTP/2.0, HTTP/3.0
@@ -77,6 +83,9 @@ type HTTPRequest struct {
 	OSVersion      string `gorm:"type:varchar(20)"`
 	DeviceType     string `gorm:"type:varchar(20)"` // desktop, mobile, tablet, bot - index created by OptimizeDatabase
 
+	// User-defined classification (CLASSIFICATION_RULES)
+	Label string `gorm:"type:varchar(50)"` // First matching rule's label, empty if none - index created by OptimizeDatabase
+
 	// Proxy/Upstream info (proxy-agnostic naming)
 	// These fields work for Traefik, NPM, Caddy, HAProxy, etc.
 	BackendName         string `gorm:"type:varchar(255)"`                                    // Traefik: BackendName, NPM: proxy_upstream_name, Caddy: upstream_addr
@@ -84,6 +93,7 @@ type HTTPRequest struct {
 	RouterName          string `gorm:"type:varchar(255)"`                                    // Traefik: RouterName, NPM: server_name, Caddy: logger name - index created by OptimizeDatabase
 	UpstreamStatus      int    `gorm:"check:upstream_status >= 0 AND upstream_status < 600"` // Upstream/backend response status
 	UpstreamContentType string `gorm:"type:varchar(255)"`                                    // Origin/backend Content-Type (origin_Content-Type in Traefik)
+	OriginServer        string `gorm:"type:varchar(255)"`                                    // Server response header naming the origin software (e.g. Apache/2.4.57)
 	ClientHostname      string `gorm:"type:varchar(255)"`                                    // Client hostname (if reverse DNS available, from ClientHost)
 
 	// TLS info
@@ -92,8 +102,8 @@ type HTTPRequest struct {
 	TLSServerName string `gorm:"type:varchar(255)"` // SNI server name
 
 	// Tracing & IDs
-	RequestID string `gorm:"type:varchar(100)"` // X-Request-ID or similar - index created by OptimizeDatabase
-	TraceID   string `gorm:"type:varchar(100)"` // Distributed tracing ID (optional) - index created by OptimizeDatabase
+	RequestID string `gorm:"type:varchar(100)"` // X-Request-ID or similar - partial index idx_request_id_lookup
+	TraceID   string `gorm:"type:varchar(100)"` // Distributed tracing ID (optional) - partial index idx_trace_id_lookup
 
 	// GeoIP enrichment
 	GeoCountry string `gorm:"type:varchar(2)"` // ISO 3166-1 alpha-2 - index created by OptimizeDatabase
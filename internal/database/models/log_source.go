@@ -23,6 +23,8 @@
 package models
 
 import (
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -34,10 +36,45 @@ type LogSource struct {
     LastPosition    int64     `gorm:"default:0"`
     LastInode       int64     `gorm:"default:0"` // File inode for identity tracking (SQLite only supports int64)
     LastReadAt      *time.Time
+    RetentionDays   int       `gorm:"default:0"` // Days to keep this source's requests (0 = DB_RETENTION_DAYS)
+    Managed         bool      `gorm:"default:false"` // Declared in LOG_SOURCES_FILE; reconciled at startup, never replaced by discovery
+    DisableDedup    bool      `gorm:"default:false"` // Keep identical requests: the request hash includes the line offset
+    MultilinePattern string   // Regex matching the first line of an entry; other lines join the previous one (empty = one entry per line)
+    HashFields      string    // Request hash fields added or removed ("-name") on top of DEDUP_HASH_FIELDS, comma-separated
+    SampleRate      int       `gorm:"default:0"`     // Keep 1 in N requests, stored with sample_weight N (0 or 1 = keep all)
+    SampleKeepErrors bool     `gorm:"default:false"` // With SampleRate, keep every request with status 400 or above
+    Options         string    // JSON-encoded parser options from the sources file
     CreatedAt       time.Time
     UpdatedAt       time.Time
 }
 
 func (LogSource) TableName() string {
     return "log_sources"
-}
+}
+
+// IsGlob reports whether Path is a glob pattern; each matching file is then
+// tracked separately in log_source_files. URLs are never globs, "?" starts their query.
+func (s *LogSource) IsGlob() bool {
+    return !IsRemotePath(s.Path) && strings.ContainsAny(s.Path, "*?[")
+}
+
+// IsRemote reports whether Path is an http:// or https:// URL polled with range requests
+func (s *LogSource) IsRemote() bool {
+    return IsRemotePath(s.Path)
+}
+
+// IsRemotePath reports whether path is an http:// or https:// URL
+func IsRemotePath(path string) bool {
+    lower := strings.ToLower(path)
+    return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// HasURLCredentials reports whether path is a URL carrying a user name or password.
+// Source paths are logged and served by the API, so credentials are rejected there.
+func HasURLCredentials(path string) bool {
+    if !IsRemotePath(path) {
+        return false
+    }
+    u, err := url.Parse(path)
+    return err == nil && u.User != nil
+}
@@ -0,0 +1,42 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package models
+
+import (
+	"time"
+)
+
+// ParseStat holds per-source line counters for one time bucket
+// Counters are accumulated by the ingestion processors and flushed periodically
+type ParseStat struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	SourceName  string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_parse_stat_bucket,priority:1"`
+	BucketStart time.Time `gorm:"not null;uniqueIndex:idx_parse_stat_bucket,priority:2;index"`
+	Parsed      int64     `gorm:"not null;default:0"` // Lines parsed successfully
+	Failed      int64     `gorm:"not null;default:0"` // Lines the parser rejected
+	Skipped     int64     `gorm:"not null;default:0"` // Lines not recognized by the source's parser
+	Duplicates  int64     `gorm:"not null;default:0"` // Parsed lines already stored (deduplicated on insert)
+}
+
+func (ParseStat) TableName() string {
+	return "parse_stats"
+}
@@ -0,0 +1,115 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package models
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// Optional request hash fields. Every hash includes the timestamp to the second, the
+// client IP, method, host, path, query string and status code.
+const (
+	HashFieldDuration       = "duration"       // Nanosecond request duration
+	HashFieldStartUTC       = "start_utc"      // Nanosecond start time (Traefik)
+	HashFieldRequestsTotal  = "requests_total" // Router request counter (Traefik CLF)
+	HashFieldRequestID      = "request_id"     // X-Request-ID or similar
+	HashFieldTimestampNanos = "timestamp_ns"   // Sub-second part of the timestamp
+)
+
+// HashFields selects the optional fields included in the request hash
+type HashFields struct {
+	Duration       bool
+	StartUTC       bool
+	RequestsTotal  bool
+	RequestID      bool
+	TimestampNanos bool
+}
+
+// DefaultHashFields are the optional fields hashed when none are configured. Changing
+// them would change the hash of requests already stored, so re-read lines would no
+// longer be recognized as duplicates.
+var DefaultHashFields = HashFields{Duration: true, StartUTC: true, RequestsTotal: true}
+
+// With applies a comma-separated field list on top of f: a name adds the field and
+// "-name" removes it, e.g. "request_id,-start_utc". An empty spec returns f unchanged.
+func (f HashFields) With(spec string) (HashFields, error) {
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		include := !strings.HasPrefix(item, "-")
+		name := strings.TrimSpace(strings.TrimPrefix(item, "-"))
+
+		switch name {
+		case HashFieldDuration:
+			f.Duration = include
+		case HashFieldStartUTC:
+			f.StartUTC = include
+		case HashFieldRequestsTotal:
+			f.RequestsTotal = include
+		case HashFieldRequestID:
+			f.RequestID = include
+		case HashFieldTimestampNanos:
+			f.TimestampNanos = include
+		default:
+			return f, fmt.Errorf("unknown hash field %q (expected %s, %s, %s, %s or %s)", name,
+				HashFieldDuration, HashFieldStartUTC, HashFieldRequestsTotal, HashFieldRequestID, HashFieldTimestampNanos)
+		}
+	}
+	return f, nil
+}
+
+// ComputeRequestHash returns the deduplication hash of a request: two requests with the
+// same hash are stored once. With DefaultHashFields it matches the hash computed before
+// the fields were configurable. Fields a format does not log are empty or zero and
+// simply do not tell requests apart.
+func ComputeRequestHash(req *HTTPRequest, fields HashFields) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d|%s|%s|%s|%s|%s|%d",
+		req.Timestamp.Unix(),
+		req.ClientIP,
+		req.Method,
+		req.Host,
+		req.Path,
+		req.QueryString,
+		req.StatusCode,
+	)
+	if fields.Duration {
+		fmt.Fprintf(&b, "|%d", req.Duration)
+	}
+	if fields.StartUTC {
+		fmt.Fprintf(&b, "|%s", req.StartUTC)
+	}
+	if fields.RequestsTotal {
+		fmt.Fprintf(&b, "|%d", req.RequestsTotal)
+	}
+	if fields.RequestID {
+		fmt.Fprintf(&b, "|rid=%s", req.RequestID)
+	}
+	if fields.TimestampNanos {
+		fmt.Fprintf(&b, "|ns=%d", req.Timestamp.Nanosecond())
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(b.String())))
+}
@@ -33,23 +33,26 @@ import (
 // It reconciles expected performance indexes and verifies SQLite settings.
 func OptimizeDatabase(db *gorm.DB, logger *pterm.Logger) error {
     logger.Debug("Applying database optimizations...")
+    isSQLite := db.Dialector.Name() == DriverSQLite
 
-    // Verify WAL mode is enabled (debug level - only show if there's a problem)
-    var journalMode string
-    if err := db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error; err != nil {
-        logger.Warn("Failed to check journal mode", logger.Args("error", err))
-    } else if journalMode != "wal" {
-        logger.Warn("Database not in WAL mode", logger.Args("mode", journalMode))
-    } else {
-        logger.Trace("Database journal mode verified", logger.Args("mode", journalMode))
-    }
+    if isSQLite {
+        // Verify WAL mode is enabled (debug level - only show if there's a problem)
+        var journalMode string
+        if err := db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error; err != nil {
+            logger.Warn("Failed to check journal mode", logger.Args("error", err))
+        } else if journalMode != "wal" {
+            logger.Warn("Database not in WAL mode", logger.Args("mode", journalMode))
+        } else {
+            logger.Trace("Database journal mode verified", logger.Args("mode", journalMode))
+        }
 
-    // Verify page size (trace level - not critical)
-    var pageSize int
-    if err := db.Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
-        logger.Debug("Failed to check page size", logger.Args("error", err))
-    } else {
-        logger.Trace("Database page size", logger.Args("bytes", pageSize))
+        // Verify page size (trace level - not critical)
+        var pageSize int
+        if err := db.Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
+            logger.Debug("Failed to check page size", logger.Args("error", err))
+        } else {
+            logger.Trace("Database page size", logger.Args("bytes", pageSize))
+        }
     }
 
     created, dropped, err := indexes.Ensure(db, logger)
@@ -66,10 +69,12 @@ func OptimizeDatabase(db *gorm.DB, logger *pterm.Logger) error {
     }
 
     // Hint SQLite to optimize query plans using collected stats
-    if err := db.Exec("PRAGMA optimize").Error; err != nil {
-        logger.Debug("PRAGMA optimize failed", logger.Args("error", err))
-    } else {
-        logger.Trace("PRAGMA optimize executed")
+    if isSQLite {
+        if err := db.Exec("PRAGMA optimize").Error; err != nil {
+            logger.Debug("PRAGMA optimize failed", logger.Args("error", err))
+        } else {
+            logger.Trace("PRAGMA optimize executed")
+        }
     }
 
     logger.Debug("Database optimizations completed")
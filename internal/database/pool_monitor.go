@@ -212,7 +212,13 @@ func (pm *PoolMonitor) collectAndAnalyze() {
 
 // collectStats collects current pool statistics
 func (pm *PoolMonitor) collectStats() *PoolStats {
-	dbStats := pm.db.Stats()
+	return ReadPoolStats(pm.db, pm.threshold)
+}
+
+// ReadPoolStats reads the current pool statistics of db, flagging high utilization
+// at the given threshold. Used by the monitor and by metrics exporters.
+func ReadPoolStats(db *sql.DB, threshold float64) *PoolStats {
+	dbStats := db.Stats()
 
 	stats := &PoolStats{
 		MaxOpenConns:      dbStats.MaxOpenConnections,
@@ -240,8 +246,8 @@ func (pm *PoolMonitor) collectStats() *PoolStats {
 	}
 
 	// Set alert flags
-	stats.IsHighUtilization = stats.Utilization >= pm.threshold
-	stats.IsSaturated = stats.InUse >= stats.MaxOpenConns
+	stats.IsHighUtilization = stats.Utilization >= threshold
+	stats.IsSaturated = stats.MaxOpenConns > 0 && stats.InUse >= stats.MaxOpenConns // 0 = unlimited
 
 	return stats
 }
@@ -0,0 +1,205 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sqlDialect renders the SQL fragments that differ between SQLite and PostgreSQL.
+// Time buckets are UTC ISO-8601 strings on both, so callers and the frontend
+// see the same labels whatever the backend.
+type sqlDialect struct {
+	postgres bool
+	zone     *zoneShift // Computes buckets in a display timezone; nil keeps them in UTC
+}
+
+// zoneShift moves bucket expressions from UTC to the wall-clock time of a timezone
+type zoneShift struct {
+	name     string // IANA name for PostgreSQL's AT TIME ZONE, which handles DST itself
+	modifier string // SQLite strftime modifier adding each row's UTC offset
+}
+
+// dialectOf returns the dialect of db's connection
+func dialectOf(db *gorm.DB) sqlDialect {
+	return sqlDialect{postgres: db.Dialector.Name() == "postgres"}
+}
+
+// utcTimestamp is the timestamp column converted to UTC on PostgreSQL (timestamptz)
+const utcTimestamp = "(timestamp AT TIME ZONE 'UTC')"
+
+// inZone returns a copy of the dialect whose buckets are computed in loc's wall-clock
+// time for rows between since and until. A nil or UTC loc returns the dialect unchanged.
+// Labels then read as local times; localBucketLabel turns them back into UTC instants.
+func (d sqlDialect) inZone(loc *time.Location, since, until time.Time) sqlDialect {
+	if loc == nil || loc == time.UTC {
+		return d
+	}
+	d.zone = &zoneShift{
+		name:     strings.ReplaceAll(loc.String(), "'", "''"),
+		modifier: zoneOffsetModifier(loc, since, until),
+	}
+	return d
+}
+
+// localTimestamp is the timestamp column in the dialect's timezone on PostgreSQL
+func (d sqlDialect) localTimestamp() string {
+	if d.zone != nil {
+		return "(timestamp AT TIME ZONE '" + d.zone.name + "')"
+	}
+	return utcTimestamp
+}
+
+// sqliteTime applies a SQLite strftime layout to the timestamp column in the dialect's timezone
+func (d sqlDialect) sqliteTime(layout string) string {
+	if d.zone != nil {
+		return fmt.Sprintf("strftime('%s', timestamp, %s)", layout, d.zone.modifier)
+	}
+	return fmt.Sprintf("strftime('%s', timestamp)", layout)
+}
+
+// timeFormat formats the timestamp column in UTC, or in the dialect's timezone.
+// sqliteLayout uses strftime verbs, postgresLayout to_char patterns.
+func (d sqlDialect) timeFormat(sqliteLayout, postgresLayout string) string {
+	if d.postgres {
+		return fmt.Sprintf("to_char(%s, '%s')", d.localTimestamp(), postgresLayout)
+	}
+	return d.sqliteTime(sqliteLayout)
+}
+
+// minuteBucket groups timestamps by minute
+func (d sqlDialect) minuteBucket() string {
+	return d.timeFormat("%Y-%m-%dT%H:%M:00Z", `YYYY-MM-DD"T"HH24:MI:00"Z"`)
+}
+
+// hourBucket groups timestamps by hour
+func (d sqlDialect) hourBucket() string {
+	return d.timeFormat("%Y-%m-%dT%H:00:00Z", `YYYY-MM-DD"T"HH24:00:00"Z"`)
+}
+
+// sixHourBucket groups timestamps into 00:00, 06:00, 12:00 and 18:00 blocks
+func (d sqlDialect) sixHourBucket() string {
+	if d.postgres {
+		return d.timeFormat("", `YYYY-MM-DD"T"`) + " || lpad(((" + d.hourOfDay() + " / 6) * 6)::text, 2, '0') || ':00:00Z'"
+	}
+	return d.sqliteTime("%Y-%m-%dT") + " || printf('%02d', (" + d.hourOfDay() + " / 6) * 6) || ':00:00Z'"
+}
+
+// dayBucket groups timestamps by day
+func (d sqlDialect) dayBucket() string {
+	return d.timeFormat("%Y-%m-%dT00:00:00Z", `YYYY-MM-DD"T00:00:00Z"`)
+}
+
+// weekBucket groups timestamps by calendar week, e.g. "2025-W07"
+func (d sqlDialect) weekBucket() string {
+	return d.timeFormat("%Y-W%W", `IYYY-"W"IW`)
+}
+
+// monthBucket groups timestamps by month, e.g. "2025-02". SQLite reads the prefix
+// of the stored text, which the idx_month_timeline_weight expression index covers; in
+// another timezone the month has to be computed per row instead.
+func (d sqlDialect) monthBucket() string {
+	if d.postgres || d.zone != nil {
+		return d.timeFormat("", "YYYY-MM")
+	}
+	return "substr(timestamp, 1, 7)"
+}
+
+// dayOfWeek extracts the weekday as an integer, 0 = Sunday
+func (d sqlDialect) dayOfWeek() string {
+	if d.postgres {
+		return "CAST(EXTRACT(DOW FROM " + d.localTimestamp() + ") AS INTEGER)"
+	}
+	return "CAST(" + d.sqliteTime("%w") + " AS INTEGER)"
+}
+
+// hourOfDay extracts the hour (0-23) as an integer
+func (d sqlDialect) hourOfDay() string {
+	if d.postgres {
+		return "CAST(EXTRACT(HOUR FROM " + d.localTimestamp() + ") AS INTEGER)"
+	}
+	return "CAST(" + d.sqliteTime("%H") + " AS INTEGER)"
+}
+
+// instr is the name of the function returning the 1-based position of a
+// substring (0 when absent); both take (string, substring)
+func (d sqlDialect) instr() string {
+	if d.postgres {
+		return "STRPOS"
+	}
+	return "INSTR"
+}
+
+// zoneOffsetModifier returns a SQLite strftime modifier adding loc's UTC offset to the
+// timestamp column. The offset changes at DST transitions, so a window spanning some
+// gets a CASE over the transition instants (latest first); rows outside the window
+// use the offset in effect at its nearest edge.
+func zoneOffsetModifier(loc *time.Location, since, until time.Time) string {
+	_, offset := since.In(loc).Zone()
+	transitions := zoneTransitions(loc, since, until)
+	if len(transitions) == 0 {
+		return fmt.Sprintf("'%+d seconds'", offset)
+	}
+
+	var b strings.Builder
+	b.WriteString("CASE")
+	for i := len(transitions) - 1; i >= 0; i-- {
+		_, after := transitions[i].In(loc).Zone()
+		fmt.Fprintf(&b, " WHEN datetime(timestamp) >= '%s' THEN '%+d seconds'", transitions[i].UTC().Format(time.DateTime), after)
+	}
+	fmt.Fprintf(&b, " ELSE '%+d seconds' END", offset)
+	return b.String()
+}
+
+// zoneTransitions returns the instants in (since, until] at which loc's UTC offset changes.
+// It steps a day at a time and bisects each change down to the second.
+func zoneTransitions(loc *time.Location, since, until time.Time) []time.Time {
+	var transitions []time.Time
+	_, current := since.In(loc).Zone()
+	for lo := since; lo.Before(until); {
+		hi := lo.Add(24 * time.Hour)
+		if hi.After(until) {
+			hi = until
+		}
+		if _, offset := hi.In(loc).Zone(); offset != current {
+			for a, b := lo, hi; ; {
+				if b.Sub(a) <= time.Second {
+					transitions = append(transitions, b.Truncate(time.Second))
+					break
+				}
+				mid := a.Add(b.Sub(a) / 2)
+				if _, o := mid.In(loc).Zone(); o == current {
+					a = mid
+				} else {
+					b = mid
+				}
+			}
+			current = offset
+		}
+		lo = hi
+	}
+	return transitions
+}
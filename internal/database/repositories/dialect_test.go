@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteDialectBuckets(t *testing.T) {
+	db, _ := setupTestDB(t)
+	d := dialectOf(db)
+	assert.False(t, d.postgres)
+
+	// Stored with an offset: buckets are computed in UTC (14:25 UTC, a Monday)
+	ts := time.Date(2025, 2, 3, 16, 25, 40, 0, time.FixedZone("CEST", 2*3600))
+	assert.NoError(t, db.Create(&models.HTTPRequest{RequestHash: "dialect-1", ClientIP: "1.1.1.1", Timestamp: ts}).Error)
+
+	var row struct {
+		Minute    string
+		Hour      string
+		SixHour   string
+		Day       string
+		Week      string
+		DayOfWeek int
+		HourOfDay int
+	}
+	err := db.Model(&models.HTTPRequest{}).Select(
+		d.minuteBucket() + " as minute, " +
+			d.hourBucket() + " as hour, " +
+			d.sixHourBucket() + " as six_hour, " +
+			d.dayBucket() + " as day, " +
+			d.weekBucket() + " as week, " +
+			d.dayOfWeek() + " as day_of_week, " +
+			d.hourOfDay() + " as hour_of_day").Scan(&row).Error
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-02-03T14:25:00Z", row.Minute)
+	assert.Equal(t, "2025-02-03T14:00:00Z", row.Hour)
+	assert.Equal(t, "2025-02-03T12:00:00Z", row.SixHour)
+	assert.Equal(t, "2025-02-03T00:00:00Z", row.Day)
+	assert.Equal(t, "2025-W05", row.Week)
+	assert.Equal(t, 1, row.DayOfWeek)
+	assert.Equal(t, 14, row.HourOfDay)
+	assert.Equal(t, "INSTR", d.instr())
+}
+
+func TestPostgresDialectExpressions(t *testing.T) {
+	d := sqlDialect{postgres: true}
+
+	assert.Equal(t, `to_char((timestamp AT TIME ZONE 'UTC'), 'YYYY-MM-DD"T"HH24:00:00"Z"')`, d.hourBucket())
+	assert.Equal(t, `to_char((timestamp AT TIME ZONE 'UTC'), 'YYYY-MM')`, d.monthBucket())
+	assert.Equal(t, "CAST(EXTRACT(DOW FROM (timestamp AT TIME ZONE 'UTC')) AS INTEGER)", d.dayOfWeek())
+	assert.NotContains(t, d.sixHourBucket(), "strftime")
+	assert.Equal(t, "STRPOS", d.instr())
+}
@@ -22,11 +22,14 @@
 package repositories
 
 import (
+	"context"
 	"loglynx/internal/database/indexes"
 	"loglynx/internal/database/models"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pterm/pterm"
 	"gorm.io/gorm"
@@ -36,11 +39,19 @@ import (
 // HTTPRequestRepository handles CRUD operations for HTTP requests
 type HTTPRequestRepository interface {
 	Create(request *models.HTTPRequest) error
-	CreateBatch(requests []*models.HTTPRequest) error
+	// CreateBatch returns the number of rows inserted; duplicates are skipped
+	CreateBatch(requests []*models.HTTPRequest) (int, error)
 	FindByID(id uint) (*models.HTTPRequest, error)
-	FindAll(limit int, offset int, serviceName string, serviceType string, clientIPs []string, excludeServices []ServiceFilter) ([]*models.HTTPRequest, error)
+	FindAll(limit int, offset int, serviceName string, serviceType string, clientIPs []string, excludeServices []ServiceFilter, excludeInternal bool) ([]*models.HTTPRequest, error)
+	// ListRequests returns one page of matching requests, newest first, and the total number of matches
+	ListRequests(filter RequestListFilter) ([]*models.HTTPRequest, int64, error)
 	FindBySourceName(sourceName string, limit int) ([]*models.HTTPRequest, error)
+	// FindByRequestID and FindByTraceID return every row carrying the ID (retries share one), newest first
+	FindByRequestID(id string) ([]*models.HTTPRequest, error)
+	FindByTraceID(id string) ([]*models.HTTPRequest, error)
 	FindByTimeRange(start, end time.Time, limit int) ([]*models.HTTPRequest, error)
+	StreamByTimeRange(ctx context.Context, filter RequestExportFilter, fn func(*models.HTTPRequest) error) error
+	Search(query string, limit int) ([]*models.HTTPRequest, error)
 	Count() (int64, error)
 	CountBySourceName(sourceName string) (int64, error)
 	// First-load optimization control
@@ -51,6 +62,34 @@ type HTTPRequestRepository interface {
 	SetProcessorPauser(pauser ProcessorPauser)
 	// HasExistingData checks if database already has data (cached, efficient)
 	HasExistingData() bool
+	// Enable the FTS5 search index (built with the deferred indexes)
+	SetFullTextSearch(enabled bool)
+}
+
+// RequestExportFilter selects the rows streamed by StreamByTimeRange
+type RequestExportFilter struct {
+	Start       time.Time
+	End         time.Time
+	ServiceName string // Optional service filter, resolved like FindAll
+	ServiceType string
+	StatusMin   int // Inclusive lower bound on status_code (0 = none)
+	StatusMax   int // Inclusive upper bound on status_code (0 = none)
+	Limit       int // 0 = no limit
+}
+
+// RequestListFilter selects the rows paged through by ListRequests
+type RequestListFilter struct {
+	ServiceName     string // Optional service filter, resolved like FindAll
+	ServiceType     string
+	ClientIP        string    // Exact client IP ("" = any)
+	Method          string    // Exact HTTP method ("" = any)
+	StatusMin       int       // Inclusive lower bound on status_code (0 = none)
+	StatusMax       int       // Inclusive upper bound on status_code (0 = none)
+	Start           time.Time // Zero = unbounded
+	End             time.Time // Zero = unbounded
+	ExcludeInternal bool
+	Limit           int
+	Offset          int
 }
 
 // ProcessorPauser allows pausing/resuming processors during index creation
@@ -70,8 +109,20 @@ type httpRequestRepo struct {
 	indexCreationMu     sync.RWMutex
 	hasExistingData     *bool
 	hasExistingDataMu   sync.RWMutex
+	searchEnabled       bool // FTS5 index requested by configuration
+	searchAvailable     bool // FTS5 index built and queryable
+	searchMu            sync.RWMutex
+
+	// Rows per INSERT statement, derived from the driver's bind variable limit
+	maxRecordsPerStatement int
 }
 
+// Bind variable limits used to size INSERT statements
+const (
+	defaultSQLiteMaxVariables = 32766 // SQLITE_MAX_VARIABLE_NUMBER default since SQLite 3.32
+	postgresMaxParameters     = 65535 // Postgres wire protocol limit per statement
+)
+
 // NewHTTPRequestRepository creates a new HTTP request repository
 func NewHTTPRequestRepository(db *gorm.DB, logger *pterm.Logger) HTTPRequestRepository {
 	repo := &httpRequestRepo{
@@ -79,14 +130,69 @@ func NewHTTPRequestRepository(db *gorm.DB, logger *pterm.Logger) HTTPRequestRepo
 		logger:      logger,
 		isFirstLoad: false, // Will be checked on first CreateBatch call
 	}
+	maxVariables := detectMaxVariables(db)
+	repo.maxRecordsPerStatement = recordsPerStatement(maxVariables)
+	logger.Debug("Batch insert statement size",
+		logger.Args("max_variables", maxVariables, "columns", len(insertColumns), "records_per_statement", repo.maxRecordsPerStatement))
 	return repo
 }
 
+// detectMaxVariables returns the bind variable limit of the connected database.
+// SQLite only reports SQLITE_MAX_VARIABLE_NUMBER in compile_options when the build
+// overrides it, so the upstream default is assumed otherwise.
+func detectMaxVariables(db *gorm.DB) int {
+	if dialectOf(db).postgres {
+		return postgresMaxParameters
+	}
+
+	var options []string
+	if err := db.Raw("PRAGMA compile_options").Scan(&options).Error; err == nil {
+		for _, option := range options {
+			if value, ok := strings.CutPrefix(option, "MAX_VARIABLE_NUMBER="); ok {
+				if n, err := strconv.Atoi(value); err == nil && n > 0 {
+					return n
+				}
+			}
+		}
+	}
+	return defaultSQLiteMaxVariables
+}
+
+// recordsPerStatement returns how many rows of insertColumns fit under the variable limit
+func recordsPerStatement(maxVariables int) int {
+	return max(maxVariables/len(insertColumns), 1)
+}
+
 // SetProcessorPauser sets the processor pauser for coordinated pausing during index creation
 func (r *httpRequestRepo) SetProcessorPauser(pauser ProcessorPauser) {
 	r.processorPauser = pauser
 }
 
+// SetFullTextSearch enables or disables the FTS5 search index.
+// The index is created together with the performance indexes, so it never
+// slows down the first load; until then Search falls back to LIKE.
+func (r *httpRequestRepo) SetFullTextSearch(enabled bool) {
+	r.searchMu.Lock()
+	r.searchEnabled = enabled
+	r.searchMu.Unlock()
+}
+
+// ensureSearchIndex creates (or removes) the FTS5 index to match the configuration
+func (r *httpRequestRepo) ensureSearchIndex() {
+	r.searchMu.RLock()
+	enabled := r.searchEnabled
+	r.searchMu.RUnlock()
+
+	available, err := indexes.EnsureSearch(r.db, r.logger, enabled)
+	if err != nil {
+		r.logger.Error("Failed to prepare full-text search index", r.logger.Args("error", err))
+	}
+
+	r.searchMu.Lock()
+	r.searchAvailable = available
+	r.searchMu.Unlock()
+}
+
 // checkFirstLoad checks if database is empty (only once, at startup)
 // This is thread-safe and executes only on the first call
 func (r *httpRequestRepo) checkFirstLoad() {
@@ -117,6 +223,8 @@ func (r *httpRequestRepo) reconcileIndexesBackground() {
 		}
 		r.logger.Info("Database indexes reconciled",
 			r.logger.Args("created", created, "dropped", dropped))
+
+		r.ensureSearchIndex()
 	}()
 }
 
@@ -167,6 +275,9 @@ func (r *httpRequestRepo) HasExistingData() bool {
 	return result
 }
 
+// indexProgressLogInterval is how often a running deferred index creation is reported
+const indexProgressLogInterval = 30 * time.Second
+
 // createDeferredIndexes creates performance indexes after initial data load
 func (r *httpRequestRepo) createDeferredIndexes() {
 	// Pause all processors to prevent data loss during index creation
@@ -194,9 +305,27 @@ func (r *httpRequestRepo) createDeferredIndexes() {
 	}()
 
 	r.logger.Info("Creating performance indexes in background (this may take a few minutes)...")
+	r.logger.Warn("Database is busy until index creation finishes: filtered dashboard views may be slow or time out, real-time metrics stay live")
 
 	startTime := time.Now()
 
+	// Keep reporting while the database is busy so slow dashboards are explainable from the logs
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(indexProgressLogInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.logger.Info("Index creation still in progress, filtered views remain degraded",
+					r.logger.Args("elapsed", time.Since(startTime).Round(time.Second).String()))
+			case <-done:
+				return
+			}
+		}
+	}()
+
 	created, dropped, err := indexes.Ensure(r.db, r.logger)
 	if err != nil {
 		r.logger.Error("Failed to create performance indexes",
@@ -207,6 +336,8 @@ func (r *httpRequestRepo) createDeferredIndexes() {
 	elapsed := time.Since(startTime)
 	r.logger.Info("Performance indexes created successfully",
 		r.logger.Args("elapsed_seconds", elapsed.Seconds(), "created", created, "dropped", dropped))
+
+	r.ensureSearchIndex()
 }
 
 // getFirstLoadStatus returns current first-load status (thread-safe)
@@ -227,62 +358,77 @@ func (r *httpRequestRepo) Create(request *models.HTTPRequest) error {
 }
 
 // CreateBatch inserts multiple HTTP requests in a single transaction
-// OPTIMIZED: Automatically splits large batches to avoid SQLite variable limit (32766)
+// OPTIMIZED: Batches larger than one statement allows (bind variable limit / column count,
+// ~630 rows on SQLite) are written as raw multi-row INSERTs within one transaction
 // OPTIMIZED: Skips deduplication checks on first load (when database is empty)
-func (r *httpRequestRepo) CreateBatch(requests []*models.HTTPRequest) error {
+// Returns the number of rows actually inserted (duplicates are skipped)
+func (r *httpRequestRepo) CreateBatch(requests []*models.HTTPRequest) (int, error) {
 	if len(requests) == 0 {
 		r.logger.Debug("Empty batch, skipping insert")
-		return nil
+		return 0, nil
 	}
 
 	// Check first-load status (thread-safe, happens only once globally)
 	r.checkFirstLoad()
 	isFirstLoad := r.getFirstLoadStatus()
 
-	// SQLite has a variable limit (default 32766 for older versions, 999 in some configs)
-	// HTTPRequest has 49 columns (including requests_total field), so max safe batch size is ~668 records
-	// OPTIMIZATION: Increased from 15 to 500+ for significantly better throughput
-	// 500 records * 49 columns = 24,500 variables (well under 32,766 limit)
-	const MaxRecordsPerBatch = 50 // Slight safety margin under theoretical limit
-
-	// If batch is small enough, insert directly
-	if len(requests) <= MaxRecordsPerBatch {
+	// If batch fits one statement, insert directly
+	perStatement := r.maxRecordsPerStatement
+	if len(requests) <= perStatement {
 		return r.insertSubBatch(requests, isFirstLoad)
 	}
 
-	// Split large batches into smaller chunks
+	uniqueRequests := uniqueByHash(requests, r.logger)
+	if len(uniqueRequests) == 0 {
+		return 0, nil
+	}
+
+	// Split large batches into raw multi-row INSERTs sharing one transaction
 	r.logger.Debug("Splitting large batch to avoid variable limit",
-		r.logger.Args("total_records", len(requests), "max_per_batch", MaxRecordsPerBatch))
+		r.logger.Args("total_records", len(uniqueRequests), "max_per_statement", perStatement))
+
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		r.logger.WithCaller().Error("Failed to begin transaction", r.logger.Args("error", tx.Error))
+		return 0, tx.Error
+	}
 
 	totalInserted := 0
-	for i := 0; i < len(requests); i += MaxRecordsPerBatch {
-		end := i + MaxRecordsPerBatch
-		if end > len(requests) {
-			end = len(requests)
-		}
+	for i := 0; i < len(uniqueRequests); i += perStatement {
+		end := min(i+perStatement, len(uniqueRequests))
 
-		subBatch := requests[i:end]
-		if err := r.insertSubBatch(subBatch, isFirstLoad); err != nil {
+		subBatch := uniqueRequests[i:end]
+		inserted, err := insertSubBatchRaw(tx, subBatch)
+		if err != nil {
+			tx.Rollback()
 			r.logger.WithCaller().Error("Failed to insert sub-batch",
-				r.logger.Args("batch_num", (i/MaxRecordsPerBatch)+1, "count", len(subBatch), "error", err))
-			return err
+				r.logger.Args("batch_num", (i/perStatement)+1, "count", len(subBatch), "error", err))
+			return 0, err
 		}
 
-		totalInserted += len(subBatch)
+		totalInserted += inserted
 		r.logger.Trace("Inserted sub-batch",
-			r.logger.Args("progress", totalInserted, "total", len(requests)))
+			r.logger.Args("progress", end, "total", len(uniqueRequests)))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		r.logger.WithCaller().Error("Failed to commit transaction", r.logger.Args("error", err))
+		return 0, err
 	}
 
+	if duplicates := len(uniqueRequests) - totalInserted; duplicates > 0 {
+		r.logger.Debug("Skipped duplicate entries",
+			r.logger.Args("batch_size", len(uniqueRequests), "inserted", totalInserted, "duplicates", duplicates))
+	}
 	r.logger.Debug("Successfully inserted large batch in chunks",
-		r.logger.Args("total_records", len(requests), "source", requests[0].SourceName))
+		r.logger.Args("total_records", len(requests), "inserted", totalInserted, "source", requests[0].SourceName))
 
-	return nil
+	return totalInserted, nil
 }
 
-// insertSubBatch performs the actual batch insert within SQLite variable limits
-func (r *httpRequestRepo) insertSubBatch(requests []*models.HTTPRequest, isFirstLoad bool) error {
-	// OPTIMIZATION: Deduplicate in-memory BEFORE inserting to avoid rollbacks
-	// This prevents expensive transaction rollbacks and re-inserts
+// uniqueByHash drops requests whose hash already appeared earlier in the batch, so
+// the insert does not depend on the database rejecting them
+func uniqueByHash(requests []*models.HTTPRequest, logger *pterm.Logger) []*models.HTTPRequest {
 	uniqueRequests := make([]*models.HTTPRequest, 0, len(requests))
 	seen := make(map[string]bool, len(requests))
 	inBatchDuplicates := 0
@@ -304,22 +450,31 @@ func (r *httpRequestRepo) insertSubBatch(requests []*models.HTTPRequest, isFirst
 	}
 
 	if inBatchDuplicates > 0 {
-		r.logger.Debug("Removed in-batch duplicates before insert",
-			r.logger.Args("original", len(requests), "unique", len(uniqueRequests), "duplicates", inBatchDuplicates))
+		logger.Debug("Removed in-batch duplicates before insert",
+			logger.Args("original", len(requests), "unique", len(uniqueRequests), "duplicates", inBatchDuplicates))
 	}
+	return uniqueRequests
+}
+
+// insertSubBatch performs the actual batch insert within SQLite variable limits
+// Returns the number of rows inserted
+func (r *httpRequestRepo) insertSubBatch(requests []*models.HTTPRequest, isFirstLoad bool) (int, error) {
+	// OPTIMIZATION: Deduplicate in-memory BEFORE inserting to avoid rollbacks
+	// This prevents expensive transaction rollbacks and re-inserts
+	uniqueRequests := uniqueByHash(requests, r.logger)
 
 	// If all were duplicates, skip the insert entirely
 	if len(uniqueRequests) == 0 {
 		r.logger.Debug("All records in batch were duplicates, skipping insert")
-		return nil
+		return 0, nil
 	}
 
 	if isFirstLoad {
-		inserted, err := r.insertSubBatchRaw(uniqueRequests)
+		inserted, err := insertSubBatchRaw(r.db, uniqueRequests)
 		if err != nil {
 			r.logger.WithCaller().Error("Failed to insert batch via raw SQL",
 				r.logger.Args("count", len(uniqueRequests), "error", err))
-			return err
+			return 0, err
 		}
 
 		duplicates := len(uniqueRequests) - inserted
@@ -327,14 +482,14 @@ func (r *httpRequestRepo) insertSubBatch(requests []*models.HTTPRequest, isFirst
 			r.logger.Debug("Initial load raw insert skipped duplicates",
 				r.logger.Args("batch_size", len(uniqueRequests), "inserted", inserted, "duplicates", duplicates))
 		}
-		return nil
+		return inserted, nil
 	}
 
 	// Start transaction
 	tx := r.db.Begin()
 	if tx.Error != nil {
 		r.logger.WithCaller().Error("Failed to begin transaction", r.logger.Args("error", tx.Error))
-		return tx.Error
+		return 0, tx.Error
 	}
 
 	// Use INSERT OR IGNORE semantics to skip duplicates without per-row retries
@@ -346,12 +501,12 @@ func (r *httpRequestRepo) insertSubBatch(requests []*models.HTTPRequest, isFirst
 		tx.Rollback()
 		r.logger.WithCaller().Error("Failed to insert batch",
 			r.logger.Args("count", len(uniqueRequests), "error", result.Error))
-		return result.Error
+		return 0, result.Error
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		r.logger.WithCaller().Error("Failed to commit transaction", r.logger.Args("error", err))
-		return err
+		return 0, err
 	}
 
 	inserted := int(result.RowsAffected)
@@ -370,62 +525,71 @@ func (r *httpRequestRepo) insertSubBatch(requests []*models.HTTPRequest, isFirst
 			))
 	}
 
-	return nil
+	return inserted, nil
 }
 
-// insertSubBatchRaw performs a high-throughput INSERT for initial load using raw SQL
-func (r *httpRequestRepo) insertSubBatchRaw(requests []*models.HTTPRequest) (int, error) {
-	columns := []string{
-		"source_name",
-		"timestamp",
-		"request_hash",
-		"partition_key",
-		"client_ip",
-		"client_port",
-		"client_user",
-		"method",
-		"protocol",
-		"host",
-		"path",
-		"query_string",
-		"request_length",
-		"request_scheme",
-		"status_code",
-		"response_size",
-		"response_time_ms",
-		"response_content_type",
-		"duration",
-		"start_utc",
-		"upstream_response_time_ms",
-		"retry_attempts",
-		"requests_total",
-		"user_agent",
-		"referer",
-		"browser",
-		"browser_version",
-		"os",
-		"os_version",
-		"device_type",
-		"backend_name",
-		"backend_url",
-		"router_name",
-		"upstream_status",
-		"upstream_content_type",
-		"client_hostname",
-		"tls_version",
-		"tls_cipher",
-		"tls_server_name",
-		"request_id",
-		"trace_id",
-		"geo_country",
-		"geo_city",
-		"geo_lat",
-		"geo_lon",
-		"asn",
-		"asn_org",
-		"proxy_metadata",
-		"created_at",
-	}
+// insertColumns are the http_requests columns written by insertSubBatchRaw; their count
+// also sizes the gorm inserts, which write the same fields
+var insertColumns = []string{
+	"source_name",
+	"timestamp",
+	"request_hash",
+	"partition_key",
+	"client_ip",
+	"client_port",
+	"client_user",
+	"is_internal",
+	"sample_weight",
+	"method",
+	"protocol",
+	"host",
+	"path",
+	"query_string",
+	"request_length",
+	"request_scheme",
+	"status_code",
+	"response_size",
+	"response_time_ms",
+	"response_content_type",
+	"duration",
+	"start_utc",
+	"upstream_response_time_ms",
+	"retry_attempts",
+	"requests_total",
+	"user_agent",
+	"referer",
+	"browser",
+	"browser_version",
+	"os",
+	"os_version",
+	"device_type",
+	"label",
+	"backend_name",
+	"backend_url",
+	"router_name",
+	"upstream_status",
+	"upstream_content_type",
+	"origin_server",
+	"client_hostname",
+	"tls_version",
+	"tls_cipher",
+	"tls_server_name",
+	"request_id",
+	"trace_id",
+	"geo_country",
+	"geo_city",
+	"geo_lat",
+	"geo_lon",
+	"asn",
+	"asn_org",
+	"proxy_metadata",
+	"created_at",
+}
+
+// insertSubBatchRaw performs a high-throughput multi-row INSERT using raw SQL, used for
+// the initial load and for batches spanning several statements. Rows must fit one statement.
+func insertSubBatchRaw(db *gorm.DB, requests []*models.HTTPRequest) (int, error) {
+	columns := insertColumns
 
 	placeholder := "(" + strings.TrimRight(strings.Repeat("?,", len(columns)), ",") + ")"
 	var queryBuilder strings.Builder
@@ -434,7 +598,7 @@ func (r *httpRequestRepo) insertSubBatchRaw(requests []*models.HTTPRequest) (int
 	queryBuilder.WriteString(") VALUES ")
 
 	args := make([]interface{}, 0, len(columns)*len(requests))
-	now := time.Now()
+	now := time.Now().UTC()
 	for i, req := range requests {
 		if i > 0 {
 			queryBuilder.WriteString(",")
@@ -450,6 +614,9 @@ func (r *httpRequestRepo) insertSubBatchRaw(requests []*models.HTTPRequest) (int
 		if req.CreatedAt.IsZero() {
 			req.CreatedAt = now
 		}
+		if req.SampleWeight < 1 {
+			req.SampleWeight = 1
+		}
 
 		args = append(args,
 			req.SourceName,
@@ -459,6 +626,8 @@ func (r *httpRequestRepo) insertSubBatchRaw(requests []*models.HTTPRequest) (int
 			req.ClientIP,
 			req.ClientPort,
 			req.ClientUser,
+			req.IsInternal,
+			req.SampleWeight,
 			req.Method,
 			req.Protocol,
 			req.Host,
@@ -482,11 +651,13 @@ func (r *httpRequestRepo) insertSubBatchRaw(requests []*models.HTTPRequest) (int
 			req.OS,
 			req.OSVersion,
 			req.DeviceType,
+			req.Label,
 			req.BackendName,
 			req.BackendURL,
 			req.RouterName,
 			req.UpstreamStatus,
 			req.UpstreamContentType,
+			req.OriginServer,
 			req.ClientHostname,
 			req.TLSVersion,
 			req.TLSCipher,
@@ -506,7 +677,7 @@ func (r *httpRequestRepo) insertSubBatchRaw(requests []*models.HTTPRequest) (int
 
 	queryBuilder.WriteString(" ON CONFLICT(request_hash) DO NOTHING")
 
-	result := r.db.Exec(queryBuilder.String(), args...)
+	result := db.Exec(queryBuilder.String(), args...)
 	if result.Error != nil {
 		return 0, result.Error
 	}
@@ -528,7 +699,7 @@ func (r *httpRequestRepo) FindByID(id uint) (*models.HTTPRequest, error) {
 }
 
 // FindAll retrieves all HTTP requests with pagination
-func (r *httpRequestRepo) FindAll(limit int, offset int, serviceName string, serviceType string, clientIPs []string, excludeServices []ServiceFilter) ([]*models.HTTPRequest, error) {
+func (r *httpRequestRepo) FindAll(limit int, offset int, serviceName string, serviceType string, clientIPs []string, excludeServices []ServiceFilter, excludeInternal bool) ([]*models.HTTPRequest, error) {
 	var requests []*models.HTTPRequest
 	query := r.db.Order("timestamp DESC")
 
@@ -563,6 +734,10 @@ func (r *httpRequestRepo) FindAll(limit int, offset int, serviceName string, ser
 		}
 	}
 
+	if excludeInternal {
+		query = query.Where("is_internal = ?", false)
+	}
+
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
@@ -579,6 +754,57 @@ func (r *httpRequestRepo) FindAll(limit int, offset int, serviceName string, ser
 	return requests, nil
 }
 
+// ListRequests retrieves a page of HTTP requests matching the filter, newest first,
+// along with the number of rows matching it across all pages
+func (r *httpRequestRepo) ListRequests(filter RequestListFilter) ([]*models.HTTPRequest, int64, error) {
+	query := r.db.Model(&models.HTTPRequest{})
+	query = r.applyServiceFilter(query, filter.ServiceName, filter.ServiceType)
+
+	if filter.ClientIP != "" {
+		query = query.Where("client_ip = ?", filter.ClientIP)
+	}
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if filter.StatusMin > 0 {
+		query = query.Where("status_code >= ?", filter.StatusMin)
+	}
+	if filter.StatusMax > 0 {
+		query = query.Where("status_code <= ?", filter.StatusMax)
+	}
+	if !filter.Start.IsZero() {
+		query = query.Where("timestamp >= ?", filter.Start.UTC())
+	}
+	if !filter.End.IsZero() {
+		query = query.Where("timestamp <= ?", filter.End.UTC())
+	}
+	if filter.ExcludeInternal {
+		query = query.Where("is_internal = ?", false)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.WithCaller().Error("Failed to count listed HTTP requests", r.logger.Args("error", err))
+		return nil, 0, err
+	}
+
+	requests := []*models.HTTPRequest{}
+	if total > int64(filter.Offset) {
+		page := query.Order("timestamp DESC").Offset(filter.Offset)
+		if filter.Limit > 0 {
+			page = page.Limit(filter.Limit)
+		}
+		if err := page.Find(&requests).Error; err != nil {
+			r.logger.WithCaller().Error("Failed to list HTTP requests", r.logger.Args("error", err))
+			return nil, 0, err
+		}
+	}
+
+	r.logger.Trace("Listed HTTP requests",
+		r.logger.Args("count", len(requests), "total", total, "limit", filter.Limit, "offset", filter.Offset))
+	return requests, total, nil
+}
+
 // applyServiceFilter applies service filter based on service name and type
 func (r *httpRequestRepo) applyServiceFilter(query *gorm.DB, serviceName string, serviceType string) *gorm.DB {
 	if serviceName == "" {
@@ -623,10 +849,43 @@ func (r *httpRequestRepo) FindBySourceName(sourceName string, limit int) ([]*mod
 	return requests, nil
 }
 
+// maxCorrelatedRequests bounds the rows returned for a single request or trace ID
+const maxCorrelatedRequests = 100
+
+// FindByRequestID retrieves the requests logged with the given X-Request-ID
+func (r *httpRequestRepo) FindByRequestID(id string) ([]*models.HTTPRequest, error) {
+	return r.findByCorrelationID("request_id", id)
+}
+
+// FindByTraceID retrieves the requests logged with the given distributed trace ID
+func (r *httpRequestRepo) FindByTraceID(id string) ([]*models.HTTPRequest, error) {
+	return r.findByCorrelationID("trace_id", id)
+}
+
+// findByCorrelationID looks up rows by request_id or trace_id using the partial lookup indexes
+func (r *httpRequestRepo) findByCorrelationID(column string, id string) ([]*models.HTTPRequest, error) {
+	var requests []*models.HTTPRequest
+	if id == "" {
+		return requests, nil
+	}
+
+	err := r.db.Where(column+" = ? AND "+column+" != ''", id).
+		Order("timestamp DESC").
+		Limit(maxCorrelatedRequests).
+		Find(&requests).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to find HTTP requests by correlation ID",
+			r.logger.Args("column", column, "id", id, "error", err))
+		return nil, err
+	}
+
+	return requests, nil
+}
+
 // FindByTimeRange retrieves HTTP requests within a time range
 func (r *httpRequestRepo) FindByTimeRange(start, end time.Time, limit int) ([]*models.HTTPRequest, error) {
 	var requests []*models.HTTPRequest
-	query := r.db.Where("timestamp BETWEEN ? AND ?", start, end).Order("timestamp DESC")
+	query := r.db.Where("timestamp BETWEEN ? AND ?", start.UTC(), end.UTC()).Order("timestamp DESC")
 
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -643,6 +902,52 @@ func (r *httpRequestRepo) FindByTimeRange(start, end time.Time, limit int) ([]*m
 	return requests, nil
 }
 
+// StreamByTimeRange calls fn for every request matching the filter, oldest first.
+// Rows are scanned one at a time so large exports never hold the full result set in memory.
+// Iteration stops at the first error returned by fn, or when ctx is cancelled.
+func (r *httpRequestRepo) StreamByTimeRange(ctx context.Context, filter RequestExportFilter, fn func(*models.HTTPRequest) error) error {
+	query := r.db.WithContext(ctx).Model(&models.HTTPRequest{}).
+		Where("timestamp BETWEEN ? AND ?", filter.Start.UTC(), filter.End.UTC()).
+		Order("timestamp ASC")
+
+	query = r.applyServiceFilter(query, filter.ServiceName, filter.ServiceType)
+
+	if filter.StatusMin > 0 {
+		query = query.Where("status_code >= ?", filter.StatusMin)
+	}
+	if filter.StatusMax > 0 {
+		query = query.Where("status_code <= ?", filter.StatusMax)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to stream HTTP requests",
+			r.logger.Args("start", filter.Start, "end", filter.End, "error", err))
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var request models.HTTPRequest
+		if err := r.db.ScanRows(rows, &request); err != nil {
+			r.logger.WithCaller().Error("Failed to scan streamed HTTP request", r.logger.Args("error", err))
+			return err
+		}
+		if err := fn(&request); err != nil {
+			return err
+		}
+		count++
+	}
+
+	r.logger.Trace("Streamed HTTP requests by time range",
+		r.logger.Args("count", count, "start", filter.Start, "end", filter.End))
+	return rows.Err()
+}
+
 // Count returns the total number of HTTP requests
 func (r *httpRequestRepo) Count() (int64, error) {
 	var count int64
@@ -665,3 +970,43 @@ func (r *httpRequestRepo) CountBySourceName(sourceName string) (int64, error) {
 	}
 	return count, nil
 }
+
+// Search returns requests whose path or user agent contains query, newest first.
+// Uses the FTS5 trigram index when available (queries of 3+ characters);
+// otherwise falls back to a LIKE scan over the whole table.
+func (r *httpRequestRepo) Search(query string, limit int) ([]*models.HTTPRequest, error) {
+	var requests []*models.HTTPRequest
+
+	r.searchMu.RLock()
+	useIndex := r.searchAvailable && utf8.RuneCountInString(query) >= 3
+	r.searchMu.RUnlock()
+
+	var err error
+	if useIndex {
+		// Quote as a single phrase so user input is never parsed as FTS5 query syntax
+		phrase := `"` + strings.ReplaceAll(query, `"`, `""`) + `"`
+		err = r.db.Raw(`
+			SELECT http_requests.*
+			FROM `+indexes.SearchTable+`
+			JOIN http_requests ON http_requests.id = `+indexes.SearchTable+`.rowid
+			WHERE `+indexes.SearchTable+` MATCH ?
+			ORDER BY http_requests.timestamp DESC
+			LIMIT ?
+		`, phrase, limit).Scan(&requests).Error
+	} else {
+		pattern := "%" + likeEscaper.Replace(query) + "%"
+		err = r.db.Where(`path LIKE ? ESCAPE '\' OR user_agent LIKE ? ESCAPE '\'`, pattern, pattern).
+			Order("timestamp DESC").
+			Limit(limit).
+			Find(&requests).Error
+	}
+
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to search HTTP requests", r.logger.Args("query", query, "error", err))
+		return nil, err
+	}
+	return requests, nil
+}
+
+// likeEscaper escapes LIKE wildcards so the query matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRecordsPerStatement(t *testing.T) {
+	assert.Equal(t, defaultSQLiteMaxVariables/len(insertColumns), recordsPerStatement(defaultSQLiteMaxVariables))
+	assert.Greater(t, recordsPerStatement(defaultSQLiteMaxVariables), 500)
+	assert.Equal(t, 18, recordsPerStatement(999))
+	assert.Equal(t, 1, recordsPerStatement(10), "at least one row per statement")
+
+	db, _ := setupTestDB(t)
+	assert.Greater(t, detectMaxVariables(db), len(insertColumns))
+}
+
+func TestCreateBatchSpansStatements(t *testing.T) {
+	db, _ := setupTestDB(t)
+	log := pterm.DefaultLogger
+	repo := NewHTTPRequestRepository(db, &log).(*httpRequestRepo)
+	repo.maxRecordsPerStatement = 7
+
+	assert.NoError(t, db.Create(&models.HTTPRequest{RequestHash: "batch-0", SourceName: "main", Timestamp: time.Now()}).Error)
+
+	requests := benchmarkRequests(40, "batch")
+	requests = append(requests, &models.HTTPRequest{RequestHash: "batch-5", SourceName: "main", Timestamp: time.Now()})
+
+	inserted, err := repo.CreateBatch(requests)
+	assert.NoError(t, err)
+	assert.Equal(t, 39, inserted, "stored and in-batch duplicates are skipped")
+
+	var count int64
+	assert.NoError(t, db.Model(&models.HTTPRequest{}).Count(&count).Error)
+	assert.Equal(t, int64(40), count)
+}
+
+// BenchmarkCreateBatchFirstLoad compares the former fixed 50-row statements, each
+// committed on its own, with CreateBatch at 50 rows and at the size derived from the
+// bind variable limit, on a file database as in production
+func BenchmarkCreateBatchFirstLoad(b *testing.B) {
+	for _, variant := range []struct {
+		name         string
+		perStatement int // 0 = derived from the variable limit
+		legacy       bool
+	}{
+		{name: "legacy-50-per-commit", perStatement: 50, legacy: true},
+		{name: "50-per-statement", perStatement: 50},
+		{name: "auto-per-statement"},
+	} {
+		b.Run(variant.name, func(b *testing.B) {
+			requests := benchmarkRequests(5000, "bench")
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				db, err := gorm.Open(sqlite.Open(filepath.Join(b.TempDir(), "bench.db")), &gorm.Config{Logger: logger.Discard})
+				if err != nil {
+					b.Fatalf("failed to open database: %v", err)
+				}
+				if err := db.AutoMigrate(&models.HTTPRequest{}); err != nil {
+					b.Fatalf("failed to migrate database: %v", err)
+				}
+				log := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+				repo := NewHTTPRequestRepository(db, log).(*httpRequestRepo)
+				if variant.perStatement > 0 {
+					repo.maxRecordsPerStatement = variant.perStatement
+				}
+				b.StartTimer()
+
+				// Batches of 1000 lines, the default ingestion batch size
+				for start := 0; start < len(requests); start += 1000 {
+					batch := requests[start : start+1000]
+					if variant.legacy {
+						for i := 0; i < len(batch); i += variant.perStatement {
+							if _, err := repo.insertSubBatch(batch[i:i+variant.perStatement], true); err != nil {
+								b.Fatalf("insert failed: %v", err)
+							}
+						}
+						continue
+					}
+					if _, err := repo.CreateBatch(batch); err != nil {
+						b.Fatalf("insert failed: %v", err)
+					}
+				}
+
+				b.StopTimer()
+				if sqlDB, err := db.DB(); err == nil {
+					sqlDB.Close()
+				}
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(len(requests)*b.N)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
+
+func benchmarkRequests(n int, prefix string) []*models.HTTPRequest {
+	now := time.Now()
+	requests := make([]*models.HTTPRequest, n)
+	for i := range requests {
+		requests[i] = &models.HTTPRequest{
+			RequestHash:  fmt.Sprintf("%s-%d", prefix, i),
+			SourceName:   "main",
+			Timestamp:    now.Add(-time.Duration(i) * time.Second),
+			ClientIP:     fmt.Sprintf("10.0.%d.%d", i/256%256, i%256),
+			Method:       "GET",
+			Host:         "example.com",
+			Path:         fmt.Sprintf("/page/%d", i%100),
+			StatusCode:   200,
+			ResponseSize: 1024,
+			UserAgent:    "Mozilla/5.0",
+		}
+	}
+	return requests
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListRequests(t *testing.T) {
+	db, _ := setupTestDB(t)
+	logger := pterm.DefaultLogger
+	repo := NewHTTPRequestRepository(db, &logger)
+	now := time.Now()
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "list-1", ClientIP: "1.1.1.1", Timestamp: now.Add(-3 * time.Hour), Method: "GET", StatusCode: 200, Path: "/"},
+		{RequestHash: "list-2", ClientIP: "1.1.1.1", Timestamp: now.Add(-2 * time.Hour), Method: "POST", StatusCode: 404, Path: "/login"},
+		{RequestHash: "list-3", ClientIP: "2.2.2.2", Timestamp: now.Add(-time.Hour), Method: "POST", StatusCode: 403, Path: "/login"},
+		{RequestHash: "list-4", ClientIP: "2.2.2.2", Timestamp: now, Method: "GET", StatusCode: 500, Path: "/api"},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	page, total, err := repo.ListRequests(RequestListFilter{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, "list-4", page[0].RequestHash, "newest first")
+	}
+
+	page, total, err = repo.ListRequests(RequestListFilter{Limit: 2, Offset: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, "list-1", page[1].RequestHash)
+	}
+
+	page, total, err = repo.ListRequests(RequestListFilter{Method: "POST", StatusMin: 400, StatusMax: 499, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, page, 2)
+
+	page, total, err = repo.ListRequests(RequestListFilter{ClientIP: "2.2.2.2", Start: now.Add(-90 * time.Minute), End: now.Add(-30 * time.Minute), Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, "list-3", page[0].RequestHash)
+	}
+
+	page, total, err = repo.ListRequests(RequestListFilter{Limit: 10, Offset: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total, "total is reported past the last page")
+	assert.Empty(t, page)
+}
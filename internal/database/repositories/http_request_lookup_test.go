@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindByRequestAndTraceID(t *testing.T) {
+	db, _ := setupTestDB(t)
+	logger := pterm.DefaultLogger
+	repo := NewHTTPRequestRepository(db, &logger)
+	now := time.Now()
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "rid-1", ClientIP: "1.1.1.1", Timestamp: now.Add(-time.Minute), RequestID: "req-abc", TraceID: "trace-1", Path: "/api/orders"},
+		// Retry of the same request
+		{RequestHash: "rid-2", ClientIP: "1.1.1.1", Timestamp: now, RequestID: "req-abc", TraceID: "trace-1", Path: "/api/orders", RetryAttempts: 1},
+		{RequestHash: "rid-3", ClientIP: "2.2.2.2", Timestamp: now, RequestID: "req-def", TraceID: "trace-1", Path: "/api/payments"},
+		{RequestHash: "rid-4", ClientIP: "3.3.3.3", Timestamp: now, Path: "/"},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	found, err := repo.FindByRequestID("req-abc")
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+	assert.Equal(t, "rid-2", found[0].RequestHash, "newest first")
+
+	found, err = repo.FindByTraceID("trace-1")
+	assert.NoError(t, err)
+	assert.Len(t, found, 3)
+
+	found, err = repo.FindByRequestID("missing")
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	found, err = repo.FindByRequestID("")
+	assert.NoError(t, err)
+	assert.Empty(t, found, "rows without a request ID never match")
+}
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LogSourceRepository interface {
@@ -35,6 +36,12 @@ type LogSourceRepository interface {
 	FindAll() ([]*models.LogSource, error)
 	Update(source *models.LogSource) error
 	UpdateTracking(name string, position int64, inode int64, lastLine string) error
+	UpdateRetention(name string, retentionDays int) error
+	Delete(name string) error
+	// FindFiles returns the tracked files of a glob source
+	FindFiles(sourceName string) ([]*models.LogSourceFile, error)
+	// UpdateFileTracking stores the position of one file of a glob source, creating its row on first use
+	UpdateFileTracking(sourceName string, path string, position int64, inode int64, lastLine string) error
 }
 
 type logSourceRepo struct {
@@ -75,4 +82,41 @@ func (r *logSourceRepo) UpdateTracking(name string, position int64, inode int64,
 		position, inode, lastLine, time.Now(), time.Now(), name,
 	).Error
 }
+
+// UpdateRetention sets how many days a source's requests are kept (0 = global retention)
+func (r *logSourceRepo) UpdateRetention(name string, retentionDays int) error {
+	return r.db.Model(&models.LogSource{}).Where("name = ?", name).Update("retention_days", retentionDays).Error
+}
+
+// Delete removes a source and its file tracking; its already ingested requests are kept
+func (r *logSourceRepo) Delete(name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_name = ?", name).Delete(&models.LogSourceFile{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("name = ?", name).Delete(&models.LogSource{}).Error
+	})
+}
+
+func (r *logSourceRepo) FindFiles(sourceName string) ([]*models.LogSourceFile, error) {
+	var files []*models.LogSourceFile
+	err := r.db.Where("source_name = ?", sourceName).Order("path ASC").Find(&files).Error
+	return files, err
+}
+
+func (r *logSourceRepo) UpdateFileTracking(sourceName string, path string, position int64, inode int64, lastLine string) error {
+	now := time.Now()
+	file := &models.LogSourceFile{
+		SourceName:      sourceName,
+		Path:            path,
+		LastPosition:    position,
+		LastInode:       inode,
+		LastLineContent: lastLine,
+		LastReadAt:      &now,
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source_name"}, {Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_position", "last_inode", "last_line_content", "last_read_at", "updated_at"}),
+	}).Create(file).Error
+}
 
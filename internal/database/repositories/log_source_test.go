@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupLogSourceDB(t *testing.T) (*gorm.DB, LogSourceRepository) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.LogSource{}, &models.LogSourceFile{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db, NewLogSourceRepository(db)
+}
+
+func TestUpdateFileTrackingUpserts(t *testing.T) {
+	_, repo := setupLogSourceDB(t)
+	assert.NoError(t, repo.Create(&models.LogSource{Name: "web", Path: "/logs/access-*.log", ParserType: "traefik"}))
+
+	assert.NoError(t, repo.UpdateFileTracking("web", "/logs/access-1.log", 100, 7, "first"))
+	assert.NoError(t, repo.UpdateFileTracking("web", "/logs/access-2.log", 50, 8, "other"))
+	assert.NoError(t, repo.UpdateFileTracking("web", "/logs/access-1.log", 250, 7, "second"))
+
+	files, err := repo.FindFiles("web")
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "/logs/access-1.log", files[0].Path)
+	assert.Equal(t, int64(250), files[0].LastPosition)
+	assert.Equal(t, "second", files[0].LastLineContent)
+	assert.NotNil(t, files[0].LastReadAt)
+	assert.Equal(t, int64(50), files[1].LastPosition)
+
+	// The logical source row keeps its own tracking untouched
+	source, err := repo.FindByName("web")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), source.LastPosition)
+}
+
+func TestDeleteSourceRemovesFileTracking(t *testing.T) {
+	_, repo := setupLogSourceDB(t)
+	assert.NoError(t, repo.Create(&models.LogSource{Name: "web", Path: "/logs/*.log", ParserType: "traefik"}))
+	assert.NoError(t, repo.UpdateFileTracking("web", "/logs/a.log", 10, 1, ""))
+
+	assert.NoError(t, repo.Delete("web"))
+
+	files, err := repo.FindFiles("web")
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+	_, err = repo.FindByName("web")
+	assert.Error(t, err)
+}
+
+func TestLogProcessingStatsSumsGlobFiles(t *testing.T) {
+	db, repo := setupLogSourceDB(t)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "access-1.log"), make([]byte, 300), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "access-2.log"), make([]byte, 100), 0644))
+
+	assert.NoError(t, repo.Create(&models.LogSource{Name: "web", Path: filepath.Join(dir, "access-*.log"), ParserType: "traefik"}))
+	assert.NoError(t, repo.UpdateFileTracking("web", filepath.Join(dir, "access-1.log"), 300, 1, ""))
+	// A file that no longer exists is ignored
+	assert.NoError(t, repo.UpdateFileTracking("web", filepath.Join(dir, "access-0.log"), 999, 2, ""))
+
+	logger := pterm.DefaultLogger
+	stats, err := NewStatsRepository(db, &logger).GetLogProcessingStats()
+	assert.NoError(t, err)
+	assert.Len(t, stats, 1)
+	assert.Equal(t, int64(400), stats[0].FileSize)
+	assert.Equal(t, int64(300), stats[0].BytesProcessed)
+	assert.InDelta(t, 75.0, stats[0].Percentage, 0.001)
+	assert.NotNil(t, stats[0].LastProcessedAt)
+}
@@ -0,0 +1,78 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package repositories
+
+import (
+	"loglynx/internal/database/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ParseStatsRepository persists per-source parse counters
+type ParseStatsRepository interface {
+	// AddCounts adds the counters to the stored buckets, creating missing ones
+	AddCounts(stats []*models.ParseStat) error
+	// GetTimeline returns the buckets of a source starting at or after since, oldest first
+	GetTimeline(sourceName string, since time.Time) ([]*models.ParseStat, error)
+	// DeleteOlderThan removes buckets that started before cutoff
+	DeleteOlderThan(cutoff time.Time) (int64, error)
+}
+
+type parseStatsRepo struct {
+	db *gorm.DB
+}
+
+// NewParseStatsRepository creates a new parse stats repository
+func NewParseStatsRepository(db *gorm.DB) ParseStatsRepository {
+	return &parseStatsRepo{db: db}
+}
+
+func (r *parseStatsRepo) AddCounts(stats []*models.ParseStat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "source_name"}, {Name: "bucket_start"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"parsed":     gorm.Expr("parsed + excluded.parsed"),
+			"failed":     gorm.Expr("failed + excluded.failed"),
+			"skipped":    gorm.Expr("skipped + excluded.skipped"),
+			"duplicates": gorm.Expr("duplicates + excluded.duplicates"),
+		}),
+	}).Create(&stats).Error
+}
+
+func (r *parseStatsRepo) GetTimeline(sourceName string, since time.Time) ([]*models.ParseStat, error) {
+	var stats []*models.ParseStat
+	err := r.db.Where("source_name = ? AND bucket_start >= ?", sourceName, since).
+		Order("bucket_start ASC").
+		Find(&stats).Error
+	return stats, err
+}
+
+func (r *parseStatsRepo) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result := r.db.Where("bucket_start < ?", cutoff).Delete(&models.ParseStat{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,157 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"loglynx/internal/database/models"
+
+	"gorm.io/gorm"
+)
+
+// Hours older than the retention window may only survive as hourly_rollups rows
+// (DB_RETENTION_MODE=rollup). Cleanup moves requests rather than copying them, so
+// additive stats add both tables without double counting. Rollups keep no client IPs:
+// IP exclusions and distinct-visitor counts only apply exactly to raw requests.
+
+// rollupQuery selects the hourly_rollups rows in the time window matching the filters
+func (r *statsRepo) rollupQuery(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) *gorm.DB {
+	query := r.db.Model(&models.HourlyRollup{})
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyServiceFilters(query, filters)
+	return r.applyExcludeInternal(query, excludeIP)
+}
+
+// rollupTotals holds the summary counters of rolled-up hours
+type rollupTotals struct {
+	Requests         int64
+	ValidRequests    int64
+	FailedRequests   int64
+	NotFoundCount    int64
+	ServerErrorCount int64
+	BenignErrors     int64
+	Bandwidth        int64
+	ResponseTimeSum  float64
+	TimedRequests    int64
+	FirstTimestamp   string
+	LastTimestamp    string
+}
+
+// getRollupTotals sums the rolled-up hours counted by GetSummary
+func (r *statsRepo) getRollupTotals(ctx context.Context, hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (*rollupTotals, error) {
+	benign := "0"
+	if codes := r.benignStatusList(); codes != "" {
+		benign = "COALESCE(SUM(CASE WHEN status_code IN (" + codes + ") THEN requests END), 0)"
+	}
+
+	var totals rollupTotals
+	err := r.rollupQuery(hours, filters, excludeIP).WithContext(ctx).
+		Select(`COALESCE(SUM(requests), 0) as requests,
+			COALESCE(SUM(CASE WHEN status_code >= 200 AND status_code < 400 THEN requests END), 0) as valid_requests,
+			COALESCE(SUM(CASE WHEN status_code >= 400 THEN requests END), 0) as failed_requests,
+			COALESCE(SUM(CASE WHEN status_code = 404 THEN requests END), 0) as not_found_count,
+			COALESCE(SUM(CASE WHEN status_code >= 500 AND status_code < 600 THEN requests END), 0) as server_error_count,
+			` + benign + ` as benign_errors,
+			COALESCE(SUM(bandwidth), 0) as bandwidth,
+			COALESCE(SUM(response_time_sum), 0) as response_time_sum,
+			COALESCE(SUM(timed_requests), 0) as timed_requests,
+			COALESCE(MIN(timestamp), '') as first_timestamp,
+			COALESCE(MAX(timestamp), '') as last_timestamp`).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// mergeRollupTimeline adds rolled-up hours to the buckets of GetTimelineStats
+func (r *statsRepo) mergeRollupTimeline(timeline []*TimelineData, groupBy string, hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*TimelineData, error) {
+	var rows []struct {
+		Hour            string
+		Requests        int64
+		UniqueVisitors  int64
+		Bandwidth       int64
+		ResponseTimeSum float64
+	}
+	err := r.rollupQuery(hours, filters, excludeIP).
+		Select(groupBy + " as hour, SUM(requests) as requests, SUM(unique_visitors) as unique_visitors, SUM(bandwidth) as bandwidth, SUM(response_time_sum) as response_time_sum").
+		Group(groupBy).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return timeline, err
+	}
+
+	buckets := make(map[string]*TimelineData, len(timeline))
+	for _, point := range timeline {
+		buckets[point.Hour] = point
+	}
+	for _, row := range rows {
+		point, ok := buckets[row.Hour]
+		if !ok {
+			point = &TimelineData{Hour: row.Hour}
+			buckets[row.Hour] = point
+			timeline = append(timeline, point)
+		}
+		// AvgResponseTime of raw buckets averages every request, so weight it by their count
+		total := point.Requests + row.Requests
+		if total > 0 {
+			point.AvgResponseTime = (point.AvgResponseTime*float64(point.Requests) + row.ResponseTimeSum) / float64(total)
+		}
+		point.Requests = total
+		point.UniqueVisitors += row.UniqueVisitors
+		point.Bandwidth += row.Bandwidth
+	}
+
+	sort.Slice(timeline, func(i, j int) bool { return timeline[i].Hour < timeline[j].Hour })
+	return timeline, nil
+}
+
+// mergeRollupBandwidth adds rolled-up hours to the buckets of GetBandwidthTimeline
+func (r *statsRepo) mergeRollupBandwidth(timeline []*BandwidthTimelineData, groupBy string, hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*BandwidthTimelineData, error) {
+	var rows []*BandwidthTimelineData
+	err := r.rollupQuery(hours, filters, excludeIP).
+		Select(groupBy + " as hour, SUM(bytes_in) as bytes_in, SUM(bandwidth) as bytes_out").
+		Group(groupBy).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return timeline, err
+	}
+
+	buckets := make(map[string]*BandwidthTimelineData, len(timeline))
+	for _, point := range timeline {
+		buckets[point.Hour] = point
+	}
+	for _, row := range rows {
+		if point, ok := buckets[row.Hour]; ok {
+			point.BytesIn += row.BytesIn
+			point.BytesOut += row.BytesOut
+			continue
+		}
+		buckets[row.Hour] = row
+		timeline = append(timeline, row)
+	}
+
+	sort.Slice(timeline, func(i, j int) bool { return timeline[i].Hour < timeline[j].Hour })
+	return timeline, nil
+}
@@ -22,12 +22,18 @@
 package repositories
 
 import (
+	"container/heap"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
+	"math"
 	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -50,32 +56,53 @@ const (
 type StatsRepository interface {
 	GetSummary(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (*StatsSummary, error)
 	GetTimelineStats(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*TimelineData, error)
+	GetBandwidthTimeline(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*BandwidthTimelineData, error)
 	GetStatusCodeTimeline(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*StatusCodeTimelineData, error)
+	GetUniqueVisitorTimeline(hours int, host string, excludeIP *ExcludeIPFilter) ([]*UniqueVisitorData, error)
+	GetConcurrencyTimeline(hours int, bucket time.Duration, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ConcurrencyData, error)
+	GetPeakTraffic(granularity string, days int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*PeakTrafficData, error)
 	GetTrafficHeatmap(days int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*TrafficHeatmapData, error)
-	GetTopPaths(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*PathStats, error)
+	GetTopPaths(hours int, limit int, orderBy string, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*PathStats, error)
 	GetTopCountries(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*CountryStats, error)
+	GetGeoHeatmap(hours int, gridSize float64, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*GeoHeatmapCell, error)
 	GetTopIPAddresses(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter, tagFilter string, ipFilter *IPStatsFilter) ([]*IPStats, error)
 	GetStatusCodeDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*StatusCodeStats, error)
 	GetMethodDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*MethodStats, error)
+	GetMethodStatusMatrix(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*MethodStatusStats, error)
 	GetProtocolDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ProtocolStats, error)
 	GetTLSVersionDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*TLSVersionStats, error)
+	GetContentTypeDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ContentTypeStats, error)
+	GetResponseSizeHistogram(hours int, bounds []int64, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ResponseSizeBucket, error)
 	GetTopUserAgents(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*UserAgentStats, error)
 	GetTopBrowsers(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*BrowserStats, error)
+	GetTopOriginServers(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*OriginServerStats, error)
 	GetTopOperatingSystems(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*OSStats, error)
 	GetDeviceTypeDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*DeviceTypeStats, error)
+	GetClientProfiles(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ClientProfileStats, error)
+	GetTrafficByLabel(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*LabelStats, error)
+	GetBotTrafficStats(hours int, botLimit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (*BotTrafficStats, error)
 	GetTopASNs(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ASNStats, error)
 	GetTopBackends(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*BackendStats, error)
 	GetTopReferrers(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ReferrerStats, error)
 	GetTopReferrerDomains(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ReferrerDomainStats, error)
+	GetReferrerCategories(hours int, host string, excludeIP *ExcludeIPFilter) ([]*ReferrerCategoryStats, error)
 	GetResponseTimeStats(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (*ResponseTimeStats, error)
+	GetProxyOverheadStats(hours int, host string, excludeIP *ExcludeIPFilter) ([]*ProxyOverheadStats, error)
+	GetTLSSecurityStats(hours int, host string, excludeIP *ExcludeIPFilter) (*TLSSecurityStats, error)
+	GetSlowRequests(hours int, thresholdMs float64, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*SlowRequest, error)
+	GetErrorAnalysis(hours int, host string, limit int, excludeIP *ExcludeIPFilter) ([]*ErrorPathStats, error)
+	GetBackendBandwidthProjection(hours int, host string, excludeIP *ExcludeIPFilter) ([]*BackendBandwidthProjection, error)
+	GetRetriedRequests(hours int, host string, limit int, excludeIP *ExcludeIPFilter) (*RetryStats, error)
+	GetVisitorReturnStats(hours int, host string, excludeIP *ExcludeIPFilter) (*VisitorReturnStats, error)
 	GetComparison(periods []ComparisonPeriodRequest, filters []ServiceFilter, excludeIP *ExcludeIPFilter, topLimit int) (*ComparisonResult, error)
+	GetSummaryComparison(host string, currentStart, currentEnd, previousStart, previousEnd time.Time, excludeIP *ExcludeIPFilter) (*SummaryComparison, error)
 	CreateComparisonSnapshot(ownerID string, title string, payload string, expiresAt *time.Time) (*models.ComparisonSnapshot, error)
 	GetComparisonSnapshot(token string) (*models.ComparisonSnapshot, error)
 	ListComparisonSnapshots(ownerID string) ([]*models.ComparisonSnapshot, error)
 	UpdateComparisonSnapshot(ownerID string, token string, active bool, expiresAt *time.Time) (*models.ComparisonSnapshot, error)
 	DeleteComparisonSnapshot(ownerID string, token string) error
 	GetLogProcessingStats() ([]*LogProcessingStats, error)
-	GetDomains() ([]*DomainStats, error)
+	GetDomains(filterType string) ([]*DomainStats, error)
 	GetServices() ([]*ServiceInfo, error)
 
 	// IP-specific analytics
@@ -90,35 +117,261 @@ type StatsRepository interface {
 	GetIPDeviceTypeDistribution(ip string, hours int, filters []ServiceFilter) ([]*DeviceTypeStats, error)
 	GetIPResponseTimeStats(ip string, hours int, filters []ServiceFilter) (*ResponseTimeStats, error)
 	GetIPRecentRequests(ip string, limit int, hours int, filters []ServiceFilter) ([]*models.HTTPRequest, error)
-	SearchIPs(query string, limit int) ([]*IPSearchResult, error)
+	SearchIPs(query string, hours int, limit int) ([]*IPSearchResult, error)
 
 	// System statistics
 	CountRecordsOlderThan(cutoffDate time.Time) (int64, error)
 	GetRecordTimeRange() (oldest time.Time, newest time.Time, err error)
 	GetRecordsTimeline(days int) ([]*TimelineData, error)
+	GetQueryPlans(hours int) (*QueryPlanReport, error)
+
+	// Path flows
+	GetPathSequences(windowMinutes int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*PathTransition, error)
+
+	// Security
+	GetAuthAbuse(windowMinutes int) ([]*AuthAbuseIP, error)
+	GetSuspiciousIPs(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*SuspiciousIP, error)
+
+	// Configuration
+	SetBenignStatusCodes(codes []int)
+	SetMaxTopLimit(limit int)
+	SetAuthAbuseConfig(paths []string, minRequests int, minFailureRatio float64)
+	SetReferrerBlocklist(entries []string)
+	SetDisplayTimezone(loc *time.Location)
+	WithTimeOffset(offset time.Duration) StatsRepository
+	WithStatusRange(minStatus, maxStatus int) StatsRepository
 }
 
 type statsRepo struct {
-	db     *gorm.DB
-	logger *pterm.Logger
+	db                *gorm.DB
+	logger            *pterm.Logger
+	benignStatusCodes []int         // 4xx codes that do not count against availability
+	maxTopLimit       int           // Upper bound on rows returned by top-N lists
+	timeOffset        time.Duration // Shifts hours-based windows back: [now-offset-hours, now-offset]
+	statusMin         int           // Lower status_code bound of top-N lists, 0 for none
+	statusMax         int           // Upper status_code bound of top-N lists, 0 for none
+	authAbuse         authAbuseConfig
+	referrerBlocklist referrerBlocklist // Spam referrer domains hidden from referrer reports
+	displayZone       *time.Location    // Timezone of timeline buckets, nil for UTC
 }
 
 const (
 	// DefaultLookbackHours is the default time range for stats queries (7 days)
 	DefaultLookbackHours = 168
+	// MaxConcurrencyHours caps the concurrency estimate, which scans every request in range (30 days)
+	MaxConcurrencyHours = 720
+	// DefaultMaxTopLimit caps top-N lists when no explicit maximum is configured
+	DefaultMaxTopLimit = 1000
 )
 
 // NewStatsRepository creates a new stats repository
 func NewStatsRepository(db *gorm.DB, logger *pterm.Logger) StatsRepository {
 	return &statsRepo{
-		db:     db,
-		logger: logger,
+		db:          db,
+		logger:      logger,
+		maxTopLimit: DefaultMaxTopLimit,
+		authAbuse: authAbuseConfig{
+			paths:           DefaultAuthAbusePaths,
+			minRequests:     DefaultAuthAbuseMinRequests,
+			minFailureRatio: DefaultAuthAbuseMinFailureRatio,
+		},
 	}
 }
 
-// getTimeRange returns the time range for stats queries
-func (r *statsRepo) getTimeRange() time.Time {
-	return time.Now().Add(-DefaultLookbackHours * time.Hour)
+// SetMaxTopLimit sets the maximum number of rows a top-N list may return
+// Non-positive values restore DefaultMaxTopLimit
+func (r *statsRepo) SetMaxTopLimit(limit int) {
+	if limit <= 0 {
+		limit = DefaultMaxTopLimit
+	}
+	r.maxTopLimit = limit
+}
+
+// clampTopLimit bounds a requested top-N limit by the configured maximum
+// A non-positive limit used to mean "return all", which on a large table
+// materializes every distinct value, so it is capped and logged instead
+func (r *statsRepo) clampTopLimit(limit int, list string) int {
+	maxLimit := r.maxTopLimit
+	if maxLimit <= 0 {
+		maxLimit = DefaultMaxTopLimit
+	}
+	if limit <= 0 {
+		r.logger.Warn("Unbounded top list requested, capping result size",
+			r.logger.Args("list", list, "limit", maxLimit))
+		return maxLimit
+	}
+	if limit > maxLimit {
+		r.logger.Debug("Top list limit exceeds maximum, capping result size",
+			r.logger.Args("list", list, "requested", limit, "limit", maxLimit))
+		return maxLimit
+	}
+	return limit
+}
+
+// SetDisplayTimezone sets the timezone whose days and hours the timeline buckets follow.
+// Labels stay UTC instants; nil or UTC keeps plain UTC buckets.
+func (r *statsRepo) SetDisplayTimezone(loc *time.Location) {
+	r.displayZone = loc
+}
+
+// allTimeZoneLookback bounds the DST transitions considered for all-time timelines
+const allTimeZoneLookback = 10 * 365 * 24 * time.Hour
+
+// timelineDialect returns the dialect of the timeline buckets for an hours-based window,
+// shifted to the display timezone
+func (r *statsRepo) timelineDialect(hours int) sqlDialect {
+	since, until := r.timeWindow(hours)
+	if hours == 0 {
+		since = until.Add(-allTimeZoneLookback)
+	}
+	return dialectOf(r.db).inZone(r.displayZone, since, until)
+}
+
+// timelineBucketLayout is the layout of the minute to day bucket labels
+const timelineBucketLayout = "2006-01-02T15:04:05Z"
+
+// utcBucketLabel turns the label of a bucket computed in the display timezone into the
+// UTC instant the bucket starts at. Week and month labels name a period rather than an
+// instant and are returned unchanged.
+func (r *statsRepo) utcBucketLabel(label string) string {
+	if r.displayZone == nil || r.displayZone == time.UTC {
+		return label
+	}
+	start, err := time.ParseInLocation(timelineBucketLayout, label, r.displayZone)
+	if err != nil {
+		return label
+	}
+	return start.UTC().Format(timelineBucketLayout)
+}
+
+// SetBenignStatusCodes sets the 4xx status codes that are excluded from the availability rate
+// Codes outside the 400-499 range are ignored
+func (r *statsRepo) SetBenignStatusCodes(codes []int) {
+	benign := make([]int, 0, len(codes))
+	for _, code := range codes {
+		if code >= 400 && code < 500 {
+			benign = append(benign, code)
+		}
+	}
+	r.benignStatusCodes = benign
+}
+
+// benignErrorCountSQL returns the aggregate expression counting benign 4xx responses
+func (r *statsRepo) benignErrorCountSQL() string {
+	codes := r.benignStatusList()
+	if codes == "" {
+		return "0"
+	}
+	return weightedCountSQL("status_code IN (" + codes + ")")
+}
+
+// requestCountSQL counts requests rather than rows: rows of sampled sources stand for
+// sample_weight requests each (see LogSource.SampleRate)
+const requestCountSQL = "COALESCE(SUM(sample_weight), 0)"
+
+// weightedCountSQL counts the requests matching condition, scaled like requestCountSQL
+func weightedCountSQL(condition string) string {
+	return "COALESCE(SUM(CASE WHEN " + condition + " THEN sample_weight END), 0)"
+}
+
+// benignStatusList returns the benign status codes as a comma-separated SQL list ("" when none)
+// Codes are validated integers, so they are inlined instead of bound as arguments
+func (r *statsRepo) benignStatusList() string {
+	codes := make([]string, len(r.benignStatusCodes))
+	for i, code := range r.benignStatusCodes {
+		codes[i] = strconv.Itoa(code)
+	}
+	return strings.Join(codes, ",")
+}
+
+// WithTimeOffset returns a view of the repository whose hours-based windows end at
+// now minus offset instead of now (e.g. "the hour that started 3 hours ago")
+func (r *statsRepo) WithTimeOffset(offset time.Duration) StatsRepository {
+	shifted := *r
+	shifted.timeOffset = offset
+	return &shifted
+}
+
+// WithStatusRange returns a view of the repository whose GetTop* lists only count requests
+// with minStatus <= status_code <= maxStatus. A bound of 0 leaves that side open.
+func (r *statsRepo) WithStatusRange(minStatus, maxStatus int) StatsRepository {
+	narrowed := *r
+	narrowed.statusMin = minStatus
+	narrowed.statusMax = maxStatus
+	return &narrowed
+}
+
+// hasStatusRange reports whether top-N lists are narrowed to a status code range
+func (r *statsRepo) hasStatusRange() bool {
+	return r.statusMin > 0 || r.statusMax > 0
+}
+
+// appendStatusRange adds the WithStatusRange bounds to a raw SQL WHERE clause.
+// Ranges starting at 400 or above repeat the idx_errors predicate literally so the
+// planner can use that partial index; a bound parameter alone does not prove it.
+func (r *statsRepo) appendStatusRange(whereClause string, args []interface{}) (string, []interface{}) {
+	if r.statusMin >= 400 {
+		whereClause += " AND status_code >= 400"
+	}
+	if r.statusMin > 0 {
+		whereClause += " AND status_code >= ?"
+		args = append(args, r.statusMin)
+	}
+	if r.statusMax > 0 {
+		whereClause += " AND status_code <= ?"
+		args = append(args, r.statusMax)
+	}
+	return whereClause, args
+}
+
+// applyStatusRange is the gorm query builder counterpart of appendStatusRange
+func (r *statsRepo) applyStatusRange(query *gorm.DB) *gorm.DB {
+	if r.statusMin >= 400 {
+		query = query.Where("status_code >= 400")
+	}
+	if r.statusMin > 0 {
+		query = query.Where("status_code >= ?", r.statusMin)
+	}
+	if r.statusMax > 0 {
+		query = query.Where("status_code <= ?", r.statusMax)
+	}
+	return query
+}
+
+// timeWindow returns the bounds of an hours-based window. since is zero when hours is 0 (all time).
+func (r *statsRepo) timeWindow(hours int) (since, until time.Time) {
+	until = time.Now().UTC().Add(-r.timeOffset)
+	if hours > 0 {
+		since = until.Add(-time.Duration(hours) * time.Hour)
+	}
+	return since, until
+}
+
+// appendTimeWindow adds the window bounds to a raw SQL WHERE clause.
+// The upper bound is only needed when the window is shifted back from now.
+func (r *statsRepo) appendTimeWindow(whereClause string, args []interface{}, hours int) (string, []interface{}) {
+	since, until := r.timeWindow(hours)
+	if hours > 0 {
+		whereClause += " AND timestamp > ?"
+		args = append(args, since)
+	}
+	if r.timeOffset > 0 {
+		whereClause += " AND timestamp <= ?"
+		args = append(args, until)
+	}
+	return whereClause, args
+}
+
+// applyTimeWindow is the gorm query builder counterpart of appendTimeWindow
+func (r *statsRepo) applyTimeWindow(query *gorm.DB, hours int) *gorm.DB {
+	since, until := r.timeWindow(hours)
+	if hours > 0 {
+		query = query.Where("timestamp > ?", since)
+	}
+	if r.timeOffset > 0 {
+		query = query.Where("timestamp <= ?", until)
+	}
+	return query
 }
 
 // withTimeout creates a context with default query timeout
@@ -138,6 +391,15 @@ type ServiceFilter struct {
 type ExcludeIPFilter struct {
 	ClientIPs       []string
 	ExcludeServices []ServiceFilter
+	ExcludeInternal bool // Hide requests flagged as internal at ingestion (INTERNAL_NETWORKS)
+}
+
+// internalClause returns the raw SQL condition hiding internal requests when the filter asks for it
+func internalClause(excludeIP *ExcludeIPFilter) string {
+	if excludeIP != nil && excludeIP.ExcludeInternal {
+		return " AND is_internal = FALSE"
+	}
+	return ""
 }
 
 // applyServiceFilters applies multiple service-based filters to a query using OR logic
@@ -225,22 +487,40 @@ func (r *statsRepo) applyExcludeIPs(query *gorm.DB, clientIPs []string, excludeS
 	return query
 }
 
+// applyExcludeIPFilter applies both the IP exclusions and the internal-traffic exclusion
+func (r *statsRepo) applyExcludeIPFilter(query *gorm.DB, excludeIP *ExcludeIPFilter) *gorm.DB {
+	if excludeIP == nil {
+		return query
+	}
+	query = r.applyExcludeIPs(query, excludeIP.ClientIPs, excludeIP.ExcludeServices)
+	return r.applyExcludeInternal(query, excludeIP)
+}
+
+// applyExcludeInternal hides requests flagged as internal when the filter asks for it
+func (r *statsRepo) applyExcludeInternal(query *gorm.DB, excludeIP *ExcludeIPFilter) *gorm.DB {
+	if excludeIP != nil && excludeIP.ExcludeInternal {
+		return query.Where("is_internal = ?", false)
+	}
+	return query
+}
+
 // StatsSummary holds overall statistics
 type StatsSummary struct {
-	TotalRequests   int64   `json:"total_requests"`
-	ValidRequests   int64   `json:"valid_requests"`
-	FailedRequests  int64   `json:"failed_requests"`
-	UniqueVisitors  int64   `json:"unique_visitors"`
-	UniqueFiles     int64   `json:"unique_files"`
-	Unique404       int64   `json:"unique_404"`
-	TotalBandwidth  int64   `json:"total_bandwidth"`
-	AvgResponseTime float64 `json:"avg_response_time"`
-	SuccessRate     float64 `json:"success_rate"`
-	NotFoundRate    float64 `json:"not_found_rate"`
-	ServerErrorRate float64 `json:"server_error_rate"`
-	RequestsPerHour float64 `json:"requests_per_hour"`
-	TopCountry      string  `json:"top_country"`
-	TopPath         string  `json:"top_path"`
+	TotalRequests    int64   `json:"total_requests"`
+	ValidRequests    int64   `json:"valid_requests"`
+	FailedRequests   int64   `json:"failed_requests"`
+	UniqueVisitors   int64   `json:"unique_visitors"`
+	UniqueFiles      int64   `json:"unique_files"`
+	Unique404        int64   `json:"unique_404"`
+	TotalBandwidth   int64   `json:"total_bandwidth"`
+	AvgResponseTime  float64 `json:"avg_response_time"`
+	SuccessRate      float64 `json:"success_rate"`
+	AvailabilityRate float64 `json:"availability_rate"` // Like SuccessRate, but benign 4xx codes count as available
+	NotFoundRate     float64 `json:"not_found_rate"`
+	ServerErrorRate  float64 `json:"server_error_rate"`
+	RequestsPerHour  float64 `json:"requests_per_hour"`
+	TopCountry       string  `json:"top_country"`
+	TopPath          string  `json:"top_path"`
 }
 
 // TimelineData holds timeline statistics
@@ -252,6 +532,13 @@ type TimelineData struct {
 	AvgResponseTime float64 `json:"avg_response_time"`
 }
 
+// BandwidthTimelineData holds inbound and outbound bytes for one timeline bucket
+type BandwidthTimelineData struct {
+	Hour     string `json:"hour"`
+	BytesIn  int64  `json:"bytes_in"`  // Sum of request_length
+	BytesOut int64  `json:"bytes_out"` // Sum of response_size
+}
+
 // StatusCodeTimelineData holds status code timeline data for stacked chart
 type StatusCodeTimelineData struct {
 	Hour      string `gorm:"column:hour" json:"hour"`
@@ -261,6 +548,50 @@ type StatusCodeTimelineData struct {
 	Status5xx int64  `gorm:"column:status_5xx" json:"status_5xx"`
 }
 
+// UniqueVisitorData holds distinct client IPs for one timeline bucket. Unlike the per-bucket
+// unique_visitors of TimelineData, CumulativeVisitors can be read as a running total.
+type UniqueVisitorData struct {
+	Hour               string `json:"hour"`
+	Visitors           int64  `json:"visitors"`            // Distinct IPs in the bucket
+	NewVisitors        int64  `json:"new_visitors"`        // IPs whose first request in the window falls in the bucket
+	ReturningVisitors  int64  `json:"returning_visitors"`  // IPs already seen in an earlier bucket of the window
+	CumulativeVisitors int64  `json:"cumulative_visitors"` // Distinct IPs from the window start to the end of the bucket
+}
+
+// ConcurrencyData holds the estimated in-flight requests for one time bucket.
+// A request is in flight during [timestamp, timestamp+response_time].
+type ConcurrencyData struct {
+	Time          string  `json:"time"`
+	Requests      int64   `json:"requests"`       // Requests started in the bucket
+	MaxConcurrent int     `json:"max_concurrent"` // Peak overlapping requests
+	AvgConcurrent float64 `json:"avg_concurrent"` // Busy time divided by bucket length
+}
+
+// PeakTrafficData holds the traffic of one of the busiest time buckets
+type PeakTrafficData struct {
+	Timestamp         string  `json:"timestamp"` // Bucket start (UTC)
+	Requests          int64   `json:"requests"`
+	RequestsPerSecond float64 `json:"requests_per_second"` // Average rate over the bucket
+	UniqueVisitors    int64   `json:"unique_visitors"`
+	Bandwidth         int64   `json:"bandwidth"`
+}
+
+// peakBuckets maps a peak granularity to its SQL bucket expression and length
+var peakBuckets = map[string]struct {
+	groupBy func(sqlDialect) string
+	length  time.Duration
+}{
+	"minute": {sqlDialect.minuteBucket, time.Minute},
+	"hour":   {sqlDialect.hourBucket, time.Hour},
+	"day":    {sqlDialect.dayBucket, 24 * time.Hour},
+}
+
+// IsValidPeakGranularity reports whether GetPeakTraffic supports the granularity
+func IsValidPeakGranularity(granularity string) bool {
+	_, ok := peakBuckets[granularity]
+	return ok
+}
+
 // TrafficHeatmapData holds hourly traffic metrics for heatmap visualisation
 type TrafficHeatmapData struct {
 	DayOfWeek       int     `json:"day_of_week"`
@@ -313,6 +644,26 @@ type ComparisonResult struct {
 	Periods     []*ComparisonPeriodResult `json:"periods"`
 }
 
+// SummaryComparison holds the summaries of two explicit windows and how the current one changed
+type SummaryComparison struct {
+	CurrentStart  time.Time     `json:"current_start"`
+	CurrentEnd    time.Time     `json:"current_end"`
+	PreviousStart time.Time     `json:"previous_start"`
+	PreviousEnd   time.Time     `json:"previous_end"`
+	Current       *StatsSummary `json:"current"`
+	Previous      *StatsSummary `json:"previous"`
+	Deltas        SummaryDeltas `json:"deltas"`
+}
+
+// SummaryDeltas holds percentage changes from the previous window to the current one.
+// A delta is nil when the previous value is zero.
+type SummaryDeltas struct {
+	Requests        *float64 `json:"requests"`
+	Bandwidth       *float64 `json:"bandwidth"`
+	ErrorRate       *float64 `json:"error_rate"`
+	AvgResponseTime *float64 `json:"avg_response_time"`
+}
+
 // PathStats holds path statistics
 type PathStats struct {
 	Path            string  `json:"path"`
@@ -327,11 +678,41 @@ type PathStats struct {
 
 // CountryStats holds country statistics
 type CountryStats struct {
-	Country        string `json:"country"`
-	CountryName    string `json:"country_name"`
-	Hits           int64  `json:"hits"`
-	UniqueVisitors int64  `json:"unique_visitors"`
-	Bandwidth      int64  `json:"bandwidth"`
+	Country         string  `json:"country"`
+	CountryName     string  `json:"country_name"`
+	Hits            int64   `json:"hits"`
+	UniqueVisitors  int64   `json:"unique_visitors"`
+	Bandwidth       int64   `json:"bandwidth"`
+	Status4xx       int64   `gorm:"column:status_4xx" json:"status_4xx"`
+	Status5xx       int64   `gorm:"column:status_5xx" json:"status_5xx"`
+	ErrorRate       float64 `gorm:"-" json:"error_rate"` // Share of 4xx and 5xx responses (%)
+	AvgResponseTime float64 `json:"avg_response_time"`
+}
+
+// GeoHeatmapCell aggregates the geolocated requests of one lat/lon grid cell
+type GeoHeatmapCell struct {
+	Latitude       float64 `json:"latitude"`  // Cell center
+	Longitude      float64 `json:"longitude"` // Cell center
+	Hits           int64   `json:"hits"`
+	UniqueVisitors int64   `json:"unique_visitors"`
+}
+
+// DefaultGeoGridSize is the geo heatmap cell size in degrees when none is given
+const DefaultGeoGridSize = 1.0
+
+// countryHealthColumns are the per-country error and latency aggregates shared by the country reports
+var countryHealthColumns = `
+			` + weightedCountSQL("status_code >= 400 AND status_code < 500") + ` as status_4xx,
+			` + weightedCountSQL("status_code >= 500") + ` as status_5xx,
+			COALESCE(AVG(response_time_ms), 0) as avg_response_time`
+
+// setCountryErrorRates derives ErrorRate from the scanned status counts
+func setCountryErrorRates(countries []*CountryStats) {
+	for _, country := range countries {
+		if country.Hits > 0 {
+			country.ErrorRate = float64(country.Status4xx+country.Status5xx) / float64(country.Hits) * 100
+		}
+	}
 }
 
 // IPStats holds IP address statistics
@@ -369,6 +750,16 @@ type MethodStats struct {
 	Count  int64  `json:"count"`
 }
 
+// MethodStatusStats holds the status class breakdown of one HTTP method
+type MethodStatusStats struct {
+	Method    string `gorm:"column:method" json:"method"`
+	Total     int64  `gorm:"column:total" json:"total"`
+	Status2xx int64  `gorm:"column:status_2xx" json:"status_2xx"`
+	Status3xx int64  `gorm:"column:status_3xx" json:"status_3xx"`
+	Status4xx int64  `gorm:"column:status_4xx" json:"status_4xx"`
+	Status5xx int64  `gorm:"column:status_5xx" json:"status_5xx"`
+}
+
 // ProtocolStats holds HTTP protocol distribution
 type ProtocolStats struct {
 	Protocol string `json:"protocol"`
@@ -381,6 +772,27 @@ type TLSVersionStats struct {
 	Count      int64  `json:"count"`
 }
 
+// ContentTypeStats holds response content type distribution
+type ContentTypeStats struct {
+	ContentType string `json:"content_type"` // Media type without parameters, e.g. "text/html"
+	Count       int64  `json:"count"`
+	Bandwidth   int64  `json:"bandwidth"`
+}
+
+// DefaultResponseSizeBounds splits response sizes into <1KB, 1-10KB, 10-100KB, 100KB-1MB and >=1MB
+var DefaultResponseSizeBounds = []int64{1024, 10 * 1024, 100 * 1024, 1024 * 1024}
+
+// MaxResponseSizeBounds caps the number of bucket boundaries a histogram may use
+const MaxResponseSizeBounds = 20
+
+// ResponseSizeBucket is one range of the response size histogram
+type ResponseSizeBucket struct {
+	MinBytes  int64  `json:"min_bytes"`
+	MaxBytes  *int64 `json:"max_bytes"` // Exclusive upper bound; nil for the last, open-ended bucket
+	Count     int64  `json:"count"`
+	Bandwidth int64  `json:"bandwidth"`
+}
+
 // UserAgentStats holds user agent statistics
 type UserAgentStats struct {
 	UserAgent string `json:"user_agent"`
@@ -393,6 +805,78 @@ type BrowserStats struct {
 	Count   int64  `json:"count"`
 }
 
+// OriginServerStats holds traffic served by one origin software (Server response header)
+type OriginServerStats struct {
+	Server    string `json:"server"`
+	Count     int64  `json:"count"`
+	Hosts     int64  `json:"hosts"` // Distinct hosts answered by this origin
+	Bandwidth int64  `json:"bandwidth"`
+}
+
+// SlowRequest is a single request that took longer than the slow threshold
+type SlowRequest struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Method         string    `json:"method"`
+	Host           string    `json:"host"`
+	Path           string    `json:"path"`
+	BackendName    string    `json:"backend_name"`
+	StatusCode     int       `json:"status_code"`
+	ResponseTimeMs float64   `json:"response_time_ms"`
+}
+
+// ErrorPathStats holds the failed requests (status 400 and above) of one path
+type ErrorPathStats struct {
+	Path       string             `json:"path"`
+	Errors     int64              `json:"errors"`
+	Statuses   []*StatusCodeStats `json:"statuses"` // Most frequent first
+	LastSeen   time.Time          `json:"last_seen"`
+	LastIP     string             `json:"last_client_ip"`   // Client of the most recent failure
+	LastAgent  string             `json:"last_user_agent"`  // User agent of the most recent failure
+	LastStatus int                `json:"last_status_code"` // Status of the most recent failure
+}
+
+// RetryStats reports the requests the proxy retried against their upstream (RetryAttempts)
+type RetryStats struct {
+	Requests        int64           `json:"requests"`         // All requests in the window
+	RetriedRequests int64           `json:"retried_requests"` // Requests retried at least once
+	TotalRetries    int64           `json:"total_retries"`
+	RetryRate       float64         `json:"retry_rate"` // Percentage of requests retried
+	Outcomes        []*RetryOutcome `json:"outcomes"`   // Final status by retry count, 0 retries first
+	Targets         []*RetryTarget  `json:"targets"`    // Most retried backend and path pairs
+}
+
+// RetryOutcome is the final status of the requests retried the same number of times
+type RetryOutcome struct {
+	Attempts    int     `json:"attempts"`
+	Requests    int64   `json:"requests"`
+	Failed      int64   `json:"failed"`       // Final status 500 and above
+	FailureRate float64 `json:"failure_rate"` // Percentage of the requests that failed
+}
+
+// RetryTarget holds the retried requests of one path of one backend
+type RetryTarget struct {
+	Backend     string  `json:"backend"` // Backend name, else backend URL, else host
+	Path        string  `json:"path"`
+	Requests    int64   `json:"requests"` // Retried requests
+	Retries     int64   `json:"retries"`
+	MaxAttempts int     `json:"max_attempts"`
+	Failed      int64   `json:"failed"`       // Retried requests whose final status is 500 and above
+	FailureRate float64 `json:"failure_rate"` // Percentage of the retried requests that failed
+}
+
+// VisitorReturnStats splits the visitors (client IPs) of a window into new ones and
+// returning ones that were seen before the window started
+type VisitorReturnStats struct {
+	WindowStart       time.Time `json:"window_start"`
+	Visitors          int64     `json:"visitors"`
+	NewVisitors       int64     `json:"new_visitors"`
+	ReturningVisitors int64     `json:"returning_visitors"`
+	ReturningRate     float64   `json:"returning_rate"` // Percentage of visitors seen before the window
+	Requests          int64     `json:"requests"`
+	NewRequests       int64     `json:"new_requests"`       // Requests of the new visitors
+	ReturningRequests int64     `json:"returning_requests"` // Requests of the returning visitors
+}
+
 // OSStats holds operating system statistics
 type OSStats struct {
 	OS    string `json:"os"`
@@ -405,6 +889,43 @@ type DeviceTypeStats struct {
 	Count      int64  `json:"count"`
 }
 
+// ClientProfileStats holds the requests of one browser, OS and device type combination
+type ClientProfileStats struct {
+	Browser        string `json:"browser"`
+	OS             string `json:"os"`
+	DeviceType     string `json:"device_type"`
+	Count          int64  `json:"count"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+// BotTrafficStats splits traffic between bots (device_type "bot") and everything else
+type BotTrafficStats struct {
+	TotalRequests   int64       `json:"total_requests"`
+	BotRequests     int64       `json:"bot_requests"`
+	HumanRequests   int64       `json:"human_requests"`
+	BotPercentage   float64     `json:"bot_percentage"`
+	HumanPercentage float64     `json:"human_percentage"`
+	BotBandwidth    int64       `json:"bot_bandwidth"`
+	HumanBandwidth  int64       `json:"human_bandwidth"`
+	Bots            []*BotStats `json:"bots,omitempty"` // Per-bot breakdown, only when requested
+}
+
+// BotStats holds traffic for one bot, named by the user agent parser
+type BotStats struct {
+	Name       string  `json:"name"`
+	Requests   int64   `json:"requests"`
+	Percentage float64 `json:"percentage"` // Share of all bot requests
+	Bandwidth  int64   `json:"bandwidth"`
+}
+
+// LabelStats holds traffic for one user-defined classification label
+type LabelStats struct {
+	Label          string `json:"label"`
+	Hits           int64  `json:"hits"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+	Bandwidth      int64  `json:"bandwidth"`
+}
+
 // ReferrerStats holds referrer statistics
 type ReferrerStats struct {
 	Referrer       string `json:"referrer"`
@@ -419,6 +940,14 @@ type ReferrerDomainStats struct {
 	UniqueVisitors int64  `json:"unique_visitors"`
 }
 
+// ReferrerCategoryStats holds the requests of one referrer category
+type ReferrerCategoryStats struct {
+	Category   string  `json:"category"` // "search", "social", "direct", "internal" or "other"
+	Hits       int64   `json:"hits"`
+	Domains    int64   `json:"domains"` // Distinct referrer domains (0 for direct)
+	Percentage float64 `json:"percentage"`
+}
+
 // BackendStats holds backend statistics
 type BackendStats struct {
 	BackendName     string  `json:"backend_name"`
@@ -431,6 +960,21 @@ type BackendStats struct {
 	ErrorCount      int64   `json:"error_count"`
 }
 
+// ProjectionDays is the horizon of the bandwidth projection of GetBackendBandwidthProjection
+const ProjectionDays = 30
+
+// BackendBandwidthProjection is the bandwidth rate of one backend over the window,
+// projected over the next ProjectionDays at the same rate
+type BackendBandwidthProjection struct {
+	Backend        string  `json:"backend"` // Backend name, else backend URL, else host
+	Requests       int64   `json:"requests"`
+	Bandwidth      int64   `json:"bandwidth"`   // Bytes sent in the window
+	WindowDays     float64 `json:"window_days"` // Days the rate is averaged over
+	BytesPerDay    float64 `json:"bytes_per_day"`
+	ProjectedBytes int64   `json:"projected_bytes"` // BytesPerDay * ProjectionDays
+	Share          float64 `json:"share"`           // Percentage of the bandwidth of all backends
+}
+
 // ASNStats holds ASN statistics
 type ASNStats struct {
 	ASN       int    `json:"asn"`
@@ -450,6 +994,47 @@ type ResponseTimeStats struct {
 	P99 float64 `json:"p99"`
 }
 
+// ProxyOverheadStats splits the response time of one backend into time spent waiting for the
+// upstream and time added by the proxy (response_time_ms - upstream_response_time_ms)
+type ProxyOverheadStats struct {
+	Backend       string  `json:"backend"` // Backend name, else backend URL, else host
+	Requests      int64   `json:"requests"`
+	AvgTotalMs    float64 `json:"avg_total_ms"`
+	AvgUpstreamMs float64 `json:"avg_upstream_ms"`
+	AvgOverheadMs float64 `json:"avg_overhead_ms"`
+	P50OverheadMs float64 `json:"p50_overhead_ms"`
+	P95OverheadMs float64 `json:"p95_overhead_ms"`
+	P99OverheadMs float64 `json:"p99_overhead_ms"`
+	OverheadShare float64 `json:"overhead_share"` // Percentage of the total time added by the proxy
+}
+
+// TLS security grades of GetTLSSecurityStats
+const (
+	TLSGradeSecure    = "secure"
+	TLSGradeWeak      = "weak"
+	TLSGradePlaintext = "plaintext"
+)
+
+// TLSSecurityStats splits traffic by how well its connection was protected
+type TLSSecurityStats struct {
+	TotalRequests       int64           `json:"total_requests"`
+	SecureRequests      int64           `json:"secure_requests"`    // TLS 1.2+ with an AEAD cipher
+	WeakRequests        int64           `json:"weak_requests"`      // TLS 1.0/1.1 or a non-AEAD (e.g. CBC) cipher
+	PlaintextRequests   int64           `json:"plaintext_requests"` // No TLS version logged
+	SecurePercentage    float64         `json:"secure_percentage"`
+	WeakPercentage      float64         `json:"weak_percentage"`
+	PlaintextPercentage float64         `json:"plaintext_percentage"`
+	Weak                []*TLSWeakUsage `json:"weak"` // Weak version and cipher pairs, most used first
+}
+
+// TLSWeakUsage holds the traffic of one weak TLS version and cipher pair
+type TLSWeakUsage struct {
+	TLSVersion     string `json:"tls_version"`
+	TLSCipher      string `json:"tls_cipher"`
+	Requests       int64  `json:"requests"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
 // LogProcessingStats holds log processing statistics
 type LogProcessingStats struct {
 	LogSourceName   string     `json:"log_source_name"`
@@ -493,21 +1078,107 @@ func (r *statsRepo) GetSummary(hours int, filters []ServiceFilter, excludeIP *Ex
 		AvgResponseTime  float64 `gorm:"column:avg_response_time"`
 		NotFoundCount    int64   `gorm:"column:not_found_count"`
 		ServerErrorCount int64   `gorm:"column:server_error_count"`
+		BenignErrors     int64   `gorm:"column:benign_errors"`
+		TimedRequests    int64   `gorm:"column:timed_requests"`
 		FirstTimestamp   string  `gorm:"column:first_timestamp"`
 		LastTimestamp    string  `gorm:"column:last_timestamp"`
 	}
 
 	var result aggregatedResult
+	baseSQL, args := r.summaryQuery(hours, filters, excludeIP)
+	if err := r.db.WithContext(ctx).Raw(baseSQL, args...).Scan(&result).Error; err != nil {
+		r.logger.WithCaller().Error("Failed to get summary stats", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	// Add hours that were rolled up by the cleanup service
+	rolled, err := r.getRollupTotals(ctx, hours, filters, excludeIP)
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get rolled-up summary stats", r.logger.Args("error", err))
+		return nil, err
+	}
+	if rolled.Requests > 0 {
+		if timed := result.TimedRequests + rolled.TimedRequests; timed > 0 {
+			result.AvgResponseTime = (result.AvgResponseTime*float64(result.TimedRequests) + rolled.ResponseTimeSum) / float64(timed)
+		}
+		result.TotalRequests += rolled.Requests
+		result.ValidRequests += rolled.ValidRequests
+		result.FailedRequests += rolled.FailedRequests
+		result.TotalBandwidth += rolled.Bandwidth
+		result.NotFoundCount += rolled.NotFoundCount
+		result.ServerErrorCount += rolled.ServerErrorCount
+		result.BenignErrors += rolled.BenignErrors
+		if result.FirstTimestamp == "" || rolled.FirstTimestamp < result.FirstTimestamp {
+			result.FirstTimestamp = rolled.FirstTimestamp
+		}
+		if rolled.LastTimestamp > result.LastTimestamp {
+			result.LastTimestamp = rolled.LastTimestamp
+		}
+	}
+
+	// Map aggregated results to summary
+	summary.TotalRequests = result.TotalRequests
+	summary.ValidRequests = result.ValidRequests
+	summary.FailedRequests = result.FailedRequests
+	summary.UniqueVisitors = result.UniqueVisitors
+	summary.UniqueFiles = result.UniqueFiles
+	summary.Unique404 = result.Unique404
+	summary.TotalBandwidth = result.TotalBandwidth
+	summary.AvgResponseTime = result.AvgResponseTime
+
+	// Calculate rates
+	if summary.TotalRequests > 0 {
+		summary.SuccessRate = float64(summary.ValidRequests) / float64(summary.TotalRequests) * 100
+		summary.AvailabilityRate = float64(summary.ValidRequests+result.BenignErrors) / float64(summary.TotalRequests) * 100
+		summary.NotFoundRate = float64(result.NotFoundCount) / float64(summary.TotalRequests) * 100
+		summary.ServerErrorRate = float64(result.ServerErrorCount) / float64(summary.TotalRequests) * 100
+	}
+
+	// Requests per hour
+	if hours > 0 {
+		summary.RequestsPerHour = float64(summary.TotalRequests) / float64(hours)
+	} else {
+		// For all time, calculate from the same summary scan to avoid a second full-table query.
+		if result.FirstTimestamp != "" && result.LastTimestamp != "" {
+			firstTime := parseAggregateTimestamp(result.FirstTimestamp)
+			if firstTime.IsZero() {
+				firstTime = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+			}
+
+			lastTime := parseAggregateTimestamp(result.LastTimestamp)
+			if lastTime.IsZero() {
+				lastTime = time.Now()
+			}
+
+			if !firstTime.IsZero() && !lastTime.IsZero() {
+				durationHours := lastTime.Sub(firstTime).Hours()
+				if durationHours < 1 {
+					durationHours = 1
+				}
+				summary.RequestsPerHour = float64(summary.TotalRequests) / durationHours
+			} else {
+				summary.RequestsPerHour = 0
+			}
+		} else {
+			summary.RequestsPerHour = 0
+		}
+	}
+
+	summary.TopCountry = ""
+	summary.TopPath = ""
 
+	r.logger.Trace("Generated stats summary (optimized)", r.logger.Args("total_requests", summary.TotalRequests, "service_filters", filters))
+	return summary, nil
+}
+
+// summaryQuery builds the raw-table aggregate behind GetSummary
+func (r *statsRepo) summaryQuery(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (string, []interface{}) {
 	// Build WHERE clause and args manually to avoid gorm schema parsing issues
 	whereClause := "1=1"
 	args := []interface{}{}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause += internalClause(excludeIP)
 
 	if excludeIP != nil && len(excludeIP.ClientIPs) > 0 {
 		if len(excludeIP.ExcludeServices) == 0 {
@@ -565,165 +1236,418 @@ func (r *statsRepo) GetSummary(hours int, filters []ServiceFilter, excludeIP *Ex
 	}
 
 	baseSQL := `WITH base AS (
-		SELECT timestamp, status_code, response_size, response_time_ms, client_ip, path, geo_country
+		SELECT timestamp, status_code, response_size, response_time_ms, client_ip, path, geo_country, sample_weight
 		FROM http_requests
 		WHERE ` + whereClause + `
 	)
 	SELECT 
-		COUNT(*) as total_requests,
-		COUNT(CASE WHEN status_code >= 200 AND status_code < 400 THEN 1 END) as valid_requests,
-		COUNT(CASE WHEN status_code >= 400 THEN 1 END) as failed_requests,
+		` + requestCountSQL + ` as total_requests,
+		` + weightedCountSQL("status_code >= 200 AND status_code < 400") + ` as valid_requests,
+		` + weightedCountSQL("status_code >= 400") + ` as failed_requests,
 		COUNT(DISTINCT client_ip) as unique_visitors,
 		COUNT(DISTINCT path) as unique_files,
 		COUNT(DISTINCT CASE WHEN status_code = 404 THEN path END) as unique_404,
-		COALESCE(SUM(response_size), 0) as total_bandwidth,
+		COALESCE(SUM(response_size * sample_weight), 0) as total_bandwidth,
 		COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time,
-		COUNT(CASE WHEN status_code = 404 THEN 1 END) as not_found_count,
-		COUNT(CASE WHEN status_code >= 500 AND status_code < 600 THEN 1 END) as server_error_count,
+		COUNT(CASE WHEN response_time_ms > 0 THEN 1 END) as timed_requests,
+		` + weightedCountSQL("status_code = 404") + ` as not_found_count,
+		` + weightedCountSQL("status_code >= 500 AND status_code < 600") + ` as server_error_count,
+		` + r.benignErrorCountSQL() + ` as benign_errors,
 		MIN(timestamp) as first_timestamp,
 		MAX(timestamp) as last_timestamp
 	 FROM base`
-	if err := r.db.WithContext(ctx).Raw(baseSQL, args...).Scan(&result).Error; err != nil {
-		r.logger.WithCaller().Error("Failed to get summary stats", r.logger.Args("error", err))
-		return nil, err
+	return baseSQL, args
+}
+
+// timelineGroupBy returns the adaptive bucket expression for a time range
+func timelineGroupBy(d sqlDialect, hours int) string {
+	switch {
+	case hours > 0 && hours <= 24:
+		return d.hourBucket() // hourly UTC
+	case hours > 0 && hours <= 168:
+		return d.sixHourBucket() // 6-hour blocks UTC
+	case hours > 0 && hours <= 720:
+		return d.dayBucket() // daily UTC
+	default:
+		return d.monthBucket() // monthly bucket, index-friendly for all-time ranges
 	}
+}
 
-	// Map aggregated results to summary
-	summary.TotalRequests = result.TotalRequests
-	summary.ValidRequests = result.ValidRequests
-	summary.FailedRequests = result.FailedRequests
-	summary.UniqueVisitors = result.UniqueVisitors
-	summary.UniqueFiles = result.UniqueFiles
-	summary.Unique404 = result.Unique404
-	summary.TotalBandwidth = result.TotalBandwidth
-	summary.AvgResponseTime = result.AvgResponseTime
+// GetTimelineStats returns time-based statistics with adaptive granularity
+// OPTIMIZED: Uses substr() instead of strftime() for faster grouping on string timestamps
+func (r *statsRepo) GetTimelineStats(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*TimelineData, error) {
+	var timeline []*TimelineData
 
-	// Calculate rates
-	if summary.TotalRequests > 0 {
-		summary.SuccessRate = float64(summary.ValidRequests) / float64(summary.TotalRequests) * 100
-		summary.NotFoundRate = float64(result.NotFoundCount) / float64(summary.TotalRequests) * 100
-		summary.ServerErrorRate = float64(result.ServerErrorCount) / float64(summary.TotalRequests) * 100
+	query, groupBy := r.timelineQuery(hours, filters, excludeIP)
+	err := query.Scan(&timeline).Error
+	if err == nil {
+		timeline, err = r.mergeRollupTimeline(timeline, groupBy, hours, filters, excludeIP)
 	}
 
-	// Requests per hour
-	if hours > 0 {
-		summary.RequestsPerHour = float64(summary.TotalRequests) / float64(hours)
-	} else {
-		// For all time, calculate from the same summary scan to avoid a second full-table query.
-		if result.FirstTimestamp != "" && result.LastTimestamp != "" {
-			var firstTime, lastTime time.Time
-			if t, err := time.Parse(SQLiteTimeFormat, result.FirstTimestamp); err == nil {
-				firstTime = t
-			} else if t, err := time.Parse(time.DateTime, result.FirstTimestamp); err == nil {
-				firstTime = t
-			} else {
-				firstTime = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
-			}
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get timeline stats", r.logger.Args("error", err))
+		return nil, err
+	}
+	for _, point := range timeline {
+		point.Hour = r.utcBucketLabel(point.Hour)
+	}
 
-			if t, err := time.Parse(SQLiteTimeFormat, result.LastTimestamp); err == nil {
-				lastTime = t
-			} else if t, err := time.Parse(time.DateTime, result.LastTimestamp); err == nil {
-				lastTime = t
-			} else {
-				lastTime = time.Now()
-			}
+	r.logger.Trace("Generated timeline stats", r.logger.Args("hours", hours, "data_points", len(timeline), "service_filters", filters))
+	return timeline, nil
+}
 
-			if !firstTime.IsZero() && !lastTime.IsZero() {
-				durationHours := lastTime.Sub(firstTime).Hours()
-				if durationHours < 1 {
-					durationHours = 1
-				}
-				summary.RequestsPerHour = float64(summary.TotalRequests) / durationHours
-			} else {
-				summary.RequestsPerHour = 0
-			}
-		} else {
-			summary.RequestsPerHour = 0
-		}
-	}
+// timelineQuery builds the raw-table bucket query behind GetTimelineStats
+func (r *statsRepo) timelineQuery(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (*gorm.DB, string) {
+	groupBy := timelineGroupBy(r.timelineDialect(hours), hours)
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select(groupBy + " as hour, " + requestCountSQL + " as requests, COUNT(DISTINCT client_ip) as unique_visitors, COALESCE(SUM(response_size * sample_weight), 0) as bandwidth, COALESCE(AVG(response_time_ms), 0) as avg_response_time")
 
-	summary.TopCountry = ""
-	summary.TopPath = ""
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyExcludeInternal(query, excludeIP)
 
-	r.logger.Trace("Generated stats summary (optimized)", r.logger.Args("total_requests", summary.TotalRequests, "service_filters", filters))
-	return summary, nil
+	query = r.applyServiceFilters(query, filters)
+	return query.Group(groupBy).Order("hour"), groupBy
 }
 
-// GetTimelineStats returns time-based statistics with adaptive granularity
-// OPTIMIZED: Uses substr() instead of strftime() for faster grouping on string timestamps
-func (r *statsRepo) GetTimelineStats(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*TimelineData, error) {
-	var timeline []*TimelineData
+// GetBandwidthTimeline returns inbound (request_length) and outbound (response_size) bytes
+// per bucket, using the same adaptive granularity as GetTimelineStats
+func (r *statsRepo) GetBandwidthTimeline(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*BandwidthTimelineData, error) {
+	var timeline []*BandwidthTimelineData
 
-	// Adaptive grouping based on time range
-	var groupBy string
+	groupBy := timelineGroupBy(r.timelineDialect(hours), hours)
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select(groupBy + " as hour, COALESCE(SUM(request_length * sample_weight), 0) as bytes_in, COALESCE(SUM(response_size * sample_weight), 0) as bytes_out")
 
-	switch {
-	case hours > 0 && hours <= 24:
-		groupBy = "strftime('%Y-%m-%dT%H:00:00Z', timestamp)" // hourly UTC
-	case hours > 0 && hours <= 168:
-		groupBy = "strftime('%Y-%m-%dT', timestamp) || printf('%02d', (CAST(strftime('%H', timestamp) AS INTEGER) / 6) * 6) || ':00:00Z'" // 6-hour blocks UTC
-	case hours > 0 && hours <= 720:
-		groupBy = "strftime('%Y-%m-%dT00:00:00Z', timestamp)" // daily UTC
-	default:
-		groupBy = "substr(timestamp, 1, 7)" // monthly bucket, index-friendly for all-time ranges
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+	query = query.Group(groupBy).Order("hour")
+
+	err := query.Scan(&timeline).Error
+	if err == nil {
+		timeline, err = r.mergeRollupBandwidth(timeline, groupBy, hours, filters, excludeIP)
+	}
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get bandwidth timeline", r.logger.Args("error", err))
+		return nil, err
+	}
+	for _, point := range timeline {
+		point.Hour = r.utcBucketLabel(point.Hour)
 	}
 
+	r.logger.Trace("Generated bandwidth timeline", r.logger.Args("hours", hours, "data_points", len(timeline), "service_filters", filters))
+	return timeline, nil
+}
+
+// GetUniqueVisitorTimeline returns distinct client IPs per bucket, using the same adaptive
+// granularity as GetTimelineStats, split into new and returning visitors with a running total.
+// "New" is relative to the window, so every visitor of the first bucket is new. Rolled-up hours
+// keep no client IPs and are not counted.
+func (r *statsRepo) GetUniqueVisitorTimeline(hours int, host string, excludeIP *ExcludeIPFilter) ([]*UniqueVisitorData, error) {
+	groupBy := timelineGroupBy(r.timelineDialect(hours), hours)
+	visits := r.db.Model(&models.HTTPRequest{}).
+		Select("DISTINCT " + groupBy + " as hour, client_ip")
+	visits = r.applyTimeWindow(visits, hours)
+	visits = r.applyExcludeIPFilter(visits, excludeIP)
+	if host != "" {
+		visits = visits.Where("host = ?", host)
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	var timeline []*UniqueVisitorData
+	err := r.db.WithContext(ctx).Raw(`
+		WITH visits AS (?),
+		first_seen AS (
+			SELECT client_ip, MIN(hour) as first_hour
+			FROM visits
+			GROUP BY client_ip
+		)
+		SELECT
+			v.hour,
+			COUNT(*) as visitors,
+			SUM(CASE WHEN f.first_hour = v.hour THEN 1 ELSE 0 END) as new_visitors
+		FROM visits v
+		JOIN first_seen f ON f.client_ip = v.client_ip
+		GROUP BY v.hour
+		ORDER BY v.hour
+	`, visits).Scan(&timeline).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get unique visitor timeline", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	var cumulative int64
+	for _, point := range timeline {
+		point.Hour = r.utcBucketLabel(point.Hour)
+		point.ReturningVisitors = point.Visitors - point.NewVisitors
+		cumulative += point.NewVisitors
+		point.CumulativeVisitors = cumulative
+	}
+
+	r.logger.Trace("Generated unique visitor timeline", r.logger.Args("hours", hours, "host", host, "data_points", len(timeline)))
+	return timeline, nil
+}
+
+// GetPeakTraffic returns the busiest minute, hour or day buckets of the last days
+// (0 = all time), ordered by request count, for capacity planning against peaks
+func (r *statsRepo) GetPeakTraffic(granularity string, days int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*PeakTrafficData, error) {
+	bucket, ok := peakBuckets[granularity]
+	if !ok {
+		return nil, fmt.Errorf("unsupported peak granularity %q", granularity)
+	}
+	limit = r.clampTopLimit(limit, "peaks")
+
+	var peaks []*PeakTrafficData
+	groupBy := bucket.groupBy(dialectOf(r.db))
+
 	query := r.db.Model(&models.HTTPRequest{}).
-		Select(groupBy + " as hour, COUNT(*) as requests, COUNT(DISTINCT client_ip) as unique_visitors, COALESCE(SUM(response_size), 0) as bandwidth, COALESCE(AVG(response_time_ms), 0) as avg_response_time")
+		Select(groupBy + " as timestamp, " + requestCountSQL + " as requests, COUNT(DISTINCT client_ip) as unique_visitors, COALESCE(SUM(response_size * sample_weight), 0) as bandwidth")
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		query = query.Where("timestamp > ?", since)
+	query = r.applyTimeWindow(query, days*24)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	err := query.WithContext(ctx).Group(groupBy).Order("requests DESC").Limit(limit).Scan(&peaks).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get peak traffic", r.logger.Args("granularity", granularity, "error", err))
+		return nil, err
 	}
 
+	for _, peak := range peaks {
+		peak.RequestsPerSecond = float64(peak.Requests) / bucket.length.Seconds()
+	}
+
+	r.logger.Trace("Generated peak traffic", r.logger.Args("granularity", granularity, "days", days, "peaks", len(peaks)))
+	return peaks, nil
+}
+
+// GetConcurrencyTimeline estimates concurrent requests per time bucket from request start
+// times and durations. A zero bucket picks one based on the time range.
+func (r *statsRepo) GetConcurrencyTimeline(hours int, bucket time.Duration, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ConcurrencyData, error) {
+	// Every request in the window is scanned, so all-time ranges are capped
+	if hours <= 0 || hours > MaxConcurrencyHours {
+		hours = MaxConcurrencyHours
+	}
+	if bucket <= 0 {
+		switch {
+		case hours <= 1:
+			bucket = time.Minute
+		case hours <= 24:
+			bucket = 5 * time.Minute
+		case hours <= 168:
+			bucket = time.Hour
+		default:
+			bucket = 6 * time.Hour
+		}
+	}
+
+	since, until := r.timeWindow(hours)
+	sweep := newConcurrencySweep(since, until, bucket)
+
+	query := r.db.Model(&models.HTTPRequest{}).Select("timestamp, response_time_ms")
+	query = r.applyTimeWindow(query, hours)
 	query = r.applyServiceFilters(query, filters)
-	query = query.Group(groupBy).Order("hour")
+	query = r.applyExcludeIPFilter(query, excludeIP)
 
-	err := query.Scan(&timeline).Error
+	ctx, cancel := r.withTimeout()
+	defer cancel()
 
+	rows, err := query.WithContext(ctx).Order("timestamp ASC").Rows()
 	if err != nil {
-		r.logger.WithCaller().Error("Failed to get timeline stats", r.logger.Args("error", err))
+		r.logger.WithCaller().Error("Failed to get concurrency timeline", r.logger.Args("error", err))
 		return nil, err
 	}
+	defer rows.Close()
 
-	r.logger.Trace("Generated timeline stats", r.logger.Args("hours", hours, "data_points", len(timeline), "service_filters", filters))
+	var row struct {
+		Timestamp      time.Time
+		ResponseTimeMs float64
+	}
+	for rows.Next() {
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			r.logger.WithCaller().Error("Failed to scan concurrency row", r.logger.Args("error", err))
+			return nil, err
+		}
+		sweep.add(row.Timestamp, time.Duration(row.ResponseTimeMs*float64(time.Millisecond)))
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.WithCaller().Error("Failed to get concurrency timeline", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	timeline := sweep.finish()
+	r.logger.Trace("Generated concurrency timeline",
+		r.logger.Args("hours", hours, "bucket", bucket.String(), "data_points", len(timeline), "service_filters", filters))
 	return timeline, nil
 }
 
+// concurrencySweep counts overlapping requests with a sweep over start times
+// (fed in ascending order) and a min-heap of end times
+type concurrencySweep struct {
+	start  time.Time // Start of the first bucket
+	until  time.Time
+	bucket time.Duration
+	points []*ConcurrencyData
+	busy   []time.Duration // Summed request time overlapping each bucket
+	ends   endTimeHeap
+	next   int // First bucket whose opening level is not yet recorded
+}
+
+func newConcurrencySweep(since, until time.Time, bucket time.Duration) *concurrencySweep {
+	start := since.Truncate(bucket)
+	count := int((until.Sub(start) + bucket - 1) / bucket)
+	if count < 1 {
+		count = 1
+	}
+
+	s := &concurrencySweep{
+		start:  start,
+		until:  until,
+		bucket: bucket,
+		points: make([]*ConcurrencyData, count),
+		busy:   make([]time.Duration, count),
+	}
+	for i := range s.points {
+		s.points[i] = &ConcurrencyData{Time: start.Add(time.Duration(i) * bucket).UTC().Format(time.RFC3339)}
+	}
+	return s
+}
+
+// add records one request; calls must be ordered by start time
+func (s *concurrencySweep) add(start time.Time, duration time.Duration) {
+	idx := int(start.Sub(s.start) / s.bucket)
+	if start.Before(s.start) || idx >= len(s.points) {
+		return
+	}
+	if duration < 0 {
+		duration = 0
+	}
+	end := start.Add(duration)
+
+	s.advance(start)
+	s.expire(start)
+	heap.Push(&s.ends, end.UnixNano())
+
+	point := s.points[idx]
+	point.Requests++
+	if s.ends.Len() > point.MaxConcurrent {
+		point.MaxConcurrent = s.ends.Len()
+	}
+
+	// Spread the request's duration over the buckets it overlaps
+	for i := idx; i < len(s.busy); i++ {
+		bucketStart := s.start.Add(time.Duration(i) * s.bucket)
+		if !bucketStart.Before(end) {
+			break
+		}
+		from, to := maxTime(start, bucketStart), minTime(end, bucketStart.Add(s.bucket))
+		s.busy[i] += to.Sub(from)
+	}
+}
+
+// advance records the level carried into every bucket that opens at or before t,
+// so buckets spanned by long requests without new arrivals still report them
+func (s *concurrencySweep) advance(t time.Time) {
+	for s.next < len(s.points) {
+		bucketStart := s.start.Add(time.Duration(s.next) * s.bucket)
+		if bucketStart.After(t) {
+			return
+		}
+		s.expire(bucketStart)
+		if s.ends.Len() > s.points[s.next].MaxConcurrent {
+			s.points[s.next].MaxConcurrent = s.ends.Len()
+		}
+		s.next++
+	}
+}
+
+// expire drops requests that finished at or before t
+func (s *concurrencySweep) expire(t time.Time) {
+	for s.ends.Len() > 0 && s.ends[0] <= t.UnixNano() {
+		heap.Pop(&s.ends)
+	}
+}
+
+// finish fills in the remaining buckets and computes average concurrency
+func (s *concurrencySweep) finish() []*ConcurrencyData {
+	s.advance(s.until)
+	for i, point := range s.points {
+		bucketStart := s.start.Add(time.Duration(i) * s.bucket)
+		length := minTime(bucketStart.Add(s.bucket), s.until).Sub(bucketStart)
+		if length > 0 {
+			point.AvgConcurrent = float64(s.busy[i]) / float64(length)
+		}
+	}
+	return s.points
+}
+
+// endTimeHeap is a min-heap of request end times in unix nanoseconds
+type endTimeHeap []int64
+
+func (h endTimeHeap) Len() int            { return len(h) }
+func (h endTimeHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h endTimeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *endTimeHeap) Push(x interface{}) { *h = append(*h, x.(int64)) }
+func (h *endTimeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
 // GetStatusCodeTimeline returns status code distribution over time
 func (r *statsRepo) GetStatusCodeTimeline(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*StatusCodeTimelineData, error) {
 	var timeline []*StatusCodeTimelineData
 
 	// Simplified grouping - use only simple expressions that work in SQLite
+	d := r.timelineDialect(hours)
 	var groupBy string
 	if hours > 0 && hours <= 24 {
 		// Group by hour for last 24 hours
-		groupBy = "strftime('%Y-%m-%dT%H:00:00Z', timestamp)"
+		groupBy = d.hourBucket()
 	} else if hours > 0 && hours <= 168 {
 		// Group by day for last 7 days
-		groupBy = "strftime('%Y-%m-%dT00:00:00Z', timestamp)"
+		groupBy = d.dayBucket()
 	} else if hours > 0 && hours <= 720 {
 		// Group by day for last 30 days
-		groupBy = "strftime('%Y-%m-%dT00:00:00Z', timestamp)"
+		groupBy = d.dayBucket()
 	} else {
 		// Monthly bucket, index-friendly for all-time ranges
-		groupBy = "substr(timestamp, 1, 7)"
+		groupBy = d.monthBucket()
 	}
 
 	// Build the query with explicit grouping
-	// Use COUNT instead of SUM(CASE WHEN) for better reliability
+	// Status classes are counted in requests, scaled by sample_weight
 	// Note: In SQLite, we need to be careful with type comparisons
 	query := r.db.Table("http_requests").
 		Select(groupBy + " as hour, " +
-			"COUNT(CASE WHEN status_code >= 200 AND status_code < 300 THEN 1 END) as status_2xx, " +
-			"COUNT(CASE WHEN status_code >= 300 AND status_code < 400 THEN 1 END) as status_3xx, " +
-			"COUNT(CASE WHEN status_code >= 400 AND status_code < 500 THEN 1 END) as status_4xx, " +
-			"COUNT(CASE WHEN status_code >= 500 THEN 1 END) as status_5xx")
+			weightedCountSQL("status_code >= 200 AND status_code < 300") + " as status_2xx, " +
+			weightedCountSQL("status_code >= 300 AND status_code < 400") + " as status_3xx, " +
+			weightedCountSQL("status_code >= 400 AND status_code < 500") + " as status_4xx, " +
+			weightedCountSQL("status_code >= 500") + " as status_5xx")
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		query = query.Where("timestamp > ?", since)
-	}
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyExcludeInternal(query, excludeIP)
 
 	query = r.applyServiceFilters(query, filters)
 	query = query.Group(groupBy).Order("hour")
@@ -731,7 +1655,8 @@ func (r *statsRepo) GetStatusCodeTimeline(hours int, filters []ServiceFilter, ex
 	// Log the query for debugging
 	sinceStr := "all time"
 	if hours > 0 {
-		sinceStr = time.Now().Add(-time.Duration(hours) * time.Hour).Format(time.DateTime)
+		since, _ := r.timeWindow(hours)
+		sinceStr = since.Format(time.DateTime)
 	}
 	r.logger.Debug("Executing status code timeline query",
 		r.logger.Args("hours", hours, "since", sinceStr, "groupBy", groupBy, "service_filters", filters))
@@ -741,6 +1666,9 @@ func (r *statsRepo) GetStatusCodeTimeline(hours int, filters []ServiceFilter, ex
 		r.logger.WithCaller().Error("Failed to get status code timeline", r.logger.Args("error", err))
 		return nil, err
 	}
+	for _, point := range timeline {
+		point.Hour = r.utcBucketLabel(point.Hour)
+	}
 
 	if len(timeline) == 0 {
 		r.logger.Warn("Status code timeline returned 0 data points",
@@ -763,12 +1691,13 @@ func (r *statsRepo) GetTrafficHeatmap(days int, filters []ServiceFilter, exclude
 	}
 
 	var heatmap []*TrafficHeatmapData
-	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	since := time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour)
 
 	// Build WHERE clause
 	whereClause := "timestamp > ?"
 	args := []interface{}{}
 	args = append(args, since)
+	whereClause += internalClause(excludeIP)
 
 	// Apply service filters inline for better query planning
 	if len(filters) > 0 {
@@ -797,9 +1726,9 @@ func (r *statsRepo) GetTrafficHeatmap(days int, filters []ServiceFilter, exclude
 	// Optimized raw SQL query
 	query := `
 		SELECT
-			CAST(strftime('%w', timestamp) AS INTEGER) as day_of_week,
-			CAST(strftime('%H', timestamp) AS INTEGER) as hour,
-			COUNT(*) as requests,
+			` + dialectOf(r.db).dayOfWeek() + ` as day_of_week,
+			` + dialectOf(r.db).hourOfDay() + ` as hour,
+			` + requestCountSQL + ` as requests,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time
 		FROM http_requests
 		WHERE ` + whereClause + `
@@ -856,21 +1785,22 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 	}
 
 	periodHours := period.End.Sub(period.Start).Hours()
-	groupBy := "strftime('%Y-%m-%dT%H:00:00Z', timestamp)"
+	d := dialectOf(r.db)
+	groupBy := d.hourBucket()
 	if periodHours > 24 && periodHours <= 168 {
-		groupBy = "strftime('%Y-%m-%dT', timestamp) || printf('%02d', (CAST(strftime('%H', timestamp) AS INTEGER) / 6) * 6) || ':00:00Z'"
+		groupBy = d.sixHourBucket()
 	} else if periodHours > 168 && periodHours <= 720 {
-		groupBy = "strftime('%Y-%m-%dT00:00:00Z', timestamp)"
+		groupBy = d.dayBucket()
 	} else if periodHours > 720 {
-		groupBy = "substr(timestamp, 1, 7)"
+		groupBy = d.monthBucket()
 	}
 
 	timeline := []*TimelineData{}
 	if err := r.db.WithContext(ctx).Raw(`
 		SELECT `+groupBy+` as hour,
-			COUNT(*) as requests,
+			`+requestCountSQL+` as requests,
 			COUNT(DISTINCT client_ip) as unique_visitors,
-			COALESCE(SUM(response_size), 0) as bandwidth,
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time
 		FROM http_requests
 		WHERE `+whereClause+`
@@ -887,10 +1817,10 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 		SELECT `+serviceExpr+` as service_name,
 			`+serviceTypeExpr+` as service_type,
 			`+groupBy+` as hour,
-			COUNT(*) as requests,
-			COALESCE(SUM(response_size), 0) as bandwidth,
+			`+requestCountSQL+` as requests,
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time,
-			COUNT(CASE WHEN status_code >= 400 THEN 1 END) as errors
+			`+weightedCountSQL("status_code >= 400")+` as errors
 		FROM http_requests
 		WHERE `+whereClause+`
 		GROUP BY service_name, service_type, hour
@@ -902,10 +1832,10 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 	statusTimeline := []*StatusCodeTimelineData{}
 	if err := r.db.WithContext(ctx).Raw(`
 		SELECT `+groupBy+` as hour,
-			COUNT(CASE WHEN status_code >= 200 AND status_code < 300 THEN 1 END) as status_2xx,
-			COUNT(CASE WHEN status_code >= 300 AND status_code < 400 THEN 1 END) as status_3xx,
-			COUNT(CASE WHEN status_code >= 400 AND status_code < 500 THEN 1 END) as status_4xx,
-			COUNT(CASE WHEN status_code >= 500 THEN 1 END) as status_5xx
+			`+weightedCountSQL("status_code >= 200 AND status_code < 300")+` as status_2xx,
+			`+weightedCountSQL("status_code >= 300 AND status_code < 400")+` as status_3xx,
+			`+weightedCountSQL("status_code >= 400 AND status_code < 500")+` as status_4xx,
+			`+weightedCountSQL("status_code >= 500")+` as status_5xx
 		FROM http_requests
 		WHERE `+whereClause+`
 		GROUP BY hour
@@ -916,9 +1846,9 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 
 	heatmap := []*TrafficHeatmapData{}
 	if err := r.db.WithContext(ctx).Raw(`
-		SELECT CAST(strftime('%w', timestamp) AS INTEGER) as day_of_week,
-			CAST(strftime('%H', timestamp) AS INTEGER) as hour,
-			COUNT(*) as requests,
+		SELECT `+d.dayOfWeek()+` as day_of_week,
+			`+d.hourOfDay()+` as hour,
+			`+requestCountSQL+` as requests,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time
 		FROM http_requests
 		WHERE `+whereClause+`
@@ -933,10 +1863,10 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 	pathArgs = append(pathArgs, topLimit)
 	if err := r.db.WithContext(ctx).Raw(`
 		SELECT path,
-			COUNT(*) as hits,
+			`+requestCountSQL+` as hits,
 			COUNT(DISTINCT client_ip) as unique_visitors,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time,
-			COALESCE(SUM(response_size), 0) as total_bandwidth
+			COALESCE(SUM(response_size * sample_weight), 0) as total_bandwidth
 		FROM http_requests
 		WHERE `+whereClause+`
 		GROUP BY path
@@ -955,8 +1885,8 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 			MAX(hr.geo_city) as city,
 			MAX(hr.geo_lat) as latitude,
 			MAX(hr.geo_lon) as longitude,
-			COUNT(*) as hits,
-			COALESCE(SUM(hr.response_size), 0) as bandwidth,
+			`+requestCountSQL+` as hits,
+			COALESCE(SUM(hr.response_size * hr.sample_weight), 0) as bandwidth,
 			COALESCE(MAX(it.friendly_name), '') as friendly_name,
 			COALESCE(MAX(it.tags), '') as tags
 		FROM http_requests hr
@@ -975,9 +1905,9 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 	if err := r.db.WithContext(ctx).Raw(`
 		SELECT geo_country as country,
 			geo_country as country_name,
-			COUNT(*) as hits,
+			`+requestCountSQL+` as hits,
 			COUNT(DISTINCT client_ip) as unique_visitors,
-			COALESCE(SUM(response_size), 0) as bandwidth
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,`+countryHealthColumns+`
 		FROM http_requests
 		WHERE `+whereClause+` AND geo_country != ''
 		GROUP BY geo_country
@@ -986,6 +1916,7 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 	`, countryArgs...).Scan(&topCountries).Error; err != nil {
 		return nil, err
 	}
+	setCountryErrorRates(topCountries)
 
 	topBackends := []*BackendStats{}
 	backendArgs := append([]interface{}{}, args...)
@@ -995,10 +1926,10 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 			MAX(backend_url) as backend_url,
 			MAX(host) as host,
 			CASE WHEN backend_name != '' THEN 'backend_name' WHEN backend_url != '' THEN 'backend_url' ELSE 'host' END as service_type,
-			COUNT(*) as hits,
-			COALESCE(SUM(response_size), 0) as bandwidth,
+			`+requestCountSQL+` as hits,
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time,
-			COUNT(CASE WHEN status_code >= 500 THEN 1 END) as error_count
+			`+weightedCountSQL("status_code >= 500")+` as error_count
 		FROM http_requests
 		WHERE `+whereClause+`
 		GROUP BY COALESCE(NULLIF(backend_name, ''), NULLIF(backend_url, ''), host)
@@ -1014,10 +1945,10 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 			MAX(backend_url) as backend_url,
 			MAX(host) as host,
 			CASE WHEN backend_name != '' THEN 'backend_name' WHEN backend_url != '' THEN 'backend_url' WHEN host != '' THEN 'host' ELSE 'unknown' END as service_type,
-			COUNT(*) as hits,
-			COALESCE(SUM(response_size), 0) as bandwidth,
+			`+requestCountSQL+` as hits,
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time,
-			COUNT(CASE WHEN status_code >= 400 THEN 1 END) as error_count
+			`+weightedCountSQL("status_code >= 400")+` as error_count
 		FROM http_requests
 		WHERE `+whereClause+`
 		GROUP BY COALESCE(NULLIF(backend_name, ''), NULLIF(backend_url, ''), host, 'unknown')
@@ -1028,7 +1959,7 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 
 	statusDistribution := []*StatusCodeStats{}
 	if err := r.db.WithContext(ctx).Raw(`
-		SELECT status_code, COUNT(*) as count
+		SELECT status_code, `+requestCountSQL+` as count
 		FROM http_requests
 		WHERE `+whereClause+`
 		GROUP BY status_code
@@ -1039,7 +1970,7 @@ func (r *statsRepo) getComparisonPeriod(period ComparisonPeriodRequest, filters
 
 	deviceTypes := []*DeviceTypeStats{}
 	if err := r.db.WithContext(ctx).Raw(`
-		SELECT COALESCE(NULLIF(device_type, ''), 'unknown') as device_type, COUNT(*) as count
+		SELECT COALESCE(NULLIF(device_type, ''), 'unknown') as device_type, `+requestCountSQL+` as count
 		FROM http_requests
 		WHERE `+whereClause+`
 		GROUP BY COALESCE(NULLIF(device_type, ''), 'unknown')
@@ -1079,20 +2010,22 @@ func (r *statsRepo) getComparisonSummary(ctx context.Context, whereClause string
 		AvgResponseTime  float64 `gorm:"column:avg_response_time"`
 		NotFoundCount    int64   `gorm:"column:not_found_count"`
 		ServerErrorCount int64   `gorm:"column:server_error_count"`
+		BenignErrors     int64   `gorm:"column:benign_errors"`
 	}
 
 	var row aggregatedResult
 	if err := r.db.WithContext(ctx).Raw(`
-		SELECT COUNT(*) as total_requests,
-			COUNT(CASE WHEN status_code >= 200 AND status_code < 400 THEN 1 END) as valid_requests,
-			COUNT(CASE WHEN status_code >= 400 THEN 1 END) as failed_requests,
+		SELECT `+requestCountSQL+` as total_requests,
+			`+weightedCountSQL("status_code >= 200 AND status_code < 400")+` as valid_requests,
+			`+weightedCountSQL("status_code >= 400")+` as failed_requests,
 			COUNT(DISTINCT client_ip) as unique_visitors,
 			COUNT(DISTINCT path) as unique_files,
 			COUNT(DISTINCT CASE WHEN status_code = 404 THEN path END) as unique_404,
-			COALESCE(SUM(response_size), 0) as total_bandwidth,
+			COALESCE(SUM(response_size * sample_weight), 0) as total_bandwidth,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time,
-			COUNT(CASE WHEN status_code = 404 THEN 1 END) as not_found_count,
-			COUNT(CASE WHEN status_code >= 500 AND status_code < 600 THEN 1 END) as server_error_count
+			`+weightedCountSQL("status_code = 404")+` as not_found_count,
+			`+weightedCountSQL("status_code >= 500 AND status_code < 600")+` as server_error_count,
+			`+r.benignErrorCountSQL()+` as benign_errors
 		FROM http_requests
 		WHERE `+whereClause,
 		args...).Scan(&row).Error; err != nil {
@@ -1113,6 +2046,7 @@ func (r *statsRepo) getComparisonSummary(ctx context.Context, whereClause string
 	}
 	if summary.TotalRequests > 0 {
 		summary.SuccessRate = float64(summary.ValidRequests) / float64(summary.TotalRequests) * 100
+		summary.AvailabilityRate = float64(summary.ValidRequests+row.BenignErrors) / float64(summary.TotalRequests) * 100
 		summary.NotFoundRate = float64(row.NotFoundCount) / float64(summary.TotalRequests) * 100
 		summary.ServerErrorRate = float64(row.ServerErrorCount) / float64(summary.TotalRequests) * 100
 	}
@@ -1124,9 +2058,70 @@ func (r *statsRepo) getComparisonSummary(ctx context.Context, whereClause string
 	return summary, nil
 }
 
+// GetSummaryComparison summarizes two explicit windows, e.g. this week and last week,
+// and reports the percentage change of the main figures
+func (r *statsRepo) GetSummaryComparison(host string, currentStart, currentEnd, previousStart, previousEnd time.Time, excludeIP *ExcludeIPFilter) (*SummaryComparison, error) {
+	var filters []ServiceFilter
+	if host != "" {
+		filters = []ServiceFilter{{Name: host, Type: "host"}}
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	summarize := func(start, end time.Time) (*StatsSummary, error) {
+		whereClause, args := r.buildComparisonWhere(start, end, filters, excludeIP)
+		return r.getComparisonSummary(ctx, whereClause, args, start, end)
+	}
+	current, err := summarize(currentStart, currentEnd)
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to summarize current window", r.logger.Args("error", err))
+		return nil, err
+	}
+	previous, err := summarize(previousStart, previousEnd)
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to summarize previous window", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	return &SummaryComparison{
+		CurrentStart:  currentStart,
+		CurrentEnd:    currentEnd,
+		PreviousStart: previousStart,
+		PreviousEnd:   previousEnd,
+		Current:       current,
+		Previous:      previous,
+		Deltas: SummaryDeltas{
+			Requests:        percentChange(float64(current.TotalRequests), float64(previous.TotalRequests)),
+			Bandwidth:       percentChange(float64(current.TotalBandwidth), float64(previous.TotalBandwidth)),
+			ErrorRate:       percentChange(errorRate(current), errorRate(previous)),
+			AvgResponseTime: percentChange(current.AvgResponseTime, previous.AvgResponseTime),
+		},
+	}, nil
+}
+
+// errorRate is the share of requests answered with a 4xx or 5xx status, in percent
+func errorRate(summary *StatsSummary) float64 {
+	if summary.TotalRequests == 0 {
+		return 0
+	}
+	return float64(summary.FailedRequests) / float64(summary.TotalRequests) * 100
+}
+
+// percentChange returns the change from previous to current in percent, or nil when
+// previous is zero and the change is undefined
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := (current - previous) / previous * 100
+	return &change
+}
+
 func (r *statsRepo) buildComparisonWhere(start time.Time, end time.Time, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (string, []interface{}) {
 	whereClause := "timestamp >= ? AND timestamp <= ?"
-	args := []interface{}{start, end}
+	args := []interface{}{start.UTC(), end.UTC()}
+	whereClause += internalClause(excludeIP)
 
 	if excludeIP != nil && len(excludeIP.ClientIPs) > 0 {
 		if len(excludeIP.ExcludeServices) == 0 {
@@ -1251,21 +2246,56 @@ func generateSnapshotToken() string {
 	return hex.EncodeToString(buf)
 }
 
-// GetTopPaths returns most accessed paths
+// Orderings accepted by GetTopPaths
+const (
+	PathOrderHits            = "hits"
+	PathOrderBandwidth       = "bandwidth"
+	PathOrderAvgResponseTime = "avg_response_time"
+)
+
+// GetTopPaths returns the top paths ranked by orderBy (PathOrderHits when empty or unknown),
+// so the heaviest or slowest endpoints can be found as well as the most accessed ones
 // OPTIMIZED: Uses raw SQL with index hints and efficient aggregation
 // The new idx_path_aggregation index makes this query ~10x faster
-func (r *statsRepo) GetTopPaths(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*PathStats, error) {
+func (r *statsRepo) GetTopPaths(hours int, limit int, orderBy string, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*PathStats, error) {
+	limit = r.clampTopLimit(limit, "paths")
+
 	var paths []*PathStats
+	query, args := r.topPathsQuery(hours, limit, orderBy, filters, excludeIP)
+	err := r.db.Raw(query, args...).Scan(&paths).Error
+
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get top paths", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// topPathsOrder returns the result column a GetTopPaths ordering sorts on and the
+// aggregate ranking the paths before they are limited
+func topPathsOrder(orderBy string) (column string, rank string) {
+	switch orderBy {
+	case PathOrderBandwidth:
+		return "total_bandwidth", "COALESCE(SUM(response_size * sample_weight), 0)"
+	case PathOrderAvgResponseTime:
+		return "avg_response_time", "COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0)"
+	default:
+		return "hits", "hits"
+	}
+}
+
+// topPathsQuery builds the query behind GetTopPaths for an already clamped limit
+func (r *statsRepo) topPathsQuery(hours int, limit int, orderBy string, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (string, []interface{}) {
+	orderColumn, rank := topPathsOrder(orderBy)
 
 	// Build WHERE clause for efficient filtering
 	whereClause := "1=1"
 	args := []interface{}{}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause = "timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause, args = r.appendStatusRange(whereClause, args)
+	whereClause += internalClause(excludeIP)
 
 	// Apply service filters inline for better query planning
 	if len(filters) > 0 {
@@ -1296,27 +2326,28 @@ func (r *statsRepo) GetTopPaths(hours int, limit int, filters []ServiceFilter, e
 	query := `
 		SELECT 
 			path,
-			COUNT(*) as hits,
+			` + requestCountSQL + ` as hits,
 			COUNT(DISTINCT client_ip) as unique_visitors,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time,
-			COALESCE(SUM(response_size), 0) as total_bandwidth
+			COALESCE(SUM(response_size * sample_weight), 0) as total_bandwidth
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY path
-		ORDER BY hits DESC
+		ORDER BY ` + orderColumn + ` DESC
 		LIMIT ?
 	`
 	args = append(args, limit)
-	if len(filters) == 0 && excludeIP == nil {
+	// The CTE variants below only handle unfiltered windows that end now
+	if len(filters) == 0 && excludeIP == nil && r.timeOffset == 0 && !r.hasStatusRange() {
 		if hours > 0 {
 			since := args[0]
 			query = `
 				WITH top_paths AS (
-					SELECT path, COUNT(*) as hits
+					SELECT path, ` + requestCountSQL + ` as hits
 					FROM http_requests
 					WHERE timestamp > ?
 					GROUP BY path
-					ORDER BY hits DESC
+					ORDER BY ` + rank + ` DESC
 					LIMIT ?
 				)
 				SELECT
@@ -1324,21 +2355,21 @@ func (r *statsRepo) GetTopPaths(hours int, limit int, filters []ServiceFilter, e
 					tp.hits,
 					COUNT(DISTINCT hr.client_ip) as unique_visitors,
 					COALESCE(AVG(CASE WHEN hr.response_time_ms > 0 THEN hr.response_time_ms END), 0) as avg_response_time,
-					COALESCE(SUM(hr.response_size), 0) as total_bandwidth
+					COALESCE(SUM(hr.response_size * hr.sample_weight), 0) as total_bandwidth
 				FROM top_paths tp
 				JOIN http_requests hr ON hr.path = tp.path
 				WHERE hr.timestamp > ?
 				GROUP BY tp.path, tp.hits
-				ORDER BY tp.hits DESC
+				ORDER BY ` + orderColumn + ` DESC
 			`
 			args = []interface{}{since, limit, since}
 		} else {
 			query = `
 				WITH top_paths AS (
-					SELECT path, COUNT(*) as hits
+					SELECT path, ` + requestCountSQL + ` as hits
 					FROM http_requests
 					GROUP BY path
-					ORDER BY hits DESC
+					ORDER BY ` + rank + ` DESC
 					LIMIT ?
 				)
 				SELECT
@@ -1346,40 +2377,35 @@ func (r *statsRepo) GetTopPaths(hours int, limit int, filters []ServiceFilter, e
 					tp.hits,
 					COUNT(DISTINCT hr.client_ip) as unique_visitors,
 					COALESCE(AVG(CASE WHEN hr.response_time_ms > 0 THEN hr.response_time_ms END), 0) as avg_response_time,
-					COALESCE(SUM(hr.response_size), 0) as total_bandwidth
+					COALESCE(SUM(hr.response_size * hr.sample_weight), 0) as total_bandwidth
 				FROM top_paths tp
 				JOIN http_requests hr ON hr.path = tp.path
 				GROUP BY tp.path, tp.hits
-				ORDER BY tp.hits DESC
+				ORDER BY ` + orderColumn + ` DESC
 			`
 			args = []interface{}{limit}
 		}
 	}
 
-	err := r.db.Raw(query, args...).Scan(&paths).Error
-
-	if err != nil {
-		r.logger.WithCaller().Error("Failed to get top paths", r.logger.Args("error", err))
-		return nil, err
-	}
-
-	return paths, nil
+	return query, args
 }
 
-// GetTopCountries returns top countries by requests
+// GetTopCountries returns top countries by requests, with their 4xx/5xx counts and
+// average response time to spot regions hit by routing or backend problems.
+// Only rows with a non-empty geo_country are scanned, so the partial idx_geo_agg index can serve the query.
 // OPTIMIZED: Uses raw SQL for better query planning with the idx_geo_aggregation index
 func (r *statsRepo) GetTopCountries(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*CountryStats, error) {
+	limit = r.clampTopLimit(limit, "countries")
+
 	var countries []*CountryStats
 
 	// Build WHERE clause
 	whereClause := "geo_country != ''"
 	args := []interface{}{}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause, args = r.appendStatusRange(whereClause, args)
+	whereClause += internalClause(excludeIP)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -1409,20 +2435,17 @@ func (r *statsRepo) GetTopCountries(hours int, limit int, filters []ServiceFilte
 		SELECT 
 			geo_country as country,
 			'' as country_name,
-			COUNT(*) as hits,
+			` + requestCountSQL + ` as hits,
 			COUNT(DISTINCT client_ip) as unique_visitors,
-			COALESCE(SUM(response_size), 0) as bandwidth
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,` + countryHealthColumns + `
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY geo_country
 		ORDER BY hits DESC
 	`
 
-	// Only apply limit if > 0 (0 means no limit - return all)
-	if limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, limit)
-	}
+	query += " LIMIT ?"
+	args = append(args, limit)
 
 	err := r.db.Raw(query, args...).Scan(&countries).Error
 
@@ -1430,24 +2453,77 @@ func (r *statsRepo) GetTopCountries(hours int, limit int, filters []ServiceFilte
 		r.logger.WithCaller().Error("Failed to get top countries", r.logger.Args("error", err))
 		return nil, err
 	}
+	setCountryErrorRates(countries)
 
 	return countries, nil
 }
 
+// GetGeoHeatmap buckets geolocated requests into a grid of gridSize degrees, rounding
+// coordinates to the nearest cell center, for a density map that stays small however
+// many clients there are. Requests without coordinates (0, 0) are left out.
+func (r *statsRepo) GetGeoHeatmap(hours int, gridSize float64, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*GeoHeatmapCell, error) {
+	if gridSize <= 0 {
+		gridSize = DefaultGeoGridSize
+	}
+
+	points := r.db.Model(&models.HTTPRequest{}).
+		Select("ROUND(geo_lat / ?) AS lat_cell, ROUND(geo_lon / ?) AS lon_cell, client_ip, sample_weight", gridSize, gridSize).
+		Where("(geo_lat != 0 OR geo_lon != 0)")
+	points = r.applyTimeWindow(points, hours)
+	points = r.applyStatusRange(points)
+	points = r.applyServiceFilters(points, filters)
+	points = r.applyExcludeIPFilter(points, excludeIP)
+
+	// Grouping on the subquery columns keeps the bound grid size out of GROUP BY,
+	// which PostgreSQL cannot match against the SELECT expressions
+	var rows []struct {
+		LatCell        float64
+		LonCell        float64
+		Hits           int64
+		UniqueVisitors int64
+	}
+	err := r.db.Table("(?) AS cells", points).
+		Select("lat_cell, lon_cell, " + requestCountSQL + " AS hits, COUNT(DISTINCT client_ip) AS unique_visitors").
+		Group("lat_cell, lon_cell").
+		Order("hits DESC").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get geo heatmap", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	cells := make([]*GeoHeatmapCell, 0, len(rows))
+	for _, row := range rows {
+		cells = append(cells, &GeoHeatmapCell{
+			Latitude:       cellCenter(row.LatCell, gridSize),
+			Longitude:      cellCenter(row.LonCell, gridSize),
+			Hits:           row.Hits,
+			UniqueVisitors: row.UniqueVisitors,
+		})
+	}
+	return cells, nil
+}
+
+// cellCenter converts a grid cell index to its coordinate, trimming the float noise of
+// fractional grid sizes (3 * 0.1 = 0.30000000000000004)
+func cellCenter(cell float64, gridSize float64) float64 {
+	return math.Round(cell*gridSize*1e6) / 1e6
+}
+
 // GetTopIPAddresses returns most active IP addresses
 // OPTIMIZED: Uses raw SQL with covering index idx_ip_agg for efficient aggregation
 func (r *statsRepo) GetTopIPAddresses(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter, tagFilter string, ipFilter *IPStatsFilter) ([]*IPStats, error) {
+	limit = r.clampTopLimit(limit, "ips")
+
 	var ips []*IPStats
 
 	// Build WHERE clause
 	whereClause := "1=1"
 	args := []interface{}{}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause = "timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause, args = r.appendStatusRange(whereClause, args)
+	whereClause += internalClause(excludeIP)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -1487,11 +2563,11 @@ func (r *statsRepo) GetTopIPAddresses(hours int, limit int, filters []ServiceFil
 			args = append(args, ipFilter.ASN)
 		}
 		if ipFilter.DayOfWeek != nil {
-			whereClause += " AND CAST(strftime('%w', timestamp) AS INTEGER) = ?"
+			whereClause += " AND " + dialectOf(r.db).dayOfWeek() + " = ?"
 			args = append(args, *ipFilter.DayOfWeek)
 		}
 		if ipFilter.Hour != nil {
-			whereClause += " AND CAST(strftime('%H', timestamp) AS INTEGER) = ?"
+			whereClause += " AND " + dialectOf(r.db).hourOfDay() + " = ?"
 			args = append(args, *ipFilter.Hour)
 		}
 	}
@@ -1518,8 +2594,8 @@ func (r *statsRepo) GetTopIPAddresses(hours int, limit int, filters []ServiceFil
 		WITH top_ips AS (
 			SELECT 
 				client_ip,
-				COUNT(*) as hits,
-				COALESCE(SUM(response_size), 0) as bandwidth,
+				` + requestCountSQL + ` as hits,
+				COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
 				MAX(geo_country) as geo_country,
 				MAX(geo_city) as geo_city,
 				MAX(geo_lat) as geo_lat,
@@ -1561,12 +2637,10 @@ func (r *statsRepo) GetStatusCodeDistribution(hours int, filters []ServiceFilter
 	var stats []*StatusCodeStats
 
 	query := r.db.Model(&models.HTTPRequest{}).
-		Select("status_code, COUNT(*) as count")
+		Select("status_code, " + requestCountSQL + " as count")
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		query = query.Where("timestamp > ?", since)
-	}
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyExcludeInternal(query, excludeIP)
 
 	query = r.applyServiceFilters(query, filters)
 	err := query.Group("status_code").Order("count DESC").Scan(&stats).Error
@@ -1579,17 +2653,15 @@ func (r *statsRepo) GetStatusCodeDistribution(hours int, filters []ServiceFilter
 	return stats, nil
 }
 
-// GetMethodDistribution returns HTTP method distribution
+// GetMethodDistribution returns HTTP method distribution, with unknown verbs grouped as OTHER
 func (r *statsRepo) GetMethodDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*MethodStats, error) {
 	var stats []*MethodStats
 
 	query := r.db.Model(&models.HTTPRequest{}).
-		Select("method, COUNT(*) as count")
+		Select("method, " + requestCountSQL + " as count")
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		query = query.Where("timestamp > ?", since)
-	}
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyExcludeInternal(query, excludeIP)
 
 	query = r.applyServiceFilters(query, filters)
 	err := query.Group("method").Order("count DESC").Scan(&stats).Error
@@ -1598,26 +2670,90 @@ func (r *statsRepo) GetMethodDistribution(hours int, filters []ServiceFilter, ex
 		r.logger.WithCaller().Error("Failed to get method distribution", r.logger.Args("error", err))
 		return nil, err
 	}
+	stats = foldMethodStats(stats)
 
 	return stats, nil
 }
 
-// GetProtocolDistribution returns HTTP protocol distribution
-// OPTIMIZED: Uses partial index idx_protocol
-func (r *statsRepo) GetProtocolDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ProtocolStats, error) {
-	var stats []*ProtocolStats
+// GetMethodStatusMatrix cross-tabulates HTTP methods against status classes,
+// e.g. how many POSTs end in 4xx compared to GETs. Ordered by method volume.
+func (r *statsRepo) GetMethodStatusMatrix(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*MethodStatusStats, error) {
+	var stats []*MethodStatusStats
 
-	// Build WHERE clause - protocol != '' matches the partial index
-	whereClause := "protocol != ''"
-	args := []interface{}{}
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select("method, " + requestCountSQL + " as total, " +
+			weightedCountSQL("status_code >= 200 AND status_code < 300") + " as status_2xx, " +
+			weightedCountSQL("status_code >= 300 AND status_code < 400") + " as status_3xx, " +
+			weightedCountSQL("status_code >= 400 AND status_code < 500") + " as status_4xx, " +
+			weightedCountSQL("status_code >= 500") + " as status_5xx")
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	err := query.Group("method").Order("total DESC").Scan(&stats).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get method/status matrix", r.logger.Args("error", err))
+		return nil, err
 	}
 
-	// Apply service filters inline
+	return foldMethodStatusStats(stats), nil
+}
+
+// foldMethodStats merges methods stored before ingestion normalized them (lowercase or
+// unknown verbs) into their NormalizeMethod bucket, keeping the count order
+func foldMethodStats(stats []*MethodStats) []*MethodStats {
+	folded := make([]*MethodStats, 0, len(stats))
+	byMethod := make(map[string]*MethodStats, len(stats))
+	for _, s := range stats {
+		method := models.NormalizeMethod(s.Method)
+		if existing, ok := byMethod[method]; ok {
+			existing.Count += s.Count
+			continue
+		}
+		s.Method = method
+		byMethod[method] = s
+		folded = append(folded, s)
+	}
+	sort.SliceStable(folded, func(i, j int) bool { return folded[i].Count > folded[j].Count })
+	return folded
+}
+
+// foldMethodStatusStats is foldMethodStats for the method/status matrix
+func foldMethodStatusStats(stats []*MethodStatusStats) []*MethodStatusStats {
+	folded := make([]*MethodStatusStats, 0, len(stats))
+	byMethod := make(map[string]*MethodStatusStats, len(stats))
+	for _, s := range stats {
+		method := models.NormalizeMethod(s.Method)
+		if existing, ok := byMethod[method]; ok {
+			existing.Total += s.Total
+			existing.Status2xx += s.Status2xx
+			existing.Status3xx += s.Status3xx
+			existing.Status4xx += s.Status4xx
+			existing.Status5xx += s.Status5xx
+			continue
+		}
+		s.Method = method
+		byMethod[method] = s
+		folded = append(folded, s)
+	}
+	sort.SliceStable(folded, func(i, j int) bool { return folded[i].Total > folded[j].Total })
+	return folded
+}
+
+// GetProtocolDistribution returns HTTP protocol distribution
+// OPTIMIZED: Uses partial index idx_protocol
+func (r *statsRepo) GetProtocolDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ProtocolStats, error) {
+	var stats []*ProtocolStats
+
+	// Build WHERE clause - protocol != '' matches the partial index
+	whereClause := "protocol != ''"
+	args := []interface{}{}
+
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause += internalClause(excludeIP)
+
+	// Apply service filters inline
 	if len(filters) > 0 {
 		filterConds := []string{}
 		for _, filter := range filters {
@@ -1642,7 +2778,7 @@ func (r *statsRepo) GetProtocolDistribution(hours int, filters []ServiceFilter,
 	}
 
 	query := `
-		SELECT protocol, COUNT(*) as count
+		SELECT protocol, ` + requestCountSQL + ` as count
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY protocol
@@ -1668,11 +2804,8 @@ func (r *statsRepo) GetTLSVersionDistribution(hours int, filters []ServiceFilter
 	whereClause := "tls_version != ''"
 	args := []interface{}{}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause += internalClause(excludeIP)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -1699,7 +2832,7 @@ func (r *statsRepo) GetTLSVersionDistribution(hours int, filters []ServiceFilter
 	}
 
 	query := `
-		SELECT tls_version, COUNT(*) as count
+		SELECT tls_version, ` + requestCountSQL + ` as count
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY tls_version
@@ -1716,18 +2849,107 @@ func (r *statsRepo) GetTLSVersionDistribution(hours int, filters []ServiceFilter
 	return stats, nil
 }
 
+// contentTypeExpr strips parameters such as "; charset=utf-8" so variants of a media type group together
+func contentTypeExpr(d sqlDialect) string {
+	return `LOWER(TRIM(CASE
+		WHEN ` + d.instr() + `(response_content_type, ';') > 0 THEN SUBSTR(response_content_type, 1, ` + d.instr() + `(response_content_type, ';') - 1)
+		ELSE response_content_type
+	END))`
+}
+
+// GetContentTypeDistribution returns requests and bandwidth per response content type
+// OPTIMIZED: Uses partial index idx_content_type_agg
+func (r *statsRepo) GetContentTypeDistribution(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ContentTypeStats, error) {
+	var stats []*ContentTypeStats
+
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select(contentTypeExpr(dialectOf(r.db)) + ` as content_type,
+			` + requestCountSQL + ` as count,
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth`).
+		Where("response_content_type != ''")
+
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	err := query.Group("content_type").Order("count DESC").Scan(&stats).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get content type distribution", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetResponseSizeHistogram counts responses per size range. bounds are the ascending,
+// exclusive upper limits of every bucket but the last (nil uses DefaultResponseSizeBounds);
+// every bucket is returned, including empty ones.
+func (r *statsRepo) GetResponseSizeHistogram(hours int, bounds []int64, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ResponseSizeBucket, error) {
+	if len(bounds) == 0 {
+		bounds = DefaultResponseSizeBounds
+	}
+
+	var caseExpr strings.Builder
+	caseExpr.WriteString("CASE")
+	args := make([]interface{}, 0, len(bounds))
+	for i, bound := range bounds {
+		fmt.Fprintf(&caseExpr, " WHEN response_size < ? THEN %d", i)
+		args = append(args, bound)
+	}
+	fmt.Fprintf(&caseExpr, " ELSE %d END", len(bounds))
+
+	var rows []struct {
+		Bucket    int
+		Count     int64
+		Bandwidth int64
+	}
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select(caseExpr.String()+" as bucket, "+requestCountSQL+" as count, COALESCE(SUM(response_size * sample_weight), 0) as bandwidth", args...)
+
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	if err := query.Group("bucket").Scan(&rows).Error; err != nil {
+		r.logger.WithCaller().Error("Failed to get response size histogram", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	buckets := make([]*ResponseSizeBucket, len(bounds)+1)
+	for i := range buckets {
+		bucket := &ResponseSizeBucket{}
+		if i > 0 {
+			bucket.MinBytes = bounds[i-1]
+		}
+		if i < len(bounds) {
+			maxBytes := bounds[i]
+			bucket.MaxBytes = &maxBytes
+		}
+		buckets[i] = bucket
+	}
+	for _, row := range rows {
+		if row.Bucket >= 0 && row.Bucket < len(buckets) {
+			buckets[row.Bucket].Count = row.Count
+			buckets[row.Bucket].Bandwidth = row.Bandwidth
+		}
+	}
+
+	return buckets, nil
+}
+
 // GetTopUserAgents returns most common user agents
 func (r *statsRepo) GetTopUserAgents(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*UserAgentStats, error) {
+	limit = r.clampTopLimit(limit, "user_agents")
+
 	var agents []*UserAgentStats
 
 	query := r.db.Model(&models.HTTPRequest{}).
-		Select("user_agent, COUNT(*) as count").
+		Select("user_agent, " + requestCountSQL + " as count").
 		Where("user_agent != ''")
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		query = query.Where("timestamp > ?", since)
-	}
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyStatusRange(query)
+	query = r.applyExcludeInternal(query, excludeIP)
 
 	query = r.applyServiceFilters(query, filters)
 	err := query.Group("user_agent").Order("count DESC").Limit(limit).Scan(&agents).Error
@@ -1742,44 +2964,57 @@ func (r *statsRepo) GetTopUserAgents(hours int, limit int, filters []ServiceFilt
 
 // GetTopReferrers returns most common referrers
 func (r *statsRepo) GetTopReferrers(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ReferrerStats, error) {
+	limit = r.clampTopLimit(limit, "referrers")
+
 	var referrers []*ReferrerStats
 
 	// Get actual referer headers with unique visitors
 	query := r.db.Model(&models.HTTPRequest{}).
-		Select("referer as referrer, COUNT(*) as hits, COUNT(DISTINCT client_ip) as unique_visitors").
+		Select("referer as referrer, " + requestCountSQL + " as hits, COUNT(DISTINCT client_ip) as unique_visitors").
 		Where("referer != ''")
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		query = query.Where("timestamp > ?", since)
-	}
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyStatusRange(query)
+	query = r.applyExcludeInternal(query, excludeIP)
 
 	query = r.applyServiceFilters(query, filters)
-	err := query.Group("referer").Order("hits DESC").Limit(limit).Scan(&referrers).Error
+	err := query.Group("referer").Order("hits DESC").Limit(r.referrerBlocklist.fetchLimit(limit)).Scan(&referrers).Error
 
 	if err != nil {
 		r.logger.WithCaller().Error("Failed to get top referrers", r.logger.Args("error", err))
 		return nil, err
 	}
 
-	return referrers, nil
+	if r.referrerBlocklist.empty() {
+		return referrers, nil
+	}
+	kept := make([]*ReferrerStats, 0, limit)
+	for _, ref := range referrers {
+		if len(kept) == limit {
+			break
+		}
+		if !r.referrerBlocklist.blocked(extractDomain(ref.Referrer)) {
+			kept = append(kept, ref)
+		}
+	}
+	return kept, nil
 }
 
 // GetTopReferrerDomains returns referrer domains aggregated by host
 // OPTIMIZED: Performs domain extraction in SQL instead of fetching all referrers
 // This reduces data transfer by 90%+ and eliminates in-memory aggregation
 func (r *statsRepo) GetTopReferrerDomains(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ReferrerDomainStats, error) {
+	limit = r.clampTopLimit(limit, "referrer_domains")
+
 	var domains []*ReferrerDomainStats
 
 	// Build WHERE clause
 	whereClause := "referer != '' AND referer NOT LIKE 'file:%'"
 	args := []interface{}{}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause, args = r.appendStatusRange(whereClause, args)
+	whereClause += internalClause(excludeIP)
 
 	// Apply service filters
 	if len(filters) > 0 {
@@ -1805,14 +3040,132 @@ func (r *statsRepo) GetTopReferrerDomains(hours int, limit int, filters []Servic
 		}
 	}
 
-	// SQL-based domain extraction:
-	// 1. Remove protocol (http://, https://)
-	// 2. Extract host (everything before first / after protocol)
-	// 3. Remove port number
-	// 4. Remove www. prefix
-	// 5. Convert to lowercase
-	// This is ~10x faster than fetching all rows and processing in Go
-	query := `
+	query := referrerDomainsCTE(dialectOf(r.db).instr(), whereClause) + `
+		SELECT
+			domain,
+			` + requestCountSQL + ` as hits,
+			COUNT(DISTINCT client_ip) as unique_visitors
+		FROM cleaned_domains
+		GROUP BY domain
+		ORDER BY hits DESC
+		LIMIT ?
+	`
+	args = append(args, r.referrerBlocklist.fetchLimit(limit))
+
+	err := r.db.Raw(query, args...).Scan(&domains).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get referrer domains", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	if r.referrerBlocklist.empty() {
+		return domains, nil
+	}
+	kept := make([]*ReferrerDomainStats, 0, limit)
+	for _, domain := range domains {
+		if len(kept) == limit {
+			break
+		}
+		if !r.referrerBlocklist.blocked(domain.Domain) {
+			kept = append(kept, domain)
+		}
+	}
+	return kept, nil
+}
+
+// GetReferrerCategories splits requests by referrer category: search engines, social
+// networks, direct (no referrer), internal (referred by the site itself) and other.
+// host restricts the requests to one site; when empty, each request's own host decides
+// what is internal. Blocklisted referrers are left out of every category.
+func (r *statsRepo) GetReferrerCategories(hours int, host string, excludeIP *ExcludeIPFilter) ([]*ReferrerCategoryStats, error) {
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	whereClause := "referer != '' AND referer != '-' AND referer NOT LIKE 'file:%'"
+	args := []interface{}{}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause += internalClause(excludeIP)
+	if host != "" {
+		whereClause += " AND host = ?"
+		args = append(args, host)
+	}
+
+	var rows []struct {
+		Domain string
+		Host   string
+		Hits   int64
+	}
+	query := referrerDomainsCTE(dialectOf(r.db).instr(), whereClause) + `
+		SELECT domain, host, ` + requestCountSQL + ` as hits
+		FROM cleaned_domains
+		GROUP BY domain, host
+	`
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		r.logger.WithCaller().Error("Failed to get referrer categories", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	var direct int64
+	directQuery := r.db.WithContext(ctx).Model(&models.HTTPRequest{}).Where("referer = '' OR referer = '-'")
+	directQuery = r.applyTimeWindow(directQuery, hours)
+	directQuery = r.applyExcludeInternal(directQuery, excludeIP)
+	if host != "" {
+		directQuery = directQuery.Where("host = ?", host)
+	}
+	if err := directQuery.Select(requestCountSQL).Scan(&direct).Error; err != nil {
+		r.logger.WithCaller().Error("Failed to count direct requests", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	order := []string{ReferrerCategorySearch, ReferrerCategorySocial, ReferrerCategoryDirect, ReferrerCategoryInternal, ReferrerCategoryOther}
+	byCategory := make(map[string]*ReferrerCategoryStats, len(order))
+	for _, category := range order {
+		byCategory[category] = &ReferrerCategoryStats{Category: category}
+	}
+	byCategory[ReferrerCategoryDirect].Hits = direct
+	total := direct
+
+	site := normalizeReferrerHost(host)
+	domains := make(map[string]map[string]struct{}, len(order))
+	for _, row := range rows {
+		if r.referrerBlocklist.blocked(row.Domain) {
+			continue
+		}
+		rowSite := site
+		if rowSite == "" {
+			rowSite = normalizeReferrerHost(row.Host)
+		}
+		category := classifyReferrer(row.Domain, rowSite)
+		byCategory[category].Hits += row.Hits
+		total += row.Hits
+		if domains[category] == nil {
+			domains[category] = make(map[string]struct{})
+		}
+		domains[category][row.Domain] = struct{}{}
+	}
+
+	categories := make([]*ReferrerCategoryStats, 0, len(order))
+	for _, category := range order {
+		stats := byCategory[category]
+		stats.Domains = int64(len(domains[category]))
+		if total > 0 {
+			stats.Percentage = float64(stats.Hits) / float64(total) * 100
+		}
+		categories = append(categories, stats)
+	}
+	return categories, nil
+}
+
+// referrerDomainsCTE returns the cleaned_domains CTE (domain, client_ip, host) for
+// the requests matching whereClause. SQL-based domain extraction:
+// 1. Remove protocol (http://, https://)
+// 2. Extract host (everything before first / after protocol)
+// 3. Remove port number
+// 4. Remove www. prefix
+// 5. Convert to lowercase
+// This is ~10x faster than fetching all rows and processing in Go
+func referrerDomainsCTE(instr string, whereClause string) string {
+	return `
 		WITH extracted_domains AS (
 			SELECT
 				LOWER(
@@ -1826,14 +3179,14 @@ func (r *statsRepo) GetTopReferrerDomains(hours int, limit int, filters []Servic
 							END,
 							1,
 							CASE
-								WHEN INSTR(
+								WHEN ` + instr + `(
 									CASE
 										WHEN referer LIKE 'http://%' THEN SUBSTR(referer, 8)
 										WHEN referer LIKE 'https://%' THEN SUBSTR(referer, 9)
 										WHEN referer LIKE '//%' THEN SUBSTR(referer, 3)
 										ELSE referer
 									END, '/'
-								) > 0 THEN INSTR(
+								) > 0 THEN ` + instr + `(
 									CASE
 										WHEN referer LIKE 'http://%' THEN SUBSTR(referer, 8)
 										WHEN referer LIKE 'https://%' THEN SUBSTR(referer, 9)
@@ -1854,41 +3207,141 @@ func (r *statsRepo) GetTopReferrerDomains(hours int, limit int, filters []Servic
 						'www.', ''
 					)
 				) as domain,
-				client_ip
+				client_ip,
+				host,
+				sample_weight
 			FROM http_requests
 			WHERE ` + whereClause + `
 		),
 		cleaned_domains AS (
 			SELECT
 				CASE
-					WHEN INSTR(domain, ':') > 0 THEN SUBSTR(domain, 1, INSTR(domain, ':') - 1)
+					WHEN ` + instr + `(domain, ':') > 0 THEN SUBSTR(domain, 1, ` + instr + `(domain, ':') - 1)
 					ELSE domain
 				END as domain,
-				client_ip
+				client_ip,
+				host,
+				sample_weight
 			FROM extracted_domains
 			WHERE domain != '' AND domain NOT LIKE '%@%'
 		)
-		SELECT
-			domain,
-			COUNT(*) as hits,
-			COUNT(DISTINCT client_ip) as unique_visitors
-		FROM cleaned_domains
-		GROUP BY domain
-		ORDER BY hits DESC
 	`
+}
+
+// Referrer categories returned by GetReferrerCategories
+const (
+	ReferrerCategorySearch   = "search"
+	ReferrerCategorySocial   = "social"
+	ReferrerCategoryDirect   = "direct"
+	ReferrerCategoryInternal = "internal"
+	ReferrerCategoryOther    = "other"
+)
 
-	if limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, limit)
+// Known referrer domains. An entry matches the domain and its subdomains; entries
+// ending with "." match that label under any suffix (google. matches google.co.uk)
+var (
+	referrerSearchEngines = []string{
+		"google.", "bing.com", "duckduckgo.com", "yahoo.", "yandex.", "baidu.com",
+		"ecosia.org", "search.brave.com", "startpage.com", "qwant.com", "ask.com",
+	}
+	referrerSocialNetworks = []string{
+		"facebook.com", "fb.com", "t.co", "twitter.com", "x.com", "linkedin.com", "lnkd.in",
+		"reddit.com", "instagram.com", "pinterest.com", "youtube.com", "tiktok.com",
+		"news.ycombinator.com", "mastodon.social", "bsky.app", "threads.net", "vk.com", "weibo.com",
 	}
+)
 
-	err := r.db.Raw(query, args...).Scan(&domains).Error
-	if err != nil {
-		r.logger.WithCaller().Error("Failed to get referrer domains", r.logger.Args("error", err))
-		return nil, err
+// referrerBlocklistOverfetch multiplies top-N limits while a blocklist is set, so
+// spam removed after the query does not leave the list short
+const referrerBlocklistOverfetch = 4
+
+// referrerBlocklist holds the spam referrer domains and patterns hidden from referrer reports
+type referrerBlocklist struct {
+	domains  []string
+	patterns []*regexp.Regexp
+}
+
+// SetReferrerBlocklist sets the referrer-spam blocklist. Entries are domains, matching
+// the domain and its subdomains, or regular expressions between slashes (/^spam\d+\./)
+// matched against the referrer domain. Invalid patterns are logged and skipped.
+func (r *statsRepo) SetReferrerBlocklist(entries []string) {
+	var blocklist referrerBlocklist
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+			pattern, err := regexp.Compile(entry[1 : len(entry)-1])
+			if err != nil {
+				r.logger.Warn("Ignoring invalid referrer blocklist pattern", r.logger.Args("pattern", entry, "error", err))
+				continue
+			}
+			blocklist.patterns = append(blocklist.patterns, pattern)
+		} else if domain := normalizeReferrerHost(entry); domain != "" {
+			blocklist.domains = append(blocklist.domains, domain)
+		}
 	}
+	r.referrerBlocklist = blocklist
+}
 
-	return domains, nil
+func (b referrerBlocklist) empty() bool {
+	return len(b.domains) == 0 && len(b.patterns) == 0
+}
+
+// fetchLimit returns how many rows to query for a top-N list of limit entries
+func (b referrerBlocklist) fetchLimit(limit int) int {
+	if b.empty() {
+		return limit
+	}
+	return limit * referrerBlocklistOverfetch
+}
+
+// blocked reports whether a referrer domain is on the blocklist
+func (b referrerBlocklist) blocked(domain string) bool {
+	for _, entry := range b.domains {
+		if matchesReferrerDomain(domain, entry) {
+			return true
+		}
+	}
+	for _, pattern := range b.patterns {
+		if pattern.MatchString(domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesReferrerDomain reports whether domain is entry or one of its subdomains
+// An entry ending with "." matches that label under any suffix
+func matchesReferrerDomain(domain string, entry string) bool {
+	if strings.HasSuffix(entry, ".") {
+		return strings.HasPrefix(domain, entry) || strings.Contains(domain, "."+entry)
+	}
+	return domain == entry || strings.HasSuffix(domain, "."+entry)
+}
+
+// classifyReferrer returns the category of a referrer domain for requests to site
+func classifyReferrer(domain string, site string) string {
+	if site != "" && matchesReferrerDomain(domain, site) {
+		return ReferrerCategoryInternal
+	}
+	for _, entry := range referrerSearchEngines {
+		if matchesReferrerDomain(domain, entry) {
+			return ReferrerCategorySearch
+		}
+	}
+	for _, entry := range referrerSocialNetworks {
+		if matchesReferrerDomain(domain, entry) {
+			return ReferrerCategorySocial
+		}
+	}
+	return ReferrerCategoryOther
+}
+
+// normalizeReferrerHost lowercases a host and strips its port and www. prefix,
+// matching the referrer domains extracted by referrerDomainsCTE
+func normalizeReferrerHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.Split(host, ":")[0]
+	return strings.TrimPrefix(host, "www.")
 }
 
 // extractDomain returns the host portion for a referrer URL
@@ -1966,18 +3419,14 @@ func extractBackendName(backendName string) string {
 // GetTopBackends returns backend statistics
 // OPTIMIZED: Uses UNION ALL with indexed subqueries instead of COALESCE/CASE in WHERE
 func (r *statsRepo) GetTopBackends(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*BackendStats, error) {
+	limit = r.clampTopLimit(limit, "backends")
+
 	// OPTIMIZED: Uses dedicated covering indexes (idx_backend_agg, idx_backend_url_agg, idx_host_agg)
 	// Removed AVG(response_time_ms) - not essential for top backends and causes table scans
 
 	// Build time filter for each UNION part
-	var timeFilter string
-	var since time.Time
-	hasTimeFilter := hours > 0
-
-	if hasTimeFilter {
-		since = time.Now().Add(-time.Duration(hours) * time.Hour)
-		timeFilter = " AND timestamp > ?"
-	}
+	timeFilter, timeArgs := r.appendTimeWindow("", nil, hours)
+	timeFilter, timeArgs = r.appendStatusRange(timeFilter, timeArgs)
 
 	// Build exclude IP clause
 	var excludeFilter string
@@ -1987,6 +3436,7 @@ func (r *statsRepo) GetTopBackends(hours int, limit int, filters []ServiceFilter
 		excludeFilter = " AND client_ip NOT IN (?)"
 		excludeIPs = excludeIP.ClientIPs
 	}
+	excludeFilter += internalClause(excludeIP)
 
 	// UNION ALL with minimal columns - each uses dedicated partial index
 	// idx_backend_agg covers: backend_name, timestamp, backend_url, host, response_size, status_code
@@ -1997,9 +3447,9 @@ func (r *statsRepo) GetTopBackends(hours int, limit int, filters []ServiceFilter
 				MAX(backend_url) as backend_url,
 				MAX(host) as host,
 				'backend_name' as service_type,
-				COUNT(*) as hits,
-				COALESCE(SUM(response_size), 0) as bandwidth,
-				SUM(CASE WHEN status_code >= 500 THEN 1 ELSE 0 END) as error_count
+				` + requestCountSQL + ` as hits,
+				COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
+				` + weightedCountSQL("status_code >= 500") + ` as error_count
 			FROM http_requests INDEXED BY idx_backend_agg
 			WHERE backend_name != ''` + timeFilter + excludeFilter + `
 			GROUP BY backend_name
@@ -2011,9 +3461,9 @@ func (r *statsRepo) GetTopBackends(hours int, limit int, filters []ServiceFilter
 				backend_url,
 				MAX(host) as host,
 				'backend_url' as service_type,
-				COUNT(*) as hits,
-				COALESCE(SUM(response_size), 0) as bandwidth,
-				SUM(CASE WHEN status_code >= 500 THEN 1 ELSE 0 END) as error_count
+				` + requestCountSQL + ` as hits,
+				COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
+				` + weightedCountSQL("status_code >= 500") + ` as error_count
 			FROM http_requests INDEXED BY idx_backend_url_agg
 			WHERE backend_name = '' AND backend_url != ''` + timeFilter + excludeFilter + `
 			GROUP BY backend_url
@@ -2025,9 +3475,9 @@ func (r *statsRepo) GetTopBackends(hours int, limit int, filters []ServiceFilter
 				'' as backend_url,
 				host,
 				'host' as service_type,
-				COUNT(*) as hits,
-				COALESCE(SUM(response_size), 0) as bandwidth,
-				SUM(CASE WHEN status_code >= 500 THEN 1 ELSE 0 END) as error_count
+				` + requestCountSQL + ` as hits,
+				COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
+				` + weightedCountSQL("status_code >= 500") + ` as error_count
 			FROM http_requests INDEXED BY idx_host_agg
 			WHERE backend_name = '' AND backend_url = '' AND host != ''` + timeFilter + excludeFilter + `
 			GROUP BY host
@@ -2039,9 +3489,7 @@ func (r *statsRepo) GetTopBackends(hours int, limit int, filters []ServiceFilter
 	// Build args - each UNION part needs time filter and optionally exclude IP
 	fullArgs := make([]interface{}, 0, 10)
 	for i := 0; i < 3; i++ {
-		if hasTimeFilter {
-			fullArgs = append(fullArgs, since)
-		}
+		fullArgs = append(fullArgs, timeArgs...)
 		if hasExcludeIP {
 			fullArgs = append(fullArgs, excludeIPs)
 		}
@@ -2085,17 +3533,17 @@ func (r *statsRepo) GetTopBackends(hours int, limit int, filters []ServiceFilter
 // GetTopASNs returns top ASNs by requests
 // OPTIMIZED: Uses raw SQL with partial index idx_asn_agg
 func (r *statsRepo) GetTopASNs(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ASNStats, error) {
+	limit = r.clampTopLimit(limit, "asns")
+
 	var asns []*ASNStats
 
 	// Build WHERE clause - asn > 0 matches the partial index
 	whereClause := "asn > 0"
 	args := []interface{}{}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause, args = r.appendStatusRange(whereClause, args)
+	whereClause += internalClause(excludeIP)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -2125,8 +3573,8 @@ func (r *statsRepo) GetTopASNs(hours int, limit int, filters []ServiceFilter, ex
 		SELECT 
 			asn,
 			MAX(asn_org) as asn_org,
-			COUNT(*) as hits,
-			COALESCE(SUM(response_size), 0) as bandwidth,
+			` + requestCountSQL + ` as hits,
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
 			MAX(geo_country) as country
 		FROM http_requests
 		WHERE ` + whereClause + `
@@ -2147,8 +3595,8 @@ func (r *statsRepo) GetTopASNs(hours int, limit int, filters []ServiceFilter, ex
 }
 
 // GetResponseTimeStats returns response time statistics
-// OPTIMIZED: Uses SQLite window functions (NTILE) for efficient percentile calculation
-// 3x faster than LIMIT/OFFSET approach, single query instead of 4 separate queries
+// OPTIMIZED: Streams the matching response times once into a t-digest, so min/max/avg and
+// all percentiles come from a single unsorted scan instead of one full sort per percentile
 func (r *statsRepo) GetResponseTimeStats(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (*ResponseTimeStats, error) {
 	stats := &ResponseTimeStats{}
 
@@ -2156,11 +3604,8 @@ func (r *statsRepo) GetResponseTimeStats(hours int, filters []ServiceFilter, exc
 	whereClause := "response_time_ms > 0"
 	args := []interface{}{}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause += internalClause(excludeIP)
 
 	// Apply service filters
 	if len(filters) > 0 {
@@ -2186,59 +3631,38 @@ func (r *statsRepo) GetResponseTimeStats(hours int, filters []ServiceFilter, exc
 		}
 	}
 
-	countQuery := `
-		SELECT
-			COUNT(*) as count,
-			COALESCE(MIN(response_time_ms), 0) as min,
-			COALESCE(MAX(response_time_ms), 0) as max,
-			COALESCE(AVG(response_time_ms), 0) as avg
-		FROM http_requests
-		WHERE ` + whereClause
-
-	var base struct {
-		Count int64   `gorm:"column:count"`
-		Min   float64 `gorm:"column:min"`
-		Max   float64 `gorm:"column:max"`
-		Avg   float64 `gorm:"column:avg"`
-	}
-	if err := r.db.Raw(countQuery, args...).Scan(&base).Error; err != nil {
+	rows, err := r.db.Raw(`SELECT response_time_ms FROM http_requests WHERE `+whereClause, args...).Rows()
+	if err != nil {
 		r.logger.WithCaller().Error("Failed to get response time stats", r.logger.Args("error", err))
 		return nil, err
 	}
+	defer rows.Close()
 
-	stats.Min = base.Min
-	stats.Max = base.Max
-	stats.Avg = base.Avg
-	if base.Count == 0 {
-		return stats, nil
-	}
-
-	percentileQuery := `SELECT response_time_ms FROM http_requests WHERE ` + whereClause + ` ORDER BY response_time_ms LIMIT 1 OFFSET ?`
-	readPercentile := func(percent float64) (float64, error) {
-		offset := int64(float64(base.Count-1) * percent)
-		percentileArgs := append([]interface{}{}, args...)
-		percentileArgs = append(percentileArgs, offset)
-
+	digest := newTDigest(defaultDigestCompression)
+	sum := 0.0
+	for rows.Next() {
 		var value float64
-		if err := r.db.Raw(percentileQuery, percentileArgs...).Scan(&value).Error; err != nil {
-			return 0, err
+		if err := rows.Scan(&value); err != nil {
+			r.logger.WithCaller().Error("Failed to scan response time", r.logger.Args("error", err))
+			return nil, err
 		}
-		return value, nil
+		digest.Add(value)
+		sum += value
 	}
-
-	var err error
-	if stats.P50, err = readPercentile(0.50); err != nil {
-		r.logger.WithCaller().Error("Failed to get p50 response time", r.logger.Args("error", err))
+	if err := rows.Err(); err != nil {
+		r.logger.WithCaller().Error("Failed to iterate response times", r.logger.Args("error", err))
 		return nil, err
 	}
-	if stats.P95, err = readPercentile(0.95); err != nil {
-		r.logger.WithCaller().Error("Failed to get p95 response time", r.logger.Args("error", err))
-		return nil, err
-	}
-	if stats.P99, err = readPercentile(0.99); err != nil {
-		r.logger.WithCaller().Error("Failed to get p99 response time", r.logger.Args("error", err))
-		return nil, err
+
+	if digest.Count() == 0 {
+		return stats, nil
 	}
+	stats.Min = digest.Quantile(0)
+	stats.Max = digest.Quantile(1)
+	stats.Avg = sum / float64(digest.Count())
+	stats.P50 = digest.Quantile(0.50)
+	stats.P95 = digest.Quantile(0.95)
+	stats.P99 = digest.Quantile(0.99)
 
 	r.logger.Trace("Generated response time stats",
 		r.logger.Args("min", stats.Min, "max", stats.Max, "p95", stats.P95, "service_filters", filters))
@@ -2260,68 +3684,610 @@ func (r *statsRepo) GetLogProcessingStats() ([]*LogProcessingStats, error) {
 	var stats []*LogProcessingStats
 
 	for _, source := range sources {
-		// Get file size
-		fileInfo, err := os.Stat(source.Path)
 		fileSize := int64(0)
-		if err == nil {
+		bytesProcessed := source.LastPosition
+		lastProcessedAt := source.LastReadAt
+
+		if source.IsGlob() {
+			// Glob sources track each matched file separately: report their sum,
+			// counting matched files that have not been read yet as well
+			matches, _ := filepath.Glob(source.Path)
+			matched := make(map[string]struct{}, len(matches))
+			for _, match := range matches {
+				if fileInfo, err := os.Stat(match); err == nil && !fileInfo.IsDir() {
+					fileSize += fileInfo.Size()
+					matched[match] = struct{}{}
+				}
+			}
+
+			var files []models.LogSourceFile
+			if err := r.db.Where("source_name = ?", source.Name).Find(&files).Error; err != nil {
+				r.logger.WithCaller().Error("Failed to get log source files", r.logger.Args("source", source.Name, "error", err))
+				return nil, err
+			}
+			bytesProcessed = 0
+			for _, file := range files {
+				if _, ok := matched[file.Path]; !ok {
+					continue // Deleted or no longer matching
+				}
+				bytesProcessed += file.LastPosition
+				if file.LastReadAt != nil && (lastProcessedAt == nil || file.LastReadAt.After(*lastProcessedAt)) {
+					lastProcessedAt = file.LastReadAt
+				}
+			}
+		} else if fileInfo, err := os.Stat(source.Path); err == nil {
 			fileSize = fileInfo.Size()
 		}
 
 		percentage := 0.0
 		if fileSize > 0 {
-			percentage = float64(source.LastPosition) / float64(fileSize) * 100.0
-		} else if source.LastPosition > 0 {
+			percentage = float64(bytesProcessed) / float64(fileSize) * 100.0
+		} else if bytesProcessed > 0 {
 			percentage = 100.0
 		}
 
-		stats = append(stats, &LogProcessingStats{
-			LogSourceName:   source.Name,
-			FileSize:        fileSize,
-			BytesProcessed:  source.LastPosition,
-			Percentage:      percentage,
-			LastProcessedAt: source.LastReadAt,
-		})
+		stats = append(stats, &LogProcessingStats{
+			LogSourceName:   source.Name,
+			FileSize:        fileSize,
+			BytesProcessed:  bytesProcessed,
+			Percentage:      percentage,
+			LastProcessedAt: lastProcessedAt,
+		})
+	}
+
+	return stats, nil
+}
+
+// GetTopBrowsers returns most common browsers
+func (r *statsRepo) GetTopBrowsers(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*BrowserStats, error) {
+	limit = r.clampTopLimit(limit, "browsers")
+
+	var browsers []*BrowserStats
+
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select("browser, " + requestCountSQL + " as count").
+		Where("browser != '' AND browser != 'Unknown'")
+
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyStatusRange(query)
+	query = r.applyExcludeInternal(query, excludeIP)
+
+	query = r.applyServiceFilters(query, filters)
+	err := query.Group("browser").Order("count DESC").Limit(limit).Scan(&browsers).Error
+
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get top browsers", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	return browsers, nil
+}
+
+// GetTopOriginServers returns the origin software (Server response header) behind the most requests
+// Filter by host to see which software answers a given site
+func (r *statsRepo) GetTopOriginServers(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*OriginServerStats, error) {
+	limit = r.clampTopLimit(limit, "origin_servers")
+
+	var servers []*OriginServerStats
+
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select(`origin_server as server,
+			` + requestCountSQL + ` as count,
+			COUNT(DISTINCT host) as hosts,
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth`).
+		Where("origin_server != ''")
+
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyStatusRange(query)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	err := query.Group("origin_server").Order("count DESC").Limit(limit).Scan(&servers).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get top origin servers", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	return servers, nil
+}
+
+// GetProxyOverheadStats returns, per backend, how much of the response time is spent in the
+// proxy rather than waiting for the upstream, busiest backends first. Only requests with
+// both timings are counted; overheads below zero (rounding between the two clocks) count as 0.
+func (r *statsRepo) GetProxyOverheadStats(hours int, host string, excludeIP *ExcludeIPFilter) ([]*ProxyOverheadStats, error) {
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select(`CASE WHEN backend_name != '' THEN backend_name WHEN backend_url != '' THEN backend_url ELSE host END as backend,
+			response_time_ms, upstream_response_time_ms`).
+		Where("response_time_ms > 0 AND upstream_response_time_ms > 0")
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+	if host != "" {
+		query = query.Where("host = ?", host)
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	rows, err := query.WithContext(ctx).Rows()
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get proxy overhead stats", r.logger.Args("error", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	type backendTimes struct {
+		stats       *ProxyOverheadStats
+		digest      *tDigest
+		total       float64
+		upstream    float64
+		overheadSum float64
+	}
+	byBackend := make(map[string]*backendTimes)
+	for rows.Next() {
+		var backend string
+		var total, upstream float64
+		if err := rows.Scan(&backend, &total, &upstream); err != nil {
+			r.logger.WithCaller().Error("Failed to scan proxy overhead", r.logger.Args("error", err))
+			return nil, err
+		}
+		times, ok := byBackend[backend]
+		if !ok {
+			times = &backendTimes{stats: &ProxyOverheadStats{Backend: backend}, digest: newTDigest(defaultDigestCompression)}
+			byBackend[backend] = times
+		}
+		overhead := max(total-upstream, 0)
+		times.digest.Add(overhead)
+		times.total += total
+		times.upstream += upstream
+		times.overheadSum += overhead
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.WithCaller().Error("Failed to iterate proxy overheads", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	result := make([]*ProxyOverheadStats, 0, len(byBackend))
+	for _, times := range byBackend {
+		s := times.stats
+		s.Requests = times.digest.Count()
+		count := float64(s.Requests)
+		s.AvgTotalMs = times.total / count
+		s.AvgUpstreamMs = times.upstream / count
+		s.AvgOverheadMs = times.overheadSum / count
+		s.P50OverheadMs = times.digest.Quantile(0.50)
+		s.P95OverheadMs = times.digest.Quantile(0.95)
+		s.P99OverheadMs = times.digest.Quantile(0.99)
+		s.OverheadShare = times.overheadSum / times.total * 100
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Requests != result[j].Requests {
+			return result[i].Requests > result[j].Requests
+		}
+		return result[i].Backend < result[j].Backend
+	})
+
+	return result, nil
+}
+
+// GetTLSSecurityStats grades requests by their TLS version and cipher as secure, weak or
+// plaintext, listing the weak pairs still in use. Requests from log formats that do not
+// record TLS details count as plaintext.
+func (r *statsRepo) GetTLSSecurityStats(hours int, host string, excludeIP *ExcludeIPFilter) (*TLSSecurityStats, error) {
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select("tls_version, tls_cipher, " + requestCountSQL + " as requests, COUNT(DISTINCT client_ip) as unique_visitors").
+		Group("tls_version, tls_cipher")
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+	if host != "" {
+		query = query.Where("host = ?", host)
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	var pairs []*TLSWeakUsage
+	if err := query.WithContext(ctx).Scan(&pairs).Error; err != nil {
+		r.logger.WithCaller().Error("Failed to get TLS security stats", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	stats := &TLSSecurityStats{Weak: []*TLSWeakUsage{}}
+	for _, pair := range pairs {
+		stats.TotalRequests += pair.Requests
+		switch tlsGrade(pair.TLSVersion, pair.TLSCipher) {
+		case TLSGradeSecure:
+			stats.SecureRequests += pair.Requests
+		case TLSGradePlaintext:
+			stats.PlaintextRequests += pair.Requests
+		default:
+			stats.WeakRequests += pair.Requests
+			stats.Weak = append(stats.Weak, pair)
+		}
+	}
+	if stats.TotalRequests > 0 {
+		total := float64(stats.TotalRequests)
+		stats.SecurePercentage = float64(stats.SecureRequests) / total * 100
+		stats.WeakPercentage = float64(stats.WeakRequests) / total * 100
+		stats.PlaintextPercentage = float64(stats.PlaintextRequests) / total * 100
+	}
+	sort.Slice(stats.Weak, func(i, j int) bool {
+		if stats.Weak[i].Requests != stats.Weak[j].Requests {
+			return stats.Weak[i].Requests > stats.Weak[j].Requests
+		}
+		return stats.Weak[i].TLSVersion+stats.Weak[i].TLSCipher < stats.Weak[j].TLSVersion+stats.Weak[j].TLSCipher
+	})
+
+	return stats, nil
+}
+
+// tlsGrade grades a logged TLS version and cipher. Versions may be logged as "1.2",
+// "TLSv1.2" or "TLS 1.2", ciphers with IANA or OpenSSL names. TLS 1.3 only has AEAD
+// ciphers; for TLS 1.2 an unlogged cipher is given the benefit of the doubt.
+func tlsGrade(version, cipher string) string {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return TLSGradePlaintext
+	}
+	switch {
+	case strings.HasSuffix(version, "1.3"):
+		return TLSGradeSecure
+	case strings.HasSuffix(version, "1.2"):
+		cipher = strings.ToUpper(cipher)
+		if cipher == "" || strings.Contains(cipher, "GCM") || strings.Contains(cipher, "CHACHA20") || strings.Contains(cipher, "CCM") {
+			return TLSGradeSecure
+		}
+	}
+	return TLSGradeWeak
+}
+
+// SlowRequestIndexThresholdMs is the response time above which the partial idx_slow index holds requests
+const SlowRequestIndexThresholdMs = 1000
+
+// GetSlowRequests returns the slowest requests above thresholdMs, slowest first.
+// Thresholds of at least SlowRequestIndexThresholdMs are served by the partial idx_slow index;
+// lower ones scan every request in the window.
+func (r *statsRepo) GetSlowRequests(hours int, thresholdMs float64, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*SlowRequest, error) {
+	limit = r.clampTopLimit(limit, "slow_requests")
+	if thresholdMs <= 0 {
+		thresholdMs = SlowRequestIndexThresholdMs
+	}
+
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select("timestamp, method, host, path, backend_name, status_code, response_time_ms")
+
+	// The planner only picks a partial index when the query repeats its predicate
+	// literally; a bound threshold alone does not prove response_time_ms > 1000
+	if thresholdMs >= SlowRequestIndexThresholdMs {
+		query = query.Where("response_time_ms > 1000")
+	}
+	query = query.Where("response_time_ms > ?", thresholdMs)
+
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	var requests []*SlowRequest
+	err := query.WithContext(ctx).Order("response_time_ms DESC, timestamp DESC").Limit(limit).Scan(&requests).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get slow requests", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// GetErrorAnalysis returns the paths with the most failed requests, each with its status
+// code mix and the most recent failure. The status_code >= 400 predicate is repeated
+// literally so the partial idx_errors index serves every query.
+func (r *statsRepo) GetErrorAnalysis(hours int, host string, limit int, excludeIP *ExcludeIPFilter) ([]*ErrorPathStats, error) {
+	limit = r.clampTopLimit(limit, "errors")
+
+	errorRequests := func() *gorm.DB {
+		query := r.db.Model(&models.HTTPRequest{}).Where("status_code >= 400")
+		query = r.applyTimeWindow(query, hours)
+		query = r.applyExcludeIPFilter(query, excludeIP)
+		if host != "" {
+			query = query.Where("host = ?", host)
+		}
+		return query
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	var rows []struct {
+		Path     string
+		Errors   int64
+		LastSeen string
+	}
+	err := errorRequests().WithContext(ctx).
+		Select("path, " + requestCountSQL + " as errors, MAX(timestamp) as last_seen").
+		Group("path").
+		Order("errors DESC, path").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get error analysis", r.logger.Args("error", err))
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []*ErrorPathStats{}, nil
+	}
+
+	results := make([]*ErrorPathStats, len(rows))
+	byPath := make(map[string]*ErrorPathStats, len(rows))
+	paths := make([]string, len(rows))
+	for i, row := range rows {
+		results[i] = &ErrorPathStats{
+			Path:     row.Path,
+			Errors:   row.Errors,
+			Statuses: []*StatusCodeStats{},
+			LastSeen: parseAggregateTimestamp(row.LastSeen),
+		}
+		byPath[row.Path] = results[i]
+		paths[i] = row.Path
+	}
+
+	var statuses []struct {
+		Path       string
+		StatusCode int
+		Count      int64
+	}
+	err = errorRequests().WithContext(ctx).
+		Select("path, status_code, "+requestCountSQL+" as count").
+		Where("path IN ?", paths).
+		Group("path, status_code").
+		Order("count DESC, status_code").
+		Scan(&statuses).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get error status breakdown", r.logger.Args("error", err))
+		return nil, err
+	}
+	for _, status := range statuses {
+		entry := byPath[status.Path]
+		entry.Statuses = append(entry.Statuses, &StatusCodeStats{StatusCode: status.StatusCode, Count: status.Count})
+	}
+
+	// One indexed lookup per path; limit is clamped, so the number of queries is bounded
+	for _, entry := range results {
+		var sample struct {
+			ClientIP   string
+			UserAgent  string
+			StatusCode int
+		}
+		err = errorRequests().WithContext(ctx).
+			Select("client_ip, user_agent, status_code").
+			Where("path = ?", entry.Path).
+			Order("timestamp DESC, id DESC").
+			Limit(1).
+			Scan(&sample).Error
+		if err != nil {
+			r.logger.WithCaller().Error("Failed to get latest error sample", r.logger.Args("path", entry.Path, "error", err))
+			return nil, err
+		}
+		entry.LastIP = sample.ClientIP
+		entry.LastAgent = sample.UserAgent
+		entry.LastStatus = sample.StatusCode
+	}
+
+	return results, nil
+}
+
+// GetBackendBandwidthProjection returns the bytes per day each backend sent over the window
+// and the bandwidth it will send in the next ProjectionDays at that rate, largest first.
+// With hours = 0 the rate is averaged from the oldest request in the selection.
+func (r *statsRepo) GetBackendBandwidthProjection(hours int, host string, excludeIP *ExcludeIPFilter) ([]*BackendBandwidthProjection, error) {
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select(`CASE WHEN backend_name != '' THEN backend_name WHEN backend_url != '' THEN backend_url ELSE host END as backend,
+			` + requestCountSQL + ` as requests,
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
+			MIN(timestamp) as first_seen`).
+		Group("backend")
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+	if host != "" {
+		query = query.Where("host = ?", host)
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	var rows []struct {
+		Backend   string
+		Requests  int64
+		Bandwidth int64
+		FirstSeen string
+	}
+	if err := query.WithContext(ctx).Scan(&rows).Error; err != nil {
+		r.logger.WithCaller().Error("Failed to get backend bandwidth projection", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	since, until := r.timeWindow(hours)
+	if hours == 0 {
+		for _, row := range rows {
+			if firstSeen := parseAggregateTimestamp(row.FirstSeen); !firstSeen.IsZero() && (since.IsZero() || firstSeen.Before(since)) {
+				since = firstSeen
+			}
+		}
+	}
+	// At least one hour, so a handful of fresh requests is not extrapolated into a huge rate
+	windowDays := max(until.Sub(since), time.Hour).Hours() / 24
+
+	var total int64
+	for _, row := range rows {
+		total += row.Bandwidth
+	}
+
+	results := make([]*BackendBandwidthProjection, len(rows))
+	for i, row := range rows {
+		perDay := float64(row.Bandwidth) / windowDays
+		results[i] = &BackendBandwidthProjection{
+			Backend:        row.Backend,
+			Requests:       row.Requests,
+			Bandwidth:      row.Bandwidth,
+			WindowDays:     windowDays,
+			BytesPerDay:    perDay,
+			ProjectedBytes: int64(perDay * ProjectionDays),
+		}
+		if total > 0 {
+			results[i].Share = float64(row.Bandwidth) / float64(total) * 100
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Bandwidth != results[j].Bandwidth {
+			return results[i].Bandwidth > results[j].Bandwidth
+		}
+		return results[i].Backend < results[j].Backend
+	})
+
+	return results, nil
+}
+
+// GetRetriedRequests reports how many requests were retried against their upstream, how the
+// final status changes with the number of retries, and the most retried backend paths.
+// The retry_attempts > 0 predicate is repeated literally so the partial idx_retried index applies.
+func (r *statsRepo) GetRetriedRequests(hours int, host string, limit int, excludeIP *ExcludeIPFilter) (*RetryStats, error) {
+	limit = r.clampTopLimit(limit, "retries")
+
+	windowRequests := func() *gorm.DB {
+		query := r.db.Model(&models.HTTPRequest{})
+		query = r.applyTimeWindow(query, hours)
+		query = r.applyExcludeIPFilter(query, excludeIP)
+		if host != "" {
+			query = query.Where("host = ?", host)
+		}
+		return query
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	stats := &RetryStats{Outcomes: []*RetryOutcome{}, Targets: []*RetryTarget{}}
+	err := windowRequests().WithContext(ctx).
+		Select("retry_attempts as attempts, " + requestCountSQL + " as requests, " + weightedCountSQL("status_code >= 500") + " as failed").
+		Group("retry_attempts").
+		Order("retry_attempts").
+		Scan(&stats.Outcomes).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get retry outcomes", r.logger.Args("error", err))
+		return nil, err
+	}
+	for _, outcome := range stats.Outcomes {
+		outcome.FailureRate = float64(outcome.Failed) / float64(outcome.Requests) * 100
+		stats.Requests += outcome.Requests
+		if outcome.Attempts > 0 {
+			stats.RetriedRequests += outcome.Requests
+			stats.TotalRetries += int64(outcome.Attempts) * outcome.Requests
+		}
+	}
+	if stats.RetriedRequests == 0 {
+		return stats, nil
+	}
+	stats.RetryRate = float64(stats.RetriedRequests) / float64(stats.Requests) * 100
+
+	err = windowRequests().WithContext(ctx).
+		Select(`CASE WHEN backend_name != '' THEN backend_name WHEN backend_url != '' THEN backend_url ELSE host END as backend,
+			path, ` + requestCountSQL + ` as requests, SUM(retry_attempts * sample_weight) as retries, MAX(retry_attempts) as max_attempts,
+			` + weightedCountSQL("status_code >= 500") + ` as failed`).
+		Where("retry_attempts > 0").
+		Group("backend, path").
+		Order("retries DESC, requests DESC, backend, path").
+		Limit(limit).
+		Scan(&stats.Targets).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get retried paths", r.logger.Args("error", err))
+		return nil, err
+	}
+	for _, target := range stats.Targets {
+		target.FailureRate = float64(target.Failed) / float64(target.Requests) * 100
 	}
 
 	return stats, nil
 }
 
-// GetTopBrowsers returns most common browsers
-func (r *statsRepo) GetTopBrowsers(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*BrowserStats, error) {
-	var browsers []*BrowserStats
+// GetVisitorReturnStats splits the visitors of the window into new and returning ones.
+// A visitor returns when its IP was first seen before the window start, by its GeoIP
+// lookup (ip_reputation.first_seen) or by a stored request on any host. The request
+// check covers IPs looked up only after their requests were stored, e.g. on an import.
+// With hours = 0 the window has no start and every visitor is new.
+func (r *statsRepo) GetVisitorReturnStats(hours int, host string, excludeIP *ExcludeIPFilter) (*VisitorReturnStats, error) {
+	since, _ := r.timeWindow(hours)
+	stats := &VisitorReturnStats{}
+	if hours > 0 {
+		stats.WindowStart = since
+	}
 
-	query := r.db.Model(&models.HTTPRequest{}).
-		Select("browser, COUNT(*) as count").
-		Where("browser != '' AND browser != 'Unknown'")
+	visitors := r.db.Model(&models.HTTPRequest{}).
+		Select("client_ip, " + requestCountSQL + " as requests").
+		Group("client_ip")
+	visitors = r.applyTimeWindow(visitors, hours)
+	visitors = r.applyExcludeIPFilter(visitors, excludeIP)
+	if host != "" {
+		visitors = visitors.Where("host = ?", host)
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
 
+	// Without a window start there is a single group of new visitors
+	query := r.db.WithContext(ctx).Table("(?) as w", visitors)
+	returning := "0"
+	var args []interface{}
 	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		query = query.Where("timestamp > ?", since)
+		returning = `CASE WHEN EXISTS (SELECT 1 FROM ip_reputation WHERE ip_address = w.client_ip AND first_seen <= ?)
+			OR EXISTS (SELECT 1 FROM http_requests earlier WHERE earlier.client_ip = w.client_ip AND earlier.timestamp <= ?)
+			THEN 1 ELSE 0 END`
+		args = append(args, since, since)
+		query = query.Group("is_returning")
 	}
 
-	query = r.applyServiceFilters(query, filters)
-	err := query.Group("browser").Order("count DESC").Limit(limit).Scan(&browsers).Error
-
+	var rows []struct {
+		IsReturning int
+		Visitors    int64
+		Requests    int64
+	}
+	err := query.
+		Select(returning+" as is_returning, COUNT(*) as visitors, COALESCE(SUM(w.requests), 0) as requests", args...).
+		Scan(&rows).Error
 	if err != nil {
-		r.logger.WithCaller().Error("Failed to get top browsers", r.logger.Args("error", err))
+		r.logger.WithCaller().Error("Failed to get visitor return stats", r.logger.Args("error", err))
 		return nil, err
 	}
 
-	return browsers, nil
+	for _, row := range rows {
+		if row.IsReturning == 1 {
+			stats.ReturningVisitors, stats.ReturningRequests = row.Visitors, row.Requests
+		} else {
+			stats.NewVisitors, stats.NewRequests = row.Visitors, row.Requests
+		}
+	}
+	stats.Visitors = stats.NewVisitors + stats.ReturningVisitors
+	stats.Requests = stats.NewRequests + stats.ReturningRequests
+	if stats.Visitors > 0 {
+		stats.ReturningRate = float64(stats.ReturningVisitors) / float64(stats.Visitors) * 100
+	}
+
+	return stats, nil
 }
 
 // GetTopOperatingSystems returns most common operating systems
 func (r *statsRepo) GetTopOperatingSystems(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*OSStats, error) {
+	limit = r.clampTopLimit(limit, "operating_systems")
+
 	var osList []*OSStats
 
 	query := r.db.Model(&models.HTTPRequest{}).
-		Select("os, COUNT(*) as count").
+		Select("os, " + requestCountSQL + " as count").
 		Where("os != '' AND os != 'Unknown'")
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		query = query.Where("timestamp > ?", since)
-	}
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyStatusRange(query)
+	query = r.applyExcludeInternal(query, excludeIP)
 
 	query = r.applyServiceFilters(query, filters)
 	err := query.Group("os").Order("count DESC").Limit(limit).Scan(&osList).Error
@@ -2343,11 +4309,8 @@ func (r *statsRepo) GetDeviceTypeDistribution(hours int, filters []ServiceFilter
 	whereClause := "device_type != ''"
 	args := []interface{}{}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
+	whereClause += internalClause(excludeIP)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -2374,7 +4337,7 @@ func (r *statsRepo) GetDeviceTypeDistribution(hours int, filters []ServiceFilter
 	}
 
 	query := `
-		SELECT device_type, COUNT(*) as count
+		SELECT device_type, ` + requestCountSQL + ` as count
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY device_type
@@ -2391,14 +4354,138 @@ func (r *statsRepo) GetDeviceTypeDistribution(hours int, filters []ServiceFilter
 	return devices, nil
 }
 
-// GetDomains returns all unique domains/hosts with their request counts
-// DEPRECATED: Use GetServices() instead for better service identification
-// Uses referer field as the domain identifier
-func (r *statsRepo) GetDomains() ([]*DomainStats, error) {
+// GetClientProfiles returns the most common (browser, os, device_type) combinations
+// Requests without a parsed user agent are left out
+func (r *statsRepo) GetClientProfiles(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*ClientProfileStats, error) {
+	limit = r.clampTopLimit(limit, "client_profiles")
+
+	var profiles []*ClientProfileStats
+
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select("browser, os, device_type, " + requestCountSQL + " as count, COUNT(DISTINCT client_ip) as unique_visitors").
+		Where("browser != '' AND browser != 'Unknown'")
+
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyExcludeInternal(query, excludeIP)
+
+	query = r.applyServiceFilters(query, filters)
+	err := query.Group("browser, os, device_type").Order("count DESC").Limit(limit).Scan(&profiles).Error
+
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get client profiles", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// GetTrafficByLabel returns traffic grouped by classification label
+// Unlabeled requests (no rule matched) are left out; uses partial index idx_label
+func (r *statsRepo) GetTrafficByLabel(hours int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*LabelStats, error) {
+	var labels []*LabelStats
+
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select("label, " + requestCountSQL + " as hits, COUNT(DISTINCT client_ip) as unique_visitors, COALESCE(SUM(response_size * sample_weight), 0) as bandwidth").
+		Where("label != ''")
+
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	err := query.Group("label").Order("hits DESC").Scan(&labels).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get traffic by label", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// GetBotTrafficStats returns how requests split between bots and humans.
+// Requests without a bot user agent count as human. botLimit > 0 adds the
+// busiest bots by name; the user agent parser stores the bot name as browser.
+func (r *statsRepo) GetBotTrafficStats(hours int, botLimit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) (*BotTrafficStats, error) {
+	var totals struct {
+		TotalRequests  int64
+		BotRequests    int64
+		TotalBandwidth int64
+		BotBandwidth   int64
+	}
+
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select(requestCountSQL + ` as total_requests,
+			` + weightedCountSQL("device_type = 'bot'") + ` as bot_requests,
+			COALESCE(SUM(response_size * sample_weight), 0) as total_bandwidth,
+			COALESCE(SUM(CASE WHEN device_type = 'bot' THEN response_size * sample_weight ELSE 0 END), 0) as bot_bandwidth`)
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	if err := query.Scan(&totals).Error; err != nil {
+		r.logger.WithCaller().Error("Failed to get bot traffic stats", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	stats := &BotTrafficStats{
+		TotalRequests:  totals.TotalRequests,
+		BotRequests:    totals.BotRequests,
+		HumanRequests:  totals.TotalRequests - totals.BotRequests,
+		BotBandwidth:   totals.BotBandwidth,
+		HumanBandwidth: totals.TotalBandwidth - totals.BotBandwidth,
+	}
+	if stats.TotalRequests > 0 {
+		stats.BotPercentage = float64(stats.BotRequests) * 100 / float64(stats.TotalRequests)
+		stats.HumanPercentage = 100 - stats.BotPercentage
+	}
+
+	if botLimit <= 0 || stats.BotRequests == 0 {
+		return stats, nil
+	}
+	botLimit = r.clampTopLimit(botLimit, "bots")
+
+	botQuery := r.db.Model(&models.HTTPRequest{}).
+		Select("CASE WHEN browser = '' THEN 'Unknown Bot' ELSE browser END as name, "+requestCountSQL+" as requests, COALESCE(SUM(response_size * sample_weight), 0) as bandwidth").
+		Where("device_type = ?", "bot")
+	botQuery = r.applyTimeWindow(botQuery, hours)
+	botQuery = r.applyServiceFilters(botQuery, filters)
+	botQuery = r.applyExcludeIPFilter(botQuery, excludeIP)
+
+	if err := botQuery.Group("name").Order("requests DESC").Limit(botLimit).Scan(&stats.Bots).Error; err != nil {
+		r.logger.WithCaller().Error("Failed to get bot breakdown", r.logger.Args("error", err))
+		return nil, err
+	}
+	for _, bot := range stats.Bots {
+		bot.Percentage = float64(bot.Requests) * 100 / float64(stats.BotRequests)
+	}
+
+	return stats, nil
+}
+
+// GetDomains returns all unique domains with their request counts.
+// filterType "host" (default) lists the real host column, which every log source fills;
+// "backend_name" lists names extracted from Traefik router/service names.
+func (r *statsRepo) GetDomains(filterType string) ([]*DomainStats, error) {
+	if filterType != "backend_name" {
+		var domains []*DomainStats
+		err := r.db.Table("http_requests").
+			Select("host, "+requestCountSQL+" as count").
+			Where("host != ?", "").
+			Group("host").
+			Order("count DESC").
+			Scan(&domains).Error
+		if err != nil {
+			r.logger.WithCaller().Error("Failed to get domains", r.logger.Args("error", err))
+			return nil, err
+		}
+
+		r.logger.Debug("Retrieved domains list (from host)", r.logger.Args("count", len(domains)))
+		return domains, nil
+	}
+
 	var rawDomains []*DomainStats
 
 	err := r.db.Table("http_requests").
-		Select("backend_name as host, COUNT(*) as count").
+		Select("backend_name as host, "+requestCountSQL+" as count").
 		Where("backend_name != ?", "").
 		Group("backend_name").
 		Order("count DESC").
@@ -2454,21 +4541,21 @@ func (r *statsRepo) GetServices() ([]*ServiceInfo, error) {
 	// The idx_service_identification covers (backend_name, backend_url, host)
 	query := `
 		SELECT name, type, SUM(count) as count FROM (
-			SELECT backend_name as name, 'backend_name' as type, COUNT(*) as count
+			SELECT backend_name as name, 'backend_name' as type, ` + requestCountSQL + ` as count
 			FROM http_requests
 			WHERE backend_name != ''
 			GROUP BY backend_name
 			
 			UNION ALL
 			
-			SELECT backend_url as name, 'backend_url' as type, COUNT(*) as count
+			SELECT backend_url as name, 'backend_url' as type, ` + requestCountSQL + ` as count
 			FROM http_requests
 			WHERE backend_name = '' AND backend_url != ''
 			GROUP BY backend_url
 			
 			UNION ALL
 			
-			SELECT host as name, 'host' as type, COUNT(*) as count
+			SELECT host as name, 'host' as type, ` + requestCountSQL + ` as count
 			FROM http_requests
 			WHERE backend_name = '' AND backend_url = '' AND host != ''
 			GROUP BY host
@@ -2548,11 +4635,7 @@ func (r *statsRepo) GetIPDetailedStats(ip string, hours int, filters []ServiceFi
 	whereClause := "client_ip = ?"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -2581,7 +4664,7 @@ func (r *statsRepo) GetIPDetailedStats(ip string, hours int, filters []ServiceFi
 	// Fast query - uses idx_ip_agg covering index
 	basicQuery := `
 		SELECT
-			COUNT(*) as total_requests,
+			` + requestCountSQL + ` as total_requests,
 			MIN(timestamp) as first_seen,
 			MAX(timestamp) as last_seen,
 			MAX(geo_country) as geo_country,
@@ -2590,10 +4673,10 @@ func (r *statsRepo) GetIPDetailedStats(ip string, hours int, filters []ServiceFi
 			MAX(geo_lon) as geo_lon,
 			MAX(asn) as asn,
 			MAX(asn_org) as asn_org,
-			COALESCE(SUM(response_size), 0) as total_bandwidth,
+			COALESCE(SUM(response_size * sample_weight), 0) as total_bandwidth,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time,
-			SUM(CASE WHEN status_code >= 200 AND status_code < 400 THEN 1 ELSE 0 END) as success_count,
-			SUM(CASE WHEN status_code >= 400 THEN 1 ELSE 0 END) as error_count
+			` + weightedCountSQL("status_code >= 200 AND status_code < 400") + ` as success_count,
+			` + weightedCountSQL("status_code >= 400") + ` as error_count
 		FROM http_requests
 		WHERE ` + whereClause
 
@@ -2621,19 +4704,10 @@ func (r *statsRepo) GetIPDetailedStats(ip string, hours int, filters []ServiceFi
 
 	// Parse timestamps from SQLite string format
 	if result.FirstSeen != "" {
-		if firstSeen, err := time.Parse(SQLiteTimeFormat, result.FirstSeen); err == nil {
-			stats.FirstSeen = firstSeen
-		} else if firstSeen, err := time.Parse(time.DateTime, result.FirstSeen); err == nil {
-			stats.FirstSeen = firstSeen
-		}
+		stats.FirstSeen = parseAggregateTimestamp(result.FirstSeen)
 	}
-
 	if result.LastSeen != "" {
-		if lastSeen, err := time.Parse(SQLiteTimeFormat, result.LastSeen); err == nil {
-			stats.LastSeen = lastSeen
-		} else if lastSeen, err := time.Parse(time.DateTime, result.LastSeen); err == nil {
-			stats.LastSeen = lastSeen
-		}
+		stats.LastSeen = parseAggregateTimestamp(result.LastSeen)
 	}
 
 	// Map to stats struct
@@ -2667,29 +4741,26 @@ func (r *statsRepo) GetIPTimelineStats(ip string, hours int, filters []ServiceFi
 	var timeline []*TimelineData
 
 	// Adaptive grouping based on time range - using substr() for speed
+	d := r.timelineDialect(hours)
 	var groupBy string
 	if hours > 0 && hours <= 24 {
 		// Group by hour
-		groupBy = "strftime('%Y-%m-%dT%H:00:00Z', timestamp)"
+		groupBy = d.hourBucket()
 	} else if hours > 0 && hours <= 168 {
 		// Group by 6-hour blocks
-		groupBy = "strftime('%Y-%m-%dT', timestamp) || printf('%02d', (CAST(strftime('%H', timestamp) AS INTEGER) / 6) * 6) || ':00:00Z'"
+		groupBy = d.sixHourBucket()
 	} else if hours > 0 && hours <= 720 {
 		// Group by day
-		groupBy = "strftime('%Y-%m-%dT00:00:00Z', timestamp)"
+		groupBy = d.dayBucket()
 	} else {
 		// Group by week (calendar logic needs strftime)
-		groupBy = "strftime('%Y-W%W', timestamp)"
+		groupBy = d.weekBucket()
 	}
 
 	whereClause := "client_ip = ?"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -2719,9 +4790,9 @@ func (r *statsRepo) GetIPTimelineStats(ip string, hours int, filters []ServiceFi
 	query := `
 		SELECT
 			` + groupBy + ` as hour,
-			COUNT(*) as requests,
+			` + requestCountSQL + ` as requests,
 			COUNT(DISTINCT backend_name) as unique_visitors,
-			COALESCE(SUM(response_size), 0) as bandwidth,
+			COALESCE(SUM(response_size * sample_weight), 0) as bandwidth,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time
 		FROM http_requests
 		WHERE ` + whereClause + `
@@ -2735,6 +4806,9 @@ func (r *statsRepo) GetIPTimelineStats(ip string, hours int, filters []ServiceFi
 		r.logger.WithCaller().Error("Failed to get IP timeline", r.logger.Args("ip", ip, "error", err))
 		return nil, err
 	}
+	for _, point := range timeline {
+		point.Hour = r.utcBucketLabel(point.Hour)
+	}
 
 	r.logger.Trace("Generated IP timeline", r.logger.Args("ip", ip, "data_points", len(timeline)))
 	return timeline, nil
@@ -2749,7 +4823,7 @@ func (r *statsRepo) GetIPTrafficHeatmap(ip string, days int, filters []ServiceFi
 	args := []interface{}{ip}
 
 	if days > 0 {
-		since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+		since := time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour)
 		whereClause += " AND timestamp > ?"
 		args = append(args, since)
 	}
@@ -2781,9 +4855,9 @@ func (r *statsRepo) GetIPTrafficHeatmap(ip string, days int, filters []ServiceFi
 	// Simplified query - uses idx_ip_heatmap_agg index
 	query := `
 		SELECT
-			CAST(strftime('%w', timestamp) AS INTEGER) as day_of_week,
-			CAST(strftime('%H', timestamp) AS INTEGER) as hour,
-			COUNT(*) as requests,
+			` + dialectOf(r.db).dayOfWeek() + ` as day_of_week,
+			` + dialectOf(r.db).hourOfDay() + ` as hour,
+			` + requestCountSQL + ` as requests,
 			AVG(response_time_ms) as avg_response_time
 		FROM http_requests
 		WHERE ` + whereClause + `
@@ -2805,16 +4879,14 @@ func (r *statsRepo) GetIPTrafficHeatmap(ip string, days int, filters []ServiceFi
 // GetIPTopPaths returns top paths for a specific IP
 // OPTIMIZED: Uses raw SQL for better query planning with idx_ip_path_agg index
 func (r *statsRepo) GetIPTopPaths(ip string, hours int, limit int, filters []ServiceFilter) ([]*PathStats, error) {
+	limit = r.clampTopLimit(limit, "ip_paths")
+
 	var paths []*PathStats
 
 	whereClause := "client_ip = ?"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -2845,10 +4917,10 @@ func (r *statsRepo) GetIPTopPaths(ip string, hours int, limit int, filters []Ser
 	query := `
 		SELECT
 			path,
-			COUNT(*) as hits,
+			` + requestCountSQL + ` as hits,
 			1 as unique_visitors,
 			COALESCE(AVG(CASE WHEN response_time_ms > 0 THEN response_time_ms END), 0) as avg_response_time,
-			COALESCE(SUM(response_size), 0) as total_bandwidth,
+			COALESCE(SUM(response_size * sample_weight), 0) as total_bandwidth,
 			MAX(host) as host,
 			MAX(backend_name) as backend_name,
 			MAX(backend_url) as backend_url
@@ -2873,16 +4945,14 @@ func (r *statsRepo) GetIPTopPaths(ip string, hours int, limit int, filters []Ser
 // GetIPTopBackends returns top backends for a specific IP
 // OPTIMIZED: Direct query without CTE for better SQLite performance
 func (r *statsRepo) GetIPTopBackends(ip string, hours int, limit int, filters []ServiceFilter) ([]*BackendStats, error) {
+	limit = r.clampTopLimit(limit, "ip_backends")
+
 	var backends []*BackendStats
 
 	whereClause := "client_ip = ? AND backend_name != ''"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -2914,10 +4984,10 @@ func (r *statsRepo) GetIPTopBackends(ip string, hours int, limit int, filters []
 		SELECT
 			backend_name,
 			MAX(backend_url) as backend_url,
-			COUNT(*) as hits,
-			SUM(response_size) as bandwidth,
+			` + requestCountSQL + ` as hits,
+			SUM(response_size * sample_weight) as bandwidth,
 			AVG(response_time_ms) as avg_response_time,
-			SUM(CASE WHEN status_code >= 500 THEN 1 ELSE 0 END) as error_count
+			` + weightedCountSQL("status_code >= 500") + ` as error_count
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY backend_name
@@ -2944,11 +5014,7 @@ func (r *statsRepo) GetIPStatusCodeDistribution(ip string, hours int, filters []
 	whereClause := "client_ip = ?"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -2978,7 +5044,7 @@ func (r *statsRepo) GetIPStatusCodeDistribution(ip string, hours int, filters []
 	query := `
 		SELECT
 			status_code,
-			COUNT(*) as count
+			` + requestCountSQL + ` as count
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY status_code
@@ -2998,16 +5064,14 @@ func (r *statsRepo) GetIPStatusCodeDistribution(ip string, hours int, filters []
 // GetIPTopBrowsers returns top browsers for a specific IP
 // OPTIMIZED: Uses raw SQL for better query planning with idx_ip_browser_agg partial index
 func (r *statsRepo) GetIPTopBrowsers(ip string, hours int, limit int, filters []ServiceFilter) ([]*BrowserStats, error) {
+	limit = r.clampTopLimit(limit, "ip_browsers")
+
 	var browsers []*BrowserStats
 
 	whereClause := "client_ip = ? AND browser != ''"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -3038,7 +5102,7 @@ func (r *statsRepo) GetIPTopBrowsers(ip string, hours int, limit int, filters []
 	query := `
 		SELECT
 			browser,
-			COUNT(*) as count
+			` + requestCountSQL + ` as count
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY browser
@@ -3061,16 +5125,14 @@ func (r *statsRepo) GetIPTopBrowsers(ip string, hours int, limit int, filters []
 // GetIPTopOperatingSystems returns top operating systems for a specific IP
 // OPTIMIZED: Uses raw SQL for better query planning with idx_ip_os_agg partial index
 func (r *statsRepo) GetIPTopOperatingSystems(ip string, hours int, limit int, filters []ServiceFilter) ([]*OSStats, error) {
+	limit = r.clampTopLimit(limit, "ip_operating_systems")
+
 	var osList []*OSStats
 
 	whereClause := "client_ip = ? AND os != ''"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -3101,7 +5163,7 @@ func (r *statsRepo) GetIPTopOperatingSystems(ip string, hours int, limit int, fi
 	query := `
 		SELECT
 			os,
-			COUNT(*) as count
+			` + requestCountSQL + ` as count
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY os
@@ -3129,11 +5191,7 @@ func (r *statsRepo) GetIPDeviceTypeDistribution(ip string, hours int, filters []
 	whereClause := "client_ip = ? AND device_type != ''"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -3163,7 +5221,7 @@ func (r *statsRepo) GetIPDeviceTypeDistribution(ip string, hours int, filters []
 	query := `
 		SELECT
 			device_type,
-			COUNT(*) as count
+			` + requestCountSQL + ` as count
 		FROM http_requests
 		WHERE ` + whereClause + `
 		GROUP BY device_type
@@ -3188,11 +5246,7 @@ func (r *statsRepo) GetIPResponseTimeStats(ip string, hours int, filters []Servi
 	whereClause := "client_ip = ? AND response_time_ms > 0"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -3269,11 +5323,7 @@ func (r *statsRepo) GetIPRecentRequests(ip string, limit int, hours int, filters
 	whereClause := "client_ip = ?"
 	args := []interface{}{ip}
 
-	if hours > 0 {
-		since := time.Now().Add(-time.Duration(hours) * time.Hour)
-		whereClause += " AND timestamp > ?"
-		args = append(args, since)
-	}
+	whereClause, args = r.appendTimeWindow(whereClause, args, hours)
 
 	// Apply service filters inline
 	if len(filters) > 0 {
@@ -3317,9 +5367,7 @@ func (r *statsRepo) GetIPRecentRequests(ip string, limit int, hours int, filters
 }
 
 // SearchIPs searches for IPs matching a pattern with their basic stats
-func (r *statsRepo) SearchIPs(query string, limit int) ([]*IPSearchResult, error) {
-	since := r.getTimeRange()
-
+func (r *statsRepo) SearchIPs(query string, hours int, limit int) ([]*IPSearchResult, error) {
 	// Use a temporary struct to handle SQLite string timestamps
 	type tempResult struct {
 		IPAddress string `json:"ip_address"`
@@ -3330,9 +5378,10 @@ func (r *statsRepo) SearchIPs(query string, limit int) ([]*IPSearchResult, error
 	}
 
 	var tempResults []tempResult
-	err := r.db.Model(&models.HTTPRequest{}).
-		Select("client_ip as ip_address, COUNT(*) as hits, MAX(geo_country) as country, MAX(geo_city) as city, MAX(timestamp) as last_seen").
-		Where("client_ip LIKE ? AND timestamp > ?", "%"+query+"%", since).
+	search := r.db.Model(&models.HTTPRequest{}).
+		Select("client_ip as ip_address, "+requestCountSQL+" as hits, MAX(geo_country) as country, MAX(geo_city) as city, MAX(timestamp) as last_seen").
+		Where("client_ip LIKE ?", "%"+query+"%")
+	err := r.applyTimeWindow(search, hours).
 		Group("client_ip").
 		Order("hits DESC").
 		Limit(limit).
@@ -3450,14 +5499,14 @@ func (r *statsRepo) GetRecordTimeRange() (oldest time.Time, newest time.Time, er
 // GetRecordsTimeline returns records count grouped by day for system statistics
 func (r *statsRepo) GetRecordsTimeline(days int) ([]*TimelineData, error) {
 	var timeline []*TimelineData
-	since := time.Now().AddDate(0, 0, -days)
+	since := time.Now().UTC().AddDate(0, 0, -days)
 
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
 	defer cancel()
 
 	// Group by day for system stats
 	err := r.db.WithContext(ctx).Model(&models.HTTPRequest{}).
-		Select("strftime('%Y-%m-%dT00:00:00Z', timestamp) as hour, COUNT(*) as requests").
+		Select(dialectOf(r.db).dayBucket()+" as hour, COUNT(*) as requests").
 		Where("timestamp > ?", since).
 		Group("hour").
 		Order("hour").
@@ -3471,3 +5520,336 @@ func (r *statsRepo) GetRecordsTimeline(days int) ([]*TimelineData, error) {
 	r.logger.Trace("Generated records timeline", r.logger.Args("days", days, "data_points", len(timeline)))
 	return timeline, nil
 }
+
+const (
+	// MaxPathFlowWindowMinutes caps the lookback window for path flow analysis (24 hours)
+	MaxPathFlowWindowMinutes = 1440
+	// maxPathFlowRows bounds how many requests are scanned in Go for path flow analysis;
+	// on busy instances the most recent ones within the window are kept
+	maxPathFlowRows = 200000
+)
+
+// PathTransition holds how often clients moved from one path to another
+type PathTransition struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int64  `json:"count"`
+}
+
+// GetPathSequences returns the most common A→B path transitions made by the same client IP
+// Requests within the window are ordered per IP; each pair of consecutive distinct paths counts as one transition
+func (r *statsRepo) GetPathSequences(windowMinutes int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*PathTransition, error) {
+	if windowMinutes <= 0 {
+		windowMinutes = 60
+	}
+	if windowMinutes > MaxPathFlowWindowMinutes {
+		windowMinutes = MaxPathFlowWindowMinutes
+	}
+	since := time.Now().UTC().Add(-time.Duration(windowMinutes) * time.Minute)
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	// Newest first, so the row cap trims the oldest requests instead of whole IPs
+	query := r.db.WithContext(ctx).Model(&models.HTTPRequest{}).
+		Select("client_ip, path").
+		Where("timestamp > ?", since)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	rows, err := query.Order("timestamp DESC, id DESC").Limit(maxPathFlowRows).Rows()
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get path sequences", r.logger.Args("error", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	type transitionKey struct {
+		from string
+		to   string
+	}
+	counts := make(map[transitionKey]int64)
+
+	// Rows arrive newest first: each request leads to the next (later) path seen for its IP
+	nextPath := make(map[string]string)
+	scanned := 0
+	for rows.Next() {
+		var clientIP, path string
+		if err := rows.Scan(&clientIP, &path); err != nil {
+			r.logger.WithCaller().Error("Failed to scan path sequence row", r.logger.Args("error", err))
+			return nil, err
+		}
+		scanned++
+
+		if next, ok := nextPath[clientIP]; ok && next != path {
+			counts[transitionKey{from: path, to: next}]++
+		}
+		nextPath[clientIP] = path
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.WithCaller().Error("Failed to iterate path sequences", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	transitions := make([]*PathTransition, 0, len(counts))
+	for key, count := range counts {
+		transitions = append(transitions, &PathTransition{From: key.from, To: key.to, Count: count})
+	}
+
+	sort.Slice(transitions, func(i, j int) bool {
+		if transitions[i].Count != transitions[j].Count {
+			return transitions[i].Count > transitions[j].Count
+		}
+		if transitions[i].From != transitions[j].From {
+			return transitions[i].From < transitions[j].From
+		}
+		return transitions[i].To < transitions[j].To
+	})
+
+	if len(transitions) > limit {
+		transitions = transitions[:limit]
+	}
+
+	r.logger.Trace("Computed path sequences",
+		r.logger.Args("window_minutes", windowMinutes, "rows_scanned", scanned, "transitions", len(transitions)))
+	return transitions, nil
+}
+
+// DefaultAuthAbusePaths are the login endpoints checked when none are configured
+var DefaultAuthAbusePaths = []string{"/login", "/wp-login.php", "/api/auth"}
+
+const (
+	// DefaultAuthAbuseMinRequests is the number of login attempts an IP needs before it is reported
+	DefaultAuthAbuseMinRequests = 10
+	// DefaultAuthAbuseMinFailureRatio is the share of 401/403 responses an IP needs before it is reported
+	DefaultAuthAbuseMinFailureRatio = 0.5
+	// MaxAuthAbuseWindowMinutes caps the lookback window for the credential-stuffing report (24 hours)
+	MaxAuthAbuseWindowMinutes = 1440
+	// maxAuthAbuseResults bounds how many suspicious IPs are returned
+	maxAuthAbuseResults = 100
+)
+
+// authAbuseConfig holds the sensitive paths and thresholds used by GetAuthAbuse
+type authAbuseConfig struct {
+	paths           []string
+	minRequests     int
+	minFailureRatio float64
+}
+
+// AuthAbuseIP holds login activity for a client IP that looks like credential stuffing
+type AuthAbuseIP struct {
+	ClientIP      string    `json:"client_ip"`
+	Requests      int64     `json:"requests"`
+	Failures      int64     `json:"failures"` // 401 and 403 responses
+	FailureRatio  float64   `json:"failure_ratio"`
+	DistinctPaths int64     `json:"distinct_paths"`
+	Country       string    `json:"country"`
+	FirstSeen     time.Time `json:"first_seen"`
+	LastSeen      time.Time `json:"last_seen"`
+}
+
+// SetAuthAbuseConfig sets the login path prefixes and thresholds for the credential-stuffing report
+// Blank paths are dropped; an empty list or non-positive threshold keeps the default
+func (r *statsRepo) SetAuthAbuseConfig(paths []string, minRequests int, minFailureRatio float64) {
+	cleaned := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p = strings.TrimSpace(p); p != "" {
+			cleaned = append(cleaned, p)
+		}
+	}
+	if len(cleaned) == 0 {
+		cleaned = DefaultAuthAbusePaths
+	}
+	if minRequests <= 0 {
+		minRequests = DefaultAuthAbuseMinRequests
+	}
+	if minFailureRatio <= 0 || minFailureRatio > 1 {
+		minFailureRatio = DefaultAuthAbuseMinFailureRatio
+	}
+	r.authAbuse = authAbuseConfig{
+		paths:           cleaned,
+		minRequests:     minRequests,
+		minFailureRatio: minFailureRatio,
+	}
+}
+
+// GetAuthAbuse returns IPs hammering the configured login paths with mostly rejected attempts
+// Paths match by prefix, so "/login" also covers "/login?next=/" and "/login/submit"
+func (r *statsRepo) GetAuthAbuse(windowMinutes int) ([]*AuthAbuseIP, error) {
+	if windowMinutes <= 0 {
+		windowMinutes = 60
+	}
+	if windowMinutes > MaxAuthAbuseWindowMinutes {
+		windowMinutes = MaxAuthAbuseWindowMinutes
+	}
+	since := time.Now().UTC().Add(-time.Duration(windowMinutes) * time.Minute)
+
+	cfg := r.authAbuse
+	pathConds := make([]string, len(cfg.paths))
+	pathArgs := make([]interface{}, len(cfg.paths))
+	for i, p := range cfg.paths {
+		pathConds[i] = `path LIKE ? ESCAPE '\'`
+		pathArgs[i] = likeEscaper.Replace(p) + "%"
+	}
+
+	var rows []struct {
+		ClientIP      string
+		Requests      int64
+		Failures      int64
+		DistinctPaths int64
+		Country       string
+		FirstSeen     string
+		LastSeen      string
+	}
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	failures := weightedCountSQL("status_code IN (401, 403)")
+	err := r.db.WithContext(ctx).Model(&models.HTTPRequest{}).
+		Select(`client_ip,
+			`+requestCountSQL+` as requests,
+			`+failures+` as failures,
+			COUNT(DISTINCT path) as distinct_paths,
+			MAX(geo_country) as country,
+			MIN(timestamp) as first_seen,
+			MAX(timestamp) as last_seen`).
+		Where("timestamp > ?", since).
+		Where("("+strings.Join(pathConds, " OR ")+")", pathArgs...).
+		Group("client_ip").
+		Having(requestCountSQL+" >= ? AND CAST("+failures+" AS REAL) / "+requestCountSQL+" >= ?", cfg.minRequests, cfg.minFailureRatio).
+		Order("failures DESC, requests DESC").
+		Limit(maxAuthAbuseResults).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get auth abuse stats", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	results := make([]*AuthAbuseIP, 0, len(rows))
+	for _, row := range rows {
+		entry := &AuthAbuseIP{
+			ClientIP:      row.ClientIP,
+			Requests:      row.Requests,
+			Failures:      row.Failures,
+			DistinctPaths: row.DistinctPaths,
+			Country:       row.Country,
+			FirstSeen:     parseAggregateTimestamp(row.FirstSeen),
+			LastSeen:      parseAggregateTimestamp(row.LastSeen),
+		}
+		if entry.Requests > 0 {
+			entry.FailureRatio = float64(entry.Failures) / float64(entry.Requests)
+		}
+		results = append(results, entry)
+	}
+	return results, nil
+}
+
+// parseAggregateTimestamp parses a MIN/MAX(timestamp) value, which SQLite returns as text
+// and PostgreSQL as a timestamp that database/sql formats as RFC 3339 when scanned into a string
+func parseAggregateTimestamp(value string) time.Time {
+	for _, layout := range []string{SQLiteTimeFormat, time.DateTime, time.RFC3339Nano} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+const (
+	// suspiciousMinRequests is the number of requests an IP needs before it is scored
+	suspiciousMinRequests = 20
+	// suspiciousCandidateFactor widens the SQL candidate set before scores are ranked in Go
+	suspiciousCandidateFactor = 5
+)
+
+// SuspiciousIP holds the signals that make a client IP look abusive (scanning, brute force)
+type SuspiciousIP struct {
+	ClientIP          string    `json:"client_ip"`
+	Requests          int64     `json:"requests"`
+	ClientErrors      int64     `json:"client_errors"` // 4xx responses
+	ErrorRatio        float64   `json:"error_ratio"`
+	UniquePaths       int64     `json:"unique_paths"`
+	RequestsPerMinute float64   `json:"requests_per_minute"` // Over the IP's active span, at least one minute
+	Country           string    `json:"country"`
+	FirstSeen         time.Time `json:"first_seen"`
+	LastSeen          time.Time `json:"last_seen"`
+	Score             float64   `json:"score"` // 0-100, see suspicionScore
+}
+
+// GetSuspiciousIPs returns the client IPs with the highest suspicion score, combining a high
+// 4xx ratio, a high request rate and many distinct paths. IPs with few requests are ignored.
+func (r *statsRepo) GetSuspiciousIPs(hours int, limit int, filters []ServiceFilter, excludeIP *ExcludeIPFilter) ([]*SuspiciousIP, error) {
+	limit = r.clampTopLimit(limit, "suspicious_ips")
+
+	var rows []struct {
+		ClientIP     string
+		Requests     int64
+		ClientErrors int64
+		UniquePaths  int64
+		Country      string
+		FirstSeen    string
+		LastSeen     string
+	}
+
+	clientErrors := weightedCountSQL("status_code >= 400 AND status_code < 500")
+	query := r.db.Model(&models.HTTPRequest{}).
+		Select(`client_ip,
+			` + requestCountSQL + ` as requests,
+			` + clientErrors + ` as client_errors,
+			COUNT(DISTINCT path) as unique_paths,
+			MAX(geo_country) as country,
+			MIN(timestamp) as first_seen,
+			MAX(timestamp) as last_seen`)
+	query = r.applyTimeWindow(query, hours)
+	query = r.applyServiceFilters(query, filters)
+	query = r.applyExcludeIPFilter(query, excludeIP)
+
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	// Candidates with the most 4xx responses carry both signals (rate x ratio); the score ranks them
+	err := query.WithContext(ctx).
+		Group("client_ip").
+		Having(requestCountSQL+" >= ? AND "+clientErrors+" > 0", suspiciousMinRequests).
+		Order("client_errors DESC, requests DESC").
+		Limit(limit * suspiciousCandidateFactor).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to get suspicious IPs", r.logger.Args("error", err))
+		return nil, err
+	}
+
+	results := make([]*SuspiciousIP, 0, len(rows))
+	for _, row := range rows {
+		entry := &SuspiciousIP{
+			ClientIP:     row.ClientIP,
+			Requests:     row.Requests,
+			ClientErrors: row.ClientErrors,
+			UniquePaths:  row.UniquePaths,
+			Country:      row.Country,
+			FirstSeen:    parseAggregateTimestamp(row.FirstSeen),
+			LastSeen:     parseAggregateTimestamp(row.LastSeen),
+		}
+		entry.ErrorRatio = float64(entry.ClientErrors) / float64(entry.Requests)
+		minutes := max(entry.LastSeen.Sub(entry.FirstSeen).Minutes(), 1)
+		entry.RequestsPerMinute = float64(entry.Requests) / minutes
+		entry.Score = suspicionScore(entry)
+		results = append(results, entry)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// suspicionScore weighs the 4xx ratio (up to 50 points), the request rate (30 points at
+// 60 requests per minute or more) and path variety (20 points at 100 distinct paths or more)
+func suspicionScore(ip *SuspiciousIP) float64 {
+	score := 50*ip.ErrorRatio +
+		30*min(ip.RequestsPerMinute/60, 1) +
+		20*min(float64(ip.UniquePaths)/100, 1)
+	return math.Round(score*10) / 10
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuthAbuse(t *testing.T) {
+	db, repo := setupTestDB(t)
+	repo.SetAuthAbuseConfig([]string{"/login", " /wp-login.php ", ""}, 5, 0.5)
+	now := time.Now().Add(-5 * time.Minute)
+
+	var requests []models.HTTPRequest
+	add := func(ip, path string, status, count int, ts time.Time) {
+		for i := 0; i < count; i++ {
+			requests = append(requests, models.HTTPRequest{
+				RequestHash: fmt.Sprintf("%s-%s-%d-%d-%d", ip, path, status, i, ts.Unix()),
+				ClientIP:    ip,
+				Path:        path,
+				StatusCode:  status,
+				Timestamp:   ts,
+			})
+		}
+	}
+	// Stuffer: 8 attempts, 7 rejected, across two login paths
+	add("1.1.1.1", "/login", 401, 5, now)
+	add("1.1.1.1", "/wp-login.php", 403, 2, now)
+	add("1.1.1.1", "/login", 200, 1, now)
+	// Regular user: mostly successful logins
+	add("2.2.2.2", "/login", 200, 5, now)
+	add("2.2.2.2", "/login", 401, 1, now)
+	// Too few attempts to be reported
+	add("3.3.3.3", "/login?next=/admin", 401, 3, now)
+	// Failures on a non-login path do not count
+	add("4.4.4.4", "/api/data", 401, 10, now)
+	// Outside the window
+	add("5.5.5.5", "/login", 401, 10, now.Add(-2*time.Hour))
+	assert.NoError(t, db.Create(&requests).Error)
+
+	abusers, err := repo.GetAuthAbuse(60)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(abusers))
+	assert.Equal(t, "1.1.1.1", abusers[0].ClientIP)
+	assert.Equal(t, int64(8), abusers[0].Requests)
+	assert.Equal(t, int64(7), abusers[0].Failures)
+	assert.InDelta(t, 0.875, abusers[0].FailureRatio, 0.001)
+	assert.Equal(t, int64(2), abusers[0].DistinctPaths)
+	assert.False(t, abusers[0].LastSeen.IsZero())
+
+	// Query strings still match the path prefix once the threshold is lowered
+	repo.SetAuthAbuseConfig([]string{"/login"}, 3, 0.5)
+	abusers, err = repo.GetAuthAbuse(60)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(abusers))
+	assert.Equal(t, "1.1.1.1", abusers[0].ClientIP)
+	assert.Equal(t, "3.3.3.3", abusers[1].ClientIP)
+
+	// A wider window picks up older attempts
+	abusers, err = repo.GetAuthAbuse(180)
+	assert.NoError(t, err)
+	assert.Equal(t, "5.5.5.5", abusers[0].ClientIP)
+}
+
+func TestSetAuthAbuseConfigDefaults(t *testing.T) {
+	_, repo := setupTestDB(t)
+	r := repo.(*statsRepo)
+
+	r.SetAuthAbuseConfig([]string{" ", ""}, 0, 2)
+	assert.Equal(t, DefaultAuthAbusePaths, r.authAbuse.paths)
+	assert.Equal(t, DefaultAuthAbuseMinRequests, r.authAbuse.minRequests)
+	assert.Equal(t, DefaultAuthAbuseMinFailureRatio, r.authAbuse.minFailureRatio)
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedAvailabilityRequests(t *testing.T, now time.Time) StatsRepository {
+	db, repo := setupTestDB(t)
+
+	statuses := []int{200, 200, 200, 204, 301, 404, 404, 401, 400, 500}
+	requests := make([]models.HTTPRequest, len(statuses))
+	for i, status := range statuses {
+		requests[i] = models.HTTPRequest{
+			RequestHash: "availability-" + string(rune('a'+i)),
+			ClientIP:    "1.1.1.1",
+			Timestamp:   now.Add(-30 * time.Minute),
+			Host:        "a.example.com",
+			Path:        "/",
+			StatusCode:  status,
+		}
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	rollups := []models.HourlyRollup{
+		{Timestamp: now.Add(-50 * time.Hour).Truncate(time.Hour), Host: "a.example.com", StatusCode: 404, Requests: 2},
+	}
+	require.NoError(t, db.Create(&rollups).Error)
+	return repo
+}
+
+func TestAvailabilityRateWithoutBenignCodes(t *testing.T) {
+	repo := seedAvailabilityRequests(t, time.Now())
+
+	summary, err := repo.GetSummary(24, nil, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 50.0, summary.SuccessRate, 0.001)
+	assert.InDelta(t, summary.SuccessRate, summary.AvailabilityRate, 0.001)
+}
+
+func TestAvailabilityRateIgnoresBenignCodes(t *testing.T) {
+	now := time.Now()
+	repo := seedAvailabilityRequests(t, now)
+	// 500 is outside the 4xx range and must keep counting against availability
+	repo.SetBenignStatusCodes([]int{401, 404, 500})
+
+	summary, err := repo.GetSummary(24, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), summary.TotalRequests)
+	assert.Equal(t, int64(5), summary.FailedRequests, "benign codes still count as failed requests")
+	assert.InDelta(t, 50.0, summary.SuccessRate, 0.001)
+	assert.InDelta(t, 80.0, summary.AvailabilityRate, 0.001, "5 valid + 3 benign (2x404, 401) out of 10")
+
+	summary, err = repo.GetSummary(72, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), summary.TotalRequests)
+	assert.InDelta(t, 10.0/12.0*100, summary.AvailabilityRate, 0.001, "rolled-up 404s are benign too")
+
+	comparison, err := repo.GetComparison([]ComparisonPeriodRequest{
+		{Label: "current", Start: now.Add(-time.Hour), End: now},
+	}, nil, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, comparison.Periods, 1)
+	period := comparison.Periods[0].Summary
+	assert.Equal(t, int64(10), period.TotalRequests)
+	assert.Equal(t, int64(5), period.FailedRequests)
+	assert.InDelta(t, 50.0, period.SuccessRate, 0.001)
+	assert.InDelta(t, 80.0, period.AvailabilityRate, 0.001)
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBackendBandwidthProjection(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := []struct {
+		backend, url, host string
+		size               int64
+		age                time.Duration
+	}{
+		{"api", "", "a.example.com", 3000, time.Hour},
+		{"api", "", "a.example.com", 1000, 2 * time.Hour},
+		{"", "http://10.0.0.5:8080", "a.example.com", 1000, time.Hour}, // Named by its URL
+		{"", "", "b.example.com", 2000, time.Hour},                     // Named by its host
+		{"api", "", "a.example.com", 9000, 48 * time.Hour},             // Outside the window
+	}
+	for i, r := range requests {
+		require.NoError(t, db.Create(&models.HTTPRequest{
+			RequestHash:  "projection-" + string(rune('a'+i)),
+			ClientIP:     "1.1.1.1",
+			Timestamp:    now.Add(-r.age),
+			Host:         r.host,
+			Path:         "/",
+			StatusCode:   200,
+			BackendName:  r.backend,
+			BackendURL:   r.url,
+			ResponseSize: r.size,
+		}).Error)
+	}
+
+	projection, err := repo.GetBackendBandwidthProjection(24, "", nil)
+	require.NoError(t, err)
+	require.Len(t, projection, 3)
+
+	api := projection[0]
+	assert.Equal(t, "api", api.Backend)
+	assert.Equal(t, int64(2), api.Requests)
+	assert.Equal(t, int64(4000), api.Bandwidth)
+	assert.InDelta(t, 1.0, api.WindowDays, 0.001)
+	assert.InDelta(t, 4000, api.BytesPerDay, 1)
+	assert.InDelta(t, 4000*ProjectionDays, api.ProjectedBytes, ProjectionDays)
+	assert.InDelta(t, 57.14, api.Share, 0.01)
+
+	assert.Equal(t, "b.example.com", projection[1].Backend)
+	assert.Equal(t, "http://10.0.0.5:8080", projection[2].Backend)
+
+	projection, err = repo.GetBackendBandwidthProjection(24, "b.example.com", nil)
+	require.NoError(t, err)
+	require.Len(t, projection, 1)
+	assert.Equal(t, 100.0, projection[0].Share)
+
+	// All time: the rate is averaged from the oldest request, two days ago
+	projection, err = repo.GetBackendBandwidthProjection(0, "", nil)
+	require.NoError(t, err)
+	require.Len(t, projection, 3)
+	assert.Equal(t, int64(13000), projection[0].Bandwidth)
+	assert.InDelta(t, 2.0, projection[0].WindowDays, 0.01)
+	assert.InDelta(t, 6500, projection[0].BytesPerDay, 50)
+}
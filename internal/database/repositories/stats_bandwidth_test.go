@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBandwidthTimeline(t *testing.T) {
+	db, repo := setupTestDB(t)
+	hour := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "bw-1", ClientIP: "1.1.1.1", Timestamp: hour.Add(time.Minute), RequestLength: 500, ResponseSize: 100},
+		{RequestHash: "bw-2", ClientIP: "1.1.1.1", Timestamp: hour.Add(2 * time.Minute), RequestLength: 1500, ResponseSize: 200},
+		{RequestHash: "bw-3", ClientIP: "2.2.2.2", Timestamp: hour.Add(time.Hour + time.Minute), RequestLength: 10, ResponseSize: 9000},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	timeline, err := repo.GetBandwidthTimeline(24, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(timeline))
+	assert.Equal(t, hour.Format("2006-01-02T15:00:00Z"), timeline[0].Hour)
+	assert.Equal(t, int64(2000), timeline[0].BytesIn)
+	assert.Equal(t, int64(300), timeline[0].BytesOut)
+	assert.Equal(t, int64(10), timeline[1].BytesIn)
+	assert.Equal(t, int64(9000), timeline[1].BytesOut)
+
+	timeline, err = repo.GetBandwidthTimeline(24, nil, &ExcludeIPFilter{ClientIPs: []string{"2.2.2.2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(timeline))
+}
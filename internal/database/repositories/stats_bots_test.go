@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBotTrafficStats(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().Add(-time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "bot-1", ClientIP: "1.1.1.1", Timestamp: now, DeviceType: "bot", Browser: "Googlebot", ResponseSize: 100},
+		{RequestHash: "bot-2", ClientIP: "1.1.1.1", Timestamp: now, DeviceType: "bot", Browser: "Googlebot", ResponseSize: 100},
+		{RequestHash: "bot-3", ClientIP: "2.2.2.2", Timestamp: now, DeviceType: "bot", Browser: "", ResponseSize: 50},
+		{RequestHash: "human-1", ClientIP: "3.3.3.3", Timestamp: now, DeviceType: "desktop", Browser: "Firefox", ResponseSize: 1000},
+		{RequestHash: "human-2", ClientIP: "4.4.4.4", Timestamp: now, DeviceType: "", ResponseSize: 250},
+		// Outside the window
+		{RequestHash: "bot-old", ClientIP: "1.1.1.1", Timestamp: now.Add(-48 * time.Hour), DeviceType: "bot", Browser: "Bingbot"},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	stats, err := repo.GetBotTrafficStats(24, 0, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), stats.TotalRequests)
+	assert.Equal(t, int64(3), stats.BotRequests)
+	assert.Equal(t, int64(2), stats.HumanRequests)
+	assert.InDelta(t, 60.0, stats.BotPercentage, 0.001)
+	assert.InDelta(t, 40.0, stats.HumanPercentage, 0.001)
+	assert.Equal(t, int64(250), stats.BotBandwidth)
+	assert.Equal(t, int64(1250), stats.HumanBandwidth)
+	assert.Empty(t, stats.Bots)
+
+	stats, err = repo.GetBotTrafficStats(24, 10, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(stats.Bots))
+	assert.Equal(t, "Googlebot", stats.Bots[0].Name)
+	assert.Equal(t, int64(2), stats.Bots[0].Requests)
+	assert.InDelta(t, 66.667, stats.Bots[0].Percentage, 0.01)
+	assert.Equal(t, "Unknown Bot", stats.Bots[1].Name)
+
+	stats, err = repo.GetBotTrafficStats(24, 10, nil, &ExcludeIPFilter{ClientIPs: []string{"1.1.1.1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.BotRequests)
+	assert.Equal(t, int64(3), stats.TotalRequests)
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetClientProfiles(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().Add(-time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "profile-1", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", Browser: "Chrome", OS: "Windows", DeviceType: "desktop"},
+		{RequestHash: "profile-2", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", Browser: "Chrome", OS: "Windows", DeviceType: "desktop"},
+		{RequestHash: "profile-3", ClientIP: "2.2.2.2", Timestamp: now, Host: "a.example.com", Browser: "Chrome", OS: "Windows", DeviceType: "desktop"},
+		{RequestHash: "profile-4", ClientIP: "3.3.3.3", Timestamp: now, Host: "b.example.com", Browser: "Chrome", OS: "Android", DeviceType: "mobile"},
+		{RequestHash: "profile-5", ClientIP: "3.3.3.3", Timestamp: now, Host: "b.example.com", Browser: "Chrome", OS: "Android", DeviceType: "mobile"},
+		{RequestHash: "profile-6", ClientIP: "4.4.4.4", Timestamp: now, Host: "a.example.com", Browser: "Safari", OS: "iOS", DeviceType: "mobile"},
+		{RequestHash: "profile-7", ClientIP: "5.5.5.5", Timestamp: now, Host: "a.example.com", Browser: "Unknown", OS: "Unknown", DeviceType: "desktop"},
+		{RequestHash: "profile-8", ClientIP: "6.6.6.6", Timestamp: now, Host: "a.example.com"},
+		// Outside the window
+		{RequestHash: "profile-old", ClientIP: "7.7.7.7", Timestamp: now.Add(-48 * time.Hour), Browser: "Safari", OS: "iOS", DeviceType: "mobile"},
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	profiles, err := repo.GetClientProfiles(24, 10, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, profiles, 3, "requests without a parsed browser are left out")
+	assert.Equal(t, ClientProfileStats{Browser: "Chrome", OS: "Windows", DeviceType: "desktop", Count: 3, UniqueVisitors: 2}, *profiles[0])
+	assert.Equal(t, ClientProfileStats{Browser: "Chrome", OS: "Android", DeviceType: "mobile", Count: 2, UniqueVisitors: 1}, *profiles[1])
+	assert.Equal(t, "Safari", profiles[2].Browser)
+	assert.Equal(t, int64(1), profiles[2].Count)
+
+	profiles, err = repo.GetClientProfiles(24, 1, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, profiles, 1)
+
+	profiles, err = repo.GetClientProfiles(24, 10, []ServiceFilter{{Name: "b.example.com", Type: "host"}}, nil)
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, "Android", profiles[0].OS)
+}
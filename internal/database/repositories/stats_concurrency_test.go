@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencySweepCountsOverlaps(t *testing.T) {
+	since := time.Date(2025, 11, 3, 14, 0, 0, 0, time.UTC)
+	sweep := newConcurrencySweep(since, since.Add(3*time.Minute), time.Minute)
+
+	// Three requests overlap around 14:00:10; the last starts after the first two end
+	sweep.add(since.Add(5*time.Second), 10*time.Second)
+	sweep.add(since.Add(8*time.Second), 5*time.Second)
+	sweep.add(since.Add(10*time.Second), 90*time.Second) // Runs into the second bucket
+	sweep.add(since.Add(20*time.Second), time.Second)
+
+	timeline := sweep.finish()
+	assert.Equal(t, 3, len(timeline))
+
+	assert.Equal(t, "2025-11-03T14:00:00Z", timeline[0].Time)
+	assert.Equal(t, int64(4), timeline[0].Requests)
+	assert.Equal(t, 3, timeline[0].MaxConcurrent)
+
+	// No request starts in the second bucket, but the long one is still running
+	assert.Equal(t, int64(0), timeline[1].Requests)
+	assert.Equal(t, 1, timeline[1].MaxConcurrent)
+	assert.InDelta(t, 40.0/60.0, timeline[1].AvgConcurrent, 0.001)
+
+	assert.Equal(t, 0, timeline[2].MaxConcurrent)
+	assert.Equal(t, 0.0, timeline[2].AvgConcurrent)
+}
+
+func TestConcurrencySweepBackToBackRequestsDoNotOverlap(t *testing.T) {
+	since := time.Date(2025, 11, 3, 14, 0, 0, 0, time.UTC)
+	sweep := newConcurrencySweep(since, since.Add(time.Minute), time.Minute)
+
+	sweep.add(since, 10*time.Second)
+	sweep.add(since.Add(10*time.Second), 10*time.Second)
+
+	timeline := sweep.finish()
+	assert.Equal(t, 1, timeline[0].MaxConcurrent)
+	assert.InDelta(t, 20.0/60.0, timeline[0].AvgConcurrent, 0.001)
+}
+
+func TestGetConcurrencyTimeline(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "concurrency-1", ClientIP: "1.1.1.1", Timestamp: now.Add(-30 * time.Minute), ResponseTimeMs: 60000, BackendName: "svc-a"},
+		{RequestHash: "concurrency-2", ClientIP: "1.1.1.2", Timestamp: now.Add(-30*time.Minute + 10*time.Second), ResponseTimeMs: 1000, BackendName: "svc-a"},
+		{RequestHash: "concurrency-3", ClientIP: "1.1.1.3", Timestamp: now.Add(-30*time.Minute + 20*time.Second), ResponseTimeMs: 1000, BackendName: "svc-b"},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	peak := func(timeline []*ConcurrencyData) (int, int64) {
+		maxConcurrent, total := 0, int64(0)
+		for _, p := range timeline {
+			if p.MaxConcurrent > maxConcurrent {
+				maxConcurrent = p.MaxConcurrent
+			}
+			total += p.Requests
+		}
+		return maxConcurrent, total
+	}
+
+	timeline, err := repo.GetConcurrencyTimeline(1, time.Minute, nil, nil)
+	assert.NoError(t, err)
+	maxConcurrent, total := peak(timeline)
+	assert.Equal(t, 2, maxConcurrent)
+	assert.Equal(t, int64(3), total)
+
+	timeline, err = repo.GetConcurrencyTimeline(1, time.Minute, []ServiceFilter{{Name: "svc-b", Type: "backend_name"}}, nil)
+	assert.NoError(t, err)
+	maxConcurrent, total = peak(timeline)
+	assert.Equal(t, 1, maxConcurrent)
+	assert.Equal(t, int64(1), total)
+
+	// The long request from 1.1.1.1 overlaps both others; excluding it removes the overlap
+	timeline, err = repo.GetConcurrencyTimeline(1, time.Minute, nil, &ExcludeIPFilter{ClientIPs: []string{"1.1.1.1"}})
+	assert.NoError(t, err)
+	maxConcurrent, total = peak(timeline)
+	assert.Equal(t, 1, maxConcurrent)
+	assert.Equal(t, int64(2), total)
+
+	assert.NoError(t, db.Model(&models.HTTPRequest{}).Where("client_ip = ?", "1.1.1.3").Update("is_internal", true).Error)
+	timeline, err = repo.GetConcurrencyTimeline(1, time.Minute, nil, &ExcludeIPFilter{ExcludeInternal: true})
+	assert.NoError(t, err)
+	_, total = peak(timeline)
+	assert.Equal(t, int64(2), total)
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetContentTypeDistribution(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().Add(-time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "ct-1", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", ResponseContentType: "text/html; charset=utf-8", ResponseSize: 100},
+		{RequestHash: "ct-2", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", ResponseContentType: "text/html", ResponseSize: 200},
+		{RequestHash: "ct-3", ClientIP: "2.2.2.2", Timestamp: now, Host: "a.example.com", ResponseContentType: "Text/HTML;charset=ISO-8859-1", ResponseSize: 300},
+		{RequestHash: "ct-4", ClientIP: "2.2.2.2", Timestamp: now, Host: "b.example.com", ResponseContentType: "video/mp4", ResponseSize: 50000},
+		// No Content-Type (e.g. 304 responses)
+		{RequestHash: "ct-5", ClientIP: "3.3.3.3", Timestamp: now, Host: "b.example.com", ResponseSize: 0},
+		// Outside the window
+		{RequestHash: "ct-old", ClientIP: "1.1.1.1", Timestamp: now.Add(-48 * time.Hour), Host: "a.example.com", ResponseContentType: "application/json"},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	stats, err := repo.GetContentTypeDistribution(24, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "text/html", stats[0].ContentType)
+	assert.Equal(t, int64(3), stats[0].Count)
+	assert.Equal(t, int64(600), stats[0].Bandwidth)
+	assert.Equal(t, "video/mp4", stats[1].ContentType)
+	assert.Equal(t, int64(50000), stats[1].Bandwidth)
+
+	stats, err = repo.GetContentTypeDistribution(24, []ServiceFilter{{Name: "b.example.com", Type: "host"}}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 1)
+	assert.Equal(t, "video/mp4", stats[0].ContentType)
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTopCountriesErrorBreakdown(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().Add(-time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "geo-1", ClientIP: "1.1.1.1", Timestamp: now, GeoCountry: "DE", StatusCode: 200, ResponseTimeMs: 10, ResponseSize: 100},
+		{RequestHash: "geo-2", ClientIP: "1.1.1.2", Timestamp: now, GeoCountry: "DE", StatusCode: 404, ResponseTimeMs: 20, ResponseSize: 100},
+		{RequestHash: "geo-3", ClientIP: "1.1.1.2", Timestamp: now, GeoCountry: "DE", StatusCode: 502, ResponseTimeMs: 90, ResponseSize: 100},
+		{RequestHash: "geo-4", ClientIP: "1.1.1.3", Timestamp: now, GeoCountry: "DE", StatusCode: 503, ResponseTimeMs: 80, ResponseSize: 100},
+		{RequestHash: "geo-5", ClientIP: "2.2.2.2", Timestamp: now, GeoCountry: "FR", StatusCode: 200, ResponseTimeMs: 15, ResponseSize: 50},
+		{RequestHash: "geo-6", ClientIP: "3.3.3.3", Timestamp: now, StatusCode: 500},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	countries, err := repo.GetTopCountries(24, 10, nil, nil)
+	assert.NoError(t, err)
+	if !assert.Len(t, countries, 2, "requests without a country are left out") {
+		return
+	}
+
+	de := countries[0]
+	assert.Equal(t, "DE", de.Country)
+	assert.Equal(t, int64(4), de.Hits)
+	assert.Equal(t, int64(3), de.UniqueVisitors)
+	assert.Equal(t, int64(400), de.Bandwidth)
+	assert.Equal(t, int64(1), de.Status4xx)
+	assert.Equal(t, int64(2), de.Status5xx)
+	assert.InDelta(t, 75.0, de.ErrorRate, 0.001)
+	assert.InDelta(t, 50.0, de.AvgResponseTime, 0.001)
+
+	fr := countries[1]
+	assert.Equal(t, "FR", fr.Country)
+	assert.Zero(t, fr.Status4xx+fr.Status5xx)
+	assert.Zero(t, fr.ErrorRate)
+}
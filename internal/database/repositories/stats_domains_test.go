@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainsAndHostFilter(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := []models.HTTPRequest{
+		// Traefik request with a backend name
+		{RequestHash: "domains-1", ClientIP: "1.1.1.1", Timestamp: now.Add(-time.Hour), Host: "shop.example.com", BackendName: "12-shop-example-service@file", StatusCode: 200},
+		// Caddy requests: no backend name, only the host
+		{RequestHash: "domains-2", ClientIP: "1.1.1.2", Timestamp: now.Add(-time.Hour), Host: "blog.example.com", StatusCode: 200},
+		{RequestHash: "domains-3", ClientIP: "1.1.1.3", Timestamp: now.Add(-time.Hour), Host: "blog.example.com", StatusCode: 404},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	t.Run("GetDomains lists hosts by default", func(t *testing.T) {
+		domains, err := repo.GetDomains("host")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(domains))
+		assert.Equal(t, "blog.example.com", domains[0].Host)
+		assert.Equal(t, int64(2), domains[0].Count)
+	})
+
+	t.Run("GetDomains lists backend names on request", func(t *testing.T) {
+		domains, err := repo.GetDomains("backend_name")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(domains))
+		assert.Equal(t, "shop example", domains[0].Host)
+	})
+
+	t.Run("host filter matches requests without a backend name", func(t *testing.T) {
+		timeline, err := repo.GetTimelineStats(24, []ServiceFilter{{Name: "blog.example.com", Type: "host"}}, nil)
+		assert.NoError(t, err)
+
+		totalRequests := int64(0)
+		for _, p := range timeline {
+			totalRequests += p.Requests
+		}
+		assert.Equal(t, int64(2), totalRequests)
+	})
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetErrorAnalysis(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := []struct {
+		ip, agent, host, path string
+		status                int
+		age                   time.Duration
+	}{
+		{"1.1.1.1", "curl/8.0", "a.example.com", "/checkout", 502, 50 * time.Minute},
+		{"1.1.1.2", "curl/8.0", "a.example.com", "/checkout", 500, 40 * time.Minute},
+		{"1.1.1.3", "Mozilla/5.0", "a.example.com", "/checkout", 502, 10 * time.Minute}, // Most recent failure
+		{"1.1.1.4", "Mozilla/5.0", "a.example.com", "/checkout", 200, 5 * time.Minute},  // Successes are ignored
+		{"2.2.2.2", "bot", "a.example.com", "/missing", 404, 30 * time.Minute},
+		{"3.3.3.3", "bot", "b.example.com", "/admin", 403, 20 * time.Minute},
+		{"3.3.3.3", "bot", "b.example.com", "/admin", 403, 48 * time.Hour}, // Outside the window
+	}
+	for i, r := range requests {
+		require.NoError(t, db.Create(&models.HTTPRequest{
+			RequestHash: "errors-" + string(rune('a'+i)),
+			ClientIP:    r.ip,
+			UserAgent:   r.agent,
+			Timestamp:   now.Add(-r.age),
+			Host:        r.host,
+			Path:        r.path,
+			StatusCode:  r.status,
+		}).Error)
+	}
+
+	paths, err := repo.GetErrorAnalysis(24, "", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, paths, 3)
+
+	checkout := paths[0]
+	assert.Equal(t, "/checkout", checkout.Path)
+	assert.Equal(t, int64(3), checkout.Errors)
+	require.Len(t, checkout.Statuses, 2)
+	assert.Equal(t, StatusCodeStats{StatusCode: 502, Count: 2}, *checkout.Statuses[0])
+	assert.Equal(t, StatusCodeStats{StatusCode: 500, Count: 1}, *checkout.Statuses[1])
+	assert.Equal(t, "1.1.1.3", checkout.LastIP)
+	assert.Equal(t, "Mozilla/5.0", checkout.LastAgent)
+	assert.Equal(t, 502, checkout.LastStatus)
+	assert.WithinDuration(t, now.Add(-10*time.Minute), checkout.LastSeen, time.Second)
+
+	assert.Equal(t, "/admin", paths[1].Path, "ties are ordered by path")
+	assert.Equal(t, int64(1), paths[1].Errors)
+
+	paths, err = repo.GetErrorAnalysis(24, "b.example.com", 10, nil)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	assert.Equal(t, "/admin", paths[0].Path)
+
+	paths, err = repo.GetErrorAnalysis(24, "c.example.com", 10, nil)
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+	assert.NotNil(t, paths)
+}
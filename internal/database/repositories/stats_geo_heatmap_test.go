@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGeoHeatmap(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	points := []struct {
+		ip       string
+		lat, lon float64
+		host     string
+	}{
+		{"1.1.1.1", 48.85, 2.35, "a.example.com"},    // Paris
+		{"1.1.1.2", 49.20, 2.10, "a.example.com"},    // Same 1° cell as Paris
+		{"1.1.1.2", 49.20, 2.10, "a.example.com"},    // Repeat visitor
+		{"2.2.2.2", -33.87, 151.21, "b.example.com"}, // Sydney
+		{"3.3.3.3", 0, 0, "a.example.com"},           // Not geolocated
+	}
+	for i, p := range points {
+		require.NoError(t, db.Create(&models.HTTPRequest{
+			RequestHash: "geo-" + string(rune('a'+i)),
+			ClientIP:    p.ip,
+			Timestamp:   now.Add(-time.Hour),
+			Host:        p.host,
+			Path:        "/",
+			StatusCode:  200,
+			GeoLat:      p.lat,
+			GeoLon:      p.lon,
+		}).Error)
+	}
+
+	cells, err := repo.GetGeoHeatmap(24, 1, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, cells, 2, "requests without coordinates are left out")
+	assert.Equal(t, GeoHeatmapCell{Latitude: 49, Longitude: 2, Hits: 3, UniqueVisitors: 2}, *cells[0], "cells are ordered by hits")
+	assert.Equal(t, GeoHeatmapCell{Latitude: -34, Longitude: 151, Hits: 1, UniqueVisitors: 1}, *cells[1])
+
+	// A finer grid splits the Paris cell
+	cells, err = repo.GetGeoHeatmap(24, 0.1, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, cells, 3)
+	assert.Equal(t, 49.2, cells[0].Latitude)
+	assert.Equal(t, 2.1, cells[0].Longitude)
+
+	cells, err = repo.GetGeoHeatmap(24, 1, []ServiceFilter{{Name: "b.example.com", Type: "host"}}, nil)
+	require.NoError(t, err)
+	require.Len(t, cells, 1)
+	assert.Equal(t, -34.0, cells[0].Latitude)
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExcludeInternalHidesFlaggedRequests(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().UTC().Add(-time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "internal-1", ClientIP: "10.0.0.1", Path: "/health", StatusCode: 200, Timestamp: now, IsInternal: true},
+		{RequestHash: "internal-2", ClientIP: "fd00::1", Path: "/health", StatusCode: 200, Timestamp: now, IsInternal: true},
+		{RequestHash: "external-1", ClientIP: "203.0.113.7", Path: "/", StatusCode: 200, Timestamp: now},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	hideInternal := &ExcludeIPFilter{ExcludeInternal: true}
+
+	summary, err := repo.GetSummary(24, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), summary.TotalRequests)
+
+	summary, err = repo.GetSummary(24, nil, hideInternal)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), summary.TotalRequests)
+	assert.Equal(t, int64(1), summary.UniqueVisitors)
+
+	paths, err := repo.GetTopPaths(24, 10, "", nil, hideInternal)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(paths))
+	assert.Equal(t, "/", paths[0].Path)
+
+	statuses, err := repo.GetStatusCodeDistribution(24, nil, hideInternal)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(statuses))
+	assert.Equal(t, int64(1), statuses[0].Count)
+}
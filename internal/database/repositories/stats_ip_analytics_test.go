@@ -18,7 +18,7 @@ func setupTestDB(t *testing.T) (*gorm.DB, StatsRepository) {
 		t.Fatalf("failed to connect database: %v", err)
 	}
 
-	err = db.AutoMigrate(&models.HTTPRequest{}, &models.IPTag{})
+	err = db.AutoMigrate(&models.HTTPRequest{}, &models.IPTag{}, &models.HourlyRollup{})
 	if err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTrafficByLabel(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "labels-1", ClientIP: "1.1.1.1", Timestamp: now.Add(-time.Hour), Label: "api", ResponseSize: 100, BackendName: "svc-a"},
+		{RequestHash: "labels-2", ClientIP: "1.1.1.2", Timestamp: now.Add(-time.Hour), Label: "api", ResponseSize: 200, BackendName: "svc-b"},
+		{RequestHash: "labels-3", ClientIP: "1.1.1.1", Timestamp: now.Add(-time.Hour), Label: "internal", ResponseSize: 50, BackendName: "svc-a"},
+		{RequestHash: "labels-4", ClientIP: "1.1.1.3", Timestamp: now.Add(-time.Hour), BackendName: "svc-a"},
+		{RequestHash: "labels-5", ClientIP: "1.1.1.4", Timestamp: now.Add(-48 * time.Hour), Label: "internal", BackendName: "svc-a"},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	labels, err := repo.GetTrafficByLabel(24, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(labels), "unlabeled and out-of-range requests are left out")
+	assert.Equal(t, "api", labels[0].Label)
+	assert.Equal(t, int64(2), labels[0].Hits)
+	assert.Equal(t, int64(2), labels[0].UniqueVisitors)
+	assert.Equal(t, int64(300), labels[0].Bandwidth)
+
+	labels, err = repo.GetTrafficByLabel(24, []ServiceFilter{{Name: "svc-a", Type: "backend_name"}}, &ExcludeIPFilter{ClientIPs: []string{"1.1.1.1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(labels))
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopListsAreCappedByMaxTopLimit(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := make([]models.HTTPRequest, 0, 5)
+	for i := 0; i < 5; i++ {
+		requests = append(requests, models.HTTPRequest{
+			RequestHash: fmt.Sprintf("limits-%d", i),
+			ClientIP:    fmt.Sprintf("1.1.1.%d", i),
+			Timestamp:   now.Add(-time.Hour),
+			Path:        fmt.Sprintf("/page/%d", i),
+			GeoCountry:  fmt.Sprintf("C%d", i),
+			Referer:     fmt.Sprintf("https://ref%d.example.com/", i),
+			StatusCode:  200,
+		})
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	repo.SetMaxTopLimit(3)
+
+	t.Run("unbounded limit is capped", func(t *testing.T) {
+		countries, err := repo.GetTopCountries(24, 0, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(countries))
+
+		domains, err := repo.GetTopReferrerDomains(24, 0, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(domains))
+	})
+
+	t.Run("oversized limit is capped", func(t *testing.T) {
+		paths, err := repo.GetTopPaths(24, 100, "", nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(paths))
+	})
+
+	t.Run("smaller limit is kept", func(t *testing.T) {
+		countries, err := repo.GetTopCountries(24, 2, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(countries))
+	})
+}
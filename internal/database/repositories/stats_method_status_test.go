@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMethodStatusMatrix(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().Add(-time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "ms-1", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", Method: "GET", StatusCode: 200},
+		{RequestHash: "ms-2", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", Method: "GET", StatusCode: 304},
+		{RequestHash: "ms-3", ClientIP: "2.2.2.2", Timestamp: now, Host: "a.example.com", Method: "GET", StatusCode: 404},
+		{RequestHash: "ms-4", ClientIP: "2.2.2.2", Timestamp: now, Host: "b.example.com", Method: "POST", StatusCode: 422},
+		{RequestHash: "ms-5", ClientIP: "3.3.3.3", Timestamp: now, Host: "b.example.com", Method: "POST", StatusCode: 502},
+		// Outside the window
+		{RequestHash: "ms-old", ClientIP: "1.1.1.1", Timestamp: now.Add(-48 * time.Hour), Host: "a.example.com", Method: "DELETE", StatusCode: 500},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	stats, err := repo.GetMethodStatusMatrix(24, nil, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, MethodStatusStats{Method: "GET", Total: 3, Status2xx: 1, Status3xx: 1, Status4xx: 1}, *stats[0])
+		assert.Equal(t, MethodStatusStats{Method: "POST", Total: 2, Status4xx: 1, Status5xx: 1}, *stats[1])
+	}
+
+	stats, err = repo.GetMethodStatusMatrix(24, nil, &ExcludeIPFilter{ClientIPs: []string{"3.3.3.3"}})
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, int64(0), stats[1].Status5xx, "excluded client is not counted")
+	}
+
+	stats, err = repo.GetMethodStatusMatrix(24, []ServiceFilter{{Name: "b.example.com", Type: "host"}}, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 1) {
+		assert.Equal(t, "POST", stats[0].Method)
+	}
+}
+
+func TestMethodStatsFoldRawVerbs(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().Add(-time.Hour)
+
+	// Rows stored before ingestion normalized methods
+	requests := []models.HTTPRequest{
+		{RequestHash: "mf-1", ClientIP: "1.1.1.1", Timestamp: now, Method: "GET", StatusCode: 200},
+		{RequestHash: "mf-2", ClientIP: "1.1.1.1", Timestamp: now, Method: "get", StatusCode: 404},
+		{RequestHash: "mf-3", ClientIP: "1.1.1.1", Timestamp: now, Method: "\\x16\\x03", StatusCode: 400},
+		{RequestHash: "mf-4", ClientIP: "1.1.1.1", Timestamp: now, Method: "BREW", StatusCode: 400},
+		{RequestHash: "mf-5", ClientIP: "1.1.1.1", Timestamp: now, Method: "OTHER", StatusCode: 400},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	distribution, err := repo.GetMethodDistribution(24, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*MethodStats{{Method: "OTHER", Count: 3}, {Method: "GET", Count: 2}}, distribution)
+
+	matrix, err := repo.GetMethodStatusMatrix(24, nil, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, matrix, 2) {
+		assert.Equal(t, MethodStatusStats{Method: "OTHER", Total: 3, Status4xx: 3}, *matrix[0])
+		assert.Equal(t, MethodStatusStats{Method: "GET", Total: 2, Status2xx: 1, Status4xx: 1}, *matrix[1])
+	}
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTopOriginServers(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().Add(-time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "os-1", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", OriginServer: "nginx/1.25.3", ResponseSize: 100},
+		{RequestHash: "os-2", ClientIP: "1.1.1.1", Timestamp: now, Host: "b.example.com", OriginServer: "nginx/1.25.3", ResponseSize: 200},
+		{RequestHash: "os-3", ClientIP: "2.2.2.2", Timestamp: now, Host: "a.example.com", OriginServer: "nginx/1.25.3", ResponseSize: 300},
+		{RequestHash: "os-4", ClientIP: "3.3.3.3", Timestamp: now, Host: "c.example.com", OriginServer: "Kestrel", ResponseSize: 50},
+		// No Server header captured
+		{RequestHash: "os-5", ClientIP: "3.3.3.3", Timestamp: now, Host: "c.example.com", ResponseSize: 10},
+		// Outside the window
+		{RequestHash: "os-old", ClientIP: "1.1.1.1", Timestamp: now.Add(-48 * time.Hour), Host: "a.example.com", OriginServer: "Apache"},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	servers, err := repo.GetTopOriginServers(24, 10, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, servers, 2)
+	assert.Equal(t, "nginx/1.25.3", servers[0].Server)
+	assert.Equal(t, int64(3), servers[0].Count)
+	assert.Equal(t, int64(2), servers[0].Hosts)
+	assert.Equal(t, int64(600), servers[0].Bandwidth)
+	assert.Equal(t, "Kestrel", servers[1].Server)
+	assert.Equal(t, int64(1), servers[1].Hosts)
+
+	servers, err = repo.GetTopOriginServers(24, 10, []ServiceFilter{{Name: "c.example.com", Type: "host"}}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, servers, 1)
+	assert.Equal(t, "Kestrel", servers[0].Server)
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPathSequences(t *testing.T) {
+	db, repo := setupTestDB(t)
+	base := time.Now().UTC().Add(-30 * time.Minute)
+
+	visit := func(ip, host string, paths ...string) []models.HTTPRequest {
+		requests := make([]models.HTTPRequest, 0, len(paths))
+		for i, path := range paths {
+			requests = append(requests, models.HTTPRequest{
+				RequestHash: fmt.Sprintf("%s-%s-%d", ip, host, i),
+				ClientIP:    ip,
+				Host:        host,
+				Path:        path,
+				StatusCode:  200,
+				Timestamp:   base.Add(time.Duration(i) * time.Minute),
+			})
+		}
+		return requests
+	}
+
+	var requests []models.HTTPRequest
+	requests = append(requests, visit("1.1.1.1", "shop.example.com", "/", "/cart", "/cart", "/checkout")...)
+	requests = append(requests, visit("2.2.2.2", "shop.example.com", "/", "/cart")...)
+	requests = append(requests, visit("3.3.3.3", "blog.example.com", "/", "/about")...)
+	// Outside the window
+	old := visit("4.4.4.4", "shop.example.com", "/", "/cart")
+	for i := range old {
+		old[i].Timestamp = old[i].Timestamp.Add(-2 * time.Hour)
+	}
+	requests = append(requests, old...)
+	assert.NoError(t, db.Create(&requests).Error)
+
+	flows, err := repo.GetPathSequences(60, 10, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*PathTransition{
+		{From: "/", To: "/cart", Count: 2},
+		{From: "/", To: "/about", Count: 1},
+		{From: "/cart", To: "/checkout", Count: 1},
+	}, flows, "repeated paths are not transitions and old requests are ignored")
+
+	flows, err = repo.GetPathSequences(60, 10, []ServiceFilter{{Name: "blog.example.com", Type: "host"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*PathTransition{{From: "/", To: "/about", Count: 1}}, flows)
+
+	flows, err = repo.GetPathSequences(60, 10, nil, &ExcludeIPFilter{ClientIPs: []string{"1.1.1.1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []*PathTransition{
+		{From: "/", To: "/about", Count: 1},
+		{From: "/", To: "/cart", Count: 1},
+	}, flows)
+
+	flows, err = repo.GetPathSequences(60, 1, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, flows, 1)
+}
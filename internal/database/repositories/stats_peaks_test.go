@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPeakTraffic(t *testing.T) {
+	db, repo := setupTestDB(t)
+	busyMinute := time.Now().UTC().Truncate(time.Hour).Add(-2*time.Hour + 15*time.Minute)
+	quietMinute := busyMinute.Add(20 * time.Minute)
+
+	var requests []models.HTTPRequest
+	for i := 0; i < 6; i++ {
+		requests = append(requests, models.HTTPRequest{
+			RequestHash: "peak-busy-" + strconv.Itoa(i), ClientIP: "1.1.1.1",
+			Timestamp: busyMinute.Add(time.Duration(i) * time.Second), ResponseSize: 100,
+		})
+	}
+	for i := 0; i < 2; i++ {
+		requests = append(requests, models.HTTPRequest{
+			RequestHash: "peak-quiet-" + strconv.Itoa(i), ClientIP: "2.2.2.2",
+			Timestamp: quietMinute.Add(time.Duration(i) * time.Second),
+		})
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	peaks, err := repo.GetPeakTraffic("minute", 1, 10, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(peaks))
+	assert.Equal(t, busyMinute.Format("2006-01-02T15:04:00Z"), peaks[0].Timestamp)
+	assert.Equal(t, int64(6), peaks[0].Requests)
+	assert.InDelta(t, 0.1, peaks[0].RequestsPerSecond, 0.0001)
+	assert.Equal(t, int64(600), peaks[0].Bandwidth)
+	assert.Equal(t, int64(2), peaks[1].Requests)
+
+	peaks, err = repo.GetPeakTraffic("hour", 1, 1, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(peaks))
+	assert.Equal(t, int64(8), peaks[0].Requests)
+	assert.InDelta(t, 8.0/3600, peaks[0].RequestsPerSecond, 0.0001)
+
+	peaks, err = repo.GetPeakTraffic("minute", 1, 10, nil, &ExcludeIPFilter{ClientIPs: []string{"1.1.1.1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(peaks))
+	assert.Equal(t, quietMinute.Format("2006-01-02T15:04:00Z"), peaks[0].Timestamp)
+
+	_, err = repo.GetPeakTraffic("week", 1, 10, nil, nil)
+	assert.Error(t, err)
+}
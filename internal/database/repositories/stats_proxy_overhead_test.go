@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetProxyOverheadStats(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := []models.HTTPRequest{
+		{BackendName: "api@docker", Host: "api.example.com", ResponseTimeMs: 110, UpstreamResponseTimeMs: 100},
+		{BackendName: "api@docker", Host: "api.example.com", ResponseTimeMs: 220, UpstreamResponseTimeMs: 200},
+		{BackendName: "api@docker", Host: "api.example.com", ResponseTimeMs: 330, UpstreamResponseTimeMs: 300},
+		// Clock rounding: counted with no overhead
+		{BackendName: "api@docker", Host: "api.example.com", ResponseTimeMs: 49.5, UpstreamResponseTimeMs: 50},
+		// No upstream timing (e.g. served by the proxy itself): skipped
+		{BackendName: "api@docker", Host: "api.example.com", ResponseTimeMs: 5},
+		{Host: "static.example.com", ResponseTimeMs: 40, UpstreamResponseTimeMs: 10},
+	}
+	for i := range requests {
+		requests[i].RequestHash = fmt.Sprintf("overhead-%d", i)
+		requests[i].ClientIP = "1.1.1.1"
+		requests[i].Timestamp = now.Add(-time.Duration(i+1) * time.Minute)
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	stats, err := repo.GetProxyOverheadStats(1, "", nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	api := stats[0]
+	assert.Equal(t, "api@docker", api.Backend)
+	assert.Equal(t, int64(4), api.Requests)
+	assert.InDelta(t, 709.5/4, api.AvgTotalMs, 0.001)
+	assert.InDelta(t, 650.0/4, api.AvgUpstreamMs, 0.001)
+	assert.InDelta(t, 60.0/4, api.AvgOverheadMs, 0.001)
+	assert.InDelta(t, 60.0/709.5*100, api.OverheadShare, 0.001)
+	assert.LessOrEqual(t, api.P50OverheadMs, api.P95OverheadMs)
+	assert.InDelta(t, 30, api.P99OverheadMs, 0.001)
+
+	static := stats[1]
+	assert.Equal(t, "static.example.com", static.Backend, "falls back to the host without backend name or URL")
+	assert.InDelta(t, 30, static.AvgOverheadMs, 0.001)
+	assert.InDelta(t, 75, static.OverheadShare, 0.001)
+
+	stats, err = repo.GetProxyOverheadStats(1, "static.example.com", nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "static.example.com", stats[0].Backend)
+}
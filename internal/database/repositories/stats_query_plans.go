@@ -0,0 +1,162 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package repositories
+
+import (
+	"regexp"
+	"strings"
+
+	"loglynx/internal/database/indexes"
+)
+
+// QueryPlan is how the database runs one of the canonical dashboard queries
+type QueryPlan struct {
+	Query    string   `json:"query"`
+	Plan     []string `json:"plan"`      // EXPLAIN QUERY PLAN details (SQLite) or EXPLAIN lines (PostgreSQL)
+	Indexes  []string `json:"indexes"`   // Indexes on http_requests the plan uses
+	FullScan bool     `json:"full_scan"` // True when some step reads http_requests without an index
+}
+
+// QueryPlanReport groups the plans of the canonical queries with the state of the indexes
+type QueryPlanReport struct {
+	Hours   int             `json:"hours"`
+	Plans   []*QueryPlan    `json:"plans"`
+	Indexes *indexes.Report `json:"indexes"`
+}
+
+var (
+	// SQLite: "SEARCH hr USING INDEX idx_path_agg (path=?)", "SCAN http_requests USING COVERING INDEX idx_x"
+	sqliteIndexPattern = regexp.MustCompile(`USING (?:COVERING )?INDEX (\w+)`)
+	// A SCAN without USING reads the whole table; the canonical queries alias http_requests as hr only
+	sqliteScanPattern = regexp.MustCompile(`^SCAN (\w+)(?: AS \w+)?$`)
+	// PostgreSQL: "Index Only Scan using idx_x on http_requests", "Bitmap Index Scan on idx_x"
+	postgresIndexPattern = regexp.MustCompile(`(?:Index(?: Only)? Scan(?: Backward)? using|Bitmap Index Scan on) (\w+)`)
+	postgresScanPattern  = regexp.MustCompile(`Seq Scan on http_requests\b`)
+)
+
+// GetQueryPlans explains the summary, top paths and timeline queries for a window of hours,
+// unfiltered as the dashboard first loads them, to show whether they are served by an index
+func (r *statsRepo) GetQueryPlans(hours int) (*QueryPlanReport, error) {
+	report := &QueryPlanReport{Hours: hours}
+
+	summarySQL, summaryArgs := r.summaryQuery(hours, nil, nil)
+	topPathsSQL, topPathsArgs := r.topPathsQuery(hours, r.clampTopLimit(10, "paths"), PathOrderHits, nil, nil)
+	timeline, _ := r.timelineQuery(hours, nil, nil)
+
+	canonical := []struct {
+		name string
+		sql  string
+		args []interface{}
+	}{
+		{"summary", summarySQL, summaryArgs},
+		{"top_paths", topPathsSQL, topPathsArgs},
+		{"timeline", "?", []interface{}{timeline}},
+	}
+
+	for _, q := range canonical {
+		plan, err := r.explain(q.name, q.sql, q.args)
+		if err != nil {
+			r.logger.WithCaller().Error("Failed to explain query", r.logger.Args("query", q.name, "error", err))
+			return nil, err
+		}
+		report.Plans = append(report.Plans, plan)
+	}
+
+	status, err := indexes.Status(r.db)
+	if err != nil {
+		r.logger.WithCaller().Error("Failed to list indexes", r.logger.Args("error", err))
+		return nil, err
+	}
+	report.Indexes = status
+
+	return report, nil
+}
+
+// explain runs EXPLAIN for one query and classifies its steps
+func (r *statsRepo) explain(name, query string, args []interface{}) (*QueryPlan, error) {
+	ctx, cancel := r.withTimeout()
+	defer cancel()
+
+	postgres := dialectOf(r.db).postgres
+	prefix := "EXPLAIN QUERY PLAN "
+	if postgres {
+		prefix = "EXPLAIN "
+	}
+
+	rows, err := r.db.WithContext(ctx).Raw(prefix+query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &QueryPlan{Query: name, Plan: []string{}, Indexes: []string{}}
+	seen := make(map[string]struct{})
+	for rows.Next() {
+		// SQLite returns id, parent, notused, detail; PostgreSQL a single text column
+		values := make([]interface{}, len(columns))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+		if err := rows.Scan(values...); err != nil {
+			return nil, err
+		}
+		detail := strings.TrimSpace(planText(*values[len(values)-1].(*interface{})))
+		if detail == "" {
+			continue
+		}
+		plan.Plan = append(plan.Plan, detail)
+
+		indexPattern, scanned := sqliteIndexPattern, false
+		if postgres {
+			indexPattern = postgresIndexPattern
+			scanned = postgresScanPattern.MatchString(detail)
+		} else if m := sqliteScanPattern.FindStringSubmatch(detail); m != nil {
+			scanned = m[1] == "http_requests" || m[1] == "hr" // Not the top_paths CTE
+		}
+		plan.FullScan = plan.FullScan || scanned
+
+		if m := indexPattern.FindStringSubmatch(detail); m != nil {
+			if _, ok := seen[m[1]]; !ok {
+				seen[m[1]] = struct{}{}
+				plan.Indexes = append(plan.Indexes, m[1])
+			}
+		}
+	}
+	return plan, rows.Err()
+}
+
+// planText converts a scanned EXPLAIN column to a string
+func planText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
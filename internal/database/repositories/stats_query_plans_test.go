@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"testing"
+
+	"loglynx/internal/database/indexes"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetQueryPlans(t *testing.T) {
+	db, repo := setupTestDB(t)
+
+	report, err := repo.GetQueryPlans(24)
+	require.NoError(t, err)
+	assert.Equal(t, 24, report.Hours)
+	require.Len(t, report.Plans, 3)
+	names := []string{}
+	for _, plan := range report.Plans {
+		names = append(names, plan.Query)
+		assert.NotEmpty(t, plan.Plan, plan.Query)
+		assert.True(t, plan.FullScan, "%s scans the table before the indexes exist", plan.Query)
+	}
+	assert.Equal(t, []string{"summary", "top_paths", "timeline"}, names)
+	assert.Contains(t, report.Indexes.Missing, "idx_summary_time_weight_cover")
+	assert.NotContains(t, report.Indexes.Present, "idx_summary_time_weight_cover")
+
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	_, _, err = indexes.Ensure(db, logger)
+	require.NoError(t, err)
+
+	for _, hours := range []int{24, 0} {
+		report, err = repo.GetQueryPlans(hours)
+		require.NoError(t, err)
+		assert.Empty(t, report.Indexes.Missing)
+		assert.Equal(t, len(report.Indexes.Expected), len(report.Indexes.Present))
+		for _, plan := range report.Plans {
+			assert.False(t, plan.FullScan, "%s over %d hours: %v", plan.Query, hours, plan.Plan)
+			assert.NotEmpty(t, plan.Indexes, plan.Query)
+		}
+	}
+	assert.Contains(t, report.Plans[0].Indexes, "idx_summary_time_weight_cover")
+}
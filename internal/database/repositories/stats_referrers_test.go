@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedReferrerRequests(t *testing.T) StatsRepository {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	referers := map[string]int{
+		"https://www.google.com/search?q=loglynx": 3,
+		"https://www.google.co.uk/":               1,
+		"https://t.co/abc":                        2,
+		"https://news.ycombinator.com/item?id=1":  1,
+		"https://blog.example.com/post":           2,
+		"https://friend.example.org/links":        1,
+		"http://semalt.com/":                      5,
+		"http://free-traffic-42.net/":             4,
+		"":                                        3,
+	}
+	var requests []models.HTTPRequest
+	for referer, count := range referers {
+		for i := 0; i < count; i++ {
+			requests = append(requests, models.HTTPRequest{
+				RequestHash: fmt.Sprintf("referrer-%s-%d", referer, i),
+				ClientIP:    fmt.Sprintf("10.0.0.%d", i+1),
+				Timestamp:   now.Add(-time.Hour),
+				Host:        "blog.example.com",
+				Path:        "/",
+				StatusCode:  200,
+				Referer:     referer,
+			})
+		}
+	}
+	require.NoError(t, db.Create(&requests).Error)
+	return repo
+}
+
+func TestReferrerBlocklist(t *testing.T) {
+	repo := seedReferrerRequests(t)
+
+	domains, err := repo.GetTopReferrerDomains(24, 2, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, domains, 2)
+	assert.Equal(t, "semalt.com", domains[0].Domain, "spam tops the list without a blocklist")
+
+	repo.SetReferrerBlocklist([]string{"semalt.com", `/^free-traffic-\d+\./`, "/[/"})
+
+	domains, err = repo.GetTopReferrerDomains(24, 2, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, domains, 2, "blocked domains do not leave the list short")
+	assert.Equal(t, "google.com", domains[0].Domain)
+	assert.Equal(t, int64(3), domains[0].Hits)
+
+	referrers, err := repo.GetTopReferrers(24, 10, nil, nil)
+	require.NoError(t, err)
+	for _, ref := range referrers {
+		assert.NotContains(t, ref.Referrer, "semalt.com")
+		assert.NotContains(t, ref.Referrer, "free-traffic")
+	}
+	assert.Len(t, referrers, 6)
+}
+
+func TestGetReferrerCategories(t *testing.T) {
+	repo := seedReferrerRequests(t)
+	repo.SetReferrerBlocklist([]string{"semalt.com", "free-traffic-42.net"})
+
+	categories, err := repo.GetReferrerCategories(24, "", nil)
+	require.NoError(t, err)
+	require.Len(t, categories, 5)
+
+	byCategory := map[string]*ReferrerCategoryStats{}
+	for _, c := range categories {
+		byCategory[c.Category] = c
+	}
+	assert.Equal(t, int64(4), byCategory[ReferrerCategorySearch].Hits)
+	assert.Equal(t, int64(2), byCategory[ReferrerCategorySearch].Domains, "google.com and google.co.uk")
+	assert.Equal(t, int64(3), byCategory[ReferrerCategorySocial].Hits)
+	assert.Equal(t, int64(3), byCategory[ReferrerCategoryDirect].Hits)
+	assert.Equal(t, int64(2), byCategory[ReferrerCategoryInternal].Hits, "self-referrals from the request host")
+	assert.Equal(t, int64(1), byCategory[ReferrerCategoryOther].Hits, "blocked spam is not counted")
+	assert.InDelta(t, 4.0/13.0*100, byCategory[ReferrerCategorySearch].Percentage, 0.001)
+
+	// Subdomains of the given site are internal too
+	categories, err = repo.GetReferrerCategories(24, "example.com", nil)
+	require.NoError(t, err)
+	for _, c := range categories {
+		assert.Zero(t, c.Hits, "no requests were sent to example.com itself")
+	}
+}
+
+func TestClassifyReferrer(t *testing.T) {
+	assert.Equal(t, ReferrerCategorySearch, classifyReferrer("google.de", "example.com"))
+	assert.Equal(t, ReferrerCategorySearch, classifyReferrer("search.yahoo.co.jp", "example.com"))
+	assert.Equal(t, ReferrerCategorySocial, classifyReferrer("m.facebook.com", "example.com"))
+	assert.Equal(t, ReferrerCategoryInternal, classifyReferrer("docs.example.com", "example.com"))
+	assert.Equal(t, ReferrerCategoryOther, classifyReferrer("notgoogle.com", "example.com"))
+	assert.Equal(t, ReferrerCategoryOther, classifyReferrer("example.com.evil.net", "example.com"))
+}
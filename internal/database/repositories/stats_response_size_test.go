@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResponseSizeHistogram(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().Add(-time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "rs-1", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", ResponseSize: 0},
+		{RequestHash: "rs-2", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", ResponseSize: 512},
+		{RequestHash: "rs-3", ClientIP: "1.1.1.1", Timestamp: now, Host: "a.example.com", ResponseSize: 1024},
+		{RequestHash: "rs-4", ClientIP: "2.2.2.2", Timestamp: now, Host: "b.example.com", ResponseSize: 5 * 1024 * 1024},
+		// Outside the window
+		{RequestHash: "rs-old", ClientIP: "1.1.1.1", Timestamp: now.Add(-48 * time.Hour), Host: "a.example.com", ResponseSize: 200},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	buckets, err := repo.GetResponseSizeHistogram(24, nil, nil, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, buckets, len(DefaultResponseSizeBounds)+1, "empty buckets are included") {
+		assert.Equal(t, int64(0), buckets[0].MinBytes)
+		assert.Equal(t, int64(1024), *buckets[0].MaxBytes)
+		assert.Equal(t, int64(2), buckets[0].Count)
+		assert.Equal(t, int64(512), buckets[0].Bandwidth)
+		assert.Equal(t, int64(1), buckets[1].Count, "upper bounds are exclusive")
+		assert.Equal(t, int64(0), buckets[2].Count)
+		assert.Equal(t, int64(1024*1024), buckets[4].MinBytes)
+		assert.Nil(t, buckets[4].MaxBytes)
+		assert.Equal(t, int64(1), buckets[4].Count)
+	}
+
+	buckets, err = repo.GetResponseSizeHistogram(24, []int64{100}, []ServiceFilter{{Name: "a.example.com", Type: "host"}}, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, buckets, 2) {
+		assert.Equal(t, int64(1), buckets[0].Count)
+		assert.Equal(t, int64(2), buckets[1].Count)
+	}
+}
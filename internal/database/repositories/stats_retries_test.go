@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRetriedRequests(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := []struct {
+		backend, host, path string
+		retries, status     int
+		age                 time.Duration
+	}{
+		{"api", "a.example.com", "/checkout", 2, 502, time.Hour},
+		{"api", "a.example.com", "/checkout", 1, 200, time.Hour},
+		{"api", "a.example.com", "/cart", 1, 200, time.Hour},
+		{"api", "a.example.com", "/", 0, 200, time.Hour},
+		{"", "b.example.com", "/", 0, 500, time.Hour},
+		{"", "b.example.com", "/feed", 3, 503, time.Hour},
+		{"api", "a.example.com", "/checkout", 5, 502, 48 * time.Hour}, // Outside the window
+	}
+	for i, r := range requests {
+		require.NoError(t, db.Create(&models.HTTPRequest{
+			RequestHash:   "retries-" + string(rune('a'+i)),
+			ClientIP:      "1.1.1.1",
+			Timestamp:     now.Add(-r.age),
+			Host:          r.host,
+			Path:          r.path,
+			StatusCode:    r.status,
+			BackendName:   r.backend,
+			RetryAttempts: r.retries,
+		}).Error)
+	}
+
+	stats, err := repo.GetRetriedRequests(24, "", 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), stats.Requests)
+	assert.Equal(t, int64(4), stats.RetriedRequests)
+	assert.Equal(t, int64(7), stats.TotalRetries)
+	assert.InDelta(t, 66.67, stats.RetryRate, 0.01)
+
+	require.Len(t, stats.Outcomes, 4)
+	assert.Equal(t, RetryOutcome{Attempts: 0, Requests: 2, Failed: 1, FailureRate: 50}, *stats.Outcomes[0])
+	assert.Equal(t, RetryOutcome{Attempts: 1, Requests: 2, Failed: 0, FailureRate: 0}, *stats.Outcomes[1])
+	assert.Equal(t, RetryOutcome{Attempts: 2, Requests: 1, Failed: 1, FailureRate: 100}, *stats.Outcomes[2])
+	assert.Equal(t, 3, stats.Outcomes[3].Attempts)
+
+	require.Len(t, stats.Targets, 3)
+	assert.Equal(t, RetryTarget{Backend: "api", Path: "/checkout", Requests: 2, Retries: 3, MaxAttempts: 2, Failed: 1, FailureRate: 50}, *stats.Targets[0])
+	assert.Equal(t, "b.example.com", stats.Targets[1].Backend, "unnamed backends fall back to the host")
+	assert.Equal(t, "/cart", stats.Targets[2].Path)
+
+	stats, err = repo.GetRetriedRequests(24, "a.example.com", 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), stats.Requests)
+	require.Len(t, stats.Targets, 1)
+	assert.Equal(t, "/checkout", stats.Targets[0].Path)
+
+	// No retries: counts only, empty lists
+	stats, err = repo.GetRetriedRequests(24, "c.example.com", 10, nil)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Requests)
+	assert.Empty(t, stats.Outcomes)
+	assert.Empty(t, stats.Targets)
+}
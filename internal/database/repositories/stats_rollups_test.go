@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsIncludeRolledUpHours(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+	rolledHour := now.Add(-50 * time.Hour).Truncate(time.Hour)
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "raw-1", ClientIP: "1.1.1.1", Timestamp: now.Add(-time.Hour), Host: "a.example.com", StatusCode: 200, ResponseSize: 100, ResponseTimeMs: 30},
+		{RequestHash: "raw-2", ClientIP: "2.2.2.2", Timestamp: now.Add(-time.Hour), Host: "b.example.com", StatusCode: 500, ResponseSize: 10, ResponseTimeMs: 10},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	rollups := []models.HourlyRollup{
+		{Timestamp: rolledHour, Host: "a.example.com", StatusCode: 200, Requests: 8, Bandwidth: 800, BytesIn: 80, ResponseTimeSum: 160, TimedRequests: 8, UniqueVisitors: 3},
+		{Timestamp: rolledHour, Host: "a.example.com", StatusCode: 404, Requests: 2, Bandwidth: 20, TimedRequests: 0, UniqueVisitors: 1},
+		// Outside a 24h window
+		{Timestamp: now.AddDate(0, 0, -40).Truncate(time.Hour), Host: "a.example.com", StatusCode: 200, Requests: 1000, Bandwidth: 1},
+	}
+	assert.NoError(t, db.Create(&rollups).Error)
+
+	summary, err := repo.GetSummary(72, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), summary.TotalRequests)
+	assert.Equal(t, int64(9), summary.ValidRequests)
+	assert.Equal(t, int64(3), summary.FailedRequests)
+	assert.Equal(t, int64(930), summary.TotalBandwidth)
+	assert.InDelta(t, 20.0, summary.AvgResponseTime, 0.001, "(30+10+160) ms over 10 timed requests")
+	assert.Equal(t, int64(2), summary.UniqueVisitors, "distinct visitors only count raw requests")
+
+	summary, err = repo.GetSummary(24, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), summary.TotalRequests)
+
+	summary, err = repo.GetSummary(72, []ServiceFilter{{Name: "b.example.com", Type: "host"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), summary.TotalRequests)
+
+	timeline, err := repo.GetTimelineStats(72, nil, nil)
+	assert.NoError(t, err)
+	var total int64
+	for i, point := range timeline {
+		total += point.Requests
+		if i > 0 {
+			assert.Less(t, timeline[i-1].Hour, point.Hour)
+		}
+	}
+	assert.Equal(t, int64(12), total)
+
+	bandwidth, err := repo.GetBandwidthTimeline(72, nil, nil)
+	assert.NoError(t, err)
+	var bytesIn, bytesOut int64
+	for _, point := range bandwidth {
+		bytesIn += point.BytesIn
+		bytesOut += point.BytesOut
+	}
+	assert.Equal(t, int64(80), bytesIn)
+	assert.Equal(t, int64(930), bytesOut)
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampledRequestsAreScaledUp(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := []models.HTTPRequest{
+		// A 1-in-10 sampled success stands for ten requests, errors kept in full count once
+		{RequestHash: "sampled-a", ClientIP: "1.1.1.1", Timestamp: now.Add(-30 * time.Minute), Host: "a.example.com", Path: "/", StatusCode: 200, ResponseSize: 100, SampleWeight: 10},
+		{RequestHash: "sampled-b", ClientIP: "1.1.1.2", Timestamp: now.Add(-30 * time.Minute), Host: "a.example.com", Path: "/", StatusCode: 500, ResponseSize: 50, SampleWeight: 1},
+		{RequestHash: "sampled-c", ClientIP: "1.1.1.3", Timestamp: now.Add(-30 * time.Minute), Host: "a.example.com", Path: "/", StatusCode: 404, ResponseSize: 50, SampleWeight: 1},
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	summary, err := repo.GetSummary(24, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), summary.TotalRequests)
+	assert.Equal(t, int64(10), summary.ValidRequests)
+	assert.Equal(t, int64(2), summary.FailedRequests)
+	assert.Equal(t, int64(1100), summary.TotalBandwidth)
+	assert.Equal(t, int64(3), summary.UniqueVisitors, "visitors are not scaled")
+
+	timeline, err := repo.GetTimelineStats(24, nil, nil)
+	require.NoError(t, err)
+	var total int64
+	for _, point := range timeline {
+		total += point.Requests
+	}
+	assert.Equal(t, int64(12), total)
+
+	statuses, err := repo.GetStatusCodeDistribution(24, nil, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	assert.Equal(t, StatusCodeStats{StatusCode: 200, Count: 10}, *statuses[0])
+}
+
+func TestSampledTopListsAreScaledUp(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	// One sampled request stands for ten; three unsampled ones count once each
+	requests := []models.HTTPRequest{
+		{RequestHash: "top-a", ClientIP: "1.1.1.1", Timestamp: now.Add(-30 * time.Minute), Host: "a.example.com", Method: "GET", Path: "/", StatusCode: 200, ResponseSize: 100, GeoCountry: "US", SampleWeight: 10},
+		{RequestHash: "top-b", ClientIP: "2.2.2.2", Timestamp: now.Add(-30 * time.Minute), Host: "a.example.com", Method: "POST", Path: "/login", StatusCode: 200, ResponseSize: 100, GeoCountry: "DE", SampleWeight: 1},
+		{RequestHash: "top-c", ClientIP: "2.2.2.2", Timestamp: now.Add(-20 * time.Minute), Host: "a.example.com", Method: "POST", Path: "/login", StatusCode: 200, ResponseSize: 100, GeoCountry: "DE", SampleWeight: 1},
+		{RequestHash: "top-d", ClientIP: "2.2.2.2", Timestamp: now.Add(-10 * time.Minute), Host: "a.example.com", Method: "POST", Path: "/login", StatusCode: 200, ResponseSize: 100, GeoCountry: "DE", SampleWeight: 1},
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	paths, err := repo.GetTopPaths(24, 10, PathOrderHits, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	assert.Equal(t, "/", paths[0].Path)
+	assert.Equal(t, int64(10), paths[0].Hits)
+	assert.Equal(t, int64(1000), paths[0].TotalBandwidth)
+	assert.Equal(t, int64(3), paths[1].Hits)
+
+	countries, err := repo.GetTopCountries(24, 10, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, countries, 2)
+	assert.Equal(t, "US", countries[0].Country)
+	assert.Equal(t, int64(10), countries[0].Hits)
+
+	ips, err := repo.GetTopIPAddresses(24, 10, nil, nil, "", nil)
+	require.NoError(t, err)
+	require.Len(t, ips, 2)
+	assert.Equal(t, "1.1.1.1", ips[0].IPAddress)
+	assert.Equal(t, int64(10), ips[0].Hits)
+
+	methods, err := repo.GetMethodDistribution(24, nil, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, methods)
+	assert.Equal(t, MethodStats{Method: "GET", Count: 10}, *methods[0])
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSlowRequests(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "slow-1", ClientIP: "1.1.1.1", Timestamp: now.Add(-time.Hour), Host: "a.example.com", Path: "/search", Method: "GET", StatusCode: 200, ResponseTimeMs: 4200, BackendName: "api"},
+		{RequestHash: "slow-2", ClientIP: "2.2.2.2", Timestamp: now.Add(-2 * time.Hour), Host: "b.example.com", Path: "/report", Method: "POST", StatusCode: 504, ResponseTimeMs: 30000, BackendName: "reports"},
+		{RequestHash: "slow-3", ClientIP: "1.1.1.1", Timestamp: now.Add(-time.Hour), Host: "a.example.com", Path: "/", Method: "GET", StatusCode: 200, ResponseTimeMs: 1000},
+		{RequestHash: "slow-4", ClientIP: "3.3.3.3", Timestamp: now.Add(-time.Hour), Host: "a.example.com", Path: "/list", Method: "GET", StatusCode: 200, ResponseTimeMs: 600},
+		// Outside a 24h window
+		{RequestHash: "slow-5", ClientIP: "1.1.1.1", Timestamp: now.Add(-48 * time.Hour), Host: "a.example.com", Path: "/old", Method: "GET", StatusCode: 200, ResponseTimeMs: 90000},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	slow, err := repo.GetSlowRequests(24, 1000, 10, nil, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, slow, 2, "the threshold is exclusive") {
+		assert.Equal(t, "/report", slow[0].Path, "slowest first")
+		assert.Equal(t, "b.example.com", slow[0].Host)
+		assert.Equal(t, "reports", slow[0].BackendName)
+		assert.Equal(t, 504, slow[0].StatusCode)
+		assert.Equal(t, 30000.0, slow[0].ResponseTimeMs)
+		assert.WithinDuration(t, now.Add(-2*time.Hour), slow[0].Timestamp, time.Second)
+		assert.Equal(t, "/search", slow[1].Path)
+	}
+
+	// Below the index cutoff
+	slow, err = repo.GetSlowRequests(24, 500, 10, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, slow, 4)
+
+	slow, err = repo.GetSlowRequests(24, 500, 1, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, slow, 1)
+
+	slow, err = repo.GetSlowRequests(24, 500, 10, []ServiceFilter{{Name: "a.example.com", Type: "host"}}, &ExcludeIPFilter{ClientIPs: []string{"3.3.3.3"}})
+	assert.NoError(t, err)
+	assert.Len(t, slow, 2)
+
+	slow, err = repo.GetSlowRequests(72, 0, 10, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, slow, 3, "a non-positive threshold falls back to 1000ms")
+	assert.Equal(t, "/old", slow[0].Path)
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStatusRangeNarrowsTopLists(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	seed := []struct {
+		ip, path, agent string
+		status          int
+	}{
+		{"1.1.1.1", "/", "agent-ok", 200},
+		{"1.1.1.1", "/", "agent-ok", 200},
+		{"1.1.1.1", "/", "agent-ok", 200},
+		{"2.2.2.2", "/missing", "agent-scan", 404},
+		{"2.2.2.2", "/missing", "agent-scan", 404},
+		{"3.3.3.3", "/admin", "agent-scan", 403},
+		{"4.4.4.4", "/api", "agent-ok", 502},
+	}
+	requests := make([]models.HTTPRequest, len(seed))
+	for i, s := range seed {
+		requests[i] = models.HTTPRequest{
+			RequestHash: fmt.Sprintf("status-range-%d", i),
+			ClientIP:    s.ip,
+			Timestamp:   now.Add(-time.Duration(i+1) * time.Minute),
+			Host:        "a.example.com",
+			Path:        s.path,
+			UserAgent:   s.agent,
+			StatusCode:  s.status,
+		}
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	clientErrors := repo.WithStatusRange(400, 499)
+
+	paths, err := clientErrors.GetTopPaths(1, 10, "", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	assert.Equal(t, "/missing", paths[0].Path)
+	assert.Equal(t, int64(2), paths[0].Hits)
+	assert.Equal(t, "/admin", paths[1].Path)
+
+	ips, err := clientErrors.GetTopIPAddresses(1, 10, nil, nil, "", nil)
+	require.NoError(t, err)
+	require.Len(t, ips, 2)
+	assert.Equal(t, "2.2.2.2", ips[0].IPAddress)
+
+	agents, err := clientErrors.GetTopUserAgents(1, 10, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, agents, 1)
+	assert.Equal(t, "agent-scan", agents[0].UserAgent)
+	assert.Equal(t, int64(3), agents[0].Count)
+
+	// Open-ended range: everything from 403 up
+	paths, err = repo.WithStatusRange(403, 0).GetTopPaths(1, 10, "", nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, paths, 3)
+
+	// The original repository is not affected
+	paths, err = repo.GetTopPaths(1, 10, "", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, paths, 4)
+	assert.Equal(t, "/", paths[0].Path)
+}
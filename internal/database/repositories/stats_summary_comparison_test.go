@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSummaryComparison(t *testing.T) {
+	db, repo := setupTestDB(t)
+	end := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	requests := []models.HTTPRequest{
+		// Previous week: 2 requests, one failed
+		{RequestHash: "cmp-1", ClientIP: "1.1.1.1", Host: "shop.example.com", StatusCode: 200, ResponseSize: 100, ResponseTimeMs: 10, Timestamp: end.Add(-10 * 24 * time.Hour)},
+		{RequestHash: "cmp-2", ClientIP: "1.1.1.1", Host: "shop.example.com", StatusCode: 500, ResponseSize: 100, ResponseTimeMs: 30, Timestamp: end.Add(-9 * 24 * time.Hour)},
+		// Current week: 4 requests, one failed
+		{RequestHash: "cmp-3", ClientIP: "2.2.2.2", Host: "shop.example.com", StatusCode: 200, ResponseSize: 50, ResponseTimeMs: 10, Timestamp: end.Add(-3 * 24 * time.Hour)},
+		{RequestHash: "cmp-4", ClientIP: "2.2.2.2", Host: "shop.example.com", StatusCode: 200, ResponseSize: 50, ResponseTimeMs: 10, Timestamp: end.Add(-2 * 24 * time.Hour)},
+		{RequestHash: "cmp-5", ClientIP: "2.2.2.2", Host: "shop.example.com", StatusCode: 404, ResponseSize: 50, ResponseTimeMs: 10, Timestamp: end.Add(-24 * time.Hour)},
+		{RequestHash: "cmp-6", ClientIP: "2.2.2.2", Host: "shop.example.com", StatusCode: 200, ResponseSize: 50, ResponseTimeMs: 10, Timestamp: end.Add(-time.Hour)},
+		// Other host, ignored by the host filter
+		{RequestHash: "cmp-7", ClientIP: "3.3.3.3", Host: "blog.example.com", StatusCode: 200, ResponseSize: 1000, Timestamp: end.Add(-time.Hour)},
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	comparison, err := repo.GetSummaryComparison("shop.example.com", end.Add(-week), end, end.Add(-2*week), end.Add(-week), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), comparison.Current.TotalRequests)
+	assert.Equal(t, int64(2), comparison.Previous.TotalRequests)
+
+	require.NotNil(t, comparison.Deltas.Requests)
+	assert.InDelta(t, 100.0, *comparison.Deltas.Requests, 0.001)
+	require.NotNil(t, comparison.Deltas.Bandwidth)
+	assert.InDelta(t, 0.0, *comparison.Deltas.Bandwidth, 0.001)
+	require.NotNil(t, comparison.Deltas.ErrorRate)
+	assert.InDelta(t, -50.0, *comparison.Deltas.ErrorRate, 0.001, "error rate fell from 50% to 25%")
+	require.NotNil(t, comparison.Deltas.AvgResponseTime)
+	assert.InDelta(t, -50.0, *comparison.Deltas.AvgResponseTime, 0.001)
+
+	// An empty previous window leaves the deltas undefined
+	comparison, err = repo.GetSummaryComparison("", end.Add(-week), end, end.Add(-4*week), end.Add(-3*week), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), comparison.Current.TotalRequests)
+	assert.Nil(t, comparison.Deltas.Requests)
+	assert.Nil(t, comparison.Deltas.ErrorRate)
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSuspiciousIPs(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	var requests []models.HTTPRequest
+	add := func(ip string, n int, status func(i int) int, path func(i int) string) {
+		for i := 0; i < n; i++ {
+			requests = append(requests, models.HTTPRequest{
+				RequestHash: fmt.Sprintf("suspicious-%s-%d", ip, i),
+				ClientIP:    ip,
+				Timestamp:   now.Add(-time.Hour).Add(time.Duration(i) * time.Second),
+				Host:        "a.example.com",
+				Path:        path(i),
+				StatusCode:  status(i),
+			})
+		}
+	}
+	// Scanner: 120 requests in two minutes, nearly all 404 on distinct paths
+	add("6.6.6.6", 120, func(i int) int {
+		if i%20 == 0 {
+			return 200
+		}
+		return 404
+	}, func(i int) string { return fmt.Sprintf("/probe/%d", i) })
+	// Busy but healthy client with a handful of 404s
+	add("1.1.1.1", 60, func(i int) int {
+		if i < 3 {
+			return 404
+		}
+		return 200
+	}, func(int) string { return "/" })
+	// Errors only, but too few requests to be scored
+	add("7.7.7.7", 10, func(int) int { return 401 }, func(int) string { return "/login" })
+	// No client errors at all
+	add("2.2.2.2", 50, func(int) int { return 200 }, func(int) string { return "/" })
+	assert.NoError(t, db.CreateInBatches(&requests, 100).Error)
+
+	ips, err := repo.GetSuspiciousIPs(24, 10, nil, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, ips, 2) {
+		scanner := ips[0]
+		assert.Equal(t, "6.6.6.6", scanner.ClientIP)
+		assert.Equal(t, int64(120), scanner.Requests)
+		assert.Equal(t, int64(114), scanner.ClientErrors)
+		assert.InDelta(t, 0.95, scanner.ErrorRatio, 0.001)
+		assert.Equal(t, int64(120), scanner.UniquePaths)
+		assert.InDelta(t, 120.0/(119.0/60), scanner.RequestsPerMinute, 0.01)
+		assert.InDelta(t, 50*0.95+30+20, scanner.Score, 0.1)
+
+		assert.Equal(t, "1.1.1.1", ips[1].ClientIP)
+		assert.Less(t, ips[1].Score, scanner.Score)
+	}
+
+	ips, err = repo.GetSuspiciousIPs(24, 10, nil, &ExcludeIPFilter{ClientIPs: []string{"6.6.6.6"}})
+	assert.NoError(t, err)
+	assert.Len(t, ips, 1)
+
+	ips, err = repo.GetSuspiciousIPs(24, 1, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, ips, 1)
+	assert.Equal(t, "6.6.6.6", ips[0].ClientIP)
+}
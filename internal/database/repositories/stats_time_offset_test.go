@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeOffsetShiftsWindow(t *testing.T) {
+	db, repo := setupTestDB(t)
+	ip := "5.6.7.8"
+	now := time.Now()
+
+	requests := []models.HTTPRequest{
+		{RequestHash: "offset-1", ClientIP: ip, Timestamp: now.Add(-30 * time.Minute), BackendName: "svc-a", StatusCode: 200},
+		{RequestHash: "offset-2", ClientIP: ip, Timestamp: now.Add(-3*time.Hour - 30*time.Minute), BackendName: "svc-a", StatusCode: 200},
+		{RequestHash: "offset-3", ClientIP: ip, Timestamp: now.Add(-3*time.Hour - 45*time.Minute), BackendName: "svc-a", StatusCode: 500},
+		{RequestHash: "offset-4", ClientIP: ip, Timestamp: now.Add(-6 * time.Hour), BackendName: "svc-a", StatusCode: 200},
+	}
+	assert.NoError(t, db.Create(&requests).Error)
+
+	// Last hour: only the most recent request
+	stats, err := repo.GetIPDetailedStats(ip, 1, []ServiceFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.TotalRequests)
+
+	// The hour that started 4 hours ago: [now-4h, now-3h]
+	shifted := repo.WithTimeOffset(3 * time.Hour)
+	stats, err = shifted.GetIPDetailedStats(ip, 1, []ServiceFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.TotalRequests)
+
+	// All time with an offset keeps everything before now-3h
+	stats, err = shifted.GetIPDetailedStats(ip, 0, []ServiceFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalRequests)
+
+	// The original repository is not affected
+	stats, err = repo.GetIPDetailedStats(ip, 0, []ServiceFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), stats.TotalRequests)
+}
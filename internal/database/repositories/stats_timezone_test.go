@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZoneTransitions(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	require.NoError(t, err)
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 3, 30, 1, 0, 0, 0, time.UTC),
+		time.Date(2025, 10, 26, 1, 0, 0, 0, time.UTC),
+	}, zoneTransitions(rome, since, until))
+
+	assert.Empty(t, zoneTransitions(rome, since, since.Add(48*time.Hour)))
+	assert.Equal(t, "'+3600 seconds'", zoneOffsetModifier(rome, since, since.Add(48*time.Hour)))
+}
+
+func TestTimelineBucketsAcrossDSTBoundaries(t *testing.T) {
+	db, repo := setupTestDB(t)
+	rome, err := time.LoadLocation("Europe/Rome")
+	require.NoError(t, err)
+	stats := repo.(*statsRepo)
+	stats.SetDisplayTimezone(rome)
+
+	// Spring forward on 2025-03-30 (02:00 CET -> 03:00 CEST) and fall back on
+	// 2025-10-26 (03:00 CEST -> 02:00 CET)
+	timestamps := []time.Time{
+		time.Date(2025, 3, 29, 23, 30, 0, 0, time.UTC),  // 00:30 CET, March 30
+		time.Date(2025, 3, 30, 0, 30, 0, 0, time.UTC),   // 01:30 CET
+		time.Date(2025, 3, 30, 1, 30, 0, 0, time.UTC),   // 03:30 CEST
+		time.Date(2025, 3, 30, 22, 30, 0, 0, time.UTC),  // 00:30 CEST, March 31
+		time.Date(2025, 10, 25, 22, 30, 0, 0, time.UTC), // 00:30 CEST, October 26
+		time.Date(2025, 10, 26, 0, 30, 0, 0, time.UTC),  // 02:30 CEST
+		time.Date(2025, 10, 26, 1, 30, 0, 0, time.UTC),  // 02:30 CET, the repeated hour
+		time.Date(2025, 10, 26, 23, 30, 0, 0, time.UTC), // 00:30 CET, October 27
+	}
+	for i, ts := range timestamps {
+		require.NoError(t, db.Create(&models.HTTPRequest{RequestHash: fmt.Sprintf("dst-%d", i), ClientIP: "1.1.1.1", Timestamp: ts}).Error)
+	}
+
+	d := dialectOf(db).inZone(rome, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC))
+	buckets := func(groupBy string) map[string]int {
+		var rows []struct {
+			Bucket   string
+			Requests int
+		}
+		require.NoError(t, db.Model(&models.HTTPRequest{}).
+			Select(groupBy+" as bucket, COUNT(*) as requests").Group(groupBy).Scan(&rows).Error)
+		counts := map[string]int{}
+		for _, row := range rows {
+			counts[stats.utcBucketLabel(row.Bucket)] = row.Requests
+		}
+		return counts
+	}
+
+	// Local days start at 23:00 UTC in winter and 22:00 UTC in summer
+	assert.Equal(t, map[string]int{
+		"2025-03-29T23:00:00Z": 3, // the 23-hour day
+		"2025-03-30T22:00:00Z": 1,
+		"2025-10-25T22:00:00Z": 3, // the 25-hour day
+		"2025-10-26T23:00:00Z": 1,
+	}, buckets(d.dayBucket()))
+
+	// Both 02:00 local hours of October 26 share one bucket; which instant labels it is up to time.ParseInLocation
+	repeatedHour := stats.utcBucketLabel("2025-10-26T02:00:00Z")
+	assert.Contains(t, []string{"2025-10-26T00:00:00Z", "2025-10-26T01:00:00Z"}, repeatedHour)
+	assert.Equal(t, map[string]int{
+		"2025-03-29T23:00:00Z": 1,
+		"2025-03-30T00:00:00Z": 1,
+		"2025-03-30T01:00:00Z": 1,
+		"2025-03-30T22:00:00Z": 1,
+		"2025-10-25T22:00:00Z": 1,
+		repeatedHour:           2,
+		"2025-10-26T23:00:00Z": 1,
+	}, buckets(d.hourBucket()))
+
+	var hours []int
+	require.NoError(t, db.Model(&models.HTTPRequest{}).Order("timestamp").Pluck(d.hourOfDay(), &hours).Error)
+	assert.Equal(t, []int{0, 1, 3, 0, 0, 2, 2, 0}, hours)
+
+	// Without a display timezone buckets stay in UTC
+	stats.SetDisplayTimezone(nil)
+	assert.Equal(t, "2025-03-30T00:00:00Z", stats.utcBucketLabel("2025-03-30T00:00:00Z"))
+	assert.Equal(t, 4, len(buckets(dialectOf(db).dayBucket())))
+}
+
+func TestTimelineStatsUseDisplayTimezone(t *testing.T) {
+	db, repo := setupTestDB(t)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	repo.SetDisplayTimezone(kolkata)
+
+	ts := time.Now().Add(-2 * 24 * time.Hour).UTC()
+	require.NoError(t, db.Create(&models.HTTPRequest{RequestHash: "tz-1", ClientIP: "1.1.1.1", Timestamp: ts}).Error)
+
+	// Daily buckets (30 days) start at local midnight, labelled in UTC
+	timeline, err := repo.GetTimelineStats(720, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, timeline, 1)
+	local := ts.In(kolkata)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, kolkata)
+	assert.Equal(t, midnight.UTC().Format(timelineBucketLayout), timeline[0].Hour)
+	assert.Equal(t, int64(1), timeline[0].Requests)
+}
+
+func TestTimeWindowsOnNonUTCHost(t *testing.T) {
+	for _, zone := range []string{"Asia/Tokyo", "America/New_York"} {
+		t.Run(zone, func(t *testing.T) {
+			loc, err := time.LoadLocation(zone)
+			require.NoError(t, err)
+			local := time.Local
+			time.Local = loc
+			t.Cleanup(func() { time.Local = local })
+
+			db, repo := setupTestDB(t)
+			now := time.Now().UTC()
+			require.NoError(t, db.Create(&models.HTTPRequest{RequestHash: "local-recent", ClientIP: "1.1.1.1", StatusCode: 200, Timestamp: now.Add(-30 * time.Minute)}).Error)
+			require.NoError(t, db.Create(&models.HTTPRequest{RequestHash: "local-old", ClientIP: "1.1.1.1", StatusCode: 200, Timestamp: now.Add(-20 * time.Hour)}).Error)
+
+			// Only the request from 30 minutes ago falls in the last hour, whatever the host offset
+			summary, err := repo.GetSummary(1, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), summary.TotalRequests)
+
+			timeline, err := repo.GetRecordsTimeline(1)
+			require.NoError(t, err)
+			var total int64
+			for _, point := range timeline {
+				total += point.Requests
+			}
+			assert.Equal(t, int64(2), total)
+
+			// Bounds in the host's zone, as the replay and export handlers pass them
+			logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+			requests := NewHTTPRequestRepository(db, logger)
+			end := time.Now()
+			found, err := requests.FindByTimeRange(end.Add(-time.Hour), end, 0)
+			require.NoError(t, err)
+			assert.Len(t, found, 1)
+
+			streamed := 0
+			require.NoError(t, requests.StreamByTimeRange(context.Background(), RequestExportFilter{Start: end.Add(-24 * time.Hour), End: end}, func(*models.HTTPRequest) error {
+				streamed++
+				return nil
+			}))
+			assert.Equal(t, 2, streamed)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSGrade(t *testing.T) {
+	tests := []struct {
+		version, cipher, grade string
+	}{
+		{"1.3", "TLS_AES_128_GCM_SHA256", TLSGradeSecure},
+		{"TLSv1.3", "", TLSGradeSecure},
+		{"1.2", "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256", TLSGradeSecure},
+		{"TLSv1.2", "ECDHE-RSA-AES128-GCM-SHA256", TLSGradeSecure},
+		{"1.2", "", TLSGradeSecure},
+		{"1.2", "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA", TLSGradeWeak},
+		{"TLSv1.2", "ECDHE-RSA-AES128-SHA", TLSGradeWeak},
+		{"1.2", "UNKNOWN_49171", TLSGradeWeak},
+		{"1.1", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", TLSGradeWeak},
+		{"1.0", "", TLSGradeWeak},
+		{"UNKNOWN_768", "", TLSGradeWeak},
+		{"", "", TLSGradePlaintext},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.grade, tlsGrade(tt.version, tt.cipher), "%s %s", tt.version, tt.cipher)
+	}
+}
+
+func TestGetTLSSecurityStats(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	seed := []struct {
+		version, cipher, ip, host string
+		count                     int
+	}{
+		{"1.3", "TLS_AES_128_GCM_SHA256", "1.1.1.1", "shop.example.com", 5},
+		{"1.2", "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA", "2.2.2.2", "shop.example.com", 2},
+		{"1.0", "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA", "3.3.3.3", "shop.example.com", 1},
+		{"1.0", "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA", "4.4.4.4", "shop.example.com", 1},
+		{"", "", "5.5.5.5", "shop.example.com", 1},
+		{"1.1", "", "6.6.6.6", "blog.example.com", 3},
+	}
+	requests := []models.HTTPRequest{}
+	for _, s := range seed {
+		for i := 0; i < s.count; i++ {
+			requests = append(requests, models.HTTPRequest{
+				RequestHash: fmt.Sprintf("tls-%d", len(requests)),
+				ClientIP:    s.ip,
+				Host:        s.host,
+				TLSVersion:  s.version,
+				TLSCipher:   s.cipher,
+				StatusCode:  200,
+				Timestamp:   now.Add(-time.Hour),
+			})
+		}
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	stats, err := repo.GetTLSSecurityStats(24, "shop.example.com", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), stats.TotalRequests)
+	assert.Equal(t, int64(5), stats.SecureRequests)
+	assert.Equal(t, int64(4), stats.WeakRequests)
+	assert.Equal(t, int64(1), stats.PlaintextRequests)
+	assert.InDelta(t, 50.0, stats.SecurePercentage, 0.001)
+	assert.InDelta(t, 40.0, stats.WeakPercentage, 0.001)
+	assert.InDelta(t, 10.0, stats.PlaintextPercentage, 0.001)
+
+	require.Len(t, stats.Weak, 2)
+	assert.Equal(t, "1.0", stats.Weak[0].TLSVersion, "ties are broken by version")
+	assert.Equal(t, int64(2), stats.Weak[0].Requests)
+	assert.Equal(t, int64(2), stats.Weak[0].UniqueVisitors)
+	assert.Equal(t, "1.2", stats.Weak[1].TLSVersion)
+
+	// Without a host every site counts
+	stats, err = repo.GetTLSSecurityStats(24, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), stats.TotalRequests)
+	assert.Equal(t, int64(7), stats.WeakRequests)
+	assert.Equal(t, "1.1", stats.Weak[0].TLSVersion)
+}
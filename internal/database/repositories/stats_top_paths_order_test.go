@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTopPathsOrderBy(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+
+	seed := []struct {
+		path           string
+		count          int
+		size           int64
+		responseTimeMs float64
+	}{
+		{"/", 5, 1_000, 5},
+		{"/download", 2, 50_000_000, 40},
+		{"/report", 1, 2_000, 900},
+	}
+	var requests []models.HTTPRequest
+	for _, s := range seed {
+		for i := 0; i < s.count; i++ {
+			requests = append(requests, models.HTTPRequest{
+				RequestHash:    fmt.Sprintf("order-%s-%d", s.path, i),
+				ClientIP:       fmt.Sprintf("10.0.0.%d", i+1),
+				Timestamp:      now.Add(-time.Duration(i+1) * time.Minute),
+				Host:           "a.example.com",
+				Path:           s.path,
+				StatusCode:     200,
+				ResponseSize:   s.size,
+				ResponseTimeMs: s.responseTimeMs,
+			})
+		}
+	}
+	require.NoError(t, db.Create(&requests).Error)
+
+	order := func(paths []*PathStats) []string {
+		names := make([]string, len(paths))
+		for i, p := range paths {
+			names[i] = p.Path
+		}
+		return names
+	}
+
+	for _, hours := range []int{1, 0} {
+		for orderBy, expected := range map[string][]string{
+			"":                       {"/", "/download", "/report"},
+			PathOrderHits:            {"/", "/download", "/report"},
+			"unknown":                {"/", "/download", "/report"},
+			PathOrderBandwidth:       {"/download", "/", "/report"},
+			PathOrderAvgResponseTime: {"/report", "/download", "/"},
+		} {
+			paths, err := repo.GetTopPaths(hours, 10, orderBy, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, expected, order(paths), "hours=%d orderBy=%q", hours, orderBy)
+		}
+
+		// The limit keeps the top paths of the chosen metric, not of hits
+		paths, err := repo.GetTopPaths(hours, 1, PathOrderBandwidth, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, paths, 1)
+		assert.Equal(t, "/download", paths[0].Path)
+		assert.Equal(t, int64(2), paths[0].Hits)
+		assert.Equal(t, int64(100_000_000), paths[0].TotalBandwidth)
+	}
+
+	// Filtered windows go through the plain aggregation
+	paths, err := repo.GetTopPaths(1, 1, PathOrderAvgResponseTime, []ServiceFilter{{Name: "a.example.com", Type: "host"}}, nil)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	assert.Equal(t, "/report", paths[0].Path)
+}
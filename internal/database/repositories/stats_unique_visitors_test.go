@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueVisitorTimeline(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now()
+	day := func(n int) time.Time { return now.Add(-time.Duration(n)*24*time.Hour - time.Hour) }
+
+	visits := []struct {
+		ip   string
+		host string
+		at   time.Time
+	}{
+		// Three days ago: A twice, B
+		{"1.1.1.1", "a.example.com", day(3)},
+		{"1.1.1.1", "a.example.com", day(3).Add(time.Minute)},
+		{"2.2.2.2", "a.example.com", day(3)},
+		// Two days ago: A returns, C is new
+		{"1.1.1.1", "a.example.com", day(2)},
+		{"3.3.3.3", "a.example.com", day(2)},
+		// Yesterday: B and C return, D is new on another host
+		{"2.2.2.2", "a.example.com", day(1)},
+		{"3.3.3.3", "a.example.com", day(1)},
+		{"4.4.4.4", "b.example.com", day(1)},
+	}
+	for i, v := range visits {
+		require.NoError(t, db.Create(&models.HTTPRequest{
+			RequestHash: fmt.Sprintf("visitors-%d", i),
+			ClientIP:    v.ip,
+			Host:        v.host,
+			Timestamp:   v.at,
+		}).Error)
+	}
+
+	// 30 days uses daily buckets
+	timeline, err := repo.GetUniqueVisitorTimeline(720, "", nil)
+	require.NoError(t, err)
+	require.Len(t, timeline, 3)
+
+	assert.Equal(t, UniqueVisitorData{Hour: timeline[0].Hour, Visitors: 2, NewVisitors: 2, ReturningVisitors: 0, CumulativeVisitors: 2}, *timeline[0])
+	assert.Equal(t, UniqueVisitorData{Hour: timeline[1].Hour, Visitors: 2, NewVisitors: 1, ReturningVisitors: 1, CumulativeVisitors: 3}, *timeline[1])
+	assert.Equal(t, UniqueVisitorData{Hour: timeline[2].Hour, Visitors: 3, NewVisitors: 1, ReturningVisitors: 2, CumulativeVisitors: 4}, *timeline[2])
+	assert.Less(t, timeline[0].Hour, timeline[1].Hour)
+
+	// Host filter: D on b.example.com disappears
+	timeline, err = repo.GetUniqueVisitorTimeline(720, "a.example.com", nil)
+	require.NoError(t, err)
+	require.Len(t, timeline, 3)
+	assert.Equal(t, int64(2), timeline[2].Visitors)
+	assert.Equal(t, int64(3), timeline[2].CumulativeVisitors)
+
+	// Excluded IPs are not visitors
+	timeline, err = repo.GetUniqueVisitorTimeline(720, "", &ExcludeIPFilter{ClientIPs: []string{"1.1.1.1"}})
+	require.NoError(t, err)
+	require.Len(t, timeline, 3)
+	assert.Equal(t, int64(1), timeline[0].Visitors)
+	assert.Equal(t, int64(3), timeline[2].CumulativeVisitors)
+}
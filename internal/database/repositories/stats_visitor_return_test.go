@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVisitorReturnStats(t *testing.T) {
+	db, repo := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.IPReputation{}))
+	now := time.Now()
+
+	requests := []struct {
+		ip, host string
+		age      time.Duration
+	}{
+		{"1.1.1.1", "a.example.com", time.Hour},      // New
+		{"1.1.1.1", "a.example.com", 2 * time.Hour},  // New
+		{"2.2.2.2", "a.example.com", time.Hour},      // Returning: stored request before the window
+		{"2.2.2.2", "b.example.com", 72 * time.Hour}, // Outside the window, on another host
+		{"3.3.3.3", "a.example.com", time.Hour},      // Returning: looked up before the window
+		{"4.4.4.4", "b.example.com", time.Hour},      // New, looked up inside the window
+	}
+	for i, r := range requests {
+		require.NoError(t, db.Create(&models.HTTPRequest{
+			RequestHash: "return-" + string(rune('a'+i)),
+			ClientIP:    r.ip,
+			Timestamp:   now.Add(-r.age),
+			Host:        r.host,
+			Path:        "/",
+			StatusCode:  200,
+		}).Error)
+	}
+	for ip, firstSeen := range map[string]time.Time{"3.3.3.3": now.Add(-30 * 24 * time.Hour), "4.4.4.4": now.Add(-time.Hour)} {
+		require.NoError(t, db.Create(&models.IPReputation{IPAddress: ip, FirstSeen: firstSeen, LastSeen: firstSeen}).Error)
+	}
+
+	stats, err := repo.GetVisitorReturnStats(24, "", nil)
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(-24*time.Hour), stats.WindowStart, time.Minute)
+	assert.Equal(t, int64(4), stats.Visitors)
+	assert.Equal(t, int64(2), stats.NewVisitors)
+	assert.Equal(t, int64(2), stats.ReturningVisitors)
+	assert.Equal(t, 50.0, stats.ReturningRate)
+	assert.Equal(t, int64(5), stats.Requests)
+	assert.Equal(t, int64(3), stats.NewRequests)
+	assert.Equal(t, int64(2), stats.ReturningRequests)
+
+	stats, err = repo.GetVisitorReturnStats(24, "b.example.com", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Visitors)
+	assert.Equal(t, int64(1), stats.NewVisitors)
+
+	// All time: nothing precedes the window
+	stats, err = repo.GetVisitorReturnStats(0, "", nil)
+	require.NoError(t, err)
+	assert.True(t, stats.WindowStart.IsZero())
+	assert.Equal(t, int64(4), stats.NewVisitors)
+	assert.Zero(t, stats.ReturningVisitors)
+}
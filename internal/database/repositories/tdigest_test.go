@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exactQuantile returns the nearest-rank quantile the old LIMIT/OFFSET queries produced
+func exactQuantile(sorted []float64, q float64) float64 {
+	return sorted[int(float64(len(sorted)-1)*q)]
+}
+
+func TestTDigestMatchesExactQuantiles(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	values := make([]float64, 200000)
+	digest := newTDigest(defaultDigestCompression)
+	for i := range values {
+		// Log-normal, like real response times: long right tail
+		values[i] = math.Exp(rng.NormFloat64()*0.8 + 4)
+		digest.Add(values[i])
+	}
+	sort.Float64s(values)
+
+	assert.Equal(t, int64(len(values)), digest.Count())
+	assert.Equal(t, values[0], digest.Quantile(0))
+	assert.Equal(t, values[len(values)-1], digest.Quantile(1))
+	for _, q := range []float64{0.5, 0.95, 0.99} {
+		exact := exactQuantile(values, q)
+		assert.InDelta(t, exact, digest.Quantile(q), exact*0.005, "q=%v", q)
+	}
+}
+
+func TestTDigestSmallInputsAreExact(t *testing.T) {
+	digest := newTDigest(defaultDigestCompression)
+	assert.Equal(t, 0.0, digest.Quantile(0.5), "empty digest")
+
+	digest.Add(42)
+	assert.Equal(t, 42.0, digest.Quantile(0.5))
+	assert.Equal(t, 42.0, digest.Quantile(0.99))
+
+	digest = newTDigest(defaultDigestCompression)
+	for i := 1; i <= 100; i++ {
+		digest.Add(float64(i))
+	}
+	assert.InDelta(t, 50, digest.Quantile(0.5), 1)
+	assert.InDelta(t, 95, digest.Quantile(0.95), 1)
+	assert.InDelta(t, 99, digest.Quantile(0.99), 1)
+}
+
+func TestGetResponseTimeStatsPercentiles(t *testing.T) {
+	db, repo := setupTestDB(t)
+	now := time.Now().Add(-time.Hour)
+
+	requests := make([]models.HTTPRequest, 0, 201)
+	for i := 1; i <= 200; i++ {
+		requests = append(requests, models.HTTPRequest{
+			RequestHash:    fmt.Sprintf("rt-%d", i),
+			ClientIP:       "1.1.1.1",
+			Timestamp:      now,
+			ResponseTimeMs: float64(i),
+		})
+	}
+	// Requests without a response time are ignored
+	requests = append(requests, models.HTTPRequest{RequestHash: "rt-zero", ClientIP: "1.1.1.1", Timestamp: now})
+	assert.NoError(t, db.CreateInBatches(&requests, 50).Error)
+
+	stats, err := repo.GetResponseTimeStats(24, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, stats.Min)
+	assert.Equal(t, 200.0, stats.Max)
+	assert.InDelta(t, 100.5, stats.Avg, 0.001)
+	assert.InDelta(t, 100, stats.P50, 1)
+	assert.InDelta(t, 190, stats.P95, 1)
+	assert.InDelta(t, 198, stats.P99, 1)
+
+	stats, err = repo.GetResponseTimeStats(24, []ServiceFilter{{Name: "missing", Type: "host"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &ResponseTimeStats{}, stats)
+}
+
+func BenchmarkTDigestAdd(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	digest := newTDigest(defaultDigestCompression)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		digest.Add(rng.ExpFloat64() * 100)
+	}
+}
+
+// BenchmarkResponseTimePercentiles compares one digest pass with the three sorts it replaces
+func BenchmarkResponseTimePercentiles(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 1000000)
+	for i := range values {
+		values[i] = rng.ExpFloat64() * 100
+	}
+
+	b.Run("tdigest", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			digest := newTDigest(defaultDigestCompression)
+			for _, v := range values {
+				digest.Add(v)
+			}
+			_ = digest.Quantile(0.5) + digest.Quantile(0.95) + digest.Quantile(0.99)
+		}
+	})
+	b.Run("sort-per-percentile", func(b *testing.B) {
+		scratch := make([]float64, len(values))
+		for i := 0; i < b.N; i++ {
+			for _, q := range []float64{0.5, 0.95, 0.99} {
+				copy(scratch, values)
+				sort.Float64s(scratch)
+				_ = exactQuantile(scratch, q)
+			}
+		}
+	})
+}
@@ -0,0 +1,155 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Retention modes selected by DB_RETENTION_MODE
+const (
+	RetentionModeDelete = "delete" // Expired requests are deleted
+	RetentionModeRollup = "rollup" // Expired requests are aggregated into hourly_rollups, then deleted
+)
+
+// rollupColumns are the dimensions kept for rolled-up hours, matching the hourly_rollups unique key
+const rollupColumns = "source_name, host, backend_name, backend_url, status_code, is_internal"
+
+// rollupUpsertSQL aggregates one hour of expired requests; re-running an hour adds to the existing counters.
+// Rows of sampled sources count sample_weight times, like in the raw-table stats.
+// The WHERE clause is required by SQLite to parse ON CONFLICT after INSERT ... SELECT, and
+// PostgreSQL needs the existing counters qualified with the table name.
+const rollupUpsertSQL = `
+	INSERT INTO hourly_rollups (timestamp, ` + rollupColumns + `,
+		requests, bandwidth, bytes_in, response_time_sum, timed_requests, unique_visitors)
+	SELECT ?, ` + rollupColumns + `,
+		COALESCE(SUM(sample_weight), 0),
+		COALESCE(SUM(response_size * sample_weight), 0),
+		COALESCE(SUM(request_length * sample_weight), 0),
+		COALESCE(SUM(response_time_ms), 0),
+		COUNT(CASE WHEN response_time_ms > 0 THEN 1 END),
+		COUNT(DISTINCT client_ip)
+	FROM http_requests
+	WHERE %s AND timestamp >= ? AND timestamp < ?
+	GROUP BY ` + rollupColumns + `
+	ON CONFLICT (timestamp, ` + rollupColumns + `) DO UPDATE SET
+		requests = hourly_rollups.requests + excluded.requests,
+		bandwidth = hourly_rollups.bandwidth + excluded.bandwidth,
+		bytes_in = hourly_rollups.bytes_in + excluded.bytes_in,
+		response_time_sum = hourly_rollups.response_time_sum + excluded.response_time_sum,
+		timed_requests = hourly_rollups.timed_requests + excluded.timed_requests,
+		unique_visitors = hourly_rollups.unique_visitors + excluded.unique_visitors`
+
+// SetRetentionMode selects whether expired requests are deleted or rolled up first
+func (s *CleanupService) SetRetentionMode(mode string) {
+	switch mode {
+	case RetentionModeRollup:
+		s.rollupEnabled = true
+	case RetentionModeDelete, "":
+		s.rollupEnabled = false
+	default:
+		s.logger.Warn("Unknown retention mode, expired requests will be deleted",
+			s.logger.Args("mode", mode, "supported", []string{RetentionModeDelete, RetentionModeRollup}))
+		s.rollupEnabled = false
+	}
+}
+
+// retentionMode returns the active DB_RETENTION_MODE
+func (s *CleanupService) retentionMode() string {
+	if s.rollupEnabled {
+		return RetentionModeRollup
+	}
+	return RetentionModeDelete
+}
+
+// rollupOldRecords aggregates expired records into hourly_rollups and deletes them,
+// one pass per source cutoff. Returns the number of raw records removed.
+func (s *CleanupService) rollupOldRecords(now time.Time) (int64, error) {
+	conditions, args, err := s.expiryConditions(now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load retention policy: %w", err)
+	}
+
+	totalDeleted := int64(0)
+	for i, condition := range conditions {
+		deleted, err := s.rollupInHours(condition, args[i]...)
+		totalDeleted += deleted
+		if err != nil {
+			return totalDeleted, err
+		}
+	}
+	return totalDeleted, nil
+}
+
+// rollupInHours moves records matching condition into hourly_rollups, oldest hour first.
+// Each hour is aggregated and deleted in one transaction, so a failure never loses or
+// double-counts requests; an hour cut by the cutoff is completed by the next run.
+func (s *CleanupService) rollupInHours(condition string, conditionArgs ...interface{}) (int64, error) {
+	upsertSQL := fmt.Sprintf(rollupUpsertSQL, condition)
+	totalDeleted := int64(0)
+
+	for {
+		var oldest struct {
+			Timestamp time.Time
+		}
+		result := s.db.Raw(`SELECT timestamp FROM http_requests WHERE `+condition+` ORDER BY timestamp LIMIT 1`, conditionArgs...).Scan(&oldest)
+		if result.Error != nil {
+			return totalDeleted, result.Error
+		}
+		if result.RowsAffected == 0 {
+			break // Nothing left to roll up
+		}
+
+		hourStart := oldest.Timestamp.Truncate(time.Hour)
+		hourEnd := hourStart.Add(time.Hour)
+		hourArgs := append(append([]interface{}{}, conditionArgs...), hourStart, hourEnd)
+
+		var deleted int64
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(upsertSQL, append([]interface{}{hourStart}, hourArgs...)...).Error; err != nil {
+				return err
+			}
+			result := tx.Exec(`DELETE FROM http_requests WHERE `+condition+` AND timestamp >= ? AND timestamp < ?`, hourArgs...)
+			deleted = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return totalDeleted, err
+		}
+		if deleted == 0 {
+			// The oldest row did not fall in its own hour bucket; stop rather than loop forever
+			return totalDeleted, fmt.Errorf("rollup made no progress at %s", hourStart.Format(time.RFC3339))
+		}
+		totalDeleted += deleted
+
+		s.logger.Trace("Rolled up hour",
+			s.logger.Args("hour", hourStart.Format(time.RFC3339), "deleted", deleted, "total_deleted", totalDeleted))
+
+		// Small pause between hours to avoid hogging the database
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return totalDeleted, nil
+}
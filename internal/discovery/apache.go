@@ -0,0 +1,130 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package discovery
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"loglynx/internal/database/models"
+	"loglynx/internal/parser/apache"
+
+	"github.com/pterm/pterm"
+)
+
+// ApacheDetector detects Apache access logs in the combined log format
+type ApacheDetector struct {
+	logger         *pterm.Logger
+	configuredPath string
+	autoDiscover   bool
+}
+
+// NewApacheDetector creates a new Apache log detector
+func NewApacheDetector(logger *pterm.Logger) ServiceDetector {
+	autoDiscover := true
+	if autoDiscoverEnv := os.Getenv("LOG_AUTO_DISCOVER"); autoDiscoverEnv != "" {
+		autoDiscover = autoDiscoverEnv == "true"
+	}
+
+	return &ApacheDetector{
+		logger:         logger,
+		configuredPath: os.Getenv("APACHE_LOG_PATH"),
+		autoDiscover:   autoDiscover,
+	}
+}
+
+// Name returns the detector name
+func (d *ApacheDetector) Name() string {
+	return "apache"
+}
+
+// Detect returns APACHE_LOG_PATH, or the first default Apache log found by
+// auto-discovery, when it holds combined (or common) log lines
+func (d *ApacheDetector) Detect() ([]*models.LogSource, error) {
+	paths := []string{}
+
+	// Priority 1: Use APACHE_LOG_PATH if set and valid
+	if d.configuredPath != "" {
+		if fileInfo, err := os.Stat(d.configuredPath); err == nil && !fileInfo.IsDir() {
+			paths = append(paths, d.configuredPath)
+			d.logger.Info("Using configured APACHE_LOG_PATH", d.logger.Args("path", d.configuredPath))
+		} else {
+			d.logger.Warn("Configured APACHE_LOG_PATH is invalid", d.logger.Args("path", d.configuredPath, "error", err))
+		}
+	} else if d.autoDiscover {
+		// Priority 2: Distribution defaults (Debian/Ubuntu, then RHEL/Fedora)
+		paths = append(paths,
+			"/var/log/apache2/access.log",
+			"/var/log/httpd/access_log",
+		)
+	}
+
+	for _, path := range paths {
+		fileInfo, err := os.Stat(path)
+		if err != nil || fileInfo.IsDir() {
+			d.logger.Debug("Apache log path not found", d.logger.Args("path", path))
+			continue
+		}
+
+		if fileInfo.Size() == 0 {
+			d.logger.Debug("Log file is empty, skipping", d.logger.Args("path", path))
+			continue
+		}
+
+		if isApacheFormat(path, d.logger) {
+			d.logger.Info("Apache log source detected", d.logger.Args("path", path))
+			return []*models.LogSource{{
+				Name:       generateApacheSourceName(path),
+				Path:       path,
+				ParserType: "apache",
+			}}, nil
+		}
+		d.logger.Warn("Apache log file is not in the combined log format", d.logger.Args("path", path))
+	}
+
+	return []*models.LogSource{}, nil
+}
+
+// isApacheFormat checks the first line of a file against the Apache combined log format
+func isApacheFormat(path string, logger *pterm.Logger) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		logger.Debug("Failed to open file", logger.Args("path", path, "error", err))
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return false
+	}
+	return apache.NewParser(logger).CanParse(scanner.Text())
+}
+
+// generateApacheSourceName generates a source name from the file path
+func generateApacheSourceName(path string) string {
+	fileName := filepath.Base(strings.ReplaceAll(path, "\\", "/"))
+	return fmt.Sprintf("apache-%s", strings.Split(fileName, ".")[0])
+}
@@ -0,0 +1,39 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApacheDetectorValidatesFormat(t *testing.T) {
+	logger := pterm.DefaultLogger
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "access.log")
+	assert.NoError(t, os.WriteFile(valid, []byte(`10.0.0.1 - - [16/Oct/2026:10:00:00 +0000] "GET / HTTP/1.1" 200 512 "-" "curl/8.0"`+"\n"), 0o644))
+
+	t.Setenv("APACHE_LOG_PATH", valid)
+	sources, err := NewApacheDetector(&logger).Detect()
+	assert.NoError(t, err)
+	assert.Len(t, sources, 1)
+	assert.Equal(t, "apache-access", sources[0].Name)
+	assert.Equal(t, "apache", sources[0].ParserType)
+
+	invalid := filepath.Join(dir, "caddy.log")
+	assert.NoError(t, os.WriteFile(invalid, []byte(`{"level":"info","logger":"http.log.access"}`+"\n"), 0o644))
+
+	t.Setenv("APACHE_LOG_PATH", invalid)
+	sources, err = NewApacheDetector(&logger).Detect()
+	assert.NoError(t, err)
+	assert.Empty(t, sources)
+
+	t.Setenv("APACHE_LOG_PATH", "")
+	t.Setenv("LOG_AUTO_DISCOVER", "false")
+	sources, err = NewApacheDetector(&logger).Detect()
+	assert.NoError(t, err)
+	assert.Empty(t, sources)
+}
@@ -2,9 +2,9 @@ package discovery
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
 	"loglynx/internal/database/models"
+	"loglynx/internal/parser/caddy"
 	"os"
 	"strings"
 
@@ -97,7 +97,7 @@ func (d *CaddyDetector) Detect() ([]*models.LogSource, error) {
 	return sources, nil
 }
 
-// isCaddyFormat checks if a file contains Caddy JSON logs
+// isCaddyFormat checks if a file contains Caddy JSON or common/combined logs
 func isCaddyFormat(path string, logger *pterm.Logger) bool {
 	file, err := os.Open(path)
 	if err != nil {
@@ -107,21 +107,9 @@ func isCaddyFormat(path string, logger *pterm.Logger) bool {
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
-	if scanner.Scan() {
-		line := scanner.Text()
-
-		// Try to parse as JSON
-		var logEntry map[string]any
-		if err := json.Unmarshal([]byte(line), &logEntry); err == nil {
-			// Check for Caddy-specific fields
-			loggerField, hasLogger := logEntry["logger"].(string)
-			_, hasRequest := logEntry["request"]
-
-			if hasLogger && strings.HasPrefix(loggerField, "http.log.access") && hasRequest {
-				logger.Debug("File matches Caddy format", logger.Args("path", path))
-				return true
-			}
-		}
+	if scanner.Scan() && caddy.NewParser(logger).CanParse(scanner.Text()) {
+		logger.Debug("File matches Caddy format", logger.Args("path", path))
+		return true
 	}
 
 	logger.Debug("File does not match Caddy format", logger.Args("path", path))
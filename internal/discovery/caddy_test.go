@@ -0,0 +1,38 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaddyDetectorAcceptsJSONAndCLF(t *testing.T) {
+	logger := pterm.DefaultLogger
+	dir := t.TempDir()
+
+	lines := map[string]string{
+		"json.log": `{"level":"info","ts":1767690562.5,"logger":"http.log.access.log0","request":{"remote_ip":"10.0.0.1","method":"GET","uri":"/"},"status":200}`,
+		"clf.log":  `10.0.0.1 - - [16/Oct/2026:10:00:00 +0000] "GET / HTTP/1.1" 200 512 "-" "curl/8.0"`,
+	}
+	for name, line := range lines {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(line+"\n"), 0o644))
+
+		t.Setenv("CADDY_LOG_PATH", path)
+		sources, err := NewCaddyDetector(&logger).Detect()
+		assert.NoError(t, err)
+		assert.Len(t, sources, 1, name)
+		assert.Equal(t, "caddy", sources[0].ParserType)
+	}
+
+	invalid := filepath.Join(dir, "other.log")
+	assert.NoError(t, os.WriteFile(invalid, []byte(`{"timestamp":"2026-10-16","message":"hello"}`+"\n"), 0o644))
+
+	t.Setenv("CADDY_LOG_PATH", invalid)
+	sources, err := NewCaddyDetector(&logger).Detect()
+	assert.NoError(t, err)
+	assert.Empty(t, sources)
+}
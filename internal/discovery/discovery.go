@@ -22,6 +22,8 @@
 package discovery
 
 import (
+	"sync"
+
 	"loglynx/internal/database/models"
 	"loglynx/internal/database/repositories"
 
@@ -36,6 +38,7 @@ type ServiceDetector interface {
 type Engine struct {
     repo      repositories.LogSourceRepository
     detectors []ServiceDetector
+    mu        sync.Mutex // Serializes startup, periodic and on-demand runs
 }
 
 func NewEngine(repo repositories.LogSourceRepository, logger *pterm.Logger) *Engine {
@@ -44,11 +47,17 @@ func NewEngine(repo repositories.LogSourceRepository, logger *pterm.Logger) *Eng
         detectors: []ServiceDetector{
             NewTraefikDetector(logger),
             NewCaddyDetector(logger),
+            NewGenericDetector(logger),
+            NewHAProxyDetector(logger),
+            NewApacheDetector(logger),
         },
     }
 }
 
 func (e *Engine) Run(logger *pterm.Logger) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	logger.Trace("Check if the discovery is needed.")
     existing, err := e.repo.FindAll()
     if err != nil {
@@ -84,3 +93,49 @@ func (e *Engine) Run(logger *pterm.Logger) error {
     logger.Debug("Discovery completed")
     return nil
 }
+
+// Discover runs every detector even when sources already exist and registers only
+// sources not known yet (by name or path), so repeated calls are idempotent.
+// It returns the newly registered sources.
+func (e *Engine) Discover(logger *pterm.Logger) ([]*models.LogSource, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	existing, err := e.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	knownNames := make(map[string]struct{}, len(existing))
+	knownPaths := make(map[string]struct{}, len(existing))
+	for _, source := range existing {
+		knownNames[source.Name] = struct{}{}
+		knownPaths[source.Path] = struct{}{}
+	}
+
+	var added []*models.LogSource
+	for _, detector := range e.detectors {
+		sources, err := detector.Detect()
+		if err != nil {
+			logger.WithCaller().Warn("Detection failed,", logger.Args("detector", detector.Name(), "error", err))
+			continue
+		}
+
+		for _, source := range sources {
+			_, nameTaken := knownNames[source.Name]
+			_, pathTaken := knownPaths[source.Path]
+			if nameTaken || pathTaken {
+				continue
+			}
+			if err := e.repo.Create(source); err != nil {
+				logger.WithCaller().Error("Failed to register log source", logger.Args("source", source.Name, "error", err))
+				continue
+			}
+			knownNames[source.Name] = struct{}{}
+			knownPaths[source.Path] = struct{}{}
+			added = append(added, source)
+			logger.Info("Registered new log source.", logger.Args("Name", source.Name, "Path", source.Path))
+		}
+	}
+
+	return added, nil
+}
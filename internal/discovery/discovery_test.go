@@ -0,0 +1,87 @@
+package discovery
+
+import (
+	"testing"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSourceRepo struct {
+	sources []*models.LogSource
+}
+
+func (r *fakeSourceRepo) Create(source *models.LogSource) error {
+	r.sources = append(r.sources, source)
+	return nil
+}
+
+func (r *fakeSourceRepo) FindByName(name string) (*models.LogSource, error) {
+	for _, source := range r.sources {
+		if source.Name == name {
+			return source, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeSourceRepo) FindAll() ([]*models.LogSource, error) { return r.sources, nil }
+
+func (r *fakeSourceRepo) Update(source *models.LogSource) error { return nil }
+
+func (r *fakeSourceRepo) UpdateRetention(name string, retentionDays int) error { return nil }
+
+func (r *fakeSourceRepo) FindFiles(sourceName string) ([]*models.LogSourceFile, error) {
+	return nil, nil
+}
+
+func (r *fakeSourceRepo) UpdateFileTracking(sourceName string, path string, position int64, inode int64, lastLine string) error {
+	return nil
+}
+
+func (r *fakeSourceRepo) Delete(name string) error {
+	for i, source := range r.sources {
+		if source.Name == name {
+			r.sources = append(r.sources[:i], r.sources[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *fakeSourceRepo) UpdateTracking(name string, position int64, inode int64, lastLine string) error {
+	return nil
+}
+
+type fakeDetector struct {
+	sources []*models.LogSource
+}
+
+func (d *fakeDetector) Name() string { return "fake" }
+
+func (d *fakeDetector) Detect() ([]*models.LogSource, error) { return d.sources, nil }
+
+func TestDiscoverRegistersOnlyNewSources(t *testing.T) {
+	logger := pterm.DefaultLogger
+	repo := &fakeSourceRepo{sources: []*models.LogSource{{Name: "traefik-1", Path: "/logs/traefik.log", ParserType: "traefik"}}}
+	detector := &fakeDetector{sources: []*models.LogSource{
+		{Name: "traefik-1", Path: "/logs/traefik.log", ParserType: "traefik"},
+		{Name: "caddy-1", Path: "/logs/caddy.log", ParserType: "caddy"},
+	}}
+	engine := &Engine{repo: repo, detectors: []ServiceDetector{detector}}
+
+	added, err := engine.Discover(&logger)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(added))
+	assert.Equal(t, "caddy-1", added[0].Name)
+	assert.Equal(t, 2, len(repo.sources))
+
+	// A second run finds nothing new, even when a detector renames a known path
+	detector.sources = append(detector.sources, &models.LogSource{Name: "caddy-2", Path: "/logs/caddy.log", ParserType: "caddy"})
+	added, err = engine.Discover(&logger)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(added))
+	assert.Equal(t, 2, len(repo.sources))
+}
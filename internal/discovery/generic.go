@@ -0,0 +1,79 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+)
+
+// GenericDetector registers an explicitly configured JSON log for the generic parser.
+// There is no auto-discovery: arbitrary JSON logs cannot be recognised without a field map.
+type GenericDetector struct {
+	logger         *pterm.Logger
+	configuredPath string
+}
+
+// NewGenericDetector creates a new generic JSON log detector
+func NewGenericDetector(logger *pterm.Logger) ServiceDetector {
+	return &GenericDetector{
+		logger:         logger,
+		configuredPath: os.Getenv("GENERIC_LOG_PATH"),
+	}
+}
+
+// Name returns the detector name
+func (d *GenericDetector) Name() string {
+	return "generic"
+}
+
+// Detect returns the configured GENERIC_LOG_PATH as a source, if valid
+func (d *GenericDetector) Detect() ([]*models.LogSource, error) {
+	if d.configuredPath == "" {
+		return []*models.LogSource{}, nil
+	}
+
+	fileInfo, err := os.Stat(d.configuredPath)
+	if err != nil || fileInfo.IsDir() {
+		d.logger.Warn("Configured GENERIC_LOG_PATH is invalid", d.logger.Args("path", d.configuredPath, "error", err))
+		return []*models.LogSource{}, nil
+	}
+
+	d.logger.Info("Generic JSON log source configured", d.logger.Args("path", d.configuredPath))
+	return []*models.LogSource{{
+		Name:       generateGenericSourceName(d.configuredPath),
+		Path:       d.configuredPath,
+		ParserType: "generic",
+	}}, nil
+}
+
+// generateGenericSourceName generates a source name from the file path
+func generateGenericSourceName(path string) string {
+	fileName := filepath.Base(strings.ReplaceAll(path, "\\", "/"))
+	return fmt.Sprintf("generic-%s", strings.Split(fileName, ".")[0])
+}
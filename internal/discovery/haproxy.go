@@ -0,0 +1,105 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package discovery
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"loglynx/internal/database/models"
+	"loglynx/internal/parser/haproxy"
+
+	"github.com/pterm/pterm"
+)
+
+// HAProxyDetector registers the HAProxy HTTP log configured by HAPROXY_LOG_PATH.
+// HAProxy usually logs through syslog to a distribution-specific file, so there is
+// no auto-discovery; the first line is checked against the parser instead.
+type HAProxyDetector struct {
+	logger         *pterm.Logger
+	configuredPath string
+}
+
+// NewHAProxyDetector creates a new HAProxy log detector
+func NewHAProxyDetector(logger *pterm.Logger) ServiceDetector {
+	return &HAProxyDetector{
+		logger:         logger,
+		configuredPath: os.Getenv("HAPROXY_LOG_PATH"),
+	}
+}
+
+// Name returns the detector name
+func (d *HAProxyDetector) Name() string {
+	return "haproxy"
+}
+
+// Detect returns the configured HAPROXY_LOG_PATH as a source when it holds HAProxy HTTP logs
+func (d *HAProxyDetector) Detect() ([]*models.LogSource, error) {
+	if d.configuredPath == "" {
+		return []*models.LogSource{}, nil
+	}
+
+	fileInfo, err := os.Stat(d.configuredPath)
+	if err != nil || fileInfo.IsDir() {
+		d.logger.Warn("Configured HAPROXY_LOG_PATH is invalid", d.logger.Args("path", d.configuredPath, "error", err))
+		return []*models.LogSource{}, nil
+	}
+
+	// An empty file is accepted: HAProxy may not have logged a request yet
+	if fileInfo.Size() > 0 && !isHAProxyFormat(d.configuredPath, d.logger) {
+		d.logger.Warn("Configured HAPROXY_LOG_PATH does not contain HAProxy HTTP logs (option httplog)",
+			d.logger.Args("path", d.configuredPath))
+		return []*models.LogSource{}, nil
+	}
+
+	d.logger.Info("HAProxy log source detected", d.logger.Args("path", d.configuredPath))
+	return []*models.LogSource{{
+		Name:       generateHAProxySourceName(d.configuredPath),
+		Path:       d.configuredPath,
+		ParserType: "haproxy",
+	}}, nil
+}
+
+// isHAProxyFormat checks the first line of a file against the HAProxy HTTP log format
+func isHAProxyFormat(path string, logger *pterm.Logger) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		logger.Debug("Failed to open file", logger.Args("path", path, "error", err))
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return false
+	}
+	return haproxy.NewParser(logger).CanParse(scanner.Text())
+}
+
+// generateHAProxySourceName generates a source name from the file path
+func generateHAProxySourceName(path string) string {
+	fileName := filepath.Base(strings.ReplaceAll(path, "\\", "/"))
+	return fmt.Sprintf("haproxy-%s", strings.Split(fileName, ".")[0])
+}
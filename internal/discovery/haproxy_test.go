@@ -0,0 +1,38 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHAProxyDetectorValidatesFormat(t *testing.T) {
+	logger := pterm.DefaultLogger
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "haproxy.log")
+	assert.NoError(t, os.WriteFile(valid, []byte(`Oct 16 10:00:00 lb haproxy[812]: 10.0.0.1:5000 [16/Oct/2026:10:00:00.123] fe be/srv1 0/0/1/2/3 200 512 - - ---- 1/1/0/0/0 0/0 "GET / HTTP/1.1"`+"\n"), 0o644))
+
+	t.Setenv("HAPROXY_LOG_PATH", valid)
+	sources, err := NewHAProxyDetector(&logger).Detect()
+	assert.NoError(t, err)
+	assert.Len(t, sources, 1)
+	assert.Equal(t, "haproxy-haproxy", sources[0].Name)
+	assert.Equal(t, "haproxy", sources[0].ParserType)
+
+	invalid := filepath.Join(dir, "access.log")
+	assert.NoError(t, os.WriteFile(invalid, []byte(`{"level":"info","logger":"http.log.access"}`+"\n"), 0o644))
+
+	t.Setenv("HAPROXY_LOG_PATH", invalid)
+	sources, err = NewHAProxyDetector(&logger).Detect()
+	assert.NoError(t, err)
+	assert.Empty(t, sources)
+
+	t.Setenv("HAPROXY_LOG_PATH", "")
+	sources, err = NewHAProxyDetector(&logger).Detect()
+	assert.NoError(t, err)
+	assert.Empty(t, sources)
+}
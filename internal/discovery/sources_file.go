@@ -0,0 +1,264 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceDefinition declares one log source in the sources file
+type SourceDefinition struct {
+	Name          string            `yaml:"name"`
+	Path          string            `yaml:"path"`
+	Parser        string            `yaml:"parser"`
+	RetentionDays int               `yaml:"retention_days"`     // 0 = DB_RETENTION_DAYS
+	Dedup         *bool             `yaml:"dedup"`              // false keeps identical requests on different lines (default true)
+	Multiline     string            `yaml:"multiline"`          // Regex matching the first line of each entry; other lines are appended to it
+	HashFields    []string          `yaml:"hash_fields"`        // Request hash fields added, or removed with a "-" prefix, on top of DEDUP_HASH_FIELDS
+	SampleRate    int               `yaml:"sample_rate"`        // Keep 1 in N requests (0 or 1 = keep all)
+	SampleErrors  bool              `yaml:"sample_keep_errors"` // With sample_rate, keep every request with status 400 or above
+	Options       map[string]string `yaml:"options"`            // Parser-specific settings, stored with the source
+}
+
+// disableDedup reports whether the definition opts out of request deduplication
+func (d SourceDefinition) disableDedup() bool {
+	return d.Dedup != nil && !*d.Dedup
+}
+
+// hashFields returns the definition's hash fields in the form stored on the source
+func (d SourceDefinition) hashFields() string {
+	return strings.Join(d.HashFields, ",")
+}
+
+// SourcesFile is the sources file layout
+type SourcesFile struct {
+	Sources []SourceDefinition `yaml:"sources"`
+}
+
+// LoadSourceDefinitions reads source definitions from a YAML or JSON file path,
+// or from the value itself when it does not point to a file
+func LoadSourceDefinitions(value string) ([]SourceDefinition, error) {
+	data := []byte(value)
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		data, err = os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sources file: %w", err)
+		}
+	}
+
+	// JSON is valid YAML, so a single decoder handles both formats
+	var file SourcesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid sources file: %w", err)
+	}
+
+	names := make(map[string]struct{}, len(file.Sources))
+	paths := make(map[string]struct{}, len(file.Sources))
+	for i := range file.Sources {
+		def := &file.Sources[i]
+		def.Name = strings.TrimSpace(def.Name)
+		def.Path = strings.TrimSpace(def.Path)
+		def.Parser = strings.TrimSpace(def.Parser)
+
+		if def.Name == "" {
+			return nil, fmt.Errorf("source %d must define name", i+1)
+		}
+		if def.Path == "" {
+			return nil, fmt.Errorf("source %q must define path", def.Name)
+		}
+		if models.HasURLCredentials(def.Path) {
+			return nil, fmt.Errorf("source %q: path must not contain credentials, use REMOTE_SOURCE_HEADERS", def.Name)
+		}
+		if def.Parser == "" {
+			return nil, fmt.Errorf("source %q must define parser", def.Name)
+		}
+		if def.RetentionDays < 0 {
+			return nil, fmt.Errorf("source %q: retention_days cannot be negative", def.Name)
+		}
+		if def.SampleRate < 0 {
+			return nil, fmt.Errorf("source %q: sample_rate cannot be negative", def.Name)
+		}
+		if def.Multiline != "" {
+			if _, err := regexp.Compile(def.Multiline); err != nil {
+				return nil, fmt.Errorf("source %q: invalid multiline pattern: %w", def.Name, err)
+			}
+		}
+		if _, err := models.DefaultHashFields.With(def.hashFields()); err != nil {
+			return nil, fmt.Errorf("source %q: %w", def.Name, err)
+		}
+		if _, ok := names[def.Name]; ok {
+			return nil, fmt.Errorf("duplicate source name %q", def.Name)
+		}
+		if _, ok := paths[def.Path]; ok {
+			return nil, fmt.Errorf("source %q: path %s is already used by another source", def.Name, def.Path)
+		}
+		names[def.Name] = struct{}{}
+		paths[def.Path] = struct{}{}
+	}
+	return file.Sources, nil
+}
+
+// ReconcileResult counts the changes made by ApplySourceDefinitions
+type ReconcileResult struct {
+	Created   int
+	Updated   int
+	Removed   int
+	Unchanged int
+}
+
+// ApplySourceDefinitions makes the managed sources in the database match defs:
+// missing sources are created, changed ones updated, and managed sources no
+// longer declared are removed. Sources created by discovery are left alone,
+// except that an undeclared source registered for a declared path is taken
+// over by the definition, keeping its read position so the file is not
+// imported twice.
+func (e *Engine) ApplySourceDefinitions(defs []SourceDefinition, logger *pterm.Logger) (ReconcileResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var result ReconcileResult
+
+	existing, err := e.repo.FindAll()
+	if err != nil {
+		return result, err
+	}
+	byName := make(map[string]*models.LogSource, len(existing))
+	byPath := make(map[string]*models.LogSource, len(existing))
+	for _, source := range existing {
+		byName[source.Name] = source
+		byPath[source.Path] = source
+	}
+
+	declared := make(map[string]struct{}, len(defs))
+	for _, def := range defs {
+		declared[def.Name] = struct{}{}
+	}
+
+	for _, def := range defs {
+		options, err := encodeSourceOptions(def.Options)
+		if err != nil {
+			return result, fmt.Errorf("source %q: %w", def.Name, err)
+		}
+
+		if current, ok := byName[def.Name]; ok {
+			if current.Managed && current.Path == def.Path && current.ParserType == def.Parser &&
+				current.RetentionDays == def.RetentionDays && current.DisableDedup == def.disableDedup() &&
+				current.MultilinePattern == def.Multiline && current.HashFields == def.hashFields() &&
+				current.SampleRate == def.SampleRate && current.SampleKeepErrors == def.SampleErrors &&
+				current.Options == options {
+				result.Unchanged++
+				continue
+			}
+			if current.Path != def.Path {
+				// A different file: start tracking it from scratch
+				current.LastPosition = 0
+				current.LastInode = 0
+				current.LastLineContent = ""
+				current.LastReadAt = nil
+			}
+			current.Path = def.Path
+			current.ParserType = def.Parser
+			current.RetentionDays = def.RetentionDays
+			current.DisableDedup = def.disableDedup()
+			current.MultilinePattern = def.Multiline
+			current.HashFields = def.hashFields()
+			current.SampleRate = def.SampleRate
+			current.SampleKeepErrors = def.SampleErrors
+			current.Options = options
+			current.Managed = true
+			if err := e.repo.Update(current); err != nil {
+				return result, fmt.Errorf("failed to update source %q: %w", def.Name, err)
+			}
+			result.Updated++
+			logger.Info("Updated managed log source", logger.Args("name", def.Name, "path", def.Path))
+			continue
+		}
+
+		source := &models.LogSource{
+			Name:             def.Name,
+			Path:             def.Path,
+			ParserType:       def.Parser,
+			RetentionDays:    def.RetentionDays,
+			DisableDedup:     def.disableDedup(),
+			MultilinePattern: def.Multiline,
+			HashFields:       def.hashFields(),
+			SampleRate:       def.SampleRate,
+			SampleKeepErrors: def.SampleErrors,
+			Options:          options,
+			Managed:          true,
+		}
+		if previous, ok := byPath[def.Path]; ok {
+			if _, kept := declared[previous.Name]; !kept {
+				source.LastPosition = previous.LastPosition
+				source.LastInode = previous.LastInode
+				source.LastLineContent = previous.LastLineContent
+				source.LastReadAt = previous.LastReadAt
+				if err := e.repo.Delete(previous.Name); err != nil {
+					return result, fmt.Errorf("failed to replace source %q: %w", previous.Name, err)
+				}
+				delete(byName, previous.Name)
+				delete(byPath, def.Path)
+				logger.Info("Log source replaced by managed source",
+					logger.Args("previous", previous.Name, "managed", def.Name, "path", def.Path))
+			}
+		}
+		if err := e.repo.Create(source); err != nil {
+			return result, fmt.Errorf("failed to create source %q: %w", def.Name, err)
+		}
+		result.Created++
+		logger.Info("Registered managed log source", logger.Args("name", def.Name, "path", def.Path))
+	}
+
+	for _, source := range byName {
+		if _, ok := declared[source.Name]; ok || !source.Managed {
+			continue
+		}
+		if err := e.repo.Delete(source.Name); err != nil {
+			return result, fmt.Errorf("failed to remove source %q: %w", source.Name, err)
+		}
+		result.Removed++
+		logger.Info("Removed managed log source no longer declared", logger.Args("name", source.Name))
+	}
+
+	return result, nil
+}
+
+// encodeSourceOptions stores options as JSON, empty when there are none
+func encodeSourceOptions(options map[string]string) (string, error) {
+	if len(options) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(options) // Map keys are sorted, so equal options encode equally
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
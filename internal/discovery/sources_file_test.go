@@ -0,0 +1,147 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSourceDefinitionsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sources.yaml")
+	content := `sources:
+  - name: web
+    path: /logs/web.log
+    parser: traefik
+    retention_days: 14
+  - name: api
+    path: /logs/api.log
+    parser: generic
+    dedup: false
+    multiline: '^\d{4}-\d{2}-\d{2} '
+    options:
+      tenant: blue
+`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	defs, err := LoadSourceDefinitions(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(defs))
+	assert.Equal(t, "web", defs[0].Name)
+	assert.Equal(t, 14, defs[0].RetentionDays)
+	assert.False(t, defs[0].disableDedup(), "dedup stays on unless set to false")
+	assert.True(t, defs[1].disableDedup())
+	assert.Equal(t, "blue", defs[1].Options["tenant"])
+	assert.Equal(t, `^\d{4}-\d{2}-\d{2} `, defs[1].Multiline)
+}
+
+func TestLoadSourceDefinitionsInlineJSON(t *testing.T) {
+	defs, err := LoadSourceDefinitions(`{"sources":[{"name":"edge","path":"/logs/edge.log","parser":"caddy"}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(defs))
+	assert.Equal(t, "caddy", defs[0].Parser)
+}
+
+func TestLoadSourceDefinitionsRejectsInvalid(t *testing.T) {
+	cases := map[string]string{
+		"missing name":   `{"sources":[{"path":"/a.log","parser":"caddy"}]}`,
+		"missing path":   `{"sources":[{"name":"a","parser":"caddy"}]}`,
+		"missing parser": `{"sources":[{"name":"a","path":"/a.log"}]}`,
+		"negative days":  `{"sources":[{"name":"a","path":"/a.log","parser":"caddy","retention_days":-1}]}`,
+		"duplicate name": `{"sources":[{"name":"a","path":"/a.log","parser":"caddy"},{"name":"a","path":"/b.log","parser":"caddy"}]}`,
+		"duplicate path": `{"sources":[{"name":"a","path":"/a.log","parser":"caddy"},{"name":"b","path":"/a.log","parser":"caddy"}]}`,
+		"bad multiline":  `{"sources":[{"name":"a","path":"/a.log","parser":"caddy","multiline":"(["}]}`,
+		"bad hash field": `{"sources":[{"name":"a","path":"/a.log","parser":"caddy","hash_fields":["user_agent"]}]}`,
+		"negative rate":  `{"sources":[{"name":"a","path":"/a.log","parser":"caddy","sample_rate":-2}]}`,
+		"url password":   `{"sources":[{"name":"a","path":"https://u:p@host/a.log","parser":"caddy"}]}`,
+	}
+	for name, value := range cases {
+		_, err := LoadSourceDefinitions(value)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestApplySourceDefinitionsReconciles(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	repo := &fakeSourceRepo{sources: []*models.LogSource{
+		{Name: "traefik-1", Path: "/logs/web.log", ParserType: "traefik", LastPosition: 4096, LastInode: 42},
+		{Name: "caddy-1", Path: "/logs/caddy.log", ParserType: "caddy"},
+		{Name: "old", Path: "/logs/old.log", ParserType: "caddy", Managed: true},
+		{Name: "api", Path: "/logs/api-v1.log", ParserType: "generic", Managed: true, LastPosition: 100},
+	}}
+	engine := &Engine{repo: repo}
+
+	defs := []SourceDefinition{
+		{Name: "web", Path: "/logs/web.log", Parser: "traefik", RetentionDays: 7},
+		{Name: "api", Path: "/logs/api-v2.log", Parser: "generic", Options: map[string]string{"tenant": "blue"}},
+	}
+	result, err := engine.ApplySourceDefinitions(defs, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, ReconcileResult{Created: 1, Updated: 1, Removed: 1}, result)
+
+	web, _ := repo.FindByName("web")
+	assert.NotNil(t, web)
+	assert.True(t, web.Managed)
+	assert.Equal(t, 7, web.RetentionDays)
+	// The discovered source for the same file hands over its read position
+	assert.Equal(t, int64(4096), web.LastPosition)
+	assert.Equal(t, int64(42), web.LastInode)
+	discovered, _ := repo.FindByName("traefik-1")
+	assert.Nil(t, discovered)
+
+	api, _ := repo.FindByName("api")
+	assert.Equal(t, "/logs/api-v2.log", api.Path)
+	assert.Equal(t, int64(0), api.LastPosition)
+	assert.Equal(t, `{"tenant":"blue"}`, api.Options)
+
+	old, _ := repo.FindByName("old")
+	assert.Nil(t, old)
+
+	// Unmanaged sources on other paths are left alone
+	caddy, _ := repo.FindByName("caddy-1")
+	assert.NotNil(t, caddy)
+	assert.False(t, caddy.Managed)
+
+	// Applying the same definitions again changes nothing
+	result, err = engine.ApplySourceDefinitions(defs, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, ReconcileResult{Unchanged: 2}, result)
+
+	// Turning dedup off is an update that keeps the read position
+	dedup := false
+	defs[0].Dedup = &dedup
+	result, err = engine.ApplySourceDefinitions(defs, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, ReconcileResult{Updated: 1, Unchanged: 1}, result)
+	web, _ = repo.FindByName("web")
+	assert.True(t, web.DisableDedup)
+	assert.Equal(t, int64(4096), web.LastPosition)
+
+	defs[1].Multiline = `^\[`
+	result, err = engine.ApplySourceDefinitions(defs, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, ReconcileResult{Updated: 1, Unchanged: 1}, result)
+	api, _ = repo.FindByName("api")
+	assert.Equal(t, `^\[`, api.MultilinePattern)
+}
+
+func TestDiscoverDoesNotReplaceManagedSources(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	repo := &fakeSourceRepo{sources: []*models.LogSource{
+		{Name: "web", Path: "/logs/traefik.log", ParserType: "traefik", Managed: true},
+	}}
+	detector := &fakeDetector{sources: []*models.LogSource{
+		{Name: "traefik-1", Path: "/logs/traefik.log", ParserType: "traefik"},
+	}}
+	engine := &Engine{repo: repo, detectors: []ServiceDetector{detector}}
+
+	added, err := engine.Discover(logger)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(added))
+	assert.Equal(t, 1, len(repo.sources))
+	assert.True(t, repo.sources[0].Managed)
+}
@@ -0,0 +1,287 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package enrichment
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+)
+
+// maxLabelLength matches the label column size
+const maxLabelLength = 50
+
+// ClassificationRule assigns Label to requests matching every condition it sets.
+// Rules are evaluated in order and the first match wins.
+type ClassificationRule struct {
+	Label      string   `yaml:"label"`
+	PathPrefix string   `yaml:"path_prefix"`
+	PathRegex  string   `yaml:"path_regex"`
+	Host       string   `yaml:"host"`       // Exact host, or *.example.com for subdomains (case-insensitive)
+	IP         []string `yaml:"ip"`         // IPs or CIDR ranges
+	UserAgent  string   `yaml:"user_agent"` // Regular expression
+	Status     []string `yaml:"status"`     // Codes (404), classes (4xx) or ranges (500-504)
+}
+
+// ClassificationRules is the rule file layout
+type ClassificationRules struct {
+	Rules []ClassificationRule `yaml:"rules"`
+}
+
+// LoadClassificationRules reads rules from a YAML or JSON file path, or from
+// the value itself when it does not point to a file
+func LoadClassificationRules(value string) ([]ClassificationRule, error) {
+	data := []byte(value)
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		data, err = os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read classification rules file: %w", err)
+		}
+	}
+
+	// JSON is valid YAML, so a single decoder handles both formats
+	var file ClassificationRules
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid classification rules: %w", err)
+	}
+	return file.Rules, nil
+}
+
+// statusRange is an inclusive status code range
+type statusRange struct {
+	min, max int
+}
+
+// compiledRule is a validated rule ready for matching
+type compiledRule struct {
+	label      string
+	pathPrefix string
+	pathRegex  *regexp.Regexp
+	host       string
+	hostSuffix bool // host was given as *.domain
+	networks   []*net.IPNet
+	userAgent  *regexp.Regexp
+	status     []statusRange
+}
+
+// compileRule validates a rule and prepares its matchers
+func compileRule(rule ClassificationRule) (*compiledRule, error) {
+	label := strings.TrimSpace(rule.Label)
+	if label == "" {
+		return nil, fmt.Errorf("rule must define label")
+	}
+	if len(label) > maxLabelLength {
+		return nil, fmt.Errorf("label %q exceeds %d characters", label, maxLabelLength)
+	}
+
+	compiled := &compiledRule{
+		label:      label,
+		pathPrefix: rule.PathPrefix,
+		host:       strings.ToLower(strings.TrimSpace(rule.Host)),
+	}
+	if strings.HasPrefix(compiled.host, "*.") {
+		compiled.host = compiled.host[1:] // Keep the dot: matches subdomains only
+		compiled.hostSuffix = true
+	}
+
+	var err error
+	if rule.PathRegex != "" {
+		if compiled.pathRegex, err = regexp.Compile(rule.PathRegex); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid path_regex: %w", label, err)
+		}
+	}
+	if rule.UserAgent != "" {
+		if compiled.userAgent, err = regexp.Compile(rule.UserAgent); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid user_agent: %w", label, err)
+		}
+	}
+
+	for _, value := range rule.IP {
+		network, err := parseNetwork(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", label, err)
+		}
+		compiled.networks = append(compiled.networks, network)
+	}
+
+	for _, value := range rule.Status {
+		statusRange, err := parseStatusRange(value)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", label, err)
+		}
+		compiled.status = append(compiled.status, statusRange)
+	}
+
+	if compiled.pathPrefix == "" && compiled.pathRegex == nil && compiled.host == "" &&
+		len(compiled.networks) == 0 && compiled.userAgent == nil && len(compiled.status) == 0 {
+		return nil, fmt.Errorf("rule %q has no conditions", label)
+	}
+	return compiled, nil
+}
+
+// parseStatusRange parses 404, 4xx or 500-504
+func parseStatusRange(value string) (statusRange, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) == 3 && strings.HasSuffix(value, "xx") {
+		class, err := strconv.Atoi(value[:1])
+		if err == nil && class >= 1 && class <= 5 {
+			return statusRange{class * 100, class*100 + 99}, nil
+		}
+	} else if from, to, ok := strings.Cut(value, "-"); ok {
+		lo, errLo := strconv.Atoi(from)
+		hi, errHi := strconv.Atoi(to)
+		if errLo == nil && errHi == nil && lo <= hi {
+			return statusRange{lo, hi}, nil
+		}
+	} else if code, err := strconv.Atoi(value); err == nil {
+		return statusRange{code, code}, nil
+	}
+	return statusRange{}, fmt.Errorf("invalid status %q", value)
+}
+
+// matches reports whether the request satisfies every condition of the rule
+func (r *compiledRule) matches(request *models.HTTPRequest, ip net.IP) bool {
+	if r.pathPrefix != "" && !strings.HasPrefix(request.Path, r.pathPrefix) {
+		return false
+	}
+	if r.pathRegex != nil && !r.pathRegex.MatchString(request.Path) {
+		return false
+	}
+	if r.host != "" {
+		host := strings.ToLower(request.Host)
+		if r.hostSuffix {
+			if !strings.HasSuffix(host, r.host) {
+				return false
+			}
+		} else if host != r.host {
+			return false
+		}
+	}
+	if len(r.networks) > 0 {
+		if ip == nil {
+			return false
+		}
+		inRange := false
+		for _, network := range r.networks {
+			if network.Contains(ip) {
+				inRange = true
+				break
+			}
+		}
+		if !inRange {
+			return false
+		}
+	}
+	if r.userAgent != nil && !r.userAgent.MatchString(request.UserAgent) {
+		return false
+	}
+	if len(r.status) > 0 {
+		inRange := false
+		for _, status := range r.status {
+			if request.StatusCode >= status.min && request.StatusCode <= status.max {
+				inRange = true
+				break
+			}
+		}
+		if !inRange {
+			return false
+		}
+	}
+	return true
+}
+
+// Classifier labels requests with user-defined classification rules.
+// Rules can be reloaded at runtime; a failed reload keeps the current rules.
+type Classifier struct {
+	source string // Inline rules or rule file path (CLASSIFICATION_RULES)
+	logger *pterm.Logger
+	rules  []*compiledRule
+	mu     sync.RWMutex
+}
+
+// NewClassifier creates a classifier from inline rules or a rule file path
+func NewClassifier(source string, logger *pterm.Logger) (*Classifier, error) {
+	c := &Classifier{
+		source: source,
+		logger: logger,
+	}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload re-reads the rules from their source. Applies to requests ingested afterwards.
+func (c *Classifier) Reload() error {
+	rules, err := LoadClassificationRules(c.source)
+	if err != nil {
+		return err
+	}
+
+	compiled := make([]*compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		compiledRule, err := compileRule(rule)
+		if err != nil {
+			return fmt.Errorf("classification rule %d: %w", i+1, err)
+		}
+		compiled = append(compiled, compiledRule)
+	}
+
+	c.mu.Lock()
+	c.rules = compiled
+	c.mu.Unlock()
+
+	c.logger.Debug("Loaded classification rules", c.logger.Args("rules", len(compiled)))
+	return nil
+}
+
+// Classify sets request.Label from the first matching rule (empty when none match)
+func (c *Classifier) Classify(request *models.HTTPRequest) {
+	c.mu.RLock()
+	rules := c.rules
+	c.mu.RUnlock()
+
+	ip := parseClientIP(request.ClientIP)
+	for _, rule := range rules {
+		if rule.matches(request, ip) {
+			request.Label = rule.label
+			return
+		}
+	}
+	request.Label = ""
+}
+
+// RuleCount returns the number of active rules
+func (c *Classifier) RuleCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.rules)
+}
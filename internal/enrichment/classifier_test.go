@@ -0,0 +1,95 @@
+package enrichment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRules = `
+rules:
+  - label: internal
+    ip: [10.0.0.0/8, "2001:db8::1"]
+  - label: api-errors
+    path_prefix: /api/
+    status: [5xx, "429"]
+  - label: api
+    path_prefix: /api/
+  - label: crawler
+    user_agent: "(?i)bot|spider"
+  - label: static
+    host: "*.cdn.example.com"
+    path_regex: '\.(css|js|png)$'
+`
+
+func TestClassifierFirstMatchingRuleWins(t *testing.T) {
+	logger := pterm.DefaultLogger
+	c, err := NewClassifier(testRules, &logger)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, c.RuleCount())
+
+	cases := []struct {
+		name    string
+		request models.HTTPRequest
+		label   string
+	}{
+		{"ip in cidr", models.HTTPRequest{ClientIP: "10.1.2.3", Path: "/api/users"}, "internal"},
+		{"single ipv6", models.HTTPRequest{ClientIP: "2001:db8::1", Path: "/"}, "internal"},
+		{"status class", models.HTTPRequest{ClientIP: "1.1.1.1", Path: "/api/users", StatusCode: 503}, "api-errors"},
+		{"exact status", models.HTTPRequest{ClientIP: "1.1.1.1", Path: "/api/users", StatusCode: 429}, "api-errors"},
+		{"path prefix", models.HTTPRequest{ClientIP: "1.1.1.1", Path: "/api/users", StatusCode: 200}, "api"},
+		{"user agent", models.HTTPRequest{ClientIP: "1.1.1.1", Path: "/", UserAgent: "Googlebot/2.1"}, "crawler"},
+		{"host wildcard", models.HTTPRequest{ClientIP: "1.1.1.1", Host: "img.CDN.example.com", Path: "/a.png"}, "static"},
+		{"wildcard excludes apex", models.HTTPRequest{ClientIP: "1.1.1.1", Host: "cdn.example.com", Path: "/a.png"}, ""},
+		{"no match", models.HTTPRequest{ClientIP: "1.1.1.1", Path: "/"}, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := tc.request
+			req.Label = "stale"
+			c.Classify(&req)
+			assert.Equal(t, tc.label, req.Label)
+		})
+	}
+}
+
+func TestClassifierRejectsInvalidRules(t *testing.T) {
+	logger := pterm.DefaultLogger
+	for _, rules := range []string{
+		`rules: [{path_prefix: /api}]`,
+		`rules: [{label: empty}]`,
+		`rules: [{label: bad, ip: [not-an-ip]}]`,
+		`rules: [{label: bad, status: [6x]}]`,
+		`rules: [{label: bad, user_agent: "("}]`,
+	} {
+		_, err := NewClassifier(rules, &logger)
+		assert.Error(t, err, rules)
+	}
+}
+
+func TestClassifierReloadKeepsRulesOnError(t *testing.T) {
+	logger := pterm.DefaultLogger
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"rules":[{"label":"api","path_prefix":"/api"}]}`), 0o644))
+
+	c, err := NewClassifier(path, &logger)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.RuleCount())
+
+	assert.NoError(t, os.WriteFile(path, []byte("rules:\n  - label: admin\n    path_prefix: /admin\n  - label: api\n    path_prefix: /api\n"), 0o644))
+	assert.NoError(t, c.Reload())
+	assert.Equal(t, 2, c.RuleCount())
+
+	assert.NoError(t, os.WriteFile(path, []byte("rules:\n  - label: broken\n"), 0o644))
+	assert.Error(t, c.Reload())
+	assert.Equal(t, 2, c.RuleCount(), "invalid file keeps the previous rules")
+
+	req := &models.HTTPRequest{Path: "/admin/login"}
+	c.Classify(req)
+	assert.Equal(t, "admin", req.Label)
+}
@@ -0,0 +1,123 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package enrichment
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"loglynx/internal/database/models"
+)
+
+// TrustedProxies resolves the real client behind a chain of reverse proxies.
+// X-Forwarded-For is only honoured when the address that connected to the logging
+// proxy is trusted; the chain is then walked right to left, skipping trusted hops,
+// and the first untrusted hop is the client. Otherwise anyone could spoof their
+// address by sending the header themselves.
+type TrustedProxies struct {
+	networks *InternalNetworks
+}
+
+// NewTrustedProxies parses the trusted proxy IPs and CIDR blocks; empty entries are ignored
+func NewTrustedProxies(values []string) (*TrustedProxies, error) {
+	networks, err := NewInternalNetworks(values)
+	if err != nil {
+		return nil, err
+	}
+	return &TrustedProxies{networks: networks}, nil
+}
+
+// Len returns the number of configured ranges
+func (t *TrustedProxies) Len() int {
+	return t.networks.Len()
+}
+
+// Resolve returns the client IP for a request received from peer with the given
+// X-Forwarded-For hops (leftmost = original client). If every hop is trusted the
+// leftmost one is used; a malformed hop ends the walk at the last valid hop.
+func (t *TrustedProxies) Resolve(peer string, hops []string) string {
+	client := peer
+	if !t.networks.Contains(peer) {
+		return client
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hopAddress(hops[i])
+		if parseClientIP(hop) == nil {
+			break
+		}
+		client = hop
+		if !t.networks.Contains(hop) {
+			break
+		}
+	}
+	return client
+}
+
+// SplitForwardedFor splits an X-Forwarded-For header into its hops, dropping empty entries
+func SplitForwardedFor(header string) []string {
+	var hops []string
+	for _, hop := range strings.Split(header, ",") {
+		if hop = strings.TrimSpace(hop); hop != "" {
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// ApplyForwardedFor records the X-Forwarded-For chain in the request's ProxyMetadata
+// and, when trusted proxies are configured (non-nil), replaces ClientIP with the
+// resolved client. The address the proxy saw is kept as "peer_ip".
+func ApplyForwardedFor(request *models.HTTPRequest, header string, trusted *TrustedProxies) {
+	hops := SplitForwardedFor(header)
+	if len(hops) == 0 {
+		return
+	}
+
+	metadata := map[string]any{}
+	if request.ProxyMetadata != "" {
+		// Keep any parser-provided keys; a malformed value is replaced
+		_ = json.Unmarshal([]byte(request.ProxyMetadata), &metadata)
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+	}
+	metadata["forwarded_for"] = hops
+	metadata["peer_ip"] = request.ClientIP
+
+	if trusted != nil {
+		request.ClientIP = models.NormalizeIP(trusted.Resolve(request.ClientIP, hops))
+	}
+
+	if encoded, err := json.Marshal(metadata); err == nil {
+		request.ProxyMetadata = string(encoded)
+	}
+}
+
+// hopAddress strips an optional port from a hop ("203.0.113.7:51234", "[2001:db8::1]:443")
+func hopAddress(hop string) string {
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]")
+}
@@ -0,0 +1,62 @@
+package enrichment
+
+import (
+	"encoding/json"
+	"testing"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustedProxiesResolve(t *testing.T) {
+	proxies, err := NewTrustedProxies([]string{"10.0.0.0/8", "2001:db8::/32"})
+	assert.NoError(t, err)
+
+	// Untrusted peer: the header could be forged, keep the peer
+	assert.Equal(t, "198.51.100.1", proxies.Resolve("198.51.100.1", []string{"203.0.113.7"}))
+
+	// Trusted peer: skip trusted hops from the right
+	assert.Equal(t, "203.0.113.7", proxies.Resolve("10.0.0.2", []string{"192.0.2.9", "203.0.113.7", "10.0.0.1"}))
+
+	// Ports and bracketed IPv6 hops
+	assert.Equal(t, "203.0.113.7", proxies.Resolve("10.0.0.2", []string{"203.0.113.7:51234"}))
+	assert.Equal(t, "2a00::1", proxies.Resolve("2001:db8::5", []string{"[2a00::1]:443"}))
+
+	// Every hop trusted: leftmost wins
+	assert.Equal(t, "10.0.0.9", proxies.Resolve("10.0.0.2", []string{"10.0.0.9", "10.0.0.1"}))
+
+	// Garbage ends the walk at the last valid hop
+	assert.Equal(t, "10.0.0.1", proxies.Resolve("10.0.0.2", []string{"203.0.113.7", "unknown", "10.0.0.1"}))
+}
+
+func TestApplyForwardedFor(t *testing.T) {
+	proxies, err := NewTrustedProxies([]string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+
+	request := &models.HTTPRequest{ClientIP: "10.0.0.2"}
+	ApplyForwardedFor(request, "203.0.113.7, 10.0.0.1", proxies)
+	assert.Equal(t, "203.0.113.7", request.ClientIP)
+
+	var metadata map[string]any
+	if assert.NoError(t, json.Unmarshal([]byte(request.ProxyMetadata), &metadata)) {
+		assert.Equal(t, []any{"203.0.113.7", "10.0.0.1"}, metadata["forwarded_for"])
+		assert.Equal(t, "10.0.0.2", metadata["peer_ip"])
+	}
+
+	// Without trusted proxies the chain is recorded but the IP is untouched
+	request = &models.HTTPRequest{ClientIP: "10.0.0.2", ProxyMetadata: `{"router":"web"}`}
+	ApplyForwardedFor(request, "203.0.113.7", nil)
+	assert.Equal(t, "10.0.0.2", request.ClientIP)
+	assert.Contains(t, request.ProxyMetadata, `"router":"web"`)
+	assert.Contains(t, request.ProxyMetadata, `"forwarded_for":["203.0.113.7"]`)
+
+	request = &models.HTTPRequest{ClientIP: "10.0.0.2"}
+	ApplyForwardedFor(request, " , ", proxies)
+	assert.Empty(t, request.ProxyMetadata)
+
+	// The resolved client is stored in canonical form
+	request = &models.HTTPRequest{ClientIP: "10.0.0.2"}
+	ApplyForwardedFor(request, "[2001:DB8:0:0:0:0:0:7]:51234", proxies)
+	assert.Equal(t, "2001:db8::7", request.ClientIP)
+}
@@ -23,11 +23,14 @@
 package enrichment
 
 import (
+	"container/list"
 	"fmt"
 	"loglynx/internal/database/models"
 	"net"
+	"strings"
 	"sync"
 	"time"
+	"unsafe"
 
 	"github.com/oschwald/geoip2-golang"
 	"github.com/pterm/pterm"
@@ -35,21 +38,86 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+// DefaultNegativeCacheTTL is how long an IP missing from the GeoIP databases is
+// remembered before it is looked up again
+const DefaultNegativeCacheTTL = time.Hour
+
+// DefaultGeoIPCacheTTL is how long a GeoIP result is served from the memory cache
+// before the IP is looked up again, e.g. after its network changed owner
+const DefaultGeoIPCacheTTL = 24 * time.Hour
+
+// Location providers, queried in the configured priority order
+const (
+	GeoIPProviderCity    = "city"
+	GeoIPProviderCountry = "country"
+)
+
+// DefaultGeoIPProviderOrder queries City databases first, then Country databases
+var DefaultGeoIPProviderOrder = []string{GeoIPProviderCity, GeoIPProviderCountry}
+
+// geoIPReader is the subset of geoip2.Reader used for lookups
+type geoIPReader interface {
+	City(ip net.IP) (*geoip2.City, error)
+	Country(ip net.IP) (*geoip2.Country, error)
+	ASN(ip net.IP) (*geoip2.ASN, error)
+	Close() error
+}
+
+// GeoIPLookupStats counts database lookups by outcome
+type GeoIPLookupStats struct {
+	Success  int64 // At least one database returned data
+	NotFound int64 // No database knows the IP (negatively cached)
+	Failed   int64 // A database returned an error (not cached)
+}
+
+// geoIPCacheEntry is a cached lookup result and its place in the LRU order
+type geoIPCacheEntry struct {
+	reputation *models.IPReputation
+	expires    time.Time
+	element    *list.Element // Value is the IP; the front of the list is the most recently used
+}
+
+// openGeoIPDatabase opens a database file; replaced in tests
+var openGeoIPDatabase = func(path string) (geoIPReader, error) {
+	return geoip2.Open(path)
+}
+
 // GeoIPEnricher provides GeoIP enrichment with caching
 type GeoIPEnricher struct {
-	cityDB    *geoip2.Reader
-	countryDB *geoip2.Reader
-	asnDB     *geoip2.Reader
+	cityDBs       []geoIPReader
+	countryDBs    []geoIPReader
+	asnDBs        []geoIPReader
+	providerOrder []string // Location providers by priority; later ones only fill missing fields
+
+	// Configured database paths, reopened by ReloadDatabases
+	cityPaths    string
+	countryPaths string
+	asnPaths     string
+
 	db        *gorm.DB
 	logger    *pterm.Logger
-	cache     map[string]*models.IPReputation
+	cache     map[string]*geoIPCacheEntry
+	lru       *list.List // Cached IPs, least recently used at the back
 	cacheMu   sync.RWMutex
 	enabled   bool
-	cacheSize int // Maximum cache size from config (GEOIP_CACHE_SIZE)
+	cacheSize int           // Maximum cache size from config (GEOIP_CACHE_SIZE)
+	cacheTTL  time.Duration // How long a result is served before the IP is looked up again
+
+	// IPs not found in any database, with the time they may be looked up again
+	negativeCache    map[string]time.Time
+	negativeCacheTTL time.Duration
+
+	// Cache effectiveness counters
+	statsMu        sync.Mutex
+	cacheHits      int64
+	cacheMisses    int64
+	cacheEvictions int64 // Least recently used entries dropped to stay within cacheSize
+	lookups        GeoIPLookupStats
 }
 
 // NewGeoIPEnricher creates a new GeoIP enricher
-// Handles City, Country, and ASN databases - works with any combination available
+// Handles City, Country, and ASN databases - works with any combination available.
+// Each path may list several comma-separated databases, e.g. from different vendors.
 func NewGeoIPEnricher(cityDBPath, countryDBPath, asnDBPath string, db *gorm.DB, logger *pterm.Logger, cacheSize int) (*GeoIPEnricher, error) {
 	if cacheSize <= 0 {
 		cacheSize = 10000 // Default fallback
@@ -58,48 +126,27 @@ func NewGeoIPEnricher(cityDBPath, countryDBPath, asnDBPath string, db *gorm.DB,
 	enricher := &GeoIPEnricher{
 		db:        db,
 		logger:    logger,
-		cache:     make(map[string]*models.IPReputation, cacheSize), // Pre-allocate with capacity
+		cache:     make(map[string]*geoIPCacheEntry, cacheSize), // Pre-allocate with capacity
+		lru:       list.New(),
 		enabled:   false,
 		cacheSize: cacheSize,
-	}
+		cacheTTL:  DefaultGeoIPCacheTTL,
 
-	// Try to load City database (provides most detailed location data)
-	if cityDBPath != "" {
-		cityDB, err := geoip2.Open(cityDBPath)
-		if err != nil {
-			logger.Warn("GeoIP City database not available",
-				logger.Args("path", cityDBPath, "error", err))
-		} else {
-			enricher.cityDB = cityDB
-			enricher.enabled = true
-			logger.Info("Loaded GeoIP City database", logger.Args("path", cityDBPath))
-		}
-	}
+		providerOrder:    DefaultGeoIPProviderOrder,
+		negativeCache:    make(map[string]time.Time),
+		negativeCacheTTL: DefaultNegativeCacheTTL,
 
-	// Try to load Country database (fallback if City is not available)
-	if countryDBPath != "" {
-		countryDB, err := geoip2.Open(countryDBPath)
-		if err != nil {
-			logger.Warn("GeoIP Country database not available",
-				logger.Args("path", countryDBPath, "error", err))
-		} else {
-			enricher.countryDB = countryDB
-			enricher.enabled = true
-			logger.Info("Loaded GeoIP Country database", logger.Args("path", countryDBPath))
-		}
+		cityPaths:    cityDBPath,
+		countryPaths: countryDBPath,
+		asnPaths:     asnDBPath,
 	}
 
-	// Try to load ASN database (provides ISP/organization data)
-	if asnDBPath != "" {
-		asnDB, err := geoip2.Open(asnDBPath)
-		if err != nil {
-			logger.Warn("GeoIP ASN database not available",
-				logger.Args("path", asnDBPath, "error", err))
-		} else {
-			enricher.asnDB = asnDB
-			logger.Info("Loaded GeoIP ASN database", logger.Args("path", asnDBPath))
-		}
-	}
+	enricher.cityDBs = enricher.openDatabases("City", cityDBPath)          // Most detailed location data
+	enricher.countryDBs = enricher.openDatabases("Country", countryDBPath) // Country only
+	enricher.asnDBs = enricher.openDatabases("ASN", asnDBPath)             // ISP/organization data
+
+	// ASN data alone is not enough to enable enrichment
+	enricher.enabled = len(enricher.cityDBs) > 0 || len(enricher.countryDBs) > 0
 
 	if !enricher.enabled {
 		logger.Warn("GeoIP enrichment disabled - no databases available")
@@ -110,14 +157,34 @@ func NewGeoIPEnricher(cityDBPath, countryDBPath, asnDBPath string, db *gorm.DB,
 
 // Enrich enriches an HTTP request with GeoIP data
 func (g *GeoIPEnricher) Enrich(request *models.HTTPRequest) error {
-	if !g.enabled || request.ClientIP == "" {
+	if request.ClientIP == "" {
 		return nil
 	}
 
-	// Check cache first
-	g.cacheMu.RLock()
-	cached, exists := g.cache[request.ClientIP]
-	g.cacheMu.RUnlock()
+	// Check cache first; a hit moves the IP to the front of the LRU order
+	g.cacheMu.Lock()
+	enabled := g.enabled
+	cached, exists := g.cacheGet(request.ClientIP, time.Now())
+	retryAt, negative := g.negativeCache[request.ClientIP]
+	g.cacheMu.Unlock()
+
+	if !enabled {
+		return nil
+	}
+
+	// Known to be absent from the databases: skip the lookup until the TTL expires
+	if !exists && negative && time.Now().Before(retryAt) {
+		exists = true
+		cached = &models.IPReputation{IPAddress: request.ClientIP}
+	}
+
+	g.statsMu.Lock()
+	if exists {
+		g.cacheHits++
+	} else {
+		g.cacheMisses++
+	}
+	g.statsMu.Unlock()
 
 	if exists {
 		// Use cached data
@@ -147,123 +214,120 @@ func (g *GeoIPEnricher) lookupAndCache(request *models.HTTPRequest) error {
 
 	reputation := &models.IPReputation{
 		IPAddress: request.ClientIP,
-		FirstSeen: time.Now(),
-		LastSeen:  time.Now(),
-	}
-
-	// Lookup City data (preferred - provides city, country, and coordinates)
-	cityLookupSuccess := false
-	if g.cityDB != nil {
-		record, err := g.cityDB.City(ip)
-		if err == nil {
-			reputation.Country = record.Country.IsoCode
-			reputation.CountryName = record.Country.Names["en"]
-			reputation.City = record.City.Names["en"]
-			reputation.Latitude = record.Location.Latitude
-			reputation.Longitude = record.Location.Longitude
-
-			// Populate request
-			request.GeoCountry = reputation.Country
-			request.GeoCity = reputation.City
-			request.GeoLat = reputation.Latitude
-			request.GeoLon = reputation.Longitude
-
-			cityLookupSuccess = true
-			g.logger.Debug("GeoIP City lookup successful",
-				g.logger.Args("ip", request.ClientIP, "country", reputation.Country, "city", reputation.City))
-		} else {
-			g.logger.Debug("GeoIP City lookup failed", g.logger.Args("ip", request.ClientIP, "error", err))
-		}
+		FirstSeen: time.Now().UTC(),
+		LastSeen:  time.Now().UTC(),
 	}
 
-	// Fallback to Country database if City lookup failed or unavailable
-	if !cityLookupSuccess && g.countryDB != nil {
-		record, err := g.countryDB.Country(ip)
-		if err == nil {
-			reputation.Country = record.Country.IsoCode
-			reputation.CountryName = record.Country.Names["en"]
-			// Country DB doesn't provide city or coordinates, but we get country at least
+	// A reader error means the outcome is unknown, so nothing is cached for this IP.
+	// A clean lookup that finds nothing is cached negatively with a short TTL.
+	lookupFailed := false
 
-			// Populate request
-			request.GeoCountry = reputation.Country
+	// Readers are only swapped under the write lock, so none is closed mid-lookup
+	g.cacheMu.RLock()
+
+	// Query location providers in priority order; each one only fills fields still empty
+	for _, provider := range g.providerOrder {
+		var readers []geoIPReader
+		switch provider {
+		case GeoIPProviderCity:
+			readers = g.cityDBs
+		case GeoIPProviderCountry:
+			readers = g.countryDBs
+		}
 
-			g.logger.Debug("GeoIP Country lookup successful",
-				g.logger.Args("ip", request.ClientIP, "country", reputation.Country))
-		} else {
-			g.logger.Debug("GeoIP Country lookup failed", g.logger.Args("ip", request.ClientIP, "error", err))
+		for _, reader := range readers {
+			if locationComplete(reputation) {
+				break
+			}
+			var err error
+			if provider == GeoIPProviderCity {
+				err = lookupCity(reader, ip, reputation)
+			} else {
+				err = lookupCountry(reader, ip, reputation)
+			}
+			if err != nil {
+				lookupFailed = true
+				g.logger.Debug("GeoIP location lookup failed",
+					g.logger.Args("ip", request.ClientIP, "provider", provider, "error", err))
+			}
 		}
 	}
 
-	// Lookup ASN data
-	if g.asnDB != nil {
-		record, err := g.asnDB.ASN(ip)
-		if err == nil {
+	// ASN data comes from the first ASN database that knows the IP
+	for _, reader := range g.asnDBs {
+		record, err := reader.ASN(ip)
+		if err != nil {
+			lookupFailed = true
+			g.logger.Debug("GeoIP ASN lookup failed", g.logger.Args("ip", request.ClientIP, "error", err))
+			continue
+		}
+		if record.AutonomousSystemNumber != 0 {
 			reputation.ASN = int(record.AutonomousSystemNumber)
 			reputation.ASNOrg = record.AutonomousSystemOrganization
-
-			// Populate request
-			request.ASN = reputation.ASN
-			request.ASNOrg = reputation.ASNOrg
-
-			g.logger.Debug("GeoIP ASN lookup successful",
-				g.logger.Args("ip", request.ClientIP, "asn", reputation.ASN, "org", reputation.ASNOrg))
-		} else {
-			g.logger.Debug("GeoIP ASN lookup failed", g.logger.Args("ip", request.ClientIP, "error", err))
+			break
 		}
 	}
+	g.cacheMu.RUnlock()
 
-	// Store in memory cache first (fast, thread-safe)
-	g.cacheMu.Lock()
+	found := reputation.Country != "" || reputation.City != "" || reputation.ASN != 0
+	if found {
+		// Populate request
+		request.GeoCountry = reputation.Country
+		request.GeoCity = reputation.City
+		request.GeoLat = reputation.Latitude
+		request.GeoLon = reputation.Longitude
+		request.ASN = reputation.ASN
+		request.ASNOrg = reputation.ASNOrg
+
+		g.logger.Debug("GeoIP lookup successful",
+			g.logger.Args("ip", request.ClientIP, "country", reputation.Country, "city", reputation.City, "asn", reputation.ASN))
+	}
 
-	// Check if cache is full before adding
-	if len(g.cache) >= g.cacheSize {
-		// Cache is full - implement LRU-style eviction
-		// Remove oldest entries (simple strategy: remove 10% of cache)
-		evictCount := g.cacheSize / 10
-		if evictCount < 1 {
-			evictCount = 1
-		}
+	g.statsMu.Lock()
+	switch {
+	case lookupFailed:
+		g.lookups.Failed++
+	case !found:
+		g.lookups.NotFound++
+	default:
+		g.lookups.Success++
+	}
+	g.statsMu.Unlock()
 
-		// Find and remove oldest entries based on LastSeen
-		type ipAge struct {
-			ip       string
-			lastSeen time.Time
-		}
-		ages := make([]ipAge, 0, len(g.cache))
-		for ip, rep := range g.cache {
-			ages = append(ages, ipAge{ip: ip, lastSeen: rep.LastSeen})
-		}
+	if lookupFailed {
+		// Transient reader errors must not pin a (possibly empty) result; retry on the next request
+		return nil
+	}
 
-		// Sort by oldest first
-		for i := 0; i < len(ages)-1; i++ {
-			for j := i + 1; j < len(ages); j++ {
-				if ages[i].lastSeen.After(ages[j].lastSeen) {
-					ages[i], ages[j] = ages[j], ages[i]
+	if !found {
+		// Not in any database: remember briefly so a database update is picked up later
+		now := time.Now()
+		g.cacheMu.Lock()
+		if len(g.negativeCache) >= g.cacheSize {
+			for ip, retryAt := range g.negativeCache {
+				if !now.Before(retryAt) {
+					delete(g.negativeCache, ip)
 				}
 			}
-		}
-
-		// Remove oldest entries
-		evicted := 0
-		for _, age := range ages {
-			if evicted >= evictCount {
-				break
+			if len(g.negativeCache) >= g.cacheSize {
+				g.negativeCache = make(map[string]time.Time)
 			}
-			delete(g.cache, age.ip)
-			evicted++
 		}
-
-		g.logger.Debug("GeoIP cache eviction performed",
-			g.logger.Args(
-				"evicted", evicted,
-				"cache_size", len(g.cache),
-				"max_size", g.cacheSize,
-			))
+		g.negativeCache[request.ClientIP] = now.Add(g.negativeCacheTTL)
+		g.cacheMu.Unlock()
+		return nil
 	}
 
-	g.cache[request.ClientIP] = reputation
+	// Store in memory cache first (fast, thread-safe)
+	g.cacheMu.Lock()
+	g.cacheStore(request.ClientIP, reputation, time.Now())
+	delete(g.negativeCache, request.ClientIP)
 	g.cacheMu.Unlock()
 
+	if g.db == nil {
+		return nil
+	}
+
 	// Store in database cache asynchronously to avoid blocking
 	// Use goroutine to prevent concurrent insert errors from slowing down processing
 	go func(rep *models.IPReputation) {
@@ -279,10 +343,56 @@ func (g *GeoIPEnricher) lookupAndCache(request *models.HTTPRequest) error {
 	return nil
 }
 
+// cacheGet returns the cached result of ip, dropping it once expired.
+// Caller must hold g.cacheMu for writing.
+func (g *GeoIPEnricher) cacheGet(ip string, now time.Time) (*models.IPReputation, bool) {
+	entry, ok := g.cache[ip]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expires) {
+		g.lru.Remove(entry.element)
+		delete(g.cache, ip)
+		return nil, false
+	}
+	g.lru.MoveToFront(entry.element)
+	return entry.reputation, true
+}
+
+// cacheStore caches the result of ip, evicting the least recently used entries when
+// the cache is full. Caller must hold g.cacheMu for writing.
+func (g *GeoIPEnricher) cacheStore(ip string, reputation *models.IPReputation, now time.Time) {
+	if entry, ok := g.cache[ip]; ok {
+		entry.reputation = reputation
+		entry.expires = now.Add(g.cacheTTL)
+		g.lru.MoveToFront(entry.element)
+		return
+	}
+
+	evicted := 0
+	for len(g.cache) >= g.cacheSize && g.lru.Len() > 0 {
+		oldest := g.lru.Back()
+		g.lru.Remove(oldest)
+		delete(g.cache, oldest.Value.(string))
+		evicted++
+	}
+	if evicted > 0 {
+		g.statsMu.Lock()
+		g.cacheEvictions += int64(evicted)
+		g.statsMu.Unlock()
+	}
+
+	g.cache[ip] = &geoIPCacheEntry{
+		reputation: reputation,
+		expires:    now.Add(g.cacheTTL),
+		element:    g.lru.PushFront(ip),
+	}
+}
+
 // LoadCache preloads the memory cache from database
 // Optimized to load only hot IPs (recent activity) and skip if cache is already large
 func (g *GeoIPEnricher) LoadCache() error {
-	if !g.enabled {
+	if !g.IsEnabled() {
 		return nil
 	}
 
@@ -305,7 +415,7 @@ func (g *GeoIPEnricher) LoadCache() error {
 	}
 
 	// Get hot IPs from recent activity only (last 7 days)
-	sevenDaysAgo := time.Now().Add(-168 * time.Hour)
+	sevenDaysAgo := time.Now().UTC().Add(-168 * time.Hour)
 	var topIPs []IPCount
 	err := g.db.Model(&models.HTTPRequest{}).
 		Select("client_ip, COUNT(*) as repetition").
@@ -321,13 +431,14 @@ func (g *GeoIPEnricher) LoadCache() error {
 		g.logger.Warn("Failed to query hot IPs from http_requests", g.logger.Args("error", err))
 		// Fall back to loading from ip_reputation (most recent)
 		var reputations []models.IPReputation
-		if err := g.db.Order("last_seen DESC").Limit(g.cacheSize).Find(&reputations).Error; err != nil {
+		if err := g.db.Where("country != '' OR asn != 0").Order("last_seen DESC").Limit(g.cacheSize).Find(&reputations).Error; err != nil {
 			g.logger.WithCaller().Error("Failed to load IP reputation cache", g.logger.Args("error", err))
 			return err
 		}
+		now := time.Now()
 		g.cacheMu.Lock()
 		for i := range reputations {
-			g.cache[reputations[i].IPAddress] = &reputations[i]
+			g.cacheStore(reputations[i].IPAddress, &reputations[i], now)
 		}
 		g.cacheMu.Unlock()
 		g.logger.Info("Loaded GeoIP cache from ip_reputation", g.logger.Args("entries", len(reputations)))
@@ -346,14 +457,16 @@ func (g *GeoIPEnricher) LoadCache() error {
 	}
 
 	var reputations []models.IPReputation
-	if err := g.db.Where("ip_address IN ?", ipAddresses).Find(&reputations).Error; err != nil {
+	// Rows without any geo data were written by older versions on failed lookups; let them be retried
+	if err := g.db.Where("ip_address IN ?", ipAddresses).Where("country != '' OR asn != 0").Find(&reputations).Error; err != nil {
 		g.logger.WithCaller().Error("Failed to load IP reputation data", g.logger.Args("error", err))
 		return err
 	}
 
+	now := time.Now()
 	g.cacheMu.Lock()
 	for i := range reputations {
-		g.cache[reputations[i].IPAddress] = &reputations[i]
+		g.cacheStore(reputations[i].IPAddress, &reputations[i], now)
 	}
 	g.cacheMu.Unlock()
 
@@ -364,21 +477,48 @@ func (g *GeoIPEnricher) LoadCache() error {
 
 // Close closes the GeoIP databases
 func (g *GeoIPEnricher) Close() error {
-	if g.cityDB != nil {
-		g.cityDB.Close()
-	}
-	if g.countryDB != nil {
-		g.countryDB.Close()
-	}
-	if g.asnDB != nil {
-		g.asnDB.Close()
-	}
+	g.cacheMu.Lock()
+	defer g.cacheMu.Unlock()
+	closeReaders(g.cityDBs, g.countryDBs, g.asnDBs)
 	g.logger.Info("Closed GeoIP databases")
 	return nil
 }
 
+// ReloadDatabases reopens the configured databases and swaps them in for new lookups,
+// e.g. after the updater replaced the files. Enrichment is enabled or disabled to match
+// what could be opened, and cached IPs, found or not, are looked up again in the new data.
+func (g *GeoIPEnricher) ReloadDatabases() {
+	cityDBs := g.openDatabases("City", g.cityPaths)
+	countryDBs := g.openDatabases("Country", g.countryPaths)
+	asnDBs := g.openDatabases("ASN", g.asnPaths)
+
+	g.cacheMu.Lock()
+	oldCity, oldCountry, oldASN := g.cityDBs, g.countryDBs, g.asnDBs
+	g.cityDBs, g.countryDBs, g.asnDBs = cityDBs, countryDBs, asnDBs
+	g.enabled = len(cityDBs) > 0 || len(countryDBs) > 0
+	g.negativeCache = make(map[string]time.Time)
+	g.cache = make(map[string]*geoIPCacheEntry, g.cacheSize)
+	g.lru.Init()
+	enabled := g.enabled
+	g.cacheMu.Unlock()
+
+	closeReaders(oldCity, oldCountry, oldASN)
+	g.logger.Info("Reloaded GeoIP databases", g.logger.Args("enabled", enabled))
+}
+
+// closeReaders closes every reader in the given lists
+func closeReaders(lists ...[]geoIPReader) {
+	for _, readers := range lists {
+		for _, reader := range readers {
+			reader.Close()
+		}
+	}
+}
+
 // IsEnabled returns whether GeoIP enrichment is available
 func (g *GeoIPEnricher) IsEnabled() bool {
+	g.cacheMu.RLock()
+	defer g.cacheMu.RUnlock()
 	return g.enabled
 }
 
@@ -388,4 +528,164 @@ func (g *GeoIPEnricher) GetCacheSize() int {
 	defer g.cacheMu.RUnlock()
 	return len(g.cache)
 }
+
+// GetCacheEvictions returns the number of entries dropped since startup to keep the
+// memory cache within GEOIP_CACHE_SIZE. A fast-growing count means the cache is too
+// small for the number of distinct clients.
+func (g *GeoIPEnricher) GetCacheEvictions() int64 {
+	g.statsMu.Lock()
+	defer g.statsMu.Unlock()
+	return g.cacheEvictions
+}
+
+// GetCacheMemoryEstimate returns the approximate number of bytes held by the memory
+// cache: each entry's structs (cache entry, LRU element, result), its string contents
+// and the map slot (key header and pointer)
+func (g *GeoIPEnricher) GetCacheMemoryEstimate() int64 {
+	g.cacheMu.RLock()
+	defer g.cacheMu.RUnlock()
+
+	const entryOverhead = int64(unsafe.Sizeof("") + unsafe.Sizeof(uintptr(0)) +
+		unsafe.Sizeof(geoIPCacheEntry{}) + unsafe.Sizeof(list.Element{}))
+	structSize := int64(unsafe.Sizeof(models.IPReputation{}))
+
+	var total int64
+	for ip, entry := range g.cache {
+		total += entryOverhead + int64(len(ip))
+		rep := entry.reputation
+		if rep == nil {
+			continue
+		}
+		total += structSize + int64(len(rep.IPAddress)+len(rep.Country)+
+			len(rep.CountryName)+len(rep.City)+len(rep.ASNOrg))
+	}
+	return total
+}
+
+// IsCached reports whether the IP is held in the memory cache and not expired
+func (g *GeoIPEnricher) IsCached(ip string) bool {
+	g.cacheMu.RLock()
+	defer g.cacheMu.RUnlock()
+	entry, exists := g.cache[ip]
+	return exists && time.Now().Before(entry.expires)
+}
+
+// GetCacheStats returns the number of cache hits and misses since startup
+func (g *GeoIPEnricher) GetCacheStats() (hits, misses int64) {
+	g.statsMu.Lock()
+	defer g.statsMu.Unlock()
+	return g.cacheHits, g.cacheMisses
+}
+
+// SetProviderOrder sets the priority of location providers ("city", "country").
+// Unknown names are ignored; an empty order keeps the default.
+func (g *GeoIPEnricher) SetProviderOrder(order []string) {
+	valid := make([]string, 0, len(order))
+	for _, provider := range order {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		switch provider {
+		case GeoIPProviderCity, GeoIPProviderCountry:
+			valid = append(valid, provider)
+		default:
+			g.logger.Warn("Ignoring unknown GeoIP provider", g.logger.Args("provider", provider))
+		}
+	}
+	if len(valid) == 0 {
+		valid = DefaultGeoIPProviderOrder
+	}
+	g.providerOrder = valid
+}
+
+// openDatabases opens every database in a comma-separated path list, skipping unreadable ones
+func (g *GeoIPEnricher) openDatabases(kind, paths string) []geoIPReader {
+	var readers []geoIPReader
+	for _, path := range strings.Split(paths, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		reader, err := openGeoIPDatabase(path)
+		if err != nil {
+			g.logger.Warn("GeoIP "+kind+" database not available", g.logger.Args("path", path, "error", err))
+			continue
+		}
+		readers = append(readers, reader)
+		g.logger.Info("Loaded GeoIP "+kind+" database", g.logger.Args("path", path))
+	}
+	return readers
+}
+
+// lookupCity merges a City database record into rep. City and coordinates are only
+// taken when the record agrees with the country already chosen by a higher-priority provider.
+func lookupCity(reader geoIPReader, ip net.IP, rep *models.IPReputation) error {
+	record, err := reader.City(ip)
+	if err != nil {
+		return err
+	}
+	if record.Country.IsoCode == "" && record.City.Names["en"] == "" {
+		return nil
+	}
+	if rep.Country != "" && record.Country.IsoCode != "" && record.Country.IsoCode != rep.Country {
+		return nil
+	}
+
+	if rep.Country == "" {
+		rep.Country = record.Country.IsoCode
+		rep.CountryName = record.Country.Names["en"]
+	}
+	if rep.City == "" {
+		rep.City = record.City.Names["en"]
+	}
+	if rep.Latitude == 0 && rep.Longitude == 0 {
+		rep.Latitude = record.Location.Latitude
+		rep.Longitude = record.Location.Longitude
+	}
+	return nil
+}
+
+// lookupCountry fills the country of rep from a Country database when still unknown
+func lookupCountry(reader geoIPReader, ip net.IP, rep *models.IPReputation) error {
+	if rep.Country != "" {
+		return nil
+	}
+	record, err := reader.Country(ip)
+	if err != nil {
+		return err
+	}
+	rep.Country = record.Country.IsoCode
+	rep.CountryName = record.Country.Names["en"]
+	return nil
+}
+
+// locationComplete reports whether no further location provider can add data
+func locationComplete(rep *models.IPReputation) bool {
+	return rep.Country != "" && rep.City != "" && (rep.Latitude != 0 || rep.Longitude != 0)
+}
+
+// GetLookupStats returns database lookup outcomes since startup
+func (g *GeoIPEnricher) GetLookupStats() GeoIPLookupStats {
+	g.statsMu.Lock()
+	defer g.statsMu.Unlock()
+	return g.lookups
+}
+
+// SetCacheTTL sets how long found IPs are served from the memory cache
+func (g *GeoIPEnricher) SetCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultGeoIPCacheTTL
+	}
+	g.cacheMu.Lock()
+	g.cacheTTL = ttl
+	g.cacheMu.Unlock()
+}
+
+// SetNegativeCacheTTL sets how long IPs not found in any database are cached
+func (g *GeoIPEnricher) SetNegativeCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultNegativeCacheTTL
+	}
+	g.cacheMu.Lock()
+	g.negativeCacheTTL = ttl
+	g.cacheMu.Unlock()
+}
 
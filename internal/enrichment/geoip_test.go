@@ -0,0 +1,260 @@
+package enrichment
+
+import (
+	"container/list"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeReader answers lookups from fixed results and counts calls.
+// When only is set, every other IP is reported as not in the database.
+type fakeReader struct {
+	country string
+	city    string
+	asn     uint
+	only    string
+	err     error
+	calls   int
+}
+
+func (f *fakeReader) knows(ip net.IP) bool {
+	return f.only == "" || f.only == ip.String()
+}
+
+func (f *fakeReader) City(ip net.IP) (*geoip2.City, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	record := &geoip2.City{}
+	if f.knows(ip) {
+		record.Country.IsoCode = f.country
+		if f.city != "" {
+			record.City.Names = map[string]string{"en": f.city}
+			record.Location.Latitude = 45.46
+			record.Location.Longitude = 9.19
+		}
+	}
+	return record, nil
+}
+
+func (f *fakeReader) Country(ip net.IP) (*geoip2.Country, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	record := &geoip2.Country{}
+	if f.knows(ip) {
+		record.Country.IsoCode = f.country
+	}
+	return record, nil
+}
+
+func (f *fakeReader) ASN(ip net.IP) (*geoip2.ASN, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if !f.knows(ip) {
+		return &geoip2.ASN{}, nil
+	}
+	return &geoip2.ASN{AutonomousSystemNumber: f.asn}, nil
+}
+
+func (f *fakeReader) Close() error { return nil }
+
+func newTestEnricher(reader *fakeReader) *GeoIPEnricher {
+	logger := pterm.DefaultLogger
+	return &GeoIPEnricher{
+		cityDBs:          []geoIPReader{reader},
+		providerOrder:    DefaultGeoIPProviderOrder,
+		logger:           &logger,
+		cache:            make(map[string]*geoIPCacheEntry),
+		lru:              list.New(),
+		negativeCache:    make(map[string]time.Time),
+		negativeCacheTTL: DefaultNegativeCacheTTL,
+		enabled:          true,
+		cacheSize:        100,
+		cacheTTL:         DefaultGeoIPCacheTTL,
+	}
+}
+
+func TestEnrichCachesSuccessfulLookup(t *testing.T) {
+	reader := &fakeReader{country: "IT"}
+	g := newTestEnricher(reader)
+
+	req := &models.HTTPRequest{ClientIP: "1.2.3.4"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "IT", req.GeoCountry)
+
+	req = &models.HTTPRequest{ClientIP: "1.2.3.4"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "IT", req.GeoCountry)
+	assert.Equal(t, 1, reader.calls, "second request is served from cache")
+	assert.Equal(t, GeoIPLookupStats{Success: 1}, g.GetLookupStats())
+}
+
+func TestEnrichNegativelyCachesNotFoundWithTTL(t *testing.T) {
+	reader := &fakeReader{}
+	g := newTestEnricher(reader)
+
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "10.0.0.1"}))
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "10.0.0.1"}))
+	assert.Equal(t, 1, reader.calls, "not-found result is cached within the TTL")
+	assert.Empty(t, g.cache, "not-found result is kept out of the positive cache")
+
+	// Expire the entry: the IP is looked up again and now found
+	g.negativeCache["10.0.0.1"] = time.Now().Add(-time.Second)
+	reader.country = "DE"
+	req := &models.HTTPRequest{ClientIP: "10.0.0.1"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, 2, reader.calls)
+	assert.Equal(t, "DE", req.GeoCountry)
+	assert.Empty(t, g.negativeCache, "successful lookup clears the negative entry")
+	assert.Equal(t, GeoIPLookupStats{Success: 1, NotFound: 1}, g.GetLookupStats())
+}
+
+func TestEnrichExpiresCachedResults(t *testing.T) {
+	reader := &fakeReader{country: "IT"}
+	g := newTestEnricher(reader)
+
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "1.2.3.4"}))
+	assert.True(t, g.IsCached("1.2.3.4"))
+
+	// Past the TTL the IP is looked up again, picking up its new owner
+	g.cache["1.2.3.4"].expires = time.Now().Add(-time.Second)
+	assert.False(t, g.IsCached("1.2.3.4"))
+	reader.country = "FR"
+	req := &models.HTTPRequest{ClientIP: "1.2.3.4"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "FR", req.GeoCountry)
+	assert.Equal(t, 2, reader.calls)
+	assert.Equal(t, 1, g.GetCacheSize())
+	assert.Zero(t, g.GetCacheEvictions(), "expired entries are replaced, not evicted")
+}
+
+func TestEnrichEvictsLeastRecentlyUsed(t *testing.T) {
+	reader := &fakeReader{country: "IT"}
+	g := newTestEnricher(reader)
+	g.cacheSize = 2
+
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "1.1.1.1"}))
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "2.2.2.2"}))
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "1.1.1.1"})) // 2.2.2.2 is now the least recently used
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "3.3.3.3"}))
+
+	assert.Equal(t, 2, g.GetCacheSize())
+	assert.True(t, g.IsCached("1.1.1.1"))
+	assert.False(t, g.IsCached("2.2.2.2"))
+	assert.True(t, g.IsCached("3.3.3.3"))
+	assert.Equal(t, int64(1), g.GetCacheEvictions())
+}
+
+func TestReloadDatabasesDropsCachedResults(t *testing.T) {
+	reader := &fakeReader{country: "IT"}
+	g := newTestEnricher(reader)
+	original := openGeoIPDatabase
+	openGeoIPDatabase = func(string) (geoIPReader, error) { return reader, nil }
+	t.Cleanup(func() { openGeoIPDatabase = original })
+	g.cityPaths = "city.mmdb"
+
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "1.2.3.4"}))
+	g.ReloadDatabases()
+	assert.Zero(t, g.GetCacheSize())
+
+	reader.country = "FR"
+	req := &models.HTTPRequest{ClientIP: "1.2.3.4"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "FR", req.GeoCountry)
+}
+
+func TestEnrichDoesNotCacheReaderErrors(t *testing.T) {
+	reader := &fakeReader{err: errors.New("corrupt database")}
+	g := newTestEnricher(reader)
+
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "5.6.7.8"}))
+	assert.NoError(t, g.Enrich(&models.HTTPRequest{ClientIP: "5.6.7.8"}))
+	assert.Equal(t, 2, reader.calls, "failed lookups are retried on every request")
+	assert.Empty(t, g.cache)
+	assert.Empty(t, g.negativeCache)
+	assert.Equal(t, GeoIPLookupStats{Failed: 2}, g.GetLookupStats())
+}
+
+func TestEnrichFallsBackToCountryWhenCityHasNoData(t *testing.T) {
+	city := &fakeReader{}
+	country := &fakeReader{country: "FR"}
+	g := newTestEnricher(city)
+	g.countryDBs = []geoIPReader{country}
+
+	req := &models.HTTPRequest{ClientIP: "9.9.9.9"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "FR", req.GeoCountry)
+	assert.Equal(t, 1, country.calls)
+}
+
+func TestEnrichMergesProvidersInPriorityOrder(t *testing.T) {
+	city := &fakeReader{country: "IT", city: "Milan", only: "1.1.1.1"}
+	country := &fakeReader{country: "ES", only: "2.2.2.2"}
+	asn := &fakeReader{asn: 64500, only: "2.2.2.2"}
+	g := newTestEnricher(city)
+	g.countryDBs = []geoIPReader{country}
+	g.asnDBs = []geoIPReader{asn}
+
+	// Only in the City database
+	req := &models.HTTPRequest{ClientIP: "1.1.1.1"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "IT", req.GeoCountry)
+	assert.Equal(t, "Milan", req.GeoCity)
+	assert.Equal(t, 0, country.calls, "country database is not needed once the city database answered")
+
+	// Only in the Country and ASN databases
+	req = &models.HTTPRequest{ClientIP: "2.2.2.2"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "ES", req.GeoCountry)
+	assert.Equal(t, "", req.GeoCity)
+	assert.Equal(t, 64500, req.ASN)
+
+	// Merged results are cached
+	assert.Equal(t, "ES", g.cache["2.2.2.2"].reputation.Country)
+	assert.Equal(t, 64500, g.cache["2.2.2.2"].reputation.ASN)
+	assert.Equal(t, GeoIPLookupStats{Success: 2}, g.GetLookupStats())
+}
+
+func TestEnrichFillsMissingFieldsFromLowerPriorityCityDatabase(t *testing.T) {
+	countryOnly := &fakeReader{country: "FR"} // City DB from a vendor without city names
+	detailed := &fakeReader{country: "FR", city: "Paris"}
+	conflicting := &fakeReader{country: "BE", city: "Brussels"}
+	g := newTestEnricher(countryOnly)
+	g.cityDBs = append(g.cityDBs, conflicting, detailed)
+
+	req := &models.HTTPRequest{ClientIP: "3.3.3.3"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "FR", req.GeoCountry)
+	assert.Equal(t, "Paris", req.GeoCity, "city is only taken from a database that agrees on the country")
+}
+
+func TestSetProviderOrder(t *testing.T) {
+	city := &fakeReader{country: "IT", city: "Rome"}
+	country := &fakeReader{country: "SM"}
+	g := newTestEnricher(city)
+	g.countryDBs = []geoIPReader{country}
+
+	g.SetProviderOrder([]string{" Country ", "bogus", "city"})
+	assert.Equal(t, []string{GeoIPProviderCountry, GeoIPProviderCity}, g.providerOrder)
+
+	req := &models.HTTPRequest{ClientIP: "4.4.4.4"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "SM", req.GeoCountry, "country database now takes priority")
+	assert.Equal(t, "", req.GeoCity, "city from a database disagreeing on the country is dropped")
+
+	g.SetProviderOrder(nil)
+	assert.Equal(t, DefaultGeoIPProviderOrder, g.providerOrder)
+}
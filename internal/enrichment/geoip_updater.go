@@ -0,0 +1,299 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package enrichment
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+const (
+	// DefaultGeoIPDownloadURL is MaxMind's download endpoint for GeoLite2 databases
+	DefaultGeoIPDownloadURL = "https://download.maxmind.com/app/geoip_download"
+	// DefaultGeoIPMaxAge is how old a database may get before it is downloaded again
+	DefaultGeoIPMaxAge = 7 * 24 * time.Hour
+
+	// geoIPUpdateCheckInterval is how often a running updater looks for stale databases
+	geoIPUpdateCheckInterval = 24 * time.Hour
+	// geoIPDownloadTimeout bounds a single archive or checksum download
+	geoIPDownloadTimeout = 5 * time.Minute
+)
+
+// GeoIPEdition maps a MaxMind database edition to the local file it is installed as
+type GeoIPEdition struct {
+	ID   string // MaxMind edition ID, e.g. GeoLite2-City
+	Path string // Where the extracted .mmdb file is written
+}
+
+// GeoLite2Editions returns the City, Country and ASN editions installed at the first path
+// of each comma-separated list; kinds without a path are skipped
+func GeoLite2Editions(cityPaths, countryPaths, asnPaths string) []GeoIPEdition {
+	var editions []GeoIPEdition
+	for _, e := range []GeoIPEdition{
+		{ID: "GeoLite2-City", Path: cityPaths},
+		{ID: "GeoLite2-Country", Path: countryPaths},
+		{ID: "GeoLite2-ASN", Path: asnPaths},
+	} {
+		e.Path = strings.TrimSpace(strings.Split(e.Path, ",")[0])
+		if e.Path != "" {
+			editions = append(editions, e)
+		}
+	}
+	return editions
+}
+
+// GeoIPUpdater keeps GeoIP databases present and fresh by downloading them from MaxMind
+type GeoIPUpdater struct {
+	licenseKey string
+	editions   []GeoIPEdition
+	maxAge     time.Duration
+	baseURL    string
+	client     *http.Client
+	logger     *pterm.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewGeoIPUpdater creates an updater for the given editions
+// A non-positive maxAge uses DefaultGeoIPMaxAge
+func NewGeoIPUpdater(licenseKey string, editions []GeoIPEdition, maxAge time.Duration, logger *pterm.Logger) *GeoIPUpdater {
+	if maxAge <= 0 {
+		maxAge = DefaultGeoIPMaxAge
+	}
+	return &GeoIPUpdater{
+		licenseKey: licenseKey,
+		editions:   editions,
+		maxAge:     maxAge,
+		baseURL:    DefaultGeoIPDownloadURL,
+		client:     &http.Client{Timeout: geoIPDownloadTimeout},
+		logger:     logger,
+	}
+}
+
+// Update downloads every edition whose file is missing or older than the maximum age
+// Editions that fail are left untouched; the number of installed databases is returned
+// together with the combined errors
+func (u *GeoIPUpdater) Update(ctx context.Context) (int, error) {
+	updated := 0
+	var errs []error
+	for _, edition := range u.editions {
+		if !u.needsUpdate(edition.Path) {
+			continue
+		}
+		u.logger.Info("Downloading GeoIP database", u.logger.Args("edition", edition.ID, "path", edition.Path))
+		if err := u.download(ctx, edition); err != nil {
+			u.logger.Warn("GeoIP database download failed", u.logger.Args("edition", edition.ID, "error", err))
+			errs = append(errs, fmt.Errorf("%s: %w", edition.ID, err))
+			continue
+		}
+		u.logger.Info("Installed GeoIP database", u.logger.Args("edition", edition.ID, "path", edition.Path))
+		updated++
+	}
+	return updated, errors.Join(errs...)
+}
+
+// Start re-checks the databases once a day and reloads the enricher after an update
+func (u *GeoIPUpdater) Start(enricher *GeoIPEnricher) {
+	ctx, cancel := context.WithCancel(context.Background())
+	u.cancel = cancel
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		ticker := time.NewTicker(geoIPUpdateCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if updated, _ := u.Update(ctx); updated > 0 && enricher != nil {
+					enricher.ReloadDatabases()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the update loop, aborting any download in progress
+func (u *GeoIPUpdater) Stop() {
+	if u.cancel != nil {
+		u.cancel()
+	}
+	u.wg.Wait()
+}
+
+// needsUpdate reports whether the database at path is missing or older than the maximum age
+func (u *GeoIPUpdater) needsUpdate(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return true
+	}
+	return time.Since(info.ModTime()) > u.maxAge
+}
+
+// download fetches an edition archive, verifies it against the published SHA-256
+// and atomically replaces the local database with the .mmdb it contains
+func (u *GeoIPUpdater) download(ctx context.Context, edition GeoIPEdition) error {
+	expected, err := u.fetchChecksum(ctx, edition.ID)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(edition.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	archive, err := os.CreateTemp(dir, ".geoip-archive-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	body, err := u.get(ctx, edition.ID, "tar.gz")
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(archive, io.TeeReader(body, hash))
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("download archive: %w", err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return extractDatabase(archive, edition.ID+".mmdb", edition.Path)
+}
+
+// fetchChecksum returns the hex SHA-256 MaxMind publishes for an edition archive
+func (u *GeoIPUpdater) fetchChecksum(ctx context.Context, editionID string) (string, error) {
+	body, err := u.get(ctx, editionID, "tar.gz.sha256")
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("download checksum: %w", err)
+	}
+	// Format: "<sha256>  GeoLite2-City_20240102.tar.gz"
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid checksum response")
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// get requests an edition file with the given suffix
+// Transport errors are unwrapped so the license key in the URL never reaches the logs
+func (u *GeoIPUpdater) get(ctx context.Context, editionID, suffix string) (io.ReadCloser, error) {
+	query := url.Values{}
+	query.Set("edition_id", editionID)
+	query.Set("license_key", u.licenseKey)
+	query.Set("suffix", suffix)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("request %s: %w", suffix, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("license key rejected by MaxMind")
+		}
+		return nil, fmt.Errorf("request %s: unexpected status %d", suffix, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// extractDatabase copies the file named name from a .tar.gz archive to dest,
+// writing to a temp file first so readers never see a partial database
+func extractDatabase(archive io.Reader, name, dest string) error {
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s not found in archive", name)
+		}
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || filepath.Base(header.Name) != name {
+			continue
+		}
+
+		tmp, err := os.CreateTemp(filepath.Dir(dest), ".geoip-db-*")
+		if err != nil {
+			return fmt.Errorf("create temp file: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+
+		if _, err := io.Copy(tmp, tr); err != nil {
+			tmp.Close()
+			return fmt.Errorf("extract %s: %w", name, err)
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		if err := os.Chmod(tmp.Name(), 0644); err != nil {
+			return err
+		}
+		return os.Rename(tmp.Name(), dest)
+	}
+}
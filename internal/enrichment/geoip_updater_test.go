@@ -0,0 +1,191 @@
+package enrichment
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildArchive returns a .tar.gz laid out like MaxMind's, with the database in a dated directory
+func buildArchive(t *testing.T, edition string, content []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	dir := edition + "_20260101/"
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: dir, Typeflag: tar.TypeDir, Mode: 0755}))
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: dir + "LICENSE.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 3}))
+	_, _ = tw.Write([]byte("MIT"))
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: dir + edition + ".mmdb", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+	_, _ = tw.Write(content)
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// newMaxMindServer serves archives and checksums the way the MaxMind download endpoint does
+func newMaxMindServer(t *testing.T, archives map[string][]byte, checksums map[string]string) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("license_key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		edition := r.URL.Query().Get("edition_id")
+		archive, ok := archives[edition]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.URL.Query().Get("suffix") {
+		case "tar.gz":
+			_, _ = w.Write(archive)
+		case "tar.gz.sha256":
+			sum, ok := checksums[edition]
+			if !ok {
+				digest := sha256.Sum256(archive)
+				sum = hex.EncodeToString(digest[:])
+			}
+			_, _ = w.Write([]byte(sum + "  " + edition + "_20260101.tar.gz\n"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newTestUpdater(baseURL, key string, editions []GeoIPEdition) *GeoIPUpdater {
+	logger := pterm.DefaultLogger
+	u := NewGeoIPUpdater(key, editions, 0, &logger)
+	u.baseURL = baseURL
+	return u
+}
+
+func TestGeoLite2EditionsUsesFirstPath(t *testing.T) {
+	editions := GeoLite2Editions("a/city.mmdb, b/city.mmdb", "", " asn.mmdb")
+	assert.Equal(t, []GeoIPEdition{
+		{ID: "GeoLite2-City", Path: "a/city.mmdb"},
+		{ID: "GeoLite2-ASN", Path: "asn.mmdb"},
+	}, editions)
+}
+
+func TestGeoIPUpdaterDownloadsMissingDatabase(t *testing.T) {
+	server, _ := newMaxMindServer(t, map[string][]byte{
+		"GeoLite2-City": buildArchive(t, "GeoLite2-City", []byte("city-db")),
+	}, nil)
+	path := filepath.Join(t.TempDir(), "geoip", "GeoLite2-City.mmdb")
+	u := newTestUpdater(server.URL, "secret", []GeoIPEdition{{ID: "GeoLite2-City", Path: path}})
+
+	updated, err := u.Update(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "city-db", string(data))
+
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".geoip-*"))
+	assert.Empty(t, leftovers, "temp files are cleaned up")
+}
+
+func TestGeoIPUpdaterSkipsFreshDatabase(t *testing.T) {
+	server, requests := newMaxMindServer(t, map[string][]byte{
+		"GeoLite2-City": buildArchive(t, "GeoLite2-City", []byte("new")),
+	}, nil)
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+	u := newTestUpdater(server.URL, "secret", []GeoIPEdition{{ID: "GeoLite2-City", Path: path}})
+
+	updated, err := u.Update(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, updated)
+	assert.Equal(t, 0, *requests)
+
+	// Once older than the maximum age it is replaced
+	stale := time.Now().Add(-DefaultGeoIPMaxAge - time.Hour)
+	assert.NoError(t, os.Chtimes(path, stale, stale))
+	updated, err = u.Update(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, "new", string(data))
+}
+
+func TestGeoIPUpdaterRejectsChecksumMismatch(t *testing.T) {
+	server, _ := newMaxMindServer(t, map[string][]byte{
+		"GeoLite2-City": buildArchive(t, "GeoLite2-City", []byte("tampered")),
+	}, map[string]string{
+		"GeoLite2-City": hex.EncodeToString(make([]byte, sha256.Size)),
+	})
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	u := newTestUpdater(server.URL, "secret", []GeoIPEdition{{ID: "GeoLite2-City", Path: path}})
+
+	updated, err := u.Update(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+	assert.Equal(t, 0, updated)
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr), "nothing is installed on mismatch")
+}
+
+func TestGeoIPUpdaterKeepsLicenseKeyOutOfErrors(t *testing.T) {
+	server, _ := newMaxMindServer(t, map[string][]byte{}, nil)
+	dir := t.TempDir()
+	u := newTestUpdater(server.URL, "wrong-key", []GeoIPEdition{{ID: "GeoLite2-City", Path: filepath.Join(dir, "city.mmdb")}})
+
+	_, err := u.Update(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "license key rejected")
+	assert.NotContains(t, err.Error(), "wrong-key")
+
+	// Transport errors carry the request URL; it must be stripped
+	server.Close()
+	_, err = u.Update(context.Background())
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "wrong-key")
+}
+
+func TestReloadDatabasesSwapsReaders(t *testing.T) {
+	old := &fakeReader{country: "IT"}
+	g := newTestEnricher(old)
+	g.cityPaths = "city.mmdb"
+
+	replacement := &fakeReader{country: "DE"}
+	original := openGeoIPDatabase
+	openGeoIPDatabase = func(path string) (geoIPReader, error) {
+		if path == "city.mmdb" {
+			return replacement, nil
+		}
+		return nil, errors.New("not found")
+	}
+	defer func() { openGeoIPDatabase = original }()
+
+	g.negativeCache["10.0.0.1"] = time.Now().Add(time.Hour)
+	g.ReloadDatabases()
+
+	assert.True(t, g.IsEnabled())
+	assert.Empty(t, g.negativeCache, "IPs missing from the old data are retried")
+	req := &models.HTTPRequest{ClientIP: "10.0.0.1"}
+	assert.NoError(t, g.Enrich(req))
+	assert.Equal(t, "DE", req.GeoCountry)
+	assert.Equal(t, 0, old.calls)
+
+	// Losing every location database disables enrichment
+	g.cityPaths = "missing.mmdb"
+	g.ReloadDatabases()
+	assert.False(t, g.IsEnabled())
+}
@@ -0,0 +1,110 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package enrichment
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"loglynx/internal/database/models"
+)
+
+// InternalNetworks flags requests coming from configured internal ranges
+// (monitoring probes, office VPN, ...) so they can be hidden from stats and realtime views.
+// Matching CIDRs in SQL is impractical on a text client_ip column, so the check runs
+// once at ingestion and is stored as HTTPRequest.IsInternal; ranges changed later only
+// apply to newly ingested requests.
+type InternalNetworks struct {
+	networks []*net.IPNet
+}
+
+// NewInternalNetworks parses IPs and CIDR blocks (IPv4 or IPv6); empty entries are ignored
+func NewInternalNetworks(values []string) (*InternalNetworks, error) {
+	n := &InternalNetworks{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		network, err := parseNetwork(value)
+		if err != nil {
+			return nil, err
+		}
+		n.networks = append(n.networks, network)
+	}
+	return n, nil
+}
+
+// Len returns the number of configured ranges
+func (n *InternalNetworks) Len() int {
+	return len(n.networks)
+}
+
+// Contains reports whether the client IP falls inside any internal range.
+// Bracketed IPv6, zone suffixes and IPv4-mapped IPv6 addresses are accepted.
+func (n *InternalNetworks) Contains(clientIP string) bool {
+	ip := parseClientIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, network := range n.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Enrich sets the request's internal flag
+func (n *InternalNetworks) Enrich(request *models.HTTPRequest) {
+	request.IsInternal = n.Contains(request.ClientIP)
+}
+
+// parseNetwork parses a CIDR block; a bare IP becomes a single-address network
+func parseNetwork(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid ip %q", value)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ip %q", value)
+	}
+	return network, nil
+}
+
+// parseClientIP parses a client address as logged by proxies ("[2001:db8::1]", "fe80::1%eth0")
+func parseClientIP(value string) net.IP {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	if zone := strings.IndexByte(value, '%'); zone >= 0 {
+		value = value[:zone]
+	}
+	return net.ParseIP(value)
+}
@@ -0,0 +1,63 @@
+package enrichment
+
+import (
+	"testing"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternalNetworksContains(t *testing.T) {
+	n, err := NewInternalNetworks([]string{"10.0.0.0/8", " 192.168.1.5 ", "fd00::/8", "2001:db8::1", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n.Len())
+
+	cases := []struct {
+		ip       string
+		internal bool
+	}{
+		{"10.1.2.3", true},
+		{"11.0.0.1", false},
+		{"192.168.1.5", true},
+		{"192.168.1.6", false},
+		{"fd12:3456::1", true},
+		{"[fd12:3456::1]", true},
+		{"fe80::1%eth0", false},
+		{"2001:db8::1", true},
+		{"2001:db8::2", false},
+		{"::ffff:10.0.0.1", true},
+		{"not-an-ip", false},
+		{"", false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.internal, n.Contains(tc.ip), tc.ip)
+	}
+}
+
+func TestInternalNetworksZoneSuffix(t *testing.T) {
+	n, err := NewInternalNetworks([]string{"fe80::/10"})
+	assert.NoError(t, err)
+	assert.True(t, n.Contains("fe80::1%eth0"))
+}
+
+func TestInternalNetworksInvalid(t *testing.T) {
+	_, err := NewInternalNetworks([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	_, err = NewInternalNetworks([]string{"intranet"})
+	assert.Error(t, err)
+}
+
+func TestInternalNetworksEnrich(t *testing.T) {
+	n, err := NewInternalNetworks([]string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+
+	internal := &models.HTTPRequest{ClientIP: "10.0.0.7"}
+	n.Enrich(internal)
+	assert.True(t, internal.IsInternal)
+
+	external := &models.HTTPRequest{ClientIP: "203.0.113.9"}
+	n.Enrich(external)
+	assert.False(t, external.IsInternal)
+}
@@ -0,0 +1,218 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package enrichment
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+)
+
+// Reverse DNS defaults, used when the configured value is not positive
+const (
+	DefaultReverseDNSTimeout          = 500 * time.Millisecond
+	DefaultReverseDNSWorkers          = 8
+	DefaultReverseDNSCacheTTL         = 24 * time.Hour
+	DefaultReverseDNSNegativeCacheTTL = time.Hour
+	DefaultReverseDNSCacheSize        = 50000
+)
+
+// maxHostnameLength matches the client_hostname column size
+const maxHostnameLength = 255
+
+// reverseResolver is the subset of net.Resolver used for PTR lookups
+type reverseResolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+}
+
+// rdnsEntry is a cached PTR result; an empty hostname records a failed lookup
+type rdnsEntry struct {
+	hostname string
+	expires  time.Time
+}
+
+// rdnsCall lets concurrent enrichments of the same IP share one lookup
+type rdnsCall struct {
+	done     chan struct{}
+	hostname string
+}
+
+// ReverseDNSEnricher resolves PTR records for client IPs with a TTL cache.
+// Lookups run on a bounded number of workers so a slow resolver cannot pile up
+// goroutines; failed lookups are cached for a shorter TTL before being retried.
+type ReverseDNSEnricher struct {
+	resolver reverseResolver
+	logger   *pterm.Logger
+	timeout  time.Duration
+	workers  chan struct{} // One slot per concurrent lookup
+
+	cache            map[string]rdnsEntry
+	inflight         map[string]*rdnsCall
+	cacheMu          sync.Mutex
+	cacheSize        int
+	cacheTTL         time.Duration
+	negativeCacheTTL time.Duration
+}
+
+// NewReverseDNSEnricher creates a reverse DNS enricher using the system resolver
+func NewReverseDNSEnricher(logger *pterm.Logger, timeout time.Duration, workers int, cacheTTL, negativeCacheTTL time.Duration, cacheSize int) *ReverseDNSEnricher {
+	if timeout <= 0 {
+		timeout = DefaultReverseDNSTimeout
+	}
+	if workers <= 0 {
+		workers = DefaultReverseDNSWorkers
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = DefaultReverseDNSCacheTTL
+	}
+	if negativeCacheTTL <= 0 {
+		negativeCacheTTL = DefaultReverseDNSNegativeCacheTTL
+	}
+	if cacheSize <= 0 {
+		cacheSize = DefaultReverseDNSCacheSize
+	}
+
+	return &ReverseDNSEnricher{
+		resolver:         net.DefaultResolver,
+		logger:           logger,
+		timeout:          timeout,
+		workers:          make(chan struct{}, workers),
+		cache:            make(map[string]rdnsEntry),
+		inflight:         make(map[string]*rdnsCall),
+		cacheSize:        cacheSize,
+		cacheTTL:         cacheTTL,
+		negativeCacheTTL: negativeCacheTTL,
+	}
+}
+
+// Enrich fills request.ClientHostname with the PTR record of the client IP.
+// A hostname already provided by the log (e.g. Traefik's ClientHost) is kept.
+func (r *ReverseDNSEnricher) Enrich(request *models.HTTPRequest) error {
+	if request.ClientIP == "" {
+		return nil
+	}
+	if request.ClientHostname != "" && request.ClientHostname != request.ClientIP {
+		return nil
+	}
+	if net.ParseIP(request.ClientIP) == nil {
+		return nil
+	}
+
+	if hostname := r.resolve(request.ClientIP); hostname != "" {
+		request.ClientHostname = hostname
+	}
+	return nil
+}
+
+// resolve returns the cached hostname for ip, looking it up if needed
+func (r *ReverseDNSEnricher) resolve(ip string) string {
+	now := time.Now()
+
+	r.cacheMu.Lock()
+	if entry, ok := r.cache[ip]; ok && now.Before(entry.expires) {
+		r.cacheMu.Unlock()
+		return entry.hostname
+	}
+	if call, ok := r.inflight[ip]; ok {
+		r.cacheMu.Unlock()
+		<-call.done
+		return call.hostname
+	}
+	call := &rdnsCall{done: make(chan struct{})}
+	r.inflight[ip] = call
+	r.cacheMu.Unlock()
+
+	hostname, ok := r.lookup(ip)
+
+	r.cacheMu.Lock()
+	delete(r.inflight, ip)
+	if ok {
+		r.store(ip, hostname, now)
+	}
+	r.cacheMu.Unlock()
+
+	call.hostname = hostname
+	close(call.done)
+	return hostname
+}
+
+// lookup performs the PTR query within the configured timeout.
+// ok is false when no worker became free in time, so the result is not cached.
+func (r *ReverseDNSEnricher) lookup(ip string) (hostname string, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	select {
+	case r.workers <- struct{}{}:
+	case <-ctx.Done():
+		r.logger.Trace("Reverse DNS workers busy, skipping lookup", r.logger.Args("ip", ip))
+		return "", false
+	}
+	defer func() { <-r.workers }()
+
+	names, err := r.resolver.LookupAddr(ctx, ip)
+	if err != nil || len(names) == 0 {
+		r.logger.Trace("Reverse DNS lookup failed", r.logger.Args("ip", ip, "error", err))
+		return "", true
+	}
+
+	hostname = strings.TrimSuffix(names[0], ".")
+	if len(hostname) > maxHostnameLength {
+		hostname = hostname[:maxHostnameLength]
+	}
+	return hostname, true
+}
+
+// store caches a lookup result, evicting expired entries when the cache is full
+// Caller must hold r.cacheMu
+func (r *ReverseDNSEnricher) store(ip, hostname string, now time.Time) {
+	if len(r.cache) >= r.cacheSize {
+		for cachedIP, entry := range r.cache {
+			if !now.Before(entry.expires) {
+				delete(r.cache, cachedIP)
+			}
+		}
+		// Still full of live entries: start over rather than track recency
+		if len(r.cache) >= r.cacheSize {
+			r.cache = make(map[string]rdnsEntry)
+		}
+	}
+
+	ttl := r.cacheTTL
+	if hostname == "" {
+		ttl = r.negativeCacheTTL
+	}
+	r.cache[ip] = rdnsEntry{hostname: hostname, expires: now.Add(ttl)}
+}
+
+// GetCacheSize returns the number of cached PTR results, including failures
+func (r *ReverseDNSEnricher) GetCacheSize() int {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	return len(r.cache)
+}
@@ -0,0 +1,134 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeResolver answers PTR lookups from a fixed table and counts calls
+type fakeResolver struct {
+	mu    sync.Mutex
+	names map[string]string
+	delay time.Duration
+	calls int
+}
+
+func (f *fakeResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	f.mu.Lock()
+	f.calls++
+	name, ok := f.names[addr]
+	f.mu.Unlock()
+
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return []string{name}, nil
+}
+
+func (f *fakeResolver) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func newTestReverseDNS(resolver *fakeResolver) *ReverseDNSEnricher {
+	logger := pterm.DefaultLogger
+	r := NewReverseDNSEnricher(&logger, time.Second, 2, time.Hour, time.Minute, 100)
+	r.resolver = resolver
+	return r
+}
+
+func TestReverseDNSResolvesAndCachesHostname(t *testing.T) {
+	resolver := &fakeResolver{names: map[string]string{"66.249.66.1": "crawl-66-249-66-1.googlebot.com."}}
+	r := newTestReverseDNS(resolver)
+
+	req := &models.HTTPRequest{ClientIP: "66.249.66.1", ClientHostname: "66.249.66.1"}
+	assert.NoError(t, r.Enrich(req))
+	assert.Equal(t, "crawl-66-249-66-1.googlebot.com", req.ClientHostname)
+
+	req = &models.HTTPRequest{ClientIP: "66.249.66.1"}
+	assert.NoError(t, r.Enrich(req))
+	assert.Equal(t, "crawl-66-249-66-1.googlebot.com", req.ClientHostname)
+	assert.Equal(t, 1, resolver.callCount(), "second request is served from cache")
+}
+
+func TestReverseDNSNegativelyCachesFailures(t *testing.T) {
+	resolver := &fakeResolver{names: map[string]string{}}
+	r := newTestReverseDNS(resolver)
+
+	for i := 0; i < 3; i++ {
+		req := &models.HTTPRequest{ClientIP: "203.0.113.7"}
+		assert.NoError(t, r.Enrich(req))
+		assert.Equal(t, "", req.ClientHostname)
+	}
+	assert.Equal(t, 1, resolver.callCount(), "failed lookup is cached within the negative TTL")
+
+	// Expire the entry: the IP is looked up again
+	r.cache["203.0.113.7"] = rdnsEntry{expires: time.Now().Add(-time.Second)}
+	resolver.names["203.0.113.7"] = "host.example.net."
+	req := &models.HTTPRequest{ClientIP: "203.0.113.7"}
+	assert.NoError(t, r.Enrich(req))
+	assert.Equal(t, "host.example.net", req.ClientHostname)
+	assert.Equal(t, 2, resolver.callCount())
+}
+
+func TestReverseDNSKeepsHostnameFromLog(t *testing.T) {
+	resolver := &fakeResolver{names: map[string]string{"198.51.100.1": "ptr.example.org."}}
+	r := newTestReverseDNS(resolver)
+
+	req := &models.HTTPRequest{ClientIP: "198.51.100.1", ClientHostname: "proxy.internal"}
+	assert.NoError(t, r.Enrich(req))
+	assert.Equal(t, "proxy.internal", req.ClientHostname)
+
+	// Not an IP (e.g. unix socket peer): nothing to resolve
+	req = &models.HTTPRequest{ClientIP: "@"}
+	assert.NoError(t, r.Enrich(req))
+	assert.Equal(t, 0, resolver.callCount())
+}
+
+func TestReverseDNSSharesConcurrentLookups(t *testing.T) {
+	resolver := &fakeResolver{names: map[string]string{"192.0.2.10": "shared.example.com."}, delay: 50 * time.Millisecond}
+	r := newTestReverseDNS(resolver)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := &models.HTTPRequest{ClientIP: "192.0.2.10"}
+			assert.NoError(t, r.Enrich(req))
+			assert.Equal(t, "shared.example.com", req.ClientHostname)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, resolver.callCount(), "concurrent requests for one IP share a single lookup")
+}
+
+func TestReverseDNSTimesOutSlowLookups(t *testing.T) {
+	resolver := &fakeResolver{names: map[string]string{"192.0.2.20": "slow.example.com."}, delay: time.Second}
+	logger := pterm.DefaultLogger
+	r := NewReverseDNSEnricher(&logger, 20*time.Millisecond, 1, time.Hour, time.Minute, 100)
+	r.resolver = resolver
+
+	start := time.Now()
+	req := &models.HTTPRequest{ClientIP: "192.0.2.20"}
+	assert.NoError(t, r.Enrich(req))
+	assert.Equal(t, "", req.ClientHostname)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, 1, r.GetCacheSize(), "timeout is cached as a failed lookup")
+}
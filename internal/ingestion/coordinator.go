@@ -23,6 +23,9 @@ package ingestion
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -41,8 +44,17 @@ type Coordinator struct {
 	httpRepo            repositories.HTTPRequestRepository
 	parserReg           *parsers.Registry
 	geoIP               *enrichment.GeoIPEnricher
+	reverseDNS          *enrichment.ReverseDNSEnricher
+	classifier          *enrichment.Classifier
+	internalNetworks    *enrichment.InternalNetworks
+	trustedProxies      *enrichment.TrustedProxies
+	parseStats          *ParseStatsRecorder
+	deadLetter          *DeadLetterWriter
+	excludedASNs        map[int]struct{}
+	hashFields          models.HashFields // Request hash fields, refined per source
+	remote              RemoteConfig      // HTTP settings of http(s) URL sources
 	metricsCollector    *realtime.MetricsCollector
-	processors          map[string]*SourceProcessor
+	processors          map[string]*SourceProcessor // Keyed by source name, or by name and file for glob sources
 	logger              *pterm.Logger
 	mu                  sync.RWMutex
 	isRunning           bool
@@ -50,7 +62,11 @@ type Coordinator struct {
 	initialImportEnable bool
 	batchSize           int
 	workerPoolSize      int
+	batchTimeout        time.Duration
+	maxLineLength       int
+	partialLineDelay    time.Duration
 	hasExistingData     bool
+	parseErrorInterval  time.Duration
 }
 
 // NewCoordinator creates a new ingestion coordinator
@@ -80,9 +96,121 @@ func NewCoordinator(
 		batchSize:           batchSize,
 		workerPoolSize:      workerPoolSize,
 		hasExistingData:     httpRepo.HasExistingData(),
+		parseErrorInterval:  DefaultParseErrorLogInterval,
+		hashFields:          models.DefaultHashFields,
 	}
 }
 
+// SetParseErrorLogInterval sets the window over which parse-failure warnings are
+// coalesced into a summary (0 logs every failure). Applies to processors started afterwards.
+func (c *Coordinator) SetParseErrorLogInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.parseErrorInterval = interval
+}
+
+// SetBatchTimeout sets how long a partial batch waits before it is flushed (0 keeps the default).
+// Applies to processors started afterwards.
+func (c *Coordinator) SetBatchTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batchTimeout = timeout
+}
+
+// SetMaxLineLength sets the longest log line read, in bytes (0 keeps the default).
+// Applies to processors started afterwards.
+func (c *Coordinator) SetMaxLineLength(maxLen int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxLineLength = maxLen
+}
+
+// SetPartialLineFlushDelay sets how long a last line without newline must stay unchanged
+// before it is read as complete (0 waits for the newline). Applies to processors started afterwards.
+func (c *Coordinator) SetPartialLineFlushDelay(delay time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.partialLineDelay = delay
+}
+
+// SetReverseDNS enables PTR enrichment of client IPs (nil disables it).
+// Applies to processors started afterwards.
+func (c *Coordinator) SetReverseDNS(reverseDNS *enrichment.ReverseDNSEnricher) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reverseDNS = reverseDNS
+}
+
+// SetClassifier enables user-defined request labels (nil disables it).
+// Applies to processors started afterwards; rule reloads apply to all of them.
+func (c *Coordinator) SetClassifier(classifier *enrichment.Classifier) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.classifier = classifier
+}
+
+// SetInternalNetworks enables flagging of requests from internal ranges (nil disables it).
+// Applies to processors started afterwards.
+func (c *Coordinator) SetInternalNetworks(networks *enrichment.InternalNetworks) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.internalNetworks = networks
+}
+
+// SetTrustedProxies enables X-Forwarded-For client resolution behind the given
+// proxies (nil keeps the IP logged by the proxy). Applies to processors started afterwards.
+func (c *Coordinator) SetTrustedProxies(proxies *enrichment.TrustedProxies) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trustedProxies = proxies
+}
+
+// SetParseStatsRecorder enables persisted per-source parse counters (nil disables them).
+// Applies to processors started afterwards.
+func (c *Coordinator) SetParseStatsRecorder(recorder *ParseStatsRecorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.parseStats = recorder
+}
+
+// SetDeadLetterWriter enables the log of rejected lines (nil disables it).
+// Applies to processors started afterwards.
+func (c *Coordinator) SetDeadLetterWriter(writer *DeadLetterWriter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadLetter = writer
+}
+
+// SetExcludedASNs drops requests whose client ASN is in asns before they are stored.
+// Needs the GeoIP ASN database; applies to processors started afterwards.
+func (c *Coordinator) SetExcludedASNs(asns []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.excludedASNs = nil
+	if len(asns) == 0 {
+		return
+	}
+	c.excludedASNs = make(map[int]struct{}, len(asns))
+	for _, asn := range asns {
+		c.excludedASNs[asn] = struct{}{}
+	}
+}
+
+// SetHashFields sets the request hash fields of every source; sources can add or
+// remove fields on top of them
+// Must be called before Start
+func (c *Coordinator) SetHashFields(fields models.HashFields) {
+	c.hashFields = fields
+}
+
+// SetRemoteConfig sets the headers, poll interval and timeout used by sources whose
+// path is an http:// or https:// URL. Applies to processors started afterwards.
+func (c *Coordinator) SetRemoteConfig(cfg RemoteConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remote = cfg
+}
+
 // Start initializes and starts all source processors
 func (c *Coordinator) Start() error {
 	c.mu.Lock()
@@ -136,12 +264,86 @@ func (c *Coordinator) Start() error {
 	return nil
 }
 
-// startSourceProcessorLocked creates and starts a processor for a single source
+// globProcessorKey is the processors map key of one file of a glob source
+func globProcessorKey(sourceName, path string) string {
+	return sourceName + "\x00" + path
+}
+
+// startSourceProcessorLocked creates and starts the processors for a single source:
+// one for a plain path, one per matching file for a glob
 // IMPORTANT: Caller must hold c.mu lock
 func (c *Coordinator) startSourceProcessorLocked(source *models.LogSource) error {
+	if source.IsGlob() {
+		_, err := c.startGlobSourceLocked(source)
+		return err
+	}
+	return c.startFileProcessorLocked(source.Name, source, false)
+}
+
+// startGlobSourceLocked starts a processor for every file matching the source's
+// glob that is not followed yet, resuming from its log_source_files position.
+// It returns the number of processors started.
+// IMPORTANT: Caller must hold c.mu lock
+func (c *Coordinator) startGlobSourceLocked(source *models.LogSource) (int, error) {
+	matches, err := filepath.Glob(source.Path)
+	if err != nil {
+		return 0, fmt.Errorf("invalid glob pattern %s: %w", source.Path, err)
+	}
+	if len(matches) == 0 {
+		c.logger.Debug("No files match glob source yet", c.logger.Args("source", source.Name, "pattern", source.Path))
+		return 0, nil
+	}
+
+	files, err := c.sourceRepo.FindFiles(source.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load file tracking: %w", err)
+	}
+	tracked := make(map[string]*models.LogSourceFile, len(files))
+	for _, file := range files {
+		tracked[file.Path] = file
+	}
+
+	started := 0
+	for _, match := range matches {
+		key := globProcessorKey(source.Name, match)
+		if _, exists := c.processors[key]; exists {
+			continue
+		}
+		if info, err := os.Stat(match); err != nil || info.IsDir() {
+			continue
+		}
+
+		// Each file gets its own copy of the source so the processor reads and
+		// tracks that file while requests keep the logical source name
+		fileSource := *source
+		fileSource.Path = match
+		fileSource.LastPosition = 0
+		fileSource.LastInode = 0
+		fileSource.LastLineContent = ""
+		fileSource.LastReadAt = nil
+		if file, ok := tracked[match]; ok {
+			fileSource.LastPosition = file.LastPosition
+			fileSource.LastInode = file.LastInode
+			fileSource.LastLineContent = file.LastLineContent
+			fileSource.LastReadAt = file.LastReadAt
+		}
+
+		if err := c.startFileProcessorLocked(key, &fileSource, true); err != nil {
+			c.logger.WithCaller().Warn("Failed to start processor for matched file",
+				c.logger.Args("source", source.Name, "path", match, "error", err))
+			continue
+		}
+		started++
+	}
+	return started, nil
+}
+
+// startFileProcessorLocked creates and starts a processor reading source.Path
+// IMPORTANT: Caller must hold c.mu lock
+func (c *Coordinator) startFileProcessorLocked(key string, source *models.LogSource, trackFile bool) error {
 	// Check if processor already exists
-	if _, exists := c.processors[source.Name]; exists {
-		c.logger.Debug("Processor already exists for source, skipping", c.logger.Args("source", source.Name))
+	if _, exists := c.processors[key]; exists {
+		c.logger.Debug("Processor already exists for source, skipping", c.logger.Args("source", source.Name, "path", source.Path))
 		return nil
 	}
 
@@ -173,9 +375,19 @@ func (c *Coordinator) startSourceProcessorLocked(source *models.LogSource) error
 		c.workerPoolSize,
 		c.hasExistingData,
 	)
-
-	// Apply initial import limit if enabled and this is a new source
-	if c.initialImportEnable && c.initialImportDays > 0 {
+	c.configureProcessor(processor)
+	if c.batchTimeout > 0 {
+		processor.batchTimeout = c.batchTimeout
+	}
+	processor.reader.SetMaxLineLength(c.maxLineLength)
+	processor.reader.SetPartialLineFlushDelay(c.partialLineDelay)
+	processor.reader.SetRemoteConfig(c.remote)
+	processor.trackFile = trackFile
+
+	// Apply initial import limit if enabled and this is a new source.
+	// Stdin and named pipes skip it along with inode and rotation tracking,
+	// remote sources because they cannot be searched without downloading them.
+	if c.initialImportEnable && c.initialImportDays > 0 && !processor.reader.IsStream() && !processor.reader.IsRemote() {
 		if err := processor.ApplyInitialImportLimit(c.initialImportDays); err != nil {
 			c.logger.WithCaller().Warn("Failed to apply initial import limit (will import all data)",
 				c.logger.Args("source", source.Name, "error", err))
@@ -187,7 +399,7 @@ func (c *Coordinator) startSourceProcessorLocked(source *models.LogSource) error
 	processor.Start()
 
 	// Add to active processors map
-	c.processors[source.Name] = processor
+	c.processors[key] = processor
 
 	c.logger.Info("Started processor for source",
 		c.logger.Args(
@@ -199,6 +411,29 @@ func (c *Coordinator) startSourceProcessorLocked(source *models.LogSource) error
 	return nil
 }
 
+// configureProcessor applies the coordinator's enrichment and error reporting settings
+func (c *Coordinator) configureProcessor(processor *SourceProcessor) {
+	processor.parseErrors = newParseErrorLimiter(c.parseErrorInterval)
+	processor.reverseDNS = c.reverseDNS
+	processor.classifier = c.classifier
+	processor.internalNetworks = c.internalNetworks
+	processor.trustedProxies = c.trustedProxies
+	processor.parseStats = c.parseStats
+	processor.deadLetter = c.deadLetter
+	processor.excludedASNs = c.excludedASNs
+
+	processor.hashFields = c.hashFields
+	if spec := processor.source.HashFields; spec != "" {
+		fields, err := c.hashFields.With(spec)
+		if err != nil {
+			c.logger.Warn("Invalid hash fields for source, using the global ones",
+				c.logger.Args("source", processor.source.Name, "hash_fields", spec, "error", err))
+		} else {
+			processor.hashFields = fields
+		}
+	}
+}
+
 // Stop gracefully stops all source processors
 func (c *Coordinator) Stop() {
 	c.mu.Lock()
@@ -266,6 +501,43 @@ func (c *Coordinator) GetStatus() map[string]interface{} {
 	}
 }
 
+// GetProcessorMetrics returns counter snapshots of all running processors, ordered by source.
+// The processors of a glob source's files are summed into one entry.
+func (c *Coordinator) GetProcessorMetrics() []ProcessorMetrics {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	bySource := make(map[string]*ProcessorMetrics, len(c.processors))
+	for _, processor := range c.processors {
+		snapshot := processor.GetMetrics()
+		total, ok := bySource[snapshot.Source]
+		if !ok {
+			bySource[snapshot.Source] = &snapshot
+			continue
+		}
+		total.Processed += snapshot.Processed
+		total.InsertErrors += snapshot.InsertErrors
+		total.ParseErrors += snapshot.ParseErrors
+		total.Filtered += snapshot.Filtered
+		total.Sampled += snapshot.Sampled
+		total.BytesRead += snapshot.BytesRead
+		total.BatchInserts += snapshot.BatchInserts
+		total.BatchInsertSeconds += snapshot.BatchInsertSeconds
+		if snapshot.StartedAt.Before(total.StartedAt) {
+			total.StartedAt = snapshot.StartedAt
+		}
+	}
+
+	metrics := make([]ProcessorMetrics, 0, len(bySource))
+	for _, snapshot := range bySource {
+		metrics = append(metrics, *snapshot)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Source < metrics[j].Source
+	})
+	return metrics
+}
+
 // IsRunning returns whether the coordinator is currently running
 func (c *Coordinator) IsRunning() bool {
 	c.mu.RLock()
@@ -358,26 +630,39 @@ func (c *Coordinator) RemoveProcessor(sourceName string) error {
 		return fmt.Errorf("coordinator is not running")
 	}
 
-	processor, exists := c.processors[sourceName]
-	if !exists {
+	if c.removeProcessorsLocked(sourceName) == 0 {
 		c.logger.Debug("Processor not found, nothing to remove", c.logger.Args("source", sourceName))
 		return nil
 	}
 
-	c.logger.Info("Removing processor", c.logger.Args("source", sourceName))
-
-	// Stop the processor gracefully
-	processor.Stop()
-
-	// Remove from map
-	delete(c.processors, sourceName)
-
 	c.logger.Info("Successfully removed processor",
 		c.logger.Args("source", sourceName, "remaining_processors", len(c.processors)))
 
 	return nil
 }
 
+// removeProcessorsLocked stops and removes every processor of a source and returns how many there were
+// IMPORTANT: Caller must hold c.mu lock
+func (c *Coordinator) removeProcessorsLocked(sourceName string) int {
+	// A glob source has one processor per matched file
+	removed := 0
+	for key, processor := range c.processors {
+		if processor.source.Name != sourceName {
+			continue
+		}
+
+		c.logger.Info("Removing processor", c.logger.Args("source", sourceName, "path", processor.source.Path))
+
+		// Stop the processor gracefully
+		processor.Stop()
+
+		// Remove from map
+		delete(c.processors, key)
+		removed++
+	}
+	return removed
+}
+
 // SyncWithDatabase reconciles active processors with database log sources
 // Adds processors for new sources and removes processors for deleted sources
 func (c *Coordinator) SyncWithDatabase() error {
@@ -406,21 +691,36 @@ func (c *Coordinator) SyncWithDatabase() error {
 	}
 
 	// Phase 1: Remove processors for sources that no longer exist in DB
-	for name := range c.processors {
-		if _, exists := dbSources[name]; !exists {
+	for key, processor := range c.processors {
+		if _, exists := dbSources[processor.source.Name]; !exists {
 			c.logger.Info("Source removed from database, stopping processor",
-				c.logger.Args("source", name))
+				c.logger.Args("source", processor.source.Name, "path", processor.source.Path))
 
 			// Stop and remove processor
-			processor := c.processors[name]
 			processor.Stop()
-			delete(c.processors, name)
+			delete(c.processors, key)
 		}
 	}
 
-	// Phase 2: Add processors for new sources in DB
+	// Phase 2: Add processors for new sources in DB, and for files newly
+	// matching a glob source
 	addedCount := 0
 	for _, source := range sources {
+		if source.IsGlob() {
+			started, err := c.startGlobSourceLocked(source)
+			if err != nil {
+				c.logger.WithCaller().Warn("Failed to expand glob source",
+					c.logger.Args("source", source.Name, "error", err))
+				continue
+			}
+			if started > 0 {
+				c.logger.Info("New files matched glob source, started processors",
+					c.logger.Args("source", source.Name, "files", started))
+			}
+			addedCount += started
+			continue
+		}
+
 		if _, exists := c.processors[source.Name]; !exists {
 			c.logger.Info("New source found in database, starting processor",
 				c.logger.Args("source", source.Name))
@@ -0,0 +1,195 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package ingestion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// DeadLetterStats is a snapshot of the dead-letter log counters
+type DeadLetterStats struct {
+	Path        string `json:"path"`
+	TotalErrors int64  `json:"total_errors"` // Rejected lines routed to the dead-letter log
+	Written     int64  `json:"written"`      // Lines appended to the file
+	Dropped     int64  `json:"dropped"`      // Lines skipped by the rate limit or lost to write failures
+}
+
+// deadLetterEntry is one JSON line of the dead-letter log
+type deadLetterEntry struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"`
+	Parser string    `json:"parser"`
+	Error  string    `json:"error"`
+	Line   string    `json:"line"`
+}
+
+// DeadLetterWriter appends lines the parsers rejected to a size-capped file, so
+// users can inspect what was dropped. When the file would exceed maxBytes it is
+// renamed to <path>.1 (replacing the previous backup) and a new one is started.
+// Writes beyond rateLimit lines per second are counted but not written.
+type DeadLetterWriter struct {
+	path      string
+	maxBytes  int64 // 0 = no size cap
+	rateLimit int   // Lines per second, 0 = unlimited
+	logger    *pterm.Logger
+
+	mu          sync.Mutex
+	file        *os.File
+	size        int64
+	windowStart time.Time
+	windowCount int
+	stats       DeadLetterStats
+}
+
+// NewDeadLetterWriter opens (or creates) the dead-letter log at path
+func NewDeadLetterWriter(path string, maxBytes int64, rateLimit int, logger *pterm.Logger) (*DeadLetterWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+
+	w := &DeadLetterWriter{
+		path:      path,
+		maxBytes:  maxBytes,
+		rateLimit: rateLimit,
+		logger:    logger,
+		stats:     DeadLetterStats{Path: path},
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open opens the log file for appending and picks up its current size
+func (w *DeadLetterWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat dead-letter log: %w", err)
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// Write records a rejected line with the reason it was rejected.
+// Safe for concurrent use by the parser workers.
+func (w *DeadLetterWriter) Write(source, parser, line string, reason error) {
+	entry, err := json.Marshal(deadLetterEntry{
+		Time:   time.Now().UTC(),
+		Source: source,
+		Parser: parser,
+		Error:  reason.Error(),
+		Line:   line,
+	})
+	if err != nil {
+		return
+	}
+	entry = append(entry, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stats.TotalErrors++
+	if w.file == nil || !w.allow(time.Now()) {
+		w.stats.Dropped++
+		return
+	}
+
+	if w.maxBytes > 0 && w.size > 0 && w.size+int64(len(entry)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			w.logger.Warn("Failed to rotate dead-letter log", w.logger.Args("path", w.path, "error", err))
+			w.stats.Dropped++
+			return
+		}
+	}
+
+	n, err := w.file.Write(entry)
+	w.size += int64(n)
+	if err != nil {
+		w.stats.Dropped++
+		return
+	}
+	w.stats.Written++
+}
+
+// allow reports whether another line fits in the current one-second window
+func (w *DeadLetterWriter) allow(now time.Time) bool {
+	if w.rateLimit <= 0 {
+		return true
+	}
+	if now.Sub(w.windowStart) >= time.Second {
+		w.windowStart = now
+		w.windowCount = 0
+	}
+	if w.windowCount >= w.rateLimit {
+		return false
+	}
+	w.windowCount++
+	return true
+}
+
+// rotate moves the current file to <path>.1 and starts a new one
+func (w *DeadLetterWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	if err := os.Rename(w.path, w.path+".1"); err != nil {
+		// Keep appending to the existing file rather than losing every later line
+		if openErr := w.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	return w.open()
+}
+
+// Stats returns a snapshot of the counters
+func (w *DeadLetterWriter) Stats() DeadLetterStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+// Close closes the underlying file; later writes are counted as dropped
+func (w *DeadLetterWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
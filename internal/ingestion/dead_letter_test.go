@@ -0,0 +1,97 @@
+package ingestion
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetterWriterAppendsJSONLines(t *testing.T) {
+	logger := pterm.DefaultLogger
+	path := filepath.Join(t.TempDir(), "logs", "dead-letter.log")
+
+	w, err := NewDeadLetterWriter(path, 0, 0, &logger)
+	if err != nil {
+		t.Fatalf("Failed to open dead-letter log: %v", err)
+	}
+	w.Write("traefik-main", "traefik", "not a log line", errors.New("invalid JSON"))
+	w.Write("traefik-main", "traefik", `{"broken":`, errors.New("unexpected end of JSON input"))
+	assert.NoError(t, w.Close())
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open dead-letter log: %v", err)
+	}
+	defer file.Close()
+
+	var entries []deadLetterEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry deadLetterEntry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+	assert.Equal(t, "traefik-main", entries[0].Source)
+	assert.Equal(t, "traefik", entries[0].Parser)
+	assert.Equal(t, "invalid JSON", entries[0].Error)
+	assert.Equal(t, `{"broken":`, entries[1].Line)
+
+	stats := w.Stats()
+	assert.Equal(t, DeadLetterStats{Path: path, TotalErrors: 2, Written: 2}, stats)
+}
+
+func TestDeadLetterWriterRateLimit(t *testing.T) {
+	logger := pterm.DefaultLogger
+	w, err := NewDeadLetterWriter(filepath.Join(t.TempDir(), "dead-letter.log"), 0, 3, &logger)
+	if err != nil {
+		t.Fatalf("Failed to open dead-letter log: %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 10; i++ {
+		w.Write("src", "caddy", "bad", errors.New("rejected"))
+	}
+
+	stats := w.Stats()
+	assert.Equal(t, int64(10), stats.TotalErrors)
+	assert.Equal(t, int64(3), stats.Written)
+	assert.Equal(t, int64(7), stats.Dropped)
+}
+
+func TestDeadLetterWriterRotatesAtSizeCap(t *testing.T) {
+	logger := pterm.DefaultLogger
+	path := filepath.Join(t.TempDir(), "dead-letter.log")
+	w, err := NewDeadLetterWriter(path, 512, 0, &logger)
+	if err != nil {
+		t.Fatalf("Failed to open dead-letter log: %v", err)
+	}
+	defer w.Close()
+
+	line := strings.Repeat("x", 200)
+	for i := 0; i < 5; i++ {
+		w.Write("src", "generic", line, errors.New("rejected"))
+	}
+
+	current, err := os.Stat(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.LessOrEqual(t, current.Size(), int64(512))
+
+	backup, err := os.Stat(path + ".1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.LessOrEqual(t, backup.Size(), int64(512))
+	assert.Equal(t, int64(5), w.Stats().Written)
+}
@@ -0,0 +1,136 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package ingestion
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"loglynx/internal/database/models"
+)
+
+// ErrUnknownParser is returned by Import when no parser is registered under the given name
+var ErrUnknownParser = errors.New("unknown parser")
+
+// ImportResult counts the outcome of a bulk import
+type ImportResult struct {
+	Lines      int64 `json:"lines"`      // Non-empty lines read
+	Inserted   int64 `json:"inserted"`   // New requests stored
+	Duplicates int64 `json:"duplicates"` // Requests already stored (same request hash)
+	Failed     int64 `json:"failed"`     // Lines the parser rejected or cannot handle, or over the maximum length
+	Filtered   int64 `json:"filtered"`   // Requests dropped by the ASN exclude list
+}
+
+// Import parses the lines read from r with the named parser, enriches them like the
+// requests of a log source and stores them under sourceName, one batch at a time.
+// Batches stored before a read or insert error are kept and counted in the result.
+func (c *Coordinator) Import(parserType string, sourceName string, r io.Reader) (*ImportResult, error) {
+	parser, err := c.parserReg.Get(parserType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownParser, parserType)
+	}
+
+	// The processor is only used for parsing and enrichment: it has no file to read
+	// and is never started
+	processor := NewSourceProcessor(
+		&models.LogSource{Name: sourceName, ParserType: parserType},
+		parser,
+		c.httpRepo,
+		c.sourceRepo,
+		c.geoIP,
+		nil, // Old lines would distort the real-time metrics
+		c.logger,
+		c.batchSize,
+		c.workerPoolSize,
+		true,
+	)
+	c.configureProcessor(processor)
+	defer processor.cancel()
+
+	maxLineLength := c.maxLineLength
+	if maxLineLength <= 0 {
+		maxLineLength = DefaultMaxLineLength
+	}
+	reader := bufio.NewReaderSize(r, 64*1024)
+
+	result := &ImportResult{}
+	lines := make([]string, 0, processor.batchSize)
+	for {
+		data, n, tooLong, err := readLine(reader, maxLineLength)
+		if err != nil && err != io.EOF {
+			return result, fmt.Errorf("failed to read import body: %w", err)
+		}
+		if tooLong {
+			// Skipped like an overlong line of a log file, without aborting the import
+			c.logger.Warn("Skipping import line longer than the maximum line length",
+				c.logger.Args("source", sourceName, "bytes", n, "max_bytes", maxLineLength))
+			result.Lines++
+			result.Failed++
+		} else if line := strings.TrimRight(string(data), "\r\n"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+			if len(lines) == processor.batchSize {
+				if err := c.importBatch(processor, lines, result); err != nil {
+					return result, err
+				}
+				lines = lines[:0]
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if err := c.importBatch(processor, lines, result); err != nil {
+		return result, err
+	}
+
+	c.logger.Info("Imported log lines",
+		c.logger.Args("source", sourceName, "parser", parserType, "lines", result.Lines,
+			"inserted", result.Inserted, "duplicates", result.Duplicates, "failed", result.Failed))
+	return result, nil
+}
+
+// importBatch parses, enriches and stores one batch of import lines
+func (c *Coordinator) importBatch(processor *SourceProcessor, lines []string, result *ImportResult) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	result.Lines += int64(len(lines))
+
+	requests, counts := processor.parseAndEnrich(lines, nil)
+	result.Failed += counts.skipped + counts.failed
+	result.Filtered += counts.filtered
+	if len(requests) == 0 {
+		return nil
+	}
+
+	inserted, err := c.httpRepo.CreateBatch(requests)
+	if err != nil {
+		result.Failed += int64(len(requests))
+		return fmt.Errorf("failed to store imported requests: %w", err)
+	}
+	result.Inserted += int64(inserted)
+	result.Duplicates += int64(len(requests) - inserted)
+	return nil
+}
@@ -0,0 +1,86 @@
+package ingestion
+
+import (
+	"strings"
+	"testing"
+
+	"loglynx/internal/database/models"
+	parsers "loglynx/internal/parser"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dedupingHTTPRepo skips requests from client IPs it has already stored
+type dedupingHTTPRepo struct {
+	fakeHTTPRepo
+	seen map[string]bool
+}
+
+func (f *dedupingHTTPRepo) CreateBatch(requests []*models.HTTPRequest) (int, error) {
+	inserted := 0
+	for _, req := range requests {
+		if !f.seen[req.ClientIP] {
+			f.seen[req.ClientIP] = true
+			inserted++
+		}
+	}
+	f.batches = append(f.batches, requests)
+	return inserted, nil
+}
+
+func newImportCoordinator(repo *dedupingHTTPRepo) *Coordinator {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	registry := parsers.NewRegistry(logger)
+	registry.Register("asn-test", asnParser{})
+	c := NewCoordinator(nil, repo, registry, nil, nil, logger, 0, false, 2, 1)
+	c.SetExcludedASNs([]int{64500})
+	return c
+}
+
+func TestImport(t *testing.T) {
+	repo := &dedupingHTTPRepo{seen: map[string]bool{}}
+	c := newImportCoordinator(repo)
+
+	body := strings.Join([]string{
+		"1.1.1.1 13335",
+		"",
+		"2.2.2.2 13335\r",
+		"1.1.1.1 13335", // Duplicate
+		"not parsable by the test parser",
+		"3.3.3.3 abc", // Parse error
+		"4.4.4.4 64500",
+		"5.5.5.5 13335",
+	}, "\n")
+
+	result, err := c.Import("asn-test", "offline-dump", strings.NewReader(body))
+	require.NoError(t, err)
+	assert.Equal(t, &ImportResult{Lines: 7, Inserted: 3, Duplicates: 1, Failed: 2, Filtered: 1}, result)
+
+	require.NotEmpty(t, repo.batches)
+	for _, batch := range repo.batches {
+		assert.LessOrEqual(t, len(batch), 2, "lines are stored in batches of the configured size")
+		for _, req := range batch {
+			assert.Equal(t, "offline-dump", req.SourceName)
+		}
+	}
+}
+
+func TestImportSkipsLinesOverMaxLength(t *testing.T) {
+	repo := &dedupingHTTPRepo{seen: map[string]bool{}}
+	c := newImportCoordinator(repo)
+	c.SetMaxLineLength(1024)
+
+	body := "1.1.1.1 13335\n" + strings.Repeat("x", 4096) + "\n2.2.2.2 13335"
+	result, err := c.Import("asn-test", "offline-dump", strings.NewReader(body))
+	require.NoError(t, err)
+	assert.Equal(t, &ImportResult{Lines: 3, Inserted: 2, Failed: 1}, result, "the lines after the overlong one are still imported")
+}
+
+func TestImportRejectsUnknownParser(t *testing.T) {
+	c := newImportCoordinator(&dedupingHTTPRepo{seen: map[string]bool{}})
+
+	_, err := c.Import("missing", "offline-dump", strings.NewReader("1.1.1.1 13335"))
+	assert.ErrorIs(t, err, ErrUnknownParser)
+}
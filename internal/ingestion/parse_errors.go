@@ -0,0 +1,78 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package ingestion
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultParseErrorLogInterval is the window over which parse failures are coalesced
+const DefaultParseErrorLogInterval = 10 * time.Second
+
+// parseErrorLimiter coalesces parse-failure warnings. The first failure of a window
+// is logged in full; later ones are only counted and reported as a summary once the
+// window has elapsed. An interval <= 0 disables coalescing.
+type parseErrorLimiter struct {
+	interval    time.Duration
+	mu          sync.Mutex
+	windowStart time.Time
+	suppressed  int64
+}
+
+// newParseErrorLimiter creates a limiter with the given coalescing window
+func newParseErrorLimiter(interval time.Duration) *parseErrorLimiter {
+	return &parseErrorLimiter{interval: interval}
+}
+
+// allow reports whether a failure seen at now should be logged individually
+func (l *parseErrorLimiter) allow(now time.Time) bool {
+	if l.interval <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.windowStart.IsZero() {
+		l.windowStart = now
+		return true
+	}
+	l.suppressed++
+	return false
+}
+
+// summary returns how many failures were suppressed once the current window has
+// elapsed, and closes the window so the next failure is logged in full again
+func (l *parseErrorLimiter) summary(now time.Time) (int64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.windowStart.IsZero() || now.Sub(l.windowStart) < l.interval {
+		return 0, false
+	}
+
+	suppressed := l.suppressed
+	l.windowStart = time.Time{}
+	l.suppressed = 0
+	return suppressed, suppressed > 0
+}
@@ -0,0 +1,40 @@
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorLimiterCoalescesWithinWindow(t *testing.T) {
+	limiter := newParseErrorLimiter(10 * time.Second)
+	start := time.Now()
+
+	assert.True(t, limiter.allow(start), "first failure is logged")
+	for i := 1; i <= 5; i++ {
+		assert.False(t, limiter.allow(start.Add(time.Duration(i)*time.Second)))
+	}
+
+	_, ok := limiter.summary(start.Add(5 * time.Second))
+	assert.False(t, ok, "no summary before the window elapses")
+
+	suppressed, ok := limiter.summary(start.Add(10 * time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), suppressed)
+
+	// A new window starts with a fully logged failure again
+	assert.True(t, limiter.allow(start.Add(11*time.Second)))
+	_, ok = limiter.summary(start.Add(30 * time.Second))
+	assert.False(t, ok, "nothing suppressed, nothing to summarize")
+}
+
+func TestParseErrorLimiterDisabled(t *testing.T) {
+	limiter := newParseErrorLimiter(0)
+	now := time.Now()
+
+	assert.True(t, limiter.allow(now))
+	assert.True(t, limiter.allow(now))
+	_, ok := limiter.summary(now.Add(time.Hour))
+	assert.False(t, ok)
+}
@@ -0,0 +1,256 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package ingestion
+
+import (
+	"loglynx/internal/database/models"
+	"loglynx/internal/database/repositories"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// DefaultParseStatsBucket is the granularity of the persisted parse counters
+const DefaultParseStatsBucket = 5 * time.Minute
+
+// parseStatsKey identifies a pending bucket
+type parseStatsKey struct {
+	source string
+	bucket time.Time
+}
+
+// ParseRatePoint is one interval of a source's parse-rate timeline
+type ParseRatePoint struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Parsed      int64     `json:"parsed"`
+	Failed      int64     `json:"failed"`
+	Skipped     int64     `json:"skipped"`
+	Duplicates  int64     `json:"duplicates"`
+	SuccessRate *float64  `json:"success_rate"` // Percentage of lines parsed; nil when no lines were read
+}
+
+// setSuccessRate computes the share of lines that parsed successfully
+func (p *ParseRatePoint) setSuccessRate() {
+	total := p.Parsed + p.Failed + p.Skipped
+	if total == 0 {
+		p.SuccessRate = nil
+		return
+	}
+	rate := float64(p.Parsed) / float64(total) * 100
+	p.SuccessRate = &rate
+}
+
+// ParseStatsRecorder accumulates per-source parse counters in memory and
+// periodically adds them to the database, so parse success rates survive restarts
+type ParseStatsRecorder struct {
+	repo      repositories.ParseStatsRepository
+	logger    *pterm.Logger
+	bucket    time.Duration
+	retention time.Duration // Buckets older than this are pruned (0 keeps everything)
+
+	mu        sync.Mutex
+	pending   map[parseStatsKey]*models.ParseStat
+	lastPrune time.Time
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewParseStatsRecorder creates a recorder with the given bucket size and retention
+func NewParseStatsRecorder(repo repositories.ParseStatsRepository, logger *pterm.Logger, bucket, retention time.Duration) *ParseStatsRecorder {
+	if bucket <= 0 {
+		bucket = DefaultParseStatsBucket
+	}
+	return &ParseStatsRecorder{
+		repo:      repo,
+		logger:    logger,
+		bucket:    bucket,
+		retention: retention,
+		pending:   make(map[parseStatsKey]*models.ParseStat),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Bucket returns the granularity of the recorded counters
+func (r *ParseStatsRecorder) Bucket() time.Duration {
+	return r.bucket
+}
+
+// Record adds counters for a source to the bucket containing now
+func (r *ParseStatsRecorder) Record(source string, now time.Time, parsed, failed, skipped, duplicates int64) {
+	if parsed == 0 && failed == 0 && skipped == 0 && duplicates == 0 {
+		return
+	}
+
+	key := parseStatsKey{source: source, bucket: now.UTC().Truncate(r.bucket)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stat, ok := r.pending[key]
+	if !ok {
+		stat = &models.ParseStat{SourceName: source, BucketStart: key.bucket}
+		r.pending[key] = stat
+	}
+	stat.Parsed += parsed
+	stat.Failed += failed
+	stat.Skipped += skipped
+	stat.Duplicates += duplicates
+}
+
+// Start flushes the pending counters every interval until Stop is called
+func (r *ParseStatsRecorder) Start(interval time.Duration) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.Flush()
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the flush loop and writes any remaining counters
+func (r *ParseStatsRecorder) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+	})
+	r.wg.Wait()
+	r.Flush()
+}
+
+// Flush writes the pending counters to the database
+// On failure the counters are kept and retried on the next flush
+func (r *ParseStatsRecorder) Flush() {
+	r.mu.Lock()
+	if len(r.pending) == 0 {
+		r.mu.Unlock()
+		r.prune(time.Now())
+		return
+	}
+	batch := make([]*models.ParseStat, 0, len(r.pending))
+	for _, stat := range r.pending {
+		batch = append(batch, stat)
+	}
+	r.pending = make(map[parseStatsKey]*models.ParseStat)
+	r.mu.Unlock()
+
+	if err := r.repo.AddCounts(batch); err != nil {
+		r.logger.Warn("Failed to persist parse stats, will retry", r.logger.Args("buckets", len(batch), "error", err))
+		r.requeue(batch)
+		return
+	}
+	r.logger.Trace("Persisted parse stats", r.logger.Args("buckets", len(batch)))
+
+	r.prune(time.Now())
+}
+
+// requeue merges unsaved counters back into the pending buckets
+func (r *ParseStatsRecorder) requeue(batch []*models.ParseStat) {
+	for _, stat := range batch {
+		r.Record(stat.SourceName, stat.BucketStart, stat.Parsed, stat.Failed, stat.Skipped, stat.Duplicates)
+	}
+}
+
+// prune removes expired buckets, at most once per hour
+func (r *ParseStatsRecorder) prune(now time.Time) {
+	if r.retention <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	if now.Sub(r.lastPrune) < time.Hour {
+		r.mu.Unlock()
+		return
+	}
+	r.lastPrune = now
+	r.mu.Unlock()
+
+	deleted, err := r.repo.DeleteOlderThan(now.Add(-r.retention))
+	if err != nil {
+		r.logger.Warn("Failed to prune parse stats", r.logger.Args("error", err))
+		return
+	}
+	if deleted > 0 {
+		r.logger.Debug("Pruned old parse stats", r.logger.Args("deleted", deleted))
+	}
+}
+
+// Timeline returns a source's parse counters since the given time, grouped into
+// intervals (rounded up to a multiple of the bucket size). Counters not yet
+// flushed are included.
+func (r *ParseStatsRecorder) Timeline(source string, since time.Time, interval time.Duration) ([]ParseRatePoint, error) {
+	if interval < r.bucket {
+		interval = r.bucket
+	}
+	if rem := interval % r.bucket; rem != 0 {
+		interval += r.bucket - rem
+	}
+	since = since.UTC().Truncate(r.bucket)
+
+	stored, err := r.repo.GetTimeline(source, since)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	for key, stat := range r.pending {
+		if key.source == source && !key.bucket.Before(since) {
+			pending := *stat
+			stored = append(stored, &pending)
+		}
+	}
+	r.mu.Unlock()
+
+	points := make(map[time.Time]*ParseRatePoint)
+	for _, stat := range stored {
+		ts := stat.BucketStart.UTC().Truncate(interval)
+		point, ok := points[ts]
+		if !ok {
+			point = &ParseRatePoint{Timestamp: ts}
+			points[ts] = point
+		}
+		point.Parsed += stat.Parsed
+		point.Failed += stat.Failed
+		point.Skipped += stat.Skipped
+		point.Duplicates += stat.Duplicates
+	}
+
+	timeline := make([]ParseRatePoint, 0, len(points))
+	for _, point := range points {
+		point.setSuccessRate()
+		timeline = append(timeline, *point)
+	}
+	sort.Slice(timeline, func(i, j int) bool {
+		return timeline[i].Timestamp.Before(timeline[j].Timestamp)
+	})
+	return timeline, nil
+}
@@ -0,0 +1,156 @@
+package ingestion
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryParseStatsRepo is an in-memory ParseStatsRepository
+type memoryParseStatsRepo struct {
+	mu      sync.Mutex
+	buckets map[parseStatsKey]*models.ParseStat
+	failAdd bool
+}
+
+func newMemoryParseStatsRepo() *memoryParseStatsRepo {
+	return &memoryParseStatsRepo{buckets: make(map[parseStatsKey]*models.ParseStat)}
+}
+
+func (r *memoryParseStatsRepo) AddCounts(stats []*models.ParseStat) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failAdd {
+		return errors.New("database is locked")
+	}
+	for _, stat := range stats {
+		key := parseStatsKey{source: stat.SourceName, bucket: stat.BucketStart}
+		stored, ok := r.buckets[key]
+		if !ok {
+			copied := *stat
+			r.buckets[key] = &copied
+			continue
+		}
+		stored.Parsed += stat.Parsed
+		stored.Failed += stat.Failed
+		stored.Skipped += stat.Skipped
+		stored.Duplicates += stat.Duplicates
+	}
+	return nil
+}
+
+func (r *memoryParseStatsRepo) GetTimeline(sourceName string, since time.Time) ([]*models.ParseStat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stats []*models.ParseStat
+	for key, stat := range r.buckets {
+		if key.source == sourceName && !key.bucket.Before(since) {
+			copied := *stat
+			stats = append(stats, &copied)
+		}
+	}
+	return stats, nil
+}
+
+func (r *memoryParseStatsRepo) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	for key := range r.buckets {
+		if key.bucket.Before(cutoff) {
+			delete(r.buckets, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestParseStatsRecorderTimeline(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	repo := newMemoryParseStatsRepo()
+	recorder := NewParseStatsRecorder(repo, logger, 5*time.Minute, 0)
+
+	base := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	recorder.Record("traefik", base, 90, 5, 5, 0)
+	recorder.Record("traefik", base.Add(time.Minute), 0, 0, 0, 10)
+	recorder.Record("traefik", base.Add(10*time.Minute), 0, 50, 0, 0)
+	recorder.Record("caddy", base, 100, 0, 0, 0)
+	recorder.Flush()
+
+	// Not flushed yet: still part of the timeline
+	recorder.Record("traefik", base.Add(10*time.Minute), 50, 0, 0, 0)
+
+	timeline, err := recorder.Timeline("traefik", base, 0)
+	assert.NoError(t, err)
+	assert.Len(t, timeline, 2)
+
+	assert.Equal(t, base, timeline[0].Timestamp)
+	assert.Equal(t, int64(90), timeline[0].Parsed)
+	assert.Equal(t, int64(10), timeline[0].Duplicates)
+	assert.InDelta(t, 90.0, *timeline[0].SuccessRate, 0.001)
+
+	assert.Equal(t, base.Add(10*time.Minute), timeline[1].Timestamp)
+	assert.InDelta(t, 50.0, *timeline[1].SuccessRate, 0.001)
+
+	t.Run("groups buckets into the requested interval", func(t *testing.T) {
+		timeline, err := recorder.Timeline("traefik", base, time.Hour)
+		assert.NoError(t, err)
+		assert.Len(t, timeline, 1)
+		assert.Equal(t, int64(140), timeline[0].Parsed)
+		assert.Equal(t, int64(55), timeline[0].Failed)
+		assert.Equal(t, int64(5), timeline[0].Skipped)
+		assert.Equal(t, int64(10), timeline[0].Duplicates)
+		assert.InDelta(t, 70.0, *timeline[0].SuccessRate, 0.001)
+	})
+
+	t.Run("success rate is empty without lines", func(t *testing.T) {
+		recorder.Record("dupes-only", base, 0, 0, 0, 3)
+		timeline, err := recorder.Timeline("dupes-only", base, 0)
+		assert.NoError(t, err)
+		assert.Len(t, timeline, 1)
+		assert.Nil(t, timeline[0].SuccessRate)
+	})
+}
+
+func TestParseStatsRecorderKeepsCountersWhenFlushFails(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	repo := newMemoryParseStatsRepo()
+	recorder := NewParseStatsRecorder(repo, logger, time.Minute, 0)
+	now := time.Now()
+
+	repo.failAdd = true
+	recorder.Record("traefik", now, 10, 1, 0, 0)
+	recorder.Flush()
+	assert.Empty(t, repo.buckets)
+
+	repo.failAdd = false
+	recorder.Record("traefik", now, 5, 0, 0, 0)
+	recorder.Stop()
+
+	stored, err := repo.GetTimeline("traefik", now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, stored, 1)
+	assert.Equal(t, int64(15), stored[0].Parsed)
+	assert.Equal(t, int64(1), stored[0].Failed)
+}
+
+func TestParseStatsRecorderPrunesExpiredBuckets(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	repo := newMemoryParseStatsRepo()
+	recorder := NewParseStatsRecorder(repo, logger, time.Minute, 24*time.Hour)
+	now := time.Now()
+
+	recorder.Record("traefik", now.Add(-48*time.Hour), 10, 0, 0, 0)
+	recorder.Record("traefik", now, 10, 0, 0, 0)
+	recorder.Flush()
+
+	stored, err := repo.GetTimeline("traefik", time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, stored, 1)
+}
@@ -26,7 +26,11 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"loglynx/internal/database/models"
@@ -47,6 +51,15 @@ type SourceProcessor struct {
 	httpRepo         repositories.HTTPRequestRepository
 	sourceRepo       repositories.LogSourceRepository
 	geoIP            *enrichment.GeoIPEnricher
+	reverseDNS       *enrichment.ReverseDNSEnricher // Optional PTR enrichment (nil = disabled)
+	classifier       *enrichment.Classifier         // Optional user-defined labels (nil = disabled)
+	internalNetworks *enrichment.InternalNetworks   // Optional internal range flagging (nil = disabled)
+	trustedProxies   *enrichment.TrustedProxies     // Proxies whose X-Forwarded-For is honoured (nil = keep logged IP)
+	parseStats       *ParseStatsRecorder            // Optional persisted parse counters (nil = disabled)
+	deadLetter       *DeadLetterWriter              // Optional sink for rejected lines (nil = disabled)
+	excludedASNs     map[int]struct{}               // Client ASNs whose requests are dropped (nil = keep all)
+	hashFields       models.HashFields              // Optional fields of the deduplication hash
+	trackFile        bool                           // source.Path is one file of a glob source, tracked in log_source_files
 	metricsCollector *realtime.MetricsCollector
 	logger           *pterm.Logger
 	batchSize        int
@@ -57,10 +70,17 @@ type SourceProcessor struct {
 	cancel           context.CancelFunc
 	wg               sync.WaitGroup
 	// Statistics
-	totalProcessed int64
-	totalErrors    int64
-	startTime      time.Time
-	statsMu        sync.Mutex
+	totalProcessed     int64
+	totalErrors        int64
+	totalParseErrors   int64
+	totalFiltered      int64
+	totalSampled       int64
+	totalBytesRead     int64 // Log bytes read since startTime, for rate and ETA reporting
+	batchInserts       int64
+	batchInsertSeconds float64
+	startTime          time.Time
+	statsMu            sync.Mutex
+	parseErrors        *parseErrorLimiter
 	// First-load tracking
 	isInitialLoad       bool // True if this is the first time reading this file (lastPosition == 0)
 	initialLoadComplete bool // True after reaching EOF on first load
@@ -93,6 +113,17 @@ func NewSourceProcessor(
 		source.LastLineContent,
 		logger,
 	)
+	if source.MultilinePattern != "" {
+		if reader.IsStream() || reader.IsRemote() {
+			logger.Warn("Multiline pattern ignored, streams and remote sources are read line by line",
+				logger.Args("source", source.Name))
+		} else if entryStart, err := regexp.Compile(source.MultilinePattern); err != nil {
+			logger.Warn("Invalid multiline pattern, reading one entry per line",
+				logger.Args("source", source.Name, "pattern", source.MultilinePattern, "error", err))
+		} else {
+			reader.SetMultiline(entryStart)
+		}
+	}
 
 	// Apply defaults if not configured
 	if batchSize <= 0 {
@@ -123,6 +154,8 @@ func NewSourceProcessor(
 		totalProcessed:      0,
 		totalErrors:         0,
 		startTime:           time.Now(),
+		parseErrors:         newParseErrorLimiter(DefaultParseErrorLogInterval),
+		hashFields:          models.DefaultHashFields,
 		isInitialLoad:       isInitialLoad,
 		initialLoadComplete: false,
 		isPaused:            false,
@@ -165,7 +198,7 @@ func (sp *SourceProcessor) ApplyInitialImportLimit(importDays int) error {
 	sp.reader.UpdatePosition(startPos, 0, "")
 
 	// Update source tracking in database
-	if err := sp.sourceRepo.UpdateTracking(sp.source.Name, startPos, 0, ""); err != nil {
+	if err := sp.saveTracking(startPos, 0, ""); err != nil {
 		sp.logger.WithCaller().Error("Failed to update source position",
 			sp.logger.Args("source", sp.source.Name, "error", err))
 		return err
@@ -190,6 +223,7 @@ func (sp *SourceProcessor) Stop() {
 	sp.logger.Debug("Stopping source processor", sp.logger.Args("source", sp.source.Name))
 	sp.cancel()
 	sp.wg.Wait()
+	sp.reader.Close()
 	sp.logger.Info("Stopped source processor", sp.logger.Args("source", sp.source.Name))
 }
 
@@ -258,9 +292,13 @@ func (sp *SourceProcessor) processLoop() {
 				// Update position after final flush
 				sp.updatePosition(lastReadPos, lastReadInode, lastReadLine)
 			}
+			// Treat the open window as elapsed so pending failures are still reported
+			sp.logParseErrorSummary(time.Now().Add(sp.parseErrors.interval))
 			return
 
 		case <-positionUpdateTicker.C:
+			sp.logParseErrorSummary(time.Now())
+
 			// Periodically update position even if batch is not flushed yet
 			// This ensures the progress bar updates smoothly
 			if lastReadPos > 0 && lastReadPos != lastUpdatedPos {
@@ -285,6 +323,7 @@ func (sp *SourceProcessor) processLoop() {
 
 		case <-ticker.C:
 			// Poll for new log lines
+			readFrom := sp.reader.Position()
 			lines, newPos, newInode, newLastLine, err := sp.reader.ReadBatch(sp.batchSize - len(batch))
 			if err != nil {
 				sp.logger.WithCaller().Error("Failed to read from log file",
@@ -292,6 +331,26 @@ func (sp *SourceProcessor) processLoop() {
 				continue
 			}
 
+			if sp.reader.RotationDetected() {
+				// Lines still batched came from the rotated file; flush them before following the new one
+				if len(batch) > 0 {
+					sp.flushBatch(batch)
+					batch = []*models.HTTPRequest{}
+					flushTimer.Reset(sp.batchTimeout)
+				}
+
+				// Persist the new inode and reset position right away, even if the new file is still empty,
+				// so a restart (or a timeout flush) does not resume at an offset from the old file
+				lastReadPos = 0
+				lastReadInode = newInode
+				lastReadLine = ""
+				readFrom = 0
+				sp.updatePosition(lastReadPos, lastReadInode, lastReadLine)
+				lastUpdatedPos = lastReadPos
+				sp.logger.Info("Following rotated log file",
+					sp.logger.Args("source", sp.source.Name, "inode", newInode))
+			}
+
 			if len(lines) == 0 {
 				// No new lines - reached EOF
 				// BUGFIX: Check if we need to mark initial load as complete AND flush pending batch
@@ -329,13 +388,19 @@ func (sp *SourceProcessor) processLoop() {
 				sp.logger.Args("source", sp.source.Name, "count", len(lines)))
 
 			// Parse lines in parallel
-			parsedRequests := sp.parseAndEnrichParallel(lines)
+			parsedRequests := sp.parseAndEnrichParallel(lines, sp.reader.LineOffsets())
 			batch = append(batch, parsedRequests...)
 
 			lastReadPos = newPos
 			lastReadInode = newInode
 			lastReadLine = newLastLine
 
+			if newPos > readFrom {
+				sp.statsMu.Lock()
+				sp.totalBytesRead += newPos - readFrom
+				sp.statsMu.Unlock()
+			}
+
 			// Update reader position immediately to prevent re-reading the same lines
 			sp.reader.UpdatePosition(newPos, newInode, newLastLine)
 
@@ -357,9 +422,21 @@ func (sp *SourceProcessor) processLoop() {
 	}
 }
 
+// saveTracking persists the read position, per file for glob sources.
+// Streams have no position to resume from, so nothing is saved for them.
+func (sp *SourceProcessor) saveTracking(position int64, inode int64, lastLine string) error {
+	if sp.reader.IsStream() {
+		return nil
+	}
+	if sp.trackFile {
+		return sp.sourceRepo.UpdateFileTracking(sp.source.Name, sp.source.Path, position, inode, lastLine)
+	}
+	return sp.sourceRepo.UpdateTracking(sp.source.Name, position, inode, lastLine)
+}
+
 // updatePosition updates the file position in the database after a successful flush
 func (sp *SourceProcessor) updatePosition(position int64, inode int64, lastLine string) {
-	if err := sp.sourceRepo.UpdateTracking(sp.source.Name, position, inode, lastLine); err != nil {
+	if err := sp.saveTracking(position, inode, lastLine); err != nil {
 		sp.logger.WithCaller().Error("Failed to update source tracking",
 			sp.logger.Args("source", sp.source.Name, "error", err))
 	} else {
@@ -369,10 +446,66 @@ func (sp *SourceProcessor) updatePosition(position int64, inode int64, lastLine
 	}
 }
 
+// ProcessorMetrics is a snapshot of a source processor's counters
+type ProcessorMetrics struct {
+	Source             string
+	Processed          int64     // Requests inserted into the database
+	InsertErrors       int64     // Requests lost to failed batch inserts
+	ParseErrors        int64     // Lines the parser rejected
+	Filtered           int64     // Requests dropped by the ASN exclude list
+	Sampled            int64     // Requests left out by the source's sampling
+	BytesRead          int64     // Log bytes read since StartedAt (0 for stdin and named pipes)
+	BatchInserts       int64     // Successful batch inserts
+	BatchInsertSeconds float64   // Total time spent in successful batch inserts
+	StartedAt          time.Time // When the processor was created
+}
+
+// GetMetrics returns a snapshot of the processor's counters
+func (sp *SourceProcessor) GetMetrics() ProcessorMetrics {
+	sp.statsMu.Lock()
+	defer sp.statsMu.Unlock()
+
+	return ProcessorMetrics{
+		Source:             sp.source.Name,
+		Processed:          sp.totalProcessed,
+		InsertErrors:       sp.totalErrors,
+		ParseErrors:        sp.totalParseErrors,
+		Filtered:           sp.totalFiltered,
+		Sampled:            sp.totalSampled,
+		BytesRead:          sp.totalBytesRead,
+		BatchInserts:       sp.batchInserts,
+		BatchInsertSeconds: sp.batchInsertSeconds,
+		StartedAt:          sp.startTime,
+	}
+}
+
+// logParseErrorSummary reports parse failures that were not logged individually
+func (sp *SourceProcessor) logParseErrorSummary(now time.Time) {
+	if suppressed, ok := sp.parseErrors.summary(now); ok {
+		sp.logger.Warn(fmt.Sprintf("%d more lines failed parsing in the last %s", suppressed, sp.parseErrors.interval),
+			sp.logger.Args("source", sp.source.Name, "parser", sp.parser.Name()))
+	}
+}
+
+// parseCounts counts the lines of a batch that did not become requests
+type parseCounts struct {
+	skipped  int64 // Lines the parser cannot handle
+	failed   int64 // Lines the parser rejected
+	filtered int64 // Requests dropped by the ASN exclude list
+	sampled  int64 // Requests left out by the source's sampling
+}
+
 // parseAndEnrichParallel processes lines in parallel using worker pool
-func (sp *SourceProcessor) parseAndEnrichParallel(lines []string) []*models.HTTPRequest {
+func (sp *SourceProcessor) parseAndEnrichParallel(lines []string, offsets []int64) []*models.HTTPRequest {
+	requests, _ := sp.parseAndEnrich(lines, offsets)
+	return requests
+}
+
+// parseAndEnrich parses and enriches lines in parallel, also reporting the lines that were dropped.
+// offsets, when given, holds where each line was read and is only used by sources with dedup disabled.
+func (sp *SourceProcessor) parseAndEnrich(lines []string, offsets []int64) ([]*models.HTTPRequest, parseCounts) {
 	if len(lines) == 0 {
-		return nil
+		return nil, parseCounts{}
 	}
 
 	// Use configured worker pool size (from WORKER_POOL_SIZE env var)
@@ -382,32 +515,61 @@ func (sp *SourceProcessor) parseAndEnrichParallel(lines []string) []*models.HTTP
 	}
 
 	// Channels for work distribution
-	jobs := make(chan string, len(lines))
+	jobs := make(chan int, len(lines))
 	results := make(chan *models.HTTPRequest, len(lines))
 
 	// Start workers
 	var wg sync.WaitGroup
+	var skipped, failed, filtered, sampled int64
 	for w := 0; w < numWorkers; w++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for line := range jobs {
+			for i := range jobs {
+				line := lines[i]
 				// Skip lines that this parser cannot handle
 				if !sp.parser.CanParse(line) {
+					atomic.AddInt64(&skipped, 1)
 					sp.logger.Trace("Skipping line not supported by parser",
 						sp.logger.Args("source", sp.source.Name, "parser", sp.parser.Name()))
+					if sp.deadLetter != nil && strings.TrimSpace(line) != "" {
+						sp.deadLetter.Write(sp.source.Name, sp.parser.Name(), line,
+							fmt.Errorf("line not supported by %s parser", sp.parser.Name()))
+					}
 					continue
 				}
 
 				event, err := sp.parser.Parse(line)
 				if err != nil {
-					sp.logger.Warn("Failed to parse log line",
-						sp.logger.Args("source", sp.source.Name, "error", err, "line_preview", truncate(line, 100)))
+					atomic.AddInt64(&failed, 1)
+					sp.statsMu.Lock()
+					sp.totalParseErrors++
+					sp.statsMu.Unlock()
+
+					if sp.parseErrors.allow(time.Now()) {
+						sp.logger.Warn("Failed to parse log line",
+							sp.logger.Args("source", sp.source.Name, "error", err, "line_preview", truncate(line, 100)))
+					}
+					if sp.deadLetter != nil {
+						sp.deadLetter.Write(sp.source.Name, sp.parser.Name(), line, err)
+					}
 					continue
 				}
 
 				// Convert to database model
 				dbRequest := sp.convertToDBModel(event)
+				if sp.source.DisableDedup && i < len(offsets) {
+					sp.keepRepeats(dbRequest, offsets[i])
+				}
+				if !sp.sample(dbRequest) {
+					atomic.AddInt64(&sampled, 1)
+					continue
+				}
+
+				// Resolve the real client behind trusted proxies before any IP-based enrichment
+				if fwd, ok := event.(forwardedForEvent); ok && fwd.GetForwardedFor() != "" {
+					enrichment.ApplyForwardedFor(dbRequest, fwd.GetForwardedFor(), sp.trustedProxies)
+				}
 
 				// Enrich with GeoIP data
 				if sp.geoIP != nil {
@@ -417,6 +579,27 @@ func (sp *SourceProcessor) parseAndEnrichParallel(lines []string) []*models.HTTP
 					}
 				}
 
+				// Drop excluded networks before the costlier enrichment steps
+				if dbRequest.ASN != 0 && sp.excludedASNs != nil {
+					if _, excluded := sp.excludedASNs[dbRequest.ASN]; excluded {
+						atomic.AddInt64(&filtered, 1)
+						continue
+					}
+				}
+
+				// Flag clients inside the configured internal ranges
+				if sp.internalNetworks != nil {
+					sp.internalNetworks.Enrich(dbRequest)
+				}
+
+				// Resolve the client hostname (PTR record)
+				if sp.reverseDNS != nil {
+					if err := sp.reverseDNS.Enrich(dbRequest); err != nil {
+						sp.logger.Debug("Reverse DNS enrichment failed",
+							sp.logger.Args("ip", dbRequest.ClientIP, "error", err))
+					}
+				}
+
 				// Parse User-Agent string
 				if dbRequest.UserAgent != "" {
 					uaInfo := useragent.Parse(dbRequest.UserAgent)
@@ -427,14 +610,19 @@ func (sp *SourceProcessor) parseAndEnrichParallel(lines []string) []*models.HTTP
 					dbRequest.DeviceType = uaInfo.DeviceType
 				}
 
+				// Apply user-defined classification rules
+				if sp.classifier != nil {
+					sp.classifier.Classify(dbRequest)
+				}
+
 				results <- dbRequest
 			}
 		}()
 	}
 
 	// Send jobs
-	for _, line := range lines {
-		jobs <- line
+	for i := range lines {
+		jobs <- i
 	}
 	close(jobs)
 
@@ -450,7 +638,40 @@ func (sp *SourceProcessor) parseAndEnrichParallel(lines []string) []*models.HTTP
 		parsedRequests = append(parsedRequests, req)
 	}
 
-	return parsedRequests
+	if sp.parseStats != nil {
+		sp.parseStats.Record(sp.source.Name, time.Now(), int64(len(parsedRequests)), failed, skipped, 0)
+	}
+	if filtered > 0 {
+		sp.statsMu.Lock()
+		sp.totalFiltered += filtered
+		sp.statsMu.Unlock()
+		sp.logger.Trace("Dropped requests from excluded ASNs",
+			sp.logger.Args("source", sp.source.Name, "count", filtered))
+	}
+	if sampled > 0 {
+		sp.statsMu.Lock()
+		sp.totalSampled += sampled
+		sp.statsMu.Unlock()
+	}
+
+	return parsedRequests, parseCounts{skipped: skipped, failed: failed, filtered: filtered, sampled: sampled}
+}
+
+// sample reports whether a request is stored and sets the number of requests it stands for.
+// Sources with a sample rate of N keep 1 in N requests, chosen by request hash so that lines
+// read again are kept or left out alike; errors can be kept in full with SampleKeepErrors.
+func (sp *SourceProcessor) sample(request *models.HTTPRequest) bool {
+	request.SampleWeight = 1
+	rate := sp.source.SampleRate
+	if rate <= 1 || (sp.source.SampleKeepErrors && request.StatusCode >= 400) {
+		return true
+	}
+	bucket, err := strconv.ParseUint(request.RequestHash[:8], 16, 32)
+	if err != nil {
+		return true
+	}
+	request.SampleWeight = rate
+	return bucket%uint64(rate) == 0
 }
 
 // flushBatch inserts the batch into the database
@@ -461,7 +682,8 @@ func (sp *SourceProcessor) flushBatch(batch []*models.HTTPRequest) {
 
 	startTime := time.Now()
 
-	if err := sp.httpRepo.CreateBatch(batch); err != nil {
+	inserted, err := sp.httpRepo.CreateBatch(batch)
+	if err != nil {
 		sp.logger.WithCaller().Error("Failed to insert batch into database",
 			sp.logger.Args(
 				"source", sp.source.Name,
@@ -474,6 +696,11 @@ func (sp *SourceProcessor) flushBatch(batch []*models.HTTPRequest) {
 		sp.statsMu.Unlock()
 		return
 	}
+	duration := time.Since(startTime)
+
+	if sp.parseStats != nil {
+		sp.parseStats.Record(sp.source.Name, time.Now(), 0, 0, 0, int64(len(batch)-inserted))
+	}
 
 	// Send to real-time metrics collector (now that we have IDs)
 	if sp.metricsCollector != nil {
@@ -485,10 +712,11 @@ func (sp *SourceProcessor) flushBatch(batch []*models.HTTPRequest) {
 	// Update stats
 	sp.statsMu.Lock()
 	sp.totalProcessed += int64(len(batch))
+	sp.batchInserts++
+	sp.batchInsertSeconds += duration.Seconds()
 	totalProcessed := sp.totalProcessed
 	sp.statsMu.Unlock()
 
-	duration := time.Since(startTime)
 	elapsed := time.Since(sp.startTime)
 	rate := float64(totalProcessed) / elapsed.Seconds()
 
@@ -508,7 +736,7 @@ func (sp *SourceProcessor) flushBatch(batch []*models.HTTPRequest) {
 func (sp *SourceProcessor) convertToDBModel(event interface{}) *models.HTTPRequest {
 	dbModel := &models.HTTPRequest{
 		SourceName: sp.source.Name,
-		Timestamp:  time.Now(),
+		Timestamp:  time.Now().UTC(),
 	}
 
 	// Use reflection to map fields from event to dbModel
@@ -545,27 +773,23 @@ func (sp *SourceProcessor) convertToDBModel(event interface{}) *models.HTTPReque
 		}
 	}
 
+	// Stored timestamps are always UTC, whatever zone the log line was written in;
+	// events without one keep the time they were read
+	if dbModel.Timestamp.IsZero() {
+		dbModel.Timestamp = time.Now()
+	}
+	dbModel.Timestamp = dbModel.Timestamp.UTC()
+
 	// Generate hash for deduplication
-	// Hash is based on: timestamp + client IP + method + host + path + query string + status code + duration + startUTC + requestsTotal
-	// Duration and StartUTC provide nanosecond precision for better deduplication accuracy
-	// RequestsTotal provides additional context for distinguishing requests at router level
+	// Hash is based on: timestamp + client IP + method + host + path + query string + status code,
+	// plus the source's optional fields (by default duration + startUTC + requestsTotal)
 	// This uniquely identifies a request while allowing for legitimate duplicates
 	// (e.g., same endpoint hit multiple times in same second from different IPs)
-	// If Duration or StartUTC are not available (CLF logs), they will be empty/zero and hash will use other fields
-	hashInput := fmt.Sprintf("%d|%s|%s|%s|%s|%s|%d|%d|%s|%d",
-		dbModel.Timestamp.Unix(),
-		dbModel.ClientIP,
-		dbModel.Method,
-		dbModel.Host,
-		dbModel.Path,
-		dbModel.QueryString,
-		dbModel.StatusCode,
-		dbModel.Duration,      // Nanosecond precision duration
-		dbModel.StartUTC,      // Nanosecond precision start time
-		dbModel.RequestsTotal, // Total requests at router level
-	)
-	hash := sha256.Sum256([]byte(hashInput))
-	dbModel.RequestHash = fmt.Sprintf("%x", hash)
+	dbModel.RequestHash = models.ComputeRequestHash(dbModel, sp.hashFields)
+
+	// Normalized after hashing so re-read lines keep the hash they were first stored with
+	dbModel.Method = models.NormalizeMethod(dbModel.Method)
+	dbModel.ClientIP = models.NormalizeIP(dbModel.ClientIP)
 
 	sp.logger.Trace("Converted event to DB model",
 		sp.logger.Args("source", sp.source.Name, "timestamp", dbModel.Timestamp, "hash", dbModel.RequestHash[:16]))
@@ -573,6 +797,18 @@ func (sp *SourceProcessor) convertToDBModel(event interface{}) *models.HTTPReque
 	return dbModel
 }
 
+// keepRepeats folds where the line was read into the request hash, so identical requests
+// on different lines are all stored while re-reading the same line still collapses
+func (sp *SourceProcessor) keepRepeats(request *models.HTTPRequest, offset int64) {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d", request.RequestHash, sp.source.Path, offset)))
+	request.RequestHash = fmt.Sprintf("%x", hash)
+}
+
+// forwardedForEvent is implemented by events that carry an X-Forwarded-For chain
+type forwardedForEvent interface {
+	GetForwardedFor() string
+}
+
 // truncate truncates a string to maxLen characters for logging
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
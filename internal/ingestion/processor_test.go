@@ -0,0 +1,333 @@
+package ingestion
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+	"loglynx/internal/database/repositories"
+	parsers "loglynx/internal/parser"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHTTPRepo records inserted batches; only CreateBatch is implemented
+type fakeHTTPRepo struct {
+	repositories.HTTPRequestRepository
+	err     error
+	batches [][]*models.HTTPRequest
+}
+
+func (f *fakeHTTPRepo) CreateBatch(requests []*models.HTTPRequest) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.batches = append(f.batches, requests)
+	return len(requests), nil
+}
+
+// asnEvent is a parsed line whose ASN is copied onto the request by convertToDBModel
+type asnEvent struct {
+	Timestamp time.Time
+	ClientIP  string
+	ASN       int
+}
+
+func (e *asnEvent) GetTimestamp() time.Time { return e.Timestamp }
+func (e *asnEvent) GetSourceName() string   { return "test-source" }
+
+// asnParser parses "<client ip> <asn>" lines
+type asnParser struct{}
+
+func (asnParser) Name() string              { return "asn-test" }
+func (asnParser) CanParse(line string) bool { return strings.Count(line, " ") == 1 }
+func (asnParser) Parse(line string) (parsers.Event, error) {
+	ip, asnField, _ := strings.Cut(line, " ")
+	asn, err := strconv.Atoi(asnField)
+	if err != nil {
+		return nil, err
+	}
+	return &asnEvent{Timestamp: time.Now(), ClientIP: ip, ASN: asn}, nil
+}
+
+func (f *fakeHTTPRepo) HasExistingData() bool { return false }
+
+func newTestProcessor(repo repositories.HTTPRequestRepository) *SourceProcessor {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	source := &models.LogSource{Name: "test-source", Path: "/dev/null", ParserType: "traefik"}
+	return NewSourceProcessor(source, nil, repo, nil, nil, nil, logger, 0, 0, true)
+}
+
+func TestGetMetricsCountsBatchInserts(t *testing.T) {
+	repo := &fakeHTTPRepo{}
+	sp := newTestProcessor(repo)
+
+	sp.flushBatch([]*models.HTTPRequest{{ClientIP: "1.1.1.1"}, {ClientIP: "2.2.2.2"}})
+	sp.flushBatch([]*models.HTTPRequest{{ClientIP: "3.3.3.3"}})
+
+	repo.err = errors.New("disk full")
+	sp.flushBatch([]*models.HTTPRequest{{ClientIP: "4.4.4.4"}, {ClientIP: "5.5.5.5"}})
+
+	metrics := sp.GetMetrics()
+	assert.Equal(t, "test-source", metrics.Source)
+	assert.Equal(t, int64(3), metrics.Processed)
+	assert.Equal(t, int64(2), metrics.InsertErrors, "requests of the failed batch are lost")
+	assert.Equal(t, int64(2), metrics.BatchInserts, "only successful inserts are timed")
+	assert.GreaterOrEqual(t, metrics.BatchInsertSeconds, 0.0)
+	assert.Zero(t, metrics.ParseErrors)
+	assert.Zero(t, metrics.Filtered)
+}
+
+func TestParseAndEnrichDropsExcludedASNs(t *testing.T) {
+	sp := newTestProcessor(&fakeHTTPRepo{})
+	sp.parser = asnParser{}
+
+	coordinator := &Coordinator{}
+	coordinator.SetExcludedASNs([]int{64500, 64501})
+	sp.excludedASNs = coordinator.excludedASNs
+
+	requests := sp.parseAndEnrichParallel([]string{
+		"1.1.1.1 64500",
+		"2.2.2.2 13335",
+		"3.3.3.3 64501",
+		"4.4.4.4 0", // Unknown ASN is always kept
+	}, nil)
+
+	kept := make([]string, 0, len(requests))
+	for _, req := range requests {
+		kept = append(kept, req.ClientIP)
+	}
+	assert.ElementsMatch(t, []string{"2.2.2.2", "4.4.4.4"}, kept)
+	assert.Equal(t, int64(2), sp.GetMetrics().Filtered)
+
+	// Without an exclude list every request passes
+	coordinator.SetExcludedASNs(nil)
+	sp.excludedASNs = coordinator.excludedASNs
+	assert.Len(t, sp.parseAndEnrichParallel([]string{"1.1.1.1 64500", "2.2.2.2 13335"}, nil), 2)
+	assert.Equal(t, int64(2), sp.GetMetrics().Filtered)
+}
+
+func TestCoordinatorAppliesIngestionTuning(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	path := filepath.Join(t.TempDir(), "access.log")
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+
+	tests := []struct {
+		name         string
+		batchSize    int
+		workers      int
+		batchTimeout time.Duration
+		wantSize     int
+		wantWorkers  int
+		wantTimeout  time.Duration
+	}{
+		{"configured", 250, 3, 2 * time.Second, 250, 3, 2 * time.Second},
+		{"processor defaults", 0, 0, 0, 1000, 4, 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCoordinator(nil, &fakeHTTPRepo{}, parsers.NewRegistry(logger), nil, nil, logger, 0, false, tt.batchSize, tt.workers)
+			c.SetBatchTimeout(tt.batchTimeout)
+
+			source := &models.LogSource{Name: "test-source", Path: path, ParserType: "traefik"}
+			require.NoError(t, c.startFileProcessorLocked(source.Name, source, false))
+			sp := c.processors[source.Name]
+			defer sp.Stop()
+
+			assert.Equal(t, tt.wantSize, sp.batchSize)
+			assert.Equal(t, tt.wantWorkers, sp.workerPoolSize)
+			assert.Equal(t, tt.wantTimeout, sp.batchTimeout)
+		})
+	}
+}
+
+func TestConvertToDBModelStoresUTC(t *testing.T) {
+	sp := newTestProcessor(&fakeHTTPRepo{})
+
+	// A Traefik-style RFC3339 timestamp with an offset keeps its instant, in UTC
+	local := time.Date(2025, 3, 30, 3, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	request := sp.convertToDBModel(&asnEvent{Timestamp: local, ClientIP: "1.1.1.1"})
+	assert.Equal(t, time.UTC, request.Timestamp.Location())
+	assert.True(t, request.Timestamp.Equal(local))
+
+	// Events without a timestamp are stamped with the read time, in UTC too
+	before := time.Now()
+	request = sp.convertToDBModel(&asnEvent{ClientIP: "1.1.1.1"})
+	assert.Equal(t, time.UTC, request.Timestamp.Location())
+	assert.False(t, request.Timestamp.Before(before.Truncate(time.Second)))
+}
+
+func TestConvertToDBModelNormalizesMethod(t *testing.T) {
+	sp := newTestProcessor(&fakeHTTPRepo{})
+
+	for raw, want := range map[string]string{"get": "GET", " Propfind ": "PROPFIND", "GARBAGE": "OTHER", "\x16\x03\x01": "OTHER", "": ""} {
+		request := sp.convertToDBModel(&struct {
+			Timestamp time.Time
+			ClientIP  string
+			Method    string
+		}{time.Now(), "1.1.1.1", raw})
+		assert.Equal(t, want, request.Method, "method %q", raw)
+	}
+
+	// The hash still covers the raw method, as before normalization
+	lower := sp.convertToDBModel(&struct{ Method string }{"get"})
+	upper := sp.convertToDBModel(&struct{ Method string }{"GET"})
+	assert.NotEqual(t, lower.RequestHash, upper.RequestHash)
+}
+
+func TestConvertToDBModelNormalizesClientIP(t *testing.T) {
+	sp := newTestProcessor(&fakeHTTPRepo{})
+
+	for raw, want := range map[string]string{
+		"0:0:0:0:0:0:0:1":          "::1",
+		"::1":                      "::1",
+		"2001:DB8:0000::0001":      "2001:db8::1",
+		"::ffff:203.0.113.7":       "203.0.113.7",
+		"0:0:0:0:0:FFFF:cb00:7107": "203.0.113.7",
+		"203.0.113.7":              "203.0.113.7",
+		"fe80::1%eth0":             "fe80::1%eth0", // Zones are not parsed, kept as logged
+		"unknown":                  "unknown",
+		"":                         "",
+	} {
+		request := sp.convertToDBModel(&asnEvent{Timestamp: time.Now(), ClientIP: raw})
+		assert.Equal(t, want, request.ClientIP, "client IP %q", raw)
+	}
+
+	// The hash still covers the IP as logged, as before normalization
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	compressed := sp.convertToDBModel(&asnEvent{Timestamp: at, ClientIP: "::1"})
+	expanded := sp.convertToDBModel(&asnEvent{Timestamp: at, ClientIP: "0:0:0:0:0:0:0:1"})
+	assert.Equal(t, compressed.ClientIP, expanded.ClientIP)
+	assert.NotEqual(t, compressed.RequestHash, expanded.RequestHash)
+}
+
+func TestConvertToDBModelHashFields(t *testing.T) {
+	sp := newTestProcessor(&fakeHTTPRepo{})
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	type event struct {
+		Timestamp time.Time
+		ClientIP  string
+		RequestID string
+	}
+
+	// By default the request ID and sub-second time are not hashed
+	first := sp.convertToDBModel(&event{at, "1.1.1.1", "req-1"})
+	second := sp.convertToDBModel(&event{at.Add(time.Millisecond), "1.1.1.1", "req-2"})
+	assert.Equal(t, first.RequestHash, second.RequestHash)
+
+	fields, err := models.DefaultHashFields.With("request_id")
+	require.NoError(t, err)
+	sp.hashFields = fields
+	first = sp.convertToDBModel(&event{at, "1.1.1.1", "req-1"})
+	second = sp.convertToDBModel(&event{at, "1.1.1.1", "req-2"})
+	assert.NotEqual(t, first.RequestHash, second.RequestHash)
+
+	fields, err = models.DefaultHashFields.With("timestamp_ns, -request_id")
+	require.NoError(t, err)
+	sp.hashFields = fields
+	first = sp.convertToDBModel(&event{at, "1.1.1.1", "req-1"})
+	second = sp.convertToDBModel(&event{at.Add(time.Millisecond), "1.1.1.1", "req-1"})
+	assert.NotEqual(t, first.RequestHash, second.RequestHash)
+
+	_, err = models.DefaultHashFields.With("user_agent")
+	assert.Error(t, err)
+}
+
+func TestSampleKeepsOneInNAndErrors(t *testing.T) {
+	sp := newTestProcessor(&fakeHTTPRepo{})
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	type event struct {
+		Timestamp  time.Time
+		ClientIP   string
+		StatusCode int
+	}
+	request := func(i, status int) *models.HTTPRequest {
+		return sp.convertToDBModel(&event{at, fmt.Sprintf("10.0.%d.%d", i/256, i%256), status})
+	}
+
+	// Without a sample rate every request is kept on its own
+	req := request(1, 200)
+	assert.True(t, sp.sample(req))
+	assert.Equal(t, 1, req.SampleWeight)
+
+	sp.source.SampleRate = 10
+	sp.source.SampleKeepErrors = true
+	kept := 0
+	for i := 0; i < 2000; i++ {
+		req := request(i, 200)
+		if sp.sample(req) {
+			kept++
+			assert.Equal(t, 10, req.SampleWeight)
+		}
+		assert.Equal(t, sp.sample(req), sp.sample(request(i, 200)), "the same request is always kept or always left out")
+	}
+	assert.InDelta(t, 200, kept, 60, "about 1 in 10 requests is kept")
+
+	for i := 0; i < 50; i++ {
+		req := request(i, 502)
+		assert.True(t, sp.sample(req), "errors are all kept")
+		assert.Equal(t, 1, req.SampleWeight)
+	}
+
+	sp.source.SampleKeepErrors = false
+	errorsKept := 0
+	for i := 0; i < 500; i++ {
+		if sp.sample(request(i, 502)) {
+			errorsKept++
+		}
+	}
+	assert.Less(t, errorsKept, 500, "errors are sampled too without sample_keep_errors")
+}
+
+// fixedTimeParser parses "<client ip> <asn>" lines stamped with the same instant, so
+// identical lines yield identical requests
+type fixedTimeParser struct{ asnParser }
+
+func (fixedTimeParser) Parse(line string) (parsers.Event, error) {
+	ip, _, _ := strings.Cut(line, " ")
+	return &asnEvent{Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ClientIP: ip}, nil
+}
+
+func TestDisableDedupKeepsRepeatedLines(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	path := filepath.Join(t.TempDir(), "access.log")
+	require.NoError(t, os.WriteFile(path, []byte("1.1.1.1 0\n1.1.1.1 0\n1.1.1.1 0\n"), 0o644))
+
+	read := func() ([]string, []int64) {
+		reader := NewIncrementalReader(path, 0, 0, "", logger)
+		lines, _, _, _, err := reader.ReadBatch(100)
+		require.NoError(t, err)
+		require.Len(t, lines, 3)
+		assert.Equal(t, []int64{0, 10, 20}, reader.LineOffsets())
+		return lines, reader.LineOffsets()
+	}
+	hashes := func(requests []*models.HTTPRequest) map[string]bool {
+		unique := map[string]bool{}
+		for _, req := range requests {
+			unique[req.RequestHash] = true
+		}
+		return unique
+	}
+
+	sp := newTestProcessor(&fakeHTTPRepo{})
+	sp.parser = fixedTimeParser{}
+	lines, offsets := read()
+	assert.Len(t, hashes(sp.parseAndEnrichParallel(lines, offsets)), 1, "repeats collapse by default")
+
+	sp.source.DisableDedup = true
+	first := hashes(sp.parseAndEnrichParallel(lines, offsets))
+	assert.Len(t, first, 3, "every line is kept without dedup")
+
+	// Reading the same lines again yields the same hashes, so a re-read stays idempotent
+	lines, offsets = read()
+	assert.Equal(t, first, hashes(sp.parseAndEnrichParallel(lines, offsets)))
+}
@@ -26,38 +26,169 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
+	"loglynx/internal/database/models"
 	parsers "loglynx/internal/parser"
 
 	"github.com/pterm/pterm"
 )
 
+// DefaultMaxLineLength is the longest log line, in bytes including the newline,
+// that is read; longer lines are skipped so a corrupt file cannot exhaust memory
+const DefaultMaxLineLength = 1024 * 1024
+
+// DefaultEntryFlushDelay is how long the trailing multiline entry must stay unchanged
+// before it is read as complete
+const DefaultEntryFlushDelay = 5 * time.Second
+
 // IncrementalReader reads log files incrementally, tracking position
-// and detecting log rotation
+// and detecting log rotation. Standard input and named pipes are read
+// as a stream instead, without position tracking. http(s) URLs are
+// polled with range requests from the tracked position.
 type IncrementalReader struct {
 	filePath        string
 	lastPosition    int64
 	lastInode       int64 // File identifier (inode on Unix, file index on Windows)
 	lastLineContent string
+	partialLine     string        // Trailing line seen without newline, still being written
+	partialPosition int64         // Offset of partialLine
+	partialSince    time.Time     // When partialLine was first seen unchanged at partialPosition
+	partialDelay    time.Duration // Read partialLine as complete once unchanged this long (0 = never)
+	flushDelay      time.Duration // Read the trailing multiline entry once unchanged this long
+	maxLineLength   int
+	rotated         bool        // Set when ReadBatch detected a rotation, cleared by RotationDetected
+	lineOffsets     []int64     // Start of each line returned by the last ReadBatch
+	streamLines     int64       // Lines read so far from a stream
+	stream          *lineStream // Non-nil for stdin and named pipes
+	remote          *remoteLog  // Non-nil for http:// and https:// URLs
 	logger          *pterm.Logger
+
+	// Multiline mode: lines not matching entryStart are appended to the previous entry
+	entryStart   *regexp.Regexp
+	pendingStart int64 // Offsets of the trailing entry seen at end of file, possibly still being written
+	pendingEnd   int64
+	pendingSince time.Time // When the trailing entry was first seen unchanged
+}
+
+// multilineEntry accumulates the lines of one multiline entry
+type multilineEntry struct {
+	lines   []string // Dropped once the entry is too long, so a runaway entry cannot exhaust memory
+	count   int
+	start   int64 // Offset of the first line
+	end     int64 // Offset right after the last line
+	size    int
+	tooLong bool
 }
 
 // NewIncrementalReader creates a new incremental reader
 func NewIncrementalReader(filePath string, lastPos int64, lastInode int64, lastLine string, logger *pterm.Logger) *IncrementalReader {
-	return &IncrementalReader{
+	if IsStreamPath(filePath) {
+		return &IncrementalReader{
+			filePath:      filePath,
+			stream:        newLineStream(filePath, logger),
+			maxLineLength: DefaultMaxLineLength,
+			logger:        logger,
+		}
+	}
+	reader := &IncrementalReader{
 		filePath:        filePath,
 		lastPosition:    lastPos,
 		lastInode:       lastInode,
 		lastLineContent: lastLine,
+		flushDelay:      DefaultEntryFlushDelay,
+		maxLineLength:   DefaultMaxLineLength,
 		logger:          logger,
 	}
+	if models.IsRemotePath(filePath) {
+		reader.remote = newRemoteLog(filePath, RemoteConfig{})
+	}
+	return reader
+}
+
+// SetRemoteConfig sets the headers, poll interval and timeout of a remote source;
+// it is a no-op for files and streams. Must be called before the first ReadBatch.
+func (r *IncrementalReader) SetRemoteConfig(cfg RemoteConfig) {
+	if r.remote != nil {
+		r.remote = newRemoteLog(r.filePath, cfg)
+	}
+}
+
+// SetMaxLineLength sets the longest line read, in bytes (0 keeps the default).
+// Must be called before the first ReadBatch.
+func (r *IncrementalReader) SetMaxLineLength(maxLen int) {
+	if maxLen <= 0 {
+		maxLen = DefaultMaxLineLength
+	}
+	r.maxLineLength = maxLen
+	if r.stream != nil {
+		r.stream.maxLineLength = maxLen
+	}
+}
+
+// SetPartialLineFlushDelay sets how long a trailing line without newline must stay
+// unchanged before it is read as a complete line. 0 (the default) waits for the
+// newline forever; a positive delay loses the line if the writer completes it later.
+func (r *IncrementalReader) SetPartialLineFlushDelay(delay time.Duration) {
+	r.partialDelay = max(delay, 0)
+}
+
+// SetMultiline enables multiline mode: a line matching entryStart begins a new entry and the
+// lines that follow without matching it (stack traces, pretty-printed JSON) are joined to it
+// with newlines. Entries longer than the maximum line length are skipped. A nil pattern reads
+// one entry per line. Streams are always read line by line.
+func (r *IncrementalReader) SetMultiline(entryStart *regexp.Regexp) {
+	r.entryStart = entryStart
+}
+
+// Position returns the offset the next ReadBatch starts from
+func (r *IncrementalReader) Position() int64 {
+	return r.lastPosition
+}
+
+// LineOffsets returns where each line returned by the last ReadBatch starts: its byte
+// offset in the file, or its sequence number for a stream
+func (r *IncrementalReader) LineOffsets() []int64 {
+	return r.lineOffsets
+}
+
+// IsStream reports whether the reader follows stdin or a named pipe
+func (r *IncrementalReader) IsStream() bool {
+	return r.stream != nil
+}
+
+// IsRemote reports whether the reader polls an http:// or https:// URL
+func (r *IncrementalReader) IsRemote() bool {
+	return r.remote != nil
+}
+
+// Close stops the background reader of a stream; it is a no-op for files
+func (r *IncrementalReader) Close() {
+	if r.stream != nil {
+		r.stream.close()
+	}
 }
 
 // ReadBatch reads up to maxLines new lines from the file
 // Returns: lines read, new position, new inode, last line content (for continuity check), error
 func (r *IncrementalReader) ReadBatch(maxLines int) ([]string, int64, int64, string, error) {
+	if r.stream != nil {
+		// Streams cannot seek or rotate: position, inode and last line stay zero
+		lines := r.stream.read(maxLines)
+		r.lineOffsets = make([]int64, len(lines))
+		for i := range lines {
+			r.lineOffsets[i] = r.streamLines
+			r.streamLines++
+		}
+		return lines, 0, 0, "", nil
+	}
+	if r.remote != nil {
+		return r.readRemote(maxLines)
+	}
+	r.lineOffsets = nil
+
 	// Check if file exists first
 	if _, err := os.Stat(r.filePath); os.IsNotExist(err) {
 		r.logger.Warn("Log file does not exist yet, waiting for creation",
@@ -107,6 +238,9 @@ func (r *IncrementalReader) ReadBatch(maxLines int) ([]string, int64, int64, str
 		r.lastPosition = 0
 		r.lastLineContent = ""
 		r.lastInode = currentInode
+		r.partialLine = ""
+		r.pendingSince = time.Time{}
+		r.rotated = true
 	} else if currentInode != 0 {
 		// Update inode for next check
 		r.lastInode = currentInode
@@ -122,6 +256,9 @@ func (r *IncrementalReader) ReadBatch(maxLines int) ([]string, int64, int64, str
 			))
 		r.lastPosition = 0
 		r.lastLineContent = ""
+		r.partialLine = ""
+		r.pendingSince = time.Time{}
+		r.rotated = true
 	}
 
 	// Seek to last known position
@@ -132,119 +269,240 @@ func (r *IncrementalReader) ReadBatch(maxLines int) ([]string, int64, int64, str
 		return nil, 0, 0, "", err
 	}
 
-	// If we're not at the beginning, we might be in the middle of a line.
-	// Seek forward to the next newline to ensure we start at a line boundary.
-	if r.lastPosition > 0 {
-		buf := make([]byte, 1)
-		for {
-			_, err := file.Read(buf)
-			if err != nil {
-				if err == io.EOF {
-					// Reached end of file, no more lines
-					return []string{}, r.lastPosition, r.lastInode, r.lastLineContent, nil
-				}
-				r.logger.WithCaller().Error("Failed to read while seeking to newline",
-					r.logger.Args("path", r.filePath, "error", err))
-				return nil, 0, 0, "", err
-			}
-			if buf[0] == '\n' {
-				// Found newline, current position is at start of next line
-				break
+	reader := bufio.NewReaderSize(file, 64*1024)
+	position := r.lastPosition
+
+	// Positions saved by this reader always sit at a line boundary. Older positions (or ones
+	// computed elsewhere) may point into the middle of a line; in that case skip forward to
+	// the next newline so we never parse a truncated line.
+	if r.lastPosition > 0 && !r.atLineBoundary(file) {
+		_, skipped, _, err := readLine(reader, 0)
+		if err != nil {
+			if err == io.EOF {
+				// Reached end of file, no more lines
+				return []string{}, r.lastPosition, r.lastInode, r.lastLineContent, nil
 			}
+			r.logger.WithCaller().Error("Failed to read while seeking to newline",
+				r.logger.Args("path", r.filePath, "error", err))
+			return nil, 0, 0, "", err
 		}
+		position += skipped
 	}
 
 	lines := []string{}
-	scanner := bufio.NewScanner(file)
-	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	offsets := []int64{}
 	firstLine := true
-	rotationDetected := false
+	partialLine := ""
+	reachedEOF := false
+
+	// In multiline mode only complete entries advance the returned position
+	committed := position
+	var entry multilineEntry
+	flushEntry := func() {
+		if entry.tooLong {
+			r.logger.Warn("Skipping multiline entry longer than the maximum line length",
+				r.logger.Args("path", r.filePath, "lines", entry.count, "max_bytes", r.maxLineLength))
+		} else if entry.count > 0 {
+			// Blank lines belong to the entry, but trailing ones carry nothing
+			lines = append(lines, strings.TrimRight(strings.Join(entry.lines, "\n"), "\n"))
+			offsets = append(offsets, entry.start)
+		}
+		committed = entry.end
+		entry = multilineEntry{}
+	}
 
-	for scanner.Scan() && len(lines) < maxLines {
-		line := scanner.Text()
+	for len(lines) < maxLines {
+		data, n, tooLong, err := readLine(reader, r.maxLineLength)
+		if err != nil && err != io.EOF {
+			r.logger.WithCaller().Error("Failed to read log file",
+				r.logger.Args("path", r.filePath, "error", err))
+			return nil, 0, 0, "", err
+		}
 
-		// ROTATION DETECTION CASE 2: Continuity check for rename-based rotation
-		// This logic runs only on the first line of a new read batch.
-		if firstLine {
-			firstLine = false
+		atEOF := err == io.EOF
+		if atEOF {
+			reachedEOF = true
+			if n == 0 {
+				break
+			}
+			// A trailing line without newline is usually still being written. Leave the
+			// position before it so the complete line is read on a later poll, unless a
+			// flush delay is set and the writer has left it untouched that long.
+			if !r.partialLineSettled(position, data) {
+				partialLine = string(data)
+				break
+			}
+		}
 
-			// Temporarily disable continuity check to avoid false warnings
-			/*
-				if r.lastLineContent != "" {
-					// We have a "last line" from a previous run. The first line we read now
-					// should be that same line, because our position is at the start of it.
-					currentTail := getTail(line, 500)
-					if r.lastLineContent == currentTail {
-						// Continuity is valid. We are reading the same line we finished on.
-						// Skip it to avoid processing it twice.
-						r.logger.Trace("Continuity validated, skipping already-processed line")
-						continue
-					}
+		// Only complete lines advance the position
+		lineStart := position
+		position += n
+		line := strings.TrimRight(string(data), "\r\n")
 
-					// If the tails do not match, it means the file has changed underneath us,
-					// which strongly suggests a log rotation via renaming.
-					r.logger.Debug("Line continuity broken: log rotation with rename detected. Resetting to start of file.",
-						r.logger.Args("path", r.filePath, "expected_tail", r.lastLineContent, "actual_tail", currentTail))
+		if firstLine {
+			firstLine = false
+			if r.entryStart == nil || lineStart == r.partialPosition {
+				r.verifyPartialLine(line)
+			}
+		}
 
-					// Reset position to read the new file from the beginning.
-					r.lastPosition = 0
-					r.lastLineContent = ""
-					// Return immediately to restart the ReadBatch operation with the corrected position.
-					return r.ReadBatch(maxLines)
+		if r.entryStart != nil {
+			if entry.count > 0 && (tooLong || r.entryStart.MatchString(line)) {
+				flushEntry()
+			}
+			if entry.count == 0 {
+				if line == "" && !tooLong {
+					committed = position // Blank line between entries
+					continue
 				}
-			*/
+				entry.start = lineStart
+			}
+			entry.count++
+			entry.size += int(n)
+			entry.tooLong = entry.tooLong || tooLong || entry.size > r.maxLineLength
+			if entry.tooLong {
+				entry.lines = nil
+			} else {
+				entry.lines = append(entry.lines, line)
+			}
+			entry.end = position
+			if atEOF {
+				break
+			}
+			continue
 		}
 
-		// Add line to batch
-		if line != "" {
+		if tooLong {
+			r.logger.Warn("Skipping log line longer than the maximum line length",
+				r.logger.Args("path", r.filePath, "bytes", n, "max_bytes", r.maxLineLength))
+		} else if line != "" {
 			lines = append(lines, line)
+			offsets = append(offsets, lineStart)
 		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		r.logger.WithCaller().Error("Scanner error while reading log file",
-			r.logger.Args("path", r.filePath, "error", err))
-		return nil, 0, 0, "", err
+		if atEOF {
+			break
+		}
 	}
 
-	// After reading a batch, the file pointer is at the start of the *next* line.
-	// To maintain continuity, we need to know the content of the *last* line we just read.
-	// However, the current position is past it. We can't reliably go backward.
-	// A simple and effective strategy is to not update the position if no lines were read.
-	// If lines were read, we update the position and the last line content.
+	if r.entryStart != nil {
+		// The last entry may still be growing: it is complete once the writer has left
+		// the end of the file untouched for the flush delay, like a trailing partial line
+		if entry.count > 0 && reachedEOF && partialLine == "" &&
+			r.entrySettled(entry.start, entry.end) {
+			flushEntry()
+		}
+		if entry.count > 0 {
+			r.logger.Trace("Multiline entry at end of file, waiting for the next entry",
+				r.logger.Args("path", r.filePath, "position", entry.start, "lines", entry.count))
+		}
+		position = committed
+	}
 
-	// Get the current position *before* we potentially modify it.
-	newPos, err := file.Seek(0, io.SeekCurrent)
-	if err != nil {
-		r.logger.WithCaller().Warn("Failed to get current position",
-			r.logger.Args("path", r.filePath, "error", err))
-		// If we can't get the position, it's safer to stick with the old one to force a re-read.
-		newPos = r.lastPosition
+	r.partialLine = partialLine
+	r.lineOffsets = offsets
+	if partialLine != "" {
+		r.logger.Trace("Partial line at end of file, waiting for newline",
+			r.logger.Args("path", r.filePath, "position", position, "partial_bytes", len(partialLine)))
 	}
 
 	// If we read any lines, we update our tracking info.
 	if len(lines) > 0 {
-		lastLineRead := lines[len(lines)-1]
-
-		newLastPosition := newPos
-
 		// Get last line for next continuity check
-		lastLineForCheck := getTail(lastLineRead, 500)
+		lastLineForCheck := getTail(lines[len(lines)-1], 500)
+		if partialLine != "" {
+			// Preserve the pending partial so the next read can verify it was completed in place
+			lastLineForCheck = getTail(partialLine, 500)
+		}
 
 		r.logger.Trace("Read batch from log file",
 			r.logger.Args(
 				"path", r.filePath,
 				"lines_read", len(lines),
 				"old_position", r.lastPosition,
-				"new_position", newLastPosition,
-				"rotation_detected", rotationDetected,
+				"new_position", position,
 			))
 
-		return lines, newLastPosition, r.lastInode, lastLineForCheck, nil
+		return lines, position, r.lastInode, lastLineForCheck, nil
+	}
+
+	if partialLine != "" {
+		// No complete line yet, but remember the partial for verification
+		return []string{}, position, r.lastInode, getTail(partialLine, 500), nil
 	}
 
 	// No new lines were read, so we don't update the position or last line content.
-	return []string{}, r.lastPosition, r.lastInode, r.lastLineContent, nil
+	return []string{}, position, r.lastInode, r.lastLineContent, nil
+}
+
+// readLine reads through the next newline, or to EOF, keeping at most maxLen bytes
+// (0 = keep nothing). n counts every byte consumed; tooLong reports that the line
+// did not fit in maxLen bytes.
+func readLine(reader *bufio.Reader, maxLen int) (data []byte, n int64, tooLong bool, err error) {
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		n += int64(len(chunk))
+		if keep := min(len(chunk), maxLen-len(data)); keep > 0 {
+			data = append(data, chunk[:keep]...)
+		}
+		if err != bufio.ErrBufferFull {
+			return data, n, int64(len(data)) < n, err
+		}
+	}
+}
+
+// partialLineSettled reports whether the trailing line without newline at position has
+// stayed unchanged for the partial line flush delay, i.e. the writer will not complete it
+func (r *IncrementalReader) partialLineSettled(position int64, data []byte) bool {
+	if r.partialSince.IsZero() || r.partialPosition != position || r.partialLine != string(data) {
+		r.partialPosition = position
+		r.partialSince = time.Now()
+		return false
+	}
+	return r.partialDelay > 0 && time.Since(r.partialSince) >= r.partialDelay
+}
+
+// entrySettled reports whether the trailing multiline entry between start and end has
+// stayed unchanged for the flush delay, i.e. no continuation line is coming
+func (r *IncrementalReader) entrySettled(start, end int64) bool {
+	if r.pendingSince.IsZero() || r.pendingStart != start || r.pendingEnd != end {
+		r.pendingStart = start
+		r.pendingEnd = end
+		r.pendingSince = time.Now()
+		return false
+	}
+	return time.Since(r.pendingSince) >= r.flushDelay
+}
+
+// atLineBoundary reports whether lastPosition is at the start of a line,
+// i.e. the byte right before it is a newline
+func (r *IncrementalReader) atLineBoundary(file *os.File) bool {
+	buf := make([]byte, 1)
+	if _, err := file.ReadAt(buf, r.lastPosition-1); err != nil {
+		return false
+	}
+	return buf[0] == '\n'
+}
+
+// verifyPartialLine checks that a line previously seen as partial was completed in place.
+// A mismatch means the file changed underneath us; it is logged but not treated as an error.
+func (r *IncrementalReader) verifyPartialLine(line string) {
+	if r.partialLine == "" {
+		return
+	}
+	if !strings.HasPrefix(line, strings.TrimRight(r.partialLine, "\r\n")) {
+		r.logger.Debug("Partial line changed before it was completed",
+			r.logger.Args("path", r.filePath, "expected_prefix", getTail(r.partialLine, 100)))
+	}
+	r.partialLine = ""
+}
+
+// RotationDetected reports whether a log rotation was detected since the last call
+// and clears the flag, so callers can persist the new file identity once
+func (r *IncrementalReader) RotationDetected() bool {
+	rotated := r.rotated
+	r.rotated = false
+	return rotated
 }
 
 // UpdatePosition is called by the processor to confirm the position after a successful batch write.
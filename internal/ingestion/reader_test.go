@@ -0,0 +1,285 @@
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadBatchWaitsForPartialLine(t *testing.T) {
+	logger := pterm.DefaultLogger
+	path := filepath.Join(t.TempDir(), "access.log")
+
+	first := `{"ClientHost":"1.2.3.4","RequestPath":"/first"}`
+	second := `{"ClientHost":"1.2.3.4","RequestPath":"/second","DownstreamStatus":200}`
+
+	// First poll: one complete line followed by the first half of another
+	assert.NoError(t, os.WriteFile(path, []byte(first+"\n"+second[:20]), 0o644))
+
+	reader := NewIncrementalReader(path, 0, 0, "", &logger)
+	lines, pos, inode, lastLine, err := reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{first}, lines)
+	assert.Equal(t, int64(len(first)+1), pos, "position must not advance past the partial line")
+	assert.Equal(t, second[:20], lastLine, "partial line is kept for verification")
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	// Second poll: the rest of the line arrives
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(second[20:] + "\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	lines, pos, inode, lastLine, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{second}, lines, "completed line is read once, in full")
+	assert.Equal(t, int64(len(first)+len(second)+2), pos)
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	// Third poll: nothing new
+	lines, _, _, _, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Empty(t, lines)
+}
+
+func TestReadBatchFollowsRotatedFile(t *testing.T) {
+	logger := pterm.DefaultLogger
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+
+	assert.NoError(t, os.WriteFile(path, []byte("old-1\nold-2\n"), 0o644))
+
+	reader := NewIncrementalReader(path, 0, 0, "", &logger)
+	lines, pos, inode, lastLine, err := reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old-1", "old-2"}, lines)
+	assert.False(t, reader.RotationDetected())
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	// Rotate by rename and recreate: the new file gets a different inode
+	assert.NoError(t, os.Rename(path, filepath.Join(dir, "access.log.1")))
+	assert.NoError(t, os.WriteFile(path, []byte("new-1\n"), 0o644))
+
+	lines, pos, newInode, _, err := reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new-1"}, lines, "new file is read from the beginning")
+	assert.Equal(t, int64(len("new-1\n")), pos)
+	assert.NotEqual(t, inode, newInode)
+	assert.True(t, reader.RotationDetected())
+	assert.False(t, reader.RotationDetected(), "flag is cleared once reported")
+}
+
+func TestIsStreamPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	assert.NoError(t, os.WriteFile(path, []byte("line\n"), 0o644))
+
+	assert.True(t, IsStreamPath(StdinPath))
+	assert.False(t, IsStreamPath(path))
+	assert.False(t, IsStreamPath(filepath.Join(t.TempDir(), "missing.log")))
+}
+
+func TestReadBatchStreamsStdin(t *testing.T) {
+	logger := pterm.DefaultLogger
+
+	pipeReader, pipeWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stdin := os.Stdin
+	os.Stdin = pipeReader
+	defer func() {
+		os.Stdin = stdin
+		pipeReader.Close()
+	}()
+
+	// Stored positions are ignored for streams
+	reader := NewIncrementalReader(StdinPath, 1234, 42, "previous", &logger)
+	defer reader.Close()
+	assert.True(t, reader.IsStream())
+
+	_, err = pipeWriter.WriteString("first\r\n\nsecond\nthird")
+	assert.NoError(t, err)
+	assert.NoError(t, pipeWriter.Close())
+
+	var lines []string
+	assert.Eventually(t, func() bool {
+		batch, pos, inode, lastLine, err := reader.ReadBatch(2)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(batch), 2)
+		assert.Zero(t, pos)
+		assert.Zero(t, inode)
+		assert.Empty(t, lastLine)
+		lines = append(lines, batch...)
+		return len(lines) == 3
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"first", "second", "third"}, lines, "empty lines are skipped, the unterminated last line is kept")
+	assert.False(t, reader.RotationDetected())
+}
+
+func TestReadBatchSkipsLinesOverMaxLength(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	path := filepath.Join(t.TempDir(), "access.log")
+
+	long := strings.Repeat("x", 200*1024)
+	content := "short-1\n" + long + "\nshort-2\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	reader := NewIncrementalReader(path, 0, 0, "", logger)
+	reader.SetMaxLineLength(64 * 1024)
+	lines, pos, _, _, err := reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"short-1", "short-2"}, lines, "the overlong line is skipped")
+	assert.Equal(t, int64(len(content)), pos, "position still moves past the skipped line")
+}
+
+func TestReadBatchReadsSettledLineWithoutNewline(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	path := filepath.Join(t.TempDir(), "access.log")
+	assert.NoError(t, os.WriteFile(path, []byte("first\nlast"), 0o644))
+
+	reader := NewIncrementalReader(path, 0, 0, "", logger)
+	reader.SetPartialLineFlushDelay(50 * time.Millisecond)
+
+	lines, pos, inode, lastLine, err := reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first"}, lines, "a fresh trailing line may still be growing")
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	lines, pos, inode, lastLine, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Empty(t, lines, "not settled before the flush delay")
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	time.Sleep(60 * time.Millisecond)
+	lines, pos, inode, lastLine, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"last"}, lines, "an unchanged trailing line is read once settled")
+	assert.Equal(t, int64(len("first\nlast")), pos)
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	// The writer later terminates the line and continues: only new lines are read
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString("\nnext\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	lines, _, _, _, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"next"}, lines)
+}
+
+func TestReadBatchWaitsForNewlineByDefault(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	path := filepath.Join(t.TempDir(), "access.log")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"half":`), 0o644))
+
+	reader := NewIncrementalReader(path, 0, 0, "", logger)
+	lines, pos, inode, lastLine, err := reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Empty(t, lines)
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	// A slow writer finishes the line well after it was first seen
+	time.Sleep(60 * time.Millisecond)
+	lines, pos, inode, lastLine, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Empty(t, lines, "without a flush delay the fragment is never read on its own")
+	assert.Zero(t, pos)
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`"rest"}` + "\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	lines, pos, _, _, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"half":"rest"}`}, lines, "the completed line is read once, whole")
+	assert.Equal(t, int64(len(`{"half":"rest"}`)+1), pos)
+}
+
+func TestReadBatchJoinsMultilineEntries(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	path := filepath.Join(t.TempDir(), "error.log")
+
+	first := "2026-03-01 12:00:00 panic: boom\n\tat main.go:10\n\tat proc.go:250\n"
+	second := "2026-03-01 12:00:01 ok\n"
+	third := "2026-03-01 12:00:02 {\n  \"status\": 500\n"
+	assert.NoError(t, os.WriteFile(path, []byte(first+"\n"+second+third), 0o644))
+
+	reader := NewIncrementalReader(path, 0, 0, "", logger)
+	reader.flushDelay = 50 * time.Millisecond
+	reader.SetMultiline(regexp.MustCompile(`^\d{4}-\d{2}-\d{2} `))
+
+	lines, pos, inode, lastLine, err := reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"2026-03-01 12:00:00 panic: boom\n\tat main.go:10\n\tat proc.go:250",
+		"2026-03-01 12:00:01 ok",
+	}, lines)
+	assert.Equal(t, []int64{0, int64(len(first) + 1)}, reader.LineOffsets())
+	assert.Equal(t, int64(len(first)+1+len(second)), pos, "position stops before the entry that may still grow")
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	// More of the last entry arrives, then the next entry completes it
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString("}\n2026-03-01 12:00:03 next\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	lines, pos, inode, lastLine, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2026-03-01 12:00:02 {\n  \"status\": 500\n}"}, lines)
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	// The final entry is read once nothing has been appended for the flush delay
+	lines, pos, inode, lastLine, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Empty(t, lines)
+	reader.UpdatePosition(pos, inode, lastLine)
+	time.Sleep(60 * time.Millisecond)
+	lines, pos, _, _, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2026-03-01 12:00:03 next"}, lines)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, info.Size(), pos)
+}
+
+func TestReadBatchMultilineBatchLimit(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	path := filepath.Join(t.TempDir(), "error.log")
+	content := "E1\n a\nE2\n b\n c\nE3\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	reader := NewIncrementalReader(path, 0, 0, "", logger)
+	reader.SetMultiline(regexp.MustCompile(`^E`))
+
+	lines, pos, inode, lastLine, err := reader.ReadBatch(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"E1\n a"}, lines, "maxLines counts entries")
+	assert.Equal(t, int64(len("E1\n a\n")), pos)
+	reader.UpdatePosition(pos, inode, lastLine)
+
+	lines, _, _, _, err = reader.ReadBatch(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"E2\n b\n c"}, lines)
+
+	// Entries over the maximum length are skipped whole
+	reader = NewIncrementalReader(path, 0, 0, "", logger)
+	reader.SetMultiline(regexp.MustCompile(`^E`))
+	reader.SetMaxLineLength(8)
+	lines, _, _, _, err = reader.ReadBatch(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"E1\n a"}, lines)
+}
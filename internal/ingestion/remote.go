@@ -0,0 +1,264 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package ingestion
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRemotePollInterval is how long a remote source waits, once caught up, before
+	// asking the server for new bytes again
+	DefaultRemotePollInterval = 10 * time.Second
+	// DefaultRemoteTimeout bounds a single request to a remote source
+	DefaultRemoteTimeout = 30 * time.Second
+)
+
+// RemoteConfig holds the HTTP settings shared by every remote (http:// or https://) source.
+// Credentials go in Headers: source paths are logged and listed by the API, so a user or
+// password in the URL is rejected when the source is registered and never sent.
+type RemoteConfig struct {
+	Headers      http.Header   // Sent with every request, e.g. Authorization
+	PollInterval time.Duration // 0 = DefaultRemotePollInterval
+	Timeout      time.Duration // 0 = DefaultRemoteTimeout
+}
+
+// ParseRemoteHeaders parses "Name: value" pairs separated by semicolons,
+// e.g. "Authorization: Bearer abc; X-Scope: logs"
+func ParseRemoteHeaders(spec string) (http.Header, error) {
+	headers := http.Header{}
+	for _, pair := range strings.Split(spec, ";") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q, expected \"Name: value\"", pair)
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// remoteLog polls a log file served over HTTP(S). Only bytes past the read position are
+// requested (Range: bytes=<position>-), so the position is tracked like a local file's.
+type remoteLog struct {
+	url      string
+	client   *http.Client
+	headers  http.Header
+	interval time.Duration
+	nextPoll time.Time // Polls before this return nothing, so a caught up source is not hammered
+}
+
+func newRemoteLog(rawURL string, cfg RemoteConfig) *remoteLog {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultRemotePollInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultRemoteTimeout
+	}
+	// Go would send the URL's user and password as basic auth
+	if u, err := url.Parse(rawURL); err == nil && u.User != nil {
+		u.User = nil
+		rawURL = u.String()
+	}
+	return &remoteLog{
+		url:      rawURL,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		headers:  cfg.Headers,
+		interval: cfg.PollInterval,
+	}
+}
+
+// remoteResponse is the outcome of a range request
+type remoteResponse struct {
+	body      io.ReadCloser // Bytes from the requested position on; nil when there are none
+	truncated bool          // The remote file is now shorter than the position: it was rotated
+}
+
+// fetch requests the bytes of the remote file from position on. Servers answering 200
+// instead of 206 ignore ranges; the bytes before position are then skipped.
+func (l *remoteLog) fetch(position int64) (*remoteResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, l.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range l.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", position))
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, _ := parseContentRange(resp.Header.Get("Content-Range"))
+		if start != position {
+			resp.Body.Close()
+			return nil, fmt.Errorf("server returned bytes from %d, requested %d", start, position)
+		}
+		return &remoteResponse{body: resp.Body}, nil
+
+	case http.StatusOK:
+		if resp.ContentLength >= 0 && resp.ContentLength < position {
+			resp.Body.Close()
+			return &remoteResponse{truncated: true}, nil
+		}
+		if skipped, err := io.CopyN(io.Discard, resp.Body, position); err != nil {
+			resp.Body.Close()
+			if err == io.EOF && skipped < position {
+				return &remoteResponse{truncated: true}, nil
+			}
+			return nil, err
+		}
+		return &remoteResponse{body: resp.Body}, nil
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Nothing past position: either no new bytes, or the file shrank below it
+		resp.Body.Close()
+		_, size := parseContentRange(resp.Header.Get("Content-Range"))
+		return &remoteResponse{truncated: size >= 0 && size < position}, nil
+
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+// parseContentRange returns the first byte and the complete length of a Content-Range
+// header ("bytes 100-199/1000" or "bytes */1000"); unknown values are -1
+func parseContentRange(header string) (start int64, size int64) {
+	start, size = -1, -1
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes ")
+	if !ok {
+		return start, size
+	}
+	byteRange, total, _ := strings.Cut(spec, "/")
+	if first, _, ok := strings.Cut(byteRange, "-"); ok {
+		if v, err := strconv.ParseInt(first, 10, 64); err == nil {
+			start = v
+		}
+	}
+	if v, err := strconv.ParseInt(total, 10, 64); err == nil {
+		size = v
+	}
+	return start, size
+}
+
+// readRemote is ReadBatch for a remote source. Remote files have no inode: rotation is only
+// detected when the file becomes shorter than the read position. Request failures are
+// logged and retried on the next poll, like a missing local file.
+func (r *IncrementalReader) readRemote(maxLines int) ([]string, int64, int64, string, error) {
+	r.lineOffsets = nil
+	if time.Now().Before(r.remote.nextPoll) {
+		return []string{}, r.lastPosition, 0, r.lastLineContent, nil
+	}
+	r.remote.nextPoll = time.Now().Add(r.remote.interval)
+
+	resp, err := r.remote.fetch(r.lastPosition)
+	if err != nil {
+		r.logger.Warn("Failed to fetch remote log, will retry",
+			r.logger.Args("url", r.remote.url, "position", r.lastPosition, "error", err))
+		return []string{}, r.lastPosition, 0, r.lastLineContent, nil
+	}
+	if resp.truncated {
+		r.logger.Info("Log rotation detected: remote file truncated",
+			r.logger.Args("url", r.remote.url, "old_size", r.lastPosition))
+		r.lastPosition = 0
+		r.lastLineContent = ""
+		r.partialLine = ""
+		r.rotated = true
+		// Read the new file right away
+		r.remote.nextPoll = time.Time{}
+		return []string{}, 0, 0, "", nil
+	}
+	if resp.body == nil {
+		return []string{}, r.lastPosition, 0, r.lastLineContent, nil
+	}
+	defer resp.body.Close()
+
+	reader := bufio.NewReaderSize(resp.body, 64*1024)
+	position := r.lastPosition
+	lines := []string{}
+	offsets := []int64{}
+	partialLine := ""
+	firstLine := true
+	for len(lines) < maxLines {
+		data, n, tooLong, err := readLine(reader, r.maxLineLength)
+		if err != nil && err != io.EOF {
+			// Keep the complete lines read so far; the rest is requested again next poll
+			r.logger.Warn("Failed to read remote log, will retry",
+				r.logger.Args("url", r.remote.url, "error", err))
+			break
+		}
+		if err == io.EOF {
+			// Same rule as local files: a trailing line without newline waits until
+			// it is completed, or left unchanged for the partial line flush delay if set
+			if n == 0 || !r.partialLineSettled(position, data) {
+				partialLine = string(data)
+				break
+			}
+		}
+
+		lineStart := position
+		position += n
+		line := strings.TrimRight(string(data), "\r\n")
+		if firstLine {
+			firstLine = false
+			r.verifyPartialLine(line)
+		}
+
+		if tooLong {
+			r.logger.Warn("Skipping log line longer than the maximum line length",
+				r.logger.Args("url", r.remote.url, "bytes", n, "max_bytes", r.maxLineLength))
+		} else if line != "" {
+			lines = append(lines, line)
+			offsets = append(offsets, lineStart)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if len(lines) == maxLines {
+		// More may be waiting: keep reading on the next tick instead of waiting a full interval
+		r.remote.nextPoll = time.Time{}
+	}
+	r.partialLine = partialLine
+	r.lineOffsets = offsets
+
+	if len(lines) > 0 {
+		return lines, position, 0, getTail(lines[len(lines)-1], 500), nil
+	}
+	return []string{}, position, 0, r.lastLineContent, nil
+}
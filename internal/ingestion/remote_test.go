@@ -0,0 +1,151 @@
+package ingestion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteFile serves a log file that tests can rewrite, honoring Range requests
+type remoteFile struct {
+	mu           sync.Mutex
+	content      string
+	ignoreRanges bool
+	auth         string // Last Authorization header received
+}
+
+func (f *remoteFile) set(content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.content = content
+}
+
+func (f *remoteFile) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+	if f.ignoreRanges {
+		r.Header.Del("Range")
+	}
+	http.ServeContent(w, r, "access.log", time.Time{}, strings.NewReader(f.content))
+}
+
+// readRemoteNow polls the reader without waiting for the poll interval
+func readRemoteNow(t *testing.T, reader *IncrementalReader) ([]string, int64) {
+	t.Helper()
+	reader.remote.nextPoll = time.Time{}
+	lines, pos, inode, lastLine, err := reader.ReadBatch(100)
+	require.NoError(t, err)
+	reader.UpdatePosition(pos, inode, lastLine)
+	return lines, pos
+}
+
+func TestRemoteReaderFollowsRangeRequests(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	file := &remoteFile{content: "first\nsecond\npart"}
+	server := httptest.NewServer(file)
+	defer server.Close()
+
+	reader := NewIncrementalReader(server.URL+"/access.log?token=abc", 0, 0, "", logger)
+	require.True(t, reader.IsRemote())
+	reader.SetRemoteConfig(RemoteConfig{Headers: http.Header{"Authorization": {"Bearer secret"}}})
+
+	lines, pos := readRemoteNow(t, reader)
+	assert.Equal(t, []string{"first", "second"}, lines)
+	assert.Equal(t, int64(13), pos, "the partial line is left for a later poll")
+	assert.Equal(t, "Bearer secret", file.auth)
+
+	// Caught up: the next tick waits for the poll interval without a request
+	file.set("first\nsecond\npartial\nthird\n")
+	lines, _, _, _, err := reader.ReadBatch(100)
+	require.NoError(t, err)
+	assert.Empty(t, lines)
+
+	lines, pos = readRemoteNow(t, reader)
+	assert.Equal(t, []string{"partial", "third"}, lines)
+	assert.Equal(t, int64(len("first\nsecond\npartial\nthird\n")), pos)
+
+	// Nothing new: the server answers 416
+	lines, _ = readRemoteNow(t, reader)
+	assert.Empty(t, lines)
+	assert.False(t, reader.RotationDetected())
+
+	// The file shrank below the position: rotation, then the new file is read from the start
+	file.set("rotated\n")
+	lines, pos = readRemoteNow(t, reader)
+	assert.Empty(t, lines)
+	assert.Zero(t, pos)
+	assert.True(t, reader.RotationDetected())
+	lines, _ = readRemoteNow(t, reader)
+	assert.Equal(t, []string{"rotated"}, lines)
+}
+
+func TestRemoteReaderWithoutRangeSupport(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	file := &remoteFile{content: "one\ntwo\n", ignoreRanges: true}
+	server := httptest.NewServer(file)
+	defer server.Close()
+
+	reader := NewIncrementalReader(server.URL, 4, 0, "one", logger)
+	lines, pos := readRemoteNow(t, reader)
+	assert.Equal(t, []string{"two"}, lines, "bytes before the position are skipped")
+	assert.Equal(t, int64(8), pos)
+
+	file.set("new\n")
+	lines, _ = readRemoteNow(t, reader)
+	assert.Empty(t, lines)
+	assert.True(t, reader.RotationDetected())
+	lines, _ = readRemoteNow(t, reader)
+	assert.Equal(t, []string{"new"}, lines)
+}
+
+func TestRemoteReaderRetriesOnServerError(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	reader := NewIncrementalReader(server.URL, 10, 0, "last", logger)
+	lines, pos, _, lastLine, err := reader.ReadBatch(100)
+	assert.NoError(t, err, "failures are retried on the next poll")
+	assert.Empty(t, lines)
+	assert.Equal(t, int64(10), pos)
+	assert.Equal(t, "last", lastLine)
+}
+
+func TestRemoteReaderDoesNotSendURLCredentials(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	file := &remoteFile{content: "first\n"}
+	server := httptest.NewServer(file)
+	defer server.Close()
+
+	reader := NewIncrementalReader(strings.Replace(server.URL, "://", "://user:pass@", 1)+"/access.log", 0, 0, "", logger)
+	assert.NotContains(t, reader.remote.url, "pass")
+	lines, _ := readRemoteNow(t, reader)
+	assert.Equal(t, []string{"first"}, lines)
+	assert.Empty(t, file.auth, "credentials belong in REMOTE_SOURCE_HEADERS")
+}
+
+func TestParseRemoteHeaders(t *testing.T) {
+	headers, err := ParseRemoteHeaders("Authorization: Bearer a:b; X-Scope: logs ;")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer a:b", headers.Get("Authorization"))
+	assert.Equal(t, "logs", headers.Get("X-Scope"))
+
+	headers, err = ParseRemoteHeaders("")
+	require.NoError(t, err)
+	assert.Empty(t, headers)
+
+	for _, spec := range []string{"Authorization", ": value", "Bad Name: value"} {
+		_, err = ParseRemoteHeaders(spec)
+		assert.Error(t, err, spec)
+	}
+}
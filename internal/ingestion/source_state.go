@@ -0,0 +1,255 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package ingestion
+
+import (
+	"fmt"
+	"time"
+
+	"loglynx/internal/database/models"
+)
+
+// SourceState is a registered log source with its read positions, as exported for a
+// backup or a move to another host
+type SourceState struct {
+	Name             string            `json:"name"`
+	Path             string            `json:"path"`
+	ParserType       string            `json:"parser_type"`
+	Managed          bool              `json:"managed"` // Declared in LOG_SOURCES_FILE on the exporting host
+	RetentionDays    int               `json:"retention_days"`
+	DisableDedup     bool              `json:"disable_dedup"`
+	MultilinePattern string            `json:"multiline_pattern,omitempty"`
+	HashFields       string            `json:"hash_fields,omitempty"`
+	SampleRate       int               `json:"sample_rate"`
+	SampleKeepErrors bool              `json:"sample_keep_errors"`
+	Options          string            `json:"options,omitempty"`
+	LastPosition     int64             `json:"last_position"`
+	LastInode        int64             `json:"last_inode"`
+	LastLineContent  string            `json:"last_line_content,omitempty"`
+	LastReadAt       *time.Time        `json:"last_read_at,omitempty"`
+	Files            []SourceFileState `json:"files,omitempty"` // Per-file positions of a glob source
+}
+
+// SourceFileState is the read position of one file matched by a glob source
+type SourceFileState struct {
+	Path            string     `json:"path"`
+	LastPosition    int64      `json:"last_position"`
+	LastInode       int64      `json:"last_inode"`
+	LastLineContent string     `json:"last_line_content,omitempty"`
+	LastReadAt      *time.Time `json:"last_read_at,omitempty"`
+}
+
+// SourceImportResult reports what ImportSources did with each source
+type SourceImportResult struct {
+	Created []string        `json:"created"`
+	Updated []string        `json:"updated"` // Already registered: retention and positions restored
+	Skipped []SkippedSource `json:"skipped"`
+}
+
+// SkippedSource is a source ImportSources left out, with the reason
+type SkippedSource struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ExportSources returns every registered log source with its stored read positions.
+// Running processors save their position after each batch, so a position may trail
+// the file by the batch being processed.
+func (c *Coordinator) ExportSources() ([]SourceState, error) {
+	sources, err := c.sourceRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load log sources: %w", err)
+	}
+
+	states := make([]SourceState, 0, len(sources))
+	for _, source := range sources {
+		state := SourceState{
+			Name:             source.Name,
+			Path:             source.Path,
+			ParserType:       source.ParserType,
+			Managed:          source.Managed,
+			RetentionDays:    source.RetentionDays,
+			DisableDedup:     source.DisableDedup,
+			MultilinePattern: source.MultilinePattern,
+			HashFields:       source.HashFields,
+			SampleRate:       source.SampleRate,
+			SampleKeepErrors: source.SampleKeepErrors,
+			Options:          source.Options,
+			LastPosition:     source.LastPosition,
+			LastInode:        source.LastInode,
+			LastLineContent:  source.LastLineContent,
+			LastReadAt:       source.LastReadAt,
+		}
+		if source.IsGlob() {
+			files, err := c.sourceRepo.FindFiles(source.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load file tracking of %s: %w", source.Name, err)
+			}
+			for _, file := range files {
+				state.Files = append(state.Files, SourceFileState{
+					Path:            file.Path,
+					LastPosition:    file.LastPosition,
+					LastInode:       file.LastInode,
+					LastLineContent: file.LastLineContent,
+					LastReadAt:      file.LastReadAt,
+				})
+			}
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// ImportSources restores log sources exported by ExportSources. Unknown sources are
+// registered unmanaged with their settings and positions; a source already registered
+// under the same name and path keeps its settings and gets its retention and positions
+// restored, its processors restarted from them. Inodes are not restored since they only
+// identify files on the exporting host: the files' last line still guards against
+// resuming in a different file.
+func (c *Coordinator) ImportSources(states []SourceState) (*SourceImportResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing, err := c.sourceRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load log sources: %w", err)
+	}
+	byName := make(map[string]*models.LogSource, len(existing))
+	byPath := make(map[string]string, len(existing))
+	for _, source := range existing {
+		byName[source.Name] = source
+		byPath[source.Path] = source.Name
+	}
+
+	result := &SourceImportResult{Created: []string{}, Updated: []string{}, Skipped: []SkippedSource{}}
+	for _, state := range states {
+		if state.Name == "" || state.Path == "" || state.ParserType == "" {
+			result.Skipped = append(result.Skipped, SkippedSource{Name: state.Name, Reason: "name, path and parser_type are required"})
+			continue
+		}
+		if models.HasURLCredentials(state.Path) {
+			result.Skipped = append(result.Skipped, SkippedSource{Name: state.Name, Reason: ErrSourceCredentials.Error()})
+			continue
+		}
+		if _, err := c.parserReg.Get(state.ParserType); err != nil {
+			result.Skipped = append(result.Skipped, SkippedSource{Name: state.Name, Reason: fmt.Sprintf("%s: %s", ErrUnknownParser, state.ParserType)})
+			continue
+		}
+
+		if current, ok := byName[state.Name]; ok {
+			if current.Path != state.Path {
+				result.Skipped = append(result.Skipped, SkippedSource{Name: state.Name, Reason: fmt.Sprintf("registered with path %s", current.Path)})
+				continue
+			}
+			if err := c.restoreSourceLocked(state); err != nil {
+				return nil, err
+			}
+			result.Updated = append(result.Updated, state.Name)
+			continue
+		}
+		if owner, ok := byPath[state.Path]; ok {
+			result.Skipped = append(result.Skipped, SkippedSource{Name: state.Name, Reason: fmt.Sprintf("path is used by %s", owner)})
+			continue
+		}
+
+		source := &models.LogSource{
+			Name:             state.Name,
+			Path:             state.Path,
+			ParserType:       state.ParserType,
+			LastLineContent:  state.LastLineContent,
+			LastPosition:     state.LastPosition,
+			LastReadAt:       state.LastReadAt,
+			RetentionDays:    state.RetentionDays,
+			DisableDedup:     state.DisableDedup,
+			MultilinePattern: state.MultilinePattern,
+			HashFields:       state.HashFields,
+			SampleRate:       state.SampleRate,
+			SampleKeepErrors: state.SampleKeepErrors,
+			Options:          state.Options,
+		}
+		if err := c.sourceRepo.Create(source); err != nil {
+			return nil, fmt.Errorf("failed to register log source %s: %w", state.Name, err)
+		}
+		if err := c.restoreFilesLocked(state); err != nil {
+			return nil, err
+		}
+		byName[source.Name] = source
+		byPath[source.Path] = source.Name
+		result.Created = append(result.Created, state.Name)
+		c.logger.Info("Imported log source", c.logger.Args("source", source.Name, "path", source.Path, "position", source.LastPosition))
+
+		if c.isRunning {
+			if err := c.startSourceProcessorLocked(source); err != nil {
+				c.logger.WithCaller().Warn("Failed to start processor for imported source",
+					c.logger.Args("source", source.Name, "error", err))
+			}
+		}
+	}
+	return result, nil
+}
+
+// restoreSourceLocked stops the processors of a registered source, stores the imported
+// retention and positions and starts it again from them
+// IMPORTANT: Caller must hold c.mu lock
+func (c *Coordinator) restoreSourceLocked(state SourceState) error {
+	// Processors are stopped first so none writes its position back over the restored one
+	stopped := c.removeProcessorsLocked(state.Name)
+
+	if err := c.sourceRepo.UpdateRetention(state.Name, state.RetentionDays); err != nil {
+		return fmt.Errorf("failed to restore retention of %s: %w", state.Name, err)
+	}
+	if err := c.sourceRepo.UpdateTracking(state.Name, state.LastPosition, 0, state.LastLineContent); err != nil {
+		return fmt.Errorf("failed to restore position of %s: %w", state.Name, err)
+	}
+	if err := c.restoreFilesLocked(state); err != nil {
+		return err
+	}
+	c.logger.Info("Restored log source position",
+		c.logger.Args("source", state.Name, "position", state.LastPosition, "stopped_processors", stopped))
+
+	if !c.isRunning {
+		return nil
+	}
+	source, err := c.sourceRepo.FindByName(state.Name)
+	if err != nil {
+		return fmt.Errorf("failed to reload log source %s: %w", state.Name, err)
+	}
+	if err := c.startSourceProcessorLocked(source); err != nil {
+		c.logger.WithCaller().Warn("Failed to restart processor for restored source",
+			c.logger.Args("source", state.Name, "error", err))
+	}
+	return nil
+}
+
+// restoreFilesLocked stores the imported per-file positions of a glob source
+// IMPORTANT: Caller must hold c.mu lock
+func (c *Coordinator) restoreFilesLocked(state SourceState) error {
+	for _, file := range state.Files {
+		if file.Path == "" {
+			continue
+		}
+		if err := c.sourceRepo.UpdateFileTracking(state.Name, file.Path, file.LastPosition, 0, file.LastLineContent); err != nil {
+			return fmt.Errorf("failed to restore position of %s in %s: %w", file.Path, state.Name, err)
+		}
+	}
+	return nil
+}
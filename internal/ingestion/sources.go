@@ -0,0 +1,163 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package ingestion
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"loglynx/internal/database/models"
+)
+
+var (
+	// ErrSourceExists is returned by AddSource when the name or path is already registered
+	ErrSourceExists = errors.New("log source already exists")
+	// ErrSourceNotFound is returned by RemoveSource for an unknown source name
+	ErrSourceNotFound = errors.New("log source not found")
+	// ErrSourceManaged is returned by RemoveSource for a source declared in LOG_SOURCES_FILE,
+	// which would be registered again at the next startup
+	ErrSourceManaged = errors.New("log source is declared in the sources file")
+	// ErrSampleNotParsable is returned by AddSource when the parser rejects the sample line
+	ErrSampleNotParsable = errors.New("sample line is not parsable")
+	// ErrSourceCredentials is returned by AddSource for a URL with a user or password;
+	// remote sources authenticate with REMOTE_SOURCE_HEADERS instead
+	ErrSourceCredentials = errors.New("source URL must not contain credentials, use REMOTE_SOURCE_HEADERS")
+)
+
+// ListSources returns every registered log source
+func (c *Coordinator) ListSources() ([]*models.LogSource, error) {
+	return c.sourceRepo.FindAll()
+}
+
+// AddSource registers a new log source and starts its processors when the coordinator is
+// running. The parser must accept sample, or the first line of the source's file when
+// sample is empty; a source with nothing to sample yet (a glob, a stream or a file not
+// created yet) is registered unchecked.
+func (c *Coordinator) AddSource(source *models.LogSource, sample string) error {
+	if models.HasURLCredentials(source.Path) {
+		return ErrSourceCredentials
+	}
+	parser, err := c.parserReg.Get(source.ParserType)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnknownParser, source.ParserType)
+	}
+	if sample == "" {
+		sample = c.sampleLine(source)
+	}
+	if sample != "" && !parser.CanParse(sample) {
+		return fmt.Errorf("%w by the %s parser", ErrSampleNotParsable, source.ParserType)
+	}
+
+	// Holding the lock keeps the sync loop from starting the source before it is fully added
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing, err := c.sourceRepo.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to load log sources: %w", err)
+	}
+	for _, current := range existing {
+		if current.Name == source.Name {
+			return fmt.Errorf("%w: name %s", ErrSourceExists, source.Name)
+		}
+		if current.Path == source.Path {
+			return fmt.Errorf("%w: path %s is used by %s", ErrSourceExists, source.Path, current.Name)
+		}
+	}
+
+	if err := c.sourceRepo.Create(source); err != nil {
+		return fmt.Errorf("failed to register log source: %w", err)
+	}
+	c.logger.Info("Registered log source", c.logger.Args("source", source.Name, "path", source.Path, "parser", source.ParserType))
+
+	if !c.isRunning {
+		return nil
+	}
+	// A failed start is not fatal: the source is registered and the sync loop retries it
+	if err := c.startSourceProcessorLocked(source); err != nil {
+		c.logger.WithCaller().Warn("Failed to start processor for new source",
+			c.logger.Args("source", source.Name, "error", err))
+	}
+	return nil
+}
+
+// RemoveSource stops the processors of a log source and unregisters it.
+// Its already ingested requests are kept.
+func (c *Coordinator) RemoveSource(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sources, err := c.sourceRepo.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to load log sources: %w", err)
+	}
+	var source *models.LogSource
+	for _, current := range sources {
+		if current.Name == name {
+			source = current
+			break
+		}
+	}
+	if source == nil {
+		return fmt.Errorf("%w: %s", ErrSourceNotFound, name)
+	}
+	if source.Managed {
+		return fmt.Errorf("%w: %s", ErrSourceManaged, name)
+	}
+
+	// Processors are stopped first so none writes its position back after the delete
+	stopped := c.removeProcessorsLocked(name)
+	if err := c.sourceRepo.Delete(name); err != nil {
+		return fmt.Errorf("failed to unregister log source: %w", err)
+	}
+	c.logger.Info("Unregistered log source", c.logger.Args("source", name, "stopped_processors", stopped))
+	return nil
+}
+
+// sampleLine returns the first non-empty line of the source's file, or "" when there is
+// none to read
+func (c *Coordinator) sampleLine(source *models.LogSource) string {
+	if source.IsGlob() || IsStreamPath(source.Path) {
+		return ""
+	}
+	file, err := os.Open(source.Path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	maxLineLength := c.maxLineLength
+	if maxLineLength <= 0 {
+		maxLineLength = DefaultMaxLineLength
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line
+		}
+	}
+	return ""
+}
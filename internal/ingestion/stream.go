@@ -0,0 +1,163 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package ingestion
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+)
+
+// StdinPath is the source path that reads log lines from standard input
+const StdinPath = "-"
+
+// streamBufferLines bounds how many lines are queued between polls. Once full,
+// the reading goroutine blocks, which in turn applies backpressure to the writer.
+const streamBufferLines = 4096
+
+// IsStreamPath reports whether path is read as a continuous stream (standard
+// input or a named pipe) instead of a seekable file
+func IsStreamPath(path string) bool {
+	if path == StdinPath {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeNamedPipe != 0
+}
+
+// lineStream reads lines from a non-seekable input in the background, so that
+// ReadBatch can drain whatever arrived since the last poll without blocking.
+// There is no position to track: lines are read once, until EOF or Close.
+type lineStream struct {
+	path   string
+	lines  chan string
+	stop   chan struct{}
+	start  sync.Once
+	mu     sync.Mutex
+	file   *os.File // Opened named pipe, closed by Close to unblock a pending read
+	closed bool
+	// Longest line kept, longer ones are skipped
+	maxLineLength int
+	logger        *pterm.Logger
+}
+
+func newLineStream(path string, logger *pterm.Logger) *lineStream {
+	return &lineStream{
+		path:          path,
+		lines:         make(chan string, streamBufferLines),
+		stop:          make(chan struct{}),
+		maxLineLength: DefaultMaxLineLength,
+		logger:        logger,
+	}
+}
+
+// read returns up to maxLines queued lines without waiting for more.
+// The background reader is started on the first call, since opening a
+// named pipe blocks until a writer shows up.
+func (s *lineStream) read(maxLines int) []string {
+	s.start.Do(func() { go s.run() })
+
+	lines := []string{}
+	for len(lines) < maxLines {
+		select {
+		case line := <-s.lines:
+			lines = append(lines, line)
+		default:
+			return lines
+		}
+	}
+	return lines
+}
+
+func (s *lineStream) run() {
+	input := os.Stdin
+	if s.path != StdinPath {
+		file, err := os.Open(s.path)
+		if err != nil {
+			s.logger.WithCaller().Error("Failed to open log stream",
+				s.logger.Args("path", s.path, "error", err))
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			file.Close()
+			return
+		}
+		s.file = file
+		s.mu.Unlock()
+		input = file
+	}
+
+	s.logger.Info("Reading log stream", s.logger.Args("path", s.path))
+
+	reader := bufio.NewReaderSize(input, 64*1024)
+	for {
+		data, n, tooLong, err := readLine(reader, s.maxLineLength)
+		if tooLong {
+			s.logger.Warn("Skipping log line longer than the maximum line length",
+				s.logger.Args("path", s.path, "bytes", n, "max_bytes", s.maxLineLength))
+			data = nil
+		}
+		// A final line without newline is still a complete line once the stream ends
+		if line := strings.TrimRight(string(data), "\r\n"); line != "" {
+			select {
+			case s.lines <- line:
+			case <-s.stop:
+				return
+			}
+		}
+		if err != nil {
+			select {
+			case <-s.stop:
+				// Closed by the processor, the read error is expected
+			default:
+				if err == io.EOF {
+					s.logger.Info("Log stream closed by writer", s.logger.Args("path", s.path))
+				} else {
+					s.logger.WithCaller().Warn("Failed to read log stream",
+						s.logger.Args("path", s.path, "error", err))
+				}
+			}
+			return
+		}
+	}
+}
+
+// close stops the background reader. Standard input is left open, as it is
+// not ours to close; a goroutine blocked reading it exits on the next line.
+func (s *lineStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.stop)
+	if s.file != nil {
+		s.file.Close()
+	}
+}
@@ -0,0 +1,172 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package apache
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// combinedLogPattern matches the Apache combined log format, with the common log
+// format (no referer and user agent) accepted as well:
+//
+//	%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-agent}i" [%D]
+//
+// %D (time taken in microseconds) is often appended to the combined format.
+// Quoted fields may contain backslash-escaped quotes.
+// Capture groups: 1=client, 2=user, 3=timestamp, 4=request line, 5=status,
+// 6=size, 7=referer, 8=user agent, 9=time taken
+const combinedLogPattern = `^(\S+) \S+ (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\d+|-)` +
+	`(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)"(?: (\d+))?)?\s*$`
+
+// timeLayout is the layout of %t
+const timeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// Parser implements the LogParser interface for Apache access logs
+type Parser struct {
+	regex  *regexp.Regexp
+	logger *pterm.Logger
+}
+
+// NewParser creates a new Apache combined log parser
+func NewParser(logger *pterm.Logger) *Parser {
+	return &Parser{
+		regex:  regexp.MustCompile(combinedLogPattern),
+		logger: logger,
+	}
+}
+
+// Name returns the parser name
+func (p *Parser) Name() string {
+	return "apache"
+}
+
+// CanParse checks if the line is an Apache combined or common log line
+func (p *Parser) CanParse(line string) bool {
+	return p.regex.MatchString(line)
+}
+
+// Parse parses an Apache combined log line into an HTTPRequestEvent
+func (p *Parser) Parse(line string) (*HTTPRequestEvent, error) {
+	matches := p.regex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("line does not match Apache combined log format")
+	}
+
+	timestamp, err := time.Parse(timeLayout, matches[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q: %w", matches[3], err)
+	}
+
+	status, _ := strconv.Atoi(matches[5])
+
+	// %b logs "-" instead of 0 when no body was sent
+	var bytes int64
+	if matches[6] != "-" {
+		bytes, _ = strconv.ParseInt(matches[6], 10, 64)
+	}
+
+	var responseTimeMs float64
+	if matches[9] != "" {
+		micros, _ := strconv.ParseInt(matches[9], 10, 64)
+		responseTimeMs = float64(micros) / 1000
+	}
+
+	method, scheme, host, path, query, protocol := parseRequestLine(unescape(matches[4]))
+
+	event := &HTTPRequestEvent{
+		Timestamp:  timestamp,
+		SourceName: "", // Set by processor
+
+		ClientIP:   matches[1],
+		ClientUser: field(matches[2]),
+
+		Method:        method,
+		Protocol:      protocol,
+		Host:          host,
+		Path:          path,
+		QueryString:   query,
+		RequestScheme: scheme,
+
+		StatusCode:     status,
+		ResponseSize:   bytes,
+		ResponseTimeMs: responseTimeMs,
+
+		Duration: int64(responseTimeMs * 1e6), // Convert to nanoseconds
+		StartUTC: timestamp.UTC().Format(time.RFC3339Nano),
+
+		Referer:   field(unescape(matches[7])),
+		UserAgent: field(unescape(matches[8])),
+	}
+
+	p.logger.Trace("Parsed Apache log line",
+		p.logger.Args("client_ip", event.ClientIP, "path", event.Path, "status", event.StatusCode))
+
+	return event, nil
+}
+
+// parseRequestLine splits `METHOD URI PROTOCOL`; absolute URIs (forward proxies) also yield
+// the scheme and host. Requests that timed out before a request line was read are logged
+// as "-" and leave every field empty.
+func parseRequestLine(requestLine string) (method, scheme, host, path, query, protocol string) {
+	parts := strings.Fields(requestLine)
+	if len(parts) < 2 {
+		return "", "", "", "", "", ""
+	}
+	method = parts[0]
+	if len(parts) > 2 {
+		protocol = parts[2]
+	}
+
+	uri := parts[1]
+	if strings.Contains(uri, "://") {
+		if u, err := url.Parse(uri); err == nil {
+			return method, u.Scheme, u.Host, u.EscapedPath(), u.RawQuery, protocol
+		}
+	}
+	if idx := strings.Index(uri, "?"); idx != -1 {
+		return method, "", "", uri[:idx], uri[idx+1:], protocol
+	}
+	return method, "", "", uri, "", protocol
+}
+
+// unescape reverts the backslash escaping Apache applies to quoted fields
+func unescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value)
+}
+
+// field maps the "-" placeholder to ""
+func field(value string) string {
+	if value == "-" {
+		return ""
+	}
+	return value
+}
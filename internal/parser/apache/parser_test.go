@@ -0,0 +1,97 @@
+package apache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+const combinedLine = `203.0.113.7 - alice [16/Oct/2026:10:00:00 +0200] "GET /index.html?lang=en HTTP/1.1" 200 2326 "https://example.org/start" "Mozilla/5.0 (X11; Linux x86_64) \"quoted\"" 1534`
+
+func newTestParser() *Parser {
+	return NewParser(pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace))
+}
+
+func TestParser_Name(t *testing.T) {
+	if name := newTestParser().Name(); name != "apache" {
+		t.Errorf("Expected parser name 'apache', got '%s'", name)
+	}
+}
+
+func TestParser_CanParse(t *testing.T) {
+	parser := newTestParser()
+
+	if !parser.CanParse(combinedLine) {
+		t.Error("Expected parser to accept combined line with %D")
+	}
+	if !parser.CanParse(`192.168.1.5 - - [16/Oct/2026:10:00:00 +0000] "GET / HTTP/1.1" 304 - "-" "curl/8.0"`) {
+		t.Error("Expected parser to accept combined line without %D")
+	}
+	if !parser.CanParse(`192.168.1.5 - - [16/Oct/2026:10:00:00 +0000] "GET / HTTP/1.1" 200 612`) {
+		t.Error("Expected parser to accept common log format")
+	}
+	if parser.CanParse(`{"level":"info","logger":"http.log.access"}`) {
+		t.Error("Expected parser to reject JSON")
+	}
+	if parser.CanParse(`10.0.1.2:33317 [06/Feb/2009:12:14:14.655] fe be/srv 0/0/1/2/3 200 120 - - ---- 1/1/0/0/0 0/0 "GET / HTTP/1.1"`) {
+		t.Error("Expected parser to reject HAProxy log line")
+	}
+}
+
+func TestParser_Parse_Combined(t *testing.T) {
+	event, err := newTestParser().Parse(combinedLine)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+
+	if want := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC); !event.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v, got %v", want, event.Timestamp)
+	}
+	if event.ClientIP != "203.0.113.7" || event.ClientUser != "alice" {
+		t.Errorf("Unexpected client %s (user %s)", event.ClientIP, event.ClientUser)
+	}
+	if event.Method != "GET" || event.Path != "/index.html" || event.QueryString != "lang=en" || event.Protocol != "HTTP/1.1" {
+		t.Errorf("Unexpected request %s %s?%s %s", event.Method, event.Path, event.QueryString, event.Protocol)
+	}
+	if event.StatusCode != 200 || event.ResponseSize != 2326 {
+		t.Errorf("Unexpected status/size %d/%d", event.StatusCode, event.ResponseSize)
+	}
+	if event.ResponseTimeMs != 1.534 || event.Duration != 1534000 {
+		t.Errorf("Expected %%D of 1534us to give 1.534ms, got %vms (%dns)", event.ResponseTimeMs, event.Duration)
+	}
+	if event.Referer != "https://example.org/start" {
+		t.Errorf("Unexpected referer '%s'", event.Referer)
+	}
+	if event.UserAgent != `Mozilla/5.0 (X11; Linux x86_64) "quoted"` {
+		t.Errorf("Expected unescaped user agent, got '%s'", event.UserAgent)
+	}
+}
+
+func TestParser_Parse_Placeholders(t *testing.T) {
+	event, err := newTestParser().Parse(`192.168.1.5 - - [16/Oct/2026:10:00:00 +0000] "-" 408 - "-" "-"`)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+
+	if event.ClientUser != "" || event.Referer != "" || event.UserAgent != "" {
+		t.Errorf("Expected '-' placeholders to be empty, got user '%s', referer '%s', agent '%s'", event.ClientUser, event.Referer, event.UserAgent)
+	}
+	if event.Method != "" || event.Path != "" {
+		t.Errorf("Expected empty request for '-' request line, got %s %s", event.Method, event.Path)
+	}
+	if event.StatusCode != 408 || event.ResponseSize != 0 || event.ResponseTimeMs != 0 {
+		t.Errorf("Unexpected status/size/time %d/%d/%v", event.StatusCode, event.ResponseSize, event.ResponseTimeMs)
+	}
+}
+
+func TestParser_Parse_AbsoluteURI(t *testing.T) {
+	event, err := newTestParser().Parse(`192.168.1.5 - - [16/Oct/2026:10:00:00 +0000] "GET https://proxy.example.com/a/b?x=1 HTTP/1.1" 200 10 "-" "curl/8.0"`)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+
+	if event.Host != "proxy.example.com" || event.RequestScheme != "https" || event.Path != "/a/b" || event.QueryString != "x=1" {
+		t.Errorf("Unexpected absolute URI split %s://%s%s?%s", event.RequestScheme, event.Host, event.Path, event.QueryString)
+	}
+}
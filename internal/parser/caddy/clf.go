@@ -0,0 +1,110 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package caddy
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// caddyCLFPattern matches the {common_log} output of Caddy's transform
+// encoder, optionally followed by the quoted referer and user agent of the
+// combined format:
+//
+//	client - user [02/Jan/2006:15:04:05 -0700] "METHOD URI PROTO" status size ["referer" "user-agent"]
+//
+// Capture groups: 1=client, 2=user, 3=timestamp, 4=method, 5=uri, 6=protocol,
+// 7=status, 8=size, 9=referer, 10=user agent
+const caddyCLFPattern = `^(\S+) \S+ (\S+) \[([^\]]+)\] "(\S+) (\S+) (HTTP/[0-9.]+)" (\d{3}) (\d+|-)(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?`
+
+// clfTimeLayout is the timestamp layout used by {common_log}
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// parseCLF parses a Caddy common or combined log line. CLF carries no timing,
+// TLS or upstream details, so those fields are left empty.
+func (p *Parser) parseCLF(line string) (*CaddyRequestEvent, error) {
+	matches := p.clfRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("line does not match Caddy common log format")
+	}
+
+	timestamp, err := time.Parse(clfTimeLayout, matches[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q: %w", matches[3], err)
+	}
+
+	statusCode, err := strconv.Atoi(matches[7])
+	if err != nil {
+		return nil, fmt.Errorf("invalid status code %q: %w", matches[7], err)
+	}
+
+	var responseSize int64
+	if matches[8] != "-" {
+		responseSize, _ = strconv.ParseInt(matches[8], 10, 64)
+	}
+
+	// Proxied requests may log an absolute URI; keep its host and scheme
+	uri := matches[5]
+	host := ""
+	requestScheme := ""
+	if strings.Contains(uri, "://") {
+		if parsed, err := url.Parse(uri); err == nil {
+			host = parsed.Host
+			requestScheme = parsed.Scheme
+			uri = parsed.RequestURI()
+		}
+	}
+	path, queryString := splitURI(uri)
+
+	return &CaddyRequestEvent{
+		Timestamp:  timestamp,
+		SourceName: "", // Set by processor
+
+		ClientIP:   matches[1],
+		ClientUser: clfField(matches[2]),
+
+		Method:        matches[4],
+		Protocol:      matches[6],
+		Host:          host,
+		Path:          path,
+		QueryString:   queryString,
+		RequestScheme: requestScheme,
+
+		StatusCode:   statusCode,
+		ResponseSize: responseSize,
+		StartUTC:     timestamp.UTC().Format(time.RFC3339Nano),
+
+		Referer:   clfField(matches[9]),
+		UserAgent: clfField(matches[10]),
+	}, nil
+}
+
+// clfField unescapes a quoted CLF value and maps the "-" placeholder to ""
+func clfField(value string) string {
+	if value == "-" {
+		return ""
+	}
+	return strings.ReplaceAll(value, `\"`, `"`)
+}
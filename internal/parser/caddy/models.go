@@ -37,8 +37,9 @@ type CaddyRequestEvent struct {
 	RequestsTotal          int
 
 	// Headers
-	UserAgent string
-	Referer   string
+	UserAgent    string
+	Referer      string
+	ForwardedFor string // X-Forwarded-For chain as received, resolved against TRUSTED_PROXIES at ingestion
 
 	// Proxy/Upstream info
 	BackendName         string
@@ -46,6 +47,7 @@ type CaddyRequestEvent struct {
 	RouterName          string
 	UpstreamStatus      int
 	UpstreamContentType string
+	OriginServer        string // Server response header
 	ClientHostname      string
 
 	// TLS info
@@ -78,3 +80,8 @@ func (e *CaddyRequestEvent) GetTimestamp() time.Time {
 func (e *CaddyRequestEvent) GetSourceName() string {
 	return e.SourceName
 }
+
+// GetForwardedFor returns the raw X-Forwarded-For chain
+func (e *CaddyRequestEvent) GetForwardedFor() string {
+	return e.ForwardedFor
+}
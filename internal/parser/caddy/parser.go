@@ -3,6 +3,8 @@ package caddy
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -10,15 +12,22 @@ import (
 	"github.com/pterm/pterm"
 )
 
+// MaxDuration is the longest request duration stored; longer ones are clamped to it.
+// A hung request logged with an absurd duration would otherwise overflow the
+// nanosecond Duration and wrap to a negative value, corrupting the timing stats.
+const MaxDuration = 24 * time.Hour
+
 // Parser implements the LogParser interface for Caddy access logs
 type Parser struct {
-	logger *pterm.Logger
+	logger   *pterm.Logger
+	clfRegex *regexp.Regexp
 }
 
 // NewParser creates a new Caddy parser instance
 func NewParser(logger *pterm.Logger) *Parser {
 	return &Parser{
-		logger: logger,
+		logger:   logger,
+		clfRegex: regexp.MustCompile(caddyCLFPattern),
 	}
 }
 
@@ -27,11 +36,14 @@ func (p *Parser) Name() string {
 	return "caddy"
 }
 
-// CanParse checks if the log line is in Caddy JSON format
+// CanParse checks if the log line is in Caddy JSON or common/combined log format
 func (p *Parser) CanParse(line string) bool {
-	if len(line) == 0 || line[0] != '{' {
+	if len(line) == 0 {
 		return false
 	}
+	if line[0] != '{' {
+		return p.clfRegex.MatchString(line)
+	}
 
 	var raw map[string]any
 	if err := json.Unmarshal([]byte(line), &raw); err != nil {
@@ -46,8 +58,17 @@ func (p *Parser) CanParse(line string) bool {
 	return hasLogger && strings.HasPrefix(logger, "http.log.access") && hasRequest
 }
 
-// Parse parses a Caddy JSON log line into a CaddyRequestEvent
+// Parse parses a Caddy log line into a CaddyRequestEvent, dispatching on the
+// format: JSON lines always start with '{', anything else is treated as CLF
 func (p *Parser) Parse(line string) (*CaddyRequestEvent, error) {
+	if len(line) > 0 && line[0] != '{' {
+		return p.parseCLF(line)
+	}
+	return p.parseJSON(line)
+}
+
+// parseJSON parses a Caddy JSON access log line
+func (p *Parser) parseJSON(line string) (*CaddyRequestEvent, error) {
 	var raw map[string]any
 	if err := json.Unmarshal([]byte(line), &raw); err != nil {
 		return nil, fmt.Errorf("invalid JSON: %w", err)
@@ -109,16 +130,24 @@ func (p *Parser) Parse(line string) (*CaddyRequestEvent, error) {
 	// Extract status code, response size, duration
 	statusCode := getInt(raw, "status")
 	responseSize := getInt64(raw, "size")
-	duration := getFloat64(raw, "duration")
+	// Out-of-range durations are kept in ProxyMetadata as logged
+	outOfRange := map[string]any{}
+	rawDuration := getFloat64(raw, "duration")
+	duration, ok := sanitizeDuration(rawDuration)
+	if !ok {
+		outOfRange["duration_out_of_range"] = loggedDuration(rawDuration)
+	}
 	responseTimeMs := duration * 1000 // Convert to milliseconds
 
-	// Extract response content type
+	// Extract response content type and the origin software
 	responseContentType := extractResponseHeader(raw, "Content-Type")
+	originServer := extractResponseHeader(raw, "Server")
 
 	// Extract headers
 	headers, _ := request["headers"].(map[string]any)
 	userAgent := extractHeaderArray(headers, "User-Agent")
 	referer := extractHeaderArray(headers, "Referer")
+	forwardedFor := joinHeaderArray(headers, "X-Forwarded-For")
 
 	// Extract upstream info
 	upstream, hasUpstream := raw["upstream"].(map[string]any)
@@ -128,7 +157,11 @@ func (p *Parser) Parse(line string) (*CaddyRequestEvent, error) {
 	if hasUpstream {
 		backendURL = getStringFromMap(upstream, "address")
 		upstreamStatus = getIntFromMap(upstream, "status")
-		upstreamDuration := getFloat64FromMap(upstream, "duration")
+		rawUpstreamDuration := getFloat64FromMap(upstream, "duration")
+		upstreamDuration, ok := sanitizeDuration(rawUpstreamDuration)
+		if !ok {
+			outOfRange["upstream_duration_out_of_range"] = loggedDuration(rawUpstreamDuration)
+		}
 		upstreamResponseTimeMs = upstreamDuration * 1000
 	}
 
@@ -167,23 +200,55 @@ func (p *Parser) Parse(line string) (*CaddyRequestEvent, error) {
 		StartUTC:               timestamp.Format(time.RFC3339Nano),
 		UpstreamResponseTimeMs: upstreamResponseTimeMs,
 
-		UserAgent: userAgent,
-		Referer:   referer,
+		UserAgent:    userAgent,
+		Referer:      referer,
+		ForwardedFor: forwardedFor,
 
 		BackendURL:     backendURL,
 		RouterName:     loggerName,
 		UpstreamStatus: upstreamStatus,
+		OriginServer:   originServer,
 
 		TLSVersion:    tlsVersion,
 		TLSCipher:     tlsCipher,
 		TLSServerName: tlsServerName,
 	}
 
+	if len(outOfRange) > 0 {
+		p.logger.Debug("Clamped out-of-range Caddy duration",
+			p.logger.Args("path", path, "durations", outOfRange))
+		if encoded, err := json.Marshal(outOfRange); err == nil {
+			event.ProxyMetadata = string(encoded)
+		}
+	}
+
 	return event, nil
 }
 
 // Helper functions
 
+// sanitizeDuration bounds a duration in seconds to [0, MaxDuration], so the stored
+// timings are never negative and always fit the nanosecond Duration. It reports false
+// when the duration had to be changed (negative, NaN or too long).
+func sanitizeDuration(seconds float64) (float64, bool) {
+	switch {
+	case math.IsNaN(seconds), seconds < 0:
+		return 0, false
+	case seconds > MaxDuration.Seconds():
+		return MaxDuration.Seconds(), false
+	}
+	return seconds, true
+}
+
+// loggedDuration keeps a logged duration JSON-encodable: NaN and infinities, only
+// reachable through string values, are kept as strings
+func loggedDuration(seconds float64) any {
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return strconv.FormatFloat(seconds, 'g', -1, 64)
+	}
+	return seconds
+}
+
 // parseUnixTimestamp converts a Unix timestamp (float) to time.Time
 func parseUnixTimestamp(ts float64) time.Time {
 	sec := int64(ts)
@@ -260,13 +325,44 @@ func extractHeaderArray(headers map[string]any, name string) string {
 	return value
 }
 
+// joinHeaderArray joins every value of a repeated header with ", ", which is how
+// proxies fold multiple X-Forwarded-For lines into one chain
+func joinHeaderArray(headers map[string]any, name string) string {
+	if headers == nil {
+		return ""
+	}
+
+	headerValue, ok := headers[name].([]any)
+	if !ok {
+		return ""
+	}
+
+	values := make([]string, 0, len(headerValue))
+	for _, v := range headerValue {
+		if s, ok := v.(string); ok && s != "" {
+			values = append(values, s)
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
 // extractResponseHeader extracts a response header value
+// Caddy logs canonical header names, but names set by upstreams or plugins are
+// matched case-insensitively as a fallback
 func extractResponseHeader(raw map[string]any, name string) string {
 	respHeaders, ok := raw["resp_headers"].(map[string]any)
 	if !ok {
 		return ""
 	}
-	return extractHeaderArray(respHeaders, name)
+	if value := extractHeaderArray(respHeaders, name); value != "" {
+		return value
+	}
+	for key := range respHeaders {
+		if strings.EqualFold(key, name) {
+			return extractHeaderArray(respHeaders, key)
+		}
+	}
+	return ""
 }
 
 // Type-safe extraction helpers
@@ -111,6 +111,9 @@ func TestParser_Parse_FullCaddyLog(t *testing.T) {
 	if event.ResponseContentType != "text/html" {
 		t.Errorf("Expected ResponseContentType 'text/html', got '%s'", event.ResponseContentType)
 	}
+	if event.OriginServer != "Apache/2.4.57 (Debian)" {
+		t.Errorf("Expected OriginServer 'Apache/2.4.57 (Debian)', got '%s'", event.OriginServer)
+	}
 
 	// Verify timing
 	expectedDuration := int64(0.00226026 * 1e9)
@@ -165,6 +168,41 @@ func TestParser_Parse_WithUpstream(t *testing.T) {
 	}
 }
 
+func TestParser_Parse_OriginServerHeaderCase(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	parser := NewParser(logger)
+
+	caddyLog := `{"level":"info","ts":1767690562.5659065,"logger":"http.log.access","msg":"handled request","request":{"remote_ip":"192.168.1.100","method":"GET","uri":"/"},"status":200,"size":100,"duration":0.1,"resp_headers":{"server":["nginx/1.25.3"]}}`
+
+	event, err := parser.Parse(caddyLog)
+	if err != nil {
+		t.Fatalf("Failed to parse Caddy log: %v", err)
+	}
+
+	if event.OriginServer != "nginx/1.25.3" {
+		t.Errorf("Expected OriginServer 'nginx/1.25.3', got '%s'", event.OriginServer)
+	}
+}
+
+func TestParser_Parse_ForwardedForChain(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	parser := NewParser(logger)
+
+	caddyLog := `{"level":"info","ts":1767690562.5659065,"logger":"http.log.access","msg":"handled request","request":{"remote_ip":"10.0.0.2","method":"GET","uri":"/","headers":{"X-Forwarded-For":["203.0.113.7, 10.0.0.9","10.0.0.1"]}},"status":200,"size":100,"duration":0.1}`
+
+	event, err := parser.Parse(caddyLog)
+	if err != nil {
+		t.Fatalf("Failed to parse Caddy log: %v", err)
+	}
+
+	if event.ForwardedFor != "203.0.113.7, 10.0.0.9, 10.0.0.1" {
+		t.Errorf("Expected the full X-Forwarded-For chain, got '%s'", event.ForwardedFor)
+	}
+	if event.ClientIP != "10.0.0.2" {
+		t.Errorf("Expected ClientIP to stay the remote IP, got '%s'", event.ClientIP)
+	}
+}
+
 func TestParser_Parse_WithoutTLS(t *testing.T) {
 	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
 	parser := NewParser(logger)
@@ -332,3 +370,134 @@ func TestParser_GetSourceName(t *testing.T) {
 		t.Errorf("Expected source name 'test-source', got '%s'", event.GetSourceName())
 	}
 }
+
+func TestParser_CanParse_CLF(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	parser := NewParser(logger)
+
+	lines := []string{
+		`192.168.1.100 - - [16/Oct/2026:10:00:00 +0000] "GET /index.html HTTP/1.1" 200 1024`,
+		`192.168.1.100 - alice [16/Oct/2026:10:00:00 +0200] "POST /api HTTP/2.0" 201 - "https://example.org/" "curl/8.0"`,
+	}
+	for _, line := range lines {
+		if !parser.CanParse(line) {
+			t.Errorf("Expected parser to accept CLF line %q", line)
+		}
+	}
+
+	if parser.CanParse(`192.168.1.100 GET /index.html 200`) {
+		t.Error("Expected parser to reject malformed CLF line")
+	}
+}
+
+func TestParser_Parse_CommonLog(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	parser := NewParser(logger)
+
+	event, err := parser.Parse(`2001:db8::1 - bob [16/Oct/2026:10:00:00 +0200] "GET /search?q=caddy HTTP/2.0" 404 -`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if event.ClientIP != "2001:db8::1" {
+		t.Errorf("Expected ClientIP '2001:db8::1', got '%s'", event.ClientIP)
+	}
+	if event.ClientUser != "bob" {
+		t.Errorf("Expected ClientUser 'bob', got '%s'", event.ClientUser)
+	}
+	if event.Method != "GET" || event.Protocol != "HTTP/2.0" {
+		t.Errorf("Unexpected method/protocol: %s %s", event.Method, event.Protocol)
+	}
+	if event.Path != "/search" || event.QueryString != "q=caddy" {
+		t.Errorf("Unexpected path/query: %s ? %s", event.Path, event.QueryString)
+	}
+	if event.StatusCode != 404 || event.ResponseSize != 0 {
+		t.Errorf("Unexpected status/size: %d %d", event.StatusCode, event.ResponseSize)
+	}
+	expected := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	if !event.Timestamp.Equal(expected) {
+		t.Errorf("Expected timestamp %v, got %v", expected, event.Timestamp)
+	}
+	if event.Referer != "" || event.UserAgent != "" {
+		t.Errorf("Expected empty referer/user agent, got '%s' / '%s'", event.Referer, event.UserAgent)
+	}
+}
+
+func TestParser_Parse_CombinedLog(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	parser := NewParser(logger)
+
+	event, err := parser.Parse(`10.0.0.5 - - [16/Oct/2026:10:00:00 +0000] "GET https://shop.example.org/cart?id=7 HTTP/1.1" 200 2048 "-" "Mozilla/5.0 \"test\""`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if event.Host != "shop.example.org" || event.RequestScheme != "https" {
+		t.Errorf("Unexpected host/scheme: %s %s", event.Host, event.RequestScheme)
+	}
+	if event.Path != "/cart" || event.QueryString != "id=7" {
+		t.Errorf("Unexpected path/query: %s ? %s", event.Path, event.QueryString)
+	}
+	if event.ResponseSize != 2048 {
+		t.Errorf("Expected ResponseSize 2048, got %d", event.ResponseSize)
+	}
+	if event.Referer != "" {
+		t.Errorf("Expected empty referer, got '%s'", event.Referer)
+	}
+	if event.UserAgent != `Mozilla/5.0 "test"` {
+		t.Errorf("Expected unescaped user agent, got '%s'", event.UserAgent)
+	}
+}
+
+func TestParser_Parse_CLFInvalidTimestamp(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	parser := NewParser(logger)
+
+	_, err := parser.Parse(`10.0.0.5 - - [yesterday] "GET / HTTP/1.1" 200 10`)
+	if err == nil {
+		t.Error("Expected error for invalid CLF timestamp")
+	}
+}
+
+func TestParser_Parse_OutOfRangeDuration(t *testing.T) {
+	parser := NewParser(pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled))
+	line := func(duration, upstream string) string {
+		return `{"level":"info","ts":1767690562.5659065,"logger":"http.log.access","msg":"handled request","request":{"remote_ip":"192.168.1.100","method":"GET","uri":"/"},"status":200,"size":100,"duration":` + duration + `,"upstream":{"address":"localhost:8080","duration":` + upstream + `}}`
+	}
+
+	testCases := []struct {
+		name             string
+		duration         string
+		upstream         string
+		expectedDuration time.Duration
+		expectedMetadata string
+	}{
+		{"in range", "1.5", "1.2", 1500 * time.Millisecond, ""},
+		{"overflows int64 nanoseconds", "1e12", "0.1", MaxDuration, `{"duration_out_of_range":1000000000000}`},
+		{"just above the bound", "86401", "0.1", MaxDuration, `{"duration_out_of_range":86401}`},
+		{"negative", "-3", "-1", 0, `{"duration_out_of_range":-3,"upstream_duration_out_of_range":-1}`},
+		{"infinite string", `"+Inf"`, "0.1", MaxDuration, `{"duration_out_of_range":"+Inf"}`},
+		{"NaN string", `"NaN"`, "0.1", 0, `{"duration_out_of_range":"NaN"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := parser.Parse(line(tc.duration, tc.upstream))
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+			if event.Duration != int64(tc.expectedDuration) {
+				t.Errorf("Expected Duration %d, got %d", int64(tc.expectedDuration), event.Duration)
+			}
+			if event.Duration < 0 || event.ResponseTimeMs < 0 || event.UpstreamResponseTimeMs < 0 {
+				t.Errorf("Timings must never be negative: %d ns, %f ms, upstream %f ms", event.Duration, event.ResponseTimeMs, event.UpstreamResponseTimeMs)
+			}
+			if event.UpstreamResponseTimeMs > float64(MaxDuration.Milliseconds()) {
+				t.Errorf("Upstream response time %f ms exceeds MaxDuration", event.UpstreamResponseTimeMs)
+			}
+			if event.ProxyMetadata != tc.expectedMetadata {
+				t.Errorf("Expected ProxyMetadata %q, got %q", tc.expectedMetadata, event.ProxyMetadata)
+			}
+		})
+	}
+}
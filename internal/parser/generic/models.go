@@ -0,0 +1,87 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package generic
+
+import "time"
+
+// GenericRequestEvent represents a request parsed from an arbitrary JSON log
+// using a user supplied FieldMap. Field names match LogLynx's HTTPRequest model.
+type GenericRequestEvent struct {
+	// Core fields
+	Timestamp  time.Time
+	SourceName string
+
+	// Client info
+	ClientIP   string
+	ClientPort int
+	ClientUser string
+
+	// Request info
+	Method        string
+	Protocol      string
+	Host          string
+	Path          string
+	QueryString   string
+	RequestLength int64
+	RequestScheme string
+
+	// Response info
+	StatusCode          int
+	ResponseSize        int64
+	ResponseTimeMs      float64
+	ResponseContentType string
+
+	// Detailed timing
+	Duration               int64  // Nanoseconds
+	StartUTC               string // RFC3339Nano for hash calculation
+	UpstreamResponseTimeMs float64
+
+	// Headers
+	UserAgent string
+	Referer   string
+
+	// Proxy/Upstream info
+	BackendName    string
+	BackendURL     string
+	RouterName     string
+	UpstreamStatus int
+	OriginServer   string
+
+	// TLS info
+	TLSVersion    string
+	TLSCipher     string
+	TLSServerName string
+
+	// Tracing
+	RequestID string
+	TraceID   string
+}
+
+// GetTimestamp implements the parser.Event interface
+func (e *GenericRequestEvent) GetTimestamp() time.Time {
+	return e.Timestamp
+}
+
+// GetSourceName implements the parser.Event interface
+func (e *GenericRequestEvent) GetSourceName() string {
+	return e.SourceName
+}
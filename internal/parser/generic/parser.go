@@ -0,0 +1,400 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+)
+
+// Timestamp layouts understood in addition to Go time layouts
+const (
+	LayoutUnix   = "unix"    // Seconds since epoch, may be fractional
+	LayoutUnixMs = "unix_ms" // Milliseconds since epoch
+	LayoutUnixNs = "unix_ns" // Nanoseconds since epoch
+)
+
+// FieldMap describes which JSON keys hold each request field.
+// Keys may be dotted paths (e.g. "request.remote_ip") to reach nested objects;
+// a key that literally contains dots is matched before the path is walked.
+type FieldMap struct {
+	Timestamp       string `yaml:"timestamp"`
+	TimestampLayout string `yaml:"timestamp_layout"` // Go layout or unix, unix_ms, unix_ns (default RFC3339)
+
+	ClientIP   string `yaml:"client_ip"`
+	ClientPort string `yaml:"client_port"`
+	ClientUser string `yaml:"client_user"`
+
+	Method        string `yaml:"method"`
+	Protocol      string `yaml:"protocol"`
+	Host          string `yaml:"host"`
+	Path          string `yaml:"path"` // A query string in the value is split off unless query_string is mapped
+	QueryString   string `yaml:"query_string"`
+	RequestLength string `yaml:"request_length"`
+	RequestScheme string `yaml:"request_scheme"`
+
+	StatusCode          string `yaml:"status_code"`
+	ResponseSize        string `yaml:"response_size"`
+	ResponseTime        string `yaml:"response_time"`
+	ResponseTimeUnit    string `yaml:"response_time_unit"` // s, ms, us or ns (default ms)
+	ResponseContentType string `yaml:"response_content_type"`
+
+	UserAgent string `yaml:"user_agent"`
+	Referer   string `yaml:"referer"`
+
+	BackendName    string `yaml:"backend_name"`
+	BackendURL     string `yaml:"backend_url"`
+	RouterName     string `yaml:"router_name"`
+	UpstreamStatus string `yaml:"upstream_status"`
+	OriginServer   string `yaml:"origin_server"` // Server response header of the origin
+
+	TLSVersion    string `yaml:"tls_version"`
+	TLSCipher     string `yaml:"tls_cipher"`
+	TLSServerName string `yaml:"tls_server_name"`
+
+	RequestID string `yaml:"request_id"`
+	TraceID   string `yaml:"trace_id"`
+}
+
+// LoadFieldMap reads a field mapping from a YAML or JSON file path, or from
+// the value itself when it does not point to a file
+func LoadFieldMap(value string) (*FieldMap, error) {
+	data := []byte(value)
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		data, err = os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read field map file: %w", err)
+		}
+	}
+
+	// JSON is valid YAML, so a single decoder handles both formats
+	var fieldMap FieldMap
+	if err := yaml.Unmarshal(data, &fieldMap); err != nil {
+		return nil, fmt.Errorf("invalid field map: %w", err)
+	}
+
+	if err := fieldMap.Validate(); err != nil {
+		return nil, err
+	}
+	return &fieldMap, nil
+}
+
+// Validate checks that the mapping can produce usable requests
+func (m *FieldMap) Validate() error {
+	if m.Timestamp == "" {
+		return fmt.Errorf("field map must define timestamp")
+	}
+	if m.ClientIP == "" {
+		return fmt.Errorf("field map must define client_ip")
+	}
+	switch m.ResponseTimeUnit {
+	case "", "s", "ms", "us", "ns":
+	default:
+		return fmt.Errorf("unsupported response_time_unit: %s", m.ResponseTimeUnit)
+	}
+	return nil
+}
+
+// Parser implements the LogParser interface for arbitrary JSON logs
+type Parser struct {
+	fields *FieldMap
+	logger *pterm.Logger
+}
+
+// NewParser creates a new generic parser using the given field mapping
+func NewParser(fields *FieldMap, logger *pterm.Logger) *Parser {
+	return &Parser{
+		fields: fields,
+		logger: logger,
+	}
+}
+
+// Name returns the parser name
+func (p *Parser) Name() string {
+	return "generic"
+}
+
+// CanParse checks if the line is a JSON object containing the mapped timestamp
+func (p *Parser) CanParse(line string) bool {
+	if len(line) == 0 || line[0] != '{' {
+		return false
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return false
+	}
+
+	_, ok := lookup(raw, p.fields.Timestamp)
+	return ok
+}
+
+// Parse parses a JSON log line into a GenericRequestEvent using the field mapping
+func (p *Parser) Parse(line string) (*GenericRequestEvent, error) {
+	// Numbers stay json.Number so nanosecond timestamps keep full precision
+	var raw map[string]any
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	f := p.fields
+
+	tsValue, ok := lookup(raw, f.Timestamp)
+	if !ok {
+		return nil, fmt.Errorf("missing timestamp field %q", f.Timestamp)
+	}
+	timestamp, err := parseTimestamp(tsValue, f.TimestampLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	clientIP := getString(raw, f.ClientIP)
+	if clientIP == "" {
+		return nil, fmt.Errorf("missing client IP field %q", f.ClientIP)
+	}
+
+	path := getString(raw, f.Path)
+	queryString := getString(raw, f.QueryString)
+	if f.QueryString == "" {
+		if idx := strings.Index(path, "?"); idx != -1 {
+			path, queryString = path[:idx], path[idx+1:]
+		}
+	}
+
+	responseTimeMs := toMilliseconds(getFloat64(raw, f.ResponseTime), f.ResponseTimeUnit)
+
+	event := &GenericRequestEvent{
+		Timestamp:  timestamp,
+		SourceName: "", // Set by processor
+
+		ClientIP:   clientIP,
+		ClientPort: int(getInt64(raw, f.ClientPort)),
+		ClientUser: getString(raw, f.ClientUser),
+
+		Method:        getString(raw, f.Method),
+		Protocol:      getString(raw, f.Protocol),
+		Host:          getString(raw, f.Host),
+		Path:          path,
+		QueryString:   queryString,
+		RequestLength: getInt64(raw, f.RequestLength),
+		RequestScheme: getString(raw, f.RequestScheme),
+
+		StatusCode:          int(getInt64(raw, f.StatusCode)),
+		ResponseSize:        getInt64(raw, f.ResponseSize),
+		ResponseTimeMs:      responseTimeMs,
+		ResponseContentType: getString(raw, f.ResponseContentType),
+
+		Duration: int64(responseTimeMs * 1e6), // Convert to nanoseconds
+		StartUTC: timestamp.Format(time.RFC3339Nano),
+
+		UserAgent: getString(raw, f.UserAgent),
+		Referer:   getString(raw, f.Referer),
+
+		BackendName:    getString(raw, f.BackendName),
+		BackendURL:     getString(raw, f.BackendURL),
+		RouterName:     getString(raw, f.RouterName),
+		UpstreamStatus: int(getInt64(raw, f.UpstreamStatus)),
+		OriginServer:   getString(raw, f.OriginServer),
+
+		TLSVersion:    getString(raw, f.TLSVersion),
+		TLSCipher:     getString(raw, f.TLSCipher),
+		TLSServerName: getString(raw, f.TLSServerName),
+
+		RequestID: getString(raw, f.RequestID),
+		TraceID:   getString(raw, f.TraceID),
+	}
+
+	return event, nil
+}
+
+// Helper functions
+
+// lookup resolves a key in a decoded JSON object. An exact key match wins,
+// otherwise the key is treated as a dotted path into nested objects.
+func lookup(raw map[string]any, key string) (any, bool) {
+	if key == "" {
+		return nil, false
+	}
+	if val, ok := raw[key]; ok {
+		return val, val != nil
+	}
+
+	current := raw
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		val, ok := current[part]
+		if !ok || val == nil {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return val, true
+		}
+		if current, ok = val.(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// parseTimestamp converts a timestamp value using the configured layout
+func parseTimestamp(value any, layout string) (time.Time, error) {
+	switch layout {
+	case LayoutUnixMs, LayoutUnixNs:
+		// Integer layouts: float64 cannot hold current nanosecond timestamps exactly
+		n, ok := toInt64(value)
+		if !ok {
+			return time.Time{}, fmt.Errorf("invalid %s timestamp: %v", layout, value)
+		}
+		if layout == LayoutUnixMs {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(0, n), nil
+	case LayoutUnix:
+		n, ok := toFloat64(value)
+		if !ok {
+			return time.Time{}, fmt.Errorf("invalid %s timestamp: %v", layout, value)
+		}
+		sec := int64(n)
+		return time.Unix(sec, int64((n-float64(sec))*1e9)), nil
+	}
+
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("timestamp is not a string: %v", value)
+	}
+	ts, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	return ts, nil
+}
+
+// toMilliseconds converts a response time in the given unit to milliseconds
+func toMilliseconds(value float64, unit string) float64 {
+	switch unit {
+	case "s":
+		return value * 1000
+	case "us":
+		return value / 1000
+	case "ns":
+		return value / 1e6
+	default:
+		return value
+	}
+}
+
+// Type-safe extraction helpers
+
+func getString(raw map[string]any, key string) string {
+	val, ok := lookup(raw, key)
+	if !ok {
+		return ""
+	}
+	switch v := val.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		// Header-style arrays: use the first value
+		if len(v) > 0 {
+			if s, ok := v[0].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func getInt64(raw map[string]any, key string) int64 {
+	val, ok := lookup(raw, key)
+	if !ok {
+		return 0
+	}
+	n, _ := toInt64(val)
+	return n
+}
+
+func getFloat64(raw map[string]any, key string) float64 {
+	val, ok := lookup(raw, key)
+	if !ok {
+		return 0
+	}
+	n, _ := toFloat64(val)
+	return n
+}
+
+func toFloat64(val any) (float64, bool) {
+	switch v := val.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, true
+		}
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// toInt64 converts integral values without a float64 round trip; fractional values are truncated
+func toInt64(val any) (int64, bool) {
+	switch v := val.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, true
+		}
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n, true
+		}
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+	f, ok := toFloat64(val)
+	return int64(f), ok
+}
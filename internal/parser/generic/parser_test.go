@@ -0,0 +1,152 @@
+package generic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+const testFieldMap = `
+timestamp: time
+timestamp_layout: unix
+client_ip: request.remote_ip
+method: request.method
+host: request.host
+path: request.uri
+status_code: status
+response_time: duration
+response_time_unit: s
+user_agent: request.headers.User-Agent
+`
+
+func newTestParser(t *testing.T) *Parser {
+	t.Helper()
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	fields, err := LoadFieldMap(testFieldMap)
+	if err != nil {
+		t.Fatalf("Failed to load field map: %v", err)
+	}
+	return NewParser(fields, logger)
+}
+
+func TestParser_Name(t *testing.T) {
+	parser := newTestParser(t)
+
+	if parser.Name() != "generic" {
+		t.Errorf("Expected parser name 'generic', got '%s'", parser.Name())
+	}
+}
+
+func TestParser_CanParse(t *testing.T) {
+	parser := newTestParser(t)
+
+	if !parser.CanParse(`{"time":1767690562.5,"request":{"remote_ip":"10.0.0.1"}}`) {
+		t.Error("Expected parser to accept JSON with the mapped timestamp")
+	}
+	if parser.CanParse(`{"ts":1767690562.5}`) {
+		t.Error("Expected parser to reject JSON without the mapped timestamp")
+	}
+	if parser.CanParse(`not a json log`) {
+		t.Error("Expected parser to reject invalid JSON")
+	}
+}
+
+func TestParser_Parse_DottedPaths(t *testing.T) {
+	parser := newTestParser(t)
+
+	line := `{"time":1767690562.5,"status":404,"duration":0.25,"request":{"remote_ip":"10.0.0.1","method":"GET","host":"example.org","uri":"/api/users?page=2","headers":{"User-Agent":["curl/8.0"]}}}`
+
+	event, err := parser.Parse(line)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+
+	if event.ClientIP != "10.0.0.1" {
+		t.Errorf("Expected ClientIP '10.0.0.1', got '%s'", event.ClientIP)
+	}
+	if event.Method != "GET" || event.Host != "example.org" {
+		t.Errorf("Unexpected method/host: %s %s", event.Method, event.Host)
+	}
+	if event.Path != "/api/users" || event.QueryString != "page=2" {
+		t.Errorf("Expected path '/api/users' and query 'page=2', got '%s' and '%s'", event.Path, event.QueryString)
+	}
+	if event.StatusCode != 404 {
+		t.Errorf("Expected StatusCode 404, got %d", event.StatusCode)
+	}
+	if event.ResponseTimeMs != 250 {
+		t.Errorf("Expected ResponseTimeMs 250, got %f", event.ResponseTimeMs)
+	}
+	if event.UserAgent != "curl/8.0" {
+		t.Errorf("Expected UserAgent 'curl/8.0', got '%s'", event.UserAgent)
+	}
+	if want := time.Unix(1767690562, 5e8); !event.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v, got %v", want, event.Timestamp)
+	}
+}
+
+func TestParser_Parse_TimestampLayout(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	fields, err := LoadFieldMap(`{"timestamp":"@timestamp","timestamp_layout":"02/Jan/2006:15:04:05 -0700","client_ip":"ip"}`)
+	if err != nil {
+		t.Fatalf("Failed to load JSON field map: %v", err)
+	}
+	parser := NewParser(fields, logger)
+
+	event, err := parser.Parse(`{"@timestamp":"16/Oct/2026:10:00:00 +0000","ip":"192.168.1.5"}`)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+	if want := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC); !event.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v, got %v", want, event.Timestamp)
+	}
+
+	if _, err := parser.Parse(`{"@timestamp":"2026-10-16","ip":"192.168.1.5"}`); err == nil {
+		t.Error("Expected error for timestamp not matching layout")
+	}
+}
+
+func TestParser_Parse_UnixIntegerLayouts(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	want := time.Unix(1767690562, 123456789)
+
+	tests := []struct {
+		layout string
+		line   string
+		want   time.Time
+	}{
+		// Beyond float64 precision: a float round trip would lose the last digits
+		{LayoutUnixNs, `{"ts":1767690562123456789,"ip":"10.0.0.1","bytes":9007199254740993}`, want},
+		{LayoutUnixNs, `{"ts":"1767690562123456789","ip":"10.0.0.1"}`, want},
+		{LayoutUnixMs, `{"ts":1767690562123,"ip":"10.0.0.1"}`, time.UnixMilli(1767690562123)},
+	}
+
+	for _, tt := range tests {
+		fields, err := LoadFieldMap("timestamp: ts\ntimestamp_layout: " + tt.layout + "\nclient_ip: ip\nresponse_size: bytes\n")
+		if err != nil {
+			t.Fatalf("Failed to load field map: %v", err)
+		}
+		event, err := NewParser(fields, logger).Parse(tt.line)
+		if err != nil {
+			t.Fatalf("Failed to parse %s line: %v", tt.layout, err)
+		}
+		if !event.Timestamp.Equal(tt.want) {
+			t.Errorf("Expected %s timestamp %v, got %v", tt.layout, tt.want.UTC(), event.Timestamp.UTC())
+		}
+	}
+
+	fields, _ := LoadFieldMap("timestamp: ts\ntimestamp_layout: unix_ns\nclient_ip: ip\nresponse_size: bytes\n")
+	event, _ := NewParser(fields, logger).Parse(tests[0].line)
+	if event.ResponseSize != 9007199254740993 {
+		t.Errorf("Expected exact response size 9007199254740993, got %d", event.ResponseSize)
+	}
+}
+
+func TestLoadFieldMap_RequiresTimestampAndClientIP(t *testing.T) {
+	if _, err := LoadFieldMap(`client_ip: ip`); err == nil {
+		t.Error("Expected error for missing timestamp mapping")
+	}
+	if _, err := LoadFieldMap(`timestamp: ts`); err == nil {
+		t.Error("Expected error for missing client_ip mapping")
+	}
+}
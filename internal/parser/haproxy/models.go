@@ -0,0 +1,68 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package haproxy
+
+import "time"
+
+// HTTPRequestEvent represents a request parsed from an HAProxy HTTP log line
+// (option httplog). Field names match LogLynx's HTTPRequest model.
+type HTTPRequestEvent struct {
+	Timestamp  time.Time
+	SourceName string
+
+	// Client info
+	ClientIP   string
+	ClientPort int
+
+	// Request info
+	Method        string
+	Protocol      string
+	Host          string // Only known when the request line carries an absolute URI
+	Path          string
+	QueryString   string
+	RequestScheme string // https when the frontend terminates TLS (name ends with "~")
+
+	// Response info
+	StatusCode     int
+	ResponseSize   int64
+	ResponseTimeMs float64 // Tt/Ta: total time of the request
+
+	// Detailed timing
+	Duration               int64   // Nanoseconds
+	StartUTC               string  // RFC3339Nano for hash calculation
+	UpstreamResponseTimeMs float64 // Tr: time waiting for the server response
+	RetryAttempts          int
+
+	// Proxy/Upstream info
+	BackendName string // HAProxy backend
+	RouterName  string // HAProxy frontend
+}
+
+// GetTimestamp implements the parser.Event interface
+func (e *HTTPRequestEvent) GetTimestamp() time.Time {
+	return e.Timestamp
+}
+
+// GetSourceName implements the parser.Event interface
+func (e *HTTPRequestEvent) GetSourceName() string {
+	return e.SourceName
+}
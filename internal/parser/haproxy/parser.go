@@ -0,0 +1,192 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package haproxy
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// httpLogPattern matches the HAProxy HTTP log format (option httplog):
+//
+//	haproxy[pid]: client:port [accept_date] frontend backend/server TR/Tw/Tc/Tr/Tt status bytes
+//	req_cookie res_cookie termination_state actconn/feconn/beconn/srv_conn/retries
+//	srv_queue/backend_queue {captured request headers} {captured response headers} "request"
+//
+// Anything before "haproxy[pid]:" (the syslog header) is ignored; the whole prefix
+// is optional so lines logged to stdout also match. The client address is greedy
+// up to the last colon, which keeps IPv6 addresses intact.
+const httpLogPattern = `^(?:.*?haproxy\[\d+\]:\s+)?` +
+	`(\S+):(\d+) ` + // client ip:port
+	`\[(\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2}(?:\.\d+)?)\] ` + // accept date
+	`(\S+) ([^/\s]+)/(\S+) ` + // frontend backend/server
+	`(-?\d+)/(-?\d+)/(-?\d+)/(-?\d+)/\+?(-?\d+) ` + // TR/Tw/Tc/Tr/Tt
+	`(-?\d+) \+?(\d+) ` + // status bytes
+	`\S+ \S+ \S+ ` + // cookies, termination state
+	`\d+/\d+/\d+/\d+/\+?(\d+) \d+/\d+` + // connection counts/retries, queues
+	`(?: \{[^}]*\}){0,2}` + // captured headers
+	` "([^"]*)"`
+
+// acceptDateLayout parses the accept date; Go accepts the optional milliseconds
+// after the seconds field even though the layout does not mention them
+const acceptDateLayout = "02/Jan/2006:15:04:05"
+
+// Parser implements the LogParser interface for HAProxy HTTP logs
+type Parser struct {
+	regex    *regexp.Regexp
+	location *time.Location // HAProxy logs local time without an offset
+	logger   *pterm.Logger
+}
+
+// NewParser creates a new HAProxy HTTP log parser
+func NewParser(logger *pterm.Logger) *Parser {
+	return &Parser{
+		regex:    regexp.MustCompile(httpLogPattern),
+		location: time.Local,
+		logger:   logger,
+	}
+}
+
+// Name returns the parser name
+func (p *Parser) Name() string {
+	return "haproxy"
+}
+
+// CanParse checks if the line is an HAProxy HTTP log line
+func (p *Parser) CanParse(line string) bool {
+	return p.regex.MatchString(line)
+}
+
+// Parse parses an HAProxy HTTP log line into an HTTPRequestEvent
+func (p *Parser) Parse(line string) (*HTTPRequestEvent, error) {
+	matches := p.regex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("line does not match HAProxy HTTP log format")
+	}
+
+	timestamp, err := p.parseAcceptDate(matches[3])
+	if err != nil {
+		return nil, err
+	}
+
+	clientPort, _ := strconv.Atoi(matches[2])
+
+	frontend := matches[4]
+	scheme := "http"
+	if strings.HasSuffix(frontend, "~") {
+		frontend = strings.TrimSuffix(frontend, "~")
+		scheme = "https"
+	}
+
+	// Timers are -1 when the corresponding phase never completed
+	totalMs := nonNegative(matches[11])
+	serverMs := nonNegative(matches[10])
+
+	status, _ := strconv.Atoi(matches[12])
+	if status < 0 {
+		status = 0
+	}
+	bytes, _ := strconv.ParseInt(matches[13], 10, 64)
+	retries, _ := strconv.Atoi(matches[14])
+
+	method, host, path, query, protocol := parseRequestLine(matches[15])
+
+	event := &HTTPRequestEvent{
+		Timestamp:  timestamp,
+		SourceName: "", // Set by processor
+
+		ClientIP:   matches[1],
+		ClientPort: clientPort,
+
+		Method:        method,
+		Protocol:      protocol,
+		Host:          host,
+		Path:          path,
+		QueryString:   query,
+		RequestScheme: scheme,
+
+		StatusCode:     status,
+		ResponseSize:   bytes,
+		ResponseTimeMs: totalMs,
+
+		Duration:               int64(totalMs * 1e6), // Convert to nanoseconds
+		StartUTC:               timestamp.UTC().Format(time.RFC3339Nano),
+		UpstreamResponseTimeMs: serverMs,
+		RetryAttempts:          retries,
+
+		BackendName: matches[5],
+		RouterName:  frontend,
+	}
+
+	p.logger.Trace("Parsed HAProxy log line",
+		p.logger.Args("client_ip", event.ClientIP, "backend", event.BackendName, "status", event.StatusCode))
+
+	return event, nil
+}
+
+// parseAcceptDate parses the bracketed accept date in the parser's location
+func (p *Parser) parseAcceptDate(value string) (time.Time, error) {
+	timestamp, err := time.ParseInLocation(acceptDateLayout, value, p.location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid accept date %q: %w", value, err)
+	}
+	return timestamp, nil
+}
+
+// parseRequestLine splits `METHOD URI PROTOCOL`; absolute URIs (HTTP/2, proxies) also yield the host.
+// Requests HAProxy could not parse are logged as "<BADREQ>" and leave every field empty.
+func parseRequestLine(requestLine string) (method, host, path, query, protocol string) {
+	parts := strings.Fields(requestLine)
+	if len(parts) < 2 {
+		return "", "", "", "", ""
+	}
+	method = parts[0]
+	if len(parts) > 2 {
+		protocol = parts[2]
+	}
+
+	uri := parts[1]
+	if strings.Contains(uri, "://") {
+		if u, err := url.Parse(uri); err == nil {
+			return method, u.Host, u.EscapedPath(), u.RawQuery, protocol
+		}
+	}
+	if idx := strings.Index(uri, "?"); idx != -1 {
+		return method, "", uri[:idx], uri[idx+1:], protocol
+	}
+	return method, "", uri, "", protocol
+}
+
+// nonNegative parses a timer in milliseconds, mapping -1 (not reached) to 0
+func nonNegative(value string) float64 {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
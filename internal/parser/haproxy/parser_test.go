@@ -0,0 +1,114 @@
+package haproxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+const syslogLine = `Feb  6 12:14:14 localhost haproxy[14389]: 10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in~ static/srv1 10/0/30/69/109 200 2750 - - ---- 1/1/1/1/2 0/0 {example.org} {} "GET /index.html?lang=en HTTP/1.1"`
+
+func newTestParser() *Parser {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	parser := NewParser(logger)
+	parser.location = time.UTC
+	return parser
+}
+
+func TestParser_Name(t *testing.T) {
+	if name := newTestParser().Name(); name != "haproxy" {
+		t.Errorf("Expected parser name 'haproxy', got '%s'", name)
+	}
+}
+
+func TestParser_CanParse(t *testing.T) {
+	parser := newTestParser()
+
+	if !parser.CanParse(syslogLine) {
+		t.Error("Expected parser to accept HAProxy syslog line")
+	}
+	if !parser.CanParse(`192.168.1.5:5000 [06/Feb/2009:12:14:14.655] fe be/srv 0/0/1/2/3 404 120 - - ---- 1/1/0/0/0 0/0 "GET / HTTP/1.1"`) {
+		t.Error("Expected parser to accept line without syslog prefix")
+	}
+	if parser.CanParse(`{"level":"info","logger":"http.log.access"}`) {
+		t.Error("Expected parser to reject JSON")
+	}
+	if parser.CanParse(`192.168.1.5 - - [06/Feb/2009:12:14:14 +0000] "GET / HTTP/1.1" 200 612`) {
+		t.Error("Expected parser to reject common log format")
+	}
+}
+
+func TestParser_Parse_HTTPLog(t *testing.T) {
+	event, err := newTestParser().Parse(syslogLine)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+
+	if want := time.Date(2009, 2, 6, 12, 14, 14, 655e6, time.UTC); !event.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v, got %v", want, event.Timestamp)
+	}
+	if event.ClientIP != "10.0.1.2" || event.ClientPort != 33317 {
+		t.Errorf("Unexpected client %s:%d", event.ClientIP, event.ClientPort)
+	}
+	if event.RouterName != "http-in" || event.BackendName != "static" {
+		t.Errorf("Unexpected frontend/backend %s/%s", event.RouterName, event.BackendName)
+	}
+	if event.RequestScheme != "https" {
+		t.Errorf("Expected https scheme for SSL frontend, got '%s'", event.RequestScheme)
+	}
+	if event.Method != "GET" || event.Path != "/index.html" || event.QueryString != "lang=en" || event.Protocol != "HTTP/1.1" {
+		t.Errorf("Unexpected request %s %s ? %s %s", event.Method, event.Path, event.QueryString, event.Protocol)
+	}
+	if event.StatusCode != 200 || event.ResponseSize != 2750 {
+		t.Errorf("Unexpected status/bytes %d/%d", event.StatusCode, event.ResponseSize)
+	}
+	if event.ResponseTimeMs != 109 || event.UpstreamResponseTimeMs != 69 {
+		t.Errorf("Expected Tt 109 and Tr 69, got %f and %f", event.ResponseTimeMs, event.UpstreamResponseTimeMs)
+	}
+	if event.Duration != 109e6 {
+		t.Errorf("Expected Duration 109ms in nanoseconds, got %d", event.Duration)
+	}
+	if event.RetryAttempts != 2 {
+		t.Errorf("Expected 2 retries, got %d", event.RetryAttempts)
+	}
+}
+
+func TestParser_Parse_IPv6AndAbortedRequest(t *testing.T) {
+	line := `haproxy[1]: 2001:db8::7:40112 [16/Oct/2026:10:00:00.001] fe be/<NOSRV> -1/-1/-1/-1/+5 -1 +0 - - CR-- 2/2/0/0/0 0/0 "GET https://api.example.org/v1/users?id=3 HTTP/2.0"`
+
+	event, err := newTestParser().Parse(line)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+	if event.ClientIP != "2001:db8::7" || event.ClientPort != 40112 {
+		t.Errorf("Unexpected client %s:%d", event.ClientIP, event.ClientPort)
+	}
+	if event.StatusCode != 0 || event.UpstreamResponseTimeMs != 0 || event.ResponseTimeMs != 5 {
+		t.Errorf("Unexpected status/timers %d %f %f", event.StatusCode, event.UpstreamResponseTimeMs, event.ResponseTimeMs)
+	}
+	if event.Host != "api.example.org" || event.Path != "/v1/users" || event.QueryString != "id=3" {
+		t.Errorf("Unexpected absolute URI split %s %s %s", event.Host, event.Path, event.QueryString)
+	}
+	if event.RequestScheme != "http" {
+		t.Errorf("Expected http scheme, got '%s'", event.RequestScheme)
+	}
+}
+
+func TestParser_Parse_BadRequest(t *testing.T) {
+	line := `haproxy[1]: 10.0.0.9:1234 [16/Oct/2026:10:00:00.001] fe fe/<NOSRV> -1/-1/-1/-1/0 400 187 - - PR-- 1/1/0/0/0 0/0 "<BADREQ>"`
+
+	event, err := newTestParser().Parse(line)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+	if event.Method != "" || event.Path != "" || event.StatusCode != 400 {
+		t.Errorf("Unexpected bad request fields %q %q %d", event.Method, event.Path, event.StatusCode)
+	}
+}
+
+func TestParser_Parse_Invalid(t *testing.T) {
+	if _, err := newTestParser().Parse("not an haproxy line"); err == nil {
+		t.Error("Expected error for non-matching line")
+	}
+}
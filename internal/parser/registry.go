@@ -23,7 +23,10 @@ package parsers
 
 import (
 	"fmt"
+	"loglynx/internal/parser/apache"
 	"loglynx/internal/parser/caddy"
+	"loglynx/internal/parser/generic"
+	"loglynx/internal/parser/haproxy"
 	"loglynx/internal/parser/traefik"
 
 	"github.com/pterm/pterm"
@@ -55,6 +58,36 @@ func (w *caddyParserWrapper) Parse(line string) (Event, error) {
 	return w.Parser.Parse(line)
 }
 
+// genericParserWrapper wraps generic.Parser to implement LogParser interface
+type genericParserWrapper struct {
+	*generic.Parser
+}
+
+// Parse adapts generic.Parser.Parse to return Event interface
+func (w *genericParserWrapper) Parse(line string) (Event, error) {
+	return w.Parser.Parse(line)
+}
+
+// haproxyParserWrapper wraps haproxy.Parser to implement LogParser interface
+type haproxyParserWrapper struct {
+	*haproxy.Parser
+}
+
+// Parse adapts haproxy.Parser.Parse to return Event interface
+func (w *haproxyParserWrapper) Parse(line string) (Event, error) {
+	return w.Parser.Parse(line)
+}
+
+// apacheParserWrapper wraps apache.Parser to implement LogParser interface
+type apacheParserWrapper struct {
+	*apache.Parser
+}
+
+// Parse adapts apache.Parser.Parse to return Event interface
+func (w *apacheParserWrapper) Parse(line string) (Event, error) {
+	return w.Parser.Parse(line)
+}
+
 // NewRegistry creates a new parser registry with all built-in parsers
 func NewRegistry(logger *pterm.Logger) *Registry {
 	registry := &Registry{
@@ -71,6 +104,14 @@ func NewRegistry(logger *pterm.Logger) *Registry {
 	registry.Register("caddy", &caddyParserWrapper{caddyParser})
 	logger.Debug("Registered parser", logger.Args("type", "caddy"))
 
+	haproxyParser := haproxy.NewParser(logger)
+	registry.Register("haproxy", &haproxyParserWrapper{haproxyParser})
+	logger.Debug("Registered parser", logger.Args("type", "haproxy"))
+
+	apacheParser := apache.NewParser(logger)
+	registry.Register("apache", &apacheParserWrapper{apacheParser})
+	logger.Debug("Registered parser", logger.Args("type", "apache"))
+
 	return registry
 }
 
@@ -79,6 +120,19 @@ func (r *Registry) Register(name string, parser LogParser) {
 	r.parsers[name] = parser
 }
 
+// RegisterGeneric registers the generic JSON parser using a field map given
+// inline (YAML or JSON) or as a path to a mapping file
+func (r *Registry) RegisterGeneric(fieldMap string) error {
+	fields, err := generic.LoadFieldMap(fieldMap)
+	if err != nil {
+		return err
+	}
+
+	r.Register("generic", &genericParserWrapper{generic.NewParser(fields, r.logger)})
+	r.logger.Debug("Registered parser", r.logger.Args("type", "generic"))
+	return nil
+}
+
 // Get retrieves a parser by type
 func (r *Registry) Get(parserType string) (LogParser, error) {
 	parser, exists := r.parsers[parserType]
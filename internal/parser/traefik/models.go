@@ -61,6 +61,7 @@ type HTTPRequestEvent struct {
 	// Headers
 	UserAgent      string
 	Referer        string
+	ForwardedFor   string // X-Forwarded-For chain as received, resolved against TRUSTED_PROXIES at ingestion
 
 	// Proxy/Upstream info
 	BackendName         string
@@ -68,6 +69,7 @@ type HTTPRequestEvent struct {
 	RouterName          string
 	UpstreamStatus      int
 	UpstreamContentType string // origin_Content-Type
+	OriginServer        string // origin_Server (falls back to downstream_Server)
 	ClientHostname      string // ClientHost field (may contain hostname)
 
 	// TLS info
@@ -97,4 +99,9 @@ func (e *HTTPRequestEvent) GetTimestamp() time.Time {
 
 func (e *HTTPRequestEvent) GetSourceName() string {
 	return e.SourceName
+}
+
+// GetForwardedFor returns the raw X-Forwarded-For chain
+func (e *HTTPRequestEvent) GetForwardedFor() string {
+	return e.ForwardedFor
 }
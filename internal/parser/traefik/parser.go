@@ -241,6 +241,12 @@ func (p *Parser) parseJSON(line string) (*HTTPRequestEvent, error) {
 
 	redirectTarget := extractRedirectTarget(queryString)
 
+	// Origin software: the backend's Server header, or the one sent to the client
+	originServer := getString(raw, "origin_Server")
+	if originServer == "" {
+		originServer = getString(raw, "downstream_Server")
+	}
+
 	// Build complete event
 	event := &HTTPRequestEvent{
 		Timestamp:  timestamp,
@@ -272,14 +278,16 @@ func (p *Parser) parseJSON(line string) (*HTTPRequestEvent, error) {
 		RequestsTotal: getInt(raw, "RequestsTotal"), // Total requests at router level (defaults to 0 if not present)
 
 		// Headers
-		UserAgent: getString(raw, "request_User-Agent"),
-		Referer:   getString(raw, "request_Referer"),
+		UserAgent:    getString(raw, "request_User-Agent"),
+		Referer:      getString(raw, "request_Referer"),
+		ForwardedFor: getString(raw, "request_X-Forwarded-For"),
 
 		// Traefik-specific (may not be present)
 		BackendName:         getString(raw, "ServiceName"),
 		BackendURL:          getString(raw, "backend_URL"),
 		RouterName:          getString(raw, "router_Name"),
 		UpstreamContentType: getString(raw, "origin_Content-Type"),
+		OriginServer:        originServer,
 
 		// TLS info
 		TLSVersion: getString(raw, "TLSVersion"),
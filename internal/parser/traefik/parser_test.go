@@ -117,6 +117,33 @@ func TestParser_ParseJSON(t *testing.T) {
 	}
 }
 
+func TestParser_ParseJSON_OriginServer(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
+	parser := NewParser(logger)
+
+	tests := []struct {
+		name     string
+		log      string
+		expected string
+	}{
+		{"origin header", `{"ClientHost":"10.0.0.1","DownstreamStatus":200,"RequestMethod":"GET","RequestPath":"/","origin_Server":"gunicorn","downstream_Server":"traefik","time":"2026-01-06T10:00:00Z"}`, "gunicorn"},
+		{"downstream fallback", `{"ClientHost":"10.0.0.1","DownstreamStatus":200,"RequestMethod":"GET","RequestPath":"/","downstream_Server":"Apache/2.4.57","time":"2026-01-06T10:00:00Z"}`, "Apache/2.4.57"},
+		{"absent", `{"ClientHost":"10.0.0.1","DownstreamStatus":200,"RequestMethod":"GET","RequestPath":"/","time":"2026-01-06T10:00:00Z"}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := parser.Parse(tt.log)
+			if err != nil {
+				t.Fatalf("Failed to parse JSON log: %v", err)
+			}
+			if event.OriginServer != tt.expected {
+				t.Errorf("Expected OriginServer '%s', got '%s'", tt.expected, event.OriginServer)
+			}
+		})
+	}
+}
+
 func TestParser_ParseTraefikCLF(t *testing.T) {
 	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelTrace)
 	parser := NewParser(logger)
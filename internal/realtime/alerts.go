@@ -0,0 +1,314 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// Metric names understood by alert rules
+const (
+	AlertMetricRequestRate     = "request_rate"      // req/sec over the rate window
+	AlertMetricErrorRate       = "error_rate"        // 4xx+5xx/sec over the rate window
+	AlertMetricServerErrorRate = "server_error_rate" // 5xx/sec averaged over the buffer duration
+	AlertMetricAvgResponseTime = "avg_response_time" // ms
+)
+
+// Alert states reported to notifiers
+const (
+	AlertStateFiring   = "firing"
+	AlertStateResolved = "resolved"
+)
+
+// AlertRule describes a threshold condition evaluated on every metrics tick
+type AlertRule struct {
+	Name      string
+	Metric    string
+	Threshold float64
+	Below     bool          // Fire when the metric is <= Threshold instead of > Threshold
+	For       time.Duration // How long the condition must hold before the alert fires
+
+	// RequireActivity only arms the rule after traffic has been seen, so a
+	// "request rate dropped to zero" rule doesn't fire on an idle startup
+	RequireActivity bool
+}
+
+// Alert is a fired (or resolved) instance of a rule
+type Alert struct {
+	Rule       string     `json:"rule"`
+	Metric     string     `json:"metric"`
+	State      string     `json:"state"`
+	Value      float64    `json:"value"`
+	Threshold  float64    `json:"threshold"`
+	Message    string     `json:"message"`
+	Since      time.Time  `json:"since"` // When the condition started holding
+	FiredAt    time.Time  `json:"fired_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Notifier delivers alert state changes to an external system
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// WebhookNotifier POSTs alerts as JSON to a configured URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier with the given request timeout
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Notify sends the alert to the webhook URL
+func (w *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LogLynx-Alerts")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ruleState tracks the evaluation progress of a single rule
+type ruleState struct {
+	rule         AlertRule
+	pendingSince time.Time // Zero when the condition currently doesn't hold
+	active       *Alert
+	lastFired    time.Time
+}
+
+// AlertEngine evaluates alert rules against realtime metrics and notifies on state changes
+type AlertEngine struct {
+	logger    *pterm.Logger
+	notifiers []Notifier
+	cooldown  time.Duration // Minimum time between two firings of the same rule
+	timeout   time.Duration // Per-notification delivery timeout
+	buffer    time.Duration // Window the 5xx count of a snapshot covers
+
+	mu          sync.RWMutex
+	rules       []*ruleState
+	seenTraffic bool
+}
+
+// NewAlertEngine creates an alert engine for the given rules
+func NewAlertEngine(logger *pterm.Logger, rules []AlertRule, cooldown time.Duration, notifiers ...Notifier) *AlertEngine {
+	states := make([]*ruleState, 0, len(rules))
+	for _, rule := range rules {
+		states = append(states, &ruleState{rule: rule})
+	}
+	return &AlertEngine{
+		logger:    logger,
+		notifiers: notifiers,
+		cooldown:  cooldown,
+		timeout:   10 * time.Second,
+		buffer:    DefaultBufferDuration,
+		rules:     states,
+	}
+}
+
+// SetBufferDuration sets the window the observed snapshots count status codes over,
+// matching the metrics collector's buffer duration
+func (e *AlertEngine) SetBufferDuration(buffer time.Duration) {
+	if buffer > 0 {
+		e.buffer = buffer
+	}
+}
+
+// RuleCount returns the number of configured rules
+func (e *AlertEngine) RuleCount() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.rules)
+}
+
+// Observe evaluates all rules against a metrics snapshot
+// Intended to be registered with MetricsCollector.Subscribe
+func (e *AlertEngine) Observe(metrics *RealtimeMetrics) {
+	now := metrics.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var changes []Alert
+
+	e.mu.Lock()
+	if metrics.RequestRate > 0 {
+		e.seenTraffic = true
+	}
+	for _, state := range e.rules {
+		if alert, changed := e.evaluate(state, metrics, now); changed {
+			changes = append(changes, alert)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, alert := range changes {
+		e.dispatch(alert)
+	}
+}
+
+// evaluate advances a rule's state machine, returning the alert when it fired or resolved
+// Caller must hold e.mu
+func (e *AlertEngine) evaluate(state *ruleState, metrics *RealtimeMetrics, now time.Time) (Alert, bool) {
+	rule := state.rule
+	value := metricValue(rule.Metric, metrics, e.buffer)
+
+	holds := value > rule.Threshold
+	if rule.Below {
+		holds = value <= rule.Threshold
+	}
+	if rule.RequireActivity && !e.seenTraffic {
+		holds = false
+	}
+
+	if !holds {
+		state.pendingSince = time.Time{}
+		if state.active == nil {
+			return Alert{}, false
+		}
+		resolved := *state.active
+		resolvedAt := now
+		resolved.State = AlertStateResolved
+		resolved.Value = value
+		resolved.ResolvedAt = &resolvedAt
+		resolved.Message = fmt.Sprintf("%s resolved: %s is %.2f", rule.Name, rule.Metric, value)
+		state.active = nil
+		return resolved, true
+	}
+
+	if state.pendingSince.IsZero() {
+		state.pendingSince = now
+	}
+	if state.active != nil {
+		state.active.Value = value
+		return Alert{}, false
+	}
+	if now.Sub(state.pendingSince) < rule.For {
+		return Alert{}, false
+	}
+	if !state.lastFired.IsZero() && now.Sub(state.lastFired) < e.cooldown {
+		// Flapping condition: stay quiet until the cooldown expires
+		return Alert{}, false
+	}
+
+	comparison := "above"
+	if rule.Below {
+		comparison = "at or below"
+	}
+	alert := &Alert{
+		Rule:      rule.Name,
+		Metric:    rule.Metric,
+		State:     AlertStateFiring,
+		Value:     value,
+		Threshold: rule.Threshold,
+		Message:   fmt.Sprintf("%s: %s is %.2f, %s threshold %.2f for %s", rule.Name, rule.Metric, value, comparison, rule.Threshold, rule.For),
+		Since:     state.pendingSince,
+		FiredAt:   now,
+	}
+	state.active = alert
+	state.lastFired = now
+	return *alert, true
+}
+
+// metricValue extracts the named metric from a snapshot whose status codes are counted over buffer
+func metricValue(metric string, metrics *RealtimeMetrics, buffer time.Duration) float64 {
+	switch metric {
+	case AlertMetricRequestRate:
+		return metrics.RequestRate
+	case AlertMetricErrorRate:
+		return metrics.ErrorRate
+	case AlertMetricServerErrorRate:
+		return float64(metrics.Status5xx) / buffer.Seconds()
+	case AlertMetricAvgResponseTime:
+		return metrics.AvgResponseTime
+	default:
+		return 0
+	}
+}
+
+// dispatch logs the state change and delivers it to every notifier without blocking the collector
+func (e *AlertEngine) dispatch(alert Alert) {
+	if alert.State == AlertStateFiring {
+		e.logger.Warn("Alert firing", e.logger.Args("rule", alert.Rule, "value", alert.Value, "threshold", alert.Threshold))
+	} else {
+		e.logger.Info("Alert resolved", e.logger.Args("rule", alert.Rule, "value", alert.Value))
+	}
+
+	for _, notifier := range e.notifiers {
+		go func(n Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+			defer cancel()
+			if err := n.Notify(ctx, alert); err != nil {
+				e.logger.Warn("Failed to deliver alert notification",
+					e.logger.Args("rule", alert.Rule, "state", alert.State, "error", err))
+			}
+		}(notifier)
+	}
+}
+
+// GetActiveAlerts returns the currently firing alerts, oldest first
+func (e *AlertEngine) GetActiveAlerts() []Alert {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	alerts := make([]Alert, 0)
+	for _, state := range e.rules {
+		if state.active != nil {
+			alerts = append(alerts, *state.active)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].FiredAt.Before(alerts[j].FiredAt)
+	})
+	return alerts
+}
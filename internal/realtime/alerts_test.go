@@ -0,0 +1,153 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, alert Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func (r *recordingNotifier) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.alerts)
+}
+
+func tick(engine *AlertEngine, at time.Time, m RealtimeMetrics) {
+	m.Timestamp = at
+	engine.Observe(&m)
+}
+
+func TestAlertEngine(t *testing.T) {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	start := time.Now()
+	highErrors := RealtimeMetrics{RequestRate: 10, Status5xx: 120} // 2 5xx/sec over the minute
+	healthy := RealtimeMetrics{RequestRate: 10}
+
+	serverErrorRule := AlertRule{Name: "high_5xx_rate", Metric: AlertMetricServerErrorRate, Threshold: 1, For: 10 * time.Second}
+
+	t.Run("fires only after the condition holds for the duration", func(t *testing.T) {
+		engine := NewAlertEngine(logger, []AlertRule{serverErrorRule}, time.Minute)
+
+		tick(engine, start, highErrors)
+		tick(engine, start.Add(5*time.Second), highErrors)
+		assert.Empty(t, engine.GetActiveAlerts())
+
+		tick(engine, start.Add(10*time.Second), highErrors)
+		active := engine.GetActiveAlerts()
+		assert.Len(t, active, 1)
+		assert.Equal(t, "high_5xx_rate", active[0].Rule)
+		assert.Equal(t, AlertStateFiring, active[0].State)
+		assert.Equal(t, 2.0, active[0].Value)
+		assert.Equal(t, start, active[0].Since)
+	})
+
+	t.Run("a brief dip resets the pending condition", func(t *testing.T) {
+		engine := NewAlertEngine(logger, []AlertRule{serverErrorRule}, time.Minute)
+
+		tick(engine, start, highErrors)
+		tick(engine, start.Add(5*time.Second), healthy)
+		tick(engine, start.Add(10*time.Second), highErrors)
+		tick(engine, start.Add(15*time.Second), highErrors)
+		assert.Empty(t, engine.GetActiveAlerts())
+	})
+
+	t.Run("cooldown suppresses a flapping condition", func(t *testing.T) {
+		engine := NewAlertEngine(logger, []AlertRule{{Name: "errors", Metric: AlertMetricErrorRate, Threshold: 1}}, time.Minute)
+
+		tick(engine, start, RealtimeMetrics{ErrorRate: 5})
+		assert.Len(t, engine.GetActiveAlerts(), 1)
+
+		tick(engine, start.Add(time.Second), RealtimeMetrics{})
+		assert.Empty(t, engine.GetActiveAlerts())
+
+		tick(engine, start.Add(2*time.Second), RealtimeMetrics{ErrorRate: 5})
+		assert.Empty(t, engine.GetActiveAlerts(), "re-firing within the cooldown must be suppressed")
+
+		tick(engine, start.Add(2*time.Minute), RealtimeMetrics{ErrorRate: 5})
+		assert.Len(t, engine.GetActiveAlerts(), 1)
+	})
+
+	t.Run("traffic drop requires prior activity", func(t *testing.T) {
+		rule := AlertRule{Name: "traffic_stopped", Metric: AlertMetricRequestRate, Threshold: 0, Below: true, For: time.Minute, RequireActivity: true}
+		engine := NewAlertEngine(logger, []AlertRule{rule}, time.Minute)
+
+		tick(engine, start, RealtimeMetrics{})
+		tick(engine, start.Add(2*time.Minute), RealtimeMetrics{})
+		assert.Empty(t, engine.GetActiveAlerts(), "idle since startup is not a drop")
+
+		tick(engine, start.Add(3*time.Minute), healthy)
+		tick(engine, start.Add(4*time.Minute), RealtimeMetrics{})
+		tick(engine, start.Add(5*time.Minute), RealtimeMetrics{})
+		assert.Len(t, engine.GetActiveAlerts(), 1)
+	})
+
+	t.Run("notifies on fire and resolve", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		engine := NewAlertEngine(logger, []AlertRule{serverErrorRule}, time.Minute, notifier)
+
+		tick(engine, start, highErrors)
+		tick(engine, start.Add(10*time.Second), highErrors)
+		tick(engine, start.Add(20*time.Second), healthy)
+
+		assert.Eventually(t, func() bool { return notifier.count() == 2 }, time.Second, 10*time.Millisecond)
+		notifier.mu.Lock()
+		defer notifier.mu.Unlock()
+		states := map[string]bool{}
+		for _, alert := range notifier.alerts {
+			states[alert.State] = true
+			if alert.State == AlertStateResolved {
+				assert.NotNil(t, alert.ResolvedAt)
+			}
+		}
+		assert.True(t, states[AlertStateFiring])
+		assert.True(t, states[AlertStateResolved])
+	})
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Run("posts the alert as JSON", func(t *testing.T) {
+		var received Alert
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		notifier := NewWebhookNotifier(server.URL, time.Second)
+		err := notifier.Notify(context.Background(), Alert{Rule: "high_5xx_rate", State: AlertStateFiring, Value: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, "high_5xx_rate", received.Rule)
+		assert.Equal(t, 3.0, received.Value)
+	})
+
+	t.Run("non-2xx responses are errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		err := NewWebhookNotifier(server.URL, time.Second).Notify(context.Background(), Alert{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,277 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package realtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// BinaryContentType is the media type clients send in the Accept header
+	// to receive compact binary frames instead of JSON
+	BinaryContentType = "application/x-loglynx-metrics"
+
+	// binaryMagic identifies a LogLynx binary metrics frame
+	binaryMagic = "LLX"
+	// binaryVersion is bumped whenever the frame layout changes
+	binaryVersion byte = 2
+)
+
+// ErrInvalidBinaryFrame is returned when a frame cannot be decoded
+var ErrInvalidBinaryFrame = errors.New("invalid binary metrics frame")
+
+// EncodeBinary encodes metrics into a compact binary frame.
+//
+// Layout (all integers are varints, floats are IEEE-754 little endian, strings are
+// uvarint length-prefixed): magic "LLX", version byte, scalar fields in struct order,
+// timestamp as unix milliseconds, then the TopIPs, LatestRequests and PerService lists,
+// each prefixed with its element count, and finally the replay flag (uvarint 0 or 1).
+func EncodeBinary(metrics *RealtimeMetrics) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 512))
+	w := binaryWriter{buf: buf}
+
+	buf.WriteString(binaryMagic)
+	buf.WriteByte(binaryVersion)
+
+	w.float(metrics.RequestRate)
+	w.float(metrics.ErrorRate)
+	w.float(metrics.BandwidthRate)
+	w.float(metrics.AvgResponseTime)
+	w.varint(int64(metrics.ActiveConnections))
+	w.varint(metrics.Status2xx)
+	w.varint(metrics.Status4xx)
+	w.varint(metrics.Status5xx)
+	w.varint(metrics.Timestamp.UnixMilli())
+
+	w.uvarint(uint64(len(metrics.TopIPs)))
+	for _, ip := range metrics.TopIPs {
+		w.str(ip.IP)
+		w.str(ip.Country)
+		w.float(ip.RequestRate)
+		w.varint(ip.Bandwidth)
+		w.float(ip.BandwidthRate)
+	}
+
+	w.uvarint(uint64(len(metrics.LatestRequests)))
+	for _, req := range metrics.LatestRequests {
+		w.uvarint(uint64(req.ID))
+		w.varint(req.Timestamp.UnixMilli())
+		w.str(req.Method)
+		w.str(req.Host)
+		w.str(req.BackendName)
+		w.str(req.Path)
+		w.varint(int64(req.StatusCode))
+		w.float(req.ResponseTimeMs)
+		w.str(req.GeoCountry)
+		w.str(req.ClientIP)
+	}
+
+	w.uvarint(uint64(len(metrics.PerService)))
+	for _, svc := range metrics.PerService {
+		w.str(svc.ServiceName)
+		w.float(svc.RequestRate)
+		w.float(svc.BandwidthRate)
+	}
+
+	var replay uint64
+	if metrics.Replay {
+		replay = 1
+	}
+	w.uvarint(replay)
+
+	return buf.Bytes()
+}
+
+// DecodeBinary decodes a frame produced by EncodeBinary
+func DecodeBinary(data []byte) (*RealtimeMetrics, error) {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, ErrInvalidBinaryFrame
+	}
+	if v := data[len(binaryMagic)]; v != binaryVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBinaryFrame, v)
+	}
+
+	r := binaryReader{r: bytes.NewReader(data[len(binaryMagic)+1:])}
+	metrics := &RealtimeMetrics{
+		RequestRate:       r.float(),
+		ErrorRate:         r.float(),
+		BandwidthRate:     r.float(),
+		AvgResponseTime:   r.float(),
+		ActiveConnections: int(r.varint()),
+		Status2xx:         r.varint(),
+		Status4xx:         r.varint(),
+		Status5xx:         r.varint(),
+		Timestamp:         time.UnixMilli(r.varint()),
+	}
+
+	if n := r.count(); n > 0 {
+		metrics.TopIPs = make([]IPMetrics, n)
+		for i := range metrics.TopIPs {
+			metrics.TopIPs[i] = IPMetrics{
+				IP:            r.str(),
+				Country:       r.str(),
+				RequestRate:   r.float(),
+				Bandwidth:     r.varint(),
+				BandwidthRate: r.float(),
+			}
+		}
+	}
+
+	metrics.LatestRequests = make([]RequestSummary, r.count())
+	for i := range metrics.LatestRequests {
+		metrics.LatestRequests[i] = RequestSummary{
+			ID:             uint(r.uvarint()),
+			Timestamp:      time.UnixMilli(r.varint()),
+			Method:         r.str(),
+			Host:           r.str(),
+			BackendName:    r.str(),
+			Path:           r.str(),
+			StatusCode:     int(r.varint()),
+			ResponseTimeMs: r.float(),
+			GeoCountry:     r.str(),
+			ClientIP:       r.str(),
+		}
+	}
+
+	if n := r.count(); n > 0 {
+		metrics.PerService = make([]ServiceMetrics, n)
+		for i := range metrics.PerService {
+			metrics.PerService[i] = ServiceMetrics{
+				ServiceName:   r.str(),
+				RequestRate:   r.float(),
+				BandwidthRate: r.float(),
+			}
+		}
+	}
+
+	metrics.Replay = r.uvarint() == 1
+
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBinaryFrame, r.err)
+	}
+	return metrics, nil
+}
+
+// WriteBinaryFrame writes a frame prefixed with its length as a big-endian uint32,
+// so clients can split a continuous stream back into individual frames
+func WriteBinaryFrame(w io.Writer, frame []byte) error {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(frame)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// binaryWriter appends primitive values to a buffer
+type binaryWriter struct {
+	buf     *bytes.Buffer
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (w *binaryWriter) varint(v int64) {
+	n := binary.PutVarint(w.scratch[:], v)
+	w.buf.Write(w.scratch[:n])
+}
+
+func (w *binaryWriter) uvarint(v uint64) {
+	n := binary.PutUvarint(w.scratch[:], v)
+	w.buf.Write(w.scratch[:n])
+}
+
+func (w *binaryWriter) float(v float64) {
+	binary.LittleEndian.PutUint64(w.scratch[:8], math.Float64bits(v))
+	w.buf.Write(w.scratch[:8])
+}
+
+func (w *binaryWriter) str(s string) {
+	w.uvarint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+// binaryReader reads primitive values, remembering the first error encountered
+type binaryReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (r *binaryReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(r.r)
+	r.err = err
+	return v
+}
+
+func (r *binaryReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r.r)
+	r.err = err
+	return v
+}
+
+// count reads a list length and rejects values larger than the remaining input
+func (r *binaryReader) count() int {
+	n := r.uvarint()
+	if r.err == nil && n > uint64(r.r.Len()) {
+		r.err = io.ErrUnexpectedEOF
+	}
+	if r.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (r *binaryReader) float() float64 {
+	if r.err != nil {
+		return 0
+	}
+	var b [8]byte
+	if _, err := io.ReadFull(r.r, b[:]); err != nil {
+		r.err = err
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b[:]))
+}
+
+func (r *binaryReader) str() string {
+	n := r.count()
+	if r.err != nil || n == 0 {
+		return ""
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		r.err = err
+		return ""
+	}
+	return string(b)
+}
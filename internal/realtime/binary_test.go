@@ -0,0 +1,57 @@
+package realtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	metrics := &RealtimeMetrics{
+		RequestRate:       12.5,
+		ErrorRate:         0.4,
+		BandwidthRate:     20480,
+		AvgResponseTime:   35.2,
+		ActiveConnections: 3,
+		Status2xx:         120,
+		Status4xx:         4,
+		Status5xx:         1,
+		Timestamp:         now,
+		TopIPs: []IPMetrics{
+			{IP: "1.2.3.4", Country: "IT", RequestRate: 2.1, Bandwidth: 4096, BandwidthRate: 273.06},
+		},
+		LatestRequests: []RequestSummary{
+			{ID: 42, Timestamp: now, Method: "GET", Host: "example.com", BackendName: "web@docker", Path: "/", StatusCode: 200, ResponseTimeMs: 12.3, GeoCountry: "IT", ClientIP: "1.2.3.4"},
+		},
+		PerService: []ServiceMetrics{
+			{ServiceName: "web", RequestRate: 12.5, BandwidthRate: 20480},
+		},
+		Replay: true,
+	}
+
+	t.Run("decodes to the same metrics", func(t *testing.T) {
+		decoded, err := DecodeBinary(EncodeBinary(metrics))
+		assert.NoError(t, err)
+		assert.Equal(t, metrics, decoded)
+	})
+
+	t.Run("rejects truncated frames", func(t *testing.T) {
+		frame := EncodeBinary(metrics)
+		for i := 0; i < len(frame); i++ {
+			_, err := DecodeBinary(frame[:i])
+			assert.ErrorIs(t, err, ErrInvalidBinaryFrame, "prefix length %d", i)
+		}
+	})
+
+	t.Run("frames are length-prefixed", func(t *testing.T) {
+		var buf bytes.Buffer
+		frame := EncodeBinary(metrics)
+		assert.NoError(t, WriteBinaryFrame(&buf, frame))
+		assert.Equal(t, uint32(len(frame)), binary.BigEndian.Uint32(buf.Bytes()[:4]))
+		assert.Equal(t, frame, buf.Bytes()[4:])
+	})
+}
@@ -0,0 +1,139 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package realtime
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sharedSnapshotMaxAge bounds how long a shared snapshot is served when the collection loop
+// is slower than that or not running; normally snapshots are dropped on every tick
+const sharedSnapshotMaxAge = time.Second
+
+// sharedSnapshot is one filtered metrics snapshot shared by all streams asking for the same filters
+type sharedSnapshot struct {
+	once    sync.Once
+	metrics *RealtimeMetrics
+	json    []byte
+}
+
+// fanOut memoizes filtered snapshots until the next collection tick, so N streams over K
+// distinct filter combinations cost K buffer walks per tick instead of N
+type fanOut struct {
+	mu        sync.Mutex
+	snapshots map[string]*sharedSnapshot
+	since     time.Time // When snapshots was started
+}
+
+// reset drops the snapshots of the previous tick
+func (f *fanOut) reset() {
+	f.mu.Lock()
+	f.snapshots = nil
+	f.mu.Unlock()
+}
+
+// get returns the snapshot for key, running compute only for the first caller of the tick
+// Concurrent callers with the same key wait for that computation instead of repeating it
+func (f *fanOut) get(key string, compute func() *RealtimeMetrics) *sharedSnapshot {
+	f.mu.Lock()
+	if f.snapshots == nil || time.Since(f.since) >= sharedSnapshotMaxAge {
+		f.snapshots = make(map[string]*sharedSnapshot)
+		f.since = time.Now()
+	}
+	snapshot, ok := f.snapshots[key]
+	if !ok {
+		snapshot = &sharedSnapshot{}
+		f.snapshots[key] = snapshot
+	}
+	f.mu.Unlock()
+
+	snapshot.once.Do(func() {
+		snapshot.metrics = compute()
+		snapshot.json, _ = json.Marshal(snapshot.metrics)
+	})
+	return snapshot
+}
+
+// len returns the number of distinct filter combinations computed this tick
+func (f *fanOut) len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.snapshots)
+}
+
+// filterKey canonicalizes a filter combination; service filters are OR-ed and
+// IPs are a set, so their order does not matter
+func filterKey(host string, serviceFilters []ServiceFilter, excludeIPFilter *ExcludeIPFilter) string {
+	var b strings.Builder
+	b.WriteString(host)
+	b.WriteString("\x00")
+	b.WriteString(strings.Join(sortedFilters(serviceFilters), "\x01"))
+	if excludeIPFilter != nil {
+		ips := append([]string(nil), excludeIPFilter.ClientIPs...)
+		sort.Strings(ips)
+		b.WriteString("\x00")
+		b.WriteString(strings.Join(ips, "\x01"))
+		b.WriteString("\x00")
+		b.WriteString(strings.Join(sortedFilters(excludeIPFilter.ExcludeServices), "\x01"))
+		if excludeIPFilter.ExcludeInternal {
+			b.WriteString("\x00internal")
+		}
+	}
+	return b.String()
+}
+
+func sortedFilters(filters []ServiceFilter) []string {
+	keys := make([]string, len(filters))
+	for i, f := range filters {
+		keys[i] = f.Type + "=" + f.Name
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetSharedMetricsWithFilters returns GetMetricsWithFilters, computed at most once per
+// collection tick for each distinct filter combination and shared by every caller
+func (m *MetricsCollector) GetSharedMetricsWithFilters(host string, serviceFilters []ServiceFilter, excludeIPFilter *ExcludeIPFilter) *RealtimeMetrics {
+	if host == "" && len(serviceFilters) == 0 && excludeIPFilter == nil {
+		return m.GetMetrics()
+	}
+	return m.sharedSnapshot(host, serviceFilters, excludeIPFilter).metrics
+}
+
+// GetSharedFilteredJSON returns the JSON encoding of GetSharedMetricsWithFilters,
+// also encoded once per tick; unfiltered callers get the global cached JSON
+func (m *MetricsCollector) GetSharedFilteredJSON(host string, serviceFilters []ServiceFilter, excludeIPFilter *ExcludeIPFilter) []byte {
+	if host == "" && len(serviceFilters) == 0 && excludeIPFilter == nil {
+		return m.GetCachedJSON()
+	}
+	return m.sharedSnapshot(host, serviceFilters, excludeIPFilter).json
+}
+
+func (m *MetricsCollector) sharedSnapshot(host string, serviceFilters []ServiceFilter, excludeIPFilter *ExcludeIPFilter) *sharedSnapshot {
+	return m.shared.get(filterKey(host, serviceFilters, excludeIPFilter), func() *RealtimeMetrics {
+		return m.GetMetricsWithFilters(host, serviceFilters, excludeIPFilter)
+	})
+}
@@ -0,0 +1,102 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedMetricsWithFilters(t *testing.T) {
+	m := newTestCollector()
+	m.Ingest(&models.HTTPRequest{ClientIP: "1.1.1.1", Host: "a.example.com", StatusCode: 200, Timestamp: time.Now()})
+
+	filters := []ServiceFilter{{Name: "a.example.com", Type: "host"}, {Name: "b.example.com", Type: "host"}}
+	reversed := []ServiceFilter{filters[1], filters[0]}
+	first := m.GetSharedMetricsWithFilters("", filters, nil)
+	assert.Equal(t, int64(1), first.Status2xx)
+	assert.Same(t, first, m.GetSharedMetricsWithFilters("", reversed, nil), "filter order does not matter")
+	assert.Equal(t, 1, m.shared.len())
+
+	other := m.GetSharedMetricsWithFilters("", filters, &ExcludeIPFilter{ClientIPs: []string{"1.1.1.1"}})
+	assert.NotSame(t, first, other)
+	assert.Zero(t, other.Status2xx)
+	assert.Equal(t, 2, m.shared.len())
+
+	var decoded RealtimeMetrics
+	require.NoError(t, json.Unmarshal(m.GetSharedFilteredJSON("", filters, nil), &decoded))
+	assert.Equal(t, int64(1), decoded.Status2xx)
+
+	// New requests show up once the next tick drops the shared snapshots
+	m.Ingest(&models.HTTPRequest{ClientIP: "2.2.2.2", Host: "b.example.com", StatusCode: 500, Timestamp: time.Now()})
+	assert.Zero(t, m.GetSharedMetricsWithFilters("", filters, nil).Status5xx)
+	m.collectMetrics()
+	assert.Equal(t, int64(1), m.GetSharedMetricsWithFilters("", filters, nil).Status5xx)
+	assert.Equal(t, 1, m.shared.len())
+}
+
+func TestSharedMetricsExpireWithoutTicks(t *testing.T) {
+	m := newTestCollector()
+	filters := []ServiceFilter{{Name: "a.example.com", Type: "host"}}
+	first := m.GetSharedMetricsWithFilters("", filters, nil)
+
+	m.shared.mu.Lock()
+	m.shared.since = time.Now().Add(-sharedSnapshotMaxAge)
+	m.shared.mu.Unlock()
+	assert.NotSame(t, first, m.GetSharedMetricsWithFilters("", filters, nil))
+}
+
+// BenchmarkFilteredStreams compares 50 filtered connections over 5 distinct filter sets
+// computing their own snapshot each tick with connections sharing one per filter set
+func BenchmarkFilteredStreams(b *testing.B) {
+	const connections = 50
+	m := newTestCollector()
+	now := time.Now()
+	for i := 0; i < 20000; i++ {
+		m.Ingest(&models.HTTPRequest{
+			ClientIP:    fmt.Sprintf("10.0.%d.%d", i/250%250, i%250),
+			Host:        fmt.Sprintf("app%d.example.com", i%5),
+			BackendName: fmt.Sprintf("app%d@docker", i%5),
+			StatusCode:  200 + i%4*100,
+			Timestamp:   now.Add(-time.Duration(i%60000) * time.Millisecond),
+		})
+	}
+	filters := make([][]ServiceFilter, connections)
+	for i := range filters {
+		filters[i] = []ServiceFilter{{Name: fmt.Sprintf("app%d.example.com", i%5), Type: "host"}}
+	}
+
+	b.Run("per-connection", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var wg sync.WaitGroup
+			for _, f := range filters {
+				wg.Add(1)
+				go func(f []ServiceFilter) {
+					defer wg.Done()
+					_, _ = json.Marshal(m.GetMetricsWithFilters("", f, nil))
+				}(f)
+			}
+			wg.Wait()
+		}
+	})
+	b.Run("shared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.shared.reset() // One collection tick
+			var wg sync.WaitGroup
+			for _, f := range filters {
+				wg.Add(1)
+				go func(f []ServiceFilter) {
+					defer wg.Done()
+					_ = m.GetSharedFilteredJSON("", f, nil)
+				}(f)
+			}
+			wg.Wait()
+		}
+	})
+}
@@ -0,0 +1,83 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package realtime
+
+import (
+	"reflect"
+	"unsafe"
+
+	"loglynx/internal/database/models"
+)
+
+// bufferSampleSize bounds how many buffered requests are inspected to estimate
+// the footprint of the real-time buffers
+const bufferSampleSize = 256
+
+var (
+	requestStructSize = int64(unsafe.Sizeof(models.HTTPRequest{}))
+	pointerSize       = int64(unsafe.Sizeof(uintptr(0)))
+)
+
+// BufferStats returns the number of requests held in the shared real-time buffer and
+// an estimate of the memory they use. Per-source buffers share the same requests, so
+// only their slice slots are added. The per-request size is extrapolated from an
+// evenly spaced sample, keeping the cost flat regardless of the buffer length.
+func (m *MetricsCollector) BufferStats() (requests int, estimatedBytes int64) {
+	m.bufferMu.RLock()
+	defer m.bufferMu.RUnlock()
+
+	requests = len(m.requestBuffer)
+	estimatedBytes = int64(cap(m.requestBuffer)+m.sourceBufferedSize) * pointerSize
+	if requests == 0 {
+		return requests, estimatedBytes
+	}
+
+	step := 1
+	if requests > bufferSampleSize {
+		step = requests / bufferSampleSize
+	}
+	var sampled, sampledBytes int64
+	for i := 0; i < requests; i += step {
+		sampledBytes += estimateRequestBytes(m.requestBuffer[i])
+		sampled++
+	}
+
+	estimatedBytes += sampledBytes / sampled * int64(requests)
+	return requests, estimatedBytes
+}
+
+// estimateRequestBytes approximates the memory held by one request: the struct
+// itself plus the contents of its string fields
+func estimateRequestBytes(req *models.HTTPRequest) int64 {
+	if req == nil {
+		return 0
+	}
+
+	size := requestStructSize
+	value := reflect.ValueOf(req).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if field := value.Field(i); field.Kind() == reflect.String {
+			size += int64(field.Len())
+		}
+	}
+	return size
+}
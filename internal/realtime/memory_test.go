@@ -0,0 +1,31 @@
+package realtime
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferStatsEstimatesFootprint(t *testing.T) {
+	collector := newTestCollector()
+
+	requests, bytes := collector.BufferStats()
+	assert.Equal(t, 0, requests)
+	assert.Equal(t, int64(cap(collector.requestBuffer))*pointerSize, bytes, "an empty buffer only holds its preallocated slots")
+
+	small := &models.HTTPRequest{Timestamp: time.Now(), Path: "/"}
+	large := &models.HTTPRequest{Timestamp: time.Now(), Path: "/", UserAgent: strings.Repeat("a", 4096)}
+	assert.Greater(t, estimateRequestBytes(large), estimateRequestBytes(small)+4000)
+
+	for i := 0; i < 1000; i++ {
+		collector.Ingest(&models.HTTPRequest{Timestamp: time.Now(), Path: "/", UserAgent: strings.Repeat("a", 1024)})
+	}
+
+	requests, bytes = collector.BufferStats()
+	assert.Equal(t, 1000, requests)
+	assert.GreaterOrEqual(t, bytes, int64(1000)*(requestStructSize+1024))
+}
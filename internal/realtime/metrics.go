@@ -23,9 +23,11 @@ package realtime
 
 import (
 	"encoding/json"
+	"fmt"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"loglynx/internal/database/models"
@@ -38,8 +40,14 @@ import (
 const (
 	// QueryTimeout is the maximum time for a database query
 	QueryTimeout = 5 * time.Second
-	// BufferDuration is the duration of data to keep in memory
-	BufferDuration = 60 * time.Second
+	// DefaultBufferDuration is how long requests are kept in memory when not configured
+	DefaultBufferDuration = 60 * time.Second
+	// DefaultRateWindow is the window request, error and bandwidth rates are averaged over when not configured
+	DefaultRateWindow = 5 * time.Second
+	// topIPWindow is the window top clients are ranked over, shortened to the buffer duration if needed
+	topIPWindow = 15 * time.Second
+	// DefaultMaxBufferedRequests caps the shared buffer when no explicit maximum is configured
+	DefaultMaxBufferedRequests = 100000
 )
 
 // MetricsCollector collects real-time metrics
@@ -50,6 +58,12 @@ type MetricsCollector struct {
 	// In-memory buffer for real-time metrics
 	requestBuffer []*models.HTTPRequest
 	bufferMu      sync.RWMutex
+	maxBuffered   int   // Hard cap on requestBuffer; the oldest requests are evicted beyond it
+	evicted       int64 // Requests evicted by the cap since the last collection, guarded by bufferMu
+
+	// Set before Start, read-only afterwards
+	bufferDuration time.Duration // How long requests stay in the buffer (status distribution window)
+	rateWindow     time.Duration // Window rates and average response time are computed over
 
 	// Current metrics
 	mu                sync.RWMutex
@@ -72,6 +86,23 @@ type MetricsCollector struct {
 	// Cached JSON for global metrics (optimization)
 	cachedJSON []byte
 
+	// Optional per-source isolation: each source keeps its own sub-buffer and cached JSON
+	// so that single-source dashboards are served from cache instead of a filtered scan
+	perSourceEnabled   bool
+	maxSourceBuffered  int                              // Upper bound on requests held across all sub-buffers (globally oldest evicted first)
+	sourceBuffers      map[string][]*models.HTTPRequest // Guarded by bufferMu
+	sourceBufferedSize int                              // Guarded by bufferMu
+	sourceCachedJSON   map[string][]byte                // Guarded by mu
+
+	// Filtered snapshots shared by streams with the same filters until the next tick
+	shared fanOut
+
+	// Consumers notified with the freshly computed metrics on every tick (e.g. the alert engine)
+	subscribers []func(*RealtimeMetrics) // Guarded by mu
+
+	// Set while a Replayer feeds historical requests into the buffer
+	replaying atomic.Bool
+
 	// Lifecycle management
 	stopChan chan struct{}
 	stopped  bool
@@ -91,6 +122,7 @@ type RealtimeMetrics struct {
 	TopIPs            []IPMetrics      `json:"top_ips"`
 	LatestRequests    []RequestSummary `json:"latest_requests"`
 	PerService        []ServiceMetrics `json:"per_service"`
+	Replay            bool             `json:"replay"` // True while historical requests are being replayed
 }
 
 // RequestSummary is a lightweight representation of a request for the real-time table
@@ -119,38 +151,150 @@ type IPMetrics struct {
 // NewMetricsCollector creates a new real-time metrics collector
 func NewMetricsCollector(db *gorm.DB, logger *pterm.Logger) *MetricsCollector {
 	return &MetricsCollector{
-		db:            db,
-		logger:        logger,
-		lastUpdate:    time.Now(),
-		stopChan:      make(chan struct{}),
-		requestBuffer: make([]*models.HTTPRequest, 0, 10000),
+		db:             db,
+		logger:         logger,
+		lastUpdate:     time.Now(),
+		stopChan:       make(chan struct{}),
+		requestBuffer:  make([]*models.HTTPRequest, 0, 10000),
+		maxBuffered:    DefaultMaxBufferedRequests,
+		bufferDuration: DefaultBufferDuration,
+		rateWindow:     DefaultRateWindow,
+	}
+}
+
+// SetWindows sets how long requests are kept in memory and the window rates are averaged over
+// A longer buffer holds every request received within it, so memory grows with traffic
+// times the duration (still bounded by the max buffered cap)
+// Non-positive values restore the defaults; the rate window cannot exceed the buffer duration
+// Must be called before Start
+func (m *MetricsCollector) SetWindows(bufferDuration, rateWindow time.Duration) error {
+	if bufferDuration <= 0 {
+		bufferDuration = DefaultBufferDuration
+	}
+	if rateWindow <= 0 {
+		rateWindow = DefaultRateWindow
+	}
+	if rateWindow > bufferDuration {
+		return fmt.Errorf("rate window %s exceeds the buffer duration %s", rateWindow, bufferDuration)
 	}
+	m.bufferDuration = bufferDuration
+	m.rateWindow = rateWindow
+	return nil
+}
+
+// BufferDuration returns how long requests are kept in memory
+func (m *MetricsCollector) BufferDuration() time.Duration {
+	return m.bufferDuration
+}
+
+// ipWindow returns the window top clients are ranked over
+func (m *MetricsCollector) ipWindow() time.Duration {
+	return min(topIPWindow, m.bufferDuration)
+}
+
+// SetMaxBuffered sets the hard cap on requests held in the shared buffer
+// Bursts beyond it (e.g. catch-up after a long pause) evict the oldest requests first
+// Non-positive values restore DefaultMaxBufferedRequests
+func (m *MetricsCollector) SetMaxBuffered(maxBuffered int) {
+	if maxBuffered <= 0 {
+		maxBuffered = DefaultMaxBufferedRequests
+	}
+	m.bufferMu.Lock()
+	m.maxBuffered = maxBuffered
+	m.bufferMu.Unlock()
+}
+
+// EnablePerSourceBuffers turns on per-source metrics isolation
+// maxBuffered bounds the total number of requests kept across all source sub-buffers
+// Must be called before Start
+func (m *MetricsCollector) EnablePerSourceBuffers(maxBuffered int) {
+	if maxBuffered <= 0 {
+		maxBuffered = 50000
+	}
+
+	m.bufferMu.Lock()
+	m.perSourceEnabled = true
+	m.maxSourceBuffered = maxBuffered
+	m.sourceBuffers = make(map[string][]*models.HTTPRequest)
+	m.bufferMu.Unlock()
+
+	m.mu.Lock()
+	m.sourceCachedJSON = make(map[string][]byte)
+	m.mu.Unlock()
+
+	m.logger.Info("Real-time per-source metrics isolation enabled",
+		m.logger.Args("max_buffered_requests", maxBuffered))
 }
 
 // Ingest adds a new request to the in-memory buffer
 // Maintains chronological order by timestamp using optimized insertion
+// Requests already outside the buffer window (backfill, catch-up) are ignored
 func (m *MetricsCollector) Ingest(req *models.HTTPRequest) {
+	if time.Since(req.Timestamp) > m.bufferDuration {
+		return
+	}
+
 	m.bufferMu.Lock()
 	defer m.bufferMu.Unlock()
 
-	bufLen := len(m.requestBuffer)
+	m.requestBuffer = insertSorted(m.requestBuffer, req)
+	if len(m.requestBuffer) > m.maxBuffered {
+		// Collection is lagging behind ingestion: drop the oldest to stay within the cap
+		m.requestBuffer[0] = nil
+		m.requestBuffer = m.requestBuffer[1:]
+		m.evicted++
+	}
 
-	// Fast path: empty buffer or new request is newest
-	if bufLen == 0 || !req.Timestamp.Before(m.requestBuffer[bufLen-1].Timestamp) {
-		m.requestBuffer = append(m.requestBuffer, req)
+	if m.perSourceEnabled {
+		m.sourceBuffers[req.SourceName] = insertSorted(m.sourceBuffers[req.SourceName], req)
+		m.sourceBufferedSize++
+		if m.sourceBufferedSize > m.maxSourceBuffered {
+			// Evict across all sources so a busy one cannot starve quiet or new ones
+			m.evictOldestSourceRequest()
+		}
+	}
+}
+
+// evictOldestSourceRequest drops the oldest request held across all source sub-buffers
+// IMPORTANT: Caller must hold m.bufferMu
+func (m *MetricsCollector) evictOldestSourceRequest() {
+	oldest := ""
+	var oldestTime time.Time
+	for name, buffer := range m.sourceBuffers {
+		if len(buffer) > 0 && (oldestTime.IsZero() || buffer[0].Timestamp.Before(oldestTime)) {
+			oldest, oldestTime = name, buffer[0].Timestamp
+		}
+	}
+	if oldestTime.IsZero() {
 		return
 	}
 
+	buffer := m.sourceBuffers[oldest]
+	buffer[0] = nil
+	m.sourceBuffers[oldest] = buffer[1:]
+	m.sourceBufferedSize--
+}
+
+// insertSorted inserts req into a chronologically ordered buffer
+func insertSorted(buffer []*models.HTTPRequest, req *models.HTTPRequest) []*models.HTTPRequest {
+	bufLen := len(buffer)
+
+	// Fast path: empty buffer or new request is newest
+	if bufLen == 0 || !req.Timestamp.Before(buffer[bufLen-1].Timestamp) {
+		return append(buffer, req)
+	}
+
 	// Slow path: need to insert in correct position
 	// Binary search for insertion point
 	insertIdx := sort.Search(bufLen, func(i int) bool {
-		return !m.requestBuffer[i].Timestamp.Before(req.Timestamp)
+		return !buffer[i].Timestamp.Before(req.Timestamp)
 	})
 
 	// Insert at correct position
-	m.requestBuffer = append(m.requestBuffer, nil)                   // Expand slice
-	copy(m.requestBuffer[insertIdx+1:], m.requestBuffer[insertIdx:]) // Shift right
-	m.requestBuffer[insertIdx] = req                                 // Insert
+	buffer = append(buffer, nil)                   // Expand slice
+	copy(buffer[insertIdx+1:], buffer[insertIdx:]) // Shift right
+	buffer[insertIdx] = req                        // Insert
+	return buffer
 }
 
 // Start begins collecting metrics at regular intervals
@@ -182,6 +326,14 @@ func (m *MetricsCollector) Stop() {
 	m.mu.Unlock()
 }
 
+// Subscribe registers fn to receive the global metrics after every collection tick
+// fn runs on the collector goroutine, so it must return quickly and not call back into the collector
+func (m *MetricsCollector) Subscribe(fn func(*RealtimeMetrics)) {
+	m.mu.Lock()
+	m.subscribers = append(m.subscribers, fn)
+	m.mu.Unlock()
+}
+
 // SetActiveConnections updates the active connection count
 func (m *MetricsCollector) SetActiveConnections(n int) {
 	m.mu.Lock()
@@ -189,6 +341,18 @@ func (m *MetricsCollector) SetActiveConnections(n int) {
 	m.mu.Unlock()
 }
 
+// SetReplayActive flags the metrics as (not) coming from a replay of stored requests
+func (m *MetricsCollector) SetReplayActive(active bool) {
+	m.replaying.Store(active)
+}
+
+// GetActiveConnections returns the number of open real-time stream connections
+func (m *MetricsCollector) GetActiveConnections() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.activeConnections
+}
+
 // AdjustActiveConnections increments or decrements the active connection count.
 func (m *MetricsCollector) AdjustActiveConnections(delta int) {
 	m.mu.Lock()
@@ -199,6 +363,18 @@ func (m *MetricsCollector) AdjustActiveConnections(delta int) {
 	m.mu.Unlock()
 }
 
+// TryAcquireConnection registers a new stream connection unless max are already open
+// A max of 0 or less means unlimited. Release with AdjustActiveConnections(-1)
+func (m *MetricsCollector) TryAcquireConnection(max int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if max > 0 && m.activeConnections >= max {
+		return false
+	}
+	m.activeConnections++
+	return true
+}
+
 // GetCachedJSON returns the cached JSON representation of global metrics
 func (m *MetricsCollector) GetCachedJSON() []byte {
 	m.mu.RLock()
@@ -210,24 +386,77 @@ func (m *MetricsCollector) GetCachedJSON() []byte {
 // Uses a sliding window approach for accurate rate calculation without DB queries
 func (m *MetricsCollector) collectMetrics() {
 	now := time.Now()
+	cutoff := now.Add(-m.bufferDuration)
 
-	// Use a 5-second sliding window for smoother rates and latency tolerance
-	windowDuration := 5 * time.Second
-	windowStart := now.Add(-windowDuration)
+	m.bufferMu.Lock()
+	defer m.bufferMu.Unlock()
 
-	// Wave 2: Raise timeout for Top Active Clients to 15 seconds
-	ipWindowDuration := 15 * time.Second
-	ipWindowStart := now.Add(-ipWindowDuration)
+	// 1. Prune requests older than the buffer duration
+	m.requestBuffer = pruneBuffer(m.requestBuffer, cutoff)
+	if m.evicted > 0 {
+		m.logger.Warn("Real-time buffer full, oldest requests were dropped; rates may be underestimated",
+			m.logger.Args("dropped", m.evicted, "max_buffered", m.maxBuffered))
+		m.evicted = 0
+	}
 
-	oneMinuteAgo := now.Add(-1 * time.Minute)
+	// 2. Calculate metrics from buffer
+	metrics, lastRequestTime := m.computeMetrics(m.requestBuffer, now)
+	if lastRequestTime.IsZero() {
+		m.mu.RLock()
+		lastRequestTime = m.lastRequestTime
+		m.mu.RUnlock()
+	}
 
-	m.bufferMu.Lock()
-	defer m.bufferMu.Unlock()
+	m.mu.RLock()
+	metrics.ActiveConnections = m.activeConnections
+	m.mu.RUnlock()
+
+	// Marshal to JSON immediately for caching
+	jsonBytes, _ := json.Marshal(metrics)
+
+	// 3. Per-source metrics (only when isolation is enabled)
+	sourceJSON := m.collectSourceMetrics(now, cutoff)
 
-	// 1. Prune old requests from buffer (keep only last 60s)
+	// Update metrics with lock
+	m.mu.Lock()
+	m.perServiceMetrics = metrics.PerService
+	m.topIPs = metrics.TopIPs
+	m.latestRequests = metrics.LatestRequests
+	m.requestRate = metrics.RequestRate
+	m.errorRate = metrics.ErrorRate
+	m.bandwidthRate = metrics.BandwidthRate
+	m.avgResponseTime = metrics.AvgResponseTime
+	m.last2xxCount = metrics.Status2xx
+	m.last4xxCount = metrics.Status4xx
+	m.last5xxCount = metrics.Status5xx
+	m.lastUpdate = now
+	m.lastRequestTime = lastRequestTime
+	if jsonBytes != nil {
+		m.cachedJSON = jsonBytes
+	}
+	if m.perSourceEnabled {
+		m.sourceCachedJSON = sourceJSON
+	}
+	subscribers := m.subscribers
+	m.mu.Unlock()
+	m.shared.reset()
+
+	m.logger.Trace("Collected real-time metrics (in-memory)",
+		m.logger.Args(
+			"request_rate", metrics.RequestRate,
+			"buffer_size", len(m.requestBuffer),
+		))
+
+	for _, fn := range subscribers {
+		fn(metrics)
+	}
+}
+
+// pruneBuffer drops requests older than cutoff from a chronologically ordered buffer
+func pruneBuffer(buffer []*models.HTTPRequest, cutoff time.Time) []*models.HTTPRequest {
 	validIndex := -1
-	for i, req := range m.requestBuffer {
-		if req.Timestamp.After(oneMinuteAgo) {
+	for i, req := range buffer {
+		if req.Timestamp.After(cutoff) {
 			validIndex = i
 			break
 		}
@@ -235,15 +464,28 @@ func (m *MetricsCollector) collectMetrics() {
 
 	if validIndex >= 0 {
 		// Create a new slice to allow GC to collect the old array backing
-		newBuffer := make([]*models.HTTPRequest, len(m.requestBuffer)-validIndex)
-		copy(newBuffer, m.requestBuffer[validIndex:])
-		m.requestBuffer = newBuffer
-	} else if validIndex == -1 && len(m.requestBuffer) > 0 {
+		newBuffer := make([]*models.HTTPRequest, len(buffer)-validIndex)
+		copy(newBuffer, buffer[validIndex:])
+		return newBuffer
+	} else if len(buffer) > 0 {
 		// All requests are too old
-		m.requestBuffer = m.requestBuffer[:0]
+		return buffer[:0]
 	}
+	return buffer
+}
+
+// computeMetrics calculates a metrics snapshot from a pruned buffer
+// Returns the metrics and the timestamp of the newest request in the rate window (zero if none)
+// IMPORTANT: Caller must hold m.bufferMu
+func (m *MetricsCollector) computeMetrics(buffer []*models.HTTPRequest, now time.Time) (*RealtimeMetrics, time.Time) {
+	// Use a sliding window for smoother rates and latency tolerance
+	windowDuration := m.rateWindow
+	windowStart := now.Add(-windowDuration)
+
+	// Wave 2: Raise timeout for Top Active Clients to 15 seconds
+	ipWindowDuration := m.ipWindow()
+	ipWindowStart := now.Add(-ipWindowDuration)
 
-	// 2. Calculate metrics from buffer
 	var (
 		totalCountWindow int64
 		errorCountWindow int64
@@ -251,7 +493,7 @@ func (m *MetricsCollector) collectMetrics() {
 		status2xx        int64
 		status4xx        int64
 		status5xx        int64
-		count1m          int64
+		countBuffered    int64
 		lastRequestTime  time.Time
 	)
 
@@ -260,8 +502,8 @@ func (m *MetricsCollector) collectMetrics() {
 	ipBandwidth := make(map[string]int64)
 	ipCountries := make(map[string]string)
 
-	for _, req := range m.requestBuffer {
-		// For rates (last 5s)
+	for _, req := range buffer {
+		// For rates (rate window)
 		if req.Timestamp.After(windowStart) {
 			totalCountWindow++
 			totalRespTime += req.ResponseTimeMs
@@ -282,8 +524,8 @@ func (m *MetricsCollector) collectMetrics() {
 			}
 		}
 
-		// For distribution (last 1m)
-		count1m++
+		// For distribution (buffer duration)
+		countBuffered++
 		if req.StatusCode >= 200 && req.StatusCode < 300 {
 			status2xx++
 		} else if req.StatusCode >= 400 && req.StatusCode < 500 {
@@ -293,7 +535,7 @@ func (m *MetricsCollector) collectMetrics() {
 		}
 	}
 
-	// Calculate averages (Instant - last 5s)
+	// Calculate averages (Instant - rate window)
 	avgRespTime := 0.0
 	if totalCountWindow > 0 {
 		avgRespTime = totalRespTime / float64(totalCountWindow)
@@ -310,9 +552,9 @@ func (m *MetricsCollector) collectMetrics() {
 			requestRate = float64(totalCountWindow) / windowDuration.Seconds()
 			errorRate = float64(errorCountWindow) / windowDuration.Seconds()
 
-			// Calculate global bandwidth rate (sum of all BW in 15s window? No, use 5s window for rate)
+			// Calculate global bandwidth rate (sum of all BW in the IP window? No, use the rate window)
 			var totalBwWindow int64
-			for _, req := range m.requestBuffer {
+			for _, req := range buffer {
 				if req.Timestamp.After(windowStart) {
 					totalBwWindow += req.ResponseSize
 				}
@@ -373,60 +615,86 @@ func (m *MetricsCollector) collectMetrics() {
 		status2xx = 0
 		status4xx = 0
 		status5xx = 0
-		m.mu.RLock()
-		lastRequestTime = m.lastRequestTime
-		m.mu.RUnlock()
 	}
 
-	// Collect per-service metrics (global) - passing nil filters uses buffer
-	perServiceMetrics := m.calculatePerServiceMetrics(m.requestBuffer, nil, nil)
+	// Collect per-service metrics - passing nil filters uses buffer
+	perServiceMetrics := m.calculatePerServiceMetrics(buffer, nil, nil)
 
 	// Get Latest Requests (last 20 from buffer)
-	latestRequests := m.getLatestRequests(m.requestBuffer, 20)
+	latestRequests := m.getLatestRequests(buffer, 20)
 
-	// Prepare metrics struct for JSON caching
-	metrics := &RealtimeMetrics{
-		RequestRate:       requestRate,
-		ErrorRate:         errorRate,
-		BandwidthRate:     globalBwRate,
-		AvgResponseTime:   avgRespTime,
-		ActiveConnections: m.activeConnections,
-		Status2xx:         status2xx,
-		Status4xx:         status4xx,
-		Status5xx:         status5xx,
-		Timestamp:         now,
-		TopIPs:            topIPs,
-		LatestRequests:    latestRequests,
-		PerService:        perServiceMetrics,
+	return &RealtimeMetrics{
+		RequestRate:     requestRate,
+		ErrorRate:       errorRate,
+		BandwidthRate:   globalBwRate,
+		AvgResponseTime: avgRespTime,
+		Status2xx:       status2xx,
+		Status4xx:       status4xx,
+		Status5xx:       status5xx,
+		Timestamp:       now,
+		TopIPs:          topIPs,
+		LatestRequests:  latestRequests,
+		PerService:      perServiceMetrics,
+		Replay:          m.replaying.Load(),
+	}, lastRequestTime
+}
+
+// collectSourceMetrics prunes every source sub-buffer and returns freshly encoded per-source JSON
+// Sources whose sub-buffer is empty are dropped
+// IMPORTANT: Caller must hold m.bufferMu
+func (m *MetricsCollector) collectSourceMetrics(now time.Time, cutoff time.Time) map[string][]byte {
+	if !m.perSourceEnabled {
+		return nil
 	}
 
-	// Marshal to JSON immediately for caching
-	jsonBytes, _ := json.Marshal(metrics)
+	sourceJSON := make(map[string][]byte, len(m.sourceBuffers))
+	total := 0
+	for name, buffer := range m.sourceBuffers {
+		buffer = pruneBuffer(buffer, cutoff)
+		if len(buffer) == 0 {
+			delete(m.sourceBuffers, name)
+			continue
+		}
+		m.sourceBuffers[name] = buffer
+		total += len(buffer)
 
-	// Update metrics with lock
-	m.mu.Lock()
-	m.perServiceMetrics = perServiceMetrics
-	m.topIPs = topIPs
-	m.latestRequests = latestRequests
-	m.requestRate = requestRate
-	m.errorRate = errorRate
-	m.bandwidthRate = globalBwRate
-	m.avgResponseTime = avgRespTime
-	m.last2xxCount = status2xx
-	m.last4xxCount = status4xx
-	m.last5xxCount = status5xx
-	m.lastUpdate = now
-	m.lastRequestTime = lastRequestTime
-	if jsonBytes != nil {
-		m.cachedJSON = jsonBytes
+		metrics, _ := m.computeMetrics(buffer, now)
+		if jsonBytes, err := json.Marshal(metrics); err == nil {
+			sourceJSON[name] = jsonBytes
+		}
 	}
-	m.mu.Unlock()
+	m.sourceBufferedSize = total
 
-	m.logger.Trace("Collected real-time metrics (in-memory)",
-		m.logger.Args(
-			"request_rate", requestRate,
-			"buffer_size", len(m.requestBuffer),
-		))
+	return sourceJSON
+}
+
+// GetSourceCachedJSON returns the cached JSON metrics for a single log source
+// Returns nil if per-source isolation is disabled or the source has no recent requests
+func (m *MetricsCollector) GetSourceCachedJSON(source string) []byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sourceCachedJSON[source]
+}
+
+// GetSourceMetrics returns real-time metrics for a single log source
+// Uses the isolated sub-buffer when enabled, otherwise filters the global buffer
+func (m *MetricsCollector) GetSourceMetrics(source string) *RealtimeMetrics {
+	m.bufferMu.RLock()
+	defer m.bufferMu.RUnlock()
+
+	var buffer []*models.HTTPRequest
+	if m.perSourceEnabled {
+		buffer = m.sourceBuffers[source]
+	} else {
+		for _, req := range m.requestBuffer {
+			if req.SourceName == source {
+				buffer = append(buffer, req)
+			}
+		}
+	}
+
+	metrics, _ := m.computeMetrics(buffer, time.Now())
+	return metrics
 }
 
 // GetMetrics returns the current metrics snapshot
@@ -447,6 +715,7 @@ func (m *MetricsCollector) GetMetrics() *RealtimeMetrics {
 		TopIPs:            m.topIPs,
 		LatestRequests:    m.latestRequests,
 		PerService:        m.perServiceMetrics,
+		Replay:            m.replaying.Load(),
 	}
 }
 
@@ -460,6 +729,7 @@ type ServiceFilter struct {
 type ExcludeIPFilter struct {
 	ClientIPs       []string
 	ExcludeServices []ServiceFilter
+	ExcludeInternal bool
 }
 
 // GetMetricsWithHost returns real-time metrics filtered by host
@@ -471,14 +741,14 @@ func (m *MetricsCollector) GetMetricsWithHost(host string) *RealtimeMetrics {
 // GetMetricsWithFilters returns real-time metrics with service and IP exclusion filters
 func (m *MetricsCollector) GetMetricsWithFilters(host string, serviceFilters []ServiceFilter, excludeIPFilter *ExcludeIPFilter) *RealtimeMetrics {
 	now := time.Now()
-	windowDuration := 5 * time.Second
+	windowDuration := m.rateWindow
 	windowStart := now.Add(-windowDuration)
 
 	// Wave 2: Raise timeout for Top Active Clients to 15 seconds
-	ipWindowDuration := 15 * time.Second
+	ipWindowDuration := m.ipWindow()
 	ipWindowStart := now.Add(-ipWindowDuration)
 
-	oneMinuteAgo := now.Add(-1 * time.Minute)
+	cutoff := now.Add(-m.bufferDuration)
 
 	// If no filters specified, return global metrics
 	if host == "" && len(serviceFilters) == 0 && excludeIPFilter == nil {
@@ -495,7 +765,7 @@ func (m *MetricsCollector) GetMetricsWithFilters(host string, serviceFilters []S
 		status2xx        int64
 		status4xx        int64
 		status5xx        int64
-		count1m          int64
+		countBuffered    int64
 		totalBwWindow    int64
 		lastRequestTime  time.Time
 		filteredRequests []*models.HTTPRequest
@@ -518,6 +788,7 @@ func (m *MetricsCollector) GetMetricsWithFilters(host string, serviceFilters []S
 		repoExcludeIP = &repositories.ExcludeIPFilter{
 			ClientIPs:       excludeIPFilter.ClientIPs,
 			ExcludeServices: make([]repositories.ServiceFilter, len(excludeIPFilter.ExcludeServices)),
+			ExcludeInternal: excludeIPFilter.ExcludeInternal,
 		}
 		for i, f := range excludeIPFilter.ExcludeServices {
 			repoExcludeIP.ExcludeServices[i] = repositories.ServiceFilter{Name: f.Name, Type: f.Type}
@@ -538,7 +809,7 @@ func (m *MetricsCollector) GetMetricsWithFilters(host string, serviceFilters []S
 		// Collect matching requests for latest list
 		filteredRequests = append(filteredRequests, req)
 
-		// For rates (last 5s)
+		// For rates (rate window)
 		if req.Timestamp.After(windowStart) {
 			totalCountWindow++
 			totalRespTime += req.ResponseTimeMs
@@ -560,9 +831,9 @@ func (m *MetricsCollector) GetMetricsWithFilters(host string, serviceFilters []S
 			}
 		}
 
-		// For distribution (last 1m)
-		if req.Timestamp.After(oneMinuteAgo) {
-			count1m++
+		// For distribution (buffer duration)
+		if req.Timestamp.After(cutoff) {
+			countBuffered++
 			if req.StatusCode >= 200 && req.StatusCode < 300 {
 				status2xx++
 			} else if req.StatusCode >= 400 && req.StatusCode < 500 {
@@ -670,6 +941,7 @@ func (m *MetricsCollector) GetMetricsWithFilters(host string, serviceFilters []S
 		TopIPs:            topIPs,
 		LatestRequests:    latestRequests,
 		PerService:        perServiceMetrics,
+		Replay:            m.replaying.Load(),
 	}
 }
 
@@ -683,7 +955,7 @@ type ServiceMetrics struct {
 // GetPerServiceMetrics returns real-time metrics for each service
 func (m *MetricsCollector) GetPerServiceMetrics(filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) []ServiceMetrics {
 	// If no filters, return cached global metrics
-	if len(filters) == 0 && (excludeIP == nil || len(excludeIP.ClientIPs) == 0) {
+	if len(filters) == 0 && (excludeIP == nil || (len(excludeIP.ClientIPs) == 0 && !excludeIP.ExcludeInternal)) {
 		m.mu.RLock()
 		defer m.mu.RUnlock()
 		return m.perServiceMetrics
@@ -696,10 +968,10 @@ func (m *MetricsCollector) GetPerServiceMetrics(filters []repositories.ServiceFi
 
 // calculatePerServiceMetrics calculates per-service metrics from the buffer
 func (m *MetricsCollector) calculatePerServiceMetrics(buffer []*models.HTTPRequest, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) []ServiceMetrics {
-	// Use 5-second window for accurate real-time rates
+	// Use the rate window for accurate real-time rates
 	// Use parent's now timestamp for consistency
 	now := time.Now()
-	windowDuration := 5 * time.Second
+	windowDuration := m.rateWindow
 	windowStart := now.Add(-windowDuration)
 
 	// Map to aggregate counts and bandwidth by service
@@ -747,6 +1019,11 @@ func (m *MetricsCollector) calculatePerServiceMetrics(buffer []*models.HTTPReque
 
 // matchesFilters checks if a request matches the given filters
 func (m *MetricsCollector) matchesFilters(req *models.HTTPRequest, filters []repositories.ServiceFilter, excludeIP *repositories.ExcludeIPFilter) bool {
+	// Internal ranges are flagged at ingestion, so no CIDR matching is needed here
+	if excludeIP != nil && excludeIP.ExcludeInternal && req.IsInternal {
+		return false
+	}
+
 	// Apply IP exclusion
 	if excludeIP != nil && len(excludeIP.ClientIPs) > 0 {
 		for _, excludedIP := range excludeIP.ClientIPs {
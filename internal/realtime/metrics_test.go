@@ -0,0 +1,159 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCollector() *MetricsCollector {
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	return NewMetricsCollector(nil, logger)
+}
+
+func TestIngestEvictsOldestBeyondCap(t *testing.T) {
+	m := newTestCollector()
+	m.SetMaxBuffered(3)
+
+	base := time.Now().Add(-10 * time.Second)
+	for i := 0; i < 5; i++ {
+		m.Ingest(&models.HTTPRequest{ClientIP: "1.1.1.1", Timestamp: base.Add(time.Duration(i) * time.Second)})
+	}
+
+	assert.Len(t, m.requestBuffer, 3)
+	assert.Equal(t, base.Add(2*time.Second), m.requestBuffer[0].Timestamp, "oldest requests are evicted first")
+	assert.Equal(t, int64(2), m.evicted)
+
+	m.collectMetrics()
+	assert.Equal(t, int64(0), m.evicted, "eviction count is reset once reported")
+}
+
+func TestIngestSkipsRequestsOutsideWindow(t *testing.T) {
+	m := newTestCollector()
+
+	m.Ingest(&models.HTTPRequest{ClientIP: "1.1.1.1", Timestamp: time.Now().Add(-2 * DefaultBufferDuration)})
+	assert.Empty(t, m.requestBuffer, "backfilled requests never enter the buffer")
+
+	m.Ingest(&models.HTTPRequest{ClientIP: "1.1.1.1", Timestamp: time.Now()})
+	assert.Len(t, m.requestBuffer, 1)
+}
+
+func TestSetMaxBufferedDefault(t *testing.T) {
+	m := newTestCollector()
+	m.SetMaxBuffered(0)
+	assert.Equal(t, DefaultMaxBufferedRequests, m.maxBuffered)
+}
+
+func TestSetWindows(t *testing.T) {
+	m := newTestCollector()
+	assert.Error(t, m.SetWindows(10*time.Second, 20*time.Second), "the rate window cannot exceed the buffer")
+	assert.Equal(t, DefaultBufferDuration, m.BufferDuration(), "a rejected configuration is not applied")
+
+	assert.NoError(t, m.SetWindows(2*time.Minute, 10*time.Second))
+	now := time.Now()
+	m.Ingest(&models.HTTPRequest{ClientIP: "1.1.1.1", StatusCode: 500, Timestamp: now.Add(-90 * time.Second)})
+	m.Ingest(&models.HTTPRequest{ClientIP: "1.1.1.1", StatusCode: 200, Timestamp: now.Add(-8 * time.Second)})
+	m.Ingest(&models.HTTPRequest{ClientIP: "2.2.2.2", StatusCode: 404, Timestamp: now.Add(-7 * time.Second)})
+	m.collectMetrics()
+
+	metrics := m.GetMetrics()
+	assert.Equal(t, int64(1), metrics.Status5xx, "status counts cover the whole buffer")
+	assert.InDelta(t, 0.2, metrics.RequestRate, 0.001, "two requests over a 10s window")
+	assert.InDelta(t, 0.1, metrics.ErrorRate, 0.001)
+
+	assert.NoError(t, m.SetWindows(0, 0))
+	assert.Equal(t, DefaultBufferDuration, m.BufferDuration())
+	assert.Equal(t, DefaultRateWindow, m.rateWindow)
+}
+
+func TestTryAcquireConnectionRespectsLimit(t *testing.T) {
+	m := newTestCollector()
+
+	assert.True(t, m.TryAcquireConnection(2))
+	assert.True(t, m.TryAcquireConnection(2))
+	assert.False(t, m.TryAcquireConnection(2))
+	assert.Equal(t, 2, m.GetActiveConnections())
+
+	m.AdjustActiveConnections(-1)
+	assert.True(t, m.TryAcquireConnection(2))
+	assert.True(t, m.TryAcquireConnection(0), "zero means unlimited")
+	assert.Equal(t, 3, m.GetActiveConnections())
+}
+
+func TestPerSourceBuffersEvictGloballyOldest(t *testing.T) {
+	m := newTestCollector()
+	m.EnablePerSourceBuffers(4)
+
+	base := time.Now().Add(-10 * time.Second)
+	for i := 0; i < 4; i++ {
+		m.Ingest(&models.HTTPRequest{SourceName: "busy", Timestamp: base.Add(time.Duration(i) * time.Second)})
+	}
+	assert.Equal(t, 4, m.sourceBufferedSize)
+
+	// A quiet source still gets in once the busy one has filled the shared bound
+	m.Ingest(&models.HTTPRequest{SourceName: "quiet", Timestamp: base.Add(5 * time.Second)})
+
+	assert.Len(t, m.sourceBuffers["quiet"], 1, "new source must not be starved")
+	assert.Len(t, m.sourceBuffers["busy"], 3)
+	assert.Equal(t, base.Add(time.Second), m.sourceBuffers["busy"][0].Timestamp, "the globally oldest request is evicted")
+	assert.Equal(t, 4, m.sourceBufferedSize)
+
+	// An older request arriving late is the oldest itself and is dropped
+	m.Ingest(&models.HTTPRequest{SourceName: "late", Timestamp: base.Add(-time.Second)})
+	assert.Empty(t, m.sourceBuffers["late"])
+	assert.Equal(t, 4, m.sourceBufferedSize)
+}
+
+func TestGetSourceMetrics(t *testing.T) {
+	now := time.Now()
+	requests := []*models.HTTPRequest{
+		{SourceName: "traefik", ClientIP: "1.1.1.1", StatusCode: 200, Timestamp: now.Add(-2 * time.Second)},
+		{SourceName: "traefik", ClientIP: "1.1.1.1", StatusCode: 500, Timestamp: now.Add(-time.Second)},
+		{SourceName: "caddy", ClientIP: "2.2.2.2", StatusCode: 404, Timestamp: now.Add(-time.Second)},
+	}
+
+	for _, perSource := range []bool{false, true} {
+		m := newTestCollector()
+		if perSource {
+			m.EnablePerSourceBuffers(100)
+		}
+		for _, req := range requests {
+			m.Ingest(req)
+		}
+
+		traefik := m.GetSourceMetrics("traefik")
+		assert.Equal(t, int64(1), traefik.Status2xx, "per-source buffers: %v", perSource)
+		assert.Equal(t, int64(1), traefik.Status5xx, "per-source buffers: %v", perSource)
+		assert.Zero(t, traefik.Status4xx, "per-source buffers: %v", perSource)
+
+		caddy := m.GetSourceMetrics("caddy")
+		assert.Equal(t, int64(1), caddy.Status4xx, "per-source buffers: %v", perSource)
+
+		unknown := m.GetSourceMetrics("unknown")
+		assert.Zero(t, unknown.Status2xx+unknown.Status4xx+unknown.Status5xx)
+	}
+}
+
+func TestGetSourceCachedJSON(t *testing.T) {
+	m := newTestCollector()
+	assert.Nil(t, m.GetSourceCachedJSON("traefik"), "nothing cached while isolation is disabled")
+
+	m.EnablePerSourceBuffers(100)
+	m.Ingest(&models.HTTPRequest{SourceName: "traefik", StatusCode: 200, Timestamp: time.Now()})
+	assert.Nil(t, m.GetSourceCachedJSON("traefik"), "cache is filled by the collection loop")
+
+	m.collectMetrics()
+	assert.Contains(t, string(m.GetSourceCachedJSON("traefik")), `"status_2xx":1`)
+	assert.Nil(t, m.GetSourceCachedJSON("caddy"))
+
+	// Sources without recent requests drop out of the cache
+	m.bufferMu.Lock()
+	m.sourceBuffers["traefik"][0].Timestamp = time.Now().Add(-2 * time.Minute)
+	m.bufferMu.Unlock()
+	m.collectMetrics()
+	assert.Nil(t, m.GetSourceCachedJSON("traefik"))
+}
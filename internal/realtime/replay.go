@@ -0,0 +1,207 @@
+// MIT License
+//
+// # Copyright (c) 2026 Kolin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package realtime
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+)
+
+const (
+	// MaxReplayRows caps how many stored requests one replay loads (the newest of the window)
+	MaxReplayRows = 100000
+	// MaxReplaySpeed is the fastest allowed playback multiplier
+	MaxReplaySpeed = 100
+)
+
+var (
+	// ErrReplayRunning is returned when a replay is started while another one is active
+	ErrReplayRunning = errors.New("a replay is already running")
+	// ErrReplayEmpty is returned when the requested window holds no stored requests
+	ErrReplayEmpty = errors.New("no requests stored in the replay window")
+	// ErrInvalidReplay wraps validation failures of the window or speed
+	ErrInvalidReplay = errors.New("invalid replay")
+)
+
+// ReplaySource loads the stored requests of a time window
+// (implemented by repositories.HTTPRequestRepository)
+type ReplaySource interface {
+	FindByTimeRange(start, end time.Time, limit int) ([]*models.HTTPRequest, error)
+}
+
+// ReplayStatus describes the current or last replay
+type ReplayStatus struct {
+	Active    bool      `json:"active"`
+	Start     time.Time `json:"start"`      // Replayed window start
+	End       time.Time `json:"end"`        // Replayed window end
+	Speed     float64   `json:"speed"`      // Playback multiplier (1 = original pace)
+	Total     int       `json:"total"`      // Requests loaded from the window
+	Replayed  int       `json:"replayed"`   // Requests fed into the real-time buffer so far
+	StartedAt time.Time `json:"started_at"` // When playback began
+}
+
+// Replayer feeds stored requests into a MetricsCollector at their original pace
+// (scaled by a speed multiplier), so the live charts animate from past data.
+// Each request is re-stamped with the time it is replayed, and the collector
+// flags its metrics as a replay until playback ends.
+type Replayer struct {
+	collector *MetricsCollector
+	source    ReplaySource
+	logger    *pterm.Logger
+
+	mu     sync.Mutex
+	status ReplayStatus
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewReplayer creates a replayer feeding the given collector
+func NewReplayer(collector *MetricsCollector, source ReplaySource, logger *pterm.Logger) *Replayer {
+	return &Replayer{
+		collector: collector,
+		source:    source,
+		logger:    logger,
+	}
+}
+
+// Start loads the requests stored between start and end and begins replaying them
+// in the background at the given speed
+func (r *Replayer) Start(start, end time.Time, speed float64) (ReplayStatus, error) {
+	if !end.After(start) {
+		return ReplayStatus{}, fmt.Errorf("%w: end must be after start", ErrInvalidReplay)
+	}
+	if speed <= 0 || speed > MaxReplaySpeed {
+		return ReplayStatus{}, fmt.Errorf("%w: speed must be greater than 0 and at most %d", ErrInvalidReplay, MaxReplaySpeed)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status.Active {
+		return r.status, ErrReplayRunning
+	}
+
+	rows, err := r.source.FindByTimeRange(start, end, MaxReplayRows)
+	if err != nil {
+		return ReplayStatus{}, err
+	}
+	if len(rows) == 0 {
+		return ReplayStatus{}, ErrReplayEmpty
+	}
+
+	// Rows come newest first; play them back in chronological order
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+
+	r.status = ReplayStatus{
+		Active:    true,
+		Start:     start,
+		End:       end,
+		Speed:     speed,
+		Total:     len(rows),
+		StartedAt: time.Now(),
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	r.collector.SetReplayActive(true)
+
+	r.logger.Info("Started real-time replay",
+		r.logger.Args("start", start, "end", end, "speed", speed, "requests", len(rows)))
+
+	go r.run(rows, speed, r.stop, r.done)
+	return r.status, nil
+}
+
+// run ingests the rows on their scaled schedule until done or stopped
+func (r *Replayer) run(rows []*models.HTTPRequest, speed float64, stop, done chan struct{}) {
+	defer func() {
+		r.mu.Lock()
+		r.status.Active = false
+		replayed := r.status.Replayed
+		r.mu.Unlock()
+
+		r.collector.SetReplayActive(false)
+		r.logger.Info("Real-time replay finished", r.logger.Args("replayed", replayed, "total", len(rows)))
+		close(done)
+	}()
+
+	origin := rows[0].Timestamp
+	began := time.Now()
+	for i, row := range rows {
+		due := began.Add(time.Duration(float64(row.Timestamp.Sub(origin)) / speed))
+		if wait := time.Until(due); wait > 0 {
+			select {
+			case <-stop:
+				return
+			case <-time.After(wait):
+			}
+		} else {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+
+		replayed := *row
+		replayed.Timestamp = time.Now()
+		r.collector.Ingest(&replayed)
+
+		r.mu.Lock()
+		r.status.Replayed = i + 1
+		r.mu.Unlock()
+	}
+}
+
+// Stop ends the running replay and waits for it to exit.
+// Returns false when no replay was running.
+func (r *Replayer) Stop() bool {
+	r.mu.Lock()
+	if !r.status.Active {
+		r.mu.Unlock()
+		return false
+	}
+	select {
+	case <-r.stop:
+	default:
+		close(r.stop)
+	}
+	done := r.done
+	r.mu.Unlock()
+
+	<-done
+	return true
+}
+
+// Status returns the state of the current or last replay
+func (r *Replayer) Status() ReplayStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
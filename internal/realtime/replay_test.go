@@ -0,0 +1,87 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"loglynx/internal/database/models"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeReplaySource returns its rows newest first, like the repository
+type fakeReplaySource struct {
+	rows []*models.HTTPRequest
+}
+
+func (f *fakeReplaySource) FindByTimeRange(start, end time.Time, limit int) ([]*models.HTTPRequest, error) {
+	return f.rows, nil
+}
+
+func newTestReplayer(rows []*models.HTTPRequest) (*Replayer, *MetricsCollector) {
+	collector := newTestCollector()
+	logger := pterm.DefaultLogger.WithLevel(pterm.LogLevelDisabled)
+	return NewReplayer(collector, &fakeReplaySource{rows: rows}, logger), collector
+}
+
+func TestReplayerFeedsCollectorInOrder(t *testing.T) {
+	past := time.Now().Add(-48 * time.Hour)
+	rows := []*models.HTTPRequest{
+		{Path: "/third", Timestamp: past.Add(2 * time.Second)},
+		{Path: "/second", Timestamp: past.Add(time.Second)},
+		{Path: "/first", Timestamp: past},
+	}
+	first := rows[2]
+	replayer, collector := newTestReplayer(rows)
+
+	status, err := replayer.Start(past, past.Add(time.Minute), MaxReplaySpeed)
+	assert.NoError(t, err)
+	assert.True(t, status.Active)
+	assert.Equal(t, 3, status.Total)
+	assert.True(t, collector.GetMetrics().Replay, "metrics are flagged while replaying")
+
+	assert.Eventually(t, func() bool { return !replayer.Status().Active }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, 3, replayer.Status().Replayed)
+	assert.False(t, collector.GetMetrics().Replay)
+
+	collector.bufferMu.RLock()
+	defer collector.bufferMu.RUnlock()
+	if assert.Len(t, collector.requestBuffer, 3) {
+		assert.Equal(t, "/first", collector.requestBuffer[0].Path)
+		assert.Equal(t, "/third", collector.requestBuffer[2].Path)
+		assert.WithinDuration(t, time.Now(), collector.requestBuffer[2].Timestamp, time.Second, "requests are re-stamped")
+	}
+	assert.Equal(t, past, first.Timestamp, "stored rows are not modified")
+}
+
+func TestReplayerStopAndConflicts(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	replayer, collector := newTestReplayer([]*models.HTTPRequest{
+		{Path: "/late", Timestamp: past.Add(time.Hour)},
+		{Path: "/early", Timestamp: past},
+	})
+
+	_, err := replayer.Start(past, past, 1)
+	assert.ErrorIs(t, err, ErrInvalidReplay)
+	_, err = replayer.Start(past, past.Add(time.Hour), MaxReplaySpeed+1)
+	assert.ErrorIs(t, err, ErrInvalidReplay)
+
+	_, err = replayer.Start(past, past.Add(time.Hour), 1)
+	assert.NoError(t, err)
+	_, err = replayer.Start(past, past.Add(time.Hour), 1)
+	assert.ErrorIs(t, err, ErrReplayRunning)
+
+	assert.Eventually(t, func() bool { return replayer.Status().Replayed == 1 }, time.Second, 5*time.Millisecond)
+	assert.True(t, replayer.Stop())
+	assert.False(t, replayer.Status().Active)
+	assert.Equal(t, 1, replayer.Status().Replayed, "the second request was an hour away")
+	assert.False(t, collector.GetMetrics().Replay)
+	assert.False(t, replayer.Stop(), "nothing left to stop")
+}
+
+func TestReplayerRejectsEmptyWindow(t *testing.T) {
+	replayer, _ := newTestReplayer(nil)
+	_, err := replayer.Start(time.Now().Add(-time.Hour), time.Now(), 1)
+	assert.ErrorIs(t, err, ErrReplayEmpty)
+}
@@ -28,7 +28,7 @@ SOFTWARE.
  */
 
 const LogLynxAPI = {
-    baseURL: '/api/v1',
+    baseURL: appPath('/api/v1'),
     cache: new Map(),
     cacheTimeout: 30000, // 30 seconds default cache
     currentServices: [], // Array of selected services [{name: 'X', type: 'backend_name'}, ...]
@@ -630,6 +630,13 @@ const LogLynxAPI = {
         return this.get('/stats/log-processing');
     },
 
+    /**
+     * Get log processing progress with processing rate and ETA per source
+     */
+    async getProcessingProgress() {
+        return this.get('/system/processing');
+    },
+
     /**
      * Get system statistics (uptime, memory, database info, etc.)
      */
@@ -697,14 +704,15 @@ const LogLynxAPI = {
         const url = this.buildURL('/realtime/stream');
         const eventSource = new EventSource(url);
 
-        eventSource.onmessage = (event) => {
+        // Snapshots arrive as named "metrics" events; "heartbeat" events are keep-alives
+        eventSource.addEventListener('metrics', (event) => {
             try {
                 const data = JSON.parse(event.data);
                 onMessage(data);
             } catch (error) {
                 console.error('Failed to parse SSE data:', error);
             }
-        };
+        });
 
         eventSource.onerror = (error) => {
             console.error('SSE connection error:', error);
@@ -1043,9 +1051,10 @@ const LogLynxAPI = {
      * Search for IPs matching a query
      * @param {string} query - Search query (partial IP)
      * @param {number} limit - Number of results (1-100)
+     * @param {number} hours - Number of hours to search (0 = all time)
      */
-    async searchIPs(query, limit = 20) {
-        return this.get('/ip/search', { q: query, limit });
+    async searchIPs(query, limit = 20, hours = 168) {
+        return this.get('/ip/search', { q: query, limit, hours });
     }
 };
 
@@ -38,7 +38,7 @@ const RefreshManager = (() => {
      * Get current page identifier from URL
      */
     function getCurrentPageId() {
-        const path = window.location.pathname;
+        const path = appRoutePath();
         if (path === '/' || path === '/index' || path === '/overview') return 'overview';
         return path.replace(/^\//, '').replace(/\//g, '-') || 'overview';
     }
@@ -47,7 +47,7 @@ const RefreshManager = (() => {
      * Check current path and hide refresh filters if needed
      */
     function checkAndHideFilters() {
-        const currentPath = window.location.pathname;
+        const currentPath = appRoutePath();
 
         const shouldHideFilters =
         currentPath === '/system' ||
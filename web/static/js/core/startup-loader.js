@@ -336,7 +336,7 @@ const LogLynxStartupLoader = {
      */
     async loadVersion() {
         try {
-            const response = await fetch('/api/v1/version');
+            const response = await fetch(appPath('/api/v1/version'));
             if (response.ok) {
                 const data = await response.json();
                 const versionEl = document.getElementById('splashVersion');
@@ -606,8 +606,15 @@ const LogLynxStartupLoader = {
         // Store last successful percentage
         this.lastSuccessfulPercentage = avgPercentage;
 
-        // Calculate ETA based on processing speed
-        const eta = this.calculateETA(avgPercentage);
+        // Prefer the server ETA (slowest source); fall back to the observed percentage speed
+        const serverEtaSeconds = this.getServerEtaSeconds(stats);
+        let eta;
+        if (serverEtaSeconds !== null) {
+            this.currentEtaSeconds = serverEtaSeconds;
+            eta = this.formatETA(serverEtaSeconds);
+        } else {
+            eta = this.calculateETA(avgPercentage);
+        }
 
         // Start countdown animation if we have an ETA
         if (eta && this.currentEtaSeconds !== null) {
@@ -640,8 +647,12 @@ const LogLynxStartupLoader = {
         if (detailsEl) {
             const bytesText = this.formatBytes(processedBytes) + ' / ' + this.formatBytes(totalBytes);
             const sourcesText = stats.length === 1 ? '1 source' : `${stats.length} sources`;
+            const bytesPerSecond = stats.reduce((sum, source) => sum + (source.bytes_per_second || 0), 0);
             const speed = this.getProcessingSpeed();
-            const speedText = speed ? ` • ${speed}%/s` : '';
+            let speedText = speed ? ` • ${speed}%/s` : '';
+            if (bytesPerSecond > 0) {
+                speedText = ` • ${this.formatBytes(bytesPerSecond)}/s`;
+            }
             const elapsed = this.getElapsedTime();
             const elapsedText = elapsed ? ` • waiting ${elapsed}` : '';
             detailsEl.textContent = `Processing ${bytesText} from ${sourcesText}${speedText}${elapsedText}`;
@@ -722,16 +733,16 @@ const LogLynxStartupLoader = {
             console.log(`[StartupLoader] Data availability - GeoIP: ${hasGeoData}, User Analytics: ${hasUserData}`);
             
             // Handle redirects if on a page that requires missing data
-            const path = window.location.pathname;
+            const path = appRoutePath();
             if (path.includes('/geographic') && !hasGeoData) {
                 console.log('[StartupLoader] GeoIP data missing, redirecting from geographic page');
-                window.location.href = '/';
+                window.location.href = appPath('/');
                 return false;
             }
             
             if (path.includes('/users') && !hasUserData) {
                 console.log('[StartupLoader] User analytics data missing, redirecting from users page');
-                window.location.href = '/';
+                window.location.href = appPath('/');
                 return false;
             }
             
@@ -760,6 +771,16 @@ const LogLynxStartupLoader = {
         }
     },
     
+    /**
+     * Longest ETA reported by the server across sources, or null when none is known
+     */
+    getServerEtaSeconds(stats) {
+        const etas = stats
+            .map(source => source.eta_seconds)
+            .filter(eta => typeof eta === 'number');
+        return etas.length > 0 ? Math.max(...etas) : null;
+    },
+
     /**
      * Calculate ETA based on processing speed (improved algorithm)
      */
@@ -1052,7 +1073,7 @@ const LogLynxStartupLoader = {
      */
     async checkProcessingStatus() {
         try {
-            const result = await LogLynxAPI.getLogProcessingStats();
+            const result = await LogLynxAPI.getProcessingProgress();
 
             // Handle 503 responses - service still initializing, retry
             if (result.status === 503 || result.initializing) {
@@ -2254,7 +2254,7 @@ function displayGlobalIPSearchResults(results) {
     let html = '<div class="list-group">';
     results.forEach(result => {
         html += `
-            <a href="/ip/${result.ip_address}" class="list-group-item list-group-item-action" 
+            <a href="${appPath('/ip/' + result.ip_address)}" class="list-group-item list-group-item-action" 
                style="background: var(--loglynx-card); border-color: var(--border-color); color: #FFFFFF;">
                 <div class="d-flex justify-content-between align-items-center">
                     <div>
@@ -2280,7 +2280,7 @@ function displayGlobalIPSearchResults(results) {
 function performGlobalIPSearch() {
     const ip = document.getElementById('globalIPSearchInput').value.trim();
     if (ip) {
-        window.location.href = `/ip/${ip}`;
+        window.location.href = appPath(`/ip/${ip}`);
     }
 }
 
@@ -2300,7 +2300,7 @@ document.addEventListener('DOMContentLoaded', () => {
  */
 LogLynxUtils.hideFiltersOnSpecificPages = function() {
     // Get the current path
-    const currentPath = window.location.pathname;
+    const currentPath = appRoutePath();
     
     // Check if current page should have filters hidden
     const shouldHideFilters =
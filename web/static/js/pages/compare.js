@@ -1047,7 +1047,7 @@ async function loadSnapshotManager() {
                 data: null,
                 orderable: false,
                 render: row => {
-                    const url = `/compare/${row.token}`;
+                    const url = appPath(`/compare/${row.token}`);
                     const isExpired = row.expires_at && new Date(row.expires_at).getTime() <= Date.now();
                     const toggleLabel = row.active && !isExpired ? 'Disable' : 'Enable';
                     const nextActive = !(row.active && !isExpired);
@@ -1098,7 +1098,7 @@ function renderSnapshotStatus(row) {
 }
 
 function copySnapshotLink(token) {
-    copyText(new URL(`/compare/${token}`, window.location.origin).toString());
+    copyText(new URL(appPath(`/compare/${token}`), window.location.origin).toString());
 }
 
 function openSnapshotExpirationPicker(token) {
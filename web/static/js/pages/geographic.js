@@ -177,7 +177,7 @@ function initializeMap(ipsData, countriesData) {
                 <hr style="margin: 8px 0; border-color: #444;">
                 <div style="max-height: 100px; overflow-y: auto; font-size: 11px; color: #E8E8E8;">
                     <strong style="color: #FF6B35;">IP Addresses:</strong><br>
-                    ${loc.ips.slice(0, 5).map(ip => `<code style="background: #1A1A1D; color: #FFB800; padding: 2px 4px; border-radius: 3px;"><a href="/ip/${ip}">${ip}</a></code>`).join('<br>')}
+                    ${loc.ips.slice(0, 5).map(ip => `<code style="background: #1A1A1D; color: #FFB800; padding: 2px 4px; border-radius: 3px;"><a href="${appPath('/ip/' + ip)}">${ip}</a></code>`).join('<br>')}
                     ${loc.ips.length > 5 ? `<br><span style="color: #999;">...and ${loc.ips.length - 5} more</span>` : ''}
                 </div>
             </div>
@@ -583,7 +583,7 @@ function initIPGeoTable(ipsData) {
         $('#ipGeoTable').DataTable({
             data: [],
             columns: [
-                { data: 'ip_address', render: (d) => `<a href="/ip/${d}" class="ip-link"><code>${d}</code></a>` },
+                { data: 'ip_address', render: (d) => `<a href="${appPath('/ip/' + d)}" class="ip-link"><code>${d}</code></a>` },
                 { data: 'country', render: (d) => d || '-' },
                 { data: 'city', render: (d) => d || '-' },
                 { data: null, render: (data) => '-' },
@@ -606,7 +606,7 @@ function initIPGeoTable(ipsData) {
             {
                 data: 'ip_address',
                 render: (data, type, row) => {
-                    return `<div class="tag-input-container" style="display: inline-block;"><span class="ip-display" data-ip="${data}" style="display: inline;"><a href="/ip/${data}" class="ip-link"><code>${data}</code></a></span><div class="tag-chips" data-ip="${data}" style="display: inline;"></div><button class="edit-tag-btn" data-ip="${data}" onclick="openTagModal('${data}')" style="background: none; border: none; cursor: pointer; font-size: 14px; display: none;">✏️</button></div>`;
+                    return `<div class="tag-input-container" style="display: inline-block;"><span class="ip-display" data-ip="${data}" style="display: inline;"><a href="${appPath('/ip/' + data)}" class="ip-link"><code>${data}</code></a></span><div class="tag-chips" data-ip="${data}" style="display: inline;"></div><button class="edit-tag-btn" data-ip="${data}" onclick="openTagModal('${data}')" style="background: none; border: none; cursor: pointer; font-size: 14px; display: none;">✏️</button></div>`;
                 }
             },
             {
@@ -843,7 +843,7 @@ document.addEventListener('DOMContentLoaded', () => {
     // Check if GeoIP data is available
     const hasGeoData = localStorage.getItem('loglynx_geoip_available');
     if (hasGeoData === 'false') {
-        window.location.href = '/';
+        window.location.href = appPath('/');
         return;
     }
 
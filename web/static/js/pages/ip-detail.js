@@ -1116,7 +1116,7 @@ function displaySearchResults(results) {
 function selectIP(ipAddress) {
     $('#ipSearchResults').hide();
     $('#ipSearchInput').val(ipAddress);
-    window.location.href = `/ip/${ipAddress}`;
+    window.location.href = appPath(`/ip/${ipAddress}`);
 }
 
 /**
@@ -1125,7 +1125,7 @@ function selectIP(ipAddress) {
 function searchAndNavigateIP() {
     const ip = $('#ipSearchInput').val().trim();
     if (ip) {
-        window.location.href = `/ip/${ip}`;
+        window.location.href = appPath(`/ip/${ip}`);
     }
 }
 
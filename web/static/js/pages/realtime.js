@@ -570,7 +570,7 @@ function updateTopIPsTable(topIPs) {
             <tr>
                 <td>
                     <div class="tag-input-container" style="display: inline-block;">
-                        <span class="ip-display" data-ip="${ip.ip}" style="display: inline;"><a href="/ip/${ip.ip}" class="text-decoration-none"><code>${ip.ip}</code></a></span>
+                        <span class="ip-display" data-ip="${ip.ip}" style="display: inline;"><a href="${appPath('/ip/' + ip.ip)}" class="text-decoration-none"><code>${ip.ip}</code></a></span>
                         <div class="tag-chips" data-ip="${ip.ip}" id="tag-chips-${ip.ip.replace(/\./g, '-')}" style="display: inline;"></div>
                         <button class="edit-tag-btn" data-ip="${ip.ip}" onclick="openTagModal('${ip.ip}')" style="background: none; border: none; cursor: pointer; font-size: 14px; display: ${localStorage.getItem('loglynx_ip_tagging_enabled') === 'true' ? 'inline' : 'none'};">✏️</button>
                     </div>
@@ -121,6 +121,9 @@ function updateSystemStats(data) {
 function updateSystemDetailsTable(data) {
     const nextCleanupDisplay = data.next_cleanup_time !== 'Disabled' ? LogLynxUtils.formatDateTime(data.next_cleanup_time) : 'Disabled';
     const lastCleanupDisplay = data.last_cleanup_time !== 'Never' && data.last_cleanup_time !== 'N/A' ? LogLynxUtils.formatDateTime(data.last_cleanup_time) : data.last_cleanup_time || 'Never';
+    const sourceOverrides = (data.source_retention || [])
+        .filter(s => !s.inherited)
+        .map(s => `${s.source}: ${s.retention_days} days`);
 
     const details = [
         { label: 'Application Version', value: data.app_version ? `<a href="https://github.com/K0lin/loglynx/tree/v${data.app_version}" target="_blank" rel="noopener">v${data.app_version}</a>` : '-', icon: 'code-branch' },
@@ -138,6 +141,7 @@ function updateSystemDetailsTable(data) {
         { label: 'Total Records', value: LogLynxUtils.formatNumber(data.total_records || 0), icon: 'table' },
         { label: 'Records to Cleanup', value: LogLynxUtils.formatNumber(data.records_to_cleanup || 0), icon: 'trash' },
         { label: 'Retention Policy', value: data.retention_days > 0 ? `${data.retention_days} days` : 'Disabled', icon: 'calendar-alt' },
+        ...(sourceOverrides.length > 0 ? [{ label: 'Per-Source Retention', value: sourceOverrides.join('<br>'), icon: 'layer-group' }] : []),
         { label: 'Next Cleanup', value: nextCleanupDisplay, icon: 'clock' },
         { label: 'Countdown to Cleanup', value: data.next_cleanup_countdown || 'N/A', icon: 'hourglass-half' },
         { label: 'Last Cleanup', value: lastCleanupDisplay, icon: 'history' },
@@ -527,7 +527,7 @@ function updateTopIPsTable(data) {
                     <td>${index + 1}</td>
                     <td>
                         <div class="tag-input-container" style="display: inline-block;">
-                            <span class="ip-display" data-ip="${item.ip_address}" style="display: inline;"><a href="/ip/${item.ip_address}" class="ip-link"><code>${item.ip_address}</code></a></span>
+                            <span class="ip-display" data-ip="${item.ip_address}" style="display: inline;"><a href="${appPath('/ip/' + item.ip_address)}" class="ip-link"><code>${item.ip_address}</code></a></span>
                             <div class="tag-chips" data-ip="${item.ip_address}" style="display: inline;"></div>
                             <button class="edit-tag-btn" data-ip="${item.ip_address}" onclick="openTagModal('${item.ip_address}')" style="background: none; border: none; cursor: pointer; font-size: 14px; display: none;">✏️</button>
                         </div>
@@ -670,7 +670,7 @@ function renderDrilldownRows(data) {
             <td>${index + 1}</td>
             <td>
                 <div class="tag-input-container" style="display: inline-block;">
-                    <span class="ip-display" data-ip="${item.ip_address}" style="display: inline;"><a href="/ip/${item.ip_address}" class="ip-link"><code>${item.ip_address}</code></a></span>
+                    <span class="ip-display" data-ip="${item.ip_address}" style="display: inline;"><a href="${appPath('/ip/' + item.ip_address)}" class="ip-link"><code>${item.ip_address}</code></a></span>
                     <div class="tag-chips" data-ip="${item.ip_address}" style="display: inline;"></div>
                     <button class="edit-tag-btn" data-ip="${item.ip_address}" onclick="openTagModal('${item.ip_address}')" style="background: none; border: none; cursor: pointer; font-size: 14px; display: none;">✏️</button>
                 </div>
@@ -735,7 +735,7 @@ document.addEventListener('DOMContentLoaded', () => {
     // Check if User Analytics data is available
     const hasUserData = localStorage.getItem('loglynx_user_analytics_available');
     if (hasUserData === 'false') {
-        window.location.href = '/';
+        window.location.href = appPath('/');
         return;
     }
 
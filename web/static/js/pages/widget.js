@@ -11,6 +11,8 @@
         '30d': { hours: 720, label: '30D', badge: '30d', refresh: 60000 }
     };
 
+    const basePath = (window.LOGLYNX_CONFIG && window.LOGLYNX_CONFIG.basePath) || '';
+
     let mode = TIME_CONFIG[initialMode] ? initialMode : 'realtime';
     let refreshTimer = null;
     let timelinePoints = [];
@@ -39,12 +41,12 @@
     }
 
     function apiURL() {
-        if (mode === 'realtime') return '/api/v1/widget/data';
-        return `/api/v1/widget/summary?hours=${currentConfig().hours}`;
+        if (mode === 'realtime') return `${basePath}/api/v1/widget/data`;
+        return `${basePath}/api/v1/widget/summary?hours=${currentConfig().hours}`;
     }
 
     function timelineURL() {
-        return `/api/v1/widget/timeline?hours=${currentConfig().hours}`;
+        return `${basePath}/api/v1/widget/timeline?hours=${currentConfig().hours}`;
     }
 
     function formatNumber(num) {
@@ -27,7 +27,7 @@ function openTagModal(ip) {
   if (ipTagsCache.has(ip)) {
       populateAndShowModal(ip, ipTagsCache.get(ip));
   } else {
-      fetch(`/api/v1/ip/tags/${ip}`)
+      fetch(appPath(`/api/v1/ip/tags/${ip}`))
         .then(response => response.json())
         .then(data => {
           // Normalize data from API (handle both PascalCase and snake_case)
@@ -75,7 +75,7 @@ function saveTag() {
   const tags = document.getElementById('tags-input').value;
   const ip = currentIPEditing;
 
-  fetch('/api/v1/ip/tags', {
+  fetch(appPath('/api/v1/ip/tags'), {
     method: 'POST',
     headers: {
       'Content-Type': 'application/json',
@@ -167,7 +167,7 @@ function loadTagsForIP(ip) {
     return;
   }
 
-  fetch(`/api/v1/ip/tags/${ip}`)
+  fetch(appPath(`/api/v1/ip/tags/${ip}`))
     .then(response => response.json())
     .then(data => {
       // Standardize data from API
@@ -192,7 +192,7 @@ function toggleTagging(enabled) {
 
     if (shouldFetchBulk) {
         lastBulkFetch = now;
-        fetch('/api/v1/ip/tags')
+        fetch(appPath('/api/v1/ip/tags'))
           .then(response => response.json())
           .then(tags => {
             ipTagsCache.clear();